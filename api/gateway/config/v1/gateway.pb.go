@@ -22,6 +22,62 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type TrailingSlash int32
+
+const (
+	// follow the global policy, which is set by the TRAILING_SLASH_POLICY env
+	TrailingSlash_TRAILING_SLASH_UNSPECIFIED TrailingSlash = 0
+	// only the exact path is matched
+	TrailingSlash_TRAILING_SLASH_EXACT TrailingSlash = 1
+	// both forms are matched and forwarded as is
+	TrailingSlash_TRAILING_SLASH_TRANSPARENT TrailingSlash = 2
+	// the other form is redirected to the endpoint path with 301
+	TrailingSlash_TRAILING_SLASH_REDIRECT TrailingSlash = 3
+)
+
+// Enum value maps for TrailingSlash.
+var (
+	TrailingSlash_name = map[int32]string{
+		0: "TRAILING_SLASH_UNSPECIFIED",
+		1: "TRAILING_SLASH_EXACT",
+		2: "TRAILING_SLASH_TRANSPARENT",
+		3: "TRAILING_SLASH_REDIRECT",
+	}
+	TrailingSlash_value = map[string]int32{
+		"TRAILING_SLASH_UNSPECIFIED": 0,
+		"TRAILING_SLASH_EXACT":       1,
+		"TRAILING_SLASH_TRANSPARENT": 2,
+		"TRAILING_SLASH_REDIRECT":    3,
+	}
+)
+
+func (x TrailingSlash) Enum() *TrailingSlash {
+	p := new(TrailingSlash)
+	*p = x
+	return p
+}
+
+func (x TrailingSlash) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (TrailingSlash) Descriptor() protoreflect.EnumDescriptor {
+	return file_gateway_config_v1_gateway_proto_enumTypes[0].Descriptor()
+}
+
+func (TrailingSlash) Type() protoreflect.EnumType {
+	return &file_gateway_config_v1_gateway_proto_enumTypes[0]
+}
+
+func (x TrailingSlash) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use TrailingSlash.Descriptor instead.
+func (TrailingSlash) EnumDescriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{0}
+}
+
 type Protocol int32
 
 const (
@@ -55,11 +111,11 @@ func (x Protocol) String() string {
 }
 
 func (Protocol) Descriptor() protoreflect.EnumDescriptor {
-	return file_gateway_config_v1_gateway_proto_enumTypes[0].Descriptor()
+	return file_gateway_config_v1_gateway_proto_enumTypes[1].Descriptor()
 }
 
 func (Protocol) Type() protoreflect.EnumType {
-	return &file_gateway_config_v1_gateway_proto_enumTypes[0]
+	return &file_gateway_config_v1_gateway_proto_enumTypes[1]
 }
 
 func (x Protocol) Number() protoreflect.EnumNumber {
@@ -68,7 +124,251 @@ func (x Protocol) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use Protocol.Descriptor instead.
 func (Protocol) EnumDescriptor() ([]byte, []int) {
-	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{0}
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{1}
+}
+
+type ErrorBudget_Action int32
+
+const (
+	// send the requests to the fallback backends
+	ErrorBudget_FALLBACK ErrorBudget_Action = 0
+	// reject the requests with 503
+	ErrorBudget_SHED ErrorBudget_Action = 1
+)
+
+// Enum value maps for ErrorBudget_Action.
+var (
+	ErrorBudget_Action_name = map[int32]string{
+		0: "FALLBACK",
+		1: "SHED",
+	}
+	ErrorBudget_Action_value = map[string]int32{
+		"FALLBACK": 0,
+		"SHED":     1,
+	}
+)
+
+func (x ErrorBudget_Action) Enum() *ErrorBudget_Action {
+	p := new(ErrorBudget_Action)
+	*p = x
+	return p
+}
+
+func (x ErrorBudget_Action) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ErrorBudget_Action) Descriptor() protoreflect.EnumDescriptor {
+	return file_gateway_config_v1_gateway_proto_enumTypes[2].Descriptor()
+}
+
+func (ErrorBudget_Action) Type() protoreflect.EnumType {
+	return &file_gateway_config_v1_gateway_proto_enumTypes[2]
+}
+
+func (x ErrorBudget_Action) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ErrorBudget_Action.Descriptor instead.
+func (ErrorBudget_Action) EnumDescriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{10, 0}
+}
+
+type DualWrite_FailurePolicy int32
+
+const (
+	// the secondary writes are sent in the background and their failures don't affect the request
+	DualWrite_IGNORE DualWrite_FailurePolicy = 0
+	// the secondary writes are waited for and a failed one fails the request with 502
+	DualWrite_FAIL DualWrite_FailurePolicy = 1
+)
+
+// Enum value maps for DualWrite_FailurePolicy.
+var (
+	DualWrite_FailurePolicy_name = map[int32]string{
+		0: "IGNORE",
+		1: "FAIL",
+	}
+	DualWrite_FailurePolicy_value = map[string]int32{
+		"IGNORE": 0,
+		"FAIL":   1,
+	}
+)
+
+func (x DualWrite_FailurePolicy) Enum() *DualWrite_FailurePolicy {
+	p := new(DualWrite_FailurePolicy)
+	*p = x
+	return p
+}
+
+func (x DualWrite_FailurePolicy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DualWrite_FailurePolicy) Descriptor() protoreflect.EnumDescriptor {
+	return file_gateway_config_v1_gateway_proto_enumTypes[3].Descriptor()
+}
+
+func (DualWrite_FailurePolicy) Type() protoreflect.EnumType {
+	return &file_gateway_config_v1_gateway_proto_enumTypes[3]
+}
+
+func (x DualWrite_FailurePolicy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DualWrite_FailurePolicy.Descriptor instead.
+func (DualWrite_FailurePolicy) EnumDescriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{11, 0}
+}
+
+type ResponseLimit_Action int32
+
+const (
+	// replace the oversized response with 502, responses without Content-Length are buffered up to max_bytes
+	ResponseLimit_REJECT ResponseLimit_Action = 0
+	// return the first max_bytes of the response body
+	ResponseLimit_TRUNCATE ResponseLimit_Action = 1
+)
+
+// Enum value maps for ResponseLimit_Action.
+var (
+	ResponseLimit_Action_name = map[int32]string{
+		0: "REJECT",
+		1: "TRUNCATE",
+	}
+	ResponseLimit_Action_value = map[string]int32{
+		"REJECT":   0,
+		"TRUNCATE": 1,
+	}
+)
+
+func (x ResponseLimit_Action) Enum() *ResponseLimit_Action {
+	p := new(ResponseLimit_Action)
+	*p = x
+	return p
+}
+
+func (x ResponseLimit_Action) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ResponseLimit_Action) Descriptor() protoreflect.EnumDescriptor {
+	return file_gateway_config_v1_gateway_proto_enumTypes[4].Descriptor()
+}
+
+func (ResponseLimit_Action) Type() protoreflect.EnumType {
+	return &file_gateway_config_v1_gateway_proto_enumTypes[4]
+}
+
+func (x ResponseLimit_Action) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ResponseLimit_Action.Descriptor instead.
+func (ResponseLimit_Action) EnumDescriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{18, 0}
+}
+
+type LoadBalancer_Policy int32
+
+const (
+	// pick of two choices by the ewma latency
+	LoadBalancer_P2C LoadBalancer_Policy = 0
+	// pick the node with the least in-flight cost relative to its weight
+	LoadBalancer_LEAST_COST LoadBalancer_Policy = 1
+)
+
+// Enum value maps for LoadBalancer_Policy.
+var (
+	LoadBalancer_Policy_name = map[int32]string{
+		0: "P2C",
+		1: "LEAST_COST",
+	}
+	LoadBalancer_Policy_value = map[string]int32{
+		"P2C":        0,
+		"LEAST_COST": 1,
+	}
+)
+
+func (x LoadBalancer_Policy) Enum() *LoadBalancer_Policy {
+	p := new(LoadBalancer_Policy)
+	*p = x
+	return p
+}
+
+func (x LoadBalancer_Policy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (LoadBalancer_Policy) Descriptor() protoreflect.EnumDescriptor {
+	return file_gateway_config_v1_gateway_proto_enumTypes[5].Descriptor()
+}
+
+func (LoadBalancer_Policy) Type() protoreflect.EnumType {
+	return &file_gateway_config_v1_gateway_proto_enumTypes[5]
+}
+
+func (x LoadBalancer_Policy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use LoadBalancer_Policy.Descriptor instead.
+func (LoadBalancer_Policy) EnumDescriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{21, 0}
+}
+
+type RetryBackoff_Jitter int32
+
+const (
+	// sleep the exact delay
+	RetryBackoff_NONE RetryBackoff_Jitter = 0
+	// sleep a random duration between 0 and the delay
+	RetryBackoff_FULL RetryBackoff_Jitter = 1
+	// sleep half of the delay plus a random duration up to the other half
+	RetryBackoff_EQUAL RetryBackoff_Jitter = 2
+)
+
+// Enum value maps for RetryBackoff_Jitter.
+var (
+	RetryBackoff_Jitter_name = map[int32]string{
+		0: "NONE",
+		1: "FULL",
+		2: "EQUAL",
+	}
+	RetryBackoff_Jitter_value = map[string]int32{
+		"NONE":  0,
+		"FULL":  1,
+		"EQUAL": 2,
+	}
+)
+
+func (x RetryBackoff_Jitter) Enum() *RetryBackoff_Jitter {
+	p := new(RetryBackoff_Jitter)
+	*p = x
+	return p
+}
+
+func (x RetryBackoff_Jitter) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RetryBackoff_Jitter) Descriptor() protoreflect.EnumDescriptor {
+	return file_gateway_config_v1_gateway_proto_enumTypes[6].Descriptor()
+}
+
+func (RetryBackoff_Jitter) Type() protoreflect.EnumType {
+	return &file_gateway_config_v1_gateway_proto_enumTypes[6]
+}
+
+func (x RetryBackoff_Jitter) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RetryBackoff_Jitter.Descriptor instead.
+func (RetryBackoff_Jitter) EnumDescriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{32, 0}
 }
 
 type Gateway struct {
@@ -79,10 +379,27 @@ type Gateway struct {
 	Name    string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
 	Version string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
 	// Deprecated: Marked as deprecated in gateway/config/v1/gateway.proto.
-	Hosts       []string        `protobuf:"bytes,3,rep,name=hosts,proto3" json:"hosts,omitempty"`
-	Endpoints   []*Endpoint     `protobuf:"bytes,4,rep,name=endpoints,proto3" json:"endpoints,omitempty"`
-	Middlewares []*Middleware   `protobuf:"bytes,5,rep,name=middlewares,proto3" json:"middlewares,omitempty"`
-	TlsStore    map[string]*TLS `protobuf:"bytes,6,rep,name=tls_store,json=tlsStore,proto3" json:"tls_store,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Hosts          []string        `protobuf:"bytes,3,rep,name=hosts,proto3" json:"hosts,omitempty"`
+	Endpoints      []*Endpoint     `protobuf:"bytes,4,rep,name=endpoints,proto3" json:"endpoints,omitempty"`
+	Middlewares    []*Middleware   `protobuf:"bytes,5,rep,name=middlewares,proto3" json:"middlewares,omitempty"`
+	TlsStore       map[string]*TLS `protobuf:"bytes,6,rep,name=tls_store,json=tlsStore,proto3" json:"tls_store,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	MethodOverride *MethodOverride `protobuf:"bytes,7,opt,name=method_override,json=methodOverride,proto3" json:"method_override,omitempty"`
+	// catch-all endpoint which handles any request not matched by the endpoints,
+	// its path, method and host are ignored
+	DefaultEndpoint *Endpoint `protobuf:"bytes,8,opt,name=default_endpoint,json=defaultEndpoint,proto3" json:"default_endpoint,omitempty"`
+	// discovery services the gateway can't serve without, /readyz reports not ready
+	// while any of them has no instance in discovery
+	CriticalServices []string `protobuf:"bytes,9,rep,name=critical_services,json=criticalServices,proto3" json:"critical_services,omitempty"`
+	// reusable endpoint settings, referenced by name in Endpoint.template
+	EndpointTemplates map[string]*Endpoint `protobuf:"bytes,10,rep,name=endpoint_templates,json=endpointTemplates,proto3" json:"endpoint_templates,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// routing tables of the tenants, the endpoints above are shared by all tenants as the default
+	TenantRouting *TenantRouting `protobuf:"bytes,11,opt,name=tenant_routing,json=tenantRouting,proto3" json:"tenant_routing,omitempty"`
+	// logical apps served under path prefixes, each with its own middlewares and backends
+	VirtualHosts []*VirtualHost `protobuf:"bytes,12,rep,name=virtual_hosts,json=virtualHosts,proto3" json:"virtual_hosts,omitempty"`
+	// answer the OPTIONS requests which aren't CORS preflights with 204 and an Allow header listing the methods
+	// of all the endpoints matching the path, without hitting the backends. Paths matched by an endpoint accepting
+	// any method are still forwarded
+	AutoOptions bool `protobuf:"varint,13,opt,name=auto_options,json=autoOptions,proto3" json:"auto_options,omitempty"`
 }
 
 func (x *Gateway) Reset() {
@@ -160,20 +477,74 @@ func (x *Gateway) GetTlsStore() map[string]*TLS {
 	return nil
 }
 
-type TLS struct {
+func (x *Gateway) GetMethodOverride() *MethodOverride {
+	if x != nil {
+		return x.MethodOverride
+	}
+	return nil
+}
+
+func (x *Gateway) GetDefaultEndpoint() *Endpoint {
+	if x != nil {
+		return x.DefaultEndpoint
+	}
+	return nil
+}
+
+func (x *Gateway) GetCriticalServices() []string {
+	if x != nil {
+		return x.CriticalServices
+	}
+	return nil
+}
+
+func (x *Gateway) GetEndpointTemplates() map[string]*Endpoint {
+	if x != nil {
+		return x.EndpointTemplates
+	}
+	return nil
+}
+
+func (x *Gateway) GetTenantRouting() *TenantRouting {
+	if x != nil {
+		return x.TenantRouting
+	}
+	return nil
+}
+
+func (x *Gateway) GetVirtualHosts() []*VirtualHost {
+	if x != nil {
+		return x.VirtualHosts
+	}
+	return nil
+}
+
+func (x *Gateway) GetAutoOptions() bool {
+	if x != nil {
+		return x.AutoOptions
+	}
+	return false
+}
+
+// VirtualHost serves a logical app under a path prefix of the shared host. Its endpoints use the middlewares of the
+// virtual host instead of the gateway level ones, and take precedence over the shared endpoints.
+type VirtualHost struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Insecure   bool   `protobuf:"varint,1,opt,name=insecure,proto3" json:"insecure,omitempty"`
-	Cacert     string `protobuf:"bytes,2,opt,name=cacert,proto3" json:"cacert,omitempty"`
-	Cert       string `protobuf:"bytes,3,opt,name=cert,proto3" json:"cert,omitempty"`
-	Key        string `protobuf:"bytes,4,opt,name=key,proto3" json:"key,omitempty"`
-	ServerName string `protobuf:"bytes,5,opt,name=server_name,json=serverName,proto3" json:"server_name,omitempty"`
+	// eg: /app1
+	PathPrefix  string        `protobuf:"bytes,1,opt,name=path_prefix,json=pathPrefix,proto3" json:"path_prefix,omitempty"`
+	Middlewares []*Middleware `protobuf:"bytes,2,rep,name=middlewares,proto3" json:"middlewares,omitempty"`
+	// the backends of the endpoints which don't have their own
+	Backends []*Backend `protobuf:"bytes,3,rep,name=backends,proto3" json:"backends,omitempty"`
+	// the paths are relative to the prefix, eg: /users is served as /app1/users,
+	// everything under the prefix is forwarded to the backends when empty
+	Endpoints []*Endpoint `protobuf:"bytes,4,rep,name=endpoints,proto3" json:"endpoints,omitempty"`
 }
 
-func (x *TLS) Reset() {
-	*x = TLS{}
+func (x *VirtualHost) Reset() {
+	*x = VirtualHost{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_gateway_config_v1_gateway_proto_msgTypes[1]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -181,13 +552,13 @@ func (x *TLS) Reset() {
 	}
 }
 
-func (x *TLS) String() string {
+func (x *VirtualHost) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TLS) ProtoMessage() {}
+func (*VirtualHost) ProtoMessage() {}
 
-func (x *TLS) ProtoReflect() protoreflect.Message {
+func (x *VirtualHost) ProtoReflect() protoreflect.Message {
 	mi := &file_gateway_config_v1_gateway_proto_msgTypes[1]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -199,58 +570,56 @@ func (x *TLS) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TLS.ProtoReflect.Descriptor instead.
-func (*TLS) Descriptor() ([]byte, []int) {
+// Deprecated: Use VirtualHost.ProtoReflect.Descriptor instead.
+func (*VirtualHost) Descriptor() ([]byte, []int) {
 	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{1}
 }
 
-func (x *TLS) GetInsecure() bool {
-	if x != nil {
-		return x.Insecure
-	}
-	return false
-}
-
-func (x *TLS) GetCacert() string {
+func (x *VirtualHost) GetPathPrefix() string {
 	if x != nil {
-		return x.Cacert
+		return x.PathPrefix
 	}
 	return ""
 }
 
-func (x *TLS) GetCert() string {
+func (x *VirtualHost) GetMiddlewares() []*Middleware {
 	if x != nil {
-		return x.Cert
+		return x.Middlewares
 	}
-	return ""
+	return nil
 }
 
-func (x *TLS) GetKey() string {
+func (x *VirtualHost) GetBackends() []*Backend {
 	if x != nil {
-		return x.Key
+		return x.Backends
 	}
-	return ""
+	return nil
 }
 
-func (x *TLS) GetServerName() string {
+func (x *VirtualHost) GetEndpoints() []*Endpoint {
 	if x != nil {
-		return x.ServerName
+		return x.Endpoints
 	}
-	return ""
+	return nil
 }
 
-type PriorityConfig struct {
+// TenantRouting routes the requests of a tenant against its own endpoints first, then the shared endpoints.
+type TenantRouting struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Name      string      `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Version   string      `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
-	Endpoints []*Endpoint `protobuf:"bytes,3,rep,name=endpoints,proto3" json:"endpoints,omitempty"`
+	// the request header carrying the tenant id, default is X-Tenant-Id
+	Header string `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	// resolve the tenant id from the subdomain of this domain when the header is absent,
+	// eg: the tenant id of acme.example.com is acme for example.com
+	Domain string `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	// keyed by the tenant id
+	Tenants map[string]*Tenant `protobuf:"bytes,3,rep,name=tenants,proto3" json:"tenants,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 }
 
-func (x *PriorityConfig) Reset() {
-	*x = PriorityConfig{}
+func (x *TenantRouting) Reset() {
+	*x = TenantRouting{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_gateway_config_v1_gateway_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -258,13 +627,13 @@ func (x *PriorityConfig) Reset() {
 	}
 }
 
-func (x *PriorityConfig) String() string {
+func (x *TenantRouting) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PriorityConfig) ProtoMessage() {}
+func (*TenantRouting) ProtoMessage() {}
 
-func (x *PriorityConfig) ProtoReflect() protoreflect.Message {
+func (x *TenantRouting) ProtoReflect() protoreflect.Message {
 	mi := &file_gateway_config_v1_gateway_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -276,51 +645,42 @@ func (x *PriorityConfig) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PriorityConfig.ProtoReflect.Descriptor instead.
-func (*PriorityConfig) Descriptor() ([]byte, []int) {
+// Deprecated: Use TenantRouting.ProtoReflect.Descriptor instead.
+func (*TenantRouting) Descriptor() ([]byte, []int) {
 	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *PriorityConfig) GetName() string {
+func (x *TenantRouting) GetHeader() string {
 	if x != nil {
-		return x.Name
+		return x.Header
 	}
 	return ""
 }
 
-func (x *PriorityConfig) GetVersion() string {
+func (x *TenantRouting) GetDomain() string {
 	if x != nil {
-		return x.Version
+		return x.Domain
 	}
 	return ""
 }
 
-func (x *PriorityConfig) GetEndpoints() []*Endpoint {
+func (x *TenantRouting) GetTenants() map[string]*Tenant {
 	if x != nil {
-		return x.Endpoints
+		return x.Tenants
 	}
 	return nil
 }
 
-type Endpoint struct {
+type Tenant struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Path        string               `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
-	Method      string               `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
-	Description string               `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
-	Protocol    Protocol             `protobuf:"varint,4,opt,name=protocol,proto3,enum=gateway.config.v1.Protocol" json:"protocol,omitempty"`
-	Timeout     *durationpb.Duration `protobuf:"bytes,5,opt,name=timeout,proto3" json:"timeout,omitempty"`
-	Middlewares []*Middleware        `protobuf:"bytes,6,rep,name=middlewares,proto3" json:"middlewares,omitempty"`
-	Backends    []*Backend           `protobuf:"bytes,7,rep,name=backends,proto3" json:"backends,omitempty"`
-	Retry       *Retry               `protobuf:"bytes,8,opt,name=retry,proto3" json:"retry,omitempty"`
-	Metadata    map[string]string    `protobuf:"bytes,9,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
-	Host        string               `protobuf:"bytes,10,opt,name=host,proto3" json:"host,omitempty"`
+	Endpoints []*Endpoint `protobuf:"bytes,1,rep,name=endpoints,proto3" json:"endpoints,omitempty"`
 }
 
-func (x *Endpoint) Reset() {
-	*x = Endpoint{}
+func (x *Tenant) Reset() {
+	*x = Tenant{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_gateway_config_v1_gateway_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -328,13 +688,13 @@ func (x *Endpoint) Reset() {
 	}
 }
 
-func (x *Endpoint) String() string {
+func (x *Tenant) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Endpoint) ProtoMessage() {}
+func (*Tenant) ProtoMessage() {}
 
-func (x *Endpoint) ProtoReflect() protoreflect.Message {
+func (x *Tenant) ProtoReflect() protoreflect.Message {
 	mi := &file_gateway_config_v1_gateway_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -346,93 +706,31 @@ func (x *Endpoint) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Endpoint.ProtoReflect.Descriptor instead.
-func (*Endpoint) Descriptor() ([]byte, []int) {
+// Deprecated: Use Tenant.ProtoReflect.Descriptor instead.
+func (*Tenant) Descriptor() ([]byte, []int) {
 	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *Endpoint) GetPath() string {
-	if x != nil {
-		return x.Path
-	}
-	return ""
-}
-
-func (x *Endpoint) GetMethod() string {
-	if x != nil {
-		return x.Method
-	}
-	return ""
-}
-
-func (x *Endpoint) GetDescription() string {
-	if x != nil {
-		return x.Description
-	}
-	return ""
-}
-
-func (x *Endpoint) GetProtocol() Protocol {
-	if x != nil {
-		return x.Protocol
-	}
-	return Protocol_UNSPECIFIED
-}
-
-func (x *Endpoint) GetTimeout() *durationpb.Duration {
-	if x != nil {
-		return x.Timeout
-	}
-	return nil
-}
-
-func (x *Endpoint) GetMiddlewares() []*Middleware {
-	if x != nil {
-		return x.Middlewares
-	}
-	return nil
-}
-
-func (x *Endpoint) GetBackends() []*Backend {
-	if x != nil {
-		return x.Backends
-	}
-	return nil
-}
-
-func (x *Endpoint) GetRetry() *Retry {
-	if x != nil {
-		return x.Retry
-	}
-	return nil
-}
-
-func (x *Endpoint) GetMetadata() map[string]string {
+func (x *Tenant) GetEndpoints() []*Endpoint {
 	if x != nil {
-		return x.Metadata
+		return x.Endpoints
 	}
 	return nil
 }
 
-func (x *Endpoint) GetHost() string {
-	if x != nil {
-		return x.Host
-	}
-	return ""
-}
-
-type Middleware struct {
+type MethodOverride struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Name     string     `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	Options  *anypb.Any `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
-	Required bool       `protobuf:"varint,3,opt,name=required,proto3" json:"required,omitempty"`
+	// the header which carries the overridden method, default is X-HTTP-Method-Override
+	Header string `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	// methods which are allowed to override to, eg: PUT, PATCH, DELETE
+	AllowedMethods []string `protobuf:"bytes,2,rep,name=allowed_methods,json=allowedMethods,proto3" json:"allowed_methods,omitempty"`
 }
 
-func (x *Middleware) Reset() {
-	*x = Middleware{}
+func (x *MethodOverride) Reset() {
+	*x = MethodOverride{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_gateway_config_v1_gateway_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -440,13 +738,13 @@ func (x *Middleware) Reset() {
 	}
 }
 
-func (x *Middleware) String() string {
+func (x *MethodOverride) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Middleware) ProtoMessage() {}
+func (*MethodOverride) ProtoMessage() {}
 
-func (x *Middleware) ProtoReflect() protoreflect.Message {
+func (x *MethodOverride) ProtoReflect() protoreflect.Message {
 	mi := &file_gateway_config_v1_gateway_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -458,50 +756,41 @@ func (x *Middleware) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Middleware.ProtoReflect.Descriptor instead.
-func (*Middleware) Descriptor() ([]byte, []int) {
+// Deprecated: Use MethodOverride.ProtoReflect.Descriptor instead.
+func (*MethodOverride) Descriptor() ([]byte, []int) {
 	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *Middleware) GetName() string {
+func (x *MethodOverride) GetHeader() string {
 	if x != nil {
-		return x.Name
+		return x.Header
 	}
 	return ""
 }
 
-func (x *Middleware) GetOptions() *anypb.Any {
+func (x *MethodOverride) GetAllowedMethods() []string {
 	if x != nil {
-		return x.Options
+		return x.AllowedMethods
 	}
 	return nil
 }
 
-func (x *Middleware) GetRequired() bool {
-	if x != nil {
-		return x.Required
-	}
-	return false
-}
-
-type Backend struct {
+// cacert, cert and key are either inline PEM or a secret reference like vault://path#field,
+// references are resolved every time the config is built
+type TLS struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// localhost
-	// 127.0.0.1:8000
-	// discovery:///service_name
-	Target        string            `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
-	Weight        *int64            `protobuf:"varint,2,opt,name=weight,proto3,oneof" json:"weight,omitempty"`
-	HealthCheck   *HealthCheck      `protobuf:"bytes,3,opt,name=health_check,json=healthCheck,proto3" json:"health_check,omitempty"`
-	Tls           bool              `protobuf:"varint,4,opt,name=tls,proto3" json:"tls,omitempty"`
-	TlsConfigName string            `protobuf:"bytes,5,opt,name=tls_config_name,json=tlsConfigName,proto3" json:"tls_config_name,omitempty"`
-	Metadata      map[string]string `protobuf:"bytes,6,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Insecure   bool   `protobuf:"varint,1,opt,name=insecure,proto3" json:"insecure,omitempty"`
+	Cacert     string `protobuf:"bytes,2,opt,name=cacert,proto3" json:"cacert,omitempty"`
+	Cert       string `protobuf:"bytes,3,opt,name=cert,proto3" json:"cert,omitempty"`
+	Key        string `protobuf:"bytes,4,opt,name=key,proto3" json:"key,omitempty"`
+	ServerName string `protobuf:"bytes,5,opt,name=server_name,json=serverName,proto3" json:"server_name,omitempty"`
 }
 
-func (x *Backend) Reset() {
-	*x = Backend{}
+func (x *TLS) Reset() {
+	*x = TLS{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_gateway_config_v1_gateway_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -509,13 +798,13 @@ func (x *Backend) Reset() {
 	}
 }
 
-func (x *Backend) String() string {
+func (x *TLS) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Backend) ProtoMessage() {}
+func (*TLS) ProtoMessage() {}
 
-func (x *Backend) ProtoReflect() protoreflect.Message {
+func (x *TLS) ProtoReflect() protoreflect.Message {
 	mi := &file_gateway_config_v1_gateway_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -527,61 +816,58 @@ func (x *Backend) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Backend.ProtoReflect.Descriptor instead.
-func (*Backend) Descriptor() ([]byte, []int) {
+// Deprecated: Use TLS.ProtoReflect.Descriptor instead.
+func (*TLS) Descriptor() ([]byte, []int) {
 	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *Backend) GetTarget() string {
+func (x *TLS) GetInsecure() bool {
 	if x != nil {
-		return x.Target
-	}
-	return ""
-}
-
-func (x *Backend) GetWeight() int64 {
-	if x != nil && x.Weight != nil {
-		return *x.Weight
+		return x.Insecure
 	}
-	return 0
+	return false
 }
 
-func (x *Backend) GetHealthCheck() *HealthCheck {
+func (x *TLS) GetCacert() string {
 	if x != nil {
-		return x.HealthCheck
+		return x.Cacert
 	}
-	return nil
+	return ""
 }
 
-func (x *Backend) GetTls() bool {
+func (x *TLS) GetCert() string {
 	if x != nil {
-		return x.Tls
+		return x.Cert
 	}
-	return false
+	return ""
 }
 
-func (x *Backend) GetTlsConfigName() string {
+func (x *TLS) GetKey() string {
 	if x != nil {
-		return x.TlsConfigName
+		return x.Key
 	}
 	return ""
 }
 
-func (x *Backend) GetMetadata() map[string]string {
+func (x *TLS) GetServerName() string {
 	if x != nil {
-		return x.Metadata
+		return x.ServerName
 	}
-	return nil
+	return ""
 }
 
-type HealthCheck struct {
+type PriorityConfig struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Name      string      `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Version   string      `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	Endpoints []*Endpoint `protobuf:"bytes,3,rep,name=endpoints,proto3" json:"endpoints,omitempty"`
 }
 
-func (x *HealthCheck) Reset() {
-	*x = HealthCheck{}
+func (x *PriorityConfig) Reset() {
+	*x = PriorityConfig{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_gateway_config_v1_gateway_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -589,13 +875,13 @@ func (x *HealthCheck) Reset() {
 	}
 }
 
-func (x *HealthCheck) String() string {
+func (x *PriorityConfig) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*HealthCheck) ProtoMessage() {}
+func (*PriorityConfig) ProtoMessage() {}
 
-func (x *HealthCheck) ProtoReflect() protoreflect.Message {
+func (x *PriorityConfig) ProtoReflect() protoreflect.Message {
 	mi := &file_gateway_config_v1_gateway_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -607,26 +893,104 @@ func (x *HealthCheck) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use HealthCheck.ProtoReflect.Descriptor instead.
-func (*HealthCheck) Descriptor() ([]byte, []int) {
+// Deprecated: Use PriorityConfig.ProtoReflect.Descriptor instead.
+func (*PriorityConfig) Descriptor() ([]byte, []int) {
 	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{6}
 }
 
-type Retry struct {
+func (x *PriorityConfig) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *PriorityConfig) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *PriorityConfig) GetEndpoints() []*Endpoint {
+	if x != nil {
+		return x.Endpoints
+	}
+	return nil
+}
+
+type Endpoint struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	// default attempts is 1
-	Attempts      uint32               `protobuf:"varint,1,opt,name=attempts,proto3" json:"attempts,omitempty"`
-	PerTryTimeout *durationpb.Duration `protobuf:"bytes,2,opt,name=per_try_timeout,json=perTryTimeout,proto3" json:"per_try_timeout,omitempty"`
-	Conditions    []*Condition         `protobuf:"bytes,3,rep,name=conditions,proto3" json:"conditions,omitempty"`
-	// primary,secondary
-	Priorities []string `protobuf:"bytes,4,rep,name=priorities,proto3" json:"priorities,omitempty"`
+	Path        string               `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	Method      string               `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+	Description string               `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Protocol    Protocol             `protobuf:"varint,4,opt,name=protocol,proto3,enum=gateway.config.v1.Protocol" json:"protocol,omitempty"`
+	Timeout     *durationpb.Duration `protobuf:"bytes,5,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	Middlewares []*Middleware        `protobuf:"bytes,6,rep,name=middlewares,proto3" json:"middlewares,omitempty"`
+	Backends    []*Backend           `protobuf:"bytes,7,rep,name=backends,proto3" json:"backends,omitempty"`
+	Retry       *Retry               `protobuf:"bytes,8,opt,name=retry,proto3" json:"retry,omitempty"`
+	Metadata    map[string]string    `protobuf:"bytes,9,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Host        string               `protobuf:"bytes,10,opt,name=host,proto3" json:"host,omitempty"`
+	// drop the request body of methods which should not carry one, eg: GET, HEAD
+	DropRequestBody bool     `protobuf:"varint,11,opt,name=drop_request_body,json=dropRequestBody,proto3" json:"drop_request_body,omitempty"`
+	Metrics         *Metrics `protobuf:"bytes,12,opt,name=metrics,proto3" json:"metrics,omitempty"`
+	// how to handle the request path which differs from the endpoint path only in the trailing slash
+	TrailingSlash TrailingSlash `protobuf:"varint,13,opt,name=trailing_slash,json=trailingSlash,proto3,enum=gateway.config.v1.TrailingSlash" json:"trailing_slash,omitempty"`
+	// the request must match all the header predicates to be routed to this endpoint,
+	// endpoints are matched in order, so put the more specific one first
+	Headers []*HeaderMatch `protobuf:"bytes,14,rep,name=headers,proto3" json:"headers,omitempty"`
+	// the request must match all the query parameter predicates to be routed to this endpoint,
+	// endpoints are matched in order, so put the more specific one first
+	Queries []*QueryMatch `protobuf:"bytes,15,rep,name=queries,proto3" json:"queries,omitempty"`
+	// how to pick the backend node, the global p2c balancer is used if not set
+	LoadBalancer *LoadBalancer `protobuf:"bytes,16,opt,name=load_balancer,json=loadBalancer,proto3" json:"load_balancer,omitempty"`
+	// limit the size of the response body returned to the client
+	ResponseLimit *ResponseLimit `protobuf:"bytes,17,opt,name=response_limit,json=responseLimit,proto3" json:"response_limit,omitempty"`
+	// the maximum length of the request uri including the query, requests exceeding it get 414,
+	// 0 means the global limit which is set by the PROXY_MAX_URL_LENGTH env
+	MaxUrlLength int64 `protobuf:"varint,18,opt,name=max_url_length,json=maxUrlLength,proto3" json:"max_url_length,omitempty"`
+	// name of the endpoint template this endpoint is based on, it's expanded when the config is loaded:
+	// fields set on the endpoint override the template, repeated fields like middlewares are appended to the template's
+	Template string `protobuf:"bytes,19,opt,name=template,proto3" json:"template,omitempty"`
+	// route requests to the backend group named in a request header
+	BackendGroup *BackendGroupRouting `protobuf:"bytes,20,opt,name=backend_group,json=backendGroup,proto3" json:"backend_group,omitempty"`
+	// buffer large request bodies to a temp file instead of memory so they can still be replayed on retries
+	RequestBuffering *RequestBuffering `protobuf:"bytes,21,opt,name=request_buffering,json=requestBuffering,proto3" json:"request_buffering,omitempty"`
+	// temporarily avoid the backend nodes which keep failing
+	OutlierDetection *OutlierDetection `protobuf:"bytes,22,opt,name=outlier_detection,json=outlierDetection,proto3" json:"outlier_detection,omitempty"`
+	// serve HEAD requests to a GET endpoint by sending GET upstream and discarding the response body,
+	// for the backends which don't implement HEAD
+	HeadAsGet bool `protobuf:"varint,23,opt,name=head_as_get,json=headAsGet,proto3" json:"head_as_get,omitempty"`
+	// the methods accepted besides method, only these methods are accepted when method is empty
+	AllowedMethods []string `protobuf:"bytes,24,rep,name=allowed_methods,json=allowedMethods,proto3" json:"allowed_methods,omitempty"`
+	// the response to the requests on the endpoint path with a method it doesn't accept, default is a plain 405,
+	// the Allow header always lists the methods accepted on the path
+	MethodNotAllowed *RejectResponse `protobuf:"bytes,25,opt,name=method_not_allowed,json=methodNotAllowed,proto3" json:"method_not_allowed,omitempty"`
+	// set the Content-Type of the responses without it by sniffing the first bytes of the body,
+	// it only waits for the first read from the upstream so streaming responses are not buffered
+	SniffContentType bool `protobuf:"varint,26,opt,name=sniff_content_type,json=sniffContentType,proto3" json:"sniff_content_type,omitempty"`
+	// buffer the responses without Content-Length up to a threshold, so the small ones get a Content-Length
+	// and the large ones are streamed chunked
+	ResponseBuffering *ResponseBuffering `protobuf:"bytes,27,opt,name=response_buffering,json=responseBuffering,proto3" json:"response_buffering,omitempty"`
+	// treat upstream 429s as backpressure and send less to the backend node which responds them
+	Backpressure *Backpressure `protobuf:"bytes,28,opt,name=backpressure,proto3" json:"backpressure,omitempty"`
+	// cap the size of the headers forwarded to the upstream, after the gateway and middlewares added theirs
+	UpstreamHeaderLimit *UpstreamHeaderLimit `protobuf:"bytes,29,opt,name=upstream_header_limit,json=upstreamHeaderLimit,proto3" json:"upstream_header_limit,omitempty"`
+	// track whether the requests meet the latency objective
+	LatencySlo *LatencySLO `protobuf:"bytes,30,opt,name=latency_slo,json=latencySlo,proto3" json:"latency_slo,omitempty"`
+	// act on the traffic of the endpoint while its error rate exceeds the budget
+	ErrorBudget *ErrorBudget `protobuf:"bytes,31,opt,name=error_budget,json=errorBudget,proto3" json:"error_budget,omitempty"`
+	// route the requests to other backends during scheduled windows, the first active schedule wins
+	BackendSchedules []*BackendSchedule `protobuf:"bytes,32,rep,name=backend_schedules,json=backendSchedules,proto3" json:"backend_schedules,omitempty"`
+	// also send the write requests to secondary backends, eg: while migrating to a new system
+	DualWrite *DualWrite `protobuf:"bytes,33,opt,name=dual_write,json=dualWrite,proto3" json:"dual_write,omitempty"`
 }
 
-func (x *Retry) Reset() {
-	*x = Retry{}
+func (x *Endpoint) Reset() {
+	*x = Endpoint{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_gateway_config_v1_gateway_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -634,13 +998,13 @@ func (x *Retry) Reset() {
 	}
 }
 
-func (x *Retry) String() string {
+func (x *Endpoint) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*Retry) ProtoMessage() {}
+func (*Endpoint) ProtoMessage() {}
 
-func (x *Retry) ProtoReflect() protoreflect.Message {
+func (x *Endpoint) ProtoReflect() protoreflect.Message {
 	mi := &file_gateway_config_v1_gateway_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -652,35 +1016,2220 @@ func (x *Retry) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use Retry.ProtoReflect.Descriptor instead.
-func (*Retry) Descriptor() ([]byte, []int) {
+// Deprecated: Use Endpoint.ProtoReflect.Descriptor instead.
+func (*Endpoint) Descriptor() ([]byte, []int) {
 	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *Retry) GetAttempts() uint32 {
+func (x *Endpoint) GetPath() string {
 	if x != nil {
-		return x.Attempts
+		return x.Path
 	}
-	return 0
+	return ""
 }
 
-func (x *Retry) GetPerTryTimeout() *durationpb.Duration {
+func (x *Endpoint) GetMethod() string {
 	if x != nil {
-		return x.PerTryTimeout
+		return x.Method
 	}
-	return nil
+	return ""
 }
 
-func (x *Retry) GetConditions() []*Condition {
+func (x *Endpoint) GetDescription() string {
 	if x != nil {
-		return x.Conditions
+		return x.Description
 	}
-	return nil
+	return ""
 }
 
-func (x *Retry) GetPriorities() []string {
+func (x *Endpoint) GetProtocol() Protocol {
 	if x != nil {
-		return x.Priorities
+		return x.Protocol
+	}
+	return Protocol_UNSPECIFIED
+}
+
+func (x *Endpoint) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+func (x *Endpoint) GetMiddlewares() []*Middleware {
+	if x != nil {
+		return x.Middlewares
+	}
+	return nil
+}
+
+func (x *Endpoint) GetBackends() []*Backend {
+	if x != nil {
+		return x.Backends
+	}
+	return nil
+}
+
+func (x *Endpoint) GetRetry() *Retry {
+	if x != nil {
+		return x.Retry
+	}
+	return nil
+}
+
+func (x *Endpoint) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Endpoint) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *Endpoint) GetDropRequestBody() bool {
+	if x != nil {
+		return x.DropRequestBody
+	}
+	return false
+}
+
+func (x *Endpoint) GetMetrics() *Metrics {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+func (x *Endpoint) GetTrailingSlash() TrailingSlash {
+	if x != nil {
+		return x.TrailingSlash
+	}
+	return TrailingSlash_TRAILING_SLASH_UNSPECIFIED
+}
+
+func (x *Endpoint) GetHeaders() []*HeaderMatch {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *Endpoint) GetQueries() []*QueryMatch {
+	if x != nil {
+		return x.Queries
+	}
+	return nil
+}
+
+func (x *Endpoint) GetLoadBalancer() *LoadBalancer {
+	if x != nil {
+		return x.LoadBalancer
+	}
+	return nil
+}
+
+func (x *Endpoint) GetResponseLimit() *ResponseLimit {
+	if x != nil {
+		return x.ResponseLimit
+	}
+	return nil
+}
+
+func (x *Endpoint) GetMaxUrlLength() int64 {
+	if x != nil {
+		return x.MaxUrlLength
+	}
+	return 0
+}
+
+func (x *Endpoint) GetTemplate() string {
+	if x != nil {
+		return x.Template
+	}
+	return ""
+}
+
+func (x *Endpoint) GetBackendGroup() *BackendGroupRouting {
+	if x != nil {
+		return x.BackendGroup
+	}
+	return nil
+}
+
+func (x *Endpoint) GetRequestBuffering() *RequestBuffering {
+	if x != nil {
+		return x.RequestBuffering
+	}
+	return nil
+}
+
+func (x *Endpoint) GetOutlierDetection() *OutlierDetection {
+	if x != nil {
+		return x.OutlierDetection
+	}
+	return nil
+}
+
+func (x *Endpoint) GetHeadAsGet() bool {
+	if x != nil {
+		return x.HeadAsGet
+	}
+	return false
+}
+
+func (x *Endpoint) GetAllowedMethods() []string {
+	if x != nil {
+		return x.AllowedMethods
+	}
+	return nil
+}
+
+func (x *Endpoint) GetMethodNotAllowed() *RejectResponse {
+	if x != nil {
+		return x.MethodNotAllowed
+	}
+	return nil
+}
+
+func (x *Endpoint) GetSniffContentType() bool {
+	if x != nil {
+		return x.SniffContentType
+	}
+	return false
+}
+
+func (x *Endpoint) GetResponseBuffering() *ResponseBuffering {
+	if x != nil {
+		return x.ResponseBuffering
+	}
+	return nil
+}
+
+func (x *Endpoint) GetBackpressure() *Backpressure {
+	if x != nil {
+		return x.Backpressure
+	}
+	return nil
+}
+
+func (x *Endpoint) GetUpstreamHeaderLimit() *UpstreamHeaderLimit {
+	if x != nil {
+		return x.UpstreamHeaderLimit
+	}
+	return nil
+}
+
+func (x *Endpoint) GetLatencySlo() *LatencySLO {
+	if x != nil {
+		return x.LatencySlo
+	}
+	return nil
+}
+
+func (x *Endpoint) GetErrorBudget() *ErrorBudget {
+	if x != nil {
+		return x.ErrorBudget
+	}
+	return nil
+}
+
+func (x *Endpoint) GetBackendSchedules() []*BackendSchedule {
+	if x != nil {
+		return x.BackendSchedules
+	}
+	return nil
+}
+
+func (x *Endpoint) GetDualWrite() *DualWrite {
+	if x != nil {
+		return x.DualWrite
+	}
+	return nil
+}
+
+// BackendSchedule routes the requests of the endpoint to its backends while any of its windows is active,
+// eg: a maintenance window or a scheduled cutover. The backends of the endpoint are used outside the windows.
+type BackendSchedule struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name     string            `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Windows  []*ScheduleWindow `protobuf:"bytes,2,rep,name=windows,proto3" json:"windows,omitempty"`
+	Backends []*Backend        `protobuf:"bytes,3,rep,name=backends,proto3" json:"backends,omitempty"`
+}
+
+func (x *BackendSchedule) Reset() {
+	*x = BackendSchedule{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BackendSchedule) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackendSchedule) ProtoMessage() {}
+
+func (x *BackendSchedule) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackendSchedule.ProtoReflect.Descriptor instead.
+func (*BackendSchedule) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *BackendSchedule) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *BackendSchedule) GetWindows() []*ScheduleWindow {
+	if x != nil {
+		return x.Windows
+	}
+	return nil
+}
+
+func (x *BackendSchedule) GetBackends() []*Backend {
+	if x != nil {
+		return x.Backends
+	}
+	return nil
+}
+
+// ScheduleWindow is active on the days from the start to the end time of day. It ends on the next day
+// if the end is not after the start, eg: 22:00 to 02:00 on SAT is active from Saturday 22:00 to Sunday 02:00.
+type ScheduleWindow struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// MON, TUE, WED, THU, FRI, SAT or SUN, every day if empty
+	Days []string `protobuf:"bytes,1,rep,name=days,proto3" json:"days,omitempty"`
+	// the time of day in HH:MM, the whole day if both the start and the end are empty
+	Start string `protobuf:"bytes,2,opt,name=start,proto3" json:"start,omitempty"`
+	End   string `protobuf:"bytes,3,opt,name=end,proto3" json:"end,omitempty"`
+	// the IANA time zone of the days and the times, eg: Asia/Shanghai, default is UTC
+	Timezone string `protobuf:"bytes,4,opt,name=timezone,proto3" json:"timezone,omitempty"`
+	// RFC 3339 times limiting the window, eg: a one-off cutover is a window with only not_before
+	NotBefore string `protobuf:"bytes,5,opt,name=not_before,json=notBefore,proto3" json:"not_before,omitempty"`
+	NotAfter  string `protobuf:"bytes,6,opt,name=not_after,json=notAfter,proto3" json:"not_after,omitempty"`
+}
+
+func (x *ScheduleWindow) Reset() {
+	*x = ScheduleWindow{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ScheduleWindow) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduleWindow) ProtoMessage() {}
+
+func (x *ScheduleWindow) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduleWindow.ProtoReflect.Descriptor instead.
+func (*ScheduleWindow) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ScheduleWindow) GetDays() []string {
+	if x != nil {
+		return x.Days
+	}
+	return nil
+}
+
+func (x *ScheduleWindow) GetStart() string {
+	if x != nil {
+		return x.Start
+	}
+	return ""
+}
+
+func (x *ScheduleWindow) GetEnd() string {
+	if x != nil {
+		return x.End
+	}
+	return ""
+}
+
+func (x *ScheduleWindow) GetTimezone() string {
+	if x != nil {
+		return x.Timezone
+	}
+	return ""
+}
+
+func (x *ScheduleWindow) GetNotBefore() string {
+	if x != nil {
+		return x.NotBefore
+	}
+	return ""
+}
+
+func (x *ScheduleWindow) GetNotAfter() string {
+	if x != nil {
+		return x.NotAfter
+	}
+	return ""
+}
+
+// ErrorBudget tracks the ratio of failed requests, either transport errors or 5xx responses, over a sliding window.
+// Once the ratio exceeds the budget, the action is applied to the given share of the traffic. The failures age out of
+// the window while the traffic is diverted, so the action is reverted after at most one window and triggered again
+// if the backends are still failing.
+type ErrorBudget struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the ratio of failed requests allowed, eg: 0.05
+	Budget float64 `protobuf:"fixed64,1,opt,name=budget,proto3" json:"budget,omitempty"`
+	// default is 1m
+	Window *durationpb.Duration `protobuf:"bytes,2,opt,name=window,proto3" json:"window,omitempty"`
+	// the budget is not checked until the window has this many requests, default is 20
+	MinRequests int64              `protobuf:"varint,3,opt,name=min_requests,json=minRequests,proto3" json:"min_requests,omitempty"`
+	Action      ErrorBudget_Action `protobuf:"varint,4,opt,name=action,proto3,enum=gateway.config.v1.ErrorBudget_Action" json:"action,omitempty"`
+	// the share of the traffic the action is applied to, default is 1
+	Weight float64 `protobuf:"fixed64,5,opt,name=weight,proto3" json:"weight,omitempty"`
+	// required by the FALLBACK action
+	FallbackBackends []*Backend `protobuf:"bytes,6,rep,name=fallback_backends,json=fallbackBackends,proto3" json:"fallback_backends,omitempty"`
+}
+
+func (x *ErrorBudget) Reset() {
+	*x = ErrorBudget{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ErrorBudget) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ErrorBudget) ProtoMessage() {}
+
+func (x *ErrorBudget) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ErrorBudget.ProtoReflect.Descriptor instead.
+func (*ErrorBudget) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ErrorBudget) GetBudget() float64 {
+	if x != nil {
+		return x.Budget
+	}
+	return 0
+}
+
+func (x *ErrorBudget) GetWindow() *durationpb.Duration {
+	if x != nil {
+		return x.Window
+	}
+	return nil
+}
+
+func (x *ErrorBudget) GetMinRequests() int64 {
+	if x != nil {
+		return x.MinRequests
+	}
+	return 0
+}
+
+func (x *ErrorBudget) GetAction() ErrorBudget_Action {
+	if x != nil {
+		return x.Action
+	}
+	return ErrorBudget_FALLBACK
+}
+
+func (x *ErrorBudget) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+func (x *ErrorBudget) GetFallbackBackends() []*Backend {
+	if x != nil {
+		return x.FallbackBackends
+	}
+	return nil
+}
+
+// DualWrite sends a copy of every write request of the endpoint to each secondary backend. The response of
+// the endpoint backends, the primary, is returned to the client and the results of the secondary writes are
+// logged and counted in dual_write_requests_total. A secondary write fails on an error or a 5xx response.
+type DualWrite struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Secondaries []*Backend `protobuf:"bytes,1,rep,name=secondaries,proto3" json:"secondaries,omitempty"`
+	// default is POST, PUT, PATCH and DELETE
+	Methods   []string                `protobuf:"bytes,2,rep,name=methods,proto3" json:"methods,omitempty"`
+	OnFailure DualWrite_FailurePolicy `protobuf:"varint,3,opt,name=on_failure,json=onFailure,proto3,enum=gateway.config.v1.DualWrite_FailurePolicy" json:"on_failure,omitempty"`
+	// the timeout of a background secondary write, default is the timeout of the endpoint
+	Timeout *durationpb.Duration `protobuf:"bytes,4,opt,name=timeout,proto3" json:"timeout,omitempty"`
+}
+
+func (x *DualWrite) Reset() {
+	*x = DualWrite{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[11]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DualWrite) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DualWrite) ProtoMessage() {}
+
+func (x *DualWrite) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[11]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DualWrite.ProtoReflect.Descriptor instead.
+func (*DualWrite) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *DualWrite) GetSecondaries() []*Backend {
+	if x != nil {
+		return x.Secondaries
+	}
+	return nil
+}
+
+func (x *DualWrite) GetMethods() []string {
+	if x != nil {
+		return x.Methods
+	}
+	return nil
+}
+
+func (x *DualWrite) GetOnFailure() DualWrite_FailurePolicy {
+	if x != nil {
+		return x.OnFailure
+	}
+	return DualWrite_IGNORE
+}
+
+func (x *DualWrite) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+// OutlierDetection ejects a node after consecutive errors or 5xx responses within the window, or when its failure
+// rate within the window is too high. The node is re-admitted after the ejection time and a single failure ejects it
+// again until a request succeeds. The ejection time is the cooldown multiplied by the times the node has been ejected,
+// which is forgotten once the node hasn't been ejected for max_cooldown.
+// Nodes are never ejected when that would leave no node to pick or more than max_ejection_percent of the nodes ejected.
+type OutlierDetection struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// default is 5
+	ConsecutiveErrors uint32 `protobuf:"varint,1,opt,name=consecutive_errors,json=consecutiveErrors,proto3" json:"consecutive_errors,omitempty"`
+	// the consecutive errors and the failure rate are counted within it, default is 10s
+	Window *durationpb.Duration `protobuf:"bytes,2,opt,name=window,proto3" json:"window,omitempty"`
+	// how long a node is avoided on its first ejection, default is 30s
+	Cooldown *durationpb.Duration `protobuf:"bytes,3,opt,name=cooldown,proto3" json:"cooldown,omitempty"`
+	// eject a node after consecutive 5xx responses, default is 5
+	ConsecutiveServerErrors uint32 `protobuf:"varint,4,opt,name=consecutive_server_errors,json=consecutiveServerErrors,proto3" json:"consecutive_server_errors,omitempty"`
+	// eject a node when the ratio of errors and 5xx responses reaches it, e.g. 0.5, 0 disables it
+	FailureRate float64 `protobuf:"fixed64,5,opt,name=failure_rate,json=failureRate,proto3" json:"failure_rate,omitempty"`
+	// the requests within the window needed before the failure rate is checked, default is 10
+	MinimumRequests uint32 `protobuf:"varint,6,opt,name=minimum_requests,json=minimumRequests,proto3" json:"minimum_requests,omitempty"`
+	// the upper bound of the growing ejection time, default is 300s
+	MaxCooldown *durationpb.Duration `protobuf:"bytes,7,opt,name=max_cooldown,json=maxCooldown,proto3" json:"max_cooldown,omitempty"`
+	// the maximum percentage of the nodes ejected at the same time, default is 50
+	MaxEjectionPercent uint32 `protobuf:"varint,8,opt,name=max_ejection_percent,json=maxEjectionPercent,proto3" json:"max_ejection_percent,omitempty"`
+}
+
+func (x *OutlierDetection) Reset() {
+	*x = OutlierDetection{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[12]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *OutlierDetection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OutlierDetection) ProtoMessage() {}
+
+func (x *OutlierDetection) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[12]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OutlierDetection.ProtoReflect.Descriptor instead.
+func (*OutlierDetection) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *OutlierDetection) GetConsecutiveErrors() uint32 {
+	if x != nil {
+		return x.ConsecutiveErrors
+	}
+	return 0
+}
+
+func (x *OutlierDetection) GetWindow() *durationpb.Duration {
+	if x != nil {
+		return x.Window
+	}
+	return nil
+}
+
+func (x *OutlierDetection) GetCooldown() *durationpb.Duration {
+	if x != nil {
+		return x.Cooldown
+	}
+	return nil
+}
+
+func (x *OutlierDetection) GetConsecutiveServerErrors() uint32 {
+	if x != nil {
+		return x.ConsecutiveServerErrors
+	}
+	return 0
+}
+
+func (x *OutlierDetection) GetFailureRate() float64 {
+	if x != nil {
+		return x.FailureRate
+	}
+	return 0
+}
+
+func (x *OutlierDetection) GetMinimumRequests() uint32 {
+	if x != nil {
+		return x.MinimumRequests
+	}
+	return 0
+}
+
+func (x *OutlierDetection) GetMaxCooldown() *durationpb.Duration {
+	if x != nil {
+		return x.MaxCooldown
+	}
+	return nil
+}
+
+func (x *OutlierDetection) GetMaxEjectionPercent() uint32 {
+	if x != nil {
+		return x.MaxEjectionPercent
+	}
+	return 0
+}
+
+// Backpressure caps the concurrent requests to a backend node once it responds 429, the cap is lowered on every 429
+// and raised back on the other responses until it's lifted. The node gets no requests until the Retry-After of
+// a 429 has passed. Requests are sent to the other nodes meanwhile, or shed with 503 when every node is saturated.
+type Backpressure struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the concurrency cap is multiplied by it on every 429, default is 0.5
+	DecreaseRatio float64 `protobuf:"fixed64,1,opt,name=decrease_ratio,json=decreaseRatio,proto3" json:"decrease_ratio,omitempty"`
+	// default is 1
+	MinConcurrency uint32 `protobuf:"varint,2,opt,name=min_concurrency,json=minConcurrency,proto3" json:"min_concurrency,omitempty"`
+	// the longest Retry-After which is honored, default is 60s
+	MaxRetryAfter *durationpb.Duration `protobuf:"bytes,3,opt,name=max_retry_after,json=maxRetryAfter,proto3" json:"max_retry_after,omitempty"`
+}
+
+func (x *Backpressure) Reset() {
+	*x = Backpressure{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Backpressure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Backpressure) ProtoMessage() {}
+
+func (x *Backpressure) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Backpressure.ProtoReflect.Descriptor instead.
+func (*Backpressure) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Backpressure) GetDecreaseRatio() float64 {
+	if x != nil {
+		return x.DecreaseRatio
+	}
+	return 0
+}
+
+func (x *Backpressure) GetMinConcurrency() uint32 {
+	if x != nil {
+		return x.MinConcurrency
+	}
+	return 0
+}
+
+func (x *Backpressure) GetMaxRetryAfter() *durationpb.Duration {
+	if x != nil {
+		return x.MaxRetryAfter
+	}
+	return nil
+}
+
+// LatencySLO counts the requests of the endpoint slower than the threshold in slo_violations_total, and all of them in
+// slo_requests_total. The burn rate over a window is the violation ratio divided by the error budget, eg:
+// rate(slo_violations_total[1h]) / rate(slo_requests_total[1h]) / (1 - slo_objective)
+type LatencySLO struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// eg: 300ms
+	Threshold *durationpb.Duration `protobuf:"bytes,1,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	// the ratio of requests expected to be within the threshold, exported as the slo_objective gauge, default is 0.99
+	Objective float64 `protobuf:"fixed64,2,opt,name=objective,proto3" json:"objective,omitempty"`
+}
+
+func (x *LatencySLO) Reset() {
+	*x = LatencySLO{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[14]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LatencySLO) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LatencySLO) ProtoMessage() {}
+
+func (x *LatencySLO) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[14]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LatencySLO.ProtoReflect.Descriptor instead.
+func (*LatencySLO) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *LatencySLO) GetThreshold() *durationpb.Duration {
+	if x != nil {
+		return x.Threshold
+	}
+	return nil
+}
+
+func (x *LatencySLO) GetObjective() float64 {
+	if x != nil {
+		return x.Objective
+	}
+	return 0
+}
+
+// UpstreamHeaderLimit caps the total size of the request headers forwarded to the upstream, counted as
+// name + ": " + value + CRLF for every value. The trimmable headers are removed in order until the headers fit,
+// the request fails with 431 if they still don't fit.
+type UpstreamHeaderLimit struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	MaxBytes int64 `protobuf:"varint,1,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`
+	// non-essential headers which can be dropped, eg: X-B3-Sampled, X-Forwarded-For
+	TrimmableHeaders []string `protobuf:"bytes,2,rep,name=trimmable_headers,json=trimmableHeaders,proto3" json:"trimmable_headers,omitempty"`
+}
+
+func (x *UpstreamHeaderLimit) Reset() {
+	*x = UpstreamHeaderLimit{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[15]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *UpstreamHeaderLimit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpstreamHeaderLimit) ProtoMessage() {}
+
+func (x *UpstreamHeaderLimit) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[15]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpstreamHeaderLimit.ProtoReflect.Descriptor instead.
+func (*UpstreamHeaderLimit) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *UpstreamHeaderLimit) GetMaxBytes() int64 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+func (x *UpstreamHeaderLimit) GetTrimmableHeaders() []string {
+	if x != nil {
+		return x.TrimmableHeaders
+	}
+	return nil
+}
+
+type RequestBuffering struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// request bodies larger than it are buffered to a temp file, 0 means always buffer in memory
+	MaxMemoryBytes int64 `protobuf:"varint,1,opt,name=max_memory_bytes,json=maxMemoryBytes,proto3" json:"max_memory_bytes,omitempty"`
+	// directory of the temp files, defaults to the system temp directory
+	TempDir string `protobuf:"bytes,2,opt,name=temp_dir,json=tempDir,proto3" json:"temp_dir,omitempty"`
+}
+
+func (x *RequestBuffering) Reset() {
+	*x = RequestBuffering{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[16]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RequestBuffering) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestBuffering) ProtoMessage() {}
+
+func (x *RequestBuffering) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[16]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestBuffering.ProtoReflect.Descriptor instead.
+func (*RequestBuffering) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RequestBuffering) GetMaxMemoryBytes() int64 {
+	if x != nil {
+		return x.MaxMemoryBytes
+	}
+	return 0
+}
+
+func (x *RequestBuffering) GetTempDir() string {
+	if x != nil {
+		return x.TempDir
+	}
+	return ""
+}
+
+type ResponseBuffering struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// responses up to it are sent with a Content-Length, larger ones are streamed chunked
+	ThresholdBytes int64 `protobuf:"varint,1,opt,name=threshold_bytes,json=thresholdBytes,proto3" json:"threshold_bytes,omitempty"`
+}
+
+func (x *ResponseBuffering) Reset() {
+	*x = ResponseBuffering{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResponseBuffering) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResponseBuffering) ProtoMessage() {}
+
+func (x *ResponseBuffering) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResponseBuffering.ProtoReflect.Descriptor instead.
+func (*ResponseBuffering) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ResponseBuffering) GetThresholdBytes() int64 {
+	if x != nil {
+		return x.ThresholdBytes
+	}
+	return 0
+}
+
+type ResponseLimit struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// 0 means no limit
+	MaxBytes int64                `protobuf:"varint,1,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"`
+	Action   ResponseLimit_Action `protobuf:"varint,2,opt,name=action,proto3,enum=gateway.config.v1.ResponseLimit_Action" json:"action,omitempty"`
+}
+
+func (x *ResponseLimit) Reset() {
+	*x = ResponseLimit{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResponseLimit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResponseLimit) ProtoMessage() {}
+
+func (x *ResponseLimit) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResponseLimit.ProtoReflect.Descriptor instead.
+func (*ResponseLimit) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ResponseLimit) GetMaxBytes() int64 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+func (x *ResponseLimit) GetAction() ResponseLimit_Action {
+	if x != nil {
+		return x.Action
+	}
+	return ResponseLimit_REJECT
+}
+
+// BackendGroupRouting restricts the selection to the backends of the group named in a request header,
+// the group of a backend is read from its metadata. Requests without the header are routed to the active group
+// if it's set. Requests naming a group without available backends are balanced across all the backends.
+type BackendGroupRouting struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// default is X-Route-Weight-Group
+	Header string `protobuf:"bytes,1,opt,name=header,proto3" json:"header,omitempty"`
+	// default is group
+	MetadataKey string `protobuf:"bytes,2,opt,name=metadata_key,json=metadataKey,proto3" json:"metadata_key,omitempty"`
+	// the group receiving all the requests without the header, for blue/green deploys.
+	// It can be switched to another group and rolled back at runtime, without a config change,
+	// through the debug handlers /debug/bluegreen/switch and /debug/bluegreen/rollback
+	Active string `protobuf:"bytes,3,opt,name=active,proto3" json:"active,omitempty"`
+}
+
+func (x *BackendGroupRouting) Reset() {
+	*x = BackendGroupRouting{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BackendGroupRouting) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackendGroupRouting) ProtoMessage() {}
+
+func (x *BackendGroupRouting) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackendGroupRouting.ProtoReflect.Descriptor instead.
+func (*BackendGroupRouting) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *BackendGroupRouting) GetHeader() string {
+	if x != nil {
+		return x.Header
+	}
+	return ""
+}
+
+func (x *BackendGroupRouting) GetMetadataKey() string {
+	if x != nil {
+		return x.MetadataKey
+	}
+	return ""
+}
+
+func (x *BackendGroupRouting) GetActive() string {
+	if x != nil {
+		return x.Active
+	}
+	return ""
+}
+
+// RejectResponse is the response returned when a protective middleware,
+// like a rate limiter or an open circuit breaker, rejects a request
+type RejectResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// default is decided by the middleware, eg: 429 for rate limiting and 503 for circuit breaking
+	StatusCode int32             `protobuf:"varint,1,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
+	Headers    map[string]string `protobuf:"bytes,2,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	Body       []byte            `protobuf:"bytes,3,opt,name=body,proto3" json:"body,omitempty"`
+}
+
+func (x *RejectResponse) Reset() {
+	*x = RejectResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RejectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RejectResponse) ProtoMessage() {}
+
+func (x *RejectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RejectResponse.ProtoReflect.Descriptor instead.
+func (*RejectResponse) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *RejectResponse) GetStatusCode() int32 {
+	if x != nil {
+		return x.StatusCode
+	}
+	return 0
+}
+
+func (x *RejectResponse) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *RejectResponse) GetBody() []byte {
+	if x != nil {
+		return x.Body
+	}
+	return nil
+}
+
+type LoadBalancer struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Policy LoadBalancer_Policy `protobuf:"varint,1,opt,name=policy,proto3,enum=gateway.config.v1.LoadBalancer_Policy" json:"policy,omitempty"`
+	// the header carrying the cost hint of the request, eg: X-Request-Cost
+	CostHeader string `protobuf:"bytes,2,opt,name=cost_header,json=costHeader,proto3" json:"cost_header,omitempty"`
+	// the cost of the requests whose path has the prefix, the longest prefix wins
+	PathCosts []*PathCost `protobuf:"bytes,3,rep,name=path_costs,json=pathCosts,proto3" json:"path_costs,omitempty"`
+	// the cost of the request without any hint, default 1
+	DefaultCost int64 `protobuf:"varint,4,opt,name=default_cost,json=defaultCost,proto3" json:"default_cost,omitempty"`
+	// the upper limit of the cost hint from the header, 0 means no limit
+	MaxCost int64 `protobuf:"varint,5,opt,name=max_cost,json=maxCost,proto3" json:"max_cost,omitempty"`
+	// ramp the traffic to the discovered nodes up after they join and down after they leave
+	SlowStart *SlowStart `protobuf:"bytes,6,opt,name=slow_start,json=slowStart,proto3" json:"slow_start,omitempty"`
+	// the name of a registered balancer which overrides the policy: p2c, random, round_robin, wrr or consistent_hash,
+	// an unknown name falls back to the default balancer with a warning
+	Name string `protobuf:"bytes,7,opt,name=name,proto3" json:"name,omitempty"`
+	// the header hashed by the consistent_hash balancer, the requests without it are hashed on the IP of the peer
+	// connected to the gateway, X-Forwarded-For is ignored since clients can set it freely
+	HashHeader string `protobuf:"bytes,8,opt,name=hash_header,json=hashHeader,proto3" json:"hash_header,omitempty"`
+}
+
+func (x *LoadBalancer) Reset() {
+	*x = LoadBalancer{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *LoadBalancer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoadBalancer) ProtoMessage() {}
+
+func (x *LoadBalancer) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoadBalancer.ProtoReflect.Descriptor instead.
+func (*LoadBalancer) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *LoadBalancer) GetPolicy() LoadBalancer_Policy {
+	if x != nil {
+		return x.Policy
+	}
+	return LoadBalancer_P2C
+}
+
+func (x *LoadBalancer) GetCostHeader() string {
+	if x != nil {
+		return x.CostHeader
+	}
+	return ""
+}
+
+func (x *LoadBalancer) GetPathCosts() []*PathCost {
+	if x != nil {
+		return x.PathCosts
+	}
+	return nil
+}
+
+func (x *LoadBalancer) GetDefaultCost() int64 {
+	if x != nil {
+		return x.DefaultCost
+	}
+	return 0
+}
+
+func (x *LoadBalancer) GetMaxCost() int64 {
+	if x != nil {
+		return x.MaxCost
+	}
+	return 0
+}
+
+func (x *LoadBalancer) GetSlowStart() *SlowStart {
+	if x != nil {
+		return x.SlowStart
+	}
+	return nil
+}
+
+func (x *LoadBalancer) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *LoadBalancer) GetHashHeader() string {
+	if x != nil {
+		return x.HashHeader
+	}
+	return ""
+}
+
+// SlowStart admits a joining node to a share of the selections growing linearly from min_weight to all of them
+// over the warmup, so the selector learns its latency before it takes the full load. A leaving node is kept
+// with a share shrinking to none over the cooldown. The nodes present when the endpoint is built are not ramped.
+type SlowStart struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Warmup *durationpb.Duration `protobuf:"bytes,1,opt,name=warmup,proto3" json:"warmup,omitempty"`
+	// 0 removes the leaving nodes immediately
+	Cooldown *durationpb.Duration `protobuf:"bytes,2,opt,name=cooldown,proto3" json:"cooldown,omitempty"`
+	// the share of a node at the start of the warmup, default is 0.1
+	MinWeight float64 `protobuf:"fixed64,3,opt,name=min_weight,json=minWeight,proto3" json:"min_weight,omitempty"`
+}
+
+func (x *SlowStart) Reset() {
+	*x = SlowStart{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SlowStart) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SlowStart) ProtoMessage() {}
+
+func (x *SlowStart) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SlowStart.ProtoReflect.Descriptor instead.
+func (*SlowStart) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *SlowStart) GetWarmup() *durationpb.Duration {
+	if x != nil {
+		return x.Warmup
+	}
+	return nil
+}
+
+func (x *SlowStart) GetCooldown() *durationpb.Duration {
+	if x != nil {
+		return x.Cooldown
+	}
+	return nil
+}
+
+func (x *SlowStart) GetMinWeight() float64 {
+	if x != nil {
+		return x.MinWeight
+	}
+	return 0
+}
+
+type PathCost struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Prefix string `protobuf:"bytes,1,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	Cost   int64  `protobuf:"varint,2,opt,name=cost,proto3" json:"cost,omitempty"`
+}
+
+func (x *PathCost) Reset() {
+	*x = PathCost{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PathCost) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PathCost) ProtoMessage() {}
+
+func (x *PathCost) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PathCost.ProtoReflect.Descriptor instead.
+func (*PathCost) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *PathCost) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *PathCost) GetCost() int64 {
+	if x != nil {
+		return x.Cost
+	}
+	return 0
+}
+
+type HeaderMatch struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// match the exact value, the header only needs to be present if both value and regex are empty
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// match the value with the regular expression
+	Regex string `protobuf:"bytes,3,opt,name=regex,proto3" json:"regex,omitempty"`
+}
+
+func (x *HeaderMatch) Reset() {
+	*x = HeaderMatch{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HeaderMatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeaderMatch) ProtoMessage() {}
+
+func (x *HeaderMatch) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeaderMatch.ProtoReflect.Descriptor instead.
+func (*HeaderMatch) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *HeaderMatch) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *HeaderMatch) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *HeaderMatch) GetRegex() string {
+	if x != nil {
+		return x.Regex
+	}
+	return ""
+}
+
+type QueryMatch struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// match the exact value, the parameter only needs to be present if both value and regex are empty
+	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// match the value with the regular expression
+	Regex string `protobuf:"bytes,3,opt,name=regex,proto3" json:"regex,omitempty"`
+}
+
+func (x *QueryMatch) Reset() {
+	*x = QueryMatch{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *QueryMatch) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryMatch) ProtoMessage() {}
+
+func (x *QueryMatch) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryMatch.ProtoReflect.Descriptor instead.
+func (*QueryMatch) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *QueryMatch) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *QueryMatch) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *QueryMatch) GetRegex() string {
+	if x != nil {
+		return x.Regex
+	}
+	return ""
+}
+
+type Metrics struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// disable all proxy metrics of the endpoint
+	DisableAll bool `protobuf:"varint,1,opt,name=disable_all,json=disableAll,proto3" json:"disable_all,omitempty"`
+	// disable the requests_code_total metric
+	DisableRequestsTotal bool `protobuf:"varint,2,opt,name=disable_requests_total,json=disableRequestsTotal,proto3" json:"disable_requests_total,omitempty"`
+	// disable the requests_duration_seconds metric
+	DisableRequestsDuration bool `protobuf:"varint,3,opt,name=disable_requests_duration,json=disableRequestsDuration,proto3" json:"disable_requests_duration,omitempty"`
+	// disable the requests_tx_bytes and requests_rx_bytes metrics
+	DisableBytes bool `protobuf:"varint,4,opt,name=disable_bytes,json=disableBytes,proto3" json:"disable_bytes,omitempty"`
+	// disable the requests_retry_state metric
+	DisableRetryState bool `protobuf:"varint,5,opt,name=disable_retry_state,json=disableRetryState,proto3" json:"disable_retry_state,omitempty"`
+	// disable the upstream_errors_total metric
+	DisableUpstreamErrors bool `protobuf:"varint,6,opt,name=disable_upstream_errors,json=disableUpstreamErrors,proto3" json:"disable_upstream_errors,omitempty"`
+}
+
+func (x *Metrics) Reset() {
+	*x = Metrics{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Metrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Metrics) ProtoMessage() {}
+
+func (x *Metrics) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Metrics.ProtoReflect.Descriptor instead.
+func (*Metrics) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *Metrics) GetDisableAll() bool {
+	if x != nil {
+		return x.DisableAll
+	}
+	return false
+}
+
+func (x *Metrics) GetDisableRequestsTotal() bool {
+	if x != nil {
+		return x.DisableRequestsTotal
+	}
+	return false
+}
+
+func (x *Metrics) GetDisableRequestsDuration() bool {
+	if x != nil {
+		return x.DisableRequestsDuration
+	}
+	return false
+}
+
+func (x *Metrics) GetDisableBytes() bool {
+	if x != nil {
+		return x.DisableBytes
+	}
+	return false
+}
+
+func (x *Metrics) GetDisableRetryState() bool {
+	if x != nil {
+		return x.DisableRetryState
+	}
+	return false
+}
+
+func (x *Metrics) GetDisableUpstreamErrors() bool {
+	if x != nil {
+		return x.DisableUpstreamErrors
+	}
+	return false
+}
+
+type Middleware struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name     string     `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Options  *anypb.Any `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
+	Required bool       `protobuf:"varint,3,opt,name=required,proto3" json:"required,omitempty"`
+}
+
+func (x *Middleware) Reset() {
+	*x = Middleware{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Middleware) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Middleware) ProtoMessage() {}
+
+func (x *Middleware) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Middleware.ProtoReflect.Descriptor instead.
+func (*Middleware) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *Middleware) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Middleware) GetOptions() *anypb.Any {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *Middleware) GetRequired() bool {
+	if x != nil {
+		return x.Required
+	}
+	return false
+}
+
+type Backend struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// localhost
+	// 127.0.0.1:8000
+	// discovery:///service_name
+	Target        string            `protobuf:"bytes,1,opt,name=target,proto3" json:"target,omitempty"`
+	Weight        *int64            `protobuf:"varint,2,opt,name=weight,proto3,oneof" json:"weight,omitempty"`
+	HealthCheck   *HealthCheck      `protobuf:"bytes,3,opt,name=health_check,json=healthCheck,proto3" json:"health_check,omitempty"`
+	Tls           bool              `protobuf:"varint,4,opt,name=tls,proto3" json:"tls,omitempty"`
+	TlsConfigName string            `protobuf:"bytes,5,opt,name=tls_config_name,json=tlsConfigName,proto3" json:"tls_config_name,omitempty"`
+	Metadata      map[string]string `protobuf:"bytes,6,rep,name=metadata,proto3" json:"metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// cap the requests per second sent to this backend,
+	// the limit of a discovery backend is shared by all the instances of the service
+	RateLimit *BackendRateLimit `protobuf:"bytes,7,opt,name=rate_limit,json=rateLimit,proto3" json:"rate_limit,omitempty"`
+	// use connections dedicated to this backend instead of the shared pool, so that requests to other backends
+	// behind the same address never reuse them, eg: services sharing a wildcard certificate behind a load balancer
+	// which routes by the TLS server name. The server name is the host in the metadata when it's set
+	IsolateConnections bool `protobuf:"varint,8,opt,name=isolate_connections,json=isolateConnections,proto3" json:"isolate_connections,omitempty"`
+	// the forward proxy the requests to this backend are sent through, eg: http://proxy.internal:3128,
+	// or "direct" to bypass the proxy from the environment. Not supported by the gRPC backends without TLS
+	EgressProxy string `protobuf:"bytes,9,opt,name=egress_proxy,json=egressProxy,proto3" json:"egress_proxy,omitempty"`
+	// allow the TLS backend to request one renegotiation per connection, which is required by some legacy servers
+	// for client certificates. The requests to it are sent with HTTP/1.1 since HTTP/2 forbids renegotiation.
+	// A request failed with a renegotiation is retried on the backends allowing it if the endpoint has retries
+	AllowTlsRenegotiation bool `protobuf:"varint,10,opt,name=allow_tls_renegotiation,json=allowTlsRenegotiation,proto3" json:"allow_tls_renegotiation,omitempty"`
+}
+
+func (x *Backend) Reset() {
+	*x = Backend{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Backend) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Backend) ProtoMessage() {}
+
+func (x *Backend) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Backend.ProtoReflect.Descriptor instead.
+func (*Backend) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *Backend) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+func (x *Backend) GetWeight() int64 {
+	if x != nil && x.Weight != nil {
+		return *x.Weight
+	}
+	return 0
+}
+
+func (x *Backend) GetHealthCheck() *HealthCheck {
+	if x != nil {
+		return x.HealthCheck
+	}
+	return nil
+}
+
+func (x *Backend) GetTls() bool {
+	if x != nil {
+		return x.Tls
+	}
+	return false
+}
+
+func (x *Backend) GetTlsConfigName() string {
+	if x != nil {
+		return x.TlsConfigName
+	}
+	return ""
+}
+
+func (x *Backend) GetMetadata() map[string]string {
+	if x != nil {
+		return x.Metadata
+	}
+	return nil
+}
+
+func (x *Backend) GetRateLimit() *BackendRateLimit {
+	if x != nil {
+		return x.RateLimit
+	}
+	return nil
+}
+
+func (x *Backend) GetIsolateConnections() bool {
+	if x != nil {
+		return x.IsolateConnections
+	}
+	return false
+}
+
+func (x *Backend) GetEgressProxy() string {
+	if x != nil {
+		return x.EgressProxy
+	}
+	return ""
+}
+
+func (x *Backend) GetAllowTlsRenegotiation() bool {
+	if x != nil {
+		return x.AllowTlsRenegotiation
+	}
+	return false
+}
+
+type BackendRateLimit struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	RequestsPerSecond float64 `protobuf:"fixed64,1,opt,name=requests_per_second,json=requestsPerSecond,proto3" json:"requests_per_second,omitempty"`
+	// the maximum number of requests sent at once, default is requests_per_second rounded up
+	Burst uint32 `protobuf:"varint,2,opt,name=burst,proto3" json:"burst,omitempty"`
+	// queue the excess requests up to this duration, they are shed with 503 immediately if not set
+	MaxWait *durationpb.Duration `protobuf:"bytes,3,opt,name=max_wait,json=maxWait,proto3" json:"max_wait,omitempty"`
+}
+
+func (x *BackendRateLimit) Reset() {
+	*x = BackendRateLimit{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BackendRateLimit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackendRateLimit) ProtoMessage() {}
+
+func (x *BackendRateLimit) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackendRateLimit.ProtoReflect.Descriptor instead.
+func (*BackendRateLimit) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *BackendRateLimit) GetRequestsPerSecond() float64 {
+	if x != nil {
+		return x.RequestsPerSecond
+	}
+	return 0
+}
+
+func (x *BackendRateLimit) GetBurst() uint32 {
+	if x != nil {
+		return x.Burst
+	}
+	return 0
+}
+
+func (x *BackendRateLimit) GetMaxWait() *durationpb.Duration {
+	if x != nil {
+		return x.MaxWait
+	}
+	return nil
+}
+
+// HealthCheck probes every instance of a discovery backend with an HTTP GET, sent with the client of the instance.
+// An instance failing unhealthy_threshold probes in a row is removed from the load balancing until it passes
+// healthy_threshold probes in a row. New instances are healthy until they fail the probes, and no instance is
+// removed when every instance is unhealthy.
+type HealthCheck struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// default is /health
+	Path string `protobuf:"bytes,1,opt,name=path,proto3" json:"path,omitempty"`
+	// default is 200
+	ExpectedStatus int32 `protobuf:"varint,2,opt,name=expected_status,json=expectedStatus,proto3" json:"expected_status,omitempty"`
+	// default is 10s
+	Interval *durationpb.Duration `protobuf:"bytes,3,opt,name=interval,proto3" json:"interval,omitempty"`
+	// default is 2s
+	Timeout *durationpb.Duration `protobuf:"bytes,4,opt,name=timeout,proto3" json:"timeout,omitempty"`
+	// default is 3
+	UnhealthyThreshold uint32 `protobuf:"varint,5,opt,name=unhealthy_threshold,json=unhealthyThreshold,proto3" json:"unhealthy_threshold,omitempty"`
+	// default is 2
+	HealthyThreshold uint32 `protobuf:"varint,6,opt,name=healthy_threshold,json=healthyThreshold,proto3" json:"healthy_threshold,omitempty"`
+}
+
+func (x *HealthCheck) Reset() {
+	*x = HealthCheck{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *HealthCheck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthCheck) ProtoMessage() {}
+
+func (x *HealthCheck) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HealthCheck.ProtoReflect.Descriptor instead.
+func (*HealthCheck) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *HealthCheck) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *HealthCheck) GetExpectedStatus() int32 {
+	if x != nil {
+		return x.ExpectedStatus
+	}
+	return 0
+}
+
+func (x *HealthCheck) GetInterval() *durationpb.Duration {
+	if x != nil {
+		return x.Interval
+	}
+	return nil
+}
+
+func (x *HealthCheck) GetTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.Timeout
+	}
+	return nil
+}
+
+func (x *HealthCheck) GetUnhealthyThreshold() uint32 {
+	if x != nil {
+		return x.UnhealthyThreshold
+	}
+	return 0
+}
+
+func (x *HealthCheck) GetHealthyThreshold() uint32 {
+	if x != nil {
+		return x.HealthyThreshold
+	}
+	return 0
+}
+
+type Retry struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// default attempts is 1
+	Attempts      uint32               `protobuf:"varint,1,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	PerTryTimeout *durationpb.Duration `protobuf:"bytes,2,opt,name=per_try_timeout,json=perTryTimeout,proto3" json:"per_try_timeout,omitempty"`
+	Conditions    []*Condition         `protobuf:"bytes,3,rep,name=conditions,proto3" json:"conditions,omitempty"`
+	// primary,secondary
+	Priorities []string `protobuf:"bytes,4,rep,name=priorities,proto3" json:"priorities,omitempty"`
+	// sheds retries when too few of them succeed
+	Breaker *RetryBreaker `protobuf:"bytes,5,opt,name=breaker,proto3" json:"breaker,omitempty"`
+	// the delay between attempts, retries are sent immediately if unset
+	Backoff *RetryBackoff `protobuf:"bytes,6,opt,name=backoff,proto3" json:"backoff,omitempty"`
+}
+
+func (x *Retry) Reset() {
+	*x = Retry{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Retry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Retry) ProtoMessage() {}
+
+func (x *Retry) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Retry.ProtoReflect.Descriptor instead.
+func (*Retry) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *Retry) GetAttempts() uint32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *Retry) GetPerTryTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.PerTryTimeout
+	}
+	return nil
+}
+
+func (x *Retry) GetConditions() []*Condition {
+	if x != nil {
+		return x.Conditions
+	}
+	return nil
+}
+
+func (x *Retry) GetPriorities() []string {
+	if x != nil {
+		return x.Priorities
+	}
+	return nil
+}
+
+func (x *Retry) GetBreaker() *RetryBreaker {
+	if x != nil {
+		return x.Breaker
+	}
+	return nil
+}
+
+func (x *Retry) GetBackoff() *RetryBackoff {
+	if x != nil {
+		return x.Backoff
+	}
+	return nil
+}
+
+// RetryBackoff delays the n-th retry by base * multiplier^(n-1), capped by max and randomized by the jitter. A retry
+// is given up and the last error returned if its delay would exceed the timeout of the endpoint.
+type RetryBackoff struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the delay before the first retry, eg: 25ms
+	Base *durationpb.Duration `protobuf:"bytes,1,opt,name=base,proto3" json:"base,omitempty"`
+	// the factor by which the delay grows after each retry, default is 2
+	Multiplier float64 `protobuf:"fixed64,2,opt,name=multiplier,proto3" json:"multiplier,omitempty"`
+	// the upper bound of the delay, default is unbounded
+	Max    *durationpb.Duration `protobuf:"bytes,3,opt,name=max,proto3" json:"max,omitempty"`
+	Jitter RetryBackoff_Jitter  `protobuf:"varint,4,opt,name=jitter,proto3,enum=gateway.config.v1.RetryBackoff_Jitter" json:"jitter,omitempty"`
+}
+
+func (x *RetryBackoff) Reset() {
+	*x = RetryBackoff{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RetryBackoff) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryBackoff) ProtoMessage() {}
+
+func (x *RetryBackoff) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryBackoff.ProtoReflect.Descriptor instead.
+func (*RetryBackoff) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *RetryBackoff) GetBase() *durationpb.Duration {
+	if x != nil {
+		return x.Base
+	}
+	return nil
+}
+
+func (x *RetryBackoff) GetMultiplier() float64 {
+	if x != nil {
+		return x.Multiplier
+	}
+	return 0
+}
+
+func (x *RetryBackoff) GetMax() *durationpb.Duration {
+	if x != nil {
+		return x.Max
+	}
+	return nil
+}
+
+func (x *RetryBackoff) GetJitter() RetryBackoff_Jitter {
+	if x != nil {
+		return x.Jitter
+	}
+	return RetryBackoff_NONE
+}
+
+// RetryBreaker configures the adaptive breaker which sheds retries of an endpoint, retries are dropped with a growing
+// probability once fewer than success of the retries in the window succeed, it can be reset by the admin API.
+type RetryBreaker struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the expected success ratio of retries, default is 0.8
+	Success float64 `protobuf:"fixed64,1,opt,name=success,proto3" json:"success,omitempty"`
+	// the minimum number of retries in the window before shedding, default is 100
+	Request int64 `protobuf:"varint,2,opt,name=request,proto3" json:"request,omitempty"`
+	// the sliding window of the statistics, default is 3s
+	Window *durationpb.Duration `protobuf:"bytes,3,opt,name=window,proto3" json:"window,omitempty"`
+}
+
+func (x *RetryBreaker) Reset() {
+	*x = RetryBreaker{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RetryBreaker) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RetryBreaker) ProtoMessage() {}
+
+func (x *RetryBreaker) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RetryBreaker.ProtoReflect.Descriptor instead.
+func (*RetryBreaker) Descriptor() ([]byte, []int) {
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *RetryBreaker) GetSuccess() float64 {
+	if x != nil {
+		return x.Success
+	}
+	return 0
+}
+
+func (x *RetryBreaker) GetRequest() int64 {
+	if x != nil {
+		return x.Request
+	}
+	return 0
+}
+
+func (x *RetryBreaker) GetWindow() *durationpb.Duration {
+	if x != nil {
+		return x.Window
 	}
 	return nil
 }
@@ -700,7 +3249,7 @@ type Condition struct {
 func (x *Condition) Reset() {
 	*x = Condition{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_gateway_config_v1_gateway_proto_msgTypes[8]
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[34]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -713,7 +3262,7 @@ func (x *Condition) String() string {
 func (*Condition) ProtoMessage() {}
 
 func (x *Condition) ProtoReflect() protoreflect.Message {
-	mi := &file_gateway_config_v1_gateway_proto_msgTypes[8]
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[34]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -726,7 +3275,7 @@ func (x *Condition) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Condition.ProtoReflect.Descriptor instead.
 func (*Condition) Descriptor() ([]byte, []int) {
-	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{8}
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{34}
 }
 
 func (m *Condition) GetCondition() isCondition_Condition {
@@ -773,14 +3322,21 @@ type ConditionHeader struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Name  string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// a single value or a json list of values, eg: `["5", "14"]`,
+	// matched against every value of the header including the comma separated ones
 	Value string `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	// match if the header is present regardless of its value, the value is ignored
+	Present bool `protobuf:"varint,3,opt,name=present,proto3" json:"present,omitempty"`
+	// invert the match, a negated condition vetoes the others,
+	// eg: retry on 500-599 unless the X-No-Retry header is present
+	Negate bool `protobuf:"varint,4,opt,name=negate,proto3" json:"negate,omitempty"`
 }
 
 func (x *ConditionHeader) Reset() {
 	*x = ConditionHeader{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_gateway_config_v1_gateway_proto_msgTypes[12]
+		mi := &file_gateway_config_v1_gateway_proto_msgTypes[41]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -793,7 +3349,7 @@ func (x *ConditionHeader) String() string {
 func (*ConditionHeader) ProtoMessage() {}
 
 func (x *ConditionHeader) ProtoReflect() protoreflect.Message {
-	mi := &file_gateway_config_v1_gateway_proto_msgTypes[12]
+	mi := &file_gateway_config_v1_gateway_proto_msgTypes[41]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -806,7 +3362,7 @@ func (x *ConditionHeader) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ConditionHeader.ProtoReflect.Descriptor instead.
 func (*ConditionHeader) Descriptor() ([]byte, []int) {
-	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{8, 0}
+	return file_gateway_config_v1_gateway_proto_rawDescGZIP(), []int{34, 0}
 }
 
 func (x *ConditionHeader) GetName() string {
@@ -823,6 +3379,20 @@ func (x *ConditionHeader) GetValue() string {
 	return ""
 }
 
+func (x *ConditionHeader) GetPresent() bool {
+	if x != nil {
+		return x.Present
+	}
+	return false
+}
+
+func (x *ConditionHeader) GetNegate() bool {
+	if x != nil {
+		return x.Negate
+	}
+	return false
+}
+
 var File_gateway_config_v1_gateway_proto protoreflect.FileDescriptor
 
 var file_gateway_config_v1_gateway_proto_rawDesc = []byte{
@@ -833,7 +3403,7 @@ var file_gateway_config_v1_gateway_proto_rawDesc = []byte{
 	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x61, 0x6e, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a,
 	0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
 	0x2f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22,
-	0xe9, 0x02, 0x0a, 0x07, 0x47, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0xa0, 0x07, 0x0a, 0x07, 0x47, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x6e,
 	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
 	0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
 	0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x05, 0x68, 0x6f, 0x73,
@@ -850,120 +3420,575 @@ var file_gateway_config_v1_gateway_proto_rawDesc = []byte{
 	0x28, 0x0b, 0x32, 0x28, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e,
 	0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x54,
 	0x6c, 0x73, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x74, 0x6c,
-	0x73, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x1a, 0x53, 0x0a, 0x0d, 0x54, 0x6c, 0x73, 0x53, 0x74, 0x6f,
-	0x72, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x2c, 0x0a, 0x05, 0x76, 0x61, 0x6c,
-	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77,
-	0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x4c, 0x53,
-	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x80, 0x01, 0x0a, 0x03,
-	0x54, 0x4c, 0x53, 0x12, 0x1a, 0x0a, 0x08, 0x69, 0x6e, 0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x69, 0x6e, 0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x12,
-	0x16, 0x0a, 0x06, 0x63, 0x61, 0x63, 0x65, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x06, 0x63, 0x61, 0x63, 0x65, 0x72, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x65, 0x72, 0x74, 0x18,
-	0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x65, 0x72, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x6b,
-	0x65, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x1f, 0x0a,
-	0x0b, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x0a, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x4e, 0x61, 0x6d, 0x65, 0x22, 0x79,
-	0x0a, 0x0e, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
-	0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
-	0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x39,
-	0x0a, 0x09, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66,
-	0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x09,
-	0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x22, 0x87, 0x04, 0x0a, 0x08, 0x45, 0x6e,
-	0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65,
-	0x74, 0x68, 0x6f, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x74, 0x68,
-	0x6f, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f,
-	0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70,
-	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x37, 0x0a, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c,
-	0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1b, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79,
-	0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x72, 0x6f, 0x74, 0x6f,
-	0x63, 0x6f, 0x6c, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x33, 0x0a,
-	0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19,
+	0x73, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x12, 0x4a, 0x0a, 0x0f, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64,
+	0x5f, 0x6f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x21, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69,
+	0x64, 0x65, 0x52, 0x0e, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69,
+	0x64, 0x65, 0x12, 0x46, 0x0a, 0x10, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x5f, 0x65, 0x6e,
+	0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67,
+	0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31,
+	0x2e, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x0f, 0x64, 0x65, 0x66, 0x61, 0x75,
+	0x6c, 0x74, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x2b, 0x0a, 0x11, 0x63, 0x72,
+	0x69, 0x74, 0x69, 0x63, 0x61, 0x6c, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x73, 0x18,
+	0x09, 0x20, 0x03, 0x28, 0x09, 0x52, 0x10, 0x63, 0x72, 0x69, 0x74, 0x69, 0x63, 0x61, 0x6c, 0x53,
+	0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x73, 0x12, 0x60, 0x0a, 0x12, 0x65, 0x6e, 0x64, 0x70, 0x6f,
+	0x69, 0x6e, 0x74, 0x5f, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x73, 0x18, 0x0a, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x31, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e,
+	0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x54, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65,
+	0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x11, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74,
+	0x54, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x73, 0x12, 0x47, 0x0a, 0x0e, 0x74, 0x65, 0x6e,
+	0x61, 0x6e, 0x74, 0x5f, 0x72, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x18, 0x0b, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x20, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x52, 0x6f, 0x75, 0x74,
+	0x69, 0x6e, 0x67, 0x52, 0x0d, 0x74, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x52, 0x6f, 0x75, 0x74, 0x69,
+	0x6e, 0x67, 0x12, 0x43, 0x0a, 0x0d, 0x76, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x5f, 0x68, 0x6f,
+	0x73, 0x74, 0x73, 0x18, 0x0c, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x67, 0x61, 0x74, 0x65,
+	0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x69,
+	0x72, 0x74, 0x75, 0x61, 0x6c, 0x48, 0x6f, 0x73, 0x74, 0x52, 0x0c, 0x76, 0x69, 0x72, 0x74, 0x75,
+	0x61, 0x6c, 0x48, 0x6f, 0x73, 0x74, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x61, 0x75, 0x74, 0x6f, 0x5f,
+	0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0b, 0x61,
+	0x75, 0x74, 0x6f, 0x4f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x1a, 0x53, 0x0a, 0x0d, 0x54, 0x6c,
+	0x73, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b,
+	0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x2c, 0x0a,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16, 0x2e, 0x67,
+	0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31,
+	0x2e, 0x54, 0x4c, 0x53, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a,
+	0x61, 0x0a, 0x16, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x54, 0x65, 0x6d, 0x70, 0x6c,
+	0x61, 0x74, 0x65, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x31, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x61, 0x74,
+	0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x45,
+	0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
+	0x38, 0x01, 0x22, 0xe2, 0x01, 0x0a, 0x0b, 0x56, 0x69, 0x72, 0x74, 0x75, 0x61, 0x6c, 0x48, 0x6f,
+	0x73, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x61, 0x74, 0x68, 0x5f, 0x70, 0x72, 0x65, 0x66, 0x69,
+	0x78, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x61, 0x74, 0x68, 0x50, 0x72, 0x65,
+	0x66, 0x69, 0x78, 0x12, 0x3f, 0x0a, 0x0b, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72,
+	0x65, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77,
+	0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x69, 0x64,
+	0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x52, 0x0b, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77,
+	0x61, 0x72, 0x65, 0x73, 0x12, 0x36, 0x0a, 0x08, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79,
+	0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65,
+	0x6e, 0x64, 0x52, 0x08, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x73, 0x12, 0x39, 0x0a, 0x09,
+	0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x1b, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x2e, 0x76, 0x31, 0x2e, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x09, 0x65, 0x6e,
+	0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x22, 0xdf, 0x01, 0x0a, 0x0d, 0x54, 0x65, 0x6e, 0x61,
+	0x6e, 0x74, 0x52, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x61,
+	0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x12, 0x47, 0x0a, 0x07, 0x74, 0x65, 0x6e,
+	0x61, 0x6e, 0x74, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2d, 0x2e, 0x67, 0x61, 0x74,
+	0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x54,
+	0x65, 0x6e, 0x61, 0x6e, 0x74, 0x52, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x2e, 0x54, 0x65, 0x6e,
+	0x61, 0x6e, 0x74, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x74, 0x65, 0x6e, 0x61, 0x6e,
+	0x74, 0x73, 0x1a, 0x55, 0x0a, 0x0c, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x2f, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x65, 0x6e, 0x61, 0x6e, 0x74, 0x52, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x43, 0x0a, 0x06, 0x54, 0x65, 0x6e,
+	0x61, 0x6e, 0x74, 0x12, 0x39, 0x0a, 0x09, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79,
+	0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x6e, 0x64, 0x70, 0x6f,
+	0x69, 0x6e, 0x74, 0x52, 0x09, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x22, 0x51,
+	0x0a, 0x0e, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x4f, 0x76, 0x65, 0x72, 0x72, 0x69, 0x64, 0x65,
+	0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x27, 0x0a, 0x0f, 0x61, 0x6c, 0x6c, 0x6f,
+	0x77, 0x65, 0x64, 0x5f, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x0e, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x4d, 0x65, 0x74, 0x68, 0x6f, 0x64,
+	0x73, 0x22, 0x80, 0x01, 0x0a, 0x03, 0x54, 0x4c, 0x53, 0x12, 0x1a, 0x0a, 0x08, 0x69, 0x6e, 0x73,
+	0x65, 0x63, 0x75, 0x72, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x69, 0x6e, 0x73,
+	0x65, 0x63, 0x75, 0x72, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x61, 0x63, 0x65, 0x72, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x61, 0x63, 0x65, 0x72, 0x74, 0x12, 0x12, 0x0a,
+	0x04, 0x63, 0x65, 0x72, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x65, 0x72,
+	0x74, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03,
+	0x6b, 0x65, 0x79, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x6e, 0x61,
+	0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72,
+	0x4e, 0x61, 0x6d, 0x65, 0x22, 0x79, 0x0a, 0x0e, 0x50, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x79,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65,
+	0x72, 0x73, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x72,
+	0x73, 0x69, 0x6f, 0x6e, 0x12, 0x39, 0x0a, 0x09, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74,
+	0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61,
+	0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x6e, 0x64, 0x70,
+	0x6f, 0x69, 0x6e, 0x74, 0x52, 0x09, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x73, 0x22,
+	0xb6, 0x0f, 0x0a, 0x08, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04,
+	0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68,
+	0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63,
+	0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64,
+	0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x37, 0x0a, 0x08, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1b, 0x2e, 0x67,
+	0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31,
+	0x2e, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x63, 0x6f, 0x6c, 0x12, 0x33, 0x0a, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x3f, 0x0a, 0x0b, 0x6d, 0x69, 0x64, 0x64,
+	0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e,
+	0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76,
+	0x31, 0x2e, 0x4d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x52, 0x0b, 0x6d, 0x69,
+	0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x73, 0x12, 0x36, 0x0a, 0x08, 0x62, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x61,
+	0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e,
+	0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x08, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64,
+	0x73, 0x12, 0x2e, 0x0a, 0x05, 0x72, 0x65, 0x74, 0x72, 0x79, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x18, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x74, 0x72, 0x79, 0x52, 0x05, 0x72, 0x65, 0x74, 0x72,
+	0x79, 0x12, 0x45, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x09, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x29, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74,
+	0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08,
+	0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x6f, 0x73, 0x74,
+	0x18, 0x0a, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x68, 0x6f, 0x73, 0x74, 0x12, 0x2a, 0x0a, 0x11,
+	0x64, 0x72, 0x6f, 0x70, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x5f, 0x62, 0x6f, 0x64,
+	0x79, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0f, 0x64, 0x72, 0x6f, 0x70, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x42, 0x6f, 0x64, 0x79, 0x12, 0x34, 0x0a, 0x07, 0x6d, 0x65, 0x74, 0x72,
+	0x69, 0x63, 0x73, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x61, 0x74, 0x65,
+	0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x65,
+	0x74, 0x72, 0x69, 0x63, 0x73, 0x52, 0x07, 0x6d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x12, 0x47,
+	0x0a, 0x0e, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x69, 0x6e, 0x67, 0x5f, 0x73, 0x6c, 0x61, 0x73, 0x68,
+	0x18, 0x0d, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x20, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79,
+	0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x72, 0x61, 0x69, 0x6c,
+	0x69, 0x6e, 0x67, 0x53, 0x6c, 0x61, 0x73, 0x68, 0x52, 0x0d, 0x74, 0x72, 0x61, 0x69, 0x6c, 0x69,
+	0x6e, 0x67, 0x53, 0x6c, 0x61, 0x73, 0x68, 0x12, 0x38, 0x0a, 0x07, 0x68, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x73, 0x18, 0x0e, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77,
+	0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x65, 0x61,
+	0x64, 0x65, 0x72, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x52, 0x07, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x73, 0x12, 0x37, 0x0a, 0x07, 0x71, 0x75, 0x65, 0x72, 0x69, 0x65, 0x73, 0x18, 0x0f, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x51, 0x75, 0x65, 0x72, 0x79, 0x4d, 0x61, 0x74, 0x63,
+	0x68, 0x52, 0x07, 0x71, 0x75, 0x65, 0x72, 0x69, 0x65, 0x73, 0x12, 0x44, 0x0a, 0x0d, 0x6c, 0x6f,
+	0x61, 0x64, 0x5f, 0x62, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x72, 0x18, 0x10, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1f, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x61, 0x64, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63,
+	0x65, 0x72, 0x52, 0x0c, 0x6c, 0x6f, 0x61, 0x64, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x72,
+	0x12, 0x47, 0x0a, 0x0e, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x5f, 0x6c, 0x69, 0x6d,
+	0x69, 0x74, 0x18, 0x11, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x20, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77,
+	0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x52, 0x0d, 0x72, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x24, 0x0a, 0x0e, 0x6d, 0x61, 0x78,
+	0x5f, 0x75, 0x72, 0x6c, 0x5f, 0x6c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x18, 0x12, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x0c, 0x6d, 0x61, 0x78, 0x55, 0x72, 0x6c, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x12,
+	0x1a, 0x0a, 0x08, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x18, 0x13, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x08, 0x74, 0x65, 0x6d, 0x70, 0x6c, 0x61, 0x74, 0x65, 0x12, 0x4b, 0x0a, 0x0d, 0x62,
+	0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x5f, 0x67, 0x72, 0x6f, 0x75, 0x70, 0x18, 0x14, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x26, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x47, 0x72,
+	0x6f, 0x75, 0x70, 0x52, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x52, 0x0c, 0x62, 0x61, 0x63, 0x6b,
+	0x65, 0x6e, 0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x12, 0x50, 0x0a, 0x11, 0x72, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x5f, 0x62, 0x75, 0x66, 0x66, 0x65, 0x72, 0x69, 0x6e, 0x67, 0x18, 0x15, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x42,
+	0x75, 0x66, 0x66, 0x65, 0x72, 0x69, 0x6e, 0x67, 0x52, 0x10, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x42, 0x75, 0x66, 0x66, 0x65, 0x72, 0x69, 0x6e, 0x67, 0x12, 0x50, 0x0a, 0x11, 0x6f, 0x75,
+	0x74, 0x6c, 0x69, 0x65, 0x72, 0x5f, 0x64, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18,
+	0x16, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e,
+	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4f, 0x75, 0x74, 0x6c, 0x69, 0x65,
+	0x72, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x10, 0x6f, 0x75, 0x74, 0x6c,
+	0x69, 0x65, 0x72, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1e, 0x0a, 0x0b,
+	0x68, 0x65, 0x61, 0x64, 0x5f, 0x61, 0x73, 0x5f, 0x67, 0x65, 0x74, 0x18, 0x17, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x09, 0x68, 0x65, 0x61, 0x64, 0x41, 0x73, 0x47, 0x65, 0x74, 0x12, 0x27, 0x0a, 0x0f,
+	0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x5f, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x73, 0x18,
+	0x18, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0e, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x4d, 0x65,
+	0x74, 0x68, 0x6f, 0x64, 0x73, 0x12, 0x4f, 0x0a, 0x12, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x5f,
+	0x6e, 0x6f, 0x74, 0x5f, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x18, 0x19, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x21, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x52, 0x10, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x4e, 0x6f, 0x74, 0x41,
+	0x6c, 0x6c, 0x6f, 0x77, 0x65, 0x64, 0x12, 0x2c, 0x0a, 0x12, 0x73, 0x6e, 0x69, 0x66, 0x66, 0x5f,
+	0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x1a, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x10, 0x73, 0x6e, 0x69, 0x66, 0x66, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74,
+	0x54, 0x79, 0x70, 0x65, 0x12, 0x53, 0x0a, 0x12, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x5f, 0x62, 0x75, 0x66, 0x66, 0x65, 0x72, 0x69, 0x6e, 0x67, 0x18, 0x1b, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x24, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x42, 0x75, 0x66,
+	0x66, 0x65, 0x72, 0x69, 0x6e, 0x67, 0x52, 0x11, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x42, 0x75, 0x66, 0x66, 0x65, 0x72, 0x69, 0x6e, 0x67, 0x12, 0x43, 0x0a, 0x0c, 0x62, 0x61, 0x63,
+	0x6b, 0x70, 0x72, 0x65, 0x73, 0x73, 0x75, 0x72, 0x65, 0x18, 0x1c, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1f, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x70, 0x72, 0x65, 0x73, 0x73, 0x75, 0x72, 0x65,
+	0x52, 0x0c, 0x62, 0x61, 0x63, 0x6b, 0x70, 0x72, 0x65, 0x73, 0x73, 0x75, 0x72, 0x65, 0x12, 0x5a,
+	0x0a, 0x15, 0x75, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x1d, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x26, 0x2e,
+	0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76,
+	0x31, 0x2e, 0x55, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x4c, 0x69, 0x6d, 0x69, 0x74, 0x52, 0x13, 0x75, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x48,
+	0x65, 0x61, 0x64, 0x65, 0x72, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x3e, 0x0a, 0x0b, 0x6c, 0x61,
+	0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x73, 0x6c, 0x6f, 0x18, 0x1e, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1d, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x53, 0x4c, 0x4f, 0x52, 0x0a,
+	0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x53, 0x6c, 0x6f, 0x12, 0x41, 0x0a, 0x0c, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x5f, 0x62, 0x75, 0x64, 0x67, 0x65, 0x74, 0x18, 0x1f, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1e, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x42, 0x75, 0x64, 0x67, 0x65, 0x74,
+	0x52, 0x0b, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x42, 0x75, 0x64, 0x67, 0x65, 0x74, 0x12, 0x4f, 0x0a,
+	0x11, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x5f, 0x73, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c,
+	0x65, 0x73, 0x18, 0x20, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77,
+	0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x52, 0x10, 0x62, 0x61,
+	0x63, 0x6b, 0x65, 0x6e, 0x64, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x73, 0x12, 0x3b,
+	0x0a, 0x0a, 0x64, 0x75, 0x61, 0x6c, 0x5f, 0x77, 0x72, 0x69, 0x74, 0x65, 0x18, 0x21, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e,
+	0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x75, 0x61, 0x6c, 0x57, 0x72, 0x69, 0x74, 0x65,
+	0x52, 0x09, 0x64, 0x75, 0x61, 0x6c, 0x57, 0x72, 0x69, 0x74, 0x65, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d,
+	0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14,
+	0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76,
+	0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x9a, 0x01, 0x0a, 0x0f, 0x42, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x12, 0x12, 0x0a, 0x04,
+	0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65,
+	0x12, 0x3b, 0x0a, 0x07, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x0b, 0x32, 0x21, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75, 0x6c, 0x65, 0x57, 0x69,
+	0x6e, 0x64, 0x6f, 0x77, 0x52, 0x07, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x73, 0x12, 0x36, 0x0a,
+	0x08, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x08, 0x62, 0x61, 0x63,
+	0x6b, 0x65, 0x6e, 0x64, 0x73, 0x22, 0xa4, 0x01, 0x0a, 0x0e, 0x53, 0x63, 0x68, 0x65, 0x64, 0x75,
+	0x6c, 0x65, 0x57, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x79, 0x73,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x64, 0x61, 0x79, 0x73, 0x12, 0x14, 0x0a, 0x05,
+	0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x73, 0x74, 0x61,
+	0x72, 0x74, 0x12, 0x10, 0x0a, 0x03, 0x65, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x65, 0x6e, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x7a, 0x6f, 0x6e, 0x65,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x69, 0x6d, 0x65, 0x7a, 0x6f, 0x6e, 0x65,
+	0x12, 0x1d, 0x0a, 0x0a, 0x6e, 0x6f, 0x74, 0x5f, 0x62, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x6e, 0x6f, 0x74, 0x42, 0x65, 0x66, 0x6f, 0x72, 0x65, 0x12,
+	0x1b, 0x0a, 0x09, 0x6e, 0x6f, 0x74, 0x5f, 0x61, 0x66, 0x74, 0x65, 0x72, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x6e, 0x6f, 0x74, 0x41, 0x66, 0x74, 0x65, 0x72, 0x22, 0xbd, 0x02, 0x0a,
+	0x0b, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x42, 0x75, 0x64, 0x67, 0x65, 0x74, 0x12, 0x16, 0x0a, 0x06,
+	0x62, 0x75, 0x64, 0x67, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x62, 0x75,
+	0x64, 0x67, 0x65, 0x74, 0x12, 0x31, 0x0a, 0x06, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
+	0x06, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x69, 0x6e, 0x5f, 0x72,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0b, 0x6d,
+	0x69, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x12, 0x3d, 0x0a, 0x06, 0x61, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x25, 0x2e, 0x67, 0x61, 0x74,
+	0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x45,
+	0x72, 0x72, 0x6f, 0x72, 0x42, 0x75, 0x64, 0x67, 0x65, 0x74, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x16, 0x0a, 0x06, 0x77, 0x65, 0x69,
+	0x67, 0x68, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x06, 0x77, 0x65, 0x69, 0x67, 0x68,
+	0x74, 0x12, 0x47, 0x0a, 0x11, 0x66, 0x61, 0x6c, 0x6c, 0x62, 0x61, 0x63, 0x6b, 0x5f, 0x62, 0x61,
+	0x63, 0x6b, 0x65, 0x6e, 0x64, 0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31,
+	0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x10, 0x66, 0x61, 0x6c, 0x6c, 0x62, 0x61,
+	0x63, 0x6b, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x73, 0x22, 0x20, 0x0a, 0x06, 0x41, 0x63,
+	0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0c, 0x0a, 0x08, 0x46, 0x41, 0x4c, 0x4c, 0x42, 0x41, 0x43, 0x4b,
+	0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x53, 0x48, 0x45, 0x44, 0x10, 0x01, 0x22, 0x8a, 0x02, 0x0a,
+	0x09, 0x44, 0x75, 0x61, 0x6c, 0x57, 0x72, 0x69, 0x74, 0x65, 0x12, 0x3c, 0x0a, 0x0b, 0x73, 0x65,
+	0x63, 0x6f, 0x6e, 0x64, 0x61, 0x72, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x0b, 0x73, 0x65, 0x63,
+	0x6f, 0x6e, 0x64, 0x61, 0x72, 0x69, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x74, 0x68,
+	0x6f, 0x64, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x74, 0x68, 0x6f,
+	0x64, 0x73, 0x12, 0x49, 0x0a, 0x0a, 0x6f, 0x6e, 0x5f, 0x66, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x2a, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79,
+	0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x75, 0x61, 0x6c, 0x57,
+	0x72, 0x69, 0x74, 0x65, 0x2e, 0x46, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x50, 0x6f, 0x6c, 0x69,
+	0x63, 0x79, 0x52, 0x09, 0x6f, 0x6e, 0x46, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x12, 0x33, 0x0a,
+	0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19,
 	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
 	0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f,
-	0x75, 0x74, 0x12, 0x3f, 0x0a, 0x0b, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65,
-	0x73, 0x18, 0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1d, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61,
-	0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x69, 0x64, 0x64,
-	0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x52, 0x0b, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61,
-	0x72, 0x65, 0x73, 0x12, 0x36, 0x0a, 0x08, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x73, 0x18,
-	0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e,
+	0x75, 0x74, 0x22, 0x25, 0x0a, 0x0d, 0x46, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x50, 0x6f, 0x6c,
+	0x69, 0x63, 0x79, 0x12, 0x0a, 0x0a, 0x06, 0x49, 0x47, 0x4e, 0x4f, 0x52, 0x45, 0x10, 0x00, 0x12,
+	0x08, 0x0a, 0x04, 0x46, 0x41, 0x49, 0x4c, 0x10, 0x01, 0x22, 0xa5, 0x03, 0x0a, 0x10, 0x4f, 0x75,
+	0x74, 0x6c, 0x69, 0x65, 0x72, 0x44, 0x65, 0x74, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x2d,
+	0x0a, 0x12, 0x63, 0x6f, 0x6e, 0x73, 0x65, 0x63, 0x75, 0x74, 0x69, 0x76, 0x65, 0x5f, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x11, 0x63, 0x6f, 0x6e, 0x73,
+	0x65, 0x63, 0x75, 0x74, 0x69, 0x76, 0x65, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x12, 0x31, 0x0a,
+	0x06, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77,
+	0x12, 0x35, 0x0a, 0x08, 0x63, 0x6f, 0x6f, 0x6c, 0x64, 0x6f, 0x77, 0x6e, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x63,
+	0x6f, 0x6f, 0x6c, 0x64, 0x6f, 0x77, 0x6e, 0x12, 0x3a, 0x0a, 0x19, 0x63, 0x6f, 0x6e, 0x73, 0x65,
+	0x63, 0x75, 0x74, 0x69, 0x76, 0x65, 0x5f, 0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x5f, 0x65, 0x72,
+	0x72, 0x6f, 0x72, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x17, 0x63, 0x6f, 0x6e, 0x73,
+	0x65, 0x63, 0x75, 0x74, 0x69, 0x76, 0x65, 0x53, 0x65, 0x72, 0x76, 0x65, 0x72, 0x45, 0x72, 0x72,
+	0x6f, 0x72, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x66, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x5f, 0x72,
+	0x61, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x66, 0x61, 0x69, 0x6c, 0x75,
+	0x72, 0x65, 0x52, 0x61, 0x74, 0x65, 0x12, 0x29, 0x0a, 0x10, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75,
+	0x6d, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x0f, 0x6d, 0x69, 0x6e, 0x69, 0x6d, 0x75, 0x6d, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x73, 0x12, 0x3c, 0x0a, 0x0c, 0x6d, 0x61, 0x78, 0x5f, 0x63, 0x6f, 0x6f, 0x6c, 0x64, 0x6f, 0x77,
+	0x6e, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x0b, 0x6d, 0x61, 0x78, 0x43, 0x6f, 0x6f, 0x6c, 0x64, 0x6f, 0x77, 0x6e, 0x12,
+	0x30, 0x0a, 0x14, 0x6d, 0x61, 0x78, 0x5f, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x5f,
+	0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x12, 0x6d,
+	0x61, 0x78, 0x45, 0x6a, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x50, 0x65, 0x72, 0x63, 0x65, 0x6e,
+	0x74, 0x22, 0xa1, 0x01, 0x0a, 0x0c, 0x42, 0x61, 0x63, 0x6b, 0x70, 0x72, 0x65, 0x73, 0x73, 0x75,
+	0x72, 0x65, 0x12, 0x25, 0x0a, 0x0e, 0x64, 0x65, 0x63, 0x72, 0x65, 0x61, 0x73, 0x65, 0x5f, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x64, 0x65, 0x63, 0x72,
+	0x65, 0x61, 0x73, 0x65, 0x52, 0x61, 0x74, 0x69, 0x6f, 0x12, 0x27, 0x0a, 0x0f, 0x6d, 0x69, 0x6e,
+	0x5f, 0x63, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e, 0x63, 0x79, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x0e, 0x6d, 0x69, 0x6e, 0x43, 0x6f, 0x6e, 0x63, 0x75, 0x72, 0x72, 0x65, 0x6e,
+	0x63, 0x79, 0x12, 0x41, 0x0a, 0x0f, 0x6d, 0x61, 0x78, 0x5f, 0x72, 0x65, 0x74, 0x72, 0x79, 0x5f,
+	0x61, 0x66, 0x74, 0x65, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0d, 0x6d, 0x61, 0x78, 0x52, 0x65, 0x74, 0x72, 0x79,
+	0x41, 0x66, 0x74, 0x65, 0x72, 0x22, 0x63, 0x0a, 0x0a, 0x4c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79,
+	0x53, 0x4c, 0x4f, 0x12, 0x37, 0x0a, 0x09, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x52, 0x09, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x1c, 0x0a, 0x09,
+	0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x09, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x69, 0x76, 0x65, 0x22, 0x5f, 0x0a, 0x13, 0x55, 0x70,
+	0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x4c, 0x69, 0x6d, 0x69,
+	0x74, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x2b,
+	0x0a, 0x11, 0x74, 0x72, 0x69, 0x6d, 0x6d, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x68, 0x65, 0x61, 0x64,
+	0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x10, 0x74, 0x72, 0x69, 0x6d, 0x6d,
+	0x61, 0x62, 0x6c, 0x65, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x22, 0x57, 0x0a, 0x10, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x42, 0x75, 0x66, 0x66, 0x65, 0x72, 0x69, 0x6e, 0x67, 0x12,
+	0x28, 0x0a, 0x10, 0x6d, 0x61, 0x78, 0x5f, 0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x5f, 0x62, 0x79,
+	0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0e, 0x6d, 0x61, 0x78, 0x4d, 0x65,
+	0x6d, 0x6f, 0x72, 0x79, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12, 0x19, 0x0a, 0x08, 0x74, 0x65, 0x6d,
+	0x70, 0x5f, 0x64, 0x69, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x74, 0x65, 0x6d,
+	0x70, 0x44, 0x69, 0x72, 0x22, 0x3c, 0x0a, 0x11, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x42, 0x75, 0x66, 0x66, 0x65, 0x72, 0x69, 0x6e, 0x67, 0x12, 0x27, 0x0a, 0x0f, 0x74, 0x68, 0x72,
+	0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0e, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x42, 0x79, 0x74,
+	0x65, 0x73, 0x22, 0x91, 0x01, 0x0a, 0x0d, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4c,
+	0x69, 0x6d, 0x69, 0x74, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x62, 0x79, 0x74, 0x65,
+	0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x03, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x42, 0x79, 0x74, 0x65,
+	0x73, 0x12, 0x3f, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0e, 0x32, 0x27, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x4c, 0x69,
+	0x6d, 0x69, 0x74, 0x2e, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69,
+	0x6f, 0x6e, 0x22, 0x22, 0x0a, 0x06, 0x41, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0a, 0x0a, 0x06,
+	0x52, 0x45, 0x4a, 0x45, 0x43, 0x54, 0x10, 0x00, 0x12, 0x0c, 0x0a, 0x08, 0x54, 0x52, 0x55, 0x4e,
+	0x43, 0x41, 0x54, 0x45, 0x10, 0x01, 0x22, 0x68, 0x0a, 0x13, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e,
+	0x64, 0x47, 0x72, 0x6f, 0x75, 0x70, 0x52, 0x6f, 0x75, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x16, 0x0a,
+	0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x68,
+	0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x21, 0x0a, 0x0c, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74,
+	0x61, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6d, 0x65, 0x74,
+	0x61, 0x64, 0x61, 0x74, 0x61, 0x4b, 0x65, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69,
+	0x76, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x61, 0x63, 0x74, 0x69, 0x76, 0x65,
+	0x22, 0xcb, 0x01, 0x0a, 0x0e, 0x52, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x63, 0x6f,
+	0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73,
+	0x43, 0x6f, 0x64, 0x65, 0x12, 0x48, 0x0a, 0x07, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2e, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e,
+	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x6a, 0x65, 0x63, 0x74,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x07, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x12, 0x12,
+	0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x62, 0x6f,
+	0x64, 0x79, 0x1a, 0x3a, 0x0a, 0x0c, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x73, 0x45, 0x6e, 0x74,
+	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0xfe,
+	0x02, 0x0a, 0x0c, 0x4c, 0x6f, 0x61, 0x64, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x72, 0x12,
+	0x3e, 0x0a, 0x06, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x26, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x2e, 0x76, 0x31, 0x2e, 0x4c, 0x6f, 0x61, 0x64, 0x42, 0x61, 0x6c, 0x61, 0x6e, 0x63, 0x65, 0x72,
+	0x2e, 0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x52, 0x06, 0x70, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12,
+	0x1f, 0x0a, 0x0b, 0x63, 0x6f, 0x73, 0x74, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x6f, 0x73, 0x74, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72,
+	0x12, 0x3a, 0x0a, 0x0a, 0x70, 0x61, 0x74, 0x68, 0x5f, 0x63, 0x6f, 0x73, 0x74, 0x73, 0x18, 0x03,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x50, 0x61, 0x74, 0x68, 0x43, 0x6f, 0x73,
+	0x74, 0x52, 0x09, 0x70, 0x61, 0x74, 0x68, 0x43, 0x6f, 0x73, 0x74, 0x73, 0x12, 0x21, 0x0a, 0x0c,
+	0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x5f, 0x63, 0x6f, 0x73, 0x74, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x03, 0x52, 0x0b, 0x64, 0x65, 0x66, 0x61, 0x75, 0x6c, 0x74, 0x43, 0x6f, 0x73, 0x74, 0x12,
+	0x19, 0x0a, 0x08, 0x6d, 0x61, 0x78, 0x5f, 0x63, 0x6f, 0x73, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x07, 0x6d, 0x61, 0x78, 0x43, 0x6f, 0x73, 0x74, 0x12, 0x3b, 0x0a, 0x0a, 0x73, 0x6c,
+	0x6f, 0x77, 0x5f, 0x73, 0x74, 0x61, 0x72, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1c,
+	0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e,
+	0x76, 0x31, 0x2e, 0x53, 0x6c, 0x6f, 0x77, 0x53, 0x74, 0x61, 0x72, 0x74, 0x52, 0x09, 0x73, 0x6c,
+	0x6f, 0x77, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x68,
+	0x61, 0x73, 0x68, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x68, 0x61, 0x73, 0x68, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x22, 0x21, 0x0a, 0x06,
+	0x50, 0x6f, 0x6c, 0x69, 0x63, 0x79, 0x12, 0x07, 0x0a, 0x03, 0x50, 0x32, 0x43, 0x10, 0x00, 0x12,
+	0x0e, 0x0a, 0x0a, 0x4c, 0x45, 0x41, 0x53, 0x54, 0x5f, 0x43, 0x4f, 0x53, 0x54, 0x10, 0x01, 0x22,
+	0x94, 0x01, 0x0a, 0x09, 0x53, 0x6c, 0x6f, 0x77, 0x53, 0x74, 0x61, 0x72, 0x74, 0x12, 0x31, 0x0a,
+	0x06, 0x77, 0x61, 0x72, 0x6d, 0x75, 0x70, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x77, 0x61, 0x72, 0x6d, 0x75, 0x70,
+	0x12, 0x35, 0x0a, 0x08, 0x63, 0x6f, 0x6f, 0x6c, 0x64, 0x6f, 0x77, 0x6e, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x63,
+	0x6f, 0x6f, 0x6c, 0x64, 0x6f, 0x77, 0x6e, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x69, 0x6e, 0x5f, 0x77,
+	0x65, 0x69, 0x67, 0x68, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x09, 0x6d, 0x69, 0x6e,
+	0x57, 0x65, 0x69, 0x67, 0x68, 0x74, 0x22, 0x36, 0x0a, 0x08, 0x50, 0x61, 0x74, 0x68, 0x43, 0x6f,
+	0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f,
+	0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x63, 0x6f, 0x73, 0x74, 0x22, 0x4d,
+	0x0a, 0x0b, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x12, 0x12, 0x0a,
+	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d,
+	0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x65, 0x67, 0x65, 0x78,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x72, 0x65, 0x67, 0x65, 0x78, 0x22, 0x4c, 0x0a,
+	0x0a, 0x51, 0x75, 0x65, 0x72, 0x79, 0x4d, 0x61, 0x74, 0x63, 0x68, 0x12, 0x12, 0x0a, 0x04, 0x6e,
+	0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12,
+	0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x65, 0x67, 0x65, 0x78, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x72, 0x65, 0x67, 0x65, 0x78, 0x22, 0xa9, 0x02, 0x0a, 0x07,
+	0x4d, 0x65, 0x74, 0x72, 0x69, 0x63, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x64, 0x69, 0x73, 0x61, 0x62,
+	0x6c, 0x65, 0x5f, 0x61, 0x6c, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x64, 0x69,
+	0x73, 0x61, 0x62, 0x6c, 0x65, 0x41, 0x6c, 0x6c, 0x12, 0x34, 0x0a, 0x16, 0x64, 0x69, 0x73, 0x61,
+	0x62, 0x6c, 0x65, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x5f, 0x74, 0x6f, 0x74,
+	0x61, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x14, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c,
+	0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x54, 0x6f, 0x74, 0x61, 0x6c, 0x12, 0x3a,
+	0x0a, 0x19, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x73, 0x5f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x17, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x73, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x23, 0x0a, 0x0d, 0x64, 0x69,
+	0x73, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x62, 0x79, 0x74, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x0c, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x42, 0x79, 0x74, 0x65, 0x73, 0x12,
+	0x2e, 0x0a, 0x13, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x72, 0x65, 0x74, 0x72, 0x79,
+	0x5f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08, 0x52, 0x11, 0x64, 0x69,
+	0x73, 0x61, 0x62, 0x6c, 0x65, 0x52, 0x65, 0x74, 0x72, 0x79, 0x53, 0x74, 0x61, 0x74, 0x65, 0x12,
+	0x36, 0x0a, 0x17, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x5f, 0x75, 0x70, 0x73, 0x74, 0x72,
+	0x65, 0x61, 0x6d, 0x5f, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x15, 0x64, 0x69, 0x73, 0x61, 0x62, 0x6c, 0x65, 0x55, 0x70, 0x73, 0x74, 0x72, 0x65, 0x61,
+	0x6d, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x22, 0x6c, 0x0a, 0x0a, 0x4d, 0x69, 0x64, 0x64, 0x6c,
+	0x65, 0x77, 0x61, 0x72, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x2e, 0x0a, 0x07, 0x6f, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f,
+	0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79,
+	0x52, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x71,
+	0x75, 0x69, 0x72, 0x65, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65, 0x71,
+	0x75, 0x69, 0x72, 0x65, 0x64, 0x22, 0x99, 0x04, 0x0a, 0x07, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e,
+	0x64, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x12, 0x1b, 0x0a, 0x06, 0x77, 0x65, 0x69,
+	0x67, 0x68, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x06, 0x77, 0x65, 0x69,
+	0x67, 0x68, 0x74, 0x88, 0x01, 0x01, 0x12, 0x41, 0x0a, 0x0c, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68,
+	0x5f, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x67,
+	0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31,
+	0x2e, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x0b, 0x68, 0x65,
+	0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x6c, 0x73,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x03, 0x74, 0x6c, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x74,
+	0x6c, 0x73, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x74, 0x6c, 0x73, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4e,
+	0x61, 0x6d, 0x65, 0x12, 0x44, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18,
+	0x06, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e,
 	0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e,
-	0x64, 0x52, 0x08, 0x62, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x73, 0x12, 0x2e, 0x0a, 0x05, 0x72,
-	0x65, 0x74, 0x72, 0x79, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x18, 0x2e, 0x67, 0x61, 0x74,
-	0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52,
-	0x65, 0x74, 0x72, 0x79, 0x52, 0x05, 0x72, 0x65, 0x74, 0x72, 0x79, 0x12, 0x45, 0x0a, 0x08, 0x6d,
-	0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x09, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x29, 0x2e,
+	0x64, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52,
+	0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x42, 0x0a, 0x0a, 0x72, 0x61, 0x74,
+	0x65, 0x5f, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e,
 	0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76,
-	0x31, 0x2e, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x64,
-	0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61,
-	0x74, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x6f, 0x73, 0x74, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x04, 0x68, 0x6f, 0x73, 0x74, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61,
-	0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
-	0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a,
-	0x02, 0x38, 0x01, 0x22, 0x6c, 0x0a, 0x0a, 0x4d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72,
-	0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x2e, 0x0a, 0x07, 0x6f, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x73,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x41, 0x6e, 0x79, 0x52, 0x07, 0x6f, 0x70,
-	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65,
-	0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x72, 0x65, 0x71, 0x75, 0x69, 0x72, 0x65,
-	0x64, 0x22, 0xc9, 0x02, 0x0a, 0x07, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x12, 0x16, 0x0a,
-	0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x74,
-	0x61, 0x72, 0x67, 0x65, 0x74, 0x12, 0x1b, 0x0a, 0x06, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x03, 0x48, 0x00, 0x52, 0x06, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x88,
-	0x01, 0x01, 0x12, 0x41, 0x0a, 0x0c, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x5f, 0x63, 0x68, 0x65,
-	0x63, 0x6b, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77,
-	0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x65, 0x61,
-	0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x52, 0x0b, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68,
-	0x43, 0x68, 0x65, 0x63, 0x6b, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x6c, 0x73, 0x18, 0x04, 0x20, 0x01,
-	0x28, 0x08, 0x52, 0x03, 0x74, 0x6c, 0x73, 0x12, 0x26, 0x0a, 0x0f, 0x74, 0x6c, 0x73, 0x5f, 0x63,
-	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x0d, 0x74, 0x6c, 0x73, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x4e, 0x61, 0x6d, 0x65, 0x12,
-	0x44, 0x0a, 0x08, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x06, 0x20, 0x03, 0x28,
-	0x0b, 0x32, 0x28, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66,
-	0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x2e, 0x4d, 0x65,
-	0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x08, 0x6d, 0x65, 0x74,
-	0x61, 0x64, 0x61, 0x74, 0x61, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74,
-	0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
-	0x38, 0x01, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x77, 0x65, 0x69, 0x67, 0x68, 0x74, 0x22, 0x0d, 0x0a,
-	0x0b, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65, 0x63, 0x6b, 0x22, 0xc4, 0x01, 0x0a,
-	0x05, 0x52, 0x65, 0x74, 0x72, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70,
-	0x74, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70,
-	0x74, 0x73, 0x12, 0x41, 0x0a, 0x0f, 0x70, 0x65, 0x72, 0x5f, 0x74, 0x72, 0x79, 0x5f, 0x74, 0x69,
-	0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f,
-	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75,
-	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0d, 0x70, 0x65, 0x72, 0x54, 0x72, 0x79, 0x54, 0x69,
-	0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x3c, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69,
-	0x6f, 0x6e, 0x73, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x67, 0x61, 0x74, 0x65,
-	0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f,
-	0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69,
-	0x6f, 0x6e, 0x73, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x69, 0x65,
-	0x73, 0x18, 0x04, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74,
-	0x69, 0x65, 0x73, 0x22, 0xb8, 0x01, 0x0a, 0x09, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f,
+	0x31, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d,
+	0x69, 0x74, 0x52, 0x09, 0x72, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x2f, 0x0a,
+	0x13, 0x69, 0x73, 0x6f, 0x6c, 0x61, 0x74, 0x65, 0x5f, 0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x08, 0x52, 0x12, 0x69, 0x73, 0x6f, 0x6c,
+	0x61, 0x74, 0x65, 0x43, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x21,
+	0x0a, 0x0c, 0x65, 0x67, 0x72, 0x65, 0x73, 0x73, 0x5f, 0x70, 0x72, 0x6f, 0x78, 0x79, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x65, 0x67, 0x72, 0x65, 0x73, 0x73, 0x50, 0x72, 0x6f, 0x78,
+	0x79, 0x12, 0x36, 0x0a, 0x17, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x5f, 0x74, 0x6c, 0x73, 0x5f, 0x72,
+	0x65, 0x6e, 0x65, 0x67, 0x6f, 0x74, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x0a, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x15, 0x61, 0x6c, 0x6c, 0x6f, 0x77, 0x54, 0x6c, 0x73, 0x52, 0x65, 0x6e, 0x65,
+	0x67, 0x6f, 0x74, 0x69, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x3b, 0x0a, 0x0d, 0x4d, 0x65, 0x74,
+	0x61, 0x64, 0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65,
+	0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c,
+	0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x42, 0x09, 0x0a, 0x07, 0x5f, 0x77, 0x65, 0x69, 0x67, 0x68,
+	0x74, 0x22, 0x8e, 0x01, 0x0a, 0x10, 0x42, 0x61, 0x63, 0x6b, 0x65, 0x6e, 0x64, 0x52, 0x61, 0x74,
+	0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x12, 0x2e, 0x0a, 0x13, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x11, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x50, 0x65, 0x72,
+	0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x75, 0x72, 0x73, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x05, 0x62, 0x75, 0x72, 0x73, 0x74, 0x12, 0x34, 0x0a, 0x08,
+	0x6d, 0x61, 0x78, 0x5f, 0x77, 0x61, 0x69, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x07, 0x6d, 0x61, 0x78, 0x57, 0x61,
+	0x69, 0x74, 0x22, 0x94, 0x02, 0x0a, 0x0b, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x43, 0x68, 0x65,
+	0x63, 0x6b, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x27, 0x0a, 0x0f, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74,
+	0x65, 0x64, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0e, 0x65, 0x78, 0x70, 0x65, 0x63, 0x74, 0x65, 0x64, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12,
+	0x35, 0x0a, 0x08, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x08, 0x69, 0x6e,
+	0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x12, 0x33, 0x0a, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75,
+	0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x52, 0x07, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x2f, 0x0a, 0x13, 0x75,
+	0x6e, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x5f, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f,
+	0x6c, 0x64, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x12, 0x75, 0x6e, 0x68, 0x65, 0x61, 0x6c,
+	0x74, 0x68, 0x79, 0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x12, 0x2b, 0x0a, 0x11,
+	0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x5f, 0x74, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c,
+	0x64, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x10, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79,
+	0x54, 0x68, 0x72, 0x65, 0x73, 0x68, 0x6f, 0x6c, 0x64, 0x22, 0xba, 0x02, 0x0a, 0x05, 0x52, 0x65,
+	0x74, 0x72, 0x79, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x12,
+	0x41, 0x0a, 0x0f, 0x70, 0x65, 0x72, 0x5f, 0x74, 0x72, 0x79, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f,
+	0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x52, 0x0d, 0x70, 0x65, 0x72, 0x54, 0x72, 0x79, 0x54, 0x69, 0x6d, 0x65, 0x6f,
+	0x75, 0x74, 0x12, 0x3c, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79,
+	0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6e, 0x64, 0x69,
+	0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73,
+	0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x69, 0x65, 0x73, 0x18, 0x04,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x0a, 0x70, 0x72, 0x69, 0x6f, 0x72, 0x69, 0x74, 0x69, 0x65, 0x73,
+	0x12, 0x39, 0x0a, 0x07, 0x62, 0x72, 0x65, 0x61, 0x6b, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1f, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x74, 0x72, 0x79, 0x42, 0x72, 0x65, 0x61, 0x6b,
+	0x65, 0x72, 0x52, 0x07, 0x62, 0x72, 0x65, 0x61, 0x6b, 0x65, 0x72, 0x12, 0x39, 0x0a, 0x07, 0x62,
+	0x61, 0x63, 0x6b, 0x6f, 0x66, 0x66, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x67,
+	0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31,
+	0x2e, 0x52, 0x65, 0x74, 0x72, 0x79, 0x42, 0x61, 0x63, 0x6b, 0x6f, 0x66, 0x66, 0x52, 0x07, 0x62,
+	0x61, 0x63, 0x6b, 0x6f, 0x66, 0x66, 0x22, 0xf3, 0x01, 0x0a, 0x0c, 0x52, 0x65, 0x74, 0x72, 0x79,
+	0x42, 0x61, 0x63, 0x6b, 0x6f, 0x66, 0x66, 0x12, 0x2d, 0x0a, 0x04, 0x62, 0x61, 0x73, 0x65, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x52, 0x04, 0x62, 0x61, 0x73, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x6d, 0x75, 0x6c, 0x74, 0x69, 0x70,
+	0x6c, 0x69, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x6d, 0x75, 0x6c, 0x74,
+	0x69, 0x70, 0x6c, 0x69, 0x65, 0x72, 0x12, 0x2b, 0x0a, 0x03, 0x6d, 0x61, 0x78, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x03,
+	0x6d, 0x61, 0x78, 0x12, 0x3e, 0x0a, 0x06, 0x6a, 0x69, 0x74, 0x74, 0x65, 0x72, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x0e, 0x32, 0x26, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x74, 0x72, 0x79, 0x42, 0x61, 0x63,
+	0x6b, 0x6f, 0x66, 0x66, 0x2e, 0x4a, 0x69, 0x74, 0x74, 0x65, 0x72, 0x52, 0x06, 0x6a, 0x69, 0x74,
+	0x74, 0x65, 0x72, 0x22, 0x27, 0x0a, 0x06, 0x4a, 0x69, 0x74, 0x74, 0x65, 0x72, 0x12, 0x08, 0x0a,
+	0x04, 0x4e, 0x4f, 0x4e, 0x45, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x46, 0x55, 0x4c, 0x4c, 0x10,
+	0x01, 0x12, 0x09, 0x0a, 0x05, 0x45, 0x51, 0x55, 0x41, 0x4c, 0x10, 0x02, 0x22, 0x75, 0x0a, 0x0c,
+	0x52, 0x65, 0x74, 0x72, 0x79, 0x42, 0x72, 0x65, 0x61, 0x6b, 0x65, 0x72, 0x12, 0x18, 0x0a, 0x07,
+	0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x73,
+	0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x07, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x31, 0x0a, 0x06, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x77, 0x69, 0x6e,
+	0x64, 0x6f, 0x77, 0x22, 0xea, 0x01, 0x0a, 0x09, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f,
 	0x6e, 0x12, 0x26, 0x0a, 0x0e, 0x62, 0x79, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x63,
 	0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0c, 0x62, 0x79, 0x53,
 	0x74, 0x61, 0x74, 0x75, 0x73, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x42, 0x0a, 0x09, 0x62, 0x79, 0x5f,
 	0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x23, 0x2e, 0x67,
 	0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31,
 	0x2e, 0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x68, 0x65, 0x61, 0x64, 0x65,
-	0x72, 0x48, 0x00, 0x52, 0x08, 0x62, 0x79, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x1a, 0x32, 0x0a,
+	0x72, 0x48, 0x00, 0x52, 0x08, 0x62, 0x79, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x1a, 0x64, 0x0a,
 	0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18,
 	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76,
 	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x42, 0x0b, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x2a, 0x2f,
-	0x0a, 0x08, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x0f, 0x0a, 0x0b, 0x55, 0x4e,
-	0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x48,
-	0x54, 0x54, 0x50, 0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x47, 0x52, 0x50, 0x43, 0x10, 0x02, 0x42,
-	0x34, 0x5a, 0x32, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x6f,
-	0x2d, 0x6b, 0x72, 0x61, 0x74, 0x6f, 0x73, 0x2f, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2f,
-	0x61, 0x70, 0x69, 0x2f, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2f, 0x63, 0x6f, 0x6e, 0x66,
-	0x69, 0x67, 0x2f, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x65, 0x12, 0x18, 0x0a, 0x07, 0x70, 0x72, 0x65, 0x73, 0x65, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x08, 0x52, 0x07, 0x70, 0x72, 0x65, 0x73, 0x65, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x6e,
+	0x65, 0x67, 0x61, 0x74, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x6e, 0x65, 0x67,
+	0x61, 0x74, 0x65, 0x42, 0x0b, 0x0a, 0x09, 0x63, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e,
+	0x2a, 0x86, 0x01, 0x0a, 0x0d, 0x54, 0x72, 0x61, 0x69, 0x6c, 0x69, 0x6e, 0x67, 0x53, 0x6c, 0x61,
+	0x73, 0x68, 0x12, 0x1e, 0x0a, 0x1a, 0x54, 0x52, 0x41, 0x49, 0x4c, 0x49, 0x4e, 0x47, 0x5f, 0x53,
+	0x4c, 0x41, 0x53, 0x48, 0x5f, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44,
+	0x10, 0x00, 0x12, 0x18, 0x0a, 0x14, 0x54, 0x52, 0x41, 0x49, 0x4c, 0x49, 0x4e, 0x47, 0x5f, 0x53,
+	0x4c, 0x41, 0x53, 0x48, 0x5f, 0x45, 0x58, 0x41, 0x43, 0x54, 0x10, 0x01, 0x12, 0x1e, 0x0a, 0x1a,
+	0x54, 0x52, 0x41, 0x49, 0x4c, 0x49, 0x4e, 0x47, 0x5f, 0x53, 0x4c, 0x41, 0x53, 0x48, 0x5f, 0x54,
+	0x52, 0x41, 0x4e, 0x53, 0x50, 0x41, 0x52, 0x45, 0x4e, 0x54, 0x10, 0x02, 0x12, 0x1b, 0x0a, 0x17,
+	0x54, 0x52, 0x41, 0x49, 0x4c, 0x49, 0x4e, 0x47, 0x5f, 0x53, 0x4c, 0x41, 0x53, 0x48, 0x5f, 0x52,
+	0x45, 0x44, 0x49, 0x52, 0x45, 0x43, 0x54, 0x10, 0x03, 0x2a, 0x2f, 0x0a, 0x08, 0x50, 0x72, 0x6f,
+	0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x12, 0x0f, 0x0a, 0x0b, 0x55, 0x4e, 0x53, 0x50, 0x45, 0x43, 0x49,
+	0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x48, 0x54, 0x54, 0x50, 0x10, 0x01,
+	0x12, 0x08, 0x0a, 0x04, 0x47, 0x52, 0x50, 0x43, 0x10, 0x02, 0x42, 0x34, 0x5a, 0x32, 0x67, 0x69,
+	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x6f, 0x2d, 0x6b, 0x72, 0x61, 0x74,
+	0x6f, 0x73, 0x2f, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x67,
+	0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2f, 0x76, 0x31,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -978,49 +4003,143 @@ func file_gateway_config_v1_gateway_proto_rawDescGZIP() []byte {
 	return file_gateway_config_v1_gateway_proto_rawDescData
 }
 
-var file_gateway_config_v1_gateway_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_gateway_config_v1_gateway_proto_msgTypes = make([]protoimpl.MessageInfo, 13)
+var file_gateway_config_v1_gateway_proto_enumTypes = make([]protoimpl.EnumInfo, 7)
+var file_gateway_config_v1_gateway_proto_msgTypes = make([]protoimpl.MessageInfo, 42)
 var file_gateway_config_v1_gateway_proto_goTypes = []interface{}{
-	(Protocol)(0),               // 0: gateway.config.v1.Protocol
-	(*Gateway)(nil),             // 1: gateway.config.v1.Gateway
-	(*TLS)(nil),                 // 2: gateway.config.v1.TLS
-	(*PriorityConfig)(nil),      // 3: gateway.config.v1.PriorityConfig
-	(*Endpoint)(nil),            // 4: gateway.config.v1.Endpoint
-	(*Middleware)(nil),          // 5: gateway.config.v1.Middleware
-	(*Backend)(nil),             // 6: gateway.config.v1.Backend
-	(*HealthCheck)(nil),         // 7: gateway.config.v1.HealthCheck
-	(*Retry)(nil),               // 8: gateway.config.v1.Retry
-	(*Condition)(nil),           // 9: gateway.config.v1.Condition
-	nil,                         // 10: gateway.config.v1.Gateway.TlsStoreEntry
-	nil,                         // 11: gateway.config.v1.Endpoint.MetadataEntry
-	nil,                         // 12: gateway.config.v1.Backend.MetadataEntry
-	(*ConditionHeader)(nil),     // 13: gateway.config.v1.Condition.header
-	(*durationpb.Duration)(nil), // 14: google.protobuf.Duration
-	(*anypb.Any)(nil),           // 15: google.protobuf.Any
+	(TrailingSlash)(0),           // 0: gateway.config.v1.TrailingSlash
+	(Protocol)(0),                // 1: gateway.config.v1.Protocol
+	(ErrorBudget_Action)(0),      // 2: gateway.config.v1.ErrorBudget.Action
+	(DualWrite_FailurePolicy)(0), // 3: gateway.config.v1.DualWrite.FailurePolicy
+	(ResponseLimit_Action)(0),    // 4: gateway.config.v1.ResponseLimit.Action
+	(LoadBalancer_Policy)(0),     // 5: gateway.config.v1.LoadBalancer.Policy
+	(RetryBackoff_Jitter)(0),     // 6: gateway.config.v1.RetryBackoff.Jitter
+	(*Gateway)(nil),              // 7: gateway.config.v1.Gateway
+	(*VirtualHost)(nil),          // 8: gateway.config.v1.VirtualHost
+	(*TenantRouting)(nil),        // 9: gateway.config.v1.TenantRouting
+	(*Tenant)(nil),               // 10: gateway.config.v1.Tenant
+	(*MethodOverride)(nil),       // 11: gateway.config.v1.MethodOverride
+	(*TLS)(nil),                  // 12: gateway.config.v1.TLS
+	(*PriorityConfig)(nil),       // 13: gateway.config.v1.PriorityConfig
+	(*Endpoint)(nil),             // 14: gateway.config.v1.Endpoint
+	(*BackendSchedule)(nil),      // 15: gateway.config.v1.BackendSchedule
+	(*ScheduleWindow)(nil),       // 16: gateway.config.v1.ScheduleWindow
+	(*ErrorBudget)(nil),          // 17: gateway.config.v1.ErrorBudget
+	(*DualWrite)(nil),            // 18: gateway.config.v1.DualWrite
+	(*OutlierDetection)(nil),     // 19: gateway.config.v1.OutlierDetection
+	(*Backpressure)(nil),         // 20: gateway.config.v1.Backpressure
+	(*LatencySLO)(nil),           // 21: gateway.config.v1.LatencySLO
+	(*UpstreamHeaderLimit)(nil),  // 22: gateway.config.v1.UpstreamHeaderLimit
+	(*RequestBuffering)(nil),     // 23: gateway.config.v1.RequestBuffering
+	(*ResponseBuffering)(nil),    // 24: gateway.config.v1.ResponseBuffering
+	(*ResponseLimit)(nil),        // 25: gateway.config.v1.ResponseLimit
+	(*BackendGroupRouting)(nil),  // 26: gateway.config.v1.BackendGroupRouting
+	(*RejectResponse)(nil),       // 27: gateway.config.v1.RejectResponse
+	(*LoadBalancer)(nil),         // 28: gateway.config.v1.LoadBalancer
+	(*SlowStart)(nil),            // 29: gateway.config.v1.SlowStart
+	(*PathCost)(nil),             // 30: gateway.config.v1.PathCost
+	(*HeaderMatch)(nil),          // 31: gateway.config.v1.HeaderMatch
+	(*QueryMatch)(nil),           // 32: gateway.config.v1.QueryMatch
+	(*Metrics)(nil),              // 33: gateway.config.v1.Metrics
+	(*Middleware)(nil),           // 34: gateway.config.v1.Middleware
+	(*Backend)(nil),              // 35: gateway.config.v1.Backend
+	(*BackendRateLimit)(nil),     // 36: gateway.config.v1.BackendRateLimit
+	(*HealthCheck)(nil),          // 37: gateway.config.v1.HealthCheck
+	(*Retry)(nil),                // 38: gateway.config.v1.Retry
+	(*RetryBackoff)(nil),         // 39: gateway.config.v1.RetryBackoff
+	(*RetryBreaker)(nil),         // 40: gateway.config.v1.RetryBreaker
+	(*Condition)(nil),            // 41: gateway.config.v1.Condition
+	nil,                          // 42: gateway.config.v1.Gateway.TlsStoreEntry
+	nil,                          // 43: gateway.config.v1.Gateway.EndpointTemplatesEntry
+	nil,                          // 44: gateway.config.v1.TenantRouting.TenantsEntry
+	nil,                          // 45: gateway.config.v1.Endpoint.MetadataEntry
+	nil,                          // 46: gateway.config.v1.RejectResponse.HeadersEntry
+	nil,                          // 47: gateway.config.v1.Backend.MetadataEntry
+	(*ConditionHeader)(nil),      // 48: gateway.config.v1.Condition.header
+	(*durationpb.Duration)(nil),  // 49: google.protobuf.Duration
+	(*anypb.Any)(nil),            // 50: google.protobuf.Any
 }
 var file_gateway_config_v1_gateway_proto_depIdxs = []int32{
-	4,  // 0: gateway.config.v1.Gateway.endpoints:type_name -> gateway.config.v1.Endpoint
-	5,  // 1: gateway.config.v1.Gateway.middlewares:type_name -> gateway.config.v1.Middleware
-	10, // 2: gateway.config.v1.Gateway.tls_store:type_name -> gateway.config.v1.Gateway.TlsStoreEntry
-	4,  // 3: gateway.config.v1.PriorityConfig.endpoints:type_name -> gateway.config.v1.Endpoint
-	0,  // 4: gateway.config.v1.Endpoint.protocol:type_name -> gateway.config.v1.Protocol
-	14, // 5: gateway.config.v1.Endpoint.timeout:type_name -> google.protobuf.Duration
-	5,  // 6: gateway.config.v1.Endpoint.middlewares:type_name -> gateway.config.v1.Middleware
-	6,  // 7: gateway.config.v1.Endpoint.backends:type_name -> gateway.config.v1.Backend
-	8,  // 8: gateway.config.v1.Endpoint.retry:type_name -> gateway.config.v1.Retry
-	11, // 9: gateway.config.v1.Endpoint.metadata:type_name -> gateway.config.v1.Endpoint.MetadataEntry
-	15, // 10: gateway.config.v1.Middleware.options:type_name -> google.protobuf.Any
-	7,  // 11: gateway.config.v1.Backend.health_check:type_name -> gateway.config.v1.HealthCheck
-	12, // 12: gateway.config.v1.Backend.metadata:type_name -> gateway.config.v1.Backend.MetadataEntry
-	14, // 13: gateway.config.v1.Retry.per_try_timeout:type_name -> google.protobuf.Duration
-	9,  // 14: gateway.config.v1.Retry.conditions:type_name -> gateway.config.v1.Condition
-	13, // 15: gateway.config.v1.Condition.by_header:type_name -> gateway.config.v1.Condition.header
-	2,  // 16: gateway.config.v1.Gateway.TlsStoreEntry.value:type_name -> gateway.config.v1.TLS
-	17, // [17:17] is the sub-list for method output_type
-	17, // [17:17] is the sub-list for method input_type
-	17, // [17:17] is the sub-list for extension type_name
-	17, // [17:17] is the sub-list for extension extendee
-	0,  // [0:17] is the sub-list for field type_name
+	14, // 0: gateway.config.v1.Gateway.endpoints:type_name -> gateway.config.v1.Endpoint
+	34, // 1: gateway.config.v1.Gateway.middlewares:type_name -> gateway.config.v1.Middleware
+	42, // 2: gateway.config.v1.Gateway.tls_store:type_name -> gateway.config.v1.Gateway.TlsStoreEntry
+	11, // 3: gateway.config.v1.Gateway.method_override:type_name -> gateway.config.v1.MethodOverride
+	14, // 4: gateway.config.v1.Gateway.default_endpoint:type_name -> gateway.config.v1.Endpoint
+	43, // 5: gateway.config.v1.Gateway.endpoint_templates:type_name -> gateway.config.v1.Gateway.EndpointTemplatesEntry
+	9,  // 6: gateway.config.v1.Gateway.tenant_routing:type_name -> gateway.config.v1.TenantRouting
+	8,  // 7: gateway.config.v1.Gateway.virtual_hosts:type_name -> gateway.config.v1.VirtualHost
+	34, // 8: gateway.config.v1.VirtualHost.middlewares:type_name -> gateway.config.v1.Middleware
+	35, // 9: gateway.config.v1.VirtualHost.backends:type_name -> gateway.config.v1.Backend
+	14, // 10: gateway.config.v1.VirtualHost.endpoints:type_name -> gateway.config.v1.Endpoint
+	44, // 11: gateway.config.v1.TenantRouting.tenants:type_name -> gateway.config.v1.TenantRouting.TenantsEntry
+	14, // 12: gateway.config.v1.Tenant.endpoints:type_name -> gateway.config.v1.Endpoint
+	14, // 13: gateway.config.v1.PriorityConfig.endpoints:type_name -> gateway.config.v1.Endpoint
+	1,  // 14: gateway.config.v1.Endpoint.protocol:type_name -> gateway.config.v1.Protocol
+	49, // 15: gateway.config.v1.Endpoint.timeout:type_name -> google.protobuf.Duration
+	34, // 16: gateway.config.v1.Endpoint.middlewares:type_name -> gateway.config.v1.Middleware
+	35, // 17: gateway.config.v1.Endpoint.backends:type_name -> gateway.config.v1.Backend
+	38, // 18: gateway.config.v1.Endpoint.retry:type_name -> gateway.config.v1.Retry
+	45, // 19: gateway.config.v1.Endpoint.metadata:type_name -> gateway.config.v1.Endpoint.MetadataEntry
+	33, // 20: gateway.config.v1.Endpoint.metrics:type_name -> gateway.config.v1.Metrics
+	0,  // 21: gateway.config.v1.Endpoint.trailing_slash:type_name -> gateway.config.v1.TrailingSlash
+	31, // 22: gateway.config.v1.Endpoint.headers:type_name -> gateway.config.v1.HeaderMatch
+	32, // 23: gateway.config.v1.Endpoint.queries:type_name -> gateway.config.v1.QueryMatch
+	28, // 24: gateway.config.v1.Endpoint.load_balancer:type_name -> gateway.config.v1.LoadBalancer
+	25, // 25: gateway.config.v1.Endpoint.response_limit:type_name -> gateway.config.v1.ResponseLimit
+	26, // 26: gateway.config.v1.Endpoint.backend_group:type_name -> gateway.config.v1.BackendGroupRouting
+	23, // 27: gateway.config.v1.Endpoint.request_buffering:type_name -> gateway.config.v1.RequestBuffering
+	19, // 28: gateway.config.v1.Endpoint.outlier_detection:type_name -> gateway.config.v1.OutlierDetection
+	27, // 29: gateway.config.v1.Endpoint.method_not_allowed:type_name -> gateway.config.v1.RejectResponse
+	24, // 30: gateway.config.v1.Endpoint.response_buffering:type_name -> gateway.config.v1.ResponseBuffering
+	20, // 31: gateway.config.v1.Endpoint.backpressure:type_name -> gateway.config.v1.Backpressure
+	22, // 32: gateway.config.v1.Endpoint.upstream_header_limit:type_name -> gateway.config.v1.UpstreamHeaderLimit
+	21, // 33: gateway.config.v1.Endpoint.latency_slo:type_name -> gateway.config.v1.LatencySLO
+	17, // 34: gateway.config.v1.Endpoint.error_budget:type_name -> gateway.config.v1.ErrorBudget
+	15, // 35: gateway.config.v1.Endpoint.backend_schedules:type_name -> gateway.config.v1.BackendSchedule
+	18, // 36: gateway.config.v1.Endpoint.dual_write:type_name -> gateway.config.v1.DualWrite
+	16, // 37: gateway.config.v1.BackendSchedule.windows:type_name -> gateway.config.v1.ScheduleWindow
+	35, // 38: gateway.config.v1.BackendSchedule.backends:type_name -> gateway.config.v1.Backend
+	49, // 39: gateway.config.v1.ErrorBudget.window:type_name -> google.protobuf.Duration
+	2,  // 40: gateway.config.v1.ErrorBudget.action:type_name -> gateway.config.v1.ErrorBudget.Action
+	35, // 41: gateway.config.v1.ErrorBudget.fallback_backends:type_name -> gateway.config.v1.Backend
+	35, // 42: gateway.config.v1.DualWrite.secondaries:type_name -> gateway.config.v1.Backend
+	3,  // 43: gateway.config.v1.DualWrite.on_failure:type_name -> gateway.config.v1.DualWrite.FailurePolicy
+	49, // 44: gateway.config.v1.DualWrite.timeout:type_name -> google.protobuf.Duration
+	49, // 45: gateway.config.v1.OutlierDetection.window:type_name -> google.protobuf.Duration
+	49, // 46: gateway.config.v1.OutlierDetection.cooldown:type_name -> google.protobuf.Duration
+	49, // 47: gateway.config.v1.OutlierDetection.max_cooldown:type_name -> google.protobuf.Duration
+	49, // 48: gateway.config.v1.Backpressure.max_retry_after:type_name -> google.protobuf.Duration
+	49, // 49: gateway.config.v1.LatencySLO.threshold:type_name -> google.protobuf.Duration
+	4,  // 50: gateway.config.v1.ResponseLimit.action:type_name -> gateway.config.v1.ResponseLimit.Action
+	46, // 51: gateway.config.v1.RejectResponse.headers:type_name -> gateway.config.v1.RejectResponse.HeadersEntry
+	5,  // 52: gateway.config.v1.LoadBalancer.policy:type_name -> gateway.config.v1.LoadBalancer.Policy
+	30, // 53: gateway.config.v1.LoadBalancer.path_costs:type_name -> gateway.config.v1.PathCost
+	29, // 54: gateway.config.v1.LoadBalancer.slow_start:type_name -> gateway.config.v1.SlowStart
+	49, // 55: gateway.config.v1.SlowStart.warmup:type_name -> google.protobuf.Duration
+	49, // 56: gateway.config.v1.SlowStart.cooldown:type_name -> google.protobuf.Duration
+	50, // 57: gateway.config.v1.Middleware.options:type_name -> google.protobuf.Any
+	37, // 58: gateway.config.v1.Backend.health_check:type_name -> gateway.config.v1.HealthCheck
+	47, // 59: gateway.config.v1.Backend.metadata:type_name -> gateway.config.v1.Backend.MetadataEntry
+	36, // 60: gateway.config.v1.Backend.rate_limit:type_name -> gateway.config.v1.BackendRateLimit
+	49, // 61: gateway.config.v1.BackendRateLimit.max_wait:type_name -> google.protobuf.Duration
+	49, // 62: gateway.config.v1.HealthCheck.interval:type_name -> google.protobuf.Duration
+	49, // 63: gateway.config.v1.HealthCheck.timeout:type_name -> google.protobuf.Duration
+	49, // 64: gateway.config.v1.Retry.per_try_timeout:type_name -> google.protobuf.Duration
+	41, // 65: gateway.config.v1.Retry.conditions:type_name -> gateway.config.v1.Condition
+	40, // 66: gateway.config.v1.Retry.breaker:type_name -> gateway.config.v1.RetryBreaker
+	39, // 67: gateway.config.v1.Retry.backoff:type_name -> gateway.config.v1.RetryBackoff
+	49, // 68: gateway.config.v1.RetryBackoff.base:type_name -> google.protobuf.Duration
+	49, // 69: gateway.config.v1.RetryBackoff.max:type_name -> google.protobuf.Duration
+	6,  // 70: gateway.config.v1.RetryBackoff.jitter:type_name -> gateway.config.v1.RetryBackoff.Jitter
+	49, // 71: gateway.config.v1.RetryBreaker.window:type_name -> google.protobuf.Duration
+	48, // 72: gateway.config.v1.Condition.by_header:type_name -> gateway.config.v1.Condition.header
+	12, // 73: gateway.config.v1.Gateway.TlsStoreEntry.value:type_name -> gateway.config.v1.TLS
+	14, // 74: gateway.config.v1.Gateway.EndpointTemplatesEntry.value:type_name -> gateway.config.v1.Endpoint
+	10, // 75: gateway.config.v1.TenantRouting.TenantsEntry.value:type_name -> gateway.config.v1.Tenant
+	76, // [76:76] is the sub-list for method output_type
+	76, // [76:76] is the sub-list for method input_type
+	76, // [76:76] is the sub-list for extension type_name
+	76, // [76:76] is the sub-list for extension extendee
+	0,  // [0:76] is the sub-list for field type_name
 }
 
 func init() { file_gateway_config_v1_gateway_proto_init() }
@@ -1041,7 +4160,55 @@ func file_gateway_config_v1_gateway_proto_init() {
 				return nil
 			}
 		}
-		file_gateway_config_v1_gateway_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+		file_gateway_config_v1_gateway_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VirtualHost); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TenantRouting); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Tenant); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MethodOverride); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*TLS); i {
 			case 0:
 				return &v.state
@@ -1053,7 +4220,7 @@ func file_gateway_config_v1_gateway_proto_init() {
 				return nil
 			}
 		}
-		file_gateway_config_v1_gateway_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+		file_gateway_config_v1_gateway_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*PriorityConfig); i {
 			case 0:
 				return &v.state
@@ -1065,7 +4232,7 @@ func file_gateway_config_v1_gateway_proto_init() {
 				return nil
 			}
 		}
-		file_gateway_config_v1_gateway_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+		file_gateway_config_v1_gateway_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Endpoint); i {
 			case 0:
 				return &v.state
@@ -1077,7 +4244,235 @@ func file_gateway_config_v1_gateway_proto_init() {
 				return nil
 			}
 		}
-		file_gateway_config_v1_gateway_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+		file_gateway_config_v1_gateway_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BackendSchedule); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ScheduleWindow); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ErrorBudget); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DualWrite); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*OutlierDetection); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Backpressure); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LatencySLO); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*UpstreamHeaderLimit); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RequestBuffering); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResponseBuffering); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResponseLimit); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BackendGroupRouting); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RejectResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*LoadBalancer); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SlowStart); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*PathCost); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*HeaderMatch); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*QueryMatch); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Metrics); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Middleware); i {
 			case 0:
 				return &v.state
@@ -1089,7 +4484,7 @@ func file_gateway_config_v1_gateway_proto_init() {
 				return nil
 			}
 		}
-		file_gateway_config_v1_gateway_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+		file_gateway_config_v1_gateway_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Backend); i {
 			case 0:
 				return &v.state
@@ -1101,7 +4496,19 @@ func file_gateway_config_v1_gateway_proto_init() {
 				return nil
 			}
 		}
-		file_gateway_config_v1_gateway_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+		file_gateway_config_v1_gateway_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BackendRateLimit); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*HealthCheck); i {
 			case 0:
 				return &v.state
@@ -1113,7 +4520,7 @@ func file_gateway_config_v1_gateway_proto_init() {
 				return nil
 			}
 		}
-		file_gateway_config_v1_gateway_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+		file_gateway_config_v1_gateway_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Retry); i {
 			case 0:
 				return &v.state
@@ -1125,7 +4532,31 @@ func file_gateway_config_v1_gateway_proto_init() {
 				return nil
 			}
 		}
-		file_gateway_config_v1_gateway_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+		file_gateway_config_v1_gateway_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RetryBackoff); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RetryBreaker); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_config_v1_gateway_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*Condition); i {
 			case 0:
 				return &v.state
@@ -1137,7 +4568,7 @@ func file_gateway_config_v1_gateway_proto_init() {
 				return nil
 			}
 		}
-		file_gateway_config_v1_gateway_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+		file_gateway_config_v1_gateway_proto_msgTypes[41].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*ConditionHeader); i {
 			case 0:
 				return &v.state
@@ -1150,8 +4581,8 @@ func file_gateway_config_v1_gateway_proto_init() {
 			}
 		}
 	}
-	file_gateway_config_v1_gateway_proto_msgTypes[5].OneofWrappers = []interface{}{}
-	file_gateway_config_v1_gateway_proto_msgTypes[8].OneofWrappers = []interface{}{
+	file_gateway_config_v1_gateway_proto_msgTypes[28].OneofWrappers = []interface{}{}
+	file_gateway_config_v1_gateway_proto_msgTypes[34].OneofWrappers = []interface{}{
 		(*Condition_ByStatusCode)(nil),
 		(*Condition_ByHeader)(nil),
 	}
@@ -1160,8 +4591,8 @@ func file_gateway_config_v1_gateway_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_gateway_config_v1_gateway_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   13,
+			NumEnums:      7,
+			NumMessages:   42,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
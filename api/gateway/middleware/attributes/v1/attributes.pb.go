@@ -0,0 +1,316 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        v4.25.1
+// source: gateway/middleware/attributes/v1/attributes.proto
+
+package v1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Attributes middleware config.
+type Attributes struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Attributes []*Attribute `protobuf:"bytes,1,rep,name=attributes,proto3" json:"attributes,omitempty"`
+	// the maximum JSON request body read for body attributes, default is 1MiB,
+	// larger bodies are forwarded without extracting
+	MaxBodyBytes int64 `protobuf:"varint,2,opt,name=max_body_bytes,json=maxBodyBytes,proto3" json:"max_body_bytes,omitempty"`
+}
+
+func (x *Attributes) Reset() {
+	*x = Attributes{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_middleware_attributes_v1_attributes_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Attributes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Attributes) ProtoMessage() {}
+
+func (x *Attributes) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_middleware_attributes_v1_attributes_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Attributes.ProtoReflect.Descriptor instead.
+func (*Attributes) Descriptor() ([]byte, []int) {
+	return file_gateway_middleware_attributes_v1_attributes_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Attributes) GetAttributes() []*Attribute {
+	if x != nil {
+		return x.Attributes
+	}
+	return nil
+}
+
+func (x *Attributes) GetMaxBodyBytes() int64 {
+	if x != nil {
+		return x.MaxBodyBytes
+	}
+	return 0
+}
+
+// Attribute extracts a named value from the request.
+type Attribute struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Types that are assignable to Source:
+	//
+	//	*Attribute_Path
+	//	*Attribute_Query
+	//	*Attribute_Body
+	//	*Attribute_Header
+	Source isAttribute_Source `protobuf_oneof:"source"`
+}
+
+func (x *Attribute) Reset() {
+	*x = Attribute{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_middleware_attributes_v1_attributes_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Attribute) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Attribute) ProtoMessage() {}
+
+func (x *Attribute) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_middleware_attributes_v1_attributes_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Attribute.ProtoReflect.Descriptor instead.
+func (*Attribute) Descriptor() ([]byte, []int) {
+	return file_gateway_middleware_attributes_v1_attributes_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Attribute) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (m *Attribute) GetSource() isAttribute_Source {
+	if m != nil {
+		return m.Source
+	}
+	return nil
+}
+
+func (x *Attribute) GetPath() string {
+	if x, ok := x.GetSource().(*Attribute_Path); ok {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *Attribute) GetQuery() string {
+	if x, ok := x.GetSource().(*Attribute_Query); ok {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *Attribute) GetBody() string {
+	if x, ok := x.GetSource().(*Attribute_Body); ok {
+		return x.Body
+	}
+	return ""
+}
+
+func (x *Attribute) GetHeader() string {
+	if x, ok := x.GetSource().(*Attribute_Header); ok {
+		return x.Header
+	}
+	return ""
+}
+
+type isAttribute_Source interface {
+	isAttribute_Source()
+}
+
+type Attribute_Path struct {
+	// path template like /users/{user_id}/orders/*, the value of the variable
+	// named as the attribute is extracted, * matches a segment and ** the rest of the path
+	Path string `protobuf:"bytes,2,opt,name=path,proto3,oneof"`
+}
+
+type Attribute_Query struct {
+	// query parameter
+	Query string `protobuf:"bytes,3,opt,name=query,proto3,oneof"`
+}
+
+type Attribute_Body struct {
+	// dot separated field path in the JSON request body, like user.id or items.0.sku
+	Body string `protobuf:"bytes,4,opt,name=body,proto3,oneof"`
+}
+
+type Attribute_Header struct {
+	Header string `protobuf:"bytes,5,opt,name=header,proto3,oneof"`
+}
+
+func (*Attribute_Path) isAttribute_Source() {}
+
+func (*Attribute_Query) isAttribute_Source() {}
+
+func (*Attribute_Body) isAttribute_Source() {}
+
+func (*Attribute_Header) isAttribute_Source() {}
+
+var File_gateway_middleware_attributes_v1_attributes_proto protoreflect.FileDescriptor
+
+var file_gateway_middleware_attributes_v1_attributes_proto_rawDesc = []byte{
+	0x0a, 0x31, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2f, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65,
+	0x77, 0x61, 0x72, 0x65, 0x2f, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x2f,
+	0x76, 0x31, 0x2f, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x12, 0x20, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x6d, 0x69, 0x64,
+	0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x2e, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74,
+	0x65, 0x73, 0x2e, 0x76, 0x31, 0x22, 0x7f, 0x0a, 0x0a, 0x41, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75,
+	0x74, 0x65, 0x73, 0x12, 0x4b, 0x0a, 0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2b, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61,
+	0x79, 0x2e, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x2e, 0x61, 0x74, 0x74,
+	0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73, 0x2e, 0x76, 0x31, 0x2e, 0x41, 0x74, 0x74, 0x72, 0x69,
+	0x62, 0x75, 0x74, 0x65, 0x52, 0x0a, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74, 0x65, 0x73,
+	0x12, 0x24, 0x0a, 0x0e, 0x6d, 0x61, 0x78, 0x5f, 0x62, 0x6f, 0x64, 0x79, 0x5f, 0x62, 0x79, 0x74,
+	0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0c, 0x6d, 0x61, 0x78, 0x42, 0x6f, 0x64,
+	0x79, 0x42, 0x79, 0x74, 0x65, 0x73, 0x22, 0x87, 0x01, 0x0a, 0x09, 0x41, 0x74, 0x74, 0x72, 0x69,
+	0x62, 0x75, 0x74, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x04, 0x6e, 0x61, 0x6d, 0x65, 0x12, 0x14, 0x0a, 0x04, 0x70, 0x61, 0x74, 0x68,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x70, 0x61, 0x74, 0x68, 0x12, 0x16,
+	0x0a, 0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52,
+	0x05, 0x71, 0x75, 0x65, 0x72, 0x79, 0x12, 0x14, 0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x04,
+	0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x12, 0x18, 0x0a, 0x06,
+	0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x06,
+	0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x42, 0x08, 0x0a, 0x06, 0x73, 0x6f, 0x75, 0x72, 0x63, 0x65,
+	0x42, 0x43, 0x5a, 0x41, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67,
+	0x6f, 0x2d, 0x6b, 0x72, 0x61, 0x74, 0x6f, 0x73, 0x2f, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79,
+	0x2f, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2f, 0x6d, 0x69, 0x64,
+	0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x2f, 0x61, 0x74, 0x74, 0x72, 0x69, 0x62, 0x75, 0x74,
+	0x65, 0x73, 0x2f, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_gateway_middleware_attributes_v1_attributes_proto_rawDescOnce sync.Once
+	file_gateway_middleware_attributes_v1_attributes_proto_rawDescData = file_gateway_middleware_attributes_v1_attributes_proto_rawDesc
+)
+
+func file_gateway_middleware_attributes_v1_attributes_proto_rawDescGZIP() []byte {
+	file_gateway_middleware_attributes_v1_attributes_proto_rawDescOnce.Do(func() {
+		file_gateway_middleware_attributes_v1_attributes_proto_rawDescData = protoimpl.X.CompressGZIP(file_gateway_middleware_attributes_v1_attributes_proto_rawDescData)
+	})
+	return file_gateway_middleware_attributes_v1_attributes_proto_rawDescData
+}
+
+var file_gateway_middleware_attributes_v1_attributes_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_gateway_middleware_attributes_v1_attributes_proto_goTypes = []interface{}{
+	(*Attributes)(nil), // 0: gateway.middleware.attributes.v1.Attributes
+	(*Attribute)(nil),  // 1: gateway.middleware.attributes.v1.Attribute
+}
+var file_gateway_middleware_attributes_v1_attributes_proto_depIdxs = []int32{
+	1, // 0: gateway.middleware.attributes.v1.Attributes.attributes:type_name -> gateway.middleware.attributes.v1.Attribute
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_gateway_middleware_attributes_v1_attributes_proto_init() }
+func file_gateway_middleware_attributes_v1_attributes_proto_init() {
+	if File_gateway_middleware_attributes_v1_attributes_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_gateway_middleware_attributes_v1_attributes_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Attributes); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_middleware_attributes_v1_attributes_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Attribute); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	file_gateway_middleware_attributes_v1_attributes_proto_msgTypes[1].OneofWrappers = []interface{}{
+		(*Attribute_Path)(nil),
+		(*Attribute_Query)(nil),
+		(*Attribute_Body)(nil),
+		(*Attribute_Header)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_gateway_middleware_attributes_v1_attributes_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_gateway_middleware_attributes_v1_attributes_proto_goTypes,
+		DependencyIndexes: file_gateway_middleware_attributes_v1_attributes_proto_depIdxs,
+		MessageInfos:      file_gateway_middleware_attributes_v1_attributes_proto_msgTypes,
+	}.Build()
+	File_gateway_middleware_attributes_v1_attributes_proto = out.File
+	file_gateway_middleware_attributes_v1_attributes_proto_rawDesc = nil
+	file_gateway_middleware_attributes_v1_attributes_proto_goTypes = nil
+	file_gateway_middleware_attributes_v1_attributes_proto_depIdxs = nil
+}
@@ -0,0 +1,157 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        v4.25.1
+// source: gateway/middleware/bbr/v1/bbr.proto
+
+package v1
+
+import (
+	v1 "github.com/cnsync/gateway/api/gateway/config/v1"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// BBR middleware config.
+type BBR struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// response for rejected requests, default is an empty 429
+	RejectResponse *v1.RejectResponse `protobuf:"bytes,1,opt,name=reject_response,json=rejectResponse,proto3" json:"reject_response,omitempty"`
+}
+
+func (x *BBR) Reset() {
+	*x = BBR{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_middleware_bbr_v1_bbr_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BBR) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BBR) ProtoMessage() {}
+
+func (x *BBR) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_middleware_bbr_v1_bbr_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BBR.ProtoReflect.Descriptor instead.
+func (*BBR) Descriptor() ([]byte, []int) {
+	return file_gateway_middleware_bbr_v1_bbr_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *BBR) GetRejectResponse() *v1.RejectResponse {
+	if x != nil {
+		return x.RejectResponse
+	}
+	return nil
+}
+
+var File_gateway_middleware_bbr_v1_bbr_proto protoreflect.FileDescriptor
+
+var file_gateway_middleware_bbr_v1_bbr_proto_rawDesc = []byte{
+	0x0a, 0x23, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2f, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65,
+	0x77, 0x61, 0x72, 0x65, 0x2f, 0x62, 0x62, 0x72, 0x2f, 0x76, 0x31, 0x2f, 0x62, 0x62, 0x72, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x19, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x6d,
+	0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x2e, 0x62, 0x62, 0x72, 0x2e, 0x76, 0x31,
+	0x1a, 0x1f, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x2f, 0x76, 0x31, 0x2f, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x22, 0x51, 0x0a, 0x03, 0x42, 0x42, 0x52, 0x12, 0x4a, 0x0a, 0x0f, 0x72, 0x65, 0x6a, 0x65,
+	0x63, 0x74, 0x5f, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x21, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x52, 0x0e, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x42, 0x3c, 0x5a, 0x3a, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
+	0x6f, 0x6d, 0x2f, 0x67, 0x6f, 0x2d, 0x6b, 0x72, 0x61, 0x74, 0x6f, 0x73, 0x2f, 0x67, 0x61, 0x74,
+	0x65, 0x77, 0x61, 0x79, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79,
+	0x2f, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x2f, 0x62, 0x62, 0x72, 0x2f,
+	0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_gateway_middleware_bbr_v1_bbr_proto_rawDescOnce sync.Once
+	file_gateway_middleware_bbr_v1_bbr_proto_rawDescData = file_gateway_middleware_bbr_v1_bbr_proto_rawDesc
+)
+
+func file_gateway_middleware_bbr_v1_bbr_proto_rawDescGZIP() []byte {
+	file_gateway_middleware_bbr_v1_bbr_proto_rawDescOnce.Do(func() {
+		file_gateway_middleware_bbr_v1_bbr_proto_rawDescData = protoimpl.X.CompressGZIP(file_gateway_middleware_bbr_v1_bbr_proto_rawDescData)
+	})
+	return file_gateway_middleware_bbr_v1_bbr_proto_rawDescData
+}
+
+var file_gateway_middleware_bbr_v1_bbr_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_gateway_middleware_bbr_v1_bbr_proto_goTypes = []interface{}{
+	(*BBR)(nil),               // 0: gateway.middleware.bbr.v1.BBR
+	(*v1.RejectResponse)(nil), // 1: gateway.config.v1.RejectResponse
+}
+var file_gateway_middleware_bbr_v1_bbr_proto_depIdxs = []int32{
+	1, // 0: gateway.middleware.bbr.v1.BBR.reject_response:type_name -> gateway.config.v1.RejectResponse
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_gateway_middleware_bbr_v1_bbr_proto_init() }
+func file_gateway_middleware_bbr_v1_bbr_proto_init() {
+	if File_gateway_middleware_bbr_v1_bbr_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_gateway_middleware_bbr_v1_bbr_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BBR); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_gateway_middleware_bbr_v1_bbr_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_gateway_middleware_bbr_v1_bbr_proto_goTypes,
+		DependencyIndexes: file_gateway_middleware_bbr_v1_bbr_proto_depIdxs,
+		MessageInfos:      file_gateway_middleware_bbr_v1_bbr_proto_msgTypes,
+	}.Build()
+	File_gateway_middleware_bbr_v1_bbr_proto = out.File
+	file_gateway_middleware_bbr_v1_bbr_proto_rawDesc = nil
+	file_gateway_middleware_bbr_v1_bbr_proto_goTypes = nil
+	file_gateway_middleware_bbr_v1_bbr_proto_depIdxs = nil
+}
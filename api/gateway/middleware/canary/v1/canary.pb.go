@@ -0,0 +1,205 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        v4.25.1
+// source: gateway/middleware/canary/v1/canary.proto
+
+package v1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Canary middleware config.
+type Canary struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the header carrying the stable user id, eg: X-User-ID,
+	// requests without it always go to the stable nodes
+	UserHeader string `protobuf:"bytes,1,opt,name=user_header,json=userHeader,proto3" json:"user_header,omitempty"`
+	// the percentage of distinct users assigned to the canary cohort, 0-100
+	Percentage float64 `protobuf:"fixed64,2,opt,name=percentage,proto3" json:"percentage,omitempty"`
+	// the metadata of the canary backend nodes, eg: {"stage": "canary"}
+	CanaryMetadata map[string]string `protobuf:"bytes,3,rep,name=canary_metadata,json=canaryMetadata,proto3" json:"canary_metadata,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// mixed into the hash so that different rollouts assign different cohorts
+	Salt string `protobuf:"bytes,4,opt,name=salt,proto3" json:"salt,omitempty"`
+	// the header set on the upstream request with the assigned cohort: canary or stable
+	CohortHeader string `protobuf:"bytes,5,opt,name=cohort_header,json=cohortHeader,proto3" json:"cohort_header,omitempty"`
+}
+
+func (x *Canary) Reset() {
+	*x = Canary{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_middleware_canary_v1_canary_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Canary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Canary) ProtoMessage() {}
+
+func (x *Canary) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_middleware_canary_v1_canary_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Canary.ProtoReflect.Descriptor instead.
+func (*Canary) Descriptor() ([]byte, []int) {
+	return file_gateway_middleware_canary_v1_canary_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Canary) GetUserHeader() string {
+	if x != nil {
+		return x.UserHeader
+	}
+	return ""
+}
+
+func (x *Canary) GetPercentage() float64 {
+	if x != nil {
+		return x.Percentage
+	}
+	return 0
+}
+
+func (x *Canary) GetCanaryMetadata() map[string]string {
+	if x != nil {
+		return x.CanaryMetadata
+	}
+	return nil
+}
+
+func (x *Canary) GetSalt() string {
+	if x != nil {
+		return x.Salt
+	}
+	return ""
+}
+
+func (x *Canary) GetCohortHeader() string {
+	if x != nil {
+		return x.CohortHeader
+	}
+	return ""
+}
+
+var File_gateway_middleware_canary_v1_canary_proto protoreflect.FileDescriptor
+
+var file_gateway_middleware_canary_v1_canary_proto_rawDesc = []byte{
+	0x0a, 0x29, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2f, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65,
+	0x77, 0x61, 0x72, 0x65, 0x2f, 0x63, 0x61, 0x6e, 0x61, 0x72, 0x79, 0x2f, 0x76, 0x31, 0x2f, 0x63,
+	0x61, 0x6e, 0x61, 0x72, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x1c, 0x67, 0x61, 0x74,
+	0x65, 0x77, 0x61, 0x79, 0x2e, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x2e,
+	0x63, 0x61, 0x6e, 0x61, 0x72, 0x79, 0x2e, 0x76, 0x31, 0x22, 0xa8, 0x02, 0x0a, 0x06, 0x43, 0x61,
+	0x6e, 0x61, 0x72, 0x79, 0x12, 0x1f, 0x0a, 0x0b, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x68, 0x65, 0x61,
+	0x64, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x75, 0x73, 0x65, 0x72, 0x48,
+	0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x1e, 0x0a, 0x0a, 0x70, 0x65, 0x72, 0x63, 0x65, 0x6e, 0x74,
+	0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x70, 0x65, 0x72, 0x63, 0x65,
+	0x6e, 0x74, 0x61, 0x67, 0x65, 0x12, 0x61, 0x0a, 0x0f, 0x63, 0x61, 0x6e, 0x61, 0x72, 0x79, 0x5f,
+	0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x38,
+	0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77,
+	0x61, 0x72, 0x65, 0x2e, 0x63, 0x61, 0x6e, 0x61, 0x72, 0x79, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61,
+	0x6e, 0x61, 0x72, 0x79, 0x2e, 0x43, 0x61, 0x6e, 0x61, 0x72, 0x79, 0x4d, 0x65, 0x74, 0x61, 0x64,
+	0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0e, 0x63, 0x61, 0x6e, 0x61, 0x72, 0x79,
+	0x4d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x61, 0x6c, 0x74,
+	0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x61, 0x6c, 0x74, 0x12, 0x23, 0x0a, 0x0d,
+	0x63, 0x6f, 0x68, 0x6f, 0x72, 0x74, 0x5f, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x6f, 0x68, 0x6f, 0x72, 0x74, 0x48, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x1a, 0x41, 0x0a, 0x13, 0x43, 0x61, 0x6e, 0x61, 0x72, 0x79, 0x4d, 0x65, 0x74, 0x61, 0x64,
+	0x61, 0x74, 0x61, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65,
+	0x3a, 0x02, 0x38, 0x01, 0x42, 0x3f, 0x5a, 0x3d, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
+	0x6f, 0x6d, 0x2f, 0x67, 0x6f, 0x2d, 0x6b, 0x72, 0x61, 0x74, 0x6f, 0x73, 0x2f, 0x67, 0x61, 0x74,
+	0x65, 0x77, 0x61, 0x79, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79,
+	0x2f, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x2f, 0x63, 0x61, 0x6e, 0x61,
+	0x72, 0x79, 0x2f, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_gateway_middleware_canary_v1_canary_proto_rawDescOnce sync.Once
+	file_gateway_middleware_canary_v1_canary_proto_rawDescData = file_gateway_middleware_canary_v1_canary_proto_rawDesc
+)
+
+func file_gateway_middleware_canary_v1_canary_proto_rawDescGZIP() []byte {
+	file_gateway_middleware_canary_v1_canary_proto_rawDescOnce.Do(func() {
+		file_gateway_middleware_canary_v1_canary_proto_rawDescData = protoimpl.X.CompressGZIP(file_gateway_middleware_canary_v1_canary_proto_rawDescData)
+	})
+	return file_gateway_middleware_canary_v1_canary_proto_rawDescData
+}
+
+var file_gateway_middleware_canary_v1_canary_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_gateway_middleware_canary_v1_canary_proto_goTypes = []interface{}{
+	(*Canary)(nil), // 0: gateway.middleware.canary.v1.Canary
+	nil,            // 1: gateway.middleware.canary.v1.Canary.CanaryMetadataEntry
+}
+var file_gateway_middleware_canary_v1_canary_proto_depIdxs = []int32{
+	1, // 0: gateway.middleware.canary.v1.Canary.canary_metadata:type_name -> gateway.middleware.canary.v1.Canary.CanaryMetadataEntry
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_gateway_middleware_canary_v1_canary_proto_init() }
+func file_gateway_middleware_canary_v1_canary_proto_init() {
+	if File_gateway_middleware_canary_v1_canary_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_gateway_middleware_canary_v1_canary_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Canary); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_gateway_middleware_canary_v1_canary_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_gateway_middleware_canary_v1_canary_proto_goTypes,
+		DependencyIndexes: file_gateway_middleware_canary_v1_canary_proto_depIdxs,
+		MessageInfos:      file_gateway_middleware_canary_v1_canary_proto_msgTypes,
+	}.Build()
+	File_gateway_middleware_canary_v1_canary_proto = out.File
+	file_gateway_middleware_canary_v1_canary_proto_rawDesc = nil
+	file_gateway_middleware_canary_v1_canary_proto_goTypes = nil
+	file_gateway_middleware_canary_v1_canary_proto_depIdxs = nil
+}
@@ -39,6 +39,10 @@ type CircuitBreaker struct {
 	//	*CircuitBreaker_BackupService
 	Action          isCircuitBreaker_Action `protobuf_oneof:"action"`
 	AssertCondtions []*v1.Condition         `protobuf:"bytes,5,rep,name=assert_condtions,json=assertCondtions,proto3" json:"assert_condtions,omitempty"`
+	// response for rejected requests when no action is set
+	RejectResponse *v1.RejectResponse `protobuf:"bytes,6,opt,name=reject_response,json=rejectResponse,proto3" json:"reject_response,omitempty"`
+	// share the open state with other gateway instances
+	SharedState *SharedState `protobuf:"bytes,7,opt,name=shared_state,json=sharedState,proto3" json:"shared_state,omitempty"`
 }
 
 func (x *CircuitBreaker) Reset() {
@@ -122,6 +126,20 @@ func (x *CircuitBreaker) GetAssertCondtions() []*v1.Condition {
 	return nil
 }
 
+func (x *CircuitBreaker) GetRejectResponse() *v1.RejectResponse {
+	if x != nil {
+		return x.RejectResponse
+	}
+	return nil
+}
+
+func (x *CircuitBreaker) GetSharedState() *SharedState {
+	if x != nil {
+		return x.SharedState
+	}
+	return nil
+}
+
 type isCircuitBreaker_Trigger interface {
 	isCircuitBreaker_Trigger()
 }
@@ -154,6 +172,81 @@ func (*CircuitBreaker_ResponseData) isCircuitBreaker_Action() {}
 
 func (*CircuitBreaker_BackupService) isCircuitBreaker_Action() {}
 
+type SharedState struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// redis url, e.g. redis://:password@127.0.0.1:6379/0
+	Redis string `protobuf:"bytes,1,opt,name=redis,proto3" json:"redis,omitempty"`
+	// redis key of the shared state, defaults to a hash of the breaker config
+	Key string `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	// how long the shared open state lasts, defaults to 10s
+	OpenDuration *durationpb.Duration `protobuf:"bytes,3,opt,name=open_duration,json=openDuration,proto3" json:"open_duration,omitempty"`
+	// how often the shared state is polled, defaults to 1s
+	SyncInterval *durationpb.Duration `protobuf:"bytes,4,opt,name=sync_interval,json=syncInterval,proto3" json:"sync_interval,omitempty"`
+}
+
+func (x *SharedState) Reset() {
+	*x = SharedState{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SharedState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SharedState) ProtoMessage() {}
+
+func (x *SharedState) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SharedState.ProtoReflect.Descriptor instead.
+func (*SharedState) Descriptor() ([]byte, []int) {
+	return file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SharedState) GetRedis() string {
+	if x != nil {
+		return x.Redis
+	}
+	return ""
+}
+
+func (x *SharedState) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *SharedState) GetOpenDuration() *durationpb.Duration {
+	if x != nil {
+		return x.OpenDuration
+	}
+	return nil
+}
+
+func (x *SharedState) GetSyncInterval() *durationpb.Duration {
+	if x != nil {
+		return x.SyncInterval
+	}
+	return nil
+}
+
 type Header struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -166,7 +259,7 @@ type Header struct {
 func (x *Header) Reset() {
 	*x = Header{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_msgTypes[1]
+		mi := &file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_msgTypes[2]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -179,7 +272,7 @@ func (x *Header) String() string {
 func (*Header) ProtoMessage() {}
 
 func (x *Header) ProtoReflect() protoreflect.Message {
-	mi := &file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_msgTypes[1]
+	mi := &file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_msgTypes[2]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -192,7 +285,7 @@ func (x *Header) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Header.ProtoReflect.Descriptor instead.
 func (*Header) Descriptor() ([]byte, []int) {
-	return file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_rawDescGZIP(), []int{1}
+	return file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_rawDescGZIP(), []int{2}
 }
 
 func (x *Header) GetKey() string {
@@ -222,7 +315,7 @@ type ResponseData struct {
 func (x *ResponseData) Reset() {
 	*x = ResponseData{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_msgTypes[2]
+		mi := &file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -235,7 +328,7 @@ func (x *ResponseData) String() string {
 func (*ResponseData) ProtoMessage() {}
 
 func (x *ResponseData) ProtoReflect() protoreflect.Message {
-	mi := &file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_msgTypes[2]
+	mi := &file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -248,7 +341,7 @@ func (x *ResponseData) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use ResponseData.ProtoReflect.Descriptor instead.
 func (*ResponseData) Descriptor() ([]byte, []int) {
-	return file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_rawDescGZIP(), []int{2}
+	return file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *ResponseData) GetStatusCode() int32 {
@@ -283,7 +376,7 @@ type BackupService struct {
 func (x *BackupService) Reset() {
 	*x = BackupService{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_msgTypes[3]
+		mi := &file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_msgTypes[4]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -296,7 +389,7 @@ func (x *BackupService) String() string {
 func (*BackupService) ProtoMessage() {}
 
 func (x *BackupService) ProtoReflect() protoreflect.Message {
-	mi := &file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_msgTypes[3]
+	mi := &file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_msgTypes[4]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -309,7 +402,7 @@ func (x *BackupService) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BackupService.ProtoReflect.Descriptor instead.
 func (*BackupService) Descriptor() ([]byte, []int) {
-	return file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_rawDescGZIP(), []int{3}
+	return file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_rawDescGZIP(), []int{4}
 }
 
 func (x *BackupService) GetEndpoint() *v1.Endpoint {
@@ -333,7 +426,7 @@ type SuccessRatio struct {
 func (x *SuccessRatio) Reset() {
 	*x = SuccessRatio{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_msgTypes[4]
+		mi := &file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -346,7 +439,7 @@ func (x *SuccessRatio) String() string {
 func (*SuccessRatio) ProtoMessage() {}
 
 func (x *SuccessRatio) ProtoReflect() protoreflect.Message {
-	mi := &file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_msgTypes[4]
+	mi := &file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -359,7 +452,7 @@ func (x *SuccessRatio) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SuccessRatio.ProtoReflect.Descriptor instead.
 func (*SuccessRatio) Descriptor() ([]byte, []int) {
-	return file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_rawDescGZIP(), []int{4}
+	return file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *SuccessRatio) GetSuccess() float64 {
@@ -403,7 +496,7 @@ var file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_rawDesc = []b
 	0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72, 0x6f, 0x74,
 	0x6f, 0x1a, 0x1f, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2f, 0x63, 0x6f, 0x6e, 0x66, 0x69,
 	0x67, 0x2f, 0x76, 0x31, 0x2f, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x22, 0x9a, 0x03, 0x0a, 0x0e, 0x43, 0x69, 0x72, 0x63, 0x75, 0x69, 0x74, 0x42, 0x72,
+	0x74, 0x6f, 0x22, 0xbc, 0x04, 0x0a, 0x0e, 0x43, 0x69, 0x72, 0x63, 0x75, 0x69, 0x74, 0x42, 0x72,
 	0x65, 0x61, 0x6b, 0x65, 0x72, 0x12, 0x59, 0x0a, 0x0d, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73,
 	0x5f, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x32, 0x2e, 0x67,
 	0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72,
@@ -427,39 +520,61 @@ var file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_rawDesc = []b
 	0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1c, 0x2e, 0x67, 0x61,
 	0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e,
 	0x43, 0x6f, 0x6e, 0x64, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0f, 0x61, 0x73, 0x73, 0x65, 0x72,
-	0x74, 0x43, 0x6f, 0x6e, 0x64, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x42, 0x09, 0x0a, 0x07, 0x74, 0x72,
-	0x69, 0x67, 0x67, 0x65, 0x72, 0x42, 0x08, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f, 0x6e, 0x22,
-	0x30, 0x0a, 0x06, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76,
-	0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75,
-	0x65, 0x22, 0x89, 0x01, 0x0a, 0x0c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x44, 0x61,
-	0x74, 0x61, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x63, 0x6f, 0x64,
-	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x43,
-	0x6f, 0x64, 0x65, 0x12, 0x44, 0x0a, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x02, 0x20,
-	0x03, 0x28, 0x0b, 0x32, 0x2c, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x6d, 0x69,
-	0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x2e, 0x63, 0x69, 0x72, 0x63, 0x75, 0x69, 0x74,
-	0x62, 0x72, 0x65, 0x61, 0x6b, 0x65, 0x72, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x65, 0x61, 0x64, 0x65,
-	0x72, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x62, 0x6f, 0x64,
-	0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x22, 0x48, 0x0a,
-	0x0d, 0x42, 0x61, 0x63, 0x6b, 0x75, 0x70, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x37,
-	0x0a, 0x08, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b,
-	0x32, 0x1b, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69,
-	0x67, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x08, 0x65,
-	0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x22, 0x8d, 0x01, 0x0a, 0x0c, 0x53, 0x75, 0x63, 0x63,
-	0x65, 0x73, 0x73, 0x52, 0x61, 0x74, 0x69, 0x6f, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63,
-	0x65, 0x73, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65,
-	0x73, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x05, 0x52, 0x07, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06,
-	0x62, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x62, 0x75,
-	0x63, 0x6b, 0x65, 0x74, 0x12, 0x31, 0x0a, 0x06, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x18, 0x04,
-	0x20, 0x01, 0x28, 0x0b, 0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
-	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52,
-	0x06, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x42, 0x47, 0x5a, 0x45, 0x67, 0x69, 0x74, 0x68, 0x75,
-	0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x67, 0x6f, 0x2d, 0x6b, 0x72, 0x61, 0x74, 0x6f, 0x73, 0x2f,
-	0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x61, 0x74, 0x65,
-	0x77, 0x61, 0x79, 0x2f, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x2f, 0x63,
-	0x69, 0x72, 0x63, 0x75, 0x69, 0x74, 0x62, 0x72, 0x65, 0x61, 0x6b, 0x65, 0x72, 0x2f, 0x76, 0x31,
-	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x74, 0x43, 0x6f, 0x6e, 0x64, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x4a, 0x0a, 0x0f, 0x72, 0x65,
+	0x6a, 0x65, 0x63, 0x74, 0x5f, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x52, 0x0e, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x54, 0x0a, 0x0c, 0x73, 0x68, 0x61, 0x72, 0x65, 0x64,
+	0x5f, 0x73, 0x74, 0x61, 0x74, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x31, 0x2e, 0x67,
+	0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72,
+	0x65, 0x2e, 0x63, 0x69, 0x72, 0x63, 0x75, 0x69, 0x74, 0x62, 0x72, 0x65, 0x61, 0x6b, 0x65, 0x72,
+	0x2e, 0x76, 0x31, 0x2e, 0x53, 0x68, 0x61, 0x72, 0x65, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x52,
+	0x0b, 0x73, 0x68, 0x61, 0x72, 0x65, 0x64, 0x53, 0x74, 0x61, 0x74, 0x65, 0x42, 0x09, 0x0a, 0x07,
+	0x74, 0x72, 0x69, 0x67, 0x67, 0x65, 0x72, 0x42, 0x08, 0x0a, 0x06, 0x61, 0x63, 0x74, 0x69, 0x6f,
+	0x6e, 0x22, 0xb5, 0x01, 0x0a, 0x0b, 0x53, 0x68, 0x61, 0x72, 0x65, 0x64, 0x53, 0x74, 0x61, 0x74,
+	0x65, 0x12, 0x14, 0x0a, 0x05, 0x72, 0x65, 0x64, 0x69, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x05, 0x72, 0x65, 0x64, 0x69, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x3e, 0x0a, 0x0d, 0x6f, 0x70, 0x65,
+	0x6e, 0x5f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x6f, 0x70, 0x65,
+	0x6e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x3e, 0x0a, 0x0d, 0x73, 0x79, 0x6e,
+	0x63, 0x5f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0c, 0x73, 0x79, 0x6e,
+	0x63, 0x49, 0x6e, 0x74, 0x65, 0x72, 0x76, 0x61, 0x6c, 0x22, 0x30, 0x0a, 0x06, 0x48, 0x65, 0x61,
+	0x64, 0x65, 0x72, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x22, 0x89, 0x01, 0x0a, 0x0c,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x44, 0x61, 0x74, 0x61, 0x12, 0x1f, 0x0a, 0x0b,
+	0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0a, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x43, 0x6f, 0x64, 0x65, 0x12, 0x44, 0x0a,
+	0x06, 0x68, 0x65, 0x61, 0x64, 0x65, 0x72, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x2c, 0x2e,
+	0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61,
+	0x72, 0x65, 0x2e, 0x63, 0x69, 0x72, 0x63, 0x75, 0x69, 0x74, 0x62, 0x72, 0x65, 0x61, 0x6b, 0x65,
+	0x72, 0x2e, 0x76, 0x31, 0x2e, 0x48, 0x65, 0x61, 0x64, 0x65, 0x72, 0x52, 0x06, 0x68, 0x65, 0x61,
+	0x64, 0x65, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x04, 0x62, 0x6f, 0x64, 0x79, 0x22, 0x48, 0x0a, 0x0d, 0x42, 0x61, 0x63, 0x6b, 0x75,
+	0x70, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x37, 0x0a, 0x08, 0x65, 0x6e, 0x64, 0x70,
+	0x6f, 0x69, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x67, 0x61, 0x74,
+	0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x45,
+	0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e, 0x74, 0x52, 0x08, 0x65, 0x6e, 0x64, 0x70, 0x6f, 0x69, 0x6e,
+	0x74, 0x22, 0x8d, 0x01, 0x0a, 0x0c, 0x53, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x52, 0x61, 0x74,
+	0x69, 0x6f, 0x12, 0x18, 0x0a, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x07, 0x73, 0x75, 0x63, 0x63, 0x65, 0x73, 0x73, 0x12, 0x18, 0x0a, 0x07,
+	0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07, 0x72,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x31,
+	0x0a, 0x06, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x19,
+	0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66,
+	0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x06, 0x77, 0x69, 0x6e, 0x64, 0x6f,
+	0x77, 0x42, 0x47, 0x5a, 0x45, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f,
+	0x67, 0x6f, 0x2d, 0x6b, 0x72, 0x61, 0x74, 0x6f, 0x73, 0x2f, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61,
+	0x79, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2f, 0x6d, 0x69,
+	0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x2f, 0x63, 0x69, 0x72, 0x63, 0x75, 0x69, 0x74,
+	0x62, 0x72, 0x65, 0x61, 0x6b, 0x65, 0x72, 0x2f, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x33,
 }
 
 var (
@@ -474,30 +589,36 @@ func file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_rawDescGZIP(
 	return file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_rawDescData
 }
 
-var file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_msgTypes = make([]protoimpl.MessageInfo, 6)
 var file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_goTypes = []interface{}{
 	(*CircuitBreaker)(nil),      // 0: gateway.middleware.circuitbreaker.v1.CircuitBreaker
-	(*Header)(nil),              // 1: gateway.middleware.circuitbreaker.v1.Header
-	(*ResponseData)(nil),        // 2: gateway.middleware.circuitbreaker.v1.ResponseData
-	(*BackupService)(nil),       // 3: gateway.middleware.circuitbreaker.v1.BackupService
-	(*SuccessRatio)(nil),        // 4: gateway.middleware.circuitbreaker.v1.SuccessRatio
-	(*v1.Condition)(nil),        // 5: gateway.config.v1.Condition
-	(*v1.Endpoint)(nil),         // 6: gateway.config.v1.Endpoint
-	(*durationpb.Duration)(nil), // 7: google.protobuf.Duration
+	(*SharedState)(nil),         // 1: gateway.middleware.circuitbreaker.v1.SharedState
+	(*Header)(nil),              // 2: gateway.middleware.circuitbreaker.v1.Header
+	(*ResponseData)(nil),        // 3: gateway.middleware.circuitbreaker.v1.ResponseData
+	(*BackupService)(nil),       // 4: gateway.middleware.circuitbreaker.v1.BackupService
+	(*SuccessRatio)(nil),        // 5: gateway.middleware.circuitbreaker.v1.SuccessRatio
+	(*v1.Condition)(nil),        // 6: gateway.config.v1.Condition
+	(*v1.RejectResponse)(nil),   // 7: gateway.config.v1.RejectResponse
+	(*durationpb.Duration)(nil), // 8: google.protobuf.Duration
+	(*v1.Endpoint)(nil),         // 9: gateway.config.v1.Endpoint
 }
 var file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_depIdxs = []int32{
-	4, // 0: gateway.middleware.circuitbreaker.v1.CircuitBreaker.success_ratio:type_name -> gateway.middleware.circuitbreaker.v1.SuccessRatio
-	2, // 1: gateway.middleware.circuitbreaker.v1.CircuitBreaker.response_data:type_name -> gateway.middleware.circuitbreaker.v1.ResponseData
-	3, // 2: gateway.middleware.circuitbreaker.v1.CircuitBreaker.backup_service:type_name -> gateway.middleware.circuitbreaker.v1.BackupService
-	5, // 3: gateway.middleware.circuitbreaker.v1.CircuitBreaker.assert_condtions:type_name -> gateway.config.v1.Condition
-	1, // 4: gateway.middleware.circuitbreaker.v1.ResponseData.header:type_name -> gateway.middleware.circuitbreaker.v1.Header
-	6, // 5: gateway.middleware.circuitbreaker.v1.BackupService.endpoint:type_name -> gateway.config.v1.Endpoint
-	7, // 6: gateway.middleware.circuitbreaker.v1.SuccessRatio.window:type_name -> google.protobuf.Duration
-	7, // [7:7] is the sub-list for method output_type
-	7, // [7:7] is the sub-list for method input_type
-	7, // [7:7] is the sub-list for extension type_name
-	7, // [7:7] is the sub-list for extension extendee
-	0, // [0:7] is the sub-list for field type_name
+	5,  // 0: gateway.middleware.circuitbreaker.v1.CircuitBreaker.success_ratio:type_name -> gateway.middleware.circuitbreaker.v1.SuccessRatio
+	3,  // 1: gateway.middleware.circuitbreaker.v1.CircuitBreaker.response_data:type_name -> gateway.middleware.circuitbreaker.v1.ResponseData
+	4,  // 2: gateway.middleware.circuitbreaker.v1.CircuitBreaker.backup_service:type_name -> gateway.middleware.circuitbreaker.v1.BackupService
+	6,  // 3: gateway.middleware.circuitbreaker.v1.CircuitBreaker.assert_condtions:type_name -> gateway.config.v1.Condition
+	7,  // 4: gateway.middleware.circuitbreaker.v1.CircuitBreaker.reject_response:type_name -> gateway.config.v1.RejectResponse
+	1,  // 5: gateway.middleware.circuitbreaker.v1.CircuitBreaker.shared_state:type_name -> gateway.middleware.circuitbreaker.v1.SharedState
+	8,  // 6: gateway.middleware.circuitbreaker.v1.SharedState.open_duration:type_name -> google.protobuf.Duration
+	8,  // 7: gateway.middleware.circuitbreaker.v1.SharedState.sync_interval:type_name -> google.protobuf.Duration
+	2,  // 8: gateway.middleware.circuitbreaker.v1.ResponseData.header:type_name -> gateway.middleware.circuitbreaker.v1.Header
+	9,  // 9: gateway.middleware.circuitbreaker.v1.BackupService.endpoint:type_name -> gateway.config.v1.Endpoint
+	8,  // 10: gateway.middleware.circuitbreaker.v1.SuccessRatio.window:type_name -> google.protobuf.Duration
+	11, // [11:11] is the sub-list for method output_type
+	11, // [11:11] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
 }
 
 func init() { file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_init() }
@@ -519,7 +640,7 @@ func file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_init() {
 			}
 		}
 		file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Header); i {
+			switch v := v.(*SharedState); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -531,7 +652,7 @@ func file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_init() {
 			}
 		}
 		file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*ResponseData); i {
+			switch v := v.(*Header); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -543,7 +664,7 @@ func file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_init() {
 			}
 		}
 		file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*BackupService); i {
+			switch v := v.(*ResponseData); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -555,6 +676,18 @@ func file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_init() {
 			}
 		}
 		file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BackupService); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*SuccessRatio); i {
 			case 0:
 				return &v.state
@@ -579,7 +712,7 @@ func file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_gateway_middleware_circuitbreaker_v1_circuitbreaker_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   6,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
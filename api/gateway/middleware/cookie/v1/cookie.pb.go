@@ -0,0 +1,215 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        v4.25.1
+// source: gateway/middleware/cookie/v1/cookie.proto
+
+package v1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Cookie middleware config.
+type Cookie struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// rewrite the Domain attribute of the Set-Cookie headers, the key is the domain set by the upstream,
+	// "*" matches any domain, an empty value removes the attribute
+	DomainRewrite map[string]string `protobuf:"bytes,1,rep,name=domain_rewrite,json=domainRewrite,proto3" json:"domain_rewrite,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// rewrite the Path attribute of the Set-Cookie headers, the key is the path prefix set by the upstream,
+	// the longest matched prefix is replaced with the value
+	PathRewrite map[string]string `protobuf:"bytes,2,rep,name=path_rewrite,json=pathRewrite,proto3" json:"path_rewrite,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// add the Secure attribute
+	Secure bool `protobuf:"varint,3,opt,name=secure,proto3" json:"secure,omitempty"`
+	// add the HttpOnly attribute
+	HttpOnly bool `protobuf:"varint,4,opt,name=http_only,json=httpOnly,proto3" json:"http_only,omitempty"`
+	// set the SameSite attribute, eg: Strict, Lax, None
+	SameSite string `protobuf:"bytes,5,opt,name=same_site,json=sameSite,proto3" json:"same_site,omitempty"`
+}
+
+func (x *Cookie) Reset() {
+	*x = Cookie{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_middleware_cookie_v1_cookie_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Cookie) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Cookie) ProtoMessage() {}
+
+func (x *Cookie) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_middleware_cookie_v1_cookie_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Cookie.ProtoReflect.Descriptor instead.
+func (*Cookie) Descriptor() ([]byte, []int) {
+	return file_gateway_middleware_cookie_v1_cookie_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Cookie) GetDomainRewrite() map[string]string {
+	if x != nil {
+		return x.DomainRewrite
+	}
+	return nil
+}
+
+func (x *Cookie) GetPathRewrite() map[string]string {
+	if x != nil {
+		return x.PathRewrite
+	}
+	return nil
+}
+
+func (x *Cookie) GetSecure() bool {
+	if x != nil {
+		return x.Secure
+	}
+	return false
+}
+
+func (x *Cookie) GetHttpOnly() bool {
+	if x != nil {
+		return x.HttpOnly
+	}
+	return false
+}
+
+func (x *Cookie) GetSameSite() string {
+	if x != nil {
+		return x.SameSite
+	}
+	return ""
+}
+
+var File_gateway_middleware_cookie_v1_cookie_proto protoreflect.FileDescriptor
+
+var file_gateway_middleware_cookie_v1_cookie_proto_rawDesc = []byte{
+	0x0a, 0x29, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2f, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65,
+	0x77, 0x61, 0x72, 0x65, 0x2f, 0x63, 0x6f, 0x6f, 0x6b, 0x69, 0x65, 0x2f, 0x76, 0x31, 0x2f, 0x63,
+	0x6f, 0x6f, 0x6b, 0x69, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x1c, 0x67, 0x61, 0x74,
+	0x65, 0x77, 0x61, 0x79, 0x2e, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x2e,
+	0x63, 0x6f, 0x6f, 0x6b, 0x69, 0x65, 0x2e, 0x76, 0x31, 0x22, 0x96, 0x03, 0x0a, 0x06, 0x43, 0x6f,
+	0x6f, 0x6b, 0x69, 0x65, 0x12, 0x5e, 0x0a, 0x0e, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x5f, 0x72,
+	0x65, 0x77, 0x72, 0x69, 0x74, 0x65, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x37, 0x2e, 0x67,
+	0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72,
+	0x65, 0x2e, 0x63, 0x6f, 0x6f, 0x6b, 0x69, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6f, 0x6b,
+	0x69, 0x65, 0x2e, 0x44, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x52, 0x65, 0x77, 0x72, 0x69, 0x74, 0x65,
+	0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0d, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x52, 0x65, 0x77,
+	0x72, 0x69, 0x74, 0x65, 0x12, 0x58, 0x0a, 0x0c, 0x70, 0x61, 0x74, 0x68, 0x5f, 0x72, 0x65, 0x77,
+	0x72, 0x69, 0x74, 0x65, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x35, 0x2e, 0x67, 0x61, 0x74,
+	0x65, 0x77, 0x61, 0x79, 0x2e, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x2e,
+	0x63, 0x6f, 0x6f, 0x6b, 0x69, 0x65, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x6f, 0x6f, 0x6b, 0x69, 0x65,
+	0x2e, 0x50, 0x61, 0x74, 0x68, 0x52, 0x65, 0x77, 0x72, 0x69, 0x74, 0x65, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x0b, 0x70, 0x61, 0x74, 0x68, 0x52, 0x65, 0x77, 0x72, 0x69, 0x74, 0x65, 0x12, 0x16,
+	0x0a, 0x06, 0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06,
+	0x73, 0x65, 0x63, 0x75, 0x72, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x68, 0x74, 0x74, 0x70, 0x5f, 0x6f,
+	0x6e, 0x6c, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x08, 0x52, 0x08, 0x68, 0x74, 0x74, 0x70, 0x4f,
+	0x6e, 0x6c, 0x79, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x61, 0x6d, 0x65, 0x5f, 0x73, 0x69, 0x74, 0x65,
+	0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x61, 0x6d, 0x65, 0x53, 0x69, 0x74, 0x65,
+	0x1a, 0x40, 0x0a, 0x12, 0x44, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x52, 0x65, 0x77, 0x72, 0x69, 0x74,
+	0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
+	0x38, 0x01, 0x1a, 0x3e, 0x0a, 0x10, 0x50, 0x61, 0x74, 0x68, 0x52, 0x65, 0x77, 0x72, 0x69, 0x74,
+	0x65, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02,
+	0x38, 0x01, 0x42, 0x3f, 0x5a, 0x3d, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d,
+	0x2f, 0x67, 0x6f, 0x2d, 0x6b, 0x72, 0x61, 0x74, 0x6f, 0x73, 0x2f, 0x67, 0x61, 0x74, 0x65, 0x77,
+	0x61, 0x79, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2f, 0x6d,
+	0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x2f, 0x63, 0x6f, 0x6f, 0x6b, 0x69, 0x65,
+	0x2f, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_gateway_middleware_cookie_v1_cookie_proto_rawDescOnce sync.Once
+	file_gateway_middleware_cookie_v1_cookie_proto_rawDescData = file_gateway_middleware_cookie_v1_cookie_proto_rawDesc
+)
+
+func file_gateway_middleware_cookie_v1_cookie_proto_rawDescGZIP() []byte {
+	file_gateway_middleware_cookie_v1_cookie_proto_rawDescOnce.Do(func() {
+		file_gateway_middleware_cookie_v1_cookie_proto_rawDescData = protoimpl.X.CompressGZIP(file_gateway_middleware_cookie_v1_cookie_proto_rawDescData)
+	})
+	return file_gateway_middleware_cookie_v1_cookie_proto_rawDescData
+}
+
+var file_gateway_middleware_cookie_v1_cookie_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_gateway_middleware_cookie_v1_cookie_proto_goTypes = []interface{}{
+	(*Cookie)(nil), // 0: gateway.middleware.cookie.v1.Cookie
+	nil,            // 1: gateway.middleware.cookie.v1.Cookie.DomainRewriteEntry
+	nil,            // 2: gateway.middleware.cookie.v1.Cookie.PathRewriteEntry
+}
+var file_gateway_middleware_cookie_v1_cookie_proto_depIdxs = []int32{
+	1, // 0: gateway.middleware.cookie.v1.Cookie.domain_rewrite:type_name -> gateway.middleware.cookie.v1.Cookie.DomainRewriteEntry
+	2, // 1: gateway.middleware.cookie.v1.Cookie.path_rewrite:type_name -> gateway.middleware.cookie.v1.Cookie.PathRewriteEntry
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_gateway_middleware_cookie_v1_cookie_proto_init() }
+func file_gateway_middleware_cookie_v1_cookie_proto_init() {
+	if File_gateway_middleware_cookie_v1_cookie_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_gateway_middleware_cookie_v1_cookie_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Cookie); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_gateway_middleware_cookie_v1_cookie_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   3,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_gateway_middleware_cookie_v1_cookie_proto_goTypes,
+		DependencyIndexes: file_gateway_middleware_cookie_v1_cookie_proto_depIdxs,
+		MessageInfos:      file_gateway_middleware_cookie_v1_cookie_proto_msgTypes,
+	}.Build()
+	File_gateway_middleware_cookie_v1_cookie_proto = out.File
+	file_gateway_middleware_cookie_v1_cookie_proto_rawDesc = nil
+	file_gateway_middleware_cookie_v1_cookie_proto_goTypes = nil
+	file_gateway_middleware_cookie_v1_cookie_proto_depIdxs = nil
+}
@@ -0,0 +1,294 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.31.0
+// 	protoc        v4.25.1
+// source: gateway/middleware/ratelimit/v1/ratelimit.proto
+
+package v1
+
+import (
+	v1 "github.com/cnsync/gateway/api/gateway/config/v1"
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type RateLimit_Key int32
+
+const (
+	// the client IP in X-Forwarded-For
+	RateLimit_CLIENT_IP RateLimit_Key = 0
+	// the value of the header, the requests without the header share a bucket
+	RateLimit_HEADER RateLimit_Key = 1
+	// the path of the matched endpoint, all requests of the endpoint share a bucket
+	RateLimit_ENDPOINT RateLimit_Key = 2
+)
+
+// Enum value maps for RateLimit_Key.
+var (
+	RateLimit_Key_name = map[int32]string{
+		0: "CLIENT_IP",
+		1: "HEADER",
+		2: "ENDPOINT",
+	}
+	RateLimit_Key_value = map[string]int32{
+		"CLIENT_IP": 0,
+		"HEADER":    1,
+		"ENDPOINT":  2,
+	}
+)
+
+func (x RateLimit_Key) Enum() *RateLimit_Key {
+	p := new(RateLimit_Key)
+	*p = x
+	return p
+}
+
+func (x RateLimit_Key) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RateLimit_Key) Descriptor() protoreflect.EnumDescriptor {
+	return file_gateway_middleware_ratelimit_v1_ratelimit_proto_enumTypes[0].Descriptor()
+}
+
+func (RateLimit_Key) Type() protoreflect.EnumType {
+	return &file_gateway_middleware_ratelimit_v1_ratelimit_proto_enumTypes[0]
+}
+
+func (x RateLimit_Key) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RateLimit_Key.Descriptor instead.
+func (RateLimit_Key) EnumDescriptor() ([]byte, []int) {
+	return file_gateway_middleware_ratelimit_v1_ratelimit_proto_rawDescGZIP(), []int{0, 0}
+}
+
+// RateLimit middleware config, it limits the requests with a local token bucket per key.
+// The buckets are kept in memory and aren't shared between gateway instances.
+type RateLimit struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the tokens added to a bucket per second
+	RequestsPerSecond float64 `protobuf:"fixed64,1,opt,name=requests_per_second,json=requestsPerSecond,proto3" json:"requests_per_second,omitempty"`
+	// the size of a bucket, default is requests_per_second rounded up
+	Burst uint32        `protobuf:"varint,2,opt,name=burst,proto3" json:"burst,omitempty"`
+	Key   RateLimit_Key `protobuf:"varint,3,opt,name=key,proto3,enum=gateway.middleware.ratelimit.v1.RateLimit_Key" json:"key,omitempty"`
+	// required by the HEADER key
+	Header string `protobuf:"bytes,4,opt,name=header,proto3" json:"header,omitempty"`
+	// the number of trusted proxies in front of the gateway, the client IP is the address right before them
+	// in X-Forwarded-For, default is 0 which is the peer address of the gateway
+	TrustedProxies uint32 `protobuf:"varint,5,opt,name=trusted_proxies,json=trustedProxies,proto3" json:"trusted_proxies,omitempty"`
+	// the buckets unused for this long are removed, default is 5m
+	IdleTimeout *durationpb.Duration `protobuf:"bytes,6,opt,name=idle_timeout,json=idleTimeout,proto3" json:"idle_timeout,omitempty"`
+	// response for rejected requests, default is an empty 429
+	RejectResponse *v1.RejectResponse `protobuf:"bytes,7,opt,name=reject_response,json=rejectResponse,proto3" json:"reject_response,omitempty"`
+}
+
+func (x *RateLimit) Reset() {
+	*x = RateLimit{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_gateway_middleware_ratelimit_v1_ratelimit_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *RateLimit) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RateLimit) ProtoMessage() {}
+
+func (x *RateLimit) ProtoReflect() protoreflect.Message {
+	mi := &file_gateway_middleware_ratelimit_v1_ratelimit_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RateLimit.ProtoReflect.Descriptor instead.
+func (*RateLimit) Descriptor() ([]byte, []int) {
+	return file_gateway_middleware_ratelimit_v1_ratelimit_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *RateLimit) GetRequestsPerSecond() float64 {
+	if x != nil {
+		return x.RequestsPerSecond
+	}
+	return 0
+}
+
+func (x *RateLimit) GetBurst() uint32 {
+	if x != nil {
+		return x.Burst
+	}
+	return 0
+}
+
+func (x *RateLimit) GetKey() RateLimit_Key {
+	if x != nil {
+		return x.Key
+	}
+	return RateLimit_CLIENT_IP
+}
+
+func (x *RateLimit) GetHeader() string {
+	if x != nil {
+		return x.Header
+	}
+	return ""
+}
+
+func (x *RateLimit) GetTrustedProxies() uint32 {
+	if x != nil {
+		return x.TrustedProxies
+	}
+	return 0
+}
+
+func (x *RateLimit) GetIdleTimeout() *durationpb.Duration {
+	if x != nil {
+		return x.IdleTimeout
+	}
+	return nil
+}
+
+func (x *RateLimit) GetRejectResponse() *v1.RejectResponse {
+	if x != nil {
+		return x.RejectResponse
+	}
+	return nil
+}
+
+var File_gateway_middleware_ratelimit_v1_ratelimit_proto protoreflect.FileDescriptor
+
+var file_gateway_middleware_ratelimit_v1_ratelimit_proto_rawDesc = []byte{
+	0x0a, 0x2f, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2f, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65,
+	0x77, 0x61, 0x72, 0x65, 0x2f, 0x72, 0x61, 0x74, 0x65, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x2f, 0x76,
+	0x31, 0x2f, 0x72, 0x61, 0x74, 0x65, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x12, 0x1f, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x6d, 0x69, 0x64, 0x64, 0x6c,
+	0x65, 0x77, 0x61, 0x72, 0x65, 0x2e, 0x72, 0x61, 0x74, 0x65, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x2e,
+	0x76, 0x31, 0x1a, 0x1f, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2f, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x2f, 0x76, 0x31, 0x2f, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x1a, 0x1e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x62, 0x75, 0x66, 0x2f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x22, 0x8e, 0x03, 0x0a, 0x09, 0x52, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69,
+	0x74, 0x12, 0x2e, 0x0a, 0x13, 0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x5f, 0x70, 0x65,
+	0x72, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x01, 0x52, 0x11,
+	0x72, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x73, 0x50, 0x65, 0x72, 0x53, 0x65, 0x63, 0x6f, 0x6e,
+	0x64, 0x12, 0x14, 0x0a, 0x05, 0x62, 0x75, 0x72, 0x73, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0d,
+	0x52, 0x05, 0x62, 0x75, 0x72, 0x73, 0x74, 0x12, 0x40, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0e, 0x32, 0x2e, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x6d,
+	0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x2e, 0x72, 0x61, 0x74, 0x65, 0x6c, 0x69,
+	0x6d, 0x69, 0x74, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x61, 0x74, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74,
+	0x2e, 0x4b, 0x65, 0x79, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x68, 0x65, 0x61,
+	0x64, 0x65, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x68, 0x65, 0x61, 0x64, 0x65,
+	0x72, 0x12, 0x27, 0x0a, 0x0f, 0x74, 0x72, 0x75, 0x73, 0x74, 0x65, 0x64, 0x5f, 0x70, 0x72, 0x6f,
+	0x78, 0x69, 0x65, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0e, 0x74, 0x72, 0x75, 0x73,
+	0x74, 0x65, 0x64, 0x50, 0x72, 0x6f, 0x78, 0x69, 0x65, 0x73, 0x12, 0x3c, 0x0a, 0x0c, 0x69, 0x64,
+	0x6c, 0x65, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x19, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0b, 0x69, 0x64, 0x6c,
+	0x65, 0x54, 0x69, 0x6d, 0x65, 0x6f, 0x75, 0x74, 0x12, 0x4a, 0x0a, 0x0f, 0x72, 0x65, 0x6a, 0x65,
+	0x63, 0x74, 0x5f, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x21, 0x2e, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79, 0x2e, 0x63, 0x6f, 0x6e, 0x66,
+	0x69, 0x67, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x52, 0x0e, 0x72, 0x65, 0x6a, 0x65, 0x63, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x2e, 0x0a, 0x03, 0x4b, 0x65, 0x79, 0x12, 0x0d, 0x0a, 0x09, 0x43,
+	0x4c, 0x49, 0x45, 0x4e, 0x54, 0x5f, 0x49, 0x50, 0x10, 0x00, 0x12, 0x0a, 0x0a, 0x06, 0x48, 0x45,
+	0x41, 0x44, 0x45, 0x52, 0x10, 0x01, 0x12, 0x0c, 0x0a, 0x08, 0x45, 0x4e, 0x44, 0x50, 0x4f, 0x49,
+	0x4e, 0x54, 0x10, 0x02, 0x42, 0x42, 0x5a, 0x40, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63,
+	0x6f, 0x6d, 0x2f, 0x67, 0x6f, 0x2d, 0x6b, 0x72, 0x61, 0x74, 0x6f, 0x73, 0x2f, 0x67, 0x61, 0x74,
+	0x65, 0x77, 0x61, 0x79, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x61, 0x74, 0x65, 0x77, 0x61, 0x79,
+	0x2f, 0x6d, 0x69, 0x64, 0x64, 0x6c, 0x65, 0x77, 0x61, 0x72, 0x65, 0x2f, 0x72, 0x61, 0x74, 0x65,
+	0x6c, 0x69, 0x6d, 0x69, 0x74, 0x2f, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_gateway_middleware_ratelimit_v1_ratelimit_proto_rawDescOnce sync.Once
+	file_gateway_middleware_ratelimit_v1_ratelimit_proto_rawDescData = file_gateway_middleware_ratelimit_v1_ratelimit_proto_rawDesc
+)
+
+func file_gateway_middleware_ratelimit_v1_ratelimit_proto_rawDescGZIP() []byte {
+	file_gateway_middleware_ratelimit_v1_ratelimit_proto_rawDescOnce.Do(func() {
+		file_gateway_middleware_ratelimit_v1_ratelimit_proto_rawDescData = protoimpl.X.CompressGZIP(file_gateway_middleware_ratelimit_v1_ratelimit_proto_rawDescData)
+	})
+	return file_gateway_middleware_ratelimit_v1_ratelimit_proto_rawDescData
+}
+
+var file_gateway_middleware_ratelimit_v1_ratelimit_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_gateway_middleware_ratelimit_v1_ratelimit_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_gateway_middleware_ratelimit_v1_ratelimit_proto_goTypes = []interface{}{
+	(RateLimit_Key)(0),          // 0: gateway.middleware.ratelimit.v1.RateLimit.Key
+	(*RateLimit)(nil),           // 1: gateway.middleware.ratelimit.v1.RateLimit
+	(*durationpb.Duration)(nil), // 2: google.protobuf.Duration
+	(*v1.RejectResponse)(nil),   // 3: gateway.config.v1.RejectResponse
+}
+var file_gateway_middleware_ratelimit_v1_ratelimit_proto_depIdxs = []int32{
+	0, // 0: gateway.middleware.ratelimit.v1.RateLimit.key:type_name -> gateway.middleware.ratelimit.v1.RateLimit.Key
+	2, // 1: gateway.middleware.ratelimit.v1.RateLimit.idle_timeout:type_name -> google.protobuf.Duration
+	3, // 2: gateway.middleware.ratelimit.v1.RateLimit.reject_response:type_name -> gateway.config.v1.RejectResponse
+	3, // [3:3] is the sub-list for method output_type
+	3, // [3:3] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_gateway_middleware_ratelimit_v1_ratelimit_proto_init() }
+func file_gateway_middleware_ratelimit_v1_ratelimit_proto_init() {
+	if File_gateway_middleware_ratelimit_v1_ratelimit_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_gateway_middleware_ratelimit_v1_ratelimit_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RateLimit); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_gateway_middleware_ratelimit_v1_ratelimit_proto_rawDesc,
+			NumEnums:      1,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_gateway_middleware_ratelimit_v1_ratelimit_proto_goTypes,
+		DependencyIndexes: file_gateway_middleware_ratelimit_v1_ratelimit_proto_depIdxs,
+		EnumInfos:         file_gateway_middleware_ratelimit_v1_ratelimit_proto_enumTypes,
+		MessageInfos:      file_gateway_middleware_ratelimit_v1_ratelimit_proto_msgTypes,
+	}.Build()
+	File_gateway_middleware_ratelimit_v1_ratelimit_proto = out.File
+	file_gateway_middleware_ratelimit_v1_ratelimit_proto_rawDesc = nil
+	file_gateway_middleware_ratelimit_v1_ratelimit_proto_goTypes = nil
+	file_gateway_middleware_ratelimit_v1_ratelimit_proto_depIdxs = nil
+}
@@ -0,0 +1,234 @@
+package audit
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cnsync/gateway/trust"
+	"github.com/cnsync/kratos/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// 审计事件的类型
+const (
+	// TypeAuth 表示鉴权决策事件
+	TypeAuth = "auth"
+	// TypeAdmin 表示管理或调试操作事件
+	TypeAdmin = "admin"
+	// TypeConfig 表示配置变更事件
+	TypeConfig = "config"
+)
+
+// 审计事件的结果
+const (
+	// ResultAllow 表示鉴权通过
+	ResultAllow = "allow"
+	// ResultDeny 表示鉴权拒绝
+	ResultDeny = "deny"
+	// ResultSuccess 表示操作成功
+	ResultSuccess = "success"
+	// ResultFailure 表示操作失败
+	ResultFailure = "failure"
+)
+
+// _defaultBufferSize 是审计事件缓冲队列的默认长度
+const _defaultBufferSize = 1024
+
+// _metricDroppedEvents 是一个计数器，用于记录因队列已满而被丢弃的审计事件数量
+var _metricDroppedEvents = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "audit_dropped_events_total",
+	Help:      "The total number of dropped audit events",
+})
+
+func init() {
+	prometheus.MustRegister(_metricDroppedEvents)
+}
+
+// Event 结构体定义了一条结构化的审计事件
+type Event struct {
+	// Time 是事件发生的时间
+	Time time.Time `json:"time"`
+	// Type 是事件的类型，例如 auth、admin、config
+	Type string `json:"type"`
+	// Action 是具体的操作
+	Action string `json:"action"`
+	// Actor 是操作的发起方，例如客户端地址
+	Actor string `json:"actor,omitempty"`
+	// ForwardedFor 是受信任的代理转发的请求中的 X-Forwarded-For
+	ForwardedFor string `json:"forwarded_for,omitempty"`
+	// Resource 是操作的对象，例如请求路径或配置名称
+	Resource string `json:"resource,omitempty"`
+	// Result 是操作的结果
+	Result string `json:"result"`
+	// Reason 是操作结果的原因
+	Reason string `json:"reason,omitempty"`
+	// Metadata 是事件的附加信息
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Sink 接口定义了审计事件的输出目标
+type Sink interface {
+	// Write 方法写入一条审计事件
+	Write(*Event) error
+	// Close 方法关闭输出目标
+	Close() error
+}
+
+// Factory 是一个工厂函数，用于根据 DSN 创建审计事件的输出目标
+type Factory func(dsn *url.URL) (Sink, error)
+
+// globalFactories 保存了所有已注册的输出目标工厂
+var globalFactories = map[string]Factory{}
+
+// Register 注册一个输出目标工厂
+func Register(name string, factory Factory) {
+	globalFactories[name] = factory
+}
+
+// Create 根据给定的 DSN 创建一个输出目标实例，例如 file:///var/log/audit.log、http://127.0.0.1:8000/audit
+func Create(sinkDSN string) (Sink, error) {
+	if sinkDSN == "" {
+		return nil, fmt.Errorf("sinkDSN is empty")
+	}
+	dsn, err := url.Parse(sinkDSN)
+	if err != nil {
+		return nil, fmt.Errorf("parse sinkDSN error: %s", err)
+	}
+	factory, ok := globalFactories[dsn.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("audit sink %s has not been registered", dsn.Scheme)
+	}
+	return factory(dsn)
+}
+
+// Logger 结构体将审计事件异步地写入输出目标，写入速度跟不上时丢弃事件，不会阻塞调用方
+type Logger struct {
+	sink      Sink
+	events    chan *Event
+	done      chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// NewLogger 创建一个新的 Logger 实例，size 是缓冲队列的长度
+func NewLogger(sink Sink, size int) *Logger {
+	if size <= 0 {
+		size = _defaultBufferSize
+	}
+	l := &Logger{
+		sink:   sink,
+		events: make(chan *Event, size),
+		done:   make(chan struct{}),
+		closed: make(chan struct{}),
+	}
+	go l.run()
+	return l
+}
+
+// run 方法持续地将队列中的事件写入输出目标
+func (l *Logger) run() {
+	defer close(l.closed)
+	for {
+		select {
+		case e := <-l.events:
+			l.write(e)
+		case <-l.done:
+			// 关闭前写完队列中剩余的事件
+			for {
+				select {
+				case e := <-l.events:
+					l.write(e)
+				default:
+					if err := l.sink.Close(); err != nil {
+						log.Errorf("failed to close audit sink: %v", err)
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+// write 方法写入一条事件，写入失败时只记录日志
+func (l *Logger) write(e *Event) {
+	if err := l.sink.Write(e); err != nil {
+		log.Errorf("failed to write audit event: %+v: %v", e, err)
+	}
+}
+
+// Emit 方法提交一条审计事件，队列已满时丢弃该事件
+func (l *Logger) Emit(e *Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	select {
+	case <-l.done:
+		return
+	default:
+	}
+	select {
+	case l.events <- e:
+	default:
+		_metricDroppedEvents.Inc()
+	}
+}
+
+// Close 方法停止接收新的事件，并等待已提交的事件写入完成
+func (l *Logger) Close() error {
+	l.closeOnce.Do(func() { close(l.done) })
+	<-l.closed
+	return nil
+}
+
+// globalLogger 是全局的审计日志实例，为空时不记录审计事件
+var globalLogger atomic.Pointer[Logger]
+
+// SetLogger 设置全局的审计日志实例，并返回之前的实例
+func SetLogger(l *Logger) *Logger {
+	return globalLogger.Swap(l)
+}
+
+// Init 根据给定的 DSN 创建输出目标并设置全局的审计日志实例
+func Init(sinkDSN string) (*Logger, error) {
+	sink, err := Create(sinkDSN)
+	if err != nil {
+		return nil, err
+	}
+	l := NewLogger(sink, _defaultBufferSize)
+	SetLogger(l)
+	return l, nil
+}
+
+// Emit 向全局的审计日志实例提交一条审计事件
+func Emit(e *Event) {
+	if l := globalLogger.Load(); l != nil {
+		l.Emit(e)
+	}
+}
+
+// EmitRequest 根据请求提交一条审计事件，使用请求的对端地址和路径作为发起方和操作对象，
+// 客户端可以任意设置 X-Forwarded-For，因此只在对端属于 trust.Networks 时另外记录
+func EmitRequest(r *http.Request, typ, action, result, reason string) {
+	if globalLogger.Load() == nil {
+		return
+	}
+	e := &Event{
+		Type:     typ,
+		Action:   action,
+		Actor:    r.RemoteAddr,
+		Resource: r.Method + " " + r.URL.Path,
+		Result:   result,
+		Reason:   reason,
+	}
+	if trust.IsTrustedPeer(r.RemoteAddr) {
+		e.ForwardedFor = strings.Join(r.Header.Values("X-Forwarded-For"), ", ")
+	}
+	Emit(e)
+}
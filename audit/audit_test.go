@@ -0,0 +1,155 @@
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cnsync/gateway/trust"
+)
+
+type memorySink struct {
+	lock   sync.Mutex
+	events []*Event
+	block  chan struct{}
+}
+
+func (s *memorySink) Write(e *Event) error {
+	if s.block != nil {
+		<-s.block
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.events = append(s.events, e)
+	return nil
+}
+
+func (s *memorySink) Close() error { return nil }
+
+func TestLoggerNonBlocking(t *testing.T) {
+	sink := &memorySink{block: make(chan struct{})}
+	l := NewLogger(sink, 1)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			l.Emit(&Event{Type: TypeAdmin, Action: "test", Result: ResultSuccess})
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("emit should not block when the sink is slow")
+	}
+	close(sink.block)
+	l.Close()
+	if len(sink.events) == 0 || len(sink.events) >= 100 {
+		t.Fatalf("want part of the events to be dropped but got %d", len(sink.events))
+	}
+	// 关闭后提交的事件会被忽略
+	l.Emit(&Event{Type: TypeAdmin})
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	sink, err := Create("file://" + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewLogger(sink, 0)
+	l.Emit(&Event{Type: TypeConfig, Action: "update", Result: ResultSuccess})
+	l.Emit(&Event{Type: TypeAuth, Action: "protected", Result: ResultDeny})
+	l.Close()
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var got []Event
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var e Event
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, e)
+	}
+	if len(got) != 2 || got[0].Type != TypeConfig || got[1].Result != ResultDeny {
+		t.Fatalf("unexpected events: %+v", got)
+	}
+	if got[0].Time.IsZero() {
+		t.Fatal("want event time to be set")
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	events := make(chan Event, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var e Event
+		if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+			t.Error(err)
+		}
+		events <- e
+	}))
+	defer srv.Close()
+
+	sink, err := Create(srv.URL + "/audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewLogger(sink, 0)
+	l.Emit(&Event{Type: TypeAuth, Action: "protected", Result: ResultDeny})
+	l.Close()
+	select {
+	case e := <-events:
+		if e.Type != TypeAuth || e.Result != ResultDeny {
+			t.Fatalf("unexpected event: %+v", e)
+		}
+	default:
+		t.Fatal("want webhook to receive the event")
+	}
+}
+
+func TestCreateUnknownSink(t *testing.T) {
+	if _, err := Create("kafka://127.0.0.1:9092"); err == nil {
+		t.Fatal("want error for unregistered sink")
+	}
+}
+
+func TestEmitRequestActor(t *testing.T) {
+	sink := &memorySink{}
+	l := NewLogger(sink, 10)
+	defer SetLogger(SetLogger(l))
+	defer func(networks []*net.IPNet) { trust.Networks = networks }(trust.Networks)
+	trust.Networks = trust.MustParseCIDRs("10.0.0.0/8")
+
+	// 客户端设置的 X-Forwarded-For 不能冒充发起方
+	r := httptest.NewRequest("GET", "/debug/pprof", nil)
+	r.RemoteAddr = "203.0.113.1:1234"
+	r.Header.Set("X-Forwarded-For", "127.0.0.1")
+	EmitRequest(r, TypeAdmin, "debug", ResultSuccess, "")
+	// 受信任的代理转发的请求另外记录 X-Forwarded-For
+	r = httptest.NewRequest("GET", "/debug/pprof", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	r.Header.Set("X-Forwarded-For", "203.0.113.2")
+	EmitRequest(r, TypeAdmin, "debug", ResultSuccess, "")
+	l.Close()
+
+	if len(sink.events) != 2 {
+		t.Fatalf("want 2 events but got %d", len(sink.events))
+	}
+	if e := sink.events[0]; e.Actor != "203.0.113.1:1234" || e.ForwardedFor != "" {
+		t.Fatalf("unexpected event from untrusted peer: %+v", e)
+	}
+	if e := sink.events[1]; e.Actor != "10.0.0.1:1234" || e.ForwardedFor != "203.0.113.2" {
+		t.Fatalf("unexpected event from trusted proxy: %+v", e)
+	}
+}
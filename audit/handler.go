@@ -0,0 +1,62 @@
+package audit
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// statusRecorder 结构体记录处理程序写入的响应状态码
+type statusRecorder struct {
+	http.ResponseWriter
+	// status 是写入的响应状态码，未写入时为 0
+	status int
+}
+
+// WriteHeader 方法记录第一次写入的状态码
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+// Write 方法在未写入状态码时按 200 记录
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush 方法实现了 http.Flusher 接口，便于流式输出的调试处理程序使用
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		if r.status == 0 {
+			r.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Unwrap 方法返回原始的 ResponseWriter，供 http.ResponseController 使用
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Handler 函数返回记录操作结果的处理程序，在处理程序返回之后根据响应状态码提交审计事件，
+// 状态码小于 400 时结果为 success，否则为 failure
+func Handler(typ, action string, h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w}
+		h.ServeHTTP(rec, r)
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		if status >= http.StatusBadRequest {
+			EmitRequest(r, typ, action, ResultFailure, fmt.Sprintf("status %d", status))
+			return
+		}
+		EmitRequest(r, typ, action, ResultSuccess, "")
+	})
+}
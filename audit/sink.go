@@ -0,0 +1,95 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+// _webhookTimeout 是调用 webhook 的超时时间
+const _webhookTimeout = 5 * time.Second
+
+func init() {
+	Register("file", newFileSink)
+	Register("http", newWebhookSink)
+	Register("https", newWebhookSink)
+}
+
+// fileSink 结构体将审计事件以 JSON Lines 的格式追加写入文件
+type fileSink struct {
+	lock sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// newFileSink 根据 file:///path/to/audit.log 格式的 DSN 创建一个文件输出目标
+func newFileSink(dsn *url.URL) (Sink, error) {
+	path := dsn.Path
+	if path == "" {
+		path = dsn.Opaque
+	}
+	if path == "" {
+		return nil, fmt.Errorf("audit file path is empty")
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &fileSink{file: f, enc: json.NewEncoder(f)}, nil
+}
+
+// Write 方法将事件写入文件
+func (s *fileSink) Write(e *Event) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.enc.Encode(e)
+}
+
+// Close 方法关闭文件
+func (s *fileSink) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.file.Close()
+}
+
+// webhookSink 结构体将审计事件以 JSON 的格式 POST 到指定的 webhook
+type webhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// newWebhookSink 根据 http(s)://host/path 格式的 DSN 创建一个 webhook 输出目标
+func newWebhookSink(dsn *url.URL) (Sink, error) {
+	return &webhookSink{
+		url:    dsn.String(),
+		client: &http.Client{Timeout: _webhookTimeout},
+	}, nil
+}
+
+// Write 方法将事件发送到 webhook
+func (s *webhookSink) Write(e *Event) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Post(s.url, "application/json", bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected audit webhook status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close 方法关闭空闲连接
+func (s *webhookSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/kratos/selector"
+)
+
+const (
+	// _defaultBackpressureRatio 是默认每次 429 时并发上限的缩减比例
+	_defaultBackpressureRatio = 0.5
+	// _defaultBackpressureMinConcurrency 是默认的最小并发上限
+	_defaultBackpressureMinConcurrency = 1
+	// _defaultMaxRetryAfter 是默认遵守的最长 Retry-After
+	_defaultMaxRetryAfter = time.Minute
+)
+
+// ErrBackendBackpressure 表示后端节点返回 429 后网关减少了发往它的请求，且所有节点都已达到并发上限
+var ErrBackendBackpressure = fmt.Errorf("%w: backend is applying backpressure", ErrBackendRateLimited)
+
+// backpressureState 结构体记录了一个节点的在途请求数和并发上限
+type backpressureState struct {
+	// inflight 是在途的请求数
+	inflight int
+	// limit 是并发上限，为 0 时不限制
+	limit float64
+	// ceiling 是第一次 429 时的并发数，并发上限恢复到它时解除限制
+	ceiling float64
+	// pausedUntil 是 Retry-After 指定的恢复时间，在此之前不向节点发送请求
+	pausedUntil time.Time
+}
+
+// backpressure 结构体把上游的 429 响应当作背压信号，按节点地址以 AIMD 的方式调整并发上限：
+// 每次 429 时按比例缩减，其他响应时逐步增加，恢复到第一次 429 时的并发数后解除限制
+type backpressure struct {
+	lock           sync.Mutex
+	ratio          float64
+	minConcurrency float64
+	maxRetryAfter  time.Duration
+	states         map[string]*backpressureState
+	now            func() time.Time
+}
+
+// newBackpressure 函数根据端点配置创建一个背压控制器，未配置时返回 nil
+func newBackpressure(c *config.Backpressure) *backpressure {
+	if c == nil {
+		return nil
+	}
+	b := &backpressure{
+		ratio:          _defaultBackpressureRatio,
+		minConcurrency: _defaultBackpressureMinConcurrency,
+		maxRetryAfter:  _defaultMaxRetryAfter,
+		states:         make(map[string]*backpressureState),
+		now:            time.Now,
+	}
+	if c.DecreaseRatio > 0 && c.DecreaseRatio < 1 {
+		b.ratio = c.DecreaseRatio
+	}
+	if c.MinConcurrency > 0 {
+		b.minConcurrency = float64(c.MinConcurrency)
+	}
+	if c.MaxRetryAfter != nil && c.MaxRetryAfter.AsDuration() > 0 {
+		b.maxRetryAfter = c.MaxRetryAfter.AsDuration()
+	}
+	return b
+}
+
+// available 方法判断节点当前是否可以接收请求，调用时需要持有锁
+func (b *backpressure) available(s *backpressureState, now time.Time) bool {
+	if now.Before(s.pausedUntil) {
+		return false
+	}
+	return s.limit <= 0 || float64(s.inflight) < math.Floor(s.limit)
+}
+
+// filter 方法过滤掉暂停或达到并发上限的节点，所有节点都不可用时按正常方式选择，由 acquire 拒绝请求
+func (b *backpressure) filter(_ context.Context, nodes []selector.Node) []selector.Node {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	now := b.now()
+	selected := make([]selector.Node, 0, len(nodes))
+	for _, n := range nodes {
+		if s, ok := b.states[n.Address()]; ok && !b.available(s, now) {
+			continue
+		}
+		selected = append(selected, n)
+	}
+	if len(selected) == 0 {
+		return nodes
+	}
+	return selected
+}
+
+// acquire 方法为发往节点的请求占用一个并发，节点暂停或达到并发上限时返回 false
+func (b *backpressure) acquire(addr string) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	s, ok := b.states[addr]
+	if !ok {
+		s = &backpressureState{}
+		b.states[addr] = s
+	}
+	if !b.available(s, b.now()) {
+		return false
+	}
+	s.inflight++
+	return true
+}
+
+// release 方法在请求完成时释放占用的并发
+func (b *backpressure) release(addr string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	s, ok := b.states[addr]
+	if !ok {
+		return
+	}
+	s.inflight--
+	if s.inflight <= 0 && s.limit <= 0 && !b.now().Before(s.pausedUntil) {
+		delete(b.states, addr)
+	}
+}
+
+// record 方法根据上游的响应调整节点的并发上限
+func (b *backpressure) record(addr string, resp *http.Response) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	s, ok := b.states[addr]
+	if !ok {
+		return
+	}
+	if resp.StatusCode != http.StatusTooManyRequests {
+		if s.limit > 0 {
+			s.limit += 1 / s.limit
+			if s.limit >= s.ceiling {
+				s.limit, s.ceiling = 0, 0
+			}
+		}
+		return
+	}
+	if s.limit <= 0 {
+		s.ceiling = math.Max(float64(s.inflight), b.minConcurrency)
+		s.limit = s.ceiling
+	}
+	s.limit = math.Max(s.limit*b.ratio, b.minConcurrency)
+	now := b.now()
+	if d := parseRetryAfter(resp.Header.Get("Retry-After"), now); d > 0 {
+		s.pausedUntil = now.Add(min(d, b.maxRetryAfter))
+	}
+}
+
+// wrap 方法包装选择器的完成函数，在请求完成时释放占用的并发，完成函数可能被多次调用
+func (b *backpressure) wrap(addr string, done selector.DoneFunc) selector.DoneFunc {
+	var once sync.Once
+	return func(ctx context.Context, di selector.DoneInfo) {
+		once.Do(func() { b.release(addr) })
+		done(ctx, di)
+	}
+}
+
+// parseRetryAfter 函数解析 Retry-After 响应头，它可以是秒数或 HTTP 日期
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return t.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,189 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/middleware"
+	"github.com/cnsync/kratos/selector"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestBackpressure(t *testing.T) {
+	b := newBackpressure(&config.Backpressure{})
+	now := time.Unix(0, 0)
+	b.now = func() time.Time { return now }
+	const addr = "127.0.0.1:8001"
+	tooMany := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	ok := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+	// acquireAll 占用所有可用的并发，返回占用的数量
+	acquireAll := func() int {
+		n := 0
+		for b.acquire(addr) {
+			n++
+			if n > 100 {
+				break
+			}
+		}
+		return n
+	}
+	releaseAll := func(n int) {
+		for i := 0; i < n; i++ {
+			b.release(addr)
+		}
+	}
+
+	// 未收到 429 时不限制并发
+	for i := 0; i < 8; i++ {
+		if !b.acquire(addr) {
+			t.Fatalf("want request %d to be sent", i)
+		}
+	}
+	// 每次 429 时并发上限减半
+	b.record(addr, tooMany)
+	releaseAll(8)
+	if n := acquireAll(); n != 4 {
+		t.Fatalf("want concurrency 4 after a 429 but got %d", n)
+	}
+	b.record(addr, tooMany)
+	releaseAll(4)
+	if n := acquireAll(); n != 2 {
+		t.Fatalf("want concurrency 2 after two 429s but got %d", n)
+	}
+	// 其他响应时并发上限逐步恢复，恢复到第一次 429 时的并发数后解除限制
+	for i := 0; i < 40; i++ {
+		b.record(addr, ok)
+	}
+	releaseAll(2)
+	if n := acquireAll(); n <= 8 {
+		t.Fatalf("want the concurrency cap lifted but got %d", n)
+	}
+	releaseAll(101)
+
+	// Retry-After 之前不向节点发送请求
+	if !b.acquire(addr) {
+		t.Fatal("want the request to be sent")
+	}
+	tooMany.Header.Set("Retry-After", "2")
+	b.record(addr, tooMany)
+	b.release(addr)
+	if b.acquire(addr) {
+		t.Fatal("want the node paused until Retry-After")
+	}
+	nodes := []selector.Node{selector.NewNode("http", addr, nil), selector.NewNode("http", "127.0.0.1:8002", nil)}
+	if got := b.filter(context.Background(), nodes); len(got) != 1 || got[0].Address() != "127.0.0.1:8002" {
+		t.Fatalf("want the paused node filtered but got %v", got)
+	}
+	now = now.Add(2 * time.Second)
+	if !b.acquire(addr) {
+		t.Fatal("want the request to be sent after Retry-After")
+	}
+}
+
+func TestBackpressureRetryAfter(t *testing.T) {
+	var received atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+	endpoint := &config.Endpoint{
+		Path:         "/api/backpressure",
+		Protocol:     config.Protocol_HTTP,
+		Backpressure: &config.Backpressure{},
+		Backends:     []*config.Backend{{Target: strings.TrimPrefix(srv.URL, "http://")}},
+	}
+	c, err := NewFactory(nil)(EmptyBuildContext(), endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	send := func() error {
+		req := httptest.NewRequest("GET", "/api/backpressure", nil)
+		reqOpts := middleware.NewRequestOptions(endpoint)
+		ctx := middleware.NewRequestContext(context.Background(), reqOpts)
+		resp, err := c.RoundTrip(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		reqOpts.DoneFunc(ctx, selector.DoneInfo{})
+		return nil
+	}
+	// 收到 429 之后的请求在 Retry-After 之前被拒绝，不再发往后端
+	for i := 0; i < 20; i++ {
+		err := send()
+		if i == 0 && err != nil {
+			t.Fatal(err)
+		}
+		if i > 0 && !errors.Is(err, ErrBackendRateLimited) {
+			t.Fatalf("request %d: want ErrBackendRateLimited but got %v", i, err)
+		}
+	}
+	if got := received.Load(); got != 1 {
+		t.Fatalf("want 1 request sent to the backend but got %d", got)
+	}
+	time.Sleep(time.Second)
+	if err := send(); err != nil {
+		t.Fatal(err)
+	}
+	if got := received.Load(); got != 2 {
+		t.Fatalf("want 2 requests sent to the backend but got %d", got)
+	}
+}
+
+func TestBackpressureOutlierDetection(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+	addr := strings.TrimPrefix(srv.URL, "http://")
+	endpoint := &config.Endpoint{
+		Path:         "/api/backpressure",
+		Protocol:     config.Protocol_HTTP,
+		Backpressure: &config.Backpressure{},
+		OutlierDetection: &config.OutlierDetection{
+			ConsecutiveErrors: 3,
+			Cooldown:          durationpb.New(30 * time.Second),
+		},
+		Backends: []*config.Backend{{Target: addr}},
+	}
+	c, err := NewFactory(nil)(EmptyBuildContext(), endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	for i := 0; i < 5; i++ {
+		req := httptest.NewRequest("GET", "/api/backpressure", nil)
+		reqOpts := middleware.NewRequestOptions(endpoint)
+		ctx := middleware.NewRequestContext(context.Background(), reqOpts)
+		resp, err := c.RoundTrip(req.WithContext(ctx))
+		if i == 0 {
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			reqOpts.DoneFunc(ctx, selector.DoneInfo{})
+			continue
+		}
+		if !errors.Is(err, ErrBackendBackpressure) {
+			t.Fatalf("request %d: want ErrBackendBackpressure but got %v", i, err)
+		}
+	}
+	// 因背压被拒绝的请求没有发往节点，不能让异常检测摘除饱和的节点
+	d := c.(*client).outlier
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if s, ok := d.states[addr]; ok && s.errors > 0 {
+		t.Fatalf("want backpressure rejections not counted as node errors but got %d", s.errors)
+	}
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"context"
+	"hash/fnv"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cnsync/kratos/log"
+	"github.com/cnsync/kratos/selector"
+	"github.com/cnsync/kratos/selector/node/direct"
+	"github.com/cnsync/kratos/selector/p2c"
+	"github.com/cnsync/kratos/selector/random"
+	"github.com/cnsync/kratos/selector/wrr"
+)
+
+const (
+	// _balancerRoundRobin 是不考虑权重依次选择节点的均衡器名称
+	_balancerRoundRobin = "round_robin"
+	// _balancerConsistentHash 是按请求的哈希键选择节点的均衡器名称
+	_balancerConsistentHash = "consistent_hash"
+)
+
+var (
+	balancersLock sync.RWMutex
+	// balancers 记录了可以在端点配置中按名称使用的选择器构建器
+	balancers = map[string]selector.Builder{
+		p2c.Name:                p2c.NewBuilder(),
+		random.Name:             random.NewBuilder(),
+		wrr.Name:                wrr.NewBuilder(),
+		_balancerRoundRobin:     &selector.DefaultBuilder{Node: &direct.Builder{}, Balancer: &roundRobinBuilder{}},
+		_balancerConsistentHash: &selector.DefaultBuilder{Node: &direct.Builder{}, Balancer: &consistentHashBuilder{}},
+	}
+)
+
+// RegisterBalancer 注册一个选择器构建器，端点可以通过负载均衡配置中的名称使用它
+func RegisterBalancer(name string, builder selector.Builder) {
+	balancersLock.Lock()
+	defer balancersLock.Unlock()
+	balancers[name] = builder
+}
+
+// balancerBuilder 函数返回名称对应的选择器构建器，名称未注册时返回 false
+func balancerBuilder(name string) (selector.Builder, bool) {
+	balancersLock.RLock()
+	defer balancersLock.RUnlock()
+	b, ok := balancers[name]
+	return b, ok
+}
+
+// resolvePicker 函数按名称创建端点的选择器，名称未注册时记录警告并使用默认的选择器构建器
+func resolvePicker(name string, fallback selector.Builder) selector.Selector {
+	if b, ok := balancerBuilder(name); ok {
+		return b.Build()
+	}
+	log.Warnf("Unknown load balancer: %s, falling back to the default one", name)
+	return fallback.Build()
+}
+
+// roundRobinBuilder 结构体是 roundRobin 的构建器
+type roundRobinBuilder struct{}
+
+// Build 方法创建一个 roundRobin 实例
+func (*roundRobinBuilder) Build() selector.Balancer {
+	return &roundRobin{}
+}
+
+// roundRobin 结构体不考虑权重依次选择节点
+type roundRobin struct {
+	next atomic.Uint64
+}
+
+// Pick 方法依次选择节点
+func (b *roundRobin) Pick(_ context.Context, nodes []selector.WeightedNode) (selector.WeightedNode, selector.DoneFunc, error) {
+	if len(nodes) == 0 {
+		return nil, nil, selector.ErrNoAvailable
+	}
+	selected := nodes[(b.next.Add(1)-1)%uint64(len(nodes))]
+	return selected, selected.Pick(), nil
+}
+
+// hashKey 是请求的哈希键在上下文中的键
+type hashKey struct{}
+
+// withHashKey 函数返回携带请求的哈希键的上下文
+func withHashKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, hashKey{}, key)
+}
+
+// requestHashKey 函数返回请求的哈希键，没有配置请求头或请求没有携带请求头时使用对端的 IP，
+// 客户端可以任意设置 X-Forwarded-For 来选择节点，因此不使用它
+func requestHashKey(req *http.Request, header string) string {
+	if header != "" {
+		if v := req.Header.Get(header); v != "" {
+			return v
+		}
+	}
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
+
+// consistentHashBuilder 结构体是 consistentHash 的构建器
+type consistentHashBuilder struct{}
+
+// Build 方法创建一个 consistentHash 实例
+func (*consistentHashBuilder) Build() selector.Balancer {
+	return &consistentHash{}
+}
+
+// consistentHash 结构体使用最高随机权重哈希按请求的哈希键选择节点，相同的键总是选择相同的节点，
+// 节点加入或离开时只有原本选择该节点的键会改变选择
+type consistentHash struct{}
+
+// Pick 方法选择与请求的哈希键组合后哈希值最大的节点
+func (*consistentHash) Pick(ctx context.Context, nodes []selector.WeightedNode) (selector.WeightedNode, selector.DoneFunc, error) {
+	if len(nodes) == 0 {
+		return nil, nil, selector.ErrNoAvailable
+	}
+	key, _ := ctx.Value(hashKey{}).(string)
+	var selected selector.WeightedNode
+	var max uint64
+	for _, n := range nodes {
+		h := fnv.New64a()
+		h.Write([]byte(key))
+		h.Write([]byte{0})
+		h.Write([]byte(n.Address()))
+		if sum := h.Sum64(); selected == nil || sum > max {
+			selected, max = n, sum
+		}
+	}
+	return selected, selected.Pick(), nil
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/middleware"
+)
+
+func TestRequestHashKey(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	if got := requestHashKey(req, ""); got != "192.0.2.1" {
+		t.Fatalf("want the peer IP as the hash key but got %q", got)
+	}
+	req.Header.Set("X-User", "alice")
+	if got := requestHashKey(req, "X-User"); got != "alice" {
+		t.Fatalf("want the header as the hash key but got %q", got)
+	}
+	if got := requestHashKey(req, "X-Tenant"); got != "192.0.2.1" {
+		t.Fatalf("want the peer IP as the hash key without the header but got %q", got)
+	}
+}
+
+func TestBalancerByName(t *testing.T) {
+	var backends []*config.Backend
+	for i := 0; i < 3; i++ {
+		id := fmt.Sprint(i)
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Backend", id)
+		}))
+		defer s.Close()
+		backends = append(backends, &config.Backend{Target: strings.TrimPrefix(s.URL, "http://")})
+	}
+	newClient := func(lb *config.LoadBalancer) (Client, func(http.Header) string) {
+		endpoint := &config.Endpoint{
+			Path:         "/api/echo",
+			Protocol:     config.Protocol_HTTP,
+			Backends:     backends,
+			LoadBalancer: lb,
+		}
+		c, err := NewFactory(nil)(EmptyBuildContext(), endpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { c.Close() })
+		return c, func(header http.Header) string {
+			req := httptest.NewRequest("GET", "/api/echo", nil)
+			req.Header = header
+			ctx := middleware.NewRequestContext(context.Background(), middleware.NewRequestOptions(endpoint))
+			resp, err := c.RoundTrip(req.WithContext(ctx))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			return resp.Header.Get("X-Backend")
+		}
+	}
+
+	// 轮询依次选择每个节点
+	_, send := newClient(&config.LoadBalancer{Name: "round_robin"})
+	seen := map[string]bool{}
+	for i := 0; i < 3; i++ {
+		seen[send(http.Header{})] = true
+	}
+	if len(seen) != 3 {
+		t.Fatalf("want every node picked once but got %v", seen)
+	}
+
+	// 一致性哈希对相同的键总是选择相同的节点
+	_, send = newClient(&config.LoadBalancer{Name: "consistent_hash", HashHeader: "X-User"})
+	picked := map[string]string{}
+	for i := 0; i < 20; i++ {
+		user := fmt.Sprintf("user-%d", i%10)
+		got := send(http.Header{"X-User": {user}})
+		if prev, ok := picked[user]; ok && prev != got {
+			t.Fatalf("want %s always sent to node %s but got %s", user, prev, got)
+		}
+		picked[user] = got
+	}
+	seen = map[string]bool{}
+	for _, n := range picked {
+		seen[n] = true
+	}
+	if len(seen) < 2 {
+		t.Fatalf("want the keys spread across the nodes but got %v", seen)
+	}
+	// 没有请求头时按对端的 IP 哈希，客户端设置的 X-Forwarded-For 不影响选择的节点
+	first := send(http.Header{})
+	for i := 0; i < 20; i++ {
+		forged := http.Header{"X-Forwarded-For": {fmt.Sprintf("203.0.113.%d", i)}}
+		if got := send(forged); got != first {
+			t.Fatalf("want the peer always sent to node %s but got %s", first, got)
+		}
+	}
+
+	// 未知的名称使用默认的均衡器
+	if _, send = newClient(&config.LoadBalancer{Name: "unknown"}); send(http.Header{}) == "" {
+		t.Fatal("want the request served with the default balancer")
+	}
+}
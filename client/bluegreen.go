@@ -0,0 +1,207 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/proxy/debug"
+	"github.com/cnsync/kratos/selector"
+)
+
+var (
+	// ErrBlueGreenNotFound 表示端点未配置蓝绿发布
+	ErrBlueGreenNotFound = errors.New("blue/green deploy is not configured on the endpoint")
+	// ErrBlueGreenNoRollback 表示没有可以回滚的分组
+	ErrBlueGreenNoRollback = errors.New("no previous group to roll back to")
+)
+
+// globalBlueGreen 是全局的蓝绿发布开关注册表，开关的状态在配置重新加载后保留
+var globalBlueGreen = &blueGreenRegistry{switches: map[blueGreenKey]*blueGreenSwitch{}}
+
+func init() {
+	debug.Register("bluegreen", globalBlueGreen)
+}
+
+// blueGreenKey 是蓝绿发布开关的键，用端点的方法和路径标识一个端点
+type blueGreenKey struct {
+	method string
+	path   string
+}
+
+// blueGreenState 结构体记录了接收流量的分组和上一次接收流量的分组
+type blueGreenState struct {
+	Active   string `json:"active"`
+	Previous string `json:"previous,omitempty"`
+}
+
+// blueGreenSwitch 结构体是一个端点的蓝绿发布开关，切换和回滚都是一次原子的状态替换，
+// 每个请求只读取一次状态，因此切换过程中的请求要么全部发往旧分组，要么全部发往新分组
+type blueGreenSwitch struct {
+	// lock 串行化切换和回滚
+	lock  sync.Mutex
+	state atomic.Pointer[blueGreenState]
+	// configured 是配置文件中的分组
+	configured string
+	// metadataKey 是记录后端分组名称的元数据键
+	metadataKey string
+}
+
+// active 方法返回当前接收流量的分组
+func (s *blueGreenSwitch) active() string {
+	return s.state.Load().Active
+}
+
+// filter 方法返回只选择当前分组内节点的过滤器
+func (s *blueGreenSwitch) filter() selector.NodeFilter {
+	return groupFilter(s.metadataKey, s.active())
+}
+
+// switchTo 方法将全部流量切换到指定分组
+func (s *blueGreenSwitch) switchTo(group string) *blueGreenState {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	cur := s.state.Load()
+	if cur.Active == group {
+		return cur
+	}
+	next := &blueGreenState{Active: group, Previous: cur.Active}
+	s.state.Store(next)
+	return next
+}
+
+// rollback 方法将全部流量切换回上一次接收流量的分组
+func (s *blueGreenSwitch) rollback() (*blueGreenState, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	cur := s.state.Load()
+	if cur.Previous == "" {
+		return nil, ErrBlueGreenNoRollback
+	}
+	next := &blueGreenState{Active: cur.Previous, Previous: cur.Active}
+	s.state.Store(next)
+	return next, nil
+}
+
+// blueGreenRegistry 结构体按端点记录蓝绿发布开关
+type blueGreenRegistry struct {
+	lock     sync.Mutex
+	switches map[blueGreenKey]*blueGreenSwitch
+}
+
+// get 方法返回端点的蓝绿发布开关，端点未配置蓝绿发布时返回 nil。
+// 配置文件中的分组未变化时保留运行时切换的结果，变化时以配置文件为准
+func (r *blueGreenRegistry) get(endpoint *config.Endpoint) *blueGreenSwitch {
+	routing := endpoint.BackendGroup
+	if routing.GetActive() == "" {
+		return nil
+	}
+	key := blueGreenKey{method: endpoint.Method, path: endpoint.Path}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	metadataKey := groupMetadataKey(routing)
+	if s, ok := r.switches[key]; ok && s.configured == routing.Active && s.metadataKey == metadataKey {
+		return s
+	}
+	s := &blueGreenSwitch{configured: routing.Active, metadataKey: metadataKey}
+	s.state.Store(&blueGreenState{Active: routing.Active})
+	r.switches[key] = s
+	return s
+}
+
+// lookup 方法查找端点的蓝绿发布开关
+func (r *blueGreenRegistry) lookup(method, path string) (*blueGreenSwitch, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	s, ok := r.switches[blueGreenKey{method: method, path: path}]
+	if !ok {
+		return nil, ErrBlueGreenNotFound
+	}
+	return s, nil
+}
+
+// SwitchBlueGreen 函数将端点的全部流量原子地切换到指定的后端分组
+func SwitchBlueGreen(method, path, group string) error {
+	s, err := globalBlueGreen.lookup(method, path)
+	if err != nil {
+		return err
+	}
+	s.switchTo(group)
+	return nil
+}
+
+// RollbackBlueGreen 函数将端点的全部流量原子地切换回上一次接收流量的后端分组
+func RollbackBlueGreen(method, path string) error {
+	s, err := globalBlueGreen.lookup(method, path)
+	if err != nil {
+		return err
+	}
+	_, err = s.rollback()
+	return err
+}
+
+// blueGreenStatus 结构体是调试接口返回的端点蓝绿发布状态
+type blueGreenStatus struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	blueGreenState
+}
+
+// DebugHandler 方法返回蓝绿发布的调试处理器，用于查询、切换和回滚接收流量的分组
+func (r *blueGreenRegistry) DebugHandler() http.Handler {
+	debugMux := http.NewServeMux()
+	// 查询所有端点接收流量的分组
+	debugMux.HandleFunc("/debug/bluegreen/status", func(w http.ResponseWriter, req *http.Request) {
+		r.lock.Lock()
+		status := make([]blueGreenStatus, 0, len(r.switches))
+		for key, s := range r.switches {
+			status = append(status, blueGreenStatus{Method: key.method, Path: key.path, blueGreenState: *s.state.Load()})
+		}
+		r.lock.Unlock()
+		sort.Slice(status, func(i, j int) bool {
+			if status[i].Path != status[j].Path {
+				return status[i].Path < status[j].Path
+			}
+			return status[i].Method < status[j].Method
+		})
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(status)
+	})
+	// 将端点的全部流量切换到 group 参数指定的分组
+	debugMux.HandleFunc("/debug/bluegreen/switch", func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		group := query.Get("group")
+		if group == "" {
+			http.Error(w, "group is required", http.StatusBadRequest)
+			return
+		}
+		s, err := r.lookup(query.Get("method"), query.Get("path"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.switchTo(group))
+	})
+	// 将端点的全部流量切换回上一次接收流量的分组
+	debugMux.HandleFunc("/debug/bluegreen/rollback", func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		s, err := r.lookup(query.Get("method"), query.Get("path"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		state, err := s.rollback()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(state)
+	})
+	return debugMux
+}
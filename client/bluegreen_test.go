@@ -0,0 +1,158 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/middleware"
+)
+
+func TestBlueGreen(t *testing.T) {
+	newBackend := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(name))
+		}))
+	}
+	blue, green := newBackend("blue"), newBackend("green")
+	defer blue.Close()
+	defer green.Close()
+
+	newEndpoint := func(active string) *config.Endpoint {
+		return &config.Endpoint{
+			Path:         "/api/bluegreen",
+			Method:       "GET",
+			Protocol:     config.Protocol_HTTP,
+			BackendGroup: &config.BackendGroupRouting{MetadataKey: "color", Active: active},
+			Backends: []*config.Backend{
+				{Target: strings.TrimPrefix(blue.URL, "http://"), Metadata: map[string]string{"color": "blue"}},
+				{Target: strings.TrimPrefix(green.URL, "http://"), Metadata: map[string]string{"color": "green"}},
+			},
+		}
+	}
+	endpoint := newEndpoint("blue")
+	c, err := NewFactory(nil)(EmptyBuildContext(), endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	send := func(c Client, endpoint *config.Endpoint) (string, error) {
+		req := httptest.NewRequest("GET", "/api/bluegreen", nil)
+		ctx := middleware.NewRequestContext(context.Background(), middleware.NewRequestOptions(endpoint))
+		resp, err := c.RoundTrip(req.WithContext(ctx))
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+	admin := globalBlueGreen.DebugHandler()
+	call := func(path string) int {
+		w := httptest.NewRecorder()
+		admin.ServeHTTP(w, httptest.NewRequest("POST", path, nil))
+		return w.Code
+	}
+
+	// 切换和回滚的过程中持续发送请求，切换完成后开始的请求只发往新分组
+	var (
+		want    atomic.Value
+		stop    = make(chan struct{})
+		wg      sync.WaitGroup
+		lock    sync.Mutex
+		failure string
+	)
+	want.Store("blue")
+	fail := func(msg string) {
+		lock.Lock()
+		if failure == "" {
+			failure = msg
+		}
+		lock.Unlock()
+	}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				expected := want.Load().(string)
+				got, err := send(c, endpoint)
+				if err != nil {
+					fail("request failed: " + err.Error())
+					return
+				}
+				// 读取期望分组之后才可能发生切换，因此请求只能发往期望分组或切换后的分组
+				if now := want.Load().(string); got != expected && got != now {
+					fail("want " + expected + " but got " + got)
+					return
+				}
+			}
+		}()
+	}
+	steps := []struct {
+		path   string
+		code   int
+		active string
+	}{
+		{"/debug/bluegreen/rollback?method=GET&path=/api/bluegreen", http.StatusConflict, "blue"},
+		{"/debug/bluegreen/switch?method=GET&path=/api/unknown&group=green", http.StatusNotFound, "blue"},
+		{"/debug/bluegreen/switch?method=GET&path=/api/bluegreen&group=green", http.StatusOK, "green"},
+		{"/debug/bluegreen/rollback?method=GET&path=/api/bluegreen", http.StatusOK, "blue"},
+		{"/debug/bluegreen/rollback?method=GET&path=/api/bluegreen", http.StatusOK, "green"},
+	}
+	for _, step := range steps {
+		for i := 0; i < 50; i++ {
+			if _, err := send(c, endpoint); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if code := call(step.path); code != step.code {
+			t.Fatalf("%s: want status %d but got %d", step.path, step.code, code)
+		}
+		want.Store(step.active)
+		// 切换完成后的请求全部发往新分组
+		for i := 0; i < 50; i++ {
+			got, err := send(c, endpoint)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != step.active {
+				t.Fatalf("%s: want %s but got %s", step.path, step.active, got)
+			}
+		}
+	}
+	close(stop)
+	wg.Wait()
+	if failure != "" {
+		t.Fatal(failure)
+	}
+
+	// 配置未变化时重新加载保留运行时切换的结果，配置变化时以配置为准
+	for _, tt := range []struct{ configured, want string }{{"blue", "green"}, {"green", "green"}, {"blue", "blue"}} {
+		endpoint := newEndpoint(tt.configured)
+		reloaded, err := NewFactory(nil)(EmptyBuildContext(), endpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := send(reloaded, endpoint)
+		reloaded.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Fatalf("configured %s: want %s but got %s", tt.configured, tt.want, got)
+		}
+	}
+}
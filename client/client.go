@@ -3,8 +3,10 @@ package client
 import (
 	"io"
 	"net/http"
+	"sync/atomic"
 	"time"
 
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
 	"github.com/cnsync/gateway/middleware"
 	"github.com/cnsync/kratos/selector"
 )
@@ -15,6 +17,12 @@ type client struct {
 	applier *nodeApplier
 	// selector 是一个选择器，用于选择服务节点
 	selector selector.Selector
+	// outlier 是异常节点检测器，端点未配置时为 nil
+	outlier *outlierDetector
+	// blueGreen 是蓝绿发布开关，端点未配置时为 nil
+	blueGreen *blueGreenSwitch
+	// backpressure 根据上游的 429 响应限制发往节点的并发，端点未配置时为 nil
+	backpressure *backpressure
 }
 
 // Client 接口定义了一个客户端，它继承自 http.RoundTripper 和 io.Closer 接口
@@ -23,11 +31,24 @@ type Client interface {
 	io.Closer
 }
 
+// NodeCounter 接口用于获取客户端当前可用的节点数量
+type NodeCounter interface {
+	NodeCount() int64
+}
+
+// NodeWarmer 接口用于获取客户端当前可用节点的预热目标
+type NodeWarmer interface {
+	WarmupTargets() []WarmupTarget
+}
+
 // newClient 函数用于创建一个新的客户端实例
 func newClient(applier *nodeApplier, selector selector.Selector) *client {
 	return &client{
-		applier:  applier,
-		selector: selector,
+		applier:      applier,
+		selector:     selector,
+		outlier:      applier.outlier,
+		blueGreen:    globalBlueGreen.get(applier.endpoint),
+		backpressure: newBackpressure(applier.endpoint.Backpressure),
 	}
 }
 
@@ -38,6 +59,17 @@ func (c *client) Close() error {
 	return nil
 }
 
+// NodeCount 方法返回最近一次应用到选择器中的节点数量
+func (c *client) NodeCount() int64 {
+	return atomic.LoadInt64(&c.applier.nodes)
+}
+
+// WarmupTargets 方法返回最近一次应用到选择器中的节点的预热目标
+func (c *client) WarmupTargets() []WarmupTarget {
+	nodes, _ := c.applier.applied.Load().([]selector.Node)
+	return warmupTargets(nodes)
+}
+
 func (c *client) RoundTrip(req *http.Request) (resp *http.Response, err error) {
 	// 获取请求的上下文
 	ctx := req.Context()
@@ -45,8 +77,34 @@ func (c *client) RoundTrip(req *http.Request) (resp *http.Response, err error) {
 	reqOpt, _ := middleware.FromRequestContext(ctx)
 	// 从请求上下文中获取选择器过滤器
 	filter, _ := middleware.SelectorFiltersFromContext(ctx)
+	// 请求头指定了后端分组时只在分组内选择，复制过滤器列表以免修改请求选项中的列表
+	if groupFilter := backendGroupFilter(req, c.applier.endpoint.BackendGroup); groupFilter != nil {
+		filter = append(filter[:len(filter):len(filter)], groupFilter)
+	} else if c.blueGreen != nil {
+		// 配置了蓝绿发布时只选择当前接收流量的分组
+		filter = append(filter[:len(filter):len(filter)], c.blueGreen.filter())
+	}
+	// 避开连续失败而被暂时摘除的节点
+	if c.outlier != nil {
+		filter = append(filter[:len(filter):len(filter)], c.outlier.filter)
+	}
+	// 避开返回 429 后暂停或达到并发上限的节点
+	if c.backpressure != nil {
+		filter = append(filter[:len(filter):len(filter)], c.backpressure.filter)
+	}
+	// 按预热和冷却的进度减少新加入和离开的节点参与选择的比例
+	if c.applier.slowStart != nil {
+		filter = append(filter[:len(filter):len(filter)], c.applier.slowStart.filter)
+	}
+	// 按一致性哈希均衡时将请求的哈希键传递给选择器，按成本均衡时将请求成本传递给选择器
+	selectCtx := ctx
+	if lb := c.applier.endpoint.LoadBalancer; lb.GetName() == _balancerConsistentHash {
+		selectCtx = withHashKey(ctx, requestHashKey(req, lb.HashHeader))
+	} else if lb.GetName() == "" && lb.GetPolicy() == config.LoadBalancer_LEAST_COST {
+		selectCtx = withRequestCost(ctx, requestCost(req, lb))
+	}
 	// 使用选择器选择一个节点，并获取一个完成函数和可能的错误
-	n, done, err := c.selector.Select(ctx, selector.WithNodeFilter(filter...))
+	n, done, err := c.selector.Select(selectCtx, selector.WithNodeFilter(filter...))
 	// 如果发生错误，返回 nil 和错误
 	if err != nil {
 		return nil, err
@@ -74,11 +132,52 @@ func (c *client) RoundTrip(req *http.Request) (resp *http.Response, err error) {
 	}
 	// 重置请求 URI，因为它在发送请求时不需要
 	req.RequestURI = ""
-
+	// 按后端的出站速率限制排队或拒绝请求
+	if backendNode.limiter != nil {
+		if err := backendNode.limiter.wait(ctx); err != nil {
+			done(ctx, selector.DoneInfo{Err: err})
+			return nil, err
+		}
+	}
+	// 检查转发给上游的请求头大小，在节点变换之前删除可以删除的请求头，避免删除已签名的请求头
+	if err := enforceHeaderLimit(req, c.applier.endpoint.UpstreamHeaderLimit); err != nil {
+		done(ctx, selector.DoneInfo{Err: err})
+		return nil, err
+	}
+	// 根据所选节点变换请求，例如适配不同版本的后端接口、对请求签名
+	for _, transform := range middleware.NodeTransformsFromContext(ctx) {
+		if err := transform(req, n); err != nil {
+			done(ctx, selector.DoneInfo{Err: err})
+			return nil, err
+		}
+	}
+	// 所有请求头都已添加，节点变换添加的请求头同样不能超过限制，此时只检查不删除
+	if err := checkHeaderLimit(req, c.applier.endpoint.UpstreamHeaderLimit); err != nil {
+		done(ctx, selector.DoneInfo{Err: err})
+		return nil, err
+	}
+	// 节点返回 429 后限制发往它的并发，所有节点都达到上限时拒绝请求
+	// 请求没有发往节点，必须在异常检测之前拒绝，否则饱和的节点会被当作失败而摘除
+	if c.backpressure != nil {
+		if !c.backpressure.acquire(addr) {
+			done(ctx, selector.DoneInfo{Err: ErrBackendBackpressure})
+			return nil, ErrBackendBackpressure
+		}
+		done = c.backpressure.wrap(addr, done)
+	}
+	// 请求完成时记录节点是否失败，包括代理在重试或复制响应体失败时调用的完成函数
+	var status int
+	if c.outlier != nil {
+		done = c.outlier.wrap(addr, &status, done)
+	}
 	// 记录请求开始时间
 	startAt := time.Now()
 	// 使用后端节点的客户端发送请求，并获取响应和可能的错误
-	resp, err = backendNode.client.Do(req)
+	resp, err = backendNode.do(req)
+	// 上游请求了不被允许的 TLS 重新协商时返回明确的错误
+	if isTLSRenegotiationError(err) {
+		err = backendNode.renegotiationError(req, err)
+	}
 	// 计算并记录上游响应时间
 	reqOpt.UpstreamResponseTime = append(reqOpt.UpstreamResponseTime, time.Since(startAt).Seconds())
 	// 如果发生错误，调用完成函数并返回 nil 和错误
@@ -89,6 +188,12 @@ func (c *client) RoundTrip(req *http.Request) (resp *http.Response, err error) {
 	}
 	// 记录上游状态码
 	reqOpt.UpstreamStatusCode = append(reqOpt.UpstreamStatusCode, resp.StatusCode)
+	// 完成函数按上游状态码判断节点是否返回了 5xx
+	status = resp.StatusCode
+	// 根据上游的响应调整节点的并发上限
+	if c.backpressure != nil {
+		c.backpressure.record(addr, resp)
+	}
 	// 将完成函数设置到请求选项中
 	reqOpt.DoneFunc = done
 	// 返回响应和 nil 错误
@@ -0,0 +1,308 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/middleware"
+	"github.com/cnsync/kratos/selector"
+)
+
+func TestNodeTransform(t *testing.T) {
+	var (
+		lock     sync.Mutex
+		received = map[string][]string{}
+	)
+	newBackend := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lock.Lock()
+			received[name] = append(received[name], r.URL.Path+" "+r.Header.Get("X-Api-Version"))
+			lock.Unlock()
+		}))
+	}
+	v1, v2 := newBackend("v1"), newBackend("v2")
+	defer v1.Close()
+	defer v2.Close()
+
+	endpoint := &config.Endpoint{
+		Path:     "/api/users",
+		Protocol: config.Protocol_HTTP,
+		Backends: []*config.Backend{
+			{Target: strings.TrimPrefix(v1.URL, "http://"), Metadata: map[string]string{"api": "v1"}},
+			{Target: strings.TrimPrefix(v2.URL, "http://"), Metadata: map[string]string{"api": "v2"}},
+		},
+	}
+	c, err := NewFactory(nil)(EmptyBuildContext(), endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	// 新版本的后端使用不同的路径，并需要声明接口版本
+	transform := func(req *http.Request, n selector.Node) error {
+		if n.Metadata()["api"] == "v2" {
+			req.URL.Path = "/v2" + req.URL.Path
+			req.Header.Set("X-Api-Version", "2")
+		}
+		return nil
+	}
+	for i := 0; i < 20; i++ {
+		req := httptest.NewRequest("GET", "/api/users", nil)
+		ctx := middleware.NewRequestContext(context.Background(), middleware.NewRequestOptions(endpoint))
+		ctx = middleware.WithNodeTransform(ctx, transform)
+		resp, err := c.RoundTrip(req.WithContext(ctx))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	if len(received["v1"]) == 0 || len(received["v2"]) == 0 {
+		t.Fatalf("want requests on both backends but got %v", received)
+	}
+	for _, r := range received["v1"] {
+		if r != "/api/users " {
+			t.Fatalf("want the v1 request untouched but got %q", r)
+		}
+	}
+	for _, r := range received["v2"] {
+		if r != "/v2/api/users 2" {
+			t.Fatalf("want the v2 request transformed but got %q", r)
+		}
+	}
+}
+
+func TestBackendGroup(t *testing.T) {
+	var (
+		lock     sync.Mutex
+		received = map[string]int{}
+	)
+	newBackend := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lock.Lock()
+			received[name]++
+			lock.Unlock()
+		}))
+	}
+	control, experiment := newBackend("control"), newBackend("experiment")
+	defer control.Close()
+	defer experiment.Close()
+
+	endpoint := &config.Endpoint{
+		Path:         "/api/search",
+		Protocol:     config.Protocol_HTTP,
+		BackendGroup: &config.BackendGroupRouting{},
+		Backends: []*config.Backend{
+			{Target: strings.TrimPrefix(control.URL, "http://"), Metadata: map[string]string{"group": "control"}},
+			{Target: strings.TrimPrefix(experiment.URL, "http://"), Metadata: map[string]string{"group": "experiment"}},
+		},
+	}
+	c, err := NewFactory(nil)(EmptyBuildContext(), endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	send := func(group string) {
+		req := httptest.NewRequest("GET", "/api/search", nil)
+		if group != "" {
+			req.Header.Set("X-Route-Weight-Group", group)
+		}
+		ctx := middleware.NewRequestContext(context.Background(), middleware.NewRequestOptions(endpoint))
+		resp, err := c.RoundTrip(req.WithContext(ctx))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	tests := []struct {
+		group string
+		want  map[string]bool
+	}{
+		{"experiment", map[string]bool{"experiment": true}},
+		{"control", map[string]bool{"control": true}},
+		// 未携带分组或分组不存在时在所有后端中选择
+		{"", map[string]bool{"control": true, "experiment": true}},
+		{"unknown", map[string]bool{"control": true, "experiment": true}},
+	}
+	for _, tt := range tests {
+		lock.Lock()
+		received = map[string]int{}
+		lock.Unlock()
+		for i := 0; i < 50; i++ {
+			send(tt.group)
+		}
+		lock.Lock()
+		got := map[string]bool{}
+		for name := range received {
+			got[name] = true
+		}
+		lock.Unlock()
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("group %q: want backends %v but got %v", tt.group, tt.want, received)
+		}
+	}
+}
+
+func TestIsolateConnections(t *testing.T) {
+	var (
+		lock        sync.Mutex
+		serverNames []string
+		misrouted   int
+	)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 请求的 Host 与连接的 TLS 服务器名称不一致说明请求复用了其他后端的连接
+		if r.TLS.ServerName != r.Host {
+			lock.Lock()
+			misrouted++
+			lock.Unlock()
+		}
+	}))
+	srv.EnableHTTP2 = true
+	srv.TLS = &tls.Config{
+		GetConfigForClient: func(hello *tls.ClientHelloInfo) (*tls.Config, error) {
+			lock.Lock()
+			serverNames = append(serverNames, hello.ServerName)
+			lock.Unlock()
+			return nil, nil
+		},
+	}
+	srv.StartTLS()
+	defer srv.Close()
+	addr := strings.TrimPrefix(srv.URL, "https://")
+
+	for _, isolate := range []bool{false, true} {
+		lock.Lock()
+		serverNames, misrouted = nil, 0
+		lock.Unlock()
+		// 两个后端位于同一个地址，按 TLS 服务器名称区分
+		endpoint := &config.Endpoint{
+			Path:         "/api/echo",
+			Protocol:     config.Protocol_HTTP,
+			BackendGroup: &config.BackendGroupRouting{},
+		}
+		for _, name := range []string{"a", "b"} {
+			endpoint.Backends = append(endpoint.Backends, &config.Backend{
+				Target:             addr,
+				Tls:                true,
+				TlsConfigName:      "insecure",
+				Metadata:           map[string]string{"group": name, "host": name + ".example.com"},
+				IsolateConnections: isolate,
+			})
+		}
+		tlsConfigs := map[string]*tls.Config{"insecure": {InsecureSkipVerify: true}}
+		buildContext := &BuildContext{TLSConfigs: tlsConfigs, TLSClientStore: NewHTTPSClientStore(tlsConfigs)}
+		c, err := NewFactory(nil)(buildContext, endpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 10; i++ {
+			for _, group := range []string{"a", "b"} {
+				req := httptest.NewRequest("GET", "/api/echo", nil)
+				req.Header.Set("X-Route-Weight-Group", group)
+				ctx := middleware.NewRequestContext(context.Background(), middleware.NewRequestOptions(endpoint))
+				resp, err := c.RoundTrip(req.WithContext(ctx))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if resp.ProtoMajor != 2 {
+					t.Fatalf("want HTTP/2 but got %s", resp.Proto)
+				}
+				resp.Body.Close()
+			}
+		}
+		c.Close()
+
+		lock.Lock()
+		names := append([]string(nil), serverNames...)
+		gotMisrouted := misrouted
+		lock.Unlock()
+		sort.Strings(names)
+		if !isolate {
+			// 共享连接池时两个后端复用同一个连接
+			if len(names) != 1 || gotMisrouted == 0 {
+				t.Fatalf("want a coalesced connection but got server names %v and %d misrouted requests", names, gotMisrouted)
+			}
+			continue
+		}
+		if !reflect.DeepEqual(names, []string{"a.example.com", "b.example.com"}) || gotMisrouted != 0 {
+			t.Fatalf("want a connection per backend but got server names %v and %d misrouted requests", names, gotMisrouted)
+		}
+	}
+}
+
+func TestEgressProxy(t *testing.T) {
+	var (
+		lock    sync.Mutex
+		proxied []string
+	)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 经过代理的请求使用绝对 URI
+		lock.Lock()
+		proxied = append(proxied, r.URL.Host)
+		lock.Unlock()
+		w.Header().Set("X-Backend", "proxy")
+	}))
+	defer proxy.Close()
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Backend", "direct")
+	}))
+	defer backend.Close()
+
+	tests := []struct {
+		name        string
+		target      string
+		egressProxy string
+		want        string
+	}{
+		{name: "proxy", target: "backend.internal:8000", egressProxy: proxy.URL, want: "proxy"},
+		{name: "direct", target: strings.TrimPrefix(backend.URL, "http://"), egressProxy: "direct", want: "direct"},
+		{name: "default", target: strings.TrimPrefix(backend.URL, "http://"), want: "direct"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &config.Endpoint{
+				Path:     "/api/echo",
+				Protocol: config.Protocol_HTTP,
+				Backends: []*config.Backend{{Target: tt.target, EgressProxy: tt.egressProxy}},
+			}
+			c, err := NewFactory(nil)(EmptyBuildContext(), endpoint)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+			req := httptest.NewRequest("GET", "/api/echo", nil)
+			ctx := middleware.NewRequestContext(context.Background(), middleware.NewRequestOptions(endpoint))
+			resp, err := c.RoundTrip(req.WithContext(ctx))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if got := resp.Header.Get("X-Backend"); got != tt.want {
+				t.Fatalf("want the request served by %s but got %s", tt.want, got)
+			}
+		})
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	if len(proxied) != 1 {
+		t.Fatalf("want 1 request proxied but got %v", proxied)
+	}
+
+	// h2c 的 gRPC 后端不支持出站代理
+	_, err := NewFactory(nil)(EmptyBuildContext(), &config.Endpoint{
+		Path:     "/api/echo",
+		Protocol: config.Protocol_GRPC,
+		Backends: []*config.Backend{{Target: "backend.internal:9000", EgressProxy: proxy.URL}},
+	})
+	if err == nil {
+		t.Fatal("want an error for the egress proxy of an h2c backend")
+	}
+}
@@ -0,0 +1,185 @@
+package client
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/kratos/selector"
+)
+
+var (
+	// 确保 costNode 实现了 selector.WeightedNode 接口
+	_ selector.WeightedNode = (*costNode)(nil)
+	// 确保 costBalancer 实现了 selector.Balancer 接口
+	_ selector.Balancer = (*costBalancer)(nil)
+)
+
+// _defaultCost 是没有成本提示的请求的默认成本
+const _defaultCost = int64(1)
+
+// costKey 是请求成本在上下文中的键
+type costKey struct{}
+
+// withRequestCost 函数将请求成本保存到上下文中
+func withRequestCost(ctx context.Context, cost int64) context.Context {
+	return context.WithValue(ctx, costKey{}, cost)
+}
+
+// requestCostFromContext 函数从上下文中获取请求成本，不存在时返回默认成本
+func requestCostFromContext(ctx context.Context) int64 {
+	if cost, ok := ctx.Value(costKey{}).(int64); ok {
+		return cost
+	}
+	return _defaultCost
+}
+
+// requestCost 函数根据负载均衡配置计算请求的成本，请求头中的提示优先于路径前缀
+func requestCost(req *http.Request, lb *config.LoadBalancer) int64 {
+	if lb.CostHeader != "" {
+		if v := req.Header.Get(lb.CostHeader); v != "" {
+			if cost, err := strconv.ParseInt(v, 10, 64); err == nil && cost > 0 {
+				// 请求头由客户端控制，因此限制其上限
+				if lb.MaxCost > 0 && cost > lb.MaxCost {
+					cost = lb.MaxCost
+				}
+				return cost
+			}
+		}
+	}
+	// 使用最长匹配的路径前缀
+	var matched *config.PathCost
+	for _, pc := range lb.PathCosts {
+		if strings.HasPrefix(req.URL.Path, pc.Prefix) && (matched == nil || len(pc.Prefix) > len(matched.Prefix)) {
+			matched = pc
+		}
+	}
+	if matched != nil && matched.Cost > 0 {
+		return matched.Cost
+	}
+	if lb.DefaultCost > 0 {
+		return lb.DefaultCost
+	}
+	return _defaultCost
+}
+
+// costNode 结构体是记录在途请求成本的加权节点
+type costNode struct {
+	selector.Node
+	// load 是节点上所有在途请求的成本之和
+	load int64
+	// lastPick 是最近一次选择该节点的时间
+	lastPick int64
+}
+
+// Raw 方法返回原始节点
+func (n *costNode) Raw() selector.Node {
+	return n.Node
+}
+
+// Weight 方法返回节点的权重，未设置或不合法时返回默认权重
+func (n *costNode) Weight() float64 {
+	if w := n.InitialWeight(); w != nil && *w > 0 {
+		return float64(*w)
+	}
+	return float64(_defaultWeight)
+}
+
+// Pick 方法记录选择节点的时间，在途成本由均衡器负责记录
+func (n *costNode) Pick() selector.DoneFunc {
+	atomic.StoreInt64(&n.lastPick, time.Now().UnixNano())
+	return func(context.Context, selector.DoneInfo) {}
+}
+
+// PickElapsed 方法返回自上次选择以来的时间间隔
+func (n *costNode) PickElapsed() time.Duration {
+	return time.Duration(time.Now().UnixNano() - atomic.LoadInt64(&n.lastPick))
+}
+
+// score 方法返回将成本为 cost 的请求分配给节点后的相对负载
+func (n *costNode) score(cost int64) float64 {
+	return float64(atomic.LoadInt64(&n.load)+cost) / n.Weight()
+}
+
+// costNodeBuilder 结构体是 costNode 的构建器
+type costNodeBuilder struct{}
+
+// Build 方法创建一个 costNode 实例
+func (*costNodeBuilder) Build(n selector.Node) selector.WeightedNode {
+	return &costNode{Node: n}
+}
+
+// costBalancer 结构体是按在途请求成本选择节点的均衡器，
+// 选择分配请求后相对负载最小的节点，负载相同时随机选择
+type costBalancer struct {
+	mu sync.Mutex
+	r  *rand.Rand
+}
+
+// Pick 方法选择一个节点，并在请求完成前将请求成本计入节点负载
+func (b *costBalancer) Pick(ctx context.Context, nodes []selector.WeightedNode) (selector.WeightedNode, selector.DoneFunc, error) {
+	if len(nodes) == 0 {
+		return nil, nil, selector.ErrNoAvailable
+	}
+	cost := requestCostFromContext(ctx)
+	var (
+		selected *costNode
+		minScore float64
+		ties     int
+	)
+	b.mu.Lock()
+	for _, wn := range nodes {
+		n, ok := wn.(*costNode)
+		if !ok {
+			continue
+		}
+		score := n.score(cost)
+		switch {
+		case selected == nil || score < minScore:
+			selected, minScore, ties = n, score, 1
+		case score == minScore:
+			// 蓄水池抽样，使负载相同的节点被等概率选择
+			ties++
+			if b.r.Intn(ties) == 0 {
+				selected = n
+			}
+		}
+	}
+	b.mu.Unlock()
+	if selected == nil {
+		return nil, nil, selector.ErrNoAvailable
+	}
+	atomic.AddInt64(&selected.load, cost)
+	nodeDone := selected.Pick()
+	var once sync.Once
+	done := func(ctx context.Context, di selector.DoneInfo) {
+		// 完成函数可能被调用多次，只释放一次成本
+		once.Do(func() {
+			atomic.AddInt64(&selected.load, -cost)
+			nodeDone(ctx, di)
+		})
+	}
+	return selected, done, nil
+}
+
+// costBalancerBuilder 结构体是 costBalancer 的构建器
+type costBalancerBuilder struct{}
+
+// Build 方法创建一个 costBalancer 实例
+func (*costBalancerBuilder) Build() selector.Balancer {
+	return &costBalancer{r: rand.New(rand.NewSource(time.Now().UnixNano()))}
+}
+
+// newCostPickerBuilder 函数返回一个按在途请求成本选择节点的选择器构建器
+func newCostPickerBuilder() selector.Builder {
+	return &selector.DefaultBuilder{
+		Node:     &costNodeBuilder{},
+		Balancer: &costBalancerBuilder{},
+	}
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/kratos/selector"
+)
+
+func TestRequestCost(t *testing.T) {
+	lb := &config.LoadBalancer{
+		Policy:     config.LoadBalancer_LEAST_COST,
+		CostHeader: "X-Request-Cost",
+		PathCosts: []*config.PathCost{
+			{Prefix: "/api", Cost: 2},
+			{Prefix: "/api/report", Cost: 20},
+		},
+		MaxCost: 50,
+	}
+	tests := []struct {
+		path   string
+		header string
+		want   int64
+	}{
+		{"/health", "", 1},
+		{"/api/users", "", 2},
+		{"/api/report/daily", "", 20},
+		{"/api/users", "8", 8},
+		{"/api/users", "1000", 50},
+		{"/api/users", "-1", 2},
+		{"/api/users", "abc", 2},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		if tt.header != "" {
+			req.Header.Set("X-Request-Cost", tt.header)
+		}
+		if got := requestCost(req, lb); got != tt.want {
+			t.Errorf("%s %q: want cost %d but got %d", tt.path, tt.header, tt.want, got)
+		}
+	}
+}
+
+func TestCostBalancer(t *testing.T) {
+	picker := newCostPickerBuilder().Build()
+	picker.Apply([]selector.Node{
+		newNode(EmptyBuildContext(), "127.0.0.1:8001", config.Protocol_HTTP, nil, nil, "", ""),
+		newNode(EmptyBuildContext(), "127.0.0.1:8002", config.Protocol_HTTP, nil, nil, "", ""),
+	})
+	ctx := context.Background()
+
+	// 一个昂贵的请求占用其中一个节点
+	expensive, expensiveDone, err := picker.Select(withRequestCost(ctx, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 后续的廉价请求都应避开该节点，直到其负载低于另一个节点
+	var dones []selector.DoneFunc
+	for i := 0; i < 9; i++ {
+		n, done, err := picker.Select(withRequestCost(ctx, 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n.Address() == expensive.Address() {
+			t.Fatalf("request %d: want node other than %s", i, expensive.Address())
+		}
+		dones = append(dones, done)
+	}
+	// 昂贵的请求完成后，负载较低的节点重新被选择
+	expensiveDone(ctx, selector.DoneInfo{})
+	// 重复调用完成函数不会重复释放成本
+	expensiveDone(ctx, selector.DoneInfo{})
+	n, _, err := picker.Select(withRequestCost(ctx, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Address() != expensive.Address() {
+		t.Fatalf("want node %s after the expensive request done but got %s", expensive.Address(), n.Address())
+	}
+	for _, done := range dones {
+		done(ctx, selector.DoneInfo{})
+	}
+}
+
+func TestCostBalancerWeight(t *testing.T) {
+	heavy, light := int64(30), int64(10)
+	picker := newCostPickerBuilder().Build()
+	picker.Apply([]selector.Node{
+		newNode(EmptyBuildContext(), "127.0.0.1:8001", config.Protocol_HTTP, &heavy, nil, "", ""),
+		newNode(EmptyBuildContext(), "127.0.0.1:8002", config.Protocol_HTTP, &light, nil, "", ""),
+	})
+	counts := map[string]int{}
+	for i := 0; i < 40; i++ {
+		n, _, err := picker.Select(withRequestCost(context.Background(), 1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		counts[n.Address()]++
+	}
+	// 在途成本按权重分配
+	if counts["127.0.0.1:8001"] != 30 || counts["127.0.0.1:8002"] != 10 {
+		t.Fatalf("want in-flight requests split 30/10 by weight but got %v", counts)
+	}
+}
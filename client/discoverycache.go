@@ -0,0 +1,80 @@
+package client
+
+import (
+	"encoding/json"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/cnsync/kratos/registry"
+)
+
+// discoveryCache 结构体持久化服务的实例列表，dir 为空时不持久化。
+// 网关在注册发现服务不可用时启动，使用持久化的最近一次的实例列表
+type discoveryCache struct {
+	dir string
+}
+
+// path 方法返回服务的实例列表的持久化文件路径
+func (c discoveryCache) path(service string) string {
+	return filepath.Join(c.dir, url.PathEscape(service)+".json")
+}
+
+// save 方法持久化服务的实例列表，先写入临时文件再重命名，避免进程退出时留下不完整的文件
+func (c discoveryCache) save(service string, instances []*registry.ServiceInstance) error {
+	if c.dir == "" || len(instances) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(instances)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), c.path(service))
+}
+
+// load 方法读取持久化的服务实例列表，未开启持久化或没有持久化的实例时返回 nil
+func (c discoveryCache) load(service string) ([]*registry.ServiceInstance, error) {
+	if c.dir == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(c.path(service))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var instances []*registry.ServiceInstance
+	if err := json.Unmarshal(data, &instances); err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+// failover 方法在注册发现服务不可用时读取持久化的实例列表，没有可用的实例时返回 nil
+func (c discoveryCache) failover(service string) []*registry.ServiceInstance {
+	instances, err := c.load(service)
+	if err != nil {
+		LOG.Errorf("Failed to load cached services on endpoint: %s, err: %+v", service, err)
+		return nil
+	}
+	if len(instances) > 0 {
+		LOG.Warnf("Using %d cached services on endpoint: %s, hash: %s, discovery is unavailable", len(instances), service, instancesSetHash(instances))
+	}
+	return instances
+}
@@ -0,0 +1,103 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cnsync/kratos/registry"
+)
+
+// unavailableWatcher 模拟不可用的注册发现服务，获取实例总是失败
+type unavailableWatcher struct{ ctx context.Context }
+
+func (w *unavailableWatcher) Next() ([]*registry.ServiceInstance, error) {
+	select {
+	case <-w.ctx.Done():
+		return nil, w.ctx.Err()
+	case <-time.After(10 * time.Millisecond):
+		return nil, errors.New("discovery is unavailable")
+	}
+}
+
+func (w *unavailableWatcher) Stop() error { return nil }
+
+// unavailableDiscovery 模拟不可用的注册发现服务，failWatch 为 true 时创建监控失败
+type unavailableDiscovery struct{ failWatch bool }
+
+func (d *unavailableDiscovery) GetService(context.Context, string) ([]*registry.ServiceInstance, error) {
+	return nil, errors.New("discovery is unavailable")
+}
+
+func (d *unavailableDiscovery) Watch(ctx context.Context, _ string) (registry.Watcher, error) {
+	if d.failWatch {
+		return nil, errors.New("discovery is unavailable")
+	}
+	return &unavailableWatcher{ctx: ctx}, nil
+}
+
+func TestDiscoveryCacheFailover(t *testing.T) {
+	// 监控协程在测试结束后仍可能运行，因此目录传给服务监控器而不是修改全局变量
+	dir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	receive := func(a *fakeApplier, want int) {
+		t.Helper()
+		select {
+		case got := <-a.instances:
+			if got != want {
+				t.Fatalf("want %d instances but got %d", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("want a callback but got none")
+		}
+	}
+
+	// 注册发现服务可用时持久化实例列表
+	d := &fakeDiscovery{}
+	a := &fakeApplier{instances: make(chan int, 4)}
+	s := newServiceWatcher(dir)
+	s.Add(ctx, d, "cached-svc", a)
+	receive(a, 1)
+	d.watchers[0].next <- []*registry.ServiceInstance{{ID: "1"}, {ID: "2"}}
+	receive(a, 2)
+	var cached []*registry.ServiceInstance
+	for i := 0; i < 100 && len(cached) != 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+		cached, _ = s.cache.load("cached-svc")
+	}
+	if len(cached) != 2 || cached[0].ID != "1" || cached[1].ID != "2" {
+		t.Fatalf("want 2 persisted instances but got %+v", cached)
+	}
+
+	// 重启时注册发现服务不可用，使用持久化的实例列表
+	for _, tt := range []struct {
+		name      string
+		failWatch bool
+	}{
+		{name: "watch", failWatch: true},
+		{name: "next", failWatch: false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			a := &fakeApplier{instances: make(chan int, 4)}
+			s := newServiceWatcher(dir)
+			s.Add(ctx, &unavailableDiscovery{failWatch: tt.failWatch}, "cached-svc", a)
+			receive(a, 2)
+			if !tt.failWatch {
+				if instances, ok := s.getSelectedCache("cached-svc"); !ok || len(instances) != 2 {
+					t.Fatalf("want 2 selected instances but got %d", len(instances))
+				}
+			}
+		})
+	}
+
+	// 没有持久化的实例列表时不回调
+	a = &fakeApplier{instances: make(chan int, 4)}
+	newServiceWatcher(dir).Add(ctx, &unavailableDiscovery{failWatch: true}, "unknown-svc", a)
+	if len(a.instances) != 0 {
+		t.Fatal("want no callback without persisted instances")
+	}
+}
@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"sync/atomic"
 
 	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/secrets"
 
 	"github.com/cnsync/kratos/log"
 	"github.com/cnsync/kratos/registry"
@@ -23,6 +25,8 @@ type BuildContext struct {
 	TLSConfigs map[string]*tls.Config
 	// TLSClientStore 是一个 HTTPS 客户端存储
 	TLSClientStore *HTTPSClientStore
+	// isolatedClients 缓存了隔离连接或配置了出站代理的后端专用的客户端，配置更新前后相同的后端复用同一个客户端
+	isolatedClients sync.Map
 }
 
 // Factory 是一个函数类型，它接受 BuildContext 和 Endpoint 作为参数，并返回一个 Client 和一个 error
@@ -63,8 +67,14 @@ func NewBuildContext(cfg *config.Gateway) *BuildContext {
 			// 设置服务器名称
 			ServerName: v.ServerName,
 		}
+		// 解析以引用形式给出的证书和密钥，每次构建时重新解析以获取最新的值
+		certPEM, keyPEM, cacertPEM, err := resolveTLS(v)
+		if err != nil {
+			LOG.Warnf("failed to resolve tls secrets: %q: %v", k, err)
+			continue
+		}
 		// 将证书和密钥转换为 TLS 证书对象
-		cert, err := tls.X509KeyPair([]byte(v.Cert), []byte(v.Key))
+		cert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
 		// 如果转换失败，记录错误并继续
 		if err != nil {
 			LOG.Warnf("failed to load tls cert: %q: %v", k, err)
@@ -73,11 +83,11 @@ func NewBuildContext(cfg *config.Gateway) *BuildContext {
 		// 将证书添加到 TLS 配置中
 		cfg.Certificates = []tls.Certificate{cert}
 		// 如果存在 CA 证书，将其添加到 TLS 配置中
-		if v.Cacert != "" {
+		if cacertPEM != "" {
 			// 创建一个新的证书池
 			roots := x509.NewCertPool()
 			// 将 CA 证书添加到证书池中
-			if ok := roots.AppendCertsFromPEM([]byte(v.Cacert)); !ok {
+			if ok := roots.AppendCertsFromPEM([]byte(cacertPEM)); !ok {
 				// 如果添加失败，记录错误并继续
 				LOG.Warnf("failed to load tls cacert: %q", k)
 				continue
@@ -112,6 +122,14 @@ func NewFactory(r registry.Discovery, opts ...Option) Factory {
 	return func(builderCtx *BuildContext, endpoint *config.Endpoint) (Client, error) {
 		// 使用选项中的构建器来创建选择器实例
 		picker := o.pickerBuilder.Build()
+		// 端点指定了按成本均衡时使用独立的选择器
+		if endpoint.LoadBalancer.GetPolicy() == config.LoadBalancer_LEAST_COST {
+			picker = newCostPickerBuilder().Build()
+		}
+		// 端点指定了均衡器名称时使用对应的选择器
+		if name := endpoint.LoadBalancer.GetName(); name != "" {
+			picker = resolvePicker(name, o.pickerBuilder)
+		}
 		// 创建一个带有取消功能的上下文
 		ctx, cancel := context.WithCancel(context.Background())
 		// 创建一个节点应用程序实例，用于管理服务实例的选择和应用
@@ -128,7 +146,11 @@ func NewFactory(r registry.Discovery, opts ...Option) Factory {
 			registry: r,
 			// 存储选择器实例
 			picker: picker,
+			// 新加入的节点逐步增加流量，离开的节点逐步减少流量
+			slowStart: newSlowStart(endpoint.LoadBalancer.GetSlowStart()),
 		}
+		// 异常节点检测按当前的节点数量限制同时被摘除的节点
+		applier.outlier = newOutlierDetector(endpoint.OutlierDetection, func() int64 { return atomic.LoadInt64(&applier.nodes) })
 		// 应用节点变更，即注册服务实例并开始选择
 		if err := applier.apply(ctx); err != nil {
 			// 如果应用节点变更失败，返回错误
@@ -141,6 +163,19 @@ func NewFactory(r registry.Discovery, opts ...Option) Factory {
 	}
 }
 
+// resolveTLS 函数解析 TLS 配置中的证书、密钥和 CA 证书，它们可以是内联的值或 scheme://path#field 格式的密钥引用
+func resolveTLS(v *config.TLS) (cert, key, cacert string, err error) {
+	ctx := context.Background()
+	if cert, err = secrets.Resolve(ctx, v.Cert); err != nil {
+		return
+	}
+	if key, err = secrets.Resolve(ctx, v.Key); err != nil {
+		return
+	}
+	cacert, err = secrets.Resolve(ctx, v.Cacert)
+	return
+}
+
 // nodeApplier 结构体定义了一个节点应用程序，用于管理和应用服务实例节点
 type nodeApplier struct {
 	// canceled 是一个原子整数，用于表示节点应用程序是否已被取消
@@ -155,10 +190,68 @@ type nodeApplier struct {
 	registry registry.Discovery
 	// picker 是一个选择器对象，用于选择服务实例节点
 	picker selector.Selector
+	// nodes 是最近一次应用到选择器中的节点数量
+	nodes int64
+	// applied 是最近一次应用到选择器中的节点
+	applied atomic.Value
+	// limiters 是后端的出站速率限制，直接方案的键为目标地址，发现方案的键为服务名称
+	limiters map[string]*tokenBucket
+	// isolated 记录了需要隔离连接的发现方案后端的服务名称
+	isolated map[string]bool
+	// egressProxies 记录了发现方案后端的出站代理，键为服务名称
+	egressProxies map[string]string
+	// slowStart 是发现方案节点的预热和冷却，端点未配置时为 nil
+	slowStart *slowStart
+	// outlier 是异常节点检测器，端点未配置时为 nil
+	outlier *outlierDetector
+	// healthChecks 记录了发现方案后端的健康检查配置，键为服务名称
+	healthChecks map[string]*healthCheckOptions
+	// healthChecker 主动检查发现方案的节点，没有后端配置健康检查时为 nil
+	healthChecker *healthChecker
+	// lock 保护 discovered，使发现的节点和健康状态的变化按顺序应用到选择器中
+	lock sync.Mutex
+	// discovered 是最近一次从注册发现服务中获取的节点
+	discovered []selector.Node
 }
 
 // apply 方法用于应用服务实例节点，它接受一个上下文对象作为参数，并返回一个错误
 func (na *nodeApplier) apply(ctx context.Context) error {
+	// 在添加观察器之前创建所有后端的出站速率限制，观察器可能立即回调
+	na.limiters = make(map[string]*tokenBucket)
+	na.isolated = make(map[string]bool)
+	na.egressProxies = make(map[string]string)
+	na.healthChecks = make(map[string]*healthCheckOptions)
+	for _, backend := range na.endpoint.Backends {
+		target, err := parseTarget(backend.Target)
+		if err != nil {
+			return err
+		}
+		if err := validateEgressProxy(na.endpoint.Protocol, backend); err != nil {
+			return err
+		}
+		if target.Scheme == "discovery" && backend.IsolateConnections {
+			na.isolated[target.Endpoint] = true
+		}
+		if target.Scheme == "discovery" && backend.EgressProxy != "" {
+			na.egressProxies[target.Endpoint] = backend.EgressProxy
+		}
+		if target.Scheme == "discovery" && backend.HealthCheck != nil {
+			na.healthChecks[target.Endpoint] = newHealthCheckOptions(backend.HealthCheck)
+		}
+		limiter := newTokenBucket(backend.RateLimit)
+		if limiter == nil {
+			continue
+		}
+		key := backend.Target
+		if target.Scheme == "discovery" {
+			key = target.Endpoint
+		}
+		na.limiters[key] = limiter
+	}
+	// 健康检查的后台任务随节点应用程序的取消而退出
+	if len(na.healthChecks) > 0 {
+		na.healthChecker = newHealthChecker(ctx, na.reapply)
+	}
 	// 初始化一个节点列表
 	var nodes []selector.Node
 	// 遍历端点配置中的后端列表
@@ -175,11 +268,14 @@ func (na *nodeApplier) apply(ctx context.Context) error {
 			// 对于直接方案，获取后端的权重值
 			weighted := backend.Weight // weight is only valid for direct scheme
 			// 创建一个新的节点对象，包含构建上下文、目标地址、协议、权重、元数据等信息
-			node := newNode(na.buildContext, backend.Target, na.endpoint.Protocol, weighted, backend.Metadata, "", "", WithTLS(backend.Tls), WithTLSConfigName(backend.TlsConfigName))
+			node := newNode(na.buildContext, backend.Target, na.endpoint.Protocol, weighted, backend.Metadata, "", "", WithTLS(backend.Tls), WithTLSConfigName(backend.TlsConfigName), WithRateLimiter(na.limiters[backend.Target]), WithIsolatedConnections(backend.IsolateConnections), WithEgressProxy(backend.EgressProxy), WithTLSRenegotiation(backend.AllowTlsRenegotiation))
 			// 将新节点添加到节点列表中
 			nodes = append(nodes, node)
 			// 将节点列表应用到选择器中
 			na.picker.Apply(nodes)
+			// 记录当前节点数量和节点
+			atomic.StoreInt64(&na.nodes, int64(len(nodes)))
+			na.applied.Store(nodes)
 		case "discovery":
 			// 对于发现方案，添加一个观察器，用于监视目标端点的服务实例变化
 			existed := AddWatch(ctx, na.registry, target.Endpoint, na)
@@ -236,16 +332,50 @@ func (na *nodeApplier) Callback(services []*registry.ServiceInstance) error {
 			continue
 		}
 		// 创建一个新的节点对象，包含构建上下文、地址、协议、权重、元数据、版本和名称等信息
-		node := newNode(na.buildContext, addr, na.endpoint.Protocol, nodeWeight(ser), ser.Metadata, ser.Version, ser.Name, WithTLS(false))
+		node := newNode(na.buildContext, addr, na.endpoint.Protocol, nodeWeight(ser), ser.Metadata, ser.Version, ser.Name, WithTLS(false), WithRateLimiter(na.limiters[ser.Name]), WithIsolatedConnections(na.isolated[ser.Name]), WithEgressProxy(na.egressProxies[ser.Name]))
 		// 将新节点添加到节点列表中
 		nodes = append(nodes, node)
 	}
-	// 将节点列表应用到选择器中
-	na.picker.Apply(nodes)
+	na.lock.Lock()
+	defer na.lock.Unlock()
+	na.discovered = nodes
+	// 开始检查新发现的节点，停止检查不再存在的节点
+	if na.healthChecker != nil {
+		na.healthChecker.update(nodes, na.healthChecks)
+	}
+	na.applyDiscovered()
 	// 返回 nil，表示回调成功
 	return nil
 }
 
+// applyDiscovered 方法将最近一次发现的节点应用到选择器中，调用方需要持有锁
+func (na *nodeApplier) applyDiscovered() {
+	nodes := na.discovered
+	// 配置了健康检查时摘除不健康的节点
+	if na.healthChecker != nil {
+		nodes = na.healthChecker.filter(nodes)
+	}
+	// 将节点列表应用到选择器中，配置了预热和冷却时包括仍在冷却的节点
+	if na.slowStart != nil {
+		na.picker.Apply(na.slowStart.update(nodes))
+	} else {
+		na.picker.Apply(nodes)
+	}
+	// 记录当前节点数量和节点
+	atomic.StoreInt64(&na.nodes, int64(len(nodes)))
+	na.applied.Store(nodes)
+}
+
+// reapply 方法在节点的健康状态变化时重新应用最近一次发现的节点
+func (na *nodeApplier) reapply() {
+	if na.Canceled() {
+		return
+	}
+	na.lock.Lock()
+	defer na.lock.Unlock()
+	na.applyDiscovered()
+}
+
 // Cancel 方法用于取消节点应用程序，它会设置取消状态，并调用上下文的取消函数
 func (na *nodeApplier) Cancel() {
 	log.Infof("Closing node applier for endpoint: %+v", na.endpoint)
@@ -1 +1,76 @@
 package client
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/secrets"
+)
+
+// newTestCert 函数生成一个自签名的证书和私钥
+func newTestCert(t *testing.T, cn string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}))
+}
+
+func TestNewBuildContextSecrets(t *testing.T) {
+	store := map[string]string{}
+	secrets.Register("stub", secrets.ProviderFunc(func(_ context.Context, path, field string) (string, error) {
+		return store[path+"#"+field], nil
+	}))
+	defer secrets.Deregister("stub")
+
+	cfg := &config.Gateway{
+		TlsStore: map[string]*config.TLS{
+			"upstream": {Cert: "stub://gateway/tls#cert", Key: "stub://gateway/tls#key"},
+		},
+	}
+	leaf := func(bc *BuildContext) string {
+		tlsConfig, ok := bc.TLSConfigs["upstream"]
+		if !ok {
+			t.Fatal("tls config not found")
+		}
+		cert, err := x509.ParseCertificate(tlsConfig.Certificates[0].Certificate[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cert.Subject.CommonName
+	}
+
+	store["gateway/tls#cert"], store["gateway/tls#key"] = newTestCert(t, "first")
+	if got := leaf(NewBuildContext(cfg)); got != "first" {
+		t.Fatalf("want cert %q but got %q", "first", got)
+	}
+	// 密钥轮换后，重新构建时应使用新的证书
+	store["gateway/tls#cert"], store["gateway/tls#key"] = newTestCert(t, "second")
+	if got := leaf(NewBuildContext(cfg)); got != "second" {
+		t.Fatalf("want cert %q but got %q", "second", got)
+	}
+}
@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/kratos/selector"
+)
+
+const (
+	// _defaultGroupHeader 是默认携带后端分组名称的请求头
+	_defaultGroupHeader = "X-Route-Weight-Group"
+	// _defaultGroupMetadataKey 是默认记录后端分组名称的元数据键
+	_defaultGroupMetadataKey = "group"
+)
+
+// backendGroupFilter 函数根据请求头中的分组名称创建一个节点过滤器，请求未携带分组时返回 nil
+func backendGroupFilter(req *http.Request, routing *config.BackendGroupRouting) selector.NodeFilter {
+	if routing == nil {
+		return nil
+	}
+	header := routing.Header
+	if header == "" {
+		header = _defaultGroupHeader
+	}
+	group := req.Header.Get(header)
+	if group == "" {
+		return nil
+	}
+	return groupFilter(groupMetadataKey(routing), group)
+}
+
+// groupMetadataKey 函数返回记录后端分组名称的元数据键
+func groupMetadataKey(routing *config.BackendGroupRouting) string {
+	if routing.MetadataKey == "" {
+		return _defaultGroupMetadataKey
+	}
+	return routing.MetadataKey
+}
+
+// groupFilter 函数创建一个只选择指定分组内节点的过滤器
+func groupFilter(key, group string) selector.NodeFilter {
+	return func(_ context.Context, nodes []selector.Node) []selector.Node {
+		selected := make([]selector.Node, 0, len(nodes))
+		for _, n := range nodes {
+			if n.Metadata()[key] == group {
+				selected = append(selected, n)
+			}
+		}
+		// 分组中没有可用的节点时按正常方式选择
+		if len(selected) == 0 {
+			return nodes
+		}
+		return selected
+	}
+}
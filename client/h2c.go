@@ -0,0 +1,175 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/middleware"
+	"github.com/cnsync/kratos/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/http2"
+)
+
+// ErrHTTP2Unsupported 表示 gRPC 端点的上游只支持 HTTP/1.1，无法协商 HTTP/2
+var ErrHTTP2Unsupported = errors.New("upstream does not support HTTP/2 which is required by gRPC, check whether the backend serves h2c")
+
+// grpcHTTP1Fallback 表示 gRPC 上游不支持 HTTP/2 时是否降级为 HTTP/1.1 转发，从环境变量 PROXY_GRPC_HTTP1_FALLBACK 中读取
+var grpcHTTP1Fallback bool
+
+// _metricHTTP2Unsupported 统计了 gRPC 端点的上游不支持 HTTP/2 的次数
+var _metricHTTP2Unsupported = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "client_http2_unsupported_total",
+	Help:      "The total number of gRPC requests sent to upstreams which do not support HTTP/2",
+}, []string{"protocol", "method", "path", "service", "basePath", "backend"})
+
+func init() {
+	var err error
+	if v := os.Getenv("PROXY_GRPC_HTTP1_FALLBACK"); v != "" {
+		if grpcHTTP1Fallback, err = strconv.ParseBool(v); err != nil {
+			panic(err)
+		}
+	}
+	prometheus.MustRegister(_metricHTTP2Unsupported)
+}
+
+// _http1Prefix 是 HTTP/1.x 响应的起始字节，HTTP/2 服务端发送的第一个帧不可能以此开头
+var _http1Prefix = []byte("HTTP/1.")
+
+// _clientPreface 是 HTTP/2 的客户端连接前言
+var _clientPreface = []byte(http2.ClientPreface)
+
+// dialH2C 函数建立到上游的明文连接，并在交给 HTTP/2 传输之前探测上游是否支持 HTTP/2，
+// 上游以 HTTP/1.x 响应连接前言时返回 ErrHTTP2Unsupported，避免依赖 HTTP/2 传输读取连接的时机
+func dialH2C(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+	conn, err := (&net.Dialer{Timeout: _dialTimeout}).DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+	c, err := probeH2C(ctx, conn)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// probeH2C 函数发送 HTTP/2 连接前言并读取上游响应的第一段数据，
+// 返回的连接先返回已读取的数据，并跳过 HTTP/2 传输再次发送的连接前言
+func probeH2C(ctx context.Context, conn net.Conn) (net.Conn, error) {
+	deadline := time.Now().Add(_dialTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	if _, err := conn.Write(_clientPreface); err != nil {
+		return nil, err
+	}
+	// HTTP/2 服务端收到连接前言后发送 SETTINGS 帧，帧头不短于 HTTP/1.x 响应的起始字节
+	buf := make([]byte, 512)
+	n, err := io.ReadAtLeast(conn, buf, len(_http1Prefix))
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(buf[:n], _http1Prefix) {
+		return nil, ErrHTTP2Unsupported
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return nil, err
+	}
+	return &h2cConn{Conn: conn, pending: buf[:n], skip: len(_clientPreface)}, nil
+}
+
+// h2cConn 结构体是已经探测过的 HTTP/2 明文连接，连接前言已经发送，上游响应的第一段数据已经读取
+type h2cConn struct {
+	net.Conn
+	// pending 是探测时读取的、尚未交给 HTTP/2 传输的数据，只有 HTTP/2 传输的读取协程会读取连接
+	pending []byte
+	// skip 是 HTTP/2 传输写入的数据中需要跳过的连接前言的长度，只有 HTTP/2 传输的写入协程会写入连接
+	skip int
+}
+
+// Read 方法先返回探测时读取的数据，再读取连接中的数据
+func (c *h2cConn) Read(p []byte) (int, error) {
+	if len(c.pending) > 0 {
+		n := copy(p, c.pending)
+		c.pending = c.pending[n:]
+		return n, nil
+	}
+	return c.Conn.Read(p)
+}
+
+// Write 方法跳过已经发送过的连接前言，写入其余的数据
+func (c *h2cConn) Write(p []byte) (int, error) {
+	if c.skip == 0 {
+		return c.Conn.Write(p)
+	}
+	n := c.skip
+	if n > len(p) {
+		n = len(p)
+	}
+	c.skip -= n
+	if n == len(p) {
+		return n, nil
+	}
+	written, err := c.Conn.Write(p[n:])
+	return n + written, err
+}
+
+// do 方法使用节点的客户端发送请求，gRPC 上游不支持 HTTP/2 时返回明确的错误，或者按配置降级为 HTTP/1.1，
+// 拨号时探测到的结果记录在节点上，之后发往该节点的请求不再尝试 HTTP/2
+func (n *node) do(req *http.Request) (*http.Response, error) {
+	if n.protocol != config.Protocol_GRPC || n.tls {
+		return n.client.Do(req)
+	}
+	if n.http1.Load() {
+		return n.doHTTP1(req)
+	}
+	resp, err := n.client.Do(req)
+	if err == nil || !errors.Is(err, ErrHTTP2Unsupported) {
+		return resp, err
+	}
+	if !n.http1.Swap(true) {
+		if grpcHTTP1Fallback {
+			log.Warnf("gRPC backend %s does not support HTTP/2, falling back to HTTP/1.1", n.address)
+		} else {
+			log.Errorf("gRPC backend %s does not support HTTP/2, set PROXY_GRPC_HTTP1_FALLBACK to forward with HTTP/1.1", n.address)
+		}
+	}
+	return n.doHTTP1(req)
+}
+
+// doHTTP1 方法处理发往只支持 HTTP/1.1 的 gRPC 上游的请求，未开启降级时返回 ErrHTTP2Unsupported
+func (n *node) doHTTP1(req *http.Request) (*http.Response, error) {
+	if labels, ok := middleware.MetricsLabelsFromContext(req.Context()); ok {
+		_metricHTTP2Unsupported.WithLabelValues(labels.Protocol(), labels.Method(), labels.Path(), labels.Service(), labels.BasePath(), n.address).Inc()
+	}
+	if !grpcHTTP1Fallback {
+		return nil, fmt.Errorf("%s: %w", n.address, ErrHTTP2Unsupported)
+	}
+	// 请求体可能已被 HTTP/2 传输关闭，无法重放时只能返回错误
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return nil, fmt.Errorf("%s: %w", n.address, ErrHTTP2Unsupported)
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = body
+	}
+	return _globalClient.Do(req)
+}
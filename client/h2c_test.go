@@ -0,0 +1,121 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/middleware"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// newGRPCClient 函数创建一个直连 addr 的 gRPC 端点客户端
+func newGRPCClient(t *testing.T, addr string) (Client, *config.Endpoint) {
+	endpoint := &config.Endpoint{
+		Path:     "/helloworld.Greeter/SayHello",
+		Protocol: config.Protocol_GRPC,
+		Backends: []*config.Backend{{Target: addr}},
+	}
+	c, err := NewFactory(nil)(EmptyBuildContext(), endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c, endpoint
+}
+
+// newGRPCRequest 函数创建一个带有请求选项的 gRPC 请求
+func newGRPCRequest(endpoint *config.Endpoint, body string) *http.Request {
+	req := httptest.NewRequest("POST", "/helloworld.Greeter/SayHello", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/grpc")
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(body)), nil
+	}
+	ctx := middleware.NewRequestContext(context.Background(), middleware.NewRequestOptions(endpoint))
+	return req.WithContext(ctx)
+}
+
+func TestGRPCHTTP1Backend(t *testing.T) {
+	// httptest.Server 只支持 HTTP/1.1
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c, endpoint := newGRPCClient(t, strings.TrimPrefix(srv.URL, "http://"))
+	// 拨号时探测上游，结果记录在节点上，之后的请求直接返回错误
+	for i := 0; i < 3; i++ {
+		_, err := c.RoundTrip(newGRPCRequest(endpoint, "hello"))
+		if !errors.Is(err, ErrHTTP2Unsupported) {
+			t.Fatalf("want ErrHTTP2Unsupported but got: %v", err)
+		}
+	}
+}
+
+func TestGRPCH2CBackend(t *testing.T) {
+	srv := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ProtoMajor != 2 {
+			t.Errorf("want HTTP/2 request but got %s", r.Proto)
+		}
+		b, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/grpc")
+		_, _ = w.Write(b)
+	}), &http2.Server{}))
+	defer srv.Close()
+
+	// 探测时发送的连接前言不会被 HTTP/2 传输重复发送，探测时读取的 SETTINGS 帧交给 HTTP/2 传输
+	c, endpoint := newGRPCClient(t, strings.TrimPrefix(srv.URL, "http://"))
+	for i := 0; i < 2; i++ {
+		resp, err := c.RoundTrip(newGRPCRequest(endpoint, "hello"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.ProtoMajor != 2 || !bytes.Equal(b, []byte("hello")) {
+			t.Fatalf("want the HTTP/2 response echoed but got %s %q", resp.Proto, b)
+		}
+	}
+}
+
+func TestGRPCHTTP1Fallback(t *testing.T) {
+	old := grpcHTTP1Fallback
+	grpcHTTP1Fallback = true
+	defer func() { grpcHTTP1Fallback = old }()
+
+	var http1 int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		// HTTP/2 连接前言也会被当作一个请求交给处理程序
+		if r.ProtoMajor == 1 {
+			http1++
+		}
+		w.Header().Set("Content-Type", "application/grpc")
+		_, _ = w.Write(b)
+	}))
+	defer srv.Close()
+
+	c, endpoint := newGRPCClient(t, strings.TrimPrefix(srv.URL, "http://"))
+	for i := 0; i < 2; i++ {
+		resp, err := c.RoundTrip(newGRPCRequest(endpoint, "hello"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if !bytes.Equal(b, []byte("hello")) {
+			t.Fatalf("want the request body replayed but got %q", b)
+		}
+	}
+	// 第一次请求检测到后直接降级，之后的请求不再尝试 HTTP/2
+	if http1 != 2 {
+		t.Fatalf("want two HTTP/1.1 requests but got %d", http1)
+	}
+}
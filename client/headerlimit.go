@@ -0,0 +1,58 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+)
+
+// ErrUpstreamHeadersTooLarge 表示转发给上游的请求头超过了端点的限制
+var ErrUpstreamHeadersTooLarge = errors.New("upstream request headers too large")
+
+// headerSize 函数返回请求头按 HTTP/1.1 编码后的大小，包括 Host 请求头
+func headerSize(req *http.Request) int64 {
+	size := int64(len("Host: \r\n") + len(req.Host))
+	for name, values := range req.Header {
+		for _, v := range values {
+			size += int64(len(name) + len(": \r\n") + len(v))
+		}
+	}
+	return size
+}
+
+// enforceHeaderLimit 函数检查转发给上游的请求头大小，超过限制时按顺序删除可以删除的请求头，
+// 仍然超过限制时返回 ErrUpstreamHeadersTooLarge
+func enforceHeaderLimit(req *http.Request, limit *config.UpstreamHeaderLimit) error {
+	maxBytes := limit.GetMaxBytes()
+	if maxBytes <= 0 {
+		return nil
+	}
+	size := headerSize(req)
+	if size <= maxBytes {
+		return nil
+	}
+	for _, name := range limit.TrimmableHeaders {
+		if _, ok := req.Header[http.CanonicalHeaderKey(name)]; !ok {
+			continue
+		}
+		req.Header.Del(name)
+		if size = headerSize(req); size <= maxBytes {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %d bytes exceeds the limit of %d bytes", ErrUpstreamHeadersTooLarge, size, maxBytes)
+}
+
+// checkHeaderLimit 函数检查转发给上游的请求头大小，超过限制时返回 ErrUpstreamHeadersTooLarge，不删除任何请求头
+func checkHeaderLimit(req *http.Request, limit *config.UpstreamHeaderLimit) error {
+	maxBytes := limit.GetMaxBytes()
+	if maxBytes <= 0 {
+		return nil
+	}
+	if size := headerSize(req); size > maxBytes {
+		return fmt.Errorf("%w: %d bytes exceeds the limit of %d bytes", ErrUpstreamHeadersTooLarge, size, maxBytes)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/middleware"
+	"github.com/cnsync/kratos/selector"
+)
+
+func TestUpstreamHeaderLimit(t *testing.T) {
+	var received http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+	}))
+	defer srv.Close()
+	endpoint := &config.Endpoint{
+		Path:     "/api/headers",
+		Protocol: config.Protocol_HTTP,
+		UpstreamHeaderLimit: &config.UpstreamHeaderLimit{
+			MaxBytes:         256,
+			TrimmableHeaders: []string{"x-b3-sampled", "X-Client-Info"},
+		},
+		Backends: []*config.Backend{{Target: strings.TrimPrefix(srv.URL, "http://")}},
+	}
+	c, err := NewFactory(nil)(EmptyBuildContext(), endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	// send 发送一个请求，injected 模拟中间件注入的请求头，transformed 模拟节点变换在选择节点之后添加的请求头，例如签名
+	send := func(injected, transformed int) error {
+		received = nil
+		req := httptest.NewRequest("GET", "/api/headers", nil)
+		req.Header.Set("X-Client-Info", strings.Repeat("u", 37))
+		req.Header.Set("X-B3-Sampled", "1")
+		req.Header.Set("X-Injected", strings.Repeat("i", injected))
+		reqOpts := middleware.NewRequestOptions(endpoint)
+		ctx := middleware.NewRequestContext(context.Background(), reqOpts)
+		ctx = middleware.WithNodeTransform(ctx, func(req *http.Request, _ selector.Node) error {
+			if transformed > 0 {
+				req.Header.Set("X-Signature", strings.Repeat("s", transformed))
+			}
+			return nil
+		})
+		resp, err := c.RoundTrip(req.WithContext(ctx))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		reqOpts.DoneFunc(ctx, selector.DoneInfo{})
+		return nil
+	}
+
+	// 未超过限制时原样转发
+	if err := send(10, 0); err != nil {
+		t.Fatal(err)
+	}
+	if received.Get("X-B3-Sampled") == "" || received.Get("X-Client-Info") == "" {
+		t.Fatalf("want all headers forwarded but got %v", received)
+	}
+	// 注入的请求头超过限制时按顺序删除可以删除的请求头
+	if err := send(160, 0); err != nil {
+		t.Fatal(err)
+	}
+	if received.Get("X-B3-Sampled") != "" || received.Get("X-Client-Info") == "" {
+		t.Fatalf("want only X-B3-Sampled trimmed but got %v", received)
+	}
+	if err := send(180, 0); err != nil {
+		t.Fatal(err)
+	}
+	if received.Get("X-Client-Info") != "" || received.Get("X-Injected") == "" {
+		t.Fatalf("want X-Client-Info trimmed but got %v", received)
+	}
+	// 删除后仍然超过限制时请求失败，不发往后端
+	err = send(256, 0)
+	if !errors.Is(err, ErrUpstreamHeadersTooLarge) {
+		t.Fatalf("want ErrUpstreamHeadersTooLarge but got %v", err)
+	}
+	if received != nil {
+		t.Fatalf("want no request sent to the backend but got %v", received)
+	}
+	// 节点变换之后不再删除请求头，避免删除已签名的请求头，超过限制时请求失败
+	err = send(10, 160)
+	if !errors.Is(err, ErrUpstreamHeadersTooLarge) {
+		t.Fatalf("want ErrUpstreamHeadersTooLarge but got %v", err)
+	}
+	if received != nil {
+		t.Fatalf("want no request sent to the backend but got %v", received)
+	}
+}
@@ -0,0 +1,210 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/kratos/log"
+	"github.com/cnsync/kratos/selector"
+)
+
+const (
+	// _defaultHealthCheckPath 是默认健康检查请求的路径
+	_defaultHealthCheckPath = "/health"
+	// _defaultHealthCheckInterval 是默认健康检查的间隔
+	_defaultHealthCheckInterval = 10 * time.Second
+	// _defaultHealthCheckTimeout 是默认健康检查请求的超时时间
+	_defaultHealthCheckTimeout = 2 * time.Second
+	// _defaultUnhealthyThreshold 是默认摘除节点之前连续失败的检查次数
+	_defaultUnhealthyThreshold = 3
+	// _defaultHealthyThreshold 是默认恢复节点之前连续成功的检查次数
+	_defaultHealthyThreshold = 2
+)
+
+// healthCheckOptions 结构体是一个发现方案后端的健康检查配置
+type healthCheckOptions struct {
+	path               string
+	expectedStatus     int
+	interval           time.Duration
+	timeout            time.Duration
+	unhealthyThreshold int
+	healthyThreshold   int
+}
+
+// newHealthCheckOptions 函数根据后端的健康检查配置创建健康检查选项，未配置的值使用默认值
+func newHealthCheckOptions(c *config.HealthCheck) *healthCheckOptions {
+	o := &healthCheckOptions{
+		path:               _defaultHealthCheckPath,
+		expectedStatus:     http.StatusOK,
+		interval:           _defaultHealthCheckInterval,
+		timeout:            _defaultHealthCheckTimeout,
+		unhealthyThreshold: _defaultUnhealthyThreshold,
+		healthyThreshold:   _defaultHealthyThreshold,
+	}
+	if c.Path != "" {
+		o.path = c.Path
+	}
+	if c.ExpectedStatus > 0 {
+		o.expectedStatus = int(c.ExpectedStatus)
+	}
+	if c.Interval != nil && c.Interval.AsDuration() > 0 {
+		o.interval = c.Interval.AsDuration()
+	}
+	if c.Timeout != nil && c.Timeout.AsDuration() > 0 {
+		o.timeout = c.Timeout.AsDuration()
+	}
+	if c.UnhealthyThreshold > 0 {
+		o.unhealthyThreshold = int(c.UnhealthyThreshold)
+	}
+	if c.HealthyThreshold > 0 {
+		o.healthyThreshold = int(c.HealthyThreshold)
+	}
+	return o
+}
+
+// probeNode 函数向节点发送一次健康检查请求，响应状态码不是期望的状态码时返回错误
+func probeNode(ctx context.Context, n *node, o *healthCheckOptions) error {
+	ctx, cancel := context.WithTimeout(ctx, o.timeout)
+	defer cancel()
+	scheme := "http"
+	if n.tls {
+		scheme = "https"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, scheme+"://"+n.address+o.path, nil)
+	if err != nil {
+		return err
+	}
+	if host := n.metadata["host"]; host != "" {
+		req.Host = host
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != o.expectedStatus {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// nodeHealth 结构体记录了一个节点的健康状态和连续的检查结果
+type nodeHealth struct {
+	healthy   bool
+	successes int
+	failures  int
+	cancel    context.CancelFunc
+}
+
+// healthChecker 结构体为每个配置了健康检查的节点启动一个后台任务定期检查节点，
+// 节点的健康状态变化时调用 onChange 重新应用节点，上下文取消时所有后台任务退出
+type healthChecker struct {
+	lock     sync.Mutex
+	ctx      context.Context
+	states   map[string]*nodeHealth
+	onChange func()
+	probe    func(context.Context, *node, *healthCheckOptions) error
+}
+
+// newHealthChecker 函数创建一个健康检查器，后台任务随上下文的取消而退出
+func newHealthChecker(ctx context.Context, onChange func()) *healthChecker {
+	return &healthChecker{
+		ctx:      ctx,
+		states:   make(map[string]*nodeHealth),
+		onChange: onChange,
+		probe:    probeNode,
+	}
+}
+
+// update 方法开始检查新发现的节点，停止检查不再存在的节点，options 的键为服务名称，
+// 没有健康检查配置的节点不做检查
+func (h *healthChecker) update(nodes []selector.Node, options map[string]*healthCheckOptions) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	current := make(map[string]struct{}, len(nodes))
+	for _, sn := range nodes {
+		n := sn.(*node)
+		o, ok := options[n.name]
+		if !ok {
+			continue
+		}
+		current[n.address] = struct{}{}
+		if _, ok := h.states[n.address]; ok {
+			continue
+		}
+		ctx, cancel := context.WithCancel(h.ctx)
+		s := &nodeHealth{healthy: true, cancel: cancel}
+		h.states[n.address] = s
+		go h.run(ctx, n, o, s)
+	}
+	for addr, s := range h.states {
+		if _, ok := current[addr]; !ok {
+			s.cancel()
+			delete(h.states, addr)
+		}
+	}
+}
+
+// filter 方法返回健康的节点，所有节点都不健康时返回所有节点
+func (h *healthChecker) filter(nodes []selector.Node) []selector.Node {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	healthy := make([]selector.Node, 0, len(nodes))
+	for _, n := range nodes {
+		if s, ok := h.states[n.Address()]; !ok || s.healthy {
+			healthy = append(healthy, n)
+		}
+	}
+	if len(healthy) == 0 {
+		return nodes
+	}
+	return healthy
+}
+
+// run 方法按间隔检查节点，直到节点被移除或健康检查器停止
+func (h *healthChecker) run(ctx context.Context, n *node, o *healthCheckOptions, s *nodeHealth) {
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		err := h.probe(ctx, n, o)
+		if ctx.Err() != nil {
+			return
+		}
+		if h.record(n, o, s, err) {
+			h.onChange()
+		}
+	}
+}
+
+// record 方法记录一次检查的结果，连续的结果达到阈值时改变节点的健康状态，返回健康状态是否变化
+func (h *healthChecker) record(n *node, o *healthCheckOptions, s *nodeHealth, err error) bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if err == nil {
+		s.failures = 0
+		s.successes++
+		if s.healthy || s.successes < o.healthyThreshold {
+			return false
+		}
+		s.healthy = true
+		log.Infof("Node %s of service %s passed %d health checks, restoring it", n.address, n.name, s.successes)
+		return true
+	}
+	s.successes = 0
+	s.failures++
+	if !s.healthy || s.failures < o.unhealthyThreshold {
+		return false
+	}
+	s.healthy = false
+	log.Warnf("Node %s of service %s failed %d health checks, ejecting it: %v", n.address, n.name, s.failures, err)
+	return true
+}
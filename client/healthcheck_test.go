@@ -0,0 +1,119 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/kratos/registry"
+	"github.com/cnsync/kratos/selector"
+	"github.com/cnsync/kratos/selector/p2c"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestHealthCheck(t *testing.T) {
+	var probes atomic.Int32
+	var failing atomic.Bool
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer healthy.Close()
+	flapping := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		probes.Add(1)
+		if r.URL.Path != "/ready" || failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer flapping.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	na := &nodeApplier{
+		buildContext: EmptyBuildContext(),
+		cancel:       cancel,
+		endpoint:     &config.Endpoint{Protocol: config.Protocol_HTTP},
+		picker:       p2c.NewBuilder().Build(),
+		healthChecks: map[string]*healthCheckOptions{
+			"health": newHealthCheckOptions(&config.HealthCheck{
+				Path:               "/ready",
+				Interval:           durationpb.New(10 * time.Millisecond),
+				UnhealthyThreshold: 3,
+				HealthyThreshold:   2,
+			}),
+		},
+	}
+	na.healthChecker = newHealthChecker(ctx, na.reapply)
+	if err := na.Callback([]*registry.ServiceInstance{
+		{Name: "health", Endpoints: []string{healthy.URL}},
+		{Name: "health", Endpoints: []string{flapping.URL}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	flappingAddr := strings.TrimPrefix(flapping.URL, "http://")
+	applied := func() bool {
+		nodes, _ := na.applied.Load().([]selector.Node)
+		for _, n := range nodes {
+			if n.Address() == flappingAddr {
+				return true
+			}
+		}
+		return false
+	}
+	waitFor := func(want bool) {
+		deadline := time.Now().Add(2 * time.Second)
+		for applied() != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("want the flapping node applied: %v", want)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	// 新发现的节点在检查失败之前是健康的
+	if !applied() {
+		t.Fatal("want the new node applied")
+	}
+	// 连续失败达到阈值之后摘除节点
+	failing.Store(true)
+	waitFor(false)
+	if n := probes.Load(); n < 3 {
+		t.Fatalf("want the node ejected after 3 failed probes but got %d probes", n)
+	}
+	// 连续成功达到阈值之后恢复节点
+	failing.Store(false)
+	waitFor(true)
+
+	// 节点应用程序取消之后停止检查
+	na.Cancel()
+	time.Sleep(30 * time.Millisecond)
+	stopped := probes.Load()
+	time.Sleep(50 * time.Millisecond)
+	if n := probes.Load(); n != stopped {
+		t.Fatalf("want the probes stopped but got %d more", n-stopped)
+	}
+}
+
+func TestHealthCheckDebounce(t *testing.T) {
+	h := newHealthChecker(context.Background(), func() {})
+	o := newHealthCheckOptions(&config.HealthCheck{})
+	n := &node{address: "127.0.0.1:8000", name: "svc"}
+	s := &nodeHealth{healthy: true}
+	h.states[n.address] = s
+	// 单次失败不会摘除节点
+	for _, err := range []error{context.DeadlineExceeded, nil, context.DeadlineExceeded, context.DeadlineExceeded} {
+		if h.record(n, o, s, err) {
+			t.Fatal("want the node kept before 3 consecutive failures")
+		}
+	}
+	if !h.record(n, o, s, context.DeadlineExceeded) || s.healthy {
+		t.Fatal("want the node ejected after 3 consecutive failures")
+	}
+	// 所有节点都不健康时不摘除任何节点
+	if got := h.filter([]selector.Node{n}); len(got) != 1 {
+		t.Fatalf("want every node kept when none is healthy but got %d", len(got))
+	}
+	if h.record(n, o, s, nil) || !h.record(n, o, s, nil) || !s.healthy {
+		t.Fatal("want the node restored after 2 consecutive successes")
+	}
+}
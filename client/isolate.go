@@ -0,0 +1,108 @@
+package client
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+)
+
+// _egressProxyDirect 表示后端不使用任何出站代理，包括环境变量中配置的代理
+const _egressProxyDirect = "direct"
+
+// isolationKey 是专用客户端的缓存键，相同的键复用同一个客户端
+type isolationKey struct {
+	protocol      config.Protocol
+	tls           bool
+	tlsConfig     string
+	address       string
+	serverName    string
+	egressProxy   string
+	renegotiation bool
+}
+
+// parseEgressProxy 函数解析后端的出站代理，返回 nil 表示直接连接
+func parseEgressProxy(egressProxy string) (*url.URL, error) {
+	if egressProxy == _egressProxyDirect {
+		return nil, nil
+	}
+	u, err := url.Parse(egressProxy)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid egress proxy: %q", egressProxy)
+	}
+	return u, nil
+}
+
+// validateEgressProxy 函数检查后端的出站代理配置，h2c 的 gRPC 后端不支持出站代理
+func validateEgressProxy(protocol config.Protocol, backend *config.Backend) error {
+	if backend.EgressProxy == "" {
+		return nil
+	}
+	if protocol == config.Protocol_GRPC && !backend.Tls && backend.EgressProxy != _egressProxyDirect {
+		return fmt.Errorf("egress proxy is not supported by the h2c backend: %s", backend.Target)
+	}
+	_, err := parseEgressProxy(backend.EgressProxy)
+	return err
+}
+
+// isolatedClient 方法返回节点专用的客户端。隔离连接的节点使用独立的连接池，
+// 启用 TLS 时使用节点元数据中的 host 作为 TLS 服务器名称；配置了出站代理的节点使用经过该代理的客户端；
+// 允许 TLS 重新协商的节点使用只支持 HTTP/1.1 的客户端。配置相同且不隔离连接的节点共享同一个客户端
+func (ctx *BuildContext) isolatedClient(n *node, opt *NodeOptions) *http.Client {
+	key := isolationKey{
+		protocol:      n.protocol,
+		tls:           n.tls,
+		tlsConfig:     opt.TLSConfigName,
+		egressProxy:   opt.EgressProxy,
+		renegotiation: n.renegotiation,
+	}
+	if opt.IsolateConnections {
+		key.address = n.address
+		if n.tls {
+			key.serverName = n.metadata["host"]
+			if key.serverName == "" {
+				key.serverName, _, _ = net.SplitHostPort(n.address)
+			}
+		}
+	}
+	if c, ok := ctx.isolatedClients.Load(key); ok {
+		return c.(*http.Client)
+	}
+	var c *http.Client
+	switch {
+	case n.tls:
+		tlsConfig := &tls.Config{}
+		if base, ok := ctx.TLSConfigs[opt.TLSConfigName]; ok {
+			tlsConfig = base.Clone()
+		}
+		if tlsConfig.ServerName == "" {
+			tlsConfig.ServerName = key.serverName
+		}
+		if key.renegotiation {
+			tlsConfig.Renegotiation = tls.RenegotiateOnceAsClient
+		}
+		c = createHTTPSClient(tlsConfig)
+	case n.protocol == config.Protocol_GRPC:
+		c = defaultH2CClient()
+	default:
+		c = defaultClient()
+	}
+	if key.egressProxy != "" {
+		if tr, ok := c.Transport.(*http.Transport); ok {
+			// 出站代理已在应用节点之前校验
+			proxy, _ := parseEgressProxy(key.egressProxy)
+			tr.Proxy = nil
+			if proxy != nil {
+				tr.Proxy = http.ProxyURL(proxy)
+			}
+		}
+	}
+	actual, _ := ctx.isolatedClients.LoadOrStore(key, c)
+	return actual.(*http.Client)
+}
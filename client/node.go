@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/cnsync/kratos/selector"
@@ -125,11 +126,8 @@ func defaultH2CClient() *http.Client {
 			AllowHTTP: true,
 			// 禁用压缩
 			DisableCompression: true,
-			// 自定义的 DialTLS 函数，用于处理非 TLS 连接
-			DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
-				// 忽略传入的 TLS 配置，直接使用网络和地址进行拨号
-				return net.DialTimeout(network, addr, _dialTimeout)
-			},
+			// 自定义的拨号函数，建立非 TLS 连接，并探测上游是否以 HTTP/1.x 响应，以便给出明确的错误
+			DialTLSContext: dialH2C,
 		},
 	}
 }
@@ -164,8 +162,10 @@ func createHTTPSClient(tlsConfig *tls.Config) *http.Client {
 		// 设置预期继续超时时间
 		ExpectContinueTimeout: 1 * time.Second,
 	}
-	// 配置 HTTP/2 传输
-	_ = http2.ConfigureTransport(tr)
+	// 配置 HTTP/2 传输，HTTP/2 禁止 TLS 重新协商，允许重新协商的客户端只使用 HTTP/1.1
+	if tlsConfig == nil || tlsConfig.Renegotiation == tls.RenegotiateNever {
+		_ = http2.ConfigureTransport(tr)
+	}
 	// 创建一个 HTTP 客户端实例
 	return &http.Client{
 		// 设置重定向检查函数
@@ -225,6 +225,14 @@ type NodeOptions struct {
 	TLS bool
 	// TLSConfigName 字段表示 TLS 配置的名称
 	TLSConfigName string
+	// RateLimiter 字段是发往节点的请求的出站速率限制
+	RateLimiter *tokenBucket
+	// IsolateConnections 字段表示是否使用节点专用的连接
+	IsolateConnections bool
+	// EgressProxy 字段是发往节点的请求使用的出站代理，为空时使用环境变量中配置的代理
+	EgressProxy string
+	// TLSRenegotiation 字段表示是否允许上游请求一次 TLS 重新协商
+	TLSRenegotiation bool
 }
 
 // NewNodeOption 是一个函数类型，它接受一个 NodeOptions 类型的指针参数，并返回一个 NodeOptions 类型的指针
@@ -237,6 +245,34 @@ func WithTLS(in bool) NewNodeOption {
 	}
 }
 
+// WithRateLimiter 函数返回一个 NewNodeOption 类型的函数，该函数设置发往节点的请求的出站速率限制
+func WithRateLimiter(in *tokenBucket) NewNodeOption {
+	return func(o *NodeOptions) {
+		o.RateLimiter = in
+	}
+}
+
+// WithIsolatedConnections 函数返回一个 NewNodeOption 类型的函数，该函数设置是否使用节点专用的连接
+func WithIsolatedConnections(in bool) NewNodeOption {
+	return func(o *NodeOptions) {
+		o.IsolateConnections = in
+	}
+}
+
+// WithEgressProxy 函数返回一个 NewNodeOption 类型的函数，该函数设置发往节点的请求使用的出站代理
+func WithEgressProxy(in string) NewNodeOption {
+	return func(o *NodeOptions) {
+		o.EgressProxy = in
+	}
+}
+
+// WithTLSRenegotiation 函数返回一个 NewNodeOption 类型的函数，该函数设置是否允许上游请求 TLS 重新协商
+func WithTLSRenegotiation(in bool) NewNodeOption {
+	return func(o *NodeOptions) {
+		o.TLSRenegotiation = in
+	}
+}
+
 // WithTLSConfigName 函数返回一个 NewNodeOption 类型的函数，该函数设置 NodeOptions 结构体的 TLSConfigName 字段为传入的字符串
 func WithTLSConfigName(in string) NewNodeOption {
 	return func(o *NodeOptions) {
@@ -272,6 +308,8 @@ func newNode(ctx *BuildContext, addr string, protocol config.Protocol, weight *i
 	for _, o := range opts {
 		o(opt)
 	}
+	// 设置出站速率限制
+	node.limiter = opt.RateLimiter
 	// 如果启用了 TLS，则设置 TLS 相关属性
 	if opt.TLS {
 		node.tls = true
@@ -281,6 +319,12 @@ func newNode(ctx *BuildContext, addr string, protocol config.Protocol, weight *i
 			node.client = ctx.TLSClientStore.GetClient(opt.TLSConfigName)
 		}
 	}
+	// 允许 TLS 重新协商的节点只使用 HTTP/1.1
+	node.renegotiation = opt.TLS && opt.TLSRenegotiation
+	// 隔离连接、配置了出站代理或允许 TLS 重新协商的节点使用专用的客户端
+	if opt.IsolateConnections || opt.EgressProxy != "" || node.renegotiation {
+		node.client = ctx.isolatedClient(node, opt)
+	}
 	// 返回新创建的 node 结构体实例
 	return node
 }
@@ -304,6 +348,12 @@ type node struct {
 	protocol config.Protocol
 	// 是否启用 TLS 加密
 	tls bool
+	// 是否允许上游请求 TLS 重新协商
+	renegotiation bool
+	// gRPC 节点是否已被发现只支持 HTTP/1.1 并降级转发
+	http1 atomic.Bool
+	// 发往节点的请求的出站速率限制，为 nil 时不限制
+	limiter *tokenBucket
 }
 
 // Scheme 方法返回节点的协议方案，将协议字符串转换为小写形式
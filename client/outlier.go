@@ -0,0 +1,299 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/kratos/log"
+	"github.com/cnsync/kratos/selector"
+)
+
+const (
+	// _defaultOutlierErrors 是默认摘除节点的连续错误次数
+	_defaultOutlierErrors = 5
+	// _defaultOutlierServerErrors 是默认摘除节点的连续 5xx 响应次数
+	_defaultOutlierServerErrors = 5
+	// _defaultOutlierWindow 是默认统计连续错误和失败率的时间窗口
+	_defaultOutlierWindow = 10 * time.Second
+	// _defaultOutlierCooldown 是默认第一次摘除节点的时长
+	_defaultOutlierCooldown = 30 * time.Second
+	// _defaultOutlierMaxCooldown 是默认摘除节点的最长时长
+	_defaultOutlierMaxCooldown = 300 * time.Second
+	// _defaultOutlierMinimumRequests 是默认检查失败率之前时间窗口内需要的请求数
+	_defaultOutlierMinimumRequests = 10
+	// _defaultOutlierMaxEjectionPercent 是默认同时被摘除的节点的最大百分比
+	_defaultOutlierMaxEjectionPercent = 50
+)
+
+// outlierState 结构体记录了一个节点的连续错误、时间窗口内的失败率和摘除状态
+type outlierState struct {
+	// errors 是连续错误的次数
+	errors uint32
+	// firstError 是第一次连续错误的时间
+	firstError time.Time
+	// serverErrors 是连续 5xx 响应的次数
+	serverErrors uint32
+	// firstServerError 是第一次连续 5xx 响应的时间
+	firstServerError time.Time
+	// requests 和 failures 是当前时间窗口内的请求数和失败数
+	requests, failures uint32
+	// windowStart 是当前时间窗口的开始时间
+	windowStart time.Time
+	// ejectedUntil 是节点恢复的时间，为零值时节点未被摘除
+	ejectedUntil time.Time
+	// ejections 是节点被连续摘除的次数，决定下一次摘除的时长
+	ejections uint32
+	// lastEjection 是最近一次摘除节点的时间
+	lastEjection time.Time
+}
+
+// outlierStatus 结构体是调试接口中一个节点的异常检测状态
+type outlierStatus struct {
+	Address         string    `json:"address"`
+	Ejected         bool      `json:"ejected"`
+	EjectedUntil    time.Time `json:"ejected_until,omitempty"`
+	Ejections       uint32    `json:"ejections"`
+	ConsecutiveErrs uint32    `json:"consecutive_errors"`
+	Consecutive5xx  uint32    `json:"consecutive_5xx"`
+	WindowRequests  uint32    `json:"window_requests"`
+	WindowFailures  uint32    `json:"window_failures"`
+	LastEjection    time.Time `json:"last_ejection,omitempty"`
+}
+
+// outlierDetector 结构体按节点地址记录连续错误、连续 5xx 响应和失败率，暂时避开异常的节点，
+// 摘除时间过后节点重新参与选择，此时的请求作为探测，失败一次即再次摘除，成功后恢复正常，
+// 摘除的时长随连续摘除的次数增长，同时被摘除的节点数量不超过节点总数的一定比例
+type outlierDetector struct {
+	lock               sync.Mutex
+	maxErrs            uint32
+	maxServerErrs      uint32
+	failureRate        float64
+	minimumRequests    uint32
+	window             time.Duration
+	cooldown           time.Duration
+	maxCooldown        time.Duration
+	maxEjectionPercent uint32
+	states             map[string]*outlierState
+	// nodes 返回当前的节点数量，用于限制同时被摘除的节点数量
+	nodes func() int64
+	now   func() time.Time
+}
+
+// newOutlierDetector 函数根据端点配置创建一个异常节点检测器，未配置时返回 nil
+func newOutlierDetector(c *config.OutlierDetection, nodes func() int64) *outlierDetector {
+	if c == nil {
+		return nil
+	}
+	d := &outlierDetector{
+		maxErrs:            _defaultOutlierErrors,
+		maxServerErrs:      _defaultOutlierServerErrors,
+		failureRate:        c.FailureRate,
+		minimumRequests:    _defaultOutlierMinimumRequests,
+		window:             _defaultOutlierWindow,
+		cooldown:           _defaultOutlierCooldown,
+		maxCooldown:        _defaultOutlierMaxCooldown,
+		maxEjectionPercent: _defaultOutlierMaxEjectionPercent,
+		states:             make(map[string]*outlierState),
+		nodes:              nodes,
+		now:                time.Now,
+	}
+	if c.ConsecutiveErrors > 0 {
+		d.maxErrs = c.ConsecutiveErrors
+	}
+	if c.ConsecutiveServerErrors > 0 {
+		d.maxServerErrs = c.ConsecutiveServerErrors
+	}
+	if c.MinimumRequests > 0 {
+		d.minimumRequests = c.MinimumRequests
+	}
+	if c.Window != nil && c.Window.AsDuration() > 0 {
+		d.window = c.Window.AsDuration()
+	}
+	if c.Cooldown != nil && c.Cooldown.AsDuration() > 0 {
+		d.cooldown = c.Cooldown.AsDuration()
+	}
+	if c.MaxCooldown != nil && c.MaxCooldown.AsDuration() > 0 {
+		d.maxCooldown = c.MaxCooldown.AsDuration()
+	}
+	if d.maxCooldown < d.cooldown {
+		d.maxCooldown = d.cooldown
+	}
+	if c.MaxEjectionPercent > 0 && c.MaxEjectionPercent <= 100 {
+		d.maxEjectionPercent = c.MaxEjectionPercent
+	}
+	return d
+}
+
+// filter 方法过滤掉被摘除的节点，所有节点都被摘除时按正常方式选择
+func (d *outlierDetector) filter(_ context.Context, nodes []selector.Node) []selector.Node {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if len(d.states) == 0 {
+		return nodes
+	}
+	now := d.now()
+	selected := make([]selector.Node, 0, len(nodes))
+	for _, n := range nodes {
+		if s, ok := d.states[n.Address()]; ok && now.Before(s.ejectedUntil) {
+			continue
+		}
+		selected = append(selected, n)
+	}
+	if len(selected) == 0 {
+		return nodes
+	}
+	return selected
+}
+
+// record 方法记录一次请求的结果，请求失败包括错误和 5xx 响应
+func (d *outlierDetector) record(addr string, status int, err error) {
+	// 客户端取消的请求不代表节点异常
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	now := d.now()
+	failed := err != nil || status >= http.StatusInternalServerError
+	s, ok := d.states[addr]
+	if !failed {
+		if !ok {
+			// 不统计失败率时不需要记录正常节点的状态
+			if d.failureRate <= 0 {
+				return
+			}
+			s = &outlierState{}
+			d.states[addr] = s
+		}
+		// 请求成功时节点恢复正常，摘除次数在一段时间没有被摘除之后才清零
+		s.errors, s.serverErrors = 0, 0
+		s.ejectedUntil = time.Time{}
+		d.count(s, now, false)
+		if d.failureRate <= 0 && (s.ejections == 0 || now.Sub(s.lastEjection) > d.maxCooldown) {
+			delete(d.states, addr)
+		}
+		return
+	}
+	if !ok {
+		s = &outlierState{}
+		d.states[addr] = s
+	}
+	if !s.ejectedUntil.IsZero() {
+		// 摘除时间过后的探测请求失败时再次摘除
+		if !now.Before(s.ejectedUntil) {
+			d.eject(addr, s, now, "probe failed")
+		}
+		return
+	}
+	if err != nil {
+		if s.errors == 0 || now.Sub(s.firstError) > d.window {
+			s.errors, s.firstError = 0, now
+		}
+		s.errors++
+	} else {
+		if s.serverErrors == 0 || now.Sub(s.firstServerError) > d.window {
+			s.serverErrors, s.firstServerError = 0, now
+		}
+		s.serverErrors++
+	}
+	d.count(s, now, true)
+	switch {
+	case s.errors >= d.maxErrs:
+		d.eject(addr, s, now, "consecutive errors")
+	case s.serverErrors >= d.maxServerErrs:
+		d.eject(addr, s, now, "consecutive 5xx responses")
+	case d.failureRate > 0 && s.requests >= d.minimumRequests &&
+		float64(s.failures)/float64(s.requests) >= d.failureRate:
+		d.eject(addr, s, now, "failure rate")
+	}
+}
+
+// count 方法在时间窗口内统计请求数和失败数，用于计算失败率
+func (d *outlierDetector) count(s *outlierState, now time.Time, failed bool) {
+	if d.failureRate <= 0 {
+		return
+	}
+	if s.windowStart.IsZero() || now.Sub(s.windowStart) > d.window {
+		s.windowStart, s.requests, s.failures = now, 0, 0
+	}
+	s.requests++
+	if failed {
+		s.failures++
+	}
+}
+
+// ejectable 方法判断是否还可以摘除一个节点，同时被摘除的节点数量不超过节点总数的一定比例，并且至少保留一个节点
+func (d *outlierDetector) ejectable(now time.Time) bool {
+	if d.nodes == nil {
+		return true
+	}
+	total := d.nodes()
+	allowed := total * int64(d.maxEjectionPercent) / 100
+	if allowed > total-1 {
+		allowed = total - 1
+	}
+	var ejected int64
+	for _, s := range d.states {
+		if now.Before(s.ejectedUntil) {
+			ejected++
+		}
+	}
+	return ejected < allowed
+}
+
+// eject 方法摘除节点，摘除的时长为冷却时间乘以连续摘除的次数，不超过最长时长，
+// 已经达到摘除比例上限时不摘除，节点下一次失败时再次尝试
+func (d *outlierDetector) eject(addr string, s *outlierState, now time.Time, reason string) {
+	if !d.ejectable(now) {
+		return
+	}
+	if s.ejections > 0 && now.Sub(s.lastEjection) > d.maxCooldown {
+		s.ejections = 0
+	}
+	s.ejections++
+	duration := d.cooldown * time.Duration(s.ejections)
+	if duration > d.maxCooldown || duration <= 0 {
+		duration = d.maxCooldown
+	}
+	s.ejectedUntil = now.Add(duration)
+	s.lastEjection = now
+	s.errors, s.serverErrors = 0, 0
+	s.windowStart, s.requests, s.failures = time.Time{}, 0, 0
+	log.Warnf("Ejecting outlier node %s for %s: %s", addr, duration, reason)
+}
+
+// status 方法返回所有被记录的节点的异常检测状态，按地址排序
+func (d *outlierDetector) status() []outlierStatus {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	now := d.now()
+	out := make([]outlierStatus, 0, len(d.states))
+	for addr, s := range d.states {
+		out = append(out, outlierStatus{
+			Address:         addr,
+			Ejected:         now.Before(s.ejectedUntil),
+			EjectedUntil:    s.ejectedUntil,
+			Ejections:       s.ejections,
+			ConsecutiveErrs: s.errors,
+			Consecutive5xx:  s.serverErrors,
+			WindowRequests:  s.requests,
+			WindowFailures:  s.failures,
+			LastEjection:    s.lastEjection,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Address < out[j].Address })
+	return out
+}
+
+// wrap 方法包装选择器的完成函数，在请求完成时记录结果，status 指向上游响应的状态码，没有响应时为 0
+func (d *outlierDetector) wrap(addr string, status *int, done selector.DoneFunc) selector.DoneFunc {
+	return func(ctx context.Context, di selector.DoneInfo) {
+		d.record(addr, *status, di.Err)
+		done(ctx, di)
+	}
+}
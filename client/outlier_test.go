@@ -0,0 +1,203 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/kratos/selector"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestOutlierDetector(t *testing.T) {
+	d := newOutlierDetector(&config.OutlierDetection{
+		ConsecutiveErrors: 3,
+		Window:            durationpb.New(10 * time.Second),
+		Cooldown:          durationpb.New(30 * time.Second),
+	}, nil)
+	now := time.Unix(0, 0)
+	d.now = func() time.Time { return now }
+	nodes := []selector.Node{
+		selector.NewNode("http", "127.0.0.1:8001", nil),
+		selector.NewNode("http", "127.0.0.1:8002", nil),
+	}
+	errFailed := errors.New("assertion failed")
+	// done 模拟一次发往节点的请求完成
+	done := func(addr string, err error) {
+		status := 0
+		d.wrap(addr, &status, func(context.Context, selector.DoneInfo) {})(context.Background(), selector.DoneInfo{Err: err})
+	}
+	available := func() []string {
+		var addrs []string
+		for _, n := range d.filter(context.Background(), nodes) {
+			addrs = append(addrs, n.Address())
+		}
+		return addrs
+	}
+	assertAvailable := func(want ...string) {
+		t.Helper()
+		got := available()
+		if len(got) != len(want) {
+			t.Fatalf("want available nodes %v but got %v", want, got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("want available nodes %v but got %v", want, got)
+			}
+		}
+	}
+
+	// 成功的请求会清零连续错误次数
+	done("127.0.0.1:8001", errFailed)
+	done("127.0.0.1:8001", errFailed)
+	done("127.0.0.1:8001", nil)
+	done("127.0.0.1:8001", errFailed)
+	done("127.0.0.1:8001", errFailed)
+	assertAvailable("127.0.0.1:8001", "127.0.0.1:8002")
+	// 超过时间窗口的错误不算连续错误
+	now = now.Add(11 * time.Second)
+	done("127.0.0.1:8001", errFailed)
+	assertAvailable("127.0.0.1:8001", "127.0.0.1:8002")
+	// 客户端取消的请求不计入错误
+	done("127.0.0.1:8001", context.Canceled)
+	done("127.0.0.1:8001", errFailed)
+	assertAvailable("127.0.0.1:8001", "127.0.0.1:8002")
+	done("127.0.0.1:8001", errFailed)
+	assertAvailable("127.0.0.1:8002")
+
+	// 所有节点都被摘除时按正常方式选择
+	for i := 0; i < 3; i++ {
+		done("127.0.0.1:8002", errFailed)
+	}
+	assertAvailable("127.0.0.1:8001", "127.0.0.1:8002")
+	done("127.0.0.1:8002", nil)
+	assertAvailable("127.0.0.1:8002")
+
+	// 冷却时间过后重新参与选择，探测请求失败时再次摘除
+	now = now.Add(31 * time.Second)
+	assertAvailable("127.0.0.1:8001", "127.0.0.1:8002")
+	done("127.0.0.1:8001", errFailed)
+	assertAvailable("127.0.0.1:8002")
+	// 探测请求成功后恢复正常，之后需要重新累计连续错误才会摘除
+	now = now.Add(31 * time.Second)
+	done("127.0.0.1:8001", nil)
+	assertAvailable("127.0.0.1:8001", "127.0.0.1:8002")
+	done("127.0.0.1:8001", errFailed)
+	assertAvailable("127.0.0.1:8001", "127.0.0.1:8002")
+}
+
+func TestOutlierDetectorServerErrorsAndFailureRate(t *testing.T) {
+	d := newOutlierDetector(&config.OutlierDetection{
+		ConsecutiveServerErrors: 2,
+		FailureRate:             0.5,
+		MinimumRequests:         4,
+		MaxEjectionPercent:      100,
+	}, func() int64 { return 3 })
+	now := time.Unix(0, 0)
+	d.now = func() time.Time { return now }
+	done := func(addr string, status int, err error) {
+		d.wrap(addr, &status, func(context.Context, selector.DoneInfo) {})(context.Background(), selector.DoneInfo{Err: err})
+	}
+	ejected := func(addr string) bool {
+		s, ok := d.states[addr]
+		return ok && now.Before(s.ejectedUntil)
+	}
+
+	// 连续的 5xx 响应达到阈值时摘除节点，4xx 响应不算失败
+	done("127.0.0.1:8001", 503, nil)
+	done("127.0.0.1:8001", 404, nil)
+	done("127.0.0.1:8001", 500, nil)
+	if ejected("127.0.0.1:8001") {
+		t.Fatal("want node not ejected after non-consecutive 5xx responses")
+	}
+	done("127.0.0.1:8001", 502, nil)
+	if !ejected("127.0.0.1:8001") {
+		t.Fatal("want node ejected after consecutive 5xx responses")
+	}
+
+	// 失败率达到阈值时摘除节点，请求数不足时不检查失败率
+	done("127.0.0.1:8002", 200, nil)
+	done("127.0.0.1:8002", 0, errors.New("assertion failed"))
+	done("127.0.0.1:8002", 200, nil)
+	if ejected("127.0.0.1:8002") {
+		t.Fatal("want node not ejected before minimum requests")
+	}
+	done("127.0.0.1:8002", 500, nil)
+	if !ejected("127.0.0.1:8002") {
+		t.Fatal("want node ejected on failure rate")
+	}
+
+	// 最多保留一个节点不被摘除
+	done("127.0.0.1:8003", 500, nil)
+	done("127.0.0.1:8003", 500, nil)
+	if ejected("127.0.0.1:8003") {
+		t.Fatal("want the last node not ejected")
+	}
+}
+
+func TestOutlierDetectorEjectionTime(t *testing.T) {
+	d := newOutlierDetector(&config.OutlierDetection{
+		ConsecutiveErrors: 1,
+		Cooldown:          durationpb.New(10 * time.Second),
+		MaxCooldown:       durationpb.New(25 * time.Second),
+	}, func() int64 { return 2 })
+	now := time.Unix(0, 0)
+	d.now = func() time.Time { return now }
+	errFailed := errors.New("assertion failed")
+	done := func(addr string, err error) {
+		status := 0
+		d.wrap(addr, &status, func(context.Context, selector.DoneInfo) {})(context.Background(), selector.DoneInfo{Err: err})
+	}
+	assertEjectedFor := func(want time.Duration) {
+		t.Helper()
+		s := d.states["127.0.0.1:8001"]
+		if got := s.ejectedUntil.Sub(now); got != want {
+			t.Fatalf("want ejected for %s but got %s", want, got)
+		}
+	}
+
+	// 摘除的时长随连续摘除的次数增长，不超过最长时长
+	done("127.0.0.1:8001", errFailed)
+	assertEjectedFor(10 * time.Second)
+	now = now.Add(10 * time.Second)
+	done("127.0.0.1:8001", errFailed)
+	assertEjectedFor(20 * time.Second)
+	now = now.Add(20 * time.Second)
+	done("127.0.0.1:8001", errFailed)
+	assertEjectedFor(25 * time.Second)
+
+	// 探测请求成功后节点恢复正常，摘除次数在一段时间没有被摘除之后才清零
+	now = now.Add(25 * time.Second)
+	done("127.0.0.1:8001", nil)
+	done("127.0.0.1:8001", errFailed)
+	assertEjectedFor(25 * time.Second)
+	now = now.Add(25 * time.Second)
+	done("127.0.0.1:8001", nil)
+	now = now.Add(26 * time.Second)
+	done("127.0.0.1:8001", errFailed)
+	assertEjectedFor(10 * time.Second)
+
+	// 同时被摘除的节点不超过一半
+	done("127.0.0.1:8002", errFailed)
+	if s := d.states["127.0.0.1:8002"]; now.Before(s.ejectedUntil) {
+		t.Fatal("want node not ejected over max ejection percent")
+	}
+
+	// 调试接口返回节点的摘除状态
+	watcher := &serviceWatcher{appliers: map[string]map[string]Applier{
+		"/foo": {"applier": &nodeApplier{outlier: d}},
+	}}
+	w := httptest.NewRecorder()
+	watcher.DebugHandler().ServeHTTP(w, httptest.NewRequest("GET", "/debug/watcher/outliers?service=/foo", nil))
+	var got map[string][]outlierStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got["applier"]) != 2 || !got["applier"][0].Ejected || got["applier"][0].Ejections != 1 || got["applier"][1].Ejected {
+		t.Fatalf("unexpected outlier status: %s", w.Body.String())
+	}
+}
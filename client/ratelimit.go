@@ -0,0 +1,96 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+)
+
+// ErrBackendRateLimited 表示发往后端的请求超过了后端的出站速率限制
+var ErrBackendRateLimited = errors.New("backend rate limit exceeded")
+
+// tokenBucket 结构体是一个令牌桶限流器，令牌以固定的速率补充，桶的容量即突发请求数
+type tokenBucket struct {
+	lock sync.Mutex
+	// rate 是每秒补充的令牌数
+	rate float64
+	// burst 是桶的容量
+	burst float64
+	// maxWait 是请求最长的排队时间，为 0 时超出的请求立即被拒绝
+	maxWait time.Duration
+	// tokens 是当前的令牌数，为负数时表示排队中的请求预支的令牌
+	tokens float64
+	// last 是最近一次补充令牌的时间
+	last time.Time
+	now  func() time.Time
+}
+
+// newTokenBucket 函数根据后端的速率限制配置创建一个令牌桶，未配置时返回 nil
+func newTokenBucket(c *config.BackendRateLimit) *tokenBucket {
+	if c.GetRequestsPerSecond() <= 0 {
+		return nil
+	}
+	burst := float64(c.Burst)
+	if burst <= 0 {
+		burst = math.Ceil(c.RequestsPerSecond)
+	}
+	now := time.Now
+	return &tokenBucket{
+		rate:    c.RequestsPerSecond,
+		burst:   burst,
+		maxWait: c.MaxWait.AsDuration(),
+		tokens:  burst,
+		last:    now(),
+		now:     now,
+	}
+}
+
+// reserve 方法预支一个令牌，返回需要等待的时间，等待时间超过 maxWait 时不预支并返回 false
+func (b *tokenBucket) reserve() (time.Duration, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	now := b.now()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+	wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	if wait > b.maxWait {
+		return 0, false
+	}
+	b.tokens--
+	return wait, true
+}
+
+// cancel 方法归还一个预支的令牌
+func (b *tokenBucket) cancel() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.tokens = math.Min(b.burst, b.tokens+1)
+}
+
+// wait 方法等待直到请求可以发送，超过速率限制且排队时间不足时返回 ErrBackendRateLimited
+func (b *tokenBucket) wait(ctx context.Context) error {
+	d, ok := b.reserve()
+	if !ok {
+		return ErrBackendRateLimited
+	}
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		b.cancel()
+		return ctx.Err()
+	}
+}
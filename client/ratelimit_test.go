@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/middleware"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newTokenBucket(&config.BackendRateLimit{RequestsPerSecond: 10, Burst: 2, MaxWait: durationpb.New(150 * time.Millisecond)})
+	b.now = func() time.Time { return now }
+	b.last = now
+	want := []struct {
+		wait time.Duration
+		ok   bool
+	}{
+		{0, true},
+		{0, true},
+		{100 * time.Millisecond, true},
+		// 前一个请求已预支了令牌，需要等待 200ms，超过了最长排队时间
+		{0, false},
+	}
+	for i, w := range want {
+		wait, ok := b.reserve()
+		if wait != w.wait || ok != w.ok {
+			t.Fatalf("reserve %d: want (%s, %v) but got (%s, %v)", i, w.wait, w.ok, wait, ok)
+		}
+	}
+	now = now.Add(time.Second)
+	if wait, ok := b.reserve(); wait != 0 || !ok {
+		t.Fatalf("want the bucket refilled but got (%s, %v)", wait, ok)
+	}
+	if newTokenBucket(nil) != nil || newTokenBucket(&config.BackendRateLimit{}) != nil {
+		t.Fatal("want no limiter without requests_per_second")
+	}
+}
+
+func TestBackendRateLimit(t *testing.T) {
+	var received atomic.Int64
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+	}))
+	defer backend.Close()
+
+	tests := []struct {
+		name    string
+		maxWait time.Duration
+	}{
+		{"shed", 0},
+		{"queue", 300 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received.Store(0)
+			endpoint := &config.Endpoint{
+				Path:     "/",
+				Protocol: config.Protocol_HTTP,
+				Backends: []*config.Backend{{
+					Target:    strings.TrimPrefix(backend.URL, "http://"),
+					RateLimit: &config.BackendRateLimit{RequestsPerSecond: 20, Burst: 1, MaxWait: durationpb.New(tt.maxWait)},
+				}},
+			}
+			c, err := NewFactory(nil)(EmptyBuildContext(), endpoint)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer c.Close()
+
+			// 在远高于限制的入站负载下，发往后端的请求数不应超过突发数加上这段时间内补充的令牌数
+			start := time.Now()
+			var (
+				wg      sync.WaitGroup
+				limited atomic.Int64
+			)
+			for i := 0; i < 50; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					req := httptest.NewRequest("GET", "/", nil)
+					ctx := middleware.NewRequestContext(context.Background(), middleware.NewRequestOptions(endpoint))
+					resp, err := c.RoundTrip(req.WithContext(ctx))
+					if err != nil {
+						if !errors.Is(err, ErrBackendRateLimited) {
+							t.Error(err)
+						}
+						limited.Add(1)
+						return
+					}
+					resp.Body.Close()
+				}()
+			}
+			wg.Wait()
+			max := 1 + int64(time.Since(start)/(50*time.Millisecond))
+			if n := received.Load(); n < 1 || n > max {
+				t.Fatalf("want at most %d requests sent to the backend but got %d", max, n)
+			}
+			if tt.maxWait > 0 && received.Load() < 2 {
+				t.Fatalf("want the excess requests queued but got %d received", received.Load())
+			}
+			if n := limited.Load(); n+received.Load() != 50 {
+				t.Fatalf("want the excess requests shed but got %d limited and %d received", n, received.Load())
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cnsync/gateway/middleware"
+	"github.com/cnsync/kratos/log"
+	"github.com/cnsync/kratos/selector"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ErrTLSRenegotiation 表示上游在连接建立之后请求了 TLS 重新协商，而节点不允许重新协商
+var ErrTLSRenegotiation = errors.New("upstream requested TLS renegotiation which is not allowed, set allow_tls_renegotiation on the backend if it requires renegotiation")
+
+// _tlsNoRenegotiation 是 crypto/tls 拒绝上游的重新协商请求时返回的错误信息
+const _tlsNoRenegotiation = "tls: no renegotiation"
+
+// _metricTLSRenegotiation 统计了上游请求 TLS 重新协商而被拒绝的次数
+var _metricTLSRenegotiation = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "client_tls_renegotiation_total",
+	Help:      "The total number of requests failed since the upstream requested a TLS renegotiation which is not allowed",
+}, []string{"protocol", "method", "path", "service", "basePath", "backend"})
+
+func init() {
+	prometheus.MustRegister(_metricTLSRenegotiation)
+}
+
+// isTLSRenegotiationError 函数判断错误是否由于拒绝了上游的 TLS 重新协商请求，crypto/tls 没有导出对应的错误类型
+func isTLSRenegotiationError(err error) bool {
+	return err != nil && strings.Contains(err.Error(), _tlsNoRenegotiation)
+}
+
+// renegotiationFilter 函数是请求因拒绝重新协商而失败之后重试时使用的过滤器，优先选择允许重新协商的节点
+func renegotiationFilter(_ context.Context, nodes []selector.Node) []selector.Node {
+	filtered := make([]selector.Node, 0, len(nodes))
+	for _, n := range nodes {
+		if bn, ok := n.(*node); ok && bn.renegotiation {
+			filtered = append(filtered, n)
+		}
+	}
+	if len(filtered) == 0 {
+		return nodes
+	}
+	return filtered
+}
+
+// renegotiationError 方法记录上游请求 TLS 重新协商而被拒绝的失败，返回明确的错误，
+// 并使同一请求的后续重试优先选择允许重新协商的节点
+func (n *node) renegotiationError(req *http.Request, err error) error {
+	ctx := req.Context()
+	if labels, ok := middleware.MetricsLabelsFromContext(ctx); ok {
+		_metricTLSRenegotiation.WithLabelValues(labels.Protocol(), labels.Method(), labels.Path(), labels.Service(), labels.BasePath(), n.address).Inc()
+	}
+	log.Errorf("TLS backend %s requested renegotiation which is not allowed: %v", n.address, err)
+	middleware.WithSelectorFitler(ctx, renegotiationFilter)
+	return fmt.Errorf("%s: %w", n.address, ErrTLSRenegotiation)
+}
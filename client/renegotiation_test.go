@@ -0,0 +1,57 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/middleware"
+	"github.com/cnsync/kratos/selector"
+)
+
+func TestTLSRenegotiation(t *testing.T) {
+	ctx := EmptyBuildContext()
+	legacy := newNode(ctx, "127.0.0.1:8443", config.Protocol_HTTP, nil, nil, "", "", WithTLS(true))
+	allowed := newNode(ctx, "127.0.0.1:9443", config.Protocol_HTTP, nil, nil, "", "", WithTLS(true), WithTLSRenegotiation(true))
+
+	// 允许重新协商的节点使用只支持 HTTP/1.1 的专用客户端
+	tr, ok := allowed.client.Transport.(*http.Transport)
+	if !ok || tr.TLSClientConfig.Renegotiation != tls.RenegotiateOnceAsClient {
+		t.Fatal("want a client allowing renegotiation")
+	}
+	if len(tr.TLSClientConfig.NextProtos) != 0 {
+		t.Fatalf("want HTTP/1.1 only but got %v", tr.TLSClientConfig.NextProtos)
+	}
+
+	// 模拟 crypto/tls 拒绝上游的重新协商请求
+	legacy.client = &http.Client{Transport: middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, &net.OpError{Op: "local error", Net: "tcp", Err: errors.New("tls: no renegotiation")}
+	})}
+	endpoint := &config.Endpoint{Path: "/api/echo", Protocol: config.Protocol_HTTP}
+	reqOpts := middleware.NewRequestOptions(endpoint)
+	req := httptest.NewRequest("GET", "https://127.0.0.1:8443/api/echo", nil)
+	req.RequestURI = ""
+	req = req.WithContext(middleware.NewRequestContext(context.Background(), reqOpts))
+	_, err := legacy.do(req)
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) || !isTLSRenegotiationError(err) {
+		t.Fatalf("want a renegotiation error but got %v", err)
+	}
+	if err = legacy.renegotiationError(req, err); !errors.Is(err, ErrTLSRenegotiation) {
+		t.Fatalf("want ErrTLSRenegotiation but got %v", err)
+	}
+	// 重试时优先选择允许重新协商的节点
+	nodes := []selector.Node{legacy, allowed}
+	for _, f := range reqOpts.Filters {
+		nodes = f(context.Background(), nodes)
+	}
+	if len(nodes) != 1 || nodes[0] != allowed {
+		t.Fatalf("want only the node allowing renegotiation on retry but got %v", nodes)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"hash/crc32"
 	"net/http"
+	"os"
 	"sort"
 	"strconv"
 	"sync"
@@ -15,20 +16,48 @@ import (
 	"github.com/cnsync/kratos/log"
 	"github.com/cnsync/kratos/registry"
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // 定义一个错误，表示监控被取消
 var ErrCancelWatch = errors.New("cancel watch")
 
-// 创建一个全局的服务监控器实例
-var globalServiceWatcher = newServiceWatcher()
+// 创建一个全局的服务监控器实例，持久化服务实例的目录从环境变量 PROXY_DISCOVERY_CACHE_DIR 中读取，为空时不持久化
+var globalServiceWatcher = newServiceWatcher(os.Getenv("PROXY_DISCOVERY_CACHE_DIR"))
 
 // 创建一个日志助手，用于记录日志
 var LOG = log.NewHelper(log.With(log.GetLogger(), "source", "servicewatch"))
 
+var (
+	// _metricWatches 是一个仪表，记录向注册发现服务发起的监控数量，同一个服务的所有端点共享一个监控
+	_metricWatches = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "discovery_watches",
+		Help:      "Number of the discovery watches",
+	})
+	// _metricWatchAppliers 是一个仪表，按服务记录共享一个监控的端点数量
+	_metricWatchAppliers = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "discovery_watch_appliers",
+		Help:      "Number of the endpoints served by the discovery watch of a service",
+	}, []string{"service"})
+	// _metricWatchUpdates 是一个计数器，按服务记录监控收到的实例变更次数
+	_metricWatchUpdates = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "discovery_watch_updates_total",
+		Help:      "Total instance updates received by the discovery watch of a service",
+	}, []string{"service"})
+)
+
 // 在程序初始化时，注册服务监控器到调试模块
 func init() {
 	debug.Register("watcher", globalServiceWatcher)
+	prometheus.MustRegister(_metricWatches)
+	prometheus.MustRegister(_metricWatchAppliers)
+	prometheus.MustRegister(_metricWatchUpdates)
 }
 
 // 生成一个 UUID v4 字符串
@@ -56,10 +85,14 @@ func instancesSetHash(instances []*registry.ServiceInstance) string {
 type watcherStatus struct {
 	// 监控器实例
 	watcher registry.Watcher
+	// cancel 停止监控，服务的所有端点都被清理之后调用
+	cancel context.CancelFunc
 	// 初始化通道，用于通知监控器已初始化完成
 	initializedChan chan struct{}
 	// 选中的实例列表
 	selectedInstances []*registry.ServiceInstance
+	// 注册发现服务最近一次返回的实例数量，为空时选中的实例列表会保留，但实例数量为 0
+	instances int
 }
 
 // serviceWatcher 结构体定义了服务监控器，包含读写锁、监控器状态映射和应用程序映射
@@ -70,12 +103,16 @@ type serviceWatcher struct {
 	watcherStatus map[string]*watcherStatus
 	// 应用程序映射，键为端点名称，值为应用程序实例映射
 	appliers map[string]map[string]Applier
+	// cache 持久化服务实例，供注册发现服务不可用时使用
+	cache discoveryCache
 }
 
-// newServiceWatcher 函数创建一个新的服务监控器实例，并启动一个后台清理任务
-func newServiceWatcher() *serviceWatcher {
+// newServiceWatcher 函数创建一个新的服务监控器实例，并启动一个后台清理任务，cacheDir 是持久化服务实例的目录
+func newServiceWatcher(cacheDir string) *serviceWatcher {
 	// 创建一个服务监控器实例
 	s := &serviceWatcher{
+		// 持久化服务实例的目录，为空时不持久化
+		cache: discoveryCache{dir: cacheDir},
 		// 初始化监控器状态映射
 		watcherStatus: make(map[string]*watcherStatus),
 		// 初始化应用程序映射
@@ -94,8 +131,34 @@ func (s *serviceWatcher) setSelectedCache(endpoint string, instances []*registry
 	// 延迟解锁
 	defer s.lock.Unlock()
 
+	// 监控已经停止时忽略
+	ws, ok := s.watcherStatus[endpoint]
+	if !ok {
+		return
+	}
 	// 设置指定端点的选中实例列表
-	s.watcherStatus[endpoint].selectedInstances = instances
+	ws.selectedInstances = instances
+	ws.instances = len(instances)
+}
+
+// setInstanceCount 方法设置指定端点最近一次从注册发现服务获取到的实例数量
+func (s *serviceWatcher) setInstanceCount(endpoint string, count int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if ws, ok := s.watcherStatus[endpoint]; ok {
+		ws.instances = count
+	}
+}
+
+// getInstanceCount 方法获取指定端点最近一次从注册发现服务获取到的实例数量，端点未被监控时返回 false
+func (s *serviceWatcher) getInstanceCount(endpoint string) (int, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	ws, ok := s.watcherStatus[endpoint]
+	if !ok {
+		return 0, false
+	}
+	return ws.instances, true
 }
 
 // getSelectedCache 方法获取指定端点的选中实例缓存
@@ -140,7 +203,8 @@ type Applier interface {
 	Canceled() bool
 }
 
-// Add 方法用于添加一个新的服务监控器到指定的端点，并注册一个应用程序实例来接收服务实例的回调通知
+// Add 方法用于添加一个新的服务监控器到指定的端点，并注册一个应用程序实例来接收服务实例的回调通知，
+// 同一个服务只向注册发现服务发起一个监控，由所有端点的应用程序实例共享，监控不随任何一个端点的取消而停止
 func (s *serviceWatcher) Add(ctx context.Context, discovery registry.Discovery, endpoint string, applier Applier) (watcherExisted bool) {
 	// 加锁，保护监控器状态和应用程序映射
 	s.lock.Lock()
@@ -169,13 +233,21 @@ func (s *serviceWatcher) Add(ctx context.Context, discovery registry.Discovery,
 		ws = &watcherStatus{
 			initializedChan: make(chan struct{}),
 		}
-		// 使用发现服务创建一个新的监控器实例
-		watcher, err := discovery.Watch(ctx, endpoint)
+		// 使用发现服务创建一个新的监控器实例，监控的生命周期由服务监控器管理，不随第一个端点的取消而停止
+		watchCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+		watcher, err := discovery.Watch(watchCtx, endpoint)
 		if err != nil {
+			cancel()
 			// 如果创建失败，记录错误并返回 false
 			LOG.Errorf("Failed to initialize watcher on endpoint: %s, err: %+v", endpoint, err)
+			// 使用持久化的实例列表，使网关在注册发现服务不可用时仍然可以转发请求
+			if cached := s.cache.failover(endpoint); len(cached) > 0 {
+				applier.Callback(cached)
+			}
 			return false
 		}
+		ws.cancel = cancel
+		_metricWatches.Inc()
 		// 记录成功初始化监控器的信息
 		LOG.Infof("Succeeded to initialize watcher on endpoint: %s", endpoint)
 		// 将新创建的监控器实例保存到监控器状态中
@@ -192,14 +264,24 @@ func (s *serviceWatcher) Add(ctx context.Context, discovery registry.Discovery,
 			if err != nil {
 				// 如果获取失败，记录错误并返回
 				LOG.Errorf("Failed to do initialize services discovery on endpoint: %s, err: %+v, the watch process will attempt asynchronously", endpoint, err)
+				// 在监控恢复之前使用持久化的实例列表
+				if cached := s.cache.failover(endpoint); len(cached) > 0 {
+					ws.selectedInstances = cached
+					applier.Callback(cached)
+				}
 				return
 			}
 			// 记录成功获取初始服务实例列表的信息
 			LOG.Infof("Succeeded to do initialize services discovery on endpoint: %s, %d services, hash: %s", endpoint, len(services), instancesSetHash(ws.selectedInstances))
 			// 将获取到的服务实例列表保存到监控器状态中
 			ws.selectedInstances = services
+			ws.instances = len(services)
 			// 调用应用程序实例的回调方法，传递初始服务实例列表
 			applier.Callback(services)
+			// 持久化实例列表，供注册发现服务不可用时重启使用
+			if err := s.cache.save(endpoint, services); err != nil {
+				LOG.Errorf("Failed to save cached services on endpoint: %s, err: %+v", endpoint, err)
+			}
 		}()
 
 		// 启动一个 goroutine 来持续监控服务实例的变化
@@ -209,7 +291,7 @@ func (s *serviceWatcher) Add(ctx context.Context, discovery registry.Discovery,
 				services, err := watcher.Next()
 				if err != nil {
 					// 如果获取失败，检查错误类型
-					if errors.Is(err, context.Canceled) {
+					if errors.Is(err, context.Canceled) || watchCtx.Err() != nil {
 						// 如果是上下文取消，则记录警告并返回
 						LOG.Warnf("The watch process on: %s has been canceled", endpoint)
 						return
@@ -222,6 +304,7 @@ func (s *serviceWatcher) Add(ctx context.Context, discovery registry.Discovery,
 				// 如果获取到的服务实例列表为空，则记录警告并继续
 				if len(services) == 0 {
 					LOG.Warnf("Empty services on endpoint: %s, this most likely no available instance in discovery", endpoint)
+					s.setInstanceCount(endpoint, 0)
 					continue
 				}
 				// 记录接收到的服务实例列表信息
@@ -230,6 +313,10 @@ func (s *serviceWatcher) Add(ctx context.Context, discovery registry.Discovery,
 				s.setSelectedCache(endpoint, services)
 				// 调用回调方法，通知应用程序实例服务实例列表的变化
 				s.doCallback(endpoint, services)
+				// 持久化实例列表，供注册发现服务不可用时重启使用
+				if err := s.cache.save(endpoint, services); err != nil {
+					LOG.Errorf("Failed to save cached services on endpoint: %s, err: %+v", endpoint, err)
+				}
 			}
 		}()
 
@@ -246,6 +333,7 @@ func (s *serviceWatcher) Add(ctx context.Context, discovery registry.Discovery,
 		}
 		// 为应用程序实例生成一个唯一的标识符，并将其保存到映射中
 		s.appliers[endpoint][uuid4()] = applier
+		_metricWatchAppliers.WithLabelValues(endpoint).Set(float64(len(s.appliers[endpoint])))
 	}
 
 	// 返回监控器是否已经存在的标志
@@ -254,6 +342,7 @@ func (s *serviceWatcher) Add(ctx context.Context, discovery registry.Discovery,
 
 // doCallback 方法用于遍历指定端点的所有应用程序实例，并调用它们的回调方法来处理服务实例的变化
 func (s *serviceWatcher) doCallback(endpoint string, services []*registry.ServiceInstance) {
+	_metricWatchUpdates.WithLabelValues(endpoint).Inc()
 	// 记录被取消的应用程序实例数量
 	canceled := 0
 	// 启动一个匿名函数，在函数内部加读锁，保护应用程序映射
@@ -286,47 +375,6 @@ func (s *serviceWatcher) doCallback(endpoint string, services []*registry.Servic
 
 // proccleanup 方法启动一个后台任务，定期清理已取消的应用程序实例
 func (s *serviceWatcher) proccleanup() {
-	// 定义一个内部函数 doCleanup，用于执行清理操作
-	doCleanup := func() {
-		// 遍历所有端点的应用程序实例映射
-		for endpoint, appliers := range s.appliers {
-			// 初始化一个切片，用于存储需要清理的应用程序实例的 ID
-			var cleanup []string
-			// 启动一个匿名函数，在函数内部加读锁，保护应用程序映射
-			func() {
-				s.lock.RLock()
-				defer s.lock.RUnlock()
-				// 遍历当前端点的所有应用程序实例
-				for id, applier := range appliers {
-					// 如果应用程序实例已被取消，则将其 ID 添加到清理列表中
-					if applier.Canceled() {
-						cleanup = append(cleanup, id)
-						// 记录警告信息，表示该应用程序实例将被删除
-						LOG.Warnf("applier on endpoint: %s, id: %s is canceled, will be deleted later", endpoint, id)
-						continue
-					}
-				}
-			}()
-			// 如果没有需要清理的应用程序实例，则直接返回
-			if len(cleanup) <= 0 {
-				return
-			}
-			// 记录清理信息，包括端点名称和需要清理的应用程序实例 ID
-			LOG.Infof("Cleanup appliers on endpoint: %q with keys: %+v", endpoint, cleanup)
-			// 启动一个匿名函数，在函数内部加锁，保护应用程序映射
-			func() {
-				s.lock.Lock()
-				defer s.lock.Unlock()
-				// 遍历清理列表，删除对应的应用程序实例
-				for _, id := range cleanup {
-					delete(appliers, id)
-				}
-				// 记录清理结果，包括清理的应用程序实例数量和当前端点剩余的应用程序实例数量
-				LOG.Infof("Succeeded to clean %d appliers on endpoint: %q, now %d appliers are available", len(cleanup), endpoint, len(appliers))
-			}()
-		}
-	}
-
 	// 定义清理间隔时间为 30 秒
 	const interval = time.Second * 30
 	// 启动一个无限循环，定期执行清理任务
@@ -336,9 +384,62 @@ func (s *serviceWatcher) proccleanup() {
 		// 等待清理间隔时间
 		time.Sleep(interval)
 		// 执行清理操作
-		doCleanup()
+		s.cleanup()
+	}
+}
+
+// cleanup 方法清理已取消的应用程序实例，服务没有剩余的应用程序实例时停止监控
+func (s *serviceWatcher) cleanup() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	// 遍历所有端点的应用程序实例映射
+	for endpoint, appliers := range s.appliers {
+		// 初始化一个切片，用于存储需要清理的应用程序实例的 ID
+		var cleanup []string
+		// 遍历当前端点的所有应用程序实例
+		for id, applier := range appliers {
+			// 如果应用程序实例已被取消，则将其 ID 添加到清理列表中
+			if applier.Canceled() {
+				cleanup = append(cleanup, id)
+				// 记录警告信息，表示该应用程序实例将被删除
+				LOG.Warnf("applier on endpoint: %s, id: %s is canceled, will be deleted later", endpoint, id)
+			}
+		}
+		// 如果没有需要清理的应用程序实例，则继续检查下一个端点
+		if len(cleanup) <= 0 {
+			continue
+		}
+		// 记录清理信息，包括端点名称和需要清理的应用程序实例 ID
+		LOG.Infof("Cleanup appliers on endpoint: %q with keys: %+v", endpoint, cleanup)
+		// 遍历清理列表，删除对应的应用程序实例
+		for _, id := range cleanup {
+			delete(appliers, id)
+		}
+		// 记录清理结果，包括清理的应用程序实例数量和当前端点剩余的应用程序实例数量
+		LOG.Infof("Succeeded to clean %d appliers on endpoint: %q, now %d appliers are available", len(cleanup), endpoint, len(appliers))
+		_metricWatchAppliers.WithLabelValues(endpoint).Set(float64(len(appliers)))
+		// 没有端点再使用这个服务时停止监控，减少注册发现服务的负载
+		if len(appliers) == 0 {
+			s.stopWatch(endpoint)
+		}
 	}
+}
 
+// stopWatch 方法停止指定服务的监控，调用时需要持有写锁
+func (s *serviceWatcher) stopWatch(endpoint string) {
+	delete(s.appliers, endpoint)
+	ws, ok := s.watcherStatus[endpoint]
+	if !ok {
+		return
+	}
+	delete(s.watcherStatus, endpoint)
+	ws.cancel()
+	if err := ws.watcher.Stop(); err != nil {
+		LOG.Errorf("Failed to stop watcher on endpoint: %s, err: %+v", endpoint, err)
+	}
+	_metricWatches.Dec()
+	_metricWatchAppliers.DeleteLabelValues(endpoint)
+	LOG.Infof("Stopped watcher on endpoint: %s, no appliers are left", endpoint)
 }
 
 // DebugHandler 函数返回一个 HTTP 处理器，用于处理调试请求
@@ -367,6 +468,20 @@ func (s *serviceWatcher) DebugHandler() http.Handler {
 		// 使用 JSON 编码器将应用程序实例列表编码并写入响应
 		json.NewEncoder(w).Encode(appliers)
 	})
+	// 注册一个处理函数，用于处理 /debug/watcher/outliers 路径的请求，返回每个应用程序实例的节点摘除状态
+	debugMux.HandleFunc("/debug/watcher/outliers", func(w http.ResponseWriter, r *http.Request) {
+		service := r.URL.Query().Get("service")
+		appliers, _ := s.getAppliers(service)
+		outliers := make(map[string][]outlierStatus, len(appliers))
+		for id, applier := range appliers {
+			// 只有配置了异常节点检测的端点才有摘除状态
+			if na, ok := applier.(*nodeApplier); ok && na.outlier != nil {
+				outliers[id] = na.outlier.status()
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(outliers)
+	})
 	// 返回创建的 HTTP 处理器
 	return debugMux
 }
@@ -376,3 +491,8 @@ func AddWatch(ctx context.Context, registry registry.Discovery, endpoint string,
 	// 调用全局服务监控器的 Add 方法，添加监控器和应用程序实例
 	return globalServiceWatcher.Add(ctx, registry, endpoint, applier)
 }
+
+// ServiceInstanceCount 函数返回指定服务最近一次从注册发现服务获取到的实例数量，服务未被监控时返回 false
+func ServiceInstanceCount(service string) (int, bool) {
+	return globalServiceWatcher.getInstanceCount(service)
+}
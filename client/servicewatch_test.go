@@ -0,0 +1,145 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cnsync/kratos/registry"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type fakeWatcher struct {
+	ctx     context.Context
+	next    chan []*registry.ServiceInstance
+	stopped atomic.Bool
+}
+
+func (w *fakeWatcher) Next() ([]*registry.ServiceInstance, error) {
+	select {
+	case services := <-w.next:
+		return services, nil
+	case <-w.ctx.Done():
+		return nil, w.ctx.Err()
+	}
+}
+
+func (w *fakeWatcher) Stop() error {
+	w.stopped.Store(true)
+	return nil
+}
+
+// fakeDiscovery 记录发起的监控
+type fakeDiscovery struct {
+	lock     sync.Mutex
+	watchers []*fakeWatcher
+}
+
+func (d *fakeDiscovery) GetService(context.Context, string) ([]*registry.ServiceInstance, error) {
+	return nil, nil
+}
+
+func (d *fakeDiscovery) Watch(ctx context.Context, _ string) (registry.Watcher, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	w := &fakeWatcher{ctx: ctx, next: make(chan []*registry.ServiceInstance, 1)}
+	w.next <- []*registry.ServiceInstance{{ID: "1"}}
+	d.watchers = append(d.watchers, w)
+	return w, nil
+}
+
+// fakeApplier 记录收到的实例数量
+type fakeApplier struct {
+	canceled  atomic.Bool
+	instances chan int
+}
+
+func (a *fakeApplier) Callback(services []*registry.ServiceInstance) error {
+	if a.canceled.Load() {
+		return ErrCancelWatch
+	}
+	a.instances <- len(services)
+	return nil
+}
+
+func (a *fakeApplier) Canceled() bool { return a.canceled.Load() }
+
+func TestServiceWatchShared(t *testing.T) {
+	s := newServiceWatcher("")
+	d := &fakeDiscovery{}
+	receive := func(a *fakeApplier, want int) {
+		t.Helper()
+		select {
+		case got := <-a.instances:
+			if got != want {
+				t.Fatalf("want %d instances but got %d", want, got)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("want a callback but got none")
+		}
+	}
+	appliersGauge := func() float64 {
+		t.Helper()
+		mfs, err := prometheus.DefaultGatherer.Gather()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, mf := range mfs {
+			if mf.GetName() != "go_gateway_discovery_watch_appliers" {
+				continue
+			}
+			for _, m := range mf.GetMetric() {
+				for _, l := range m.GetLabel() {
+					if l.GetName() == "service" && l.GetValue() == "shared-svc" {
+						return m.GetGauge().GetValue()
+					}
+				}
+			}
+		}
+		return 0
+	}
+
+	// 同一个服务的两个端点共享一个监控
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	a1 := &fakeApplier{instances: make(chan int, 4)}
+	a2 := &fakeApplier{instances: make(chan int, 4)}
+	if s.Add(ctx1, d, "shared-svc", a1) {
+		t.Fatal("want a new watch")
+	}
+	receive(a1, 1)
+	if !s.Add(context.Background(), d, "shared-svc", a2) {
+		t.Fatal("want the existing watch reused")
+	}
+	receive(a2, 1)
+	if len(d.watchers) != 1 {
+		t.Fatalf("want 1 watch but got %d", len(d.watchers))
+	}
+	if got := appliersGauge(); got != 2 {
+		t.Fatalf("want 2 appliers on the watch but got %v", got)
+	}
+
+	// 第一个端点取消之后监控继续为其他端点服务
+	cancel1()
+	a1.canceled.Store(true)
+	d.watchers[0].next <- []*registry.ServiceInstance{{ID: "1"}, {ID: "2"}}
+	receive(a2, 2)
+	s.cleanup()
+	if got := appliersGauge(); got != 1 {
+		t.Fatalf("want 1 applier on the watch but got %v", got)
+	}
+	if d.watchers[0].stopped.Load() {
+		t.Fatal("want the watch kept for the remaining applier")
+	}
+
+	// 所有端点都被清理之后停止监控
+	a2.canceled.Store(true)
+	s.cleanup()
+	if !d.watchers[0].stopped.Load() || d.watchers[0].ctx.Err() == nil {
+		t.Fatal("want the watch stopped")
+	}
+	if _, ok := s.getInstanceCount("shared-svc"); ok {
+		t.Fatal("want the watch removed")
+	}
+}
@@ -0,0 +1,147 @@
+package client
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/kratos/selector"
+)
+
+// _defaultSlowStartMinWeight 是默认新节点在预热开始时参与选择的比例
+const _defaultSlowStartMinWeight = 0.1
+
+// leavingNode 结构体是一个已从注册发现服务中移除、仍在冷却的节点
+type leavingNode struct {
+	node   selector.Node
+	leftAt time.Time
+}
+
+// slowStart 结构体按节点地址记录节点加入和离开的时间，新加入的节点参与选择的比例在预热时间内线性增长，
+// 离开的节点在冷却时间内保留在选择器中，参与选择的比例线性减少
+type slowStart struct {
+	lock      sync.Mutex
+	warmup    time.Duration
+	cooldown  time.Duration
+	minWeight float64
+	// joined 记录了节点加入的时间，构建端点时已存在的节点为零值，不需要预热
+	joined map[string]time.Time
+	// live 是最近一次从注册发现服务中获取的节点
+	live map[string]selector.Node
+	// leaving 记录了正在冷却的节点
+	leaving map[string]*leavingNode
+	// initialized 表示是否已经应用过第一批节点
+	initialized bool
+	now         func() time.Time
+	random      func() float64
+}
+
+// newSlowStart 函数根据端点的负载均衡配置创建节点的预热和冷却，未配置时返回 nil
+func newSlowStart(c *config.SlowStart) *slowStart {
+	if c == nil {
+		return nil
+	}
+	s := &slowStart{
+		minWeight: _defaultSlowStartMinWeight,
+		joined:    make(map[string]time.Time),
+		leaving:   make(map[string]*leavingNode),
+		now:       time.Now,
+		random:    rand.Float64,
+	}
+	if c.Warmup != nil && c.Warmup.AsDuration() > 0 {
+		s.warmup = c.Warmup.AsDuration()
+	}
+	if c.Cooldown != nil && c.Cooldown.AsDuration() > 0 {
+		s.cooldown = c.Cooldown.AsDuration()
+	}
+	if c.MinWeight > 0 && c.MinWeight <= 1 {
+		s.minWeight = c.MinWeight
+	}
+	return s
+}
+
+// update 方法记录新加入和离开的节点，返回应用到选择器中的节点，包括仍在冷却的节点
+func (s *slowStart) update(nodes []selector.Node) []selector.Node {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := s.now()
+	current := make(map[string]selector.Node, len(nodes))
+	for _, n := range nodes {
+		addr := n.Address()
+		current[addr] = n
+		if _, ok := s.live[addr]; ok {
+			continue
+		}
+		// 冷却中的节点重新加入时不需要再次预热
+		_, left := s.leaving[addr]
+		delete(s.leaving, addr)
+		s.joined[addr] = time.Time{}
+		if s.initialized && !left {
+			s.joined[addr] = now
+		}
+	}
+	s.initialized = true
+	// 上一批节点中不再存在的节点开始冷却
+	for addr, n := range s.live {
+		if _, ok := current[addr]; ok {
+			continue
+		}
+		delete(s.joined, addr)
+		if s.cooldown > 0 {
+			s.leaving[addr] = &leavingNode{node: n, leftAt: now}
+		}
+	}
+	s.live = current
+	applied := nodes
+	for addr, l := range s.leaving {
+		if now.Sub(l.leftAt) >= s.cooldown {
+			delete(s.leaving, addr)
+			continue
+		}
+		applied = append(applied[:len(applied):len(applied)], l.node)
+	}
+	return applied
+}
+
+// weight 方法返回节点当前参与选择的比例，调用方需要持有锁
+func (s *slowStart) weight(addr string, now time.Time) float64 {
+	if l, ok := s.leaving[addr]; ok {
+		return 1 - float64(now.Sub(l.leftAt))/float64(s.cooldown)
+	}
+	joined := s.joined[addr]
+	if s.warmup <= 0 || joined.IsZero() {
+		return 1
+	}
+	elapsed := now.Sub(joined)
+	if elapsed >= s.warmup {
+		return 1
+	}
+	return s.minWeight + (1-s.minWeight)*float64(elapsed)/float64(s.warmup)
+}
+
+// filter 方法按节点参与选择的比例过滤节点，没有节点被选中时使用所有未离开的节点
+func (s *slowStart) filter(_ context.Context, nodes []selector.Node) []selector.Node {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := s.now()
+	selected := make([]selector.Node, 0, len(nodes))
+	remaining := make([]selector.Node, 0, len(nodes))
+	for _, n := range nodes {
+		addr := n.Address()
+		if _, ok := s.leaving[addr]; !ok {
+			remaining = append(remaining, n)
+		}
+		if w := s.weight(addr, now); w >= 1 || (w > 0 && s.random() < w) {
+			selected = append(selected, n)
+		}
+	}
+	if len(selected) == 0 {
+		if len(remaining) == 0 {
+			return nodes
+		}
+		return remaining
+	}
+	return selected
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/kratos/selector"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestSlowStart(t *testing.T) {
+	s := newSlowStart(&config.SlowStart{
+		Warmup:    durationpb.New(10 * time.Second),
+		Cooldown:  durationpb.New(10 * time.Second),
+		MinWeight: 0.1,
+	})
+	now := time.Unix(0, 0)
+	s.now = func() time.Time { return now }
+	// random 依次返回均匀分布的值，使参与选择的次数与比例一致
+	var i int
+	s.random = func() float64 {
+		i++
+		return float64(i%100) / 100
+	}
+	a := selector.NewNode("http", "127.0.0.1:8001", nil)
+	b := selector.NewNode("http", "127.0.0.1:8002", nil)
+	c := selector.NewNode("http", "127.0.0.1:8003", nil)
+	// selected 统计 100 次过滤中每个节点参与选择的次数
+	selected := func(nodes []selector.Node) map[string]int {
+		counts := map[string]int{}
+		for j := 0; j < 100; j++ {
+			for _, n := range s.filter(context.Background(), nodes) {
+				counts[n.Address()]++
+			}
+		}
+		return counts
+	}
+
+	// 构建端点时已存在的节点不需要预热
+	nodes := s.update([]selector.Node{a, b})
+	if got := selected(nodes); got[a.Address()] != 100 || got[b.Address()] != 100 {
+		t.Fatalf("want the initial nodes fully selected but got %v", got)
+	}
+	// 新加入的节点参与选择的比例逐步增长
+	nodes = s.update([]selector.Node{a, b, c})
+	var last int
+	for _, elapsed := range []time.Duration{0, 5 * time.Second, 9 * time.Second} {
+		now = time.Unix(0, 0).Add(elapsed)
+		got := selected(nodes)[c.Address()]
+		if got >= 100 || got <= last {
+			t.Fatalf("want the joining node ramped up at %s but got %d selections after %d", elapsed, got, last)
+		}
+		last = got
+	}
+	now = time.Unix(10, 0)
+	if got := selected(nodes)[c.Address()]; got != 100 {
+		t.Fatalf("want the warmed node fully selected but got %d", got)
+	}
+
+	// 离开的节点在冷却时间内保留，参与选择的比例逐步减少
+	nodes = s.update([]selector.Node{a, c})
+	if len(nodes) != 3 {
+		t.Fatalf("want the leaving node kept but got %d nodes", len(nodes))
+	}
+	last = 100
+	for _, elapsed := range []time.Duration{time.Second, 5 * time.Second, 9 * time.Second} {
+		now = time.Unix(10, 0).Add(elapsed)
+		got := selected(nodes)[b.Address()]
+		if got >= last {
+			t.Fatalf("want the leaving node ramped down at %s but got %d selections after %d", elapsed, got, last)
+		}
+		last = got
+	}
+	now = time.Unix(20, 0)
+	if got := selected(nodes)[b.Address()]; got != 0 {
+		t.Fatalf("want the cooled down node not selected but got %d", got)
+	}
+	if nodes = s.update([]selector.Node{a, c}); len(nodes) != 2 {
+		t.Fatalf("want the cooled down node removed but got %d nodes", len(nodes))
+	}
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+
+	"github.com/cnsync/kratos/selector"
+)
+
+// WarmupTarget 结构体定义了一个需要预热的后端节点，相同节点客户端、地址和主机的目标可以比较相等，用于去重
+type WarmupTarget struct {
+	// URL 是预热请求的地址，方案由节点是否启用 TLS 决定
+	URL string
+	// client 是节点自身的客户端，预热建立的连接保留在它的连接池中
+	client *http.Client
+	// host 是节点元数据中指定的主机，为空时使用地址
+	host string
+}
+
+// warmupTarget 函数返回节点的预热目标，与转发请求时一样选择方案和主机
+func warmupTarget(n *node) WarmupTarget {
+	t := WarmupTarget{URL: "http://" + n.address + "/", client: n.client}
+	if n.tls {
+		t.URL = "https://" + n.address + "/"
+		t.host = n.address
+	}
+	if host := n.metadata["host"]; host != "" {
+		t.host = host
+	}
+	return t
+}
+
+// warmupTargets 函数返回节点列表中每个节点的预热目标
+func warmupTargets(nodes []selector.Node) []WarmupTarget {
+	targets := make([]WarmupTarget, 0, len(nodes))
+	for _, n := range nodes {
+		if backendNode, ok := n.(*node); ok {
+			targets = append(targets, warmupTarget(backendNode))
+		}
+	}
+	return targets
+}
+
+// Warm 方法通过节点自身的客户端并发发送 conns 个 HEAD 请求，读完并关闭响应体，使建立的连接留在连接池中，
+// 启用 TLS 的节点同时完成握手，返回第一个失败请求的错误
+func (t WarmupTarget) Warm(ctx context.Context, conns int) error {
+	errs := make(chan error, conns)
+	for i := 0; i < conns; i++ {
+		go func() {
+			errs <- t.warmOnce(ctx)
+		}()
+	}
+	var first error
+	for i := 0; i < conns; i++ {
+		if err := <-errs; err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// warmOnce 方法发送一个预热请求
+func (t WarmupTarget) warmOnce(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, t.URL, nil)
+	if err != nil {
+		return err
+	}
+	if t.host != "" {
+		req.Host = t.host
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.Body.Close()
+}
@@ -0,0 +1,29 @@
+package client
+
+import (
+	"testing"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+)
+
+func TestWarmupTarget(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []NewNodeOption
+		metadata map[string]string
+		url      string
+		host     string
+	}{
+		{"http", nil, nil, "http://10.0.0.1/", ""},
+		{"tls", []NewNodeOption{WithTLS(true)}, nil, "https://10.0.0.1/", "10.0.0.1"},
+		{"host", []NewNodeOption{WithTLS(true)}, map[string]string{"host": "backend.internal"}, "https://10.0.0.1/", "backend.internal"},
+	}
+	for _, tt := range tests {
+		n := newNode(EmptyBuildContext(), "10.0.0.1", config.Protocol_HTTP, nil, tt.metadata, "", "", tt.opts...)
+		target := warmupTarget(n)
+		// 未指定端口时由节点客户端按方案选择默认端口
+		if target.URL != tt.url || target.host != tt.host || target.client != n.client {
+			t.Fatalf("%s: unexpected warmup target: %+v", tt.name, target)
+		}
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/cnsync/gateway/audit"
 	"github.com/cnsync/gateway/client"
 	"github.com/cnsync/gateway/config"
 	configLoader "github.com/cnsync/gateway/config/config-loader"
@@ -20,11 +22,27 @@ import (
 	_ "net/http/pprof"
 
 	_ "github.com/cnsync/gateway/discovery/consul"
+	_ "github.com/cnsync/gateway/discovery/etcd"
+	_ "github.com/cnsync/gateway/middleware/analytics"
+	_ "github.com/cnsync/gateway/middleware/apiversion"
+	_ "github.com/cnsync/gateway/middleware/attributes"
 	_ "github.com/cnsync/gateway/middleware/bbr"
+	_ "github.com/cnsync/gateway/middleware/cache"
+	_ "github.com/cnsync/gateway/middleware/canary"
+	_ "github.com/cnsync/gateway/middleware/capture"
+	_ "github.com/cnsync/gateway/middleware/checksum"
 	"github.com/cnsync/gateway/middleware/circuitbreaker"
+	_ "github.com/cnsync/gateway/middleware/cookie"
 	_ "github.com/cnsync/gateway/middleware/cors"
+	_ "github.com/cnsync/gateway/middleware/host"
 	_ "github.com/cnsync/gateway/middleware/logging"
+	_ "github.com/cnsync/gateway/middleware/mirror"
+	_ "github.com/cnsync/gateway/middleware/ratelimit"
+	_ "github.com/cnsync/gateway/middleware/replay"
+	_ "github.com/cnsync/gateway/middleware/requestid"
 	_ "github.com/cnsync/gateway/middleware/rewrite"
+	_ "github.com/cnsync/gateway/middleware/script"
+	_ "github.com/cnsync/gateway/middleware/signing"
 	_ "github.com/cnsync/gateway/middleware/tracing"
 	_ "github.com/cnsync/gateway/middleware/transcoder"
 	_ "go.uber.org/automaxprocs"
@@ -36,14 +54,28 @@ import (
 	"golang.org/x/exp/rand"
 )
 
+// 构建信息，构建时通过 -ldflags "-X main.Version=v1.0.0 -X main.BuildSHA=xxx -X main.BuildTime=xxx" 注入
+var (
+	Version   string
+	BuildSHA  string
+	BuildTime string
+)
+
 var (
 	ctrlName          string
 	ctrlService       string
 	discoveryDSN      string
+	discoveryRegister = newSliceVar()
 	proxyAddrs        = newSliceVar(":8080")
+	tlsAddrs          = newSliceVar()
+	tlsCert           string
+	tlsKey            string
+	tlsJA3Header      string
 	proxyConfig       string
 	priorityConfigDir string
 	withDebug         bool
+	debugDisabled     string
+	auditDSN          string
 )
 
 type sliceVar struct {
@@ -70,12 +102,19 @@ func init() {
 	rand.Seed(uint64(time.Now().Nanosecond()))
 
 	flag.BoolVar(&withDebug, "debug", false, "enable debug handlers")
+	flag.StringVar(&debugDisabled, "debug.disable", "", "disabled debug handler groups, eg: -debug.disable pprof,ctrl")
 	flag.Var(&proxyAddrs, "addr", "proxy address, eg: -addr 0.0.0.0:8080")
+	flag.Var(&tlsAddrs, "tls.addr", "tls proxy address, eg: -tls.addr 0.0.0.0:8443")
+	flag.StringVar(&tlsCert, "tls.cert", "", "tls certificate file or secret reference, eg: -tls.cert vault://secret/data/gateway#cert")
+	flag.StringVar(&tlsKey, "tls.key", "", "tls private key file or secret reference, eg: -tls.key /etc/gateway/tls.key")
+	flag.StringVar(&tlsJA3Header, "tls.ja3.header", "", "forward the JA3 fingerprint of tls clients to backends in this header, eg: -tls.ja3.header X-JA3-Fingerprint")
 	flag.StringVar(&proxyConfig, "conf", "config.yaml", "config path, eg: -conf config.yaml")
 	flag.StringVar(&priorityConfigDir, "conf.priority", "", "priority config directory, eg: -conf.priority ./canary")
 	flag.StringVar(&ctrlName, "ctrl.name", os.Getenv("ADVERTISE_NAME"), "control gateway name, eg: gateway")
 	flag.StringVar(&ctrlService, "ctrl.service", "", "control service host, eg: http://127.0.0.1:8000")
-	flag.StringVar(&discoveryDSN, "discovery.dsn", "", "discovery dsn, eg: consul://127.0.0.1:7070?token=secret&datacenter=prod")
+	flag.StringVar(&discoveryDSN, "discovery.dsn", "", "discovery dsn, eg: consul://127.0.0.1:7070?token=secret&datacenter=prod or etcd://127.0.0.1:2379?prefix=/services")
+	flag.Var(&discoveryRegister, "discovery.register", "register the gateway itself with this endpoint to discovery, deregistered on drain, eg: -discovery.register http://10.0.0.1:8080")
+	flag.StringVar(&auditDSN, "audit.dsn", "", "audit sink dsn, eg: file:///var/log/gateway/audit.log or http://127.0.0.1:8000/audit")
 }
 
 func makeDiscovery() registry.Discovery {
@@ -89,14 +128,49 @@ func makeDiscovery() registry.Discovery {
 	return d
 }
 
+// makeSelfRegistration 函数创建网关自身在服务发现中的注册，排空连接时注销，未配置时返回 nil
+func makeSelfRegistration(d registry.Discovery, name string) *discovery.SelfRegistration {
+	endpoints := discoveryRegister.Get()
+	if len(endpoints) == 0 {
+		return nil
+	}
+	if d == nil {
+		log.Fatalf("-discovery.register requires -discovery.dsn")
+	}
+	id, _ := os.Hostname()
+	self, err := discovery.NewSelfRegistration(d, &registry.ServiceInstance{
+		ID:        fmt.Sprintf("%s-%d", id, os.Getpid()),
+		Name:      name,
+		Version:   Version,
+		Endpoints: endpoints,
+	})
+	if err != nil {
+		log.Fatalf("failed to create self registration: %v", err)
+	}
+	server.OnDrain(self.Deregister)
+	return self
+}
+
 func main() {
 	flag.Parse()
+	proxy.SetBuildInfo(Version, BuildSHA, BuildTime)
 
-	clientFactory := client.NewFactory(makeDiscovery())
+	if auditDSN != "" {
+		auditLogger, err := audit.Init(auditDSN)
+		if err != nil {
+			log.Fatalf("failed to create audit logger: %v", err)
+		}
+		defer auditLogger.Close()
+	}
+
+	discoverer := makeDiscovery()
+	clientFactory := client.NewFactory(discoverer)
 	p, err := proxy.New(clientFactory, middleware.Create)
 	if err != nil {
 		log.Fatalf("failed to new proxy: %v", err)
 	}
+	// 开始排空连接时就绪探针返回未就绪
+	server.OnDrain(p.Drain)
 
 	ctx := context.Background()
 	var ctrlLoader *configLoader.CtrlConfigLoader
@@ -127,6 +201,8 @@ func main() {
 	if err := p.Update(buildContext, bc); err != nil {
 		log.Fatalf("failed to update service config: %v", err)
 	}
+	// 预热后端节点，预热完成之前就绪探针返回未就绪
+	p.Warmup(ctx)
 	reloader := func() error {
 		bc, err := confLoader.Load(context.Background())
 		if err != nil {
@@ -146,6 +222,9 @@ func main() {
 
 	var serverHandler http.Handler = p
 	if withDebug {
+		if debugDisabled != "" {
+			debug.Disable(strings.Split(debugDisabled, ",")...)
+		}
 		debug.Register("proxy", p)
 		debug.Register("config", confLoader)
 		if ctrlLoader != nil {
@@ -153,17 +232,53 @@ func main() {
 		}
 		serverHandler = debug.MashupWithDebugHandler(p)
 	}
-	servers := make([]transport.Server, 0, len(proxyAddrs.Get()))
-	for _, addr := range proxyAddrs.Get() {
-		servers = append(servers, server.NewProxy(serverHandler, addr))
+	// 删除客户端伪造的 JA3 指纹请求头，TLS 连接的请求设置为握手时计算的指纹
+	serverHandler = server.JA3Handler(serverHandler, tlsJA3Header)
+	// 所有监听器共用同一个处理程序，调试模式下可以通过 /debug/listeners 在运行时添加或移除监听器
+	listeners := server.NewListeners(serverHandler, proxyAddrs.Get()...)
+	if withDebug {
+		debug.Register("listeners", listeners)
+	}
+	servers := []transport.Server{listeners}
+	if addrs := tlsAddrs.Get(); len(addrs) > 0 {
+		tlsConfig, err := server.LoadTLSConfig(tlsCert, tlsKey)
+		if err != nil {
+			log.Fatalf("failed to load tls config: %v", err)
+		}
+		for _, addr := range addrs {
+			servers = append(servers, server.NewTLSProxy(serverHandler, addr, tlsConfig, tlsJA3Header != ""))
+		}
 	}
+	self := makeSelfRegistration(discoverer, bc.Name)
 	app := kratos.New(
 		kratos.Name(bc.Name),
 		kratos.Context(ctx),
-		kratos.Server(
-			servers...,
-		),
+		kratos.Server(servers...),
+		// 由父进程交接启动时，在开始处理请求之后通知父进程退出
+		kratos.AfterStart(func(context.Context) error {
+			server.NotifyHandoffReady()
+			return nil
+		}),
+		// 开始处理请求之后将网关自身注册到服务发现
+		kratos.AfterStart(func(ctx context.Context) error {
+			if self == nil {
+				return nil
+			}
+			if err := self.Register(ctx); err != nil {
+				log.Errorf("failed to register gateway to discovery: %v", err)
+			}
+			return nil
+		}),
+		// 停止之前开始排空连接，就绪探针返回未就绪并从服务发现注销网关自身，等待 PROXY_DRAIN_DELAY 之后再关闭监听器
+		kratos.BeforeStop(func(ctx context.Context) error {
+			if err := server.Drain(ctx); err != nil {
+				log.Errorf("failed to drain: %v", err)
+			}
+			return nil
+		}),
 	)
+	// 收到 SIGUSR2 时将监听的套接字交给新的网关进程，实现无停机重启
+	server.HandoffOnSignal(ctx, func() { _ = app.Stop() })
 	if err := app.Run(); err != nil {
 		log.Errorf("failed to run servers: %v", err)
 	}
@@ -134,6 +134,9 @@ func (f *FileLoader) Load(_ context.Context) (*configv1.Gateway, error) {
 	if err := f.mergePriorityConfig(out); err != nil {
 		log.Warnf("failed to merge priority config: %+v", err)
 	}
+	if err := ExpandEndpointTemplates(out); err != nil {
+		return nil, err
+	}
 	return out, nil
 }
 
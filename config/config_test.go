@@ -2,7 +2,10 @@ package config
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	configv1 "github.com/cnsync/gateway/api/gateway/config/v1"
 	corsv1 "github.com/cnsync/gateway/api/gateway/middleware/cors/v1"
@@ -104,3 +107,73 @@ func TestFileLoader(t *testing.T) {
 		t.Errorf("inconsistent gateway config")
 	}
 }
+
+func TestEndpointTemplates(t *testing.T) {
+	confPath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(confPath, []byte(`
+name: templates
+endpoint_templates:
+  user-service:
+    protocol: HTTP
+    timeout: 1s
+    metadata:
+      service: users
+    middlewares:
+      - name: logging
+    retry:
+      attempts: 3
+    backends:
+      - target: 127.0.0.1:8000
+endpoints:
+  - path: /users
+    method: GET
+    template: user-service
+  - path: /users/*
+    method: POST
+    template: user-service
+    timeout: 5s
+    metadata:
+      basePath: /users
+    middlewares:
+      - name: requestid
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := (&FileLoader{confPath: confPath}).Load(context.TODO())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*configv1.Endpoint{{
+		Path:        "/users",
+		Method:      "GET",
+		Protocol:    configv1.Protocol_HTTP,
+		Timeout:     durationpb.New(time.Second),
+		Metadata:    map[string]string{"service": "users"},
+		Middlewares: []*configv1.Middleware{{Name: "logging"}},
+		Retry:       &configv1.Retry{Attempts: 3},
+		Backends:    []*configv1.Backend{{Target: "127.0.0.1:8000"}},
+	}, {
+		Path:        "/users/*",
+		Method:      "POST",
+		Protocol:    configv1.Protocol_HTTP,
+		Timeout:     durationpb.New(5 * time.Second),
+		Metadata:    map[string]string{"service": "users", "basePath": "/users"},
+		Middlewares: []*configv1.Middleware{{Name: "logging"}, {Name: "requestid"}},
+		Retry:       &configv1.Retry{Attempts: 3},
+		Backends:    []*configv1.Backend{{Target: "127.0.0.1:8000"}},
+	}}
+	if len(cfg.Endpoints) != len(want) {
+		t.Fatalf("want %d endpoints but got %d", len(want), len(cfg.Endpoints))
+	}
+	for i, e := range cfg.Endpoints {
+		if !proto.Equal(e, want[i]) {
+			t.Errorf("endpoint %d:\nwant: %v\ngot:  %v", i, want[i], e)
+		}
+	}
+
+	if err := ExpandEndpointTemplates(&configv1.Gateway{
+		Endpoints: []*configv1.Endpoint{{Path: "/foo", Template: "missing"}},
+	}); err == nil {
+		t.Fatal("want error for an unknown template")
+	}
+}
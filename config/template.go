@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+
+	configv1 "github.com/cnsync/gateway/api/gateway/config/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// ExpandEndpointTemplates 将引用了端点模板的端点展开为完整的端点，端点上设置的字段覆盖模板中的字段，
+// 列表字段追加在模板之后，引用不存在的模板时返回错误
+func ExpandEndpointTemplates(c *configv1.Gateway) error {
+	for i, e := range c.Endpoints {
+		expanded, err := expandEndpoint(c.EndpointTemplates, e)
+		if err != nil {
+			return err
+		}
+		c.Endpoints[i] = expanded
+	}
+	for _, tenant := range c.TenantRouting.GetTenants() {
+		for i, e := range tenant.GetEndpoints() {
+			expanded, err := expandEndpoint(c.EndpointTemplates, e)
+			if err != nil {
+				return err
+			}
+			tenant.Endpoints[i] = expanded
+		}
+	}
+	for _, vh := range c.VirtualHosts {
+		for i, e := range vh.Endpoints {
+			expanded, err := expandEndpoint(c.EndpointTemplates, e)
+			if err != nil {
+				return err
+			}
+			vh.Endpoints[i] = expanded
+		}
+	}
+	if c.DefaultEndpoint != nil {
+		expanded, err := expandEndpoint(c.EndpointTemplates, c.DefaultEndpoint)
+		if err != nil {
+			return err
+		}
+		c.DefaultEndpoint = expanded
+	}
+	return nil
+}
+
+// expandEndpoint 函数将模板与端点合并，端点未引用模板时原样返回
+func expandEndpoint(templates map[string]*configv1.Endpoint, e *configv1.Endpoint) (*configv1.Endpoint, error) {
+	if e.Template == "" {
+		return e, nil
+	}
+	tmpl, ok := templates[e.Template]
+	if !ok {
+		return nil, fmt.Errorf("endpoint %s %s: unknown template %q", e.Method, e.Path, e.Template)
+	}
+	out := proto.Clone(tmpl).(*configv1.Endpoint)
+	proto.Merge(out, e)
+	// 模板只展开一层，展开后的端点不再引用模板
+	out.Template = ""
+	return out, nil
+}
@@ -0,0 +1,242 @@
+package etcd
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cnsync/gateway/discovery"
+	"github.com/cnsync/kratos/log"
+	"github.com/cnsync/kratos/registry"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const (
+	// _defaultPrefix 是默认存放服务实例的键前缀
+	_defaultPrefix = "/microservices"
+	// _defaultDialTimeout 是默认连接 etcd 的超时时间
+	_defaultDialTimeout = 5 * time.Second
+	// _leaseTTL 是注册实例的租约时长，网关异常退出时实例在租约到期后被删除
+	_leaseTTL = 15
+)
+
+func init() {
+	discovery.Register("etcd", New)
+}
+
+// newConfig 函数根据 DSN 创建 etcd 客户端的配置并返回键前缀，
+// 例如 etcd://127.0.0.1:2379,127.0.0.2:2379?prefix=/services&username=gateway&password=secret&ca_file=ca.pem
+func newConfig(dsn *url.URL) (clientv3.Config, string, error) {
+	query := dsn.Query()
+	c := clientv3.Config{
+		Endpoints:   strings.Split(dsn.Host, ","),
+		DialTimeout: _defaultDialTimeout,
+		Username:    query.Get("username"),
+		Password:    query.Get("password"),
+	}
+	if dsn.Host == "" {
+		return c, "", fmt.Errorf("etcd: no endpoints in dsn")
+	}
+	if v := query.Get("dial_timeout"); v != "" {
+		timeout, err := time.ParseDuration(v)
+		if err != nil {
+			return c, "", fmt.Errorf("etcd: invalid dial_timeout: %w", err)
+		}
+		c.DialTimeout = timeout
+	}
+	tlsConfig, err := newTLSConfig(query.Get("cert_file"), query.Get("key_file"), query.Get("ca_file"))
+	if err != nil {
+		return c, "", err
+	}
+	c.TLS = tlsConfig
+	prefix := _defaultPrefix
+	if v := query.Get("prefix"); v != "" {
+		prefix = path.Clean("/" + v)
+	}
+	return c, prefix, nil
+}
+
+// newTLSConfig 函数根据证书文件创建 TLS 配置，没有指定任何文件时返回 nil，不使用 TLS
+func newTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	if certFile == "" && keyFile == "" && caFile == "" {
+		return nil, nil
+	}
+	c := &tls.Config{MinVersion: tls.VersionTLS12}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("etcd: load client certificate: %w", err)
+		}
+		c.Certificates = []tls.Certificate{cert}
+	}
+	if caFile != "" {
+		ca, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("etcd: read ca file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("etcd: no certificates in ca file: %s", caFile)
+		}
+		c.RootCAs = pool
+	}
+	return c, nil
+}
+
+// New 函数根据 DSN 创建一个基于 etcd 的服务发现，实例以 JSON 存放在 {prefix}/{name}/{id} 键中
+func New(dsn *url.URL) (registry.Discovery, error) {
+	c, prefix, err := newConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	client, err := clientv3.New(c)
+	if err != nil {
+		return nil, err
+	}
+	return &Registry{client: client, prefix: prefix, leases: map[string]clientv3.LeaseID{}}, nil
+}
+
+// Registry 结构体实现了基于 etcd 的服务发现和注册
+type Registry struct {
+	client *clientv3.Client
+	prefix string
+
+	lock   sync.Mutex
+	leases map[string]clientv3.LeaseID
+}
+
+// serviceKey 方法返回服务的所有实例所在的键前缀
+func (r *Registry) serviceKey(name string) string {
+	return r.prefix + "/" + name + "/"
+}
+
+// instanceKey 方法返回实例所在的键
+func (r *Registry) instanceKey(service *registry.ServiceInstance) string {
+	return r.serviceKey(service.Name) + service.ID
+}
+
+// GetService 方法返回服务当前的所有实例，无法解析的实例被忽略
+func (r *Registry) GetService(ctx context.Context, name string) ([]*registry.ServiceInstance, error) {
+	resp, err := r.client.Get(ctx, r.serviceKey(name), clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+	instances := make([]*registry.ServiceInstance, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		instance := &registry.ServiceInstance{}
+		if err := json.Unmarshal(kv.Value, instance); err != nil {
+			log.Warnf("etcd: failed to unmarshal instance %s: %v", kv.Key, err)
+			continue
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+// Watch 方法监听服务的实例变化
+func (r *Registry) Watch(ctx context.Context, name string) (registry.Watcher, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &watcher{
+		registry: r,
+		name:     name,
+		ctx:      ctx,
+		cancel:   cancel,
+		events:   r.client.Watch(ctx, r.serviceKey(name), clientv3.WithPrefix()),
+	}, nil
+}
+
+// Register 方法以租约注册一个实例，并在后台续约直到实例被注销
+func (r *Registry) Register(ctx context.Context, service *registry.ServiceInstance) error {
+	value, err := json.Marshal(service)
+	if err != nil {
+		return err
+	}
+	lease, err := r.client.Grant(ctx, _leaseTTL)
+	if err != nil {
+		return err
+	}
+	if _, err := r.client.Put(ctx, r.instanceKey(service), string(value), clientv3.WithLease(lease.ID)); err != nil {
+		return err
+	}
+	keepAlive, err := r.client.KeepAlive(context.Background(), lease.ID)
+	if err != nil {
+		return err
+	}
+	go func() {
+		for range keepAlive {
+		}
+	}()
+	r.lock.Lock()
+	r.leases[r.instanceKey(service)] = lease.ID
+	r.lock.Unlock()
+	return nil
+}
+
+// Deregister 方法删除一个实例并撤销它的租约，续约随之停止
+func (r *Registry) Deregister(ctx context.Context, service *registry.ServiceInstance) error {
+	key := r.instanceKey(service)
+	r.lock.Lock()
+	lease, ok := r.leases[key]
+	delete(r.leases, key)
+	r.lock.Unlock()
+	if _, err := r.client.Delete(ctx, key); err != nil {
+		return err
+	}
+	if ok {
+		if _, err := r.client.Revoke(ctx, lease); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// watcher 结构体监听一个服务的实例，第一次调用 Next 返回当前的实例，
+// 之后每次实例变化时返回变化后完整的实例列表
+type watcher struct {
+	registry *Registry
+	name     string
+	ctx      context.Context
+	cancel   context.CancelFunc
+	events   clientv3.WatchChan
+	started  bool
+}
+
+// Next 方法返回服务完整的实例列表，没有变化时阻塞
+func (w *watcher) Next() ([]*registry.ServiceInstance, error) {
+	if w.started {
+		select {
+		case resp, ok := <-w.events:
+			if !ok {
+				if err := w.ctx.Err(); err != nil {
+					return nil, err
+				}
+				return nil, fmt.Errorf("etcd: watch of service %s closed", w.name)
+			}
+			if err := resp.Err(); err != nil {
+				return nil, err
+			}
+		case <-w.ctx.Done():
+			return nil, w.ctx.Err()
+		}
+	}
+	instances, err := w.registry.GetService(w.ctx, w.name)
+	if err != nil {
+		return nil, err
+	}
+	w.started = true
+	return instances, nil
+}
+
+// Stop 方法停止监听
+func (w *watcher) Stop() error {
+	w.cancel()
+	return nil
+}
@@ -0,0 +1,42 @@
+package etcd
+
+import (
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestNewConfig(t *testing.T) {
+	dsn, err := url.Parse("etcd://127.0.0.1:2379,127.0.0.2:2379?prefix=services/&username=gateway&password=secret&dial_timeout=1s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, prefix, err := newConfig(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.Endpoints) != 2 || c.Endpoints[0] != "127.0.0.1:2379" || c.Endpoints[1] != "127.0.0.2:2379" {
+		t.Fatalf("unexpected endpoints: %v", c.Endpoints)
+	}
+	if c.Username != "gateway" || c.Password != "secret" || c.DialTimeout != time.Second || c.TLS != nil {
+		t.Fatalf("unexpected config: %+v", c)
+	}
+	if prefix != "/services" {
+		t.Fatalf("want prefix /services but got %s", prefix)
+	}
+
+	for _, raw := range []string{
+		"etcd://",
+		"etcd://127.0.0.1:2379?dial_timeout=1",
+		"etcd://127.0.0.1:2379?ca_file=/nonexistent/ca.pem",
+		"etcd://127.0.0.1:2379?cert_file=/nonexistent/cert.pem",
+	} {
+		dsn, err := url.Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := newConfig(dsn); err == nil {
+			t.Fatalf("want error for dsn %s", raw)
+		}
+	}
+}
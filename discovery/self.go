@@ -0,0 +1,58 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/cnsync/kratos/log"
+	"github.com/cnsync/kratos/registry"
+)
+
+// SelfRegistration 将网关自身的实例注册到服务发现，网关开始排空连接或停止时注销，
+// 使依赖服务发现的上游负载均衡器不再把请求路由到即将退出的网关
+type SelfRegistration struct {
+	lock       sync.Mutex
+	registrar  registry.Registrar
+	instance   *registry.ServiceInstance
+	registered bool
+}
+
+// NewSelfRegistration 函数创建网关自身的注册，服务发现不支持注册实例时返回错误
+func NewSelfRegistration(d registry.Discovery, instance *registry.ServiceInstance) (*SelfRegistration, error) {
+	registrar, ok := d.(registry.Registrar)
+	if !ok {
+		return nil, fmt.Errorf("discovery %T does not support registering instances", d)
+	}
+	return &SelfRegistration{registrar: registrar, instance: instance}, nil
+}
+
+// Register 方法注册网关自身的实例，已经注册时不重复注册
+func (s *SelfRegistration) Register(ctx context.Context) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.registered {
+		return nil
+	}
+	if err := s.registrar.Register(ctx, s.instance); err != nil {
+		return err
+	}
+	s.registered = true
+	log.Infof("registered gateway instance %s: %v", s.instance, s.instance.Endpoints)
+	return nil
+}
+
+// Deregister 方法注销网关自身的实例，排空和停止都会调用它，未注册或已经注销时直接返回
+func (s *SelfRegistration) Deregister(ctx context.Context) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if !s.registered {
+		return nil
+	}
+	if err := s.registrar.Deregister(ctx, s.instance); err != nil {
+		return err
+	}
+	s.registered = false
+	log.Infof("deregistered gateway instance %s", s.instance)
+	return nil
+}
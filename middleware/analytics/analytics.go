@@ -0,0 +1,240 @@
+package analytics
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/analytics/v1"
+	"github.com/cnsync/gateway/middleware"
+	"github.com/cnsync/kratos/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const (
+	// _defaultBufferSize 是默认的事件缓冲队列长度
+	_defaultBufferSize = 1024
+	// _defaultBatchSize 是默认的每批发布的事件数量
+	_defaultBatchSize = 100
+	// _defaultFlushInterval 是默认的发布未满批次的间隔
+	_defaultFlushInterval = time.Second
+)
+
+// _metricDroppedEvents 统计了因缓冲队列已满而丢弃的分析事件数量
+var _metricDroppedEvents = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "analytics_dropped_events_total",
+	Help:      "The total number of dropped analytics events",
+})
+
+// 包初始化时注册 analytics 中间件
+func init() {
+	prometheus.MustRegister(_metricDroppedEvents)
+	middleware.RegisterV2("analytics", Middleware)
+}
+
+// Event 结构体定义了一条请求的分析事件，不包含请求体和响应体
+type Event struct {
+	// Time 是请求开始的时间
+	Time time.Time `json:"time"`
+	// Endpoint 是匹配到的端点路径模板
+	Endpoint string `json:"endpoint"`
+	// Protocol 是端点的协议
+	Protocol string `json:"protocol"`
+	// Method 是请求方法
+	Method string `json:"method"`
+	// Host 是请求的主机
+	Host string `json:"host"`
+	// Path 是请求的具体路径
+	Path string `json:"path"`
+	// Status 是响应的状态码，请求失败时为 0
+	Status int `json:"status"`
+	// LatencyMs 是请求的耗时，单位为毫秒
+	LatencyMs float64 `json:"latency_ms"`
+	// Error 是请求失败的原因
+	Error string `json:"error,omitempty"`
+	// RequestHeaders 是选定的请求头
+	RequestHeaders map[string]string `json:"request_headers,omitempty"`
+	// ResponseHeaders 是选定的响应头
+	ResponseHeaders map[string]string `json:"response_headers,omitempty"`
+}
+
+// Publisher 结构体将分析事件缓冲后分批异步地发布到输出目标，缓冲队列已满时丢弃事件，不会阻塞请求
+type Publisher struct {
+	sink          Sink
+	batchSize     int
+	flushInterval time.Duration
+	events        chan *Event
+	done          chan struct{}
+	closed        chan struct{}
+	closeOnce     sync.Once
+}
+
+// NewPublisher 创建一个新的 Publisher 实例
+func NewPublisher(sink Sink, bufferSize, batchSize int, flushInterval time.Duration) *Publisher {
+	if bufferSize <= 0 {
+		bufferSize = _defaultBufferSize
+	}
+	if batchSize <= 0 {
+		batchSize = _defaultBatchSize
+	}
+	if flushInterval <= 0 {
+		flushInterval = _defaultFlushInterval
+	}
+	p := &Publisher{
+		sink:          sink,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		events:        make(chan *Event, bufferSize),
+		done:          make(chan struct{}),
+		closed:        make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+// run 方法持续地从队列中取出事件，凑满一批或到达发布间隔时发布
+func (p *Publisher) run() {
+	defer close(p.closed)
+	ticker := time.NewTicker(p.flushInterval)
+	defer ticker.Stop()
+	batch := make([]*Event, 0, p.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		p.publish(batch)
+		batch = make([]*Event, 0, p.batchSize)
+	}
+	for {
+		select {
+		case e := <-p.events:
+			batch = append(batch, e)
+			if len(batch) >= p.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-p.done:
+			// 关闭前发布队列中剩余的事件
+			for {
+				select {
+				case e := <-p.events:
+					batch = append(batch, e)
+					if len(batch) >= p.batchSize {
+						flush()
+					}
+				default:
+					flush()
+					if err := p.sink.Close(); err != nil {
+						log.Errorf("failed to close analytics sink: %v", err)
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+// publish 方法发布一批事件，发布失败时只记录日志
+func (p *Publisher) publish(batch []*Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), _publishTimeout)
+	defer cancel()
+	if err := p.sink.Publish(ctx, batch); err != nil {
+		log.Errorf("failed to publish %d analytics events: %v", len(batch), err)
+	}
+}
+
+// Emit 方法提交一条事件，队列已满或已关闭时丢弃该事件
+func (p *Publisher) Emit(e *Event) {
+	select {
+	case <-p.done:
+		return
+	default:
+	}
+	select {
+	case p.events <- e:
+	default:
+		_metricDroppedEvents.Inc()
+	}
+}
+
+// Close 方法停止接收新的事件，并等待已提交的事件发布完成
+func (p *Publisher) Close() error {
+	p.closeOnce.Do(func() { close(p.done) })
+	<-p.closed
+	return nil
+}
+
+// selectHeaders 函数返回选定的头部，不存在的头部不包含在结果中
+func selectHeaders(header http.Header, names []string) map[string]string {
+	if len(names) == 0 || header == nil {
+		return nil
+	}
+	out := make(map[string]string, len(names))
+	for _, name := range names {
+		if v := header.Get(name); v != "" {
+			out[http.CanonicalHeaderKey(name)] = v
+		}
+	}
+	return out
+}
+
+// Middleware 函数根据传入的配置对象 c 创建一个分析中间件实例，按采样率将请求的元数据异步发布到输出目标
+func Middleware(c *config.Middleware) (middleware.MiddlewareV2, error) {
+	options := &v1.Analytics{}
+	if c.Options != nil {
+		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+	}
+	sink, err := Create(options.Sink)
+	if err != nil {
+		return nil, err
+	}
+	publisher := NewPublisher(sink, int(options.BufferSize), int(options.BatchSize), options.FlushInterval.AsDuration())
+	return newMiddleware(options, publisher), nil
+}
+
+// newMiddleware 函数使用给定的 Publisher 创建分析中间件实例
+func newMiddleware(options *v1.Analytics, publisher *Publisher) middleware.MiddlewareV2 {
+	sampleRate := options.SampleRate
+	if sampleRate <= 0 || sampleRate > 1 {
+		sampleRate = 1
+	}
+	return middleware.NewWithCloser(func(next http.RoundTripper) http.RoundTripper {
+		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if sampleRate < 1 && rand.Float64() >= sampleRate {
+				return next.RoundTrip(req)
+			}
+			startTime := time.Now()
+			resp, err := next.RoundTrip(req)
+			e := &Event{
+				Time:           startTime,
+				Method:         req.Method,
+				Host:           req.Host,
+				Path:           req.URL.Path,
+				LatencyMs:      float64(time.Since(startTime).Microseconds()) / 1000,
+				RequestHeaders: selectHeaders(req.Header, options.RequestHeaders),
+			}
+			if reqOpts, ok := middleware.FromRequestContext(req.Context()); ok {
+				e.Endpoint = reqOpts.Endpoint.Path
+				e.Protocol = reqOpts.Endpoint.Protocol.String()
+			}
+			if err != nil {
+				e.Error = err.Error()
+			} else {
+				e.Status = resp.StatusCode
+				e.ResponseHeaders = selectHeaders(resp.Header, options.ResponseHeaders)
+			}
+			publisher.Emit(e)
+			return resp, err
+		})
+	}, publisher)
+}
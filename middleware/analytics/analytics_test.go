@@ -0,0 +1,174 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/analytics/v1"
+	"github.com/cnsync/gateway/middleware"
+)
+
+type memorySink struct {
+	lock    sync.Mutex
+	events  []*Event
+	batches int
+	block   chan struct{}
+}
+
+func (s *memorySink) Publish(_ context.Context, events []*Event) error {
+	if s.block != nil {
+		<-s.block
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.events = append(s.events, events...)
+	s.batches++
+	return nil
+}
+
+func (s *memorySink) Close() error { return nil }
+
+func newRequest(path string) *http.Request {
+	req := httptest.NewRequest("GET", path, nil)
+	req.Header.Set("User-Agent", "analytics-test")
+	req.Header.Set("Authorization", "secret")
+	reqOpts := middleware.NewRequestOptions(&config.Endpoint{Path: "/api/users/{id}", Protocol: config.Protocol_HTTP})
+	return req.WithContext(middleware.NewRequestContext(req.Context(), reqOpts))
+}
+
+func TestAnalytics(t *testing.T) {
+	sink := &memorySink{}
+	m := newMiddleware(&v1.Analytics{
+		RequestHeaders:  []string{"user-agent"},
+		ResponseHeaders: []string{"X-Cache"},
+	}, NewPublisher(sink, 0, 10, time.Hour))
+	next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		time.Sleep(10 * time.Millisecond)
+		return &http.Response{StatusCode: http.StatusCreated, Header: http.Header{"X-Cache": []string{"MISS"}}}, nil
+	})
+	if _, err := m.Process(next).RoundTrip(newRequest("/api/users/42")); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sink.events) != 1 {
+		t.Fatalf("want 1 event but got %d", len(sink.events))
+	}
+	e := sink.events[0]
+	if e.Endpoint != "/api/users/{id}" || e.Path != "/api/users/42" || e.Method != "GET" || e.Protocol != "HTTP" || e.Status != http.StatusCreated {
+		t.Fatalf("unexpected event: %+v", e)
+	}
+	if e.LatencyMs < 10 {
+		t.Fatalf("want latency >= 10ms but got %v", e.LatencyMs)
+	}
+	if len(e.RequestHeaders) != 1 || e.RequestHeaders["User-Agent"] != "analytics-test" {
+		t.Fatalf("unexpected request headers: %v", e.RequestHeaders)
+	}
+	if len(e.ResponseHeaders) != 1 || e.ResponseHeaders["X-Cache"] != "MISS" {
+		t.Fatalf("unexpected response headers: %v", e.ResponseHeaders)
+	}
+}
+
+func TestAnalyticsSampling(t *testing.T) {
+	next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+	tests := []struct {
+		rate     float64
+		min, max int
+	}{
+		{0, 1000, 1000},
+		{1, 1000, 1000},
+		{0.5, 350, 650},
+		{0.01, 0, 50},
+	}
+	for _, tt := range tests {
+		sink := &memorySink{}
+		m := newMiddleware(&v1.Analytics{SampleRate: tt.rate}, NewPublisher(sink, 1000, 100, time.Hour))
+		rt := m.Process(next)
+		for i := 0; i < 1000; i++ {
+			if _, err := rt.RoundTrip(newRequest("/api/users/42")); err != nil {
+				t.Fatal(err)
+			}
+		}
+		_ = m.Close()
+		if n := len(sink.events); n < tt.min || n > tt.max {
+			t.Fatalf("sample rate %v: want %d-%d events but got %d", tt.rate, tt.min, tt.max, n)
+		}
+	}
+}
+
+func TestAnalyticsNonBlocking(t *testing.T) {
+	sink := &memorySink{block: make(chan struct{})}
+	m := newMiddleware(&v1.Analytics{}, NewPublisher(sink, 2, 1, time.Hour))
+	next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		rt := m.Process(next)
+		for i := 0; i < 100; i++ {
+			_, _ = rt.RoundTrip(newRequest("/api/users/42"))
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("requests were blocked by the analytics sink")
+	}
+	close(sink.block)
+	_ = m.Close()
+	if len(sink.events) >= 100 {
+		t.Fatalf("want events dropped but got %d", len(sink.events))
+	}
+}
+
+func TestKafkaRESTSink(t *testing.T) {
+	var (
+		path        string
+		contentType string
+		body        []byte
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, contentType = r.URL.Path, r.Header.Get("Content-Type")
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	sink, err := Create("kafka-rest://" + strings.TrimPrefix(srv.URL, "http://") + "/gateway-requests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	if err := sink.Publish(context.Background(), []*Event{{Endpoint: "/api", Status: 200}}); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/topics/gateway-requests" || contentType != "application/vnd.kafka.json.v2+json" {
+		t.Fatalf("unexpected request: %s %s", path, contentType)
+	}
+	out := struct {
+		Records []struct {
+			Value Event `json:"value"`
+		} `json:"records"`
+	}{}
+	if err := json.Unmarshal(body, &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Records) != 1 || out.Records[0].Value.Endpoint != "/api" || out.Records[0].Value.Status != 200 {
+		t.Fatalf("unexpected body: %s", body)
+	}
+	if _, err := Create("kafka-rest://127.0.0.1:8082"); err == nil {
+		t.Fatal("want error for empty topic")
+	}
+}
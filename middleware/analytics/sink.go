@@ -0,0 +1,134 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// _publishTimeout 是发布一批事件的超时时间
+const _publishTimeout = 5 * time.Second
+
+// Sink 接口定义了分析事件的输出目标
+type Sink interface {
+	// Publish 方法发布一批事件
+	Publish(ctx context.Context, events []*Event) error
+	// Close 方法关闭输出目标
+	Close() error
+}
+
+// Factory 是一个工厂函数，用于根据 DSN 创建分析事件的输出目标
+type Factory func(dsn *url.URL) (Sink, error)
+
+// globalFactories 保存了所有已注册的输出目标工厂
+var globalFactories = map[string]Factory{}
+
+// Register 注册一个输出目标工厂，其他消息系统（例如 Kafka 原生客户端）可以通过它接入
+func Register(name string, factory Factory) {
+	globalFactories[name] = factory
+}
+
+func init() {
+	Register("http", newWebhookSink)
+	Register("https", newWebhookSink)
+	Register("kafka-rest", newKafkaRESTSink)
+}
+
+// Create 根据给定的 DSN 创建一个输出目标实例
+func Create(sinkDSN string) (Sink, error) {
+	if sinkDSN == "" {
+		return nil, fmt.Errorf("sinkDSN is empty")
+	}
+	dsn, err := url.Parse(sinkDSN)
+	if err != nil {
+		return nil, fmt.Errorf("parse sinkDSN error: %s", err)
+	}
+	factory, ok := globalFactories[dsn.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("analytics sink %s has not been registered", dsn.Scheme)
+	}
+	return factory(dsn)
+}
+
+// httpSink 结构体将一批事件以 JSON 的格式 POST 到指定的地址
+type httpSink struct {
+	url         string
+	contentType string
+	client      *http.Client
+	// encode 将一批事件编码为请求体
+	encode func([]*Event) ([]byte, error)
+}
+
+// newWebhookSink 根据 http(s)://host/path 格式的 DSN 创建一个 webhook 输出目标，请求体是事件的 JSON 数组
+func newWebhookSink(dsn *url.URL) (Sink, error) {
+	return &httpSink{
+		url:         dsn.String(),
+		contentType: "application/json",
+		client:      &http.Client{Timeout: _publishTimeout},
+		encode: func(events []*Event) ([]byte, error) {
+			return json.Marshal(events)
+		},
+	}, nil
+}
+
+// newKafkaRESTSink 根据 kafka-rest://host:port/topic 格式的 DSN 创建一个通过 Kafka REST Proxy 发布到 Kafka 主题的输出目标，
+// 指定 ?tls=true 时使用 https 访问 REST Proxy
+func newKafkaRESTSink(dsn *url.URL) (Sink, error) {
+	topic := strings.Trim(dsn.Path, "/")
+	if topic == "" {
+		return nil, fmt.Errorf("kafka topic is empty")
+	}
+	scheme := "http"
+	if dsn.Query().Get("tls") == "true" {
+		scheme = "https"
+	}
+	target := url.URL{Scheme: scheme, Host: dsn.Host, Path: "/topics/" + topic}
+	return &httpSink{
+		url:         target.String(),
+		contentType: "application/vnd.kafka.json.v2+json",
+		client:      &http.Client{Timeout: _publishTimeout},
+		encode: func(events []*Event) ([]byte, error) {
+			type record struct {
+				Value *Event `json:"value"`
+			}
+			records := make([]record, 0, len(events))
+			for _, e := range events {
+				records = append(records, record{Value: e})
+			}
+			return json.Marshal(map[string]interface{}{"records": records})
+		},
+	}, nil
+}
+
+// Publish 方法发布一批事件
+func (s *httpSink) Publish(ctx context.Context, events []*Event) error {
+	b, err := s.encode(events)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", s.contentType)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected analytics sink status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close 方法关闭空闲连接
+func (s *httpSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,107 @@
+package apiversion
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/apiversion/v1"
+	"github.com/cnsync/gateway/middleware"
+	"github.com/cnsync/kratos/selector"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// _defaultHeaders 是默认携带 API 版本的请求头
+var _defaultHeaders = []string{"Accept-Version", "X-API-Version"}
+
+// 包初始化时注册 apiversion 中间件
+func init() {
+	middleware.Register("apiversion", Middleware)
+}
+
+// requestVersion 函数返回请求头中的 API 版本，按配置的顺序使用第一个非空的请求头
+func requestVersion(req *http.Request, headers []string) string {
+	for _, name := range headers {
+		if v := strings.TrimSpace(req.Header.Get(name)); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// versionFilter 函数返回一个只保留带有版本所有元数据的节点的过滤器，没有这样的节点时不选择任何节点，
+// 避免请求被发往不兼容的版本
+func versionFilter(md map[string]string) selector.NodeFilter {
+	return func(_ context.Context, nodes []selector.Node) []selector.Node {
+		filtered := make([]selector.Node, 0, len(nodes))
+	NODES:
+		for _, n := range nodes {
+			nmd := n.Metadata()
+			for k, v := range md {
+				if nmd[k] != v {
+					continue NODES
+				}
+			}
+			filtered = append(filtered, n)
+		}
+		return filtered
+	}
+}
+
+// Middleware 函数根据传入的配置对象 c 创建一个 API 版本中间件实例，
+// 按请求头中的 API 版本将请求路由到对应版本的后端节点，并按版本改写请求路径和请求头
+func Middleware(c *config.Middleware) (middleware.Middleware, error) {
+	options := &v1.APIVersion{}
+	if c.Options != nil {
+		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+	}
+	if len(options.Versions) == 0 {
+		return nil, fmt.Errorf("apiversion: versions is required")
+	}
+	if _, ok := options.Versions[options.DefaultVersion]; options.DefaultVersion != "" && !ok {
+		return nil, fmt.Errorf("apiversion: unknown default version: %s", options.DefaultVersion)
+	}
+	headers := options.Headers
+	if len(headers) == 0 {
+		headers = _defaultHeaders
+	}
+	reject := middleware.NewRejectHandler(options.UnknownVersion, http.StatusBadRequest)
+	return func(next http.RoundTripper) http.RoundTripper {
+		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			name := requestVersion(req, headers)
+			if name == "" {
+				name = options.DefaultVersion
+				// 没有版本且未配置默认版本的请求原样转发
+				if name == "" {
+					return next.RoundTrip(req)
+				}
+			}
+			version, ok := options.Versions[name]
+			if !ok {
+				if options.DefaultVersion == "" {
+					return reject.RoundTrip(req)
+				}
+				version = options.Versions[options.DefaultVersion]
+			}
+			if version.PathPrefix != "" {
+				req.URL.Path = strings.TrimSuffix(version.PathPrefix, "/") + req.URL.Path
+				if req.URL.RawPath != "" {
+					req.URL.RawPath = strings.TrimSuffix(version.PathPrefix, "/") + req.URL.RawPath
+				}
+			}
+			for k, v := range version.RequestHeaders {
+				req.Header.Set(k, v)
+			}
+			if len(version.Metadata) > 0 {
+				ctx := middleware.WithSelectorFitler(req.Context(), versionFilter(version.Metadata))
+				req = req.WithContext(ctx)
+			}
+			return next.RoundTrip(req)
+		})
+	}, nil
+}
@@ -0,0 +1,110 @@
+package apiversion
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/apiversion/v1"
+	"github.com/cnsync/gateway/middleware"
+	"github.com/cnsync/kratos/registry"
+	"github.com/cnsync/kratos/selector"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func newMiddleware(t *testing.T, c *v1.APIVersion) middleware.Middleware {
+	options, err := anypb.New(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Middleware(&config.Middleware{Options: options})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestAPIVersionRouting(t *testing.T) {
+	versions := map[string]*v1.Version{
+		"v1": {Metadata: map[string]string{"version": "v1"}},
+		"v2": {
+			Metadata:       map[string]string{"version": "v2"},
+			PathPrefix:     "/v2",
+			RequestHeaders: map[string]string{"X-Backend-Api": "2"},
+		},
+	}
+	nodes := []selector.Node{
+		selector.NewNode("http", "10.0.0.1:8000", &registry.ServiceInstance{Metadata: map[string]string{"version": "v1"}}),
+		selector.NewNode("http", "10.0.0.2:8000", &registry.ServiceInstance{Metadata: map[string]string{"version": "v2"}}),
+	}
+	endpoint := &config.Endpoint{Path: "/users", Protocol: config.Protocol_HTTP}
+
+	tests := []struct {
+		name           string
+		defaultVersion string
+		header         string
+		version        string
+		wantStatus     int
+		wantPath       string
+		wantNode       string
+	}{
+		{name: "v1", header: "Accept-Version", version: "v1", wantStatus: 200, wantPath: "/users", wantNode: "10.0.0.1:8000"},
+		{name: "v2", header: "X-API-Version", version: "v2", wantStatus: 200, wantPath: "/v2/users", wantNode: "10.0.0.2:8000"},
+		{name: "no version", wantStatus: 200, wantPath: "/users"},
+		{name: "no version with default", defaultVersion: "v2", wantStatus: 200, wantPath: "/v2/users", wantNode: "10.0.0.2:8000"},
+		{name: "unknown", header: "Accept-Version", version: "v9", wantStatus: 400},
+		{name: "unknown with default", defaultVersion: "v1", header: "Accept-Version", version: "v9", wantStatus: 200, wantPath: "/users", wantNode: "10.0.0.1:8000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMiddleware(t, &v1.APIVersion{Versions: versions, DefaultVersion: tt.defaultVersion})
+			var (
+				path     string
+				selected []selector.Node
+				header   string
+			)
+			next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				path = req.URL.Path
+				header = req.Header.Get("X-Backend-Api")
+				filters, _ := middleware.SelectorFiltersFromContext(req.Context())
+				selected = nodes
+				for _, f := range filters {
+					selected = f(req.Context(), selected)
+				}
+				return &http.Response{StatusCode: http.StatusOK}, nil
+			})
+			req := httptest.NewRequest("GET", "/users", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.version)
+			}
+			req = req.WithContext(middleware.NewRequestContext(context.Background(), middleware.NewRequestOptions(endpoint)))
+			resp, err := m(next).RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("want status %d but got %d", tt.wantStatus, resp.StatusCode)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if path != tt.wantPath {
+				t.Fatalf("want path %s but got %s", tt.wantPath, path)
+			}
+			if tt.wantNode == "" {
+				if len(selected) != len(nodes) {
+					t.Fatalf("want all nodes selected but got %v", selected)
+				}
+				return
+			}
+			if len(selected) != 1 || selected[0].Address() != tt.wantNode {
+				t.Fatalf("want only %s selected but got %v", tt.wantNode, selected)
+			}
+			if wantHeader := versions[tt.version].GetRequestHeaders()["X-Backend-Api"]; tt.version == "v2" && header != wantHeader {
+				t.Fatalf("want X-Backend-Api %q but got %q", wantHeader, header)
+			}
+		})
+	}
+}
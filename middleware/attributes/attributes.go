@@ -0,0 +1,241 @@
+package attributes
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/attributes/v1"
+	"github.com/cnsync/gateway/middleware"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// defaultMaxBodyBytes 是默认读取的 JSON 请求体的最大字节数
+const defaultMaxBodyBytes = 1 << 20
+
+// 包初始化时注册 attributes 中间件
+func init() {
+	middleware.Register("attributes", Middleware)
+}
+
+// extractor 结构体定义了一个属性的提取方式
+type extractor struct {
+	// name 是属性的名称
+	name string
+	// path 是解析后的路径模板，为 nil 时不从路径中提取
+	path []string
+	// query 是查询参数的名称
+	query string
+	// body 是 JSON 请求体中字段的路径
+	body []string
+	// header 是请求头的名称
+	header string
+}
+
+// newExtractor 函数根据属性配置创建一个提取器
+func newExtractor(attr *v1.Attribute) (*extractor, error) {
+	if attr.Name == "" {
+		return nil, fmt.Errorf("attribute name is required")
+	}
+	e := &extractor{name: attr.Name}
+	switch source := attr.Source.(type) {
+	case *v1.Attribute_Path:
+		e.path = strings.Split(strings.Trim(source.Path, "/"), "/")
+		variable := "{" + attr.Name + "}"
+		found := false
+		for _, seg := range e.path {
+			if seg == variable {
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("attribute %s: path template %q has no variable %s", attr.Name, source.Path, variable)
+		}
+	case *v1.Attribute_Query:
+		e.query = source.Query
+	case *v1.Attribute_Body:
+		e.body = strings.Split(source.Body, ".")
+	case *v1.Attribute_Header:
+		e.header = source.Header
+	default:
+		return nil, fmt.Errorf("attribute %s: source is required", attr.Name)
+	}
+	return e, nil
+}
+
+// matchPath 函数按路径模板匹配请求路径，返回模板中的变量
+func matchPath(template []string, path string) (map[string]string, bool) {
+	segs := strings.Split(strings.Trim(path, "/"), "/")
+	vars := make(map[string]string)
+	for i, t := range template {
+		if t == "**" {
+			return vars, true
+		}
+		if i >= len(segs) {
+			return nil, false
+		}
+		switch {
+		case t == "*":
+		case strings.HasPrefix(t, "{") && strings.HasSuffix(t, "}"):
+			vars[t[1:len(t)-1]] = segs[i]
+		case t != segs[i]:
+			return nil, false
+		}
+	}
+	return vars, len(template) == len(segs)
+}
+
+// lookupJSON 函数按字段路径在 JSON 值中查找字段，并将其转换为字符串
+func lookupJSON(v any, fields []string) (string, bool) {
+	for _, f := range fields {
+		switch val := v.(type) {
+		case map[string]any:
+			next, ok := val[f]
+			if !ok {
+				return "", false
+			}
+			v = next
+		case []any:
+			i, err := strconv.Atoi(f)
+			if err != nil || i < 0 || i >= len(val) {
+				return "", false
+			}
+			v = val[i]
+		default:
+			return "", false
+		}
+	}
+	switch val := v.(type) {
+	case nil:
+		return "", false
+	case string:
+		return val, true
+	case json.Number:
+		return val.String(), true
+	case bool:
+		return strconv.FormatBool(val), true
+	default:
+		// 对象和数组保留为 JSON 文本
+		b, err := json.Marshal(val)
+		if err != nil {
+			return "", false
+		}
+		return string(b), true
+	}
+}
+
+// isJSON 函数判断请求体是否为 JSON 格式
+func isJSON(req *http.Request) bool {
+	mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// readJSONBody 函数读取并解析 JSON 请求体，请求体会被重新放回请求中，超过大小限制或解析失败时返回 false
+func readJSONBody(req *http.Request, maxBytes int64) (any, bool, error) {
+	if req.Body == nil || req.Body == http.NoBody || !isJSON(req) {
+		return nil, false, nil
+	}
+	if req.ContentLength > maxBytes {
+		return nil, false, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(req.Body, maxBytes+1))
+	if err != nil {
+		return nil, false, err
+	}
+	if int64(len(body)) > maxBytes {
+		// 超过大小限制时将已读取的部分与剩余部分拼接后原样转发
+		req.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+		return nil, false, nil
+	}
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	// 保留数字的原始文本，避免大整数丢失精度
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, false, nil
+	}
+	return v, true, nil
+}
+
+// Middleware 函数根据传入的配置对象 c 创建一个请求属性提取中间件实例
+func Middleware(c *config.Middleware) (middleware.Middleware, error) {
+	options := &v1.Attributes{}
+	if c.Options != nil {
+		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+	}
+	maxBodyBytes := options.MaxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = defaultMaxBodyBytes
+	}
+	extractors := make([]*extractor, 0, len(options.Attributes))
+	needBody := false
+	for _, attr := range options.Attributes {
+		e, err := newExtractor(attr)
+		if err != nil {
+			return nil, err
+		}
+		needBody = needBody || e.body != nil
+		extractors = append(extractors, e)
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			// 只有配置了请求体属性时才读取请求体
+			var (
+				body    any
+				hasBody bool
+			)
+			if needBody {
+				var err error
+				if body, hasBody, err = readJSONBody(req, maxBodyBytes); err != nil {
+					return nil, err
+				}
+			}
+			query := req.URL.Query()
+			for _, e := range extractors {
+				switch {
+				case e.path != nil:
+					if vars, ok := matchPath(e.path, req.URL.Path); ok {
+						middleware.SetRequestAttribute(ctx, e.name, vars[e.name])
+					}
+				case e.query != "":
+					if query.Has(e.query) {
+						middleware.SetRequestAttribute(ctx, e.name, query.Get(e.query))
+					}
+				case e.body != nil:
+					if !hasBody {
+						continue
+					}
+					if v, ok := lookupJSON(body, e.body); ok {
+						middleware.SetRequestAttribute(ctx, e.name, v)
+					}
+				case e.header != "":
+					if v := req.Header.Get(e.header); v != "" {
+						middleware.SetRequestAttribute(ctx, e.name, v)
+					}
+				}
+			}
+			return next.RoundTrip(req)
+		})
+	}, nil
+}
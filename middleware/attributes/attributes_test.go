@@ -0,0 +1,138 @@
+package attributes
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/attributes/v1"
+	"github.com/cnsync/gateway/middleware"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func newMiddleware(t *testing.T, options *v1.Attributes) (middleware.Middleware, error) {
+	t.Helper()
+	cfg, err := anypb.New(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return Middleware(&config.Middleware{Options: cfg})
+}
+
+func TestAttributes(t *testing.T) {
+	m, err := newMiddleware(t, &v1.Attributes{
+		Attributes: []*v1.Attribute{
+			{Name: "user_id", Source: &v1.Attribute_Path{Path: "/users/{user_id}/orders/*"}},
+			{Name: "tenant", Source: &v1.Attribute_Query{Query: "tenant"}},
+			{Name: "sku", Source: &v1.Attribute_Body{Body: "items.0.sku"}},
+			{Name: "amount", Source: &v1.Attribute_Body{Body: "amount"}},
+			{Name: "client", Source: &v1.Attribute_Header{Header: "X-Client"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+		want        map[string]string
+	}{
+		{
+			name:        "all",
+			path:        "/users/42/orders/7?tenant=acme",
+			contentType: "application/json",
+			body:        `{"items":[{"sku":"A-1"}],"amount":12345678901234567890}`,
+			want:        map[string]string{"user_id": "42", "tenant": "acme", "sku": "A-1", "amount": "12345678901234567890", "client": "web"},
+		},
+		{
+			name:        "path mismatch",
+			path:        "/users/42",
+			contentType: "application/json",
+			body:        `{"amount":1.5}`,
+			want:        map[string]string{"amount": "1.5", "client": "web"},
+		},
+		{
+			name:        "not json",
+			path:        "/users/42/orders/7",
+			contentType: "text/plain",
+			body:        `{"amount":1}`,
+			want:        map[string]string{"user_id": "42", "client": "web"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				got      map[string]string
+				upstream string
+			)
+			// 后续的中间件可以读取已提取的属性
+			next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				got = middleware.RequestAttributes(req.Context())
+				b, _ := io.ReadAll(req.Body)
+				upstream = string(b)
+				return &http.Response{StatusCode: http.StatusOK}, nil
+			})
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			req.Header.Set("X-Client", "web")
+			req = req.WithContext(middleware.NewRequestContext(req.Context(), middleware.NewRequestOptions(&config.Endpoint{})))
+			if _, err := m(next).RoundTrip(req); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("want attributes %v but got %v", tt.want, got)
+			}
+			if upstream != tt.body {
+				t.Fatalf("want upstream body %q but got %q", tt.body, upstream)
+			}
+		})
+	}
+}
+
+func TestAttributesMaxBodyBytes(t *testing.T) {
+	m, err := newMiddleware(t, &v1.Attributes{
+		MaxBodyBytes: 8,
+		Attributes:   []*v1.Attribute{{Name: "id", Source: &v1.Attribute_Body{Body: "id"}}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := `{"id":"0123456789"}`
+	var upstream string
+	next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if attrs := middleware.RequestAttributes(req.Context()); len(attrs) != 0 {
+			t.Fatalf("want no attributes from an oversized body but got %v", attrs)
+		}
+		b, _ := io.ReadAll(req.Body)
+		upstream = string(b)
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+	req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+	req.ContentLength = -1
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(middleware.NewRequestContext(req.Context(), middleware.NewRequestOptions(&config.Endpoint{})))
+	if _, err := m(next).RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+	if upstream != body {
+		t.Fatalf("want upstream body %q but got %q", body, upstream)
+	}
+}
+
+func TestAttributesInvalid(t *testing.T) {
+	for _, attr := range []*v1.Attribute{
+		{Source: &v1.Attribute_Query{Query: "q"}},
+		{Name: "id"},
+		{Name: "id", Source: &v1.Attribute_Path{Path: "/users/{user}"}},
+	} {
+		if _, err := newMiddleware(t, &v1.Attributes{Attributes: []*v1.Attribute{attr}}); err == nil {
+			t.Fatalf("want error for %v", attr)
+		}
+	}
+}
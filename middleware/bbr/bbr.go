@@ -1,33 +1,40 @@
 package bbr
 
 import (
-	"bytes"
-	"io"
 	"net/http"
 
 	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/bbr/v1"
 	"github.com/cnsync/gateway/middleware"
 	"github.com/go-kratos/aegis/ratelimit"
 	"github.com/go-kratos/aegis/ratelimit/bbr"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
 )
 
-var _nopBody = io.NopCloser(&bytes.Buffer{})
+// newLimiter 创建自适应限流器，测试时可替换
+var newLimiter = func() ratelimit.Limiter {
+	return bbr.NewLimiter() //use default settings
+}
 
 func init() {
 	middleware.Register("bbr", Middleware)
 }
 
 func Middleware(c *config.Middleware) (middleware.Middleware, error) {
-	limiter := bbr.NewLimiter() //use default settings
+	options := &v1.BBR{}
+	if c.Options != nil {
+		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+	}
+	limiter := newLimiter()
+	onReject := middleware.NewRejectHandler(options.RejectResponse, http.StatusTooManyRequests)
 	return func(next http.RoundTripper) http.RoundTripper {
 		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
 			done, err := limiter.Allow()
 			if err != nil {
-				return &http.Response{
-					Status:     http.StatusText(http.StatusTooManyRequests),
-					StatusCode: http.StatusTooManyRequests,
-					Body:       _nopBody,
-				}, nil
+				return onReject.RoundTrip(req)
 			}
 			resp, err := next.RoundTrip(req)
 			done(ratelimit.DoneInfo{Err: err})
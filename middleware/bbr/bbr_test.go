@@ -0,0 +1,69 @@
+package bbr
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/bbr/v1"
+	"github.com/cnsync/gateway/middleware"
+	"github.com/go-kratos/aegis/ratelimit"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+type rejectLimiter struct{}
+
+func (rejectLimiter) Allow() (ratelimit.DoneFunc, error) { return nil, ratelimit.ErrLimitExceed }
+
+func TestRejectResponse(t *testing.T) {
+	prev := newLimiter
+	newLimiter = func() ratelimit.Limiter { return rejectLimiter{} }
+	defer func() { newLimiter = prev }()
+
+	tests := []struct {
+		name       string
+		options    *v1.BBR
+		wantStatus int
+		wantBody   string
+		wantHeader string
+	}{
+		{name: "default", options: &v1.BBR{}, wantStatus: http.StatusTooManyRequests},
+		{
+			name: "custom",
+			options: &v1.BBR{RejectResponse: &config.RejectResponse{
+				StatusCode: http.StatusServiceUnavailable,
+				Headers:    map[string]string{"Content-Type": "application/json", "Retry-After": "5"},
+				Body:       []byte(`{"error":"overloaded","retry_after":5}`),
+			}},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   `{"error":"overloaded","retry_after":5}`,
+			wantHeader: "5",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := anypb.New(tt.options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m, err := Middleware(&config.Middleware{Options: cfg})
+			if err != nil {
+				t.Fatal(err)
+			}
+			next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				t.Fatal("want the request rejected")
+				return nil, nil
+			})
+			resp, err := m(next).RoundTrip(httptest.NewRequest("GET", "/", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus || string(body) != tt.wantBody || resp.Header.Get("Retry-After") != tt.wantHeader {
+				t.Fatalf("unexpected reject response: %d %v %q", resp.StatusCode, resp.Header, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package cache
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/cache/v1"
+	"github.com/cnsync/gateway/middleware"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const (
+	// defaultTTL 是默认的缓存时间
+	defaultTTL = time.Minute
+	// defaultMaxEntryBytes 是默认可以缓存的最大响应体大小
+	defaultMaxEntryBytes = 1 << 20
+	// defaultMaxTotalBytes 是默认缓存的响应体的总大小
+	defaultMaxTotalBytes = 64 << 20
+)
+
+// 包初始化时注册 cache 中间件
+func init() {
+	middleware.RegisterV2("cache", Middleware)
+}
+
+// isCacheableRequest 函数判断请求是否可以使用缓存，只缓存 GET 和 HEAD 请求
+func isCacheableRequest(req *http.Request) bool {
+	return req.Method == http.MethodGet || req.Method == http.MethodHead
+}
+
+// isBypassRequest 函数判断请求是否携带了绕过缓存的请求头
+func isBypassRequest(req *http.Request, bypass *v1.Bypass) bool {
+	if bypass == nil || bypass.Header == "" {
+		return false
+	}
+	value := req.Header.Get(bypass.Header)
+	if bypass.Value == "" {
+		return value != ""
+	}
+	return value == bypass.Value
+}
+
+// hasNoStore 函数判断 Cache-Control 头是否包含 no-store 指令
+func hasNoStore(header http.Header) bool {
+	for _, v := range header.Values("Cache-Control") {
+		for _, directive := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(directive), "no-store") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// setCacheStatus 函数在响应头 X-Cache 中标记响应是否来自缓存
+func setCacheStatus(resp *http.Response, status string) {
+	if resp.Header == nil {
+		resp.Header = make(http.Header)
+	}
+	resp.Header.Set("X-Cache", status)
+}
+
+// readCacheableBody 函数读取不超过 limit 的响应体，响应体超过 limit 时返回 false，
+// 此时响应体被替换为已读取的部分与剩余部分的组合，可以继续完整地读取
+func readCacheableBody(resp *http.Response, limit int64) ([]byte, bool, error) {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, false, err
+	}
+	if int64(len(body)) > limit {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return nil, false, nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return body, true, nil
+}
+
+// newResponse 函数根据缓存的响应构造一个新的响应，每次返回独立的响应头和响应体
+func newResponse(req *http.Request, e *entry) *http.Response {
+	return &http.Response{
+		StatusCode:    e.statusCode,
+		Header:        e.header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
+
+// Middleware 函数根据传入的配置对象 c 创建一个响应缓存中间件实例，
+// 缓存键默认由请求方法、主机、路径、查询参数以及上游响应 Vary 头中列出的请求头组成，可以按端点配置。
+// 响应头 X-Cache 标记响应是否命中缓存，携带 Cache-Control: no-store 的请求和响应不使用缓存，
+// 过期的响应由后台任务定期清理，响应体的总大小超过限制时淘汰最近最少使用的响应
+func Middleware(c *config.Middleware) (middleware.MiddlewareV2, error) {
+	options := &v1.Cache{}
+	if c.Options != nil {
+		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+	}
+	ttl := defaultTTL
+	if options.Ttl != nil && options.Ttl.AsDuration() > 0 {
+		ttl = options.Ttl.AsDuration()
+	}
+	maxEntryBytes := int64(defaultMaxEntryBytes)
+	if options.MaxEntryBytes > 0 {
+		maxEntryBytes = options.MaxEntryBytes
+	}
+	maxTotalBytes := int64(defaultMaxTotalBytes)
+	if options.MaxTotalBytes > 0 {
+		maxTotalBytes = options.MaxTotalBytes
+	}
+	statuses := map[int]struct{}{http.StatusOK: {}}
+	if len(options.CacheableStatuses) > 0 {
+		statuses = make(map[int]struct{}, len(options.CacheableStatuses))
+		for _, code := range options.CacheableStatuses {
+			statuses[int(code)] = struct{}{}
+		}
+	}
+	keyOf := newKeyFunc(options.Key)
+	s := newStore(maxTotalBytes)
+	go s.proceviction(ttl)
+	return middleware.NewWithCloser(func(next http.RoundTripper) http.RoundTripper {
+		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if !isCacheableRequest(req) || hasNoStore(req.Header) {
+				return next.RoundTrip(req)
+			}
+			key, ok := keyOf(req)
+			if !ok {
+				return next.RoundTrip(req)
+			}
+			// 绕过缓存的请求总是发往上游，开启 refresh 时使用上游响应刷新缓存
+			bypass := isBypassRequest(req, options.Bypass)
+			if bypass && !options.Bypass.Refresh {
+				return next.RoundTrip(req)
+			}
+			if !bypass {
+				if e, ok := s.get(key, req); ok {
+					resp := newResponse(req, e)
+					setCacheStatus(resp, "HIT")
+					return resp, nil
+				}
+			}
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := statuses[resp.StatusCode]; ok && resp.Body != nil && !hasNoStore(resp.Header) {
+				body, ok, err := readCacheableBody(resp, maxEntryBytes)
+				if err != nil {
+					return nil, err
+				}
+				if ok {
+					s.set(key, req, resp, body, ttl)
+				}
+			}
+			setCacheStatus(resp, "MISS")
+			return resp, nil
+		})
+	}, s), nil
+}
@@ -0,0 +1,381 @@
+package cache
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/cache/v1"
+	"github.com/cnsync/gateway/middleware"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// newBackend 函数返回一个按 Accept-Language 返回响应的上游，并统计调用次数
+func newBackend(calls *int, vary string) http.RoundTripper {
+	return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		*calls++
+		header := http.Header{}
+		if vary != "" {
+			header.Set("Vary", vary)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     header,
+			Body:       io.NopCloser(bytes.NewBufferString("lang=" + req.Header.Get("Accept-Language"))),
+		}, nil
+	})
+}
+
+func roundTrip(t *testing.T, rt http.RoundTripper, method, lang string) string {
+	req := httptest.NewRequest(method, "http://example.com/api/echo?a=1", nil)
+	if lang != "" {
+		req.Header.Set("Accept-Language", lang)
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestCacheVary(t *testing.T) {
+	m, err := Middleware(&config.Middleware{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	var calls int
+	rt := m.Process(newBackend(&calls, "Accept-Language"))
+
+	tests := []struct {
+		lang  string
+		want  string
+		calls int
+	}{
+		{"en", "lang=en", 1},
+		{"en", "lang=en", 1},
+		{"zh-CN", "lang=zh-CN", 2},
+		{"zh-CN", "lang=zh-CN", 2},
+		{"en", "lang=en", 2},
+		{"", "lang=", 3},
+	}
+	for _, tt := range tests {
+		if got := roundTrip(t, rt, "GET", tt.lang); got != tt.want {
+			t.Fatalf("Accept-Language %q: want body %q but got %q", tt.lang, tt.want, got)
+		}
+		if calls != tt.calls {
+			t.Fatalf("Accept-Language %q: want %d upstream calls but got %d", tt.lang, tt.calls, calls)
+		}
+	}
+	// 非 GET/HEAD 请求不使用缓存
+	roundTrip(t, rt, "POST", "en")
+	if calls != 4 {
+		t.Fatalf("want 4 upstream calls but got %d", calls)
+	}
+}
+
+func TestCacheVaryStar(t *testing.T) {
+	m, err := Middleware(&config.Middleware{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	var calls int
+	rt := m.Process(newBackend(&calls, "*"))
+	roundTrip(t, rt, "GET", "en")
+	roundTrip(t, rt, "GET", "en")
+	if calls != 2 {
+		t.Fatalf("want responses with Vary: * not cached, but got %d upstream calls", calls)
+	}
+}
+
+func TestStoreExpire(t *testing.T) {
+	s := newStore(defaultMaxTotalBytes)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	req := httptest.NewRequest("GET", "http://example.com/api/echo", nil)
+	req.Header.Set("Accept-Language", "en")
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{"Vary": []string{"accept-language, Accept-Encoding"}}}
+	s.set(primaryKey(req), req, resp, []byte("hello"), time.Second)
+	if _, ok := s.get(primaryKey(req), req); !ok {
+		t.Fatal("want cache hit")
+	}
+	// 未参与 Vary 的请求头不影响缓存键
+	req.Header.Set("User-Agent", "test")
+	if _, ok := s.get(primaryKey(req), req); !ok {
+		t.Fatal("want cache hit with a non-varying header changed")
+	}
+	req.Header.Set("Accept-Encoding", "gzip")
+	if _, ok := s.get(primaryKey(req), req); ok {
+		t.Fatal("want cache miss with a varying header changed")
+	}
+	req.Header.Del("Accept-Encoding")
+	now = now.Add(time.Second)
+	if _, ok := s.get(primaryKey(req), req); ok {
+		t.Fatal("want cache miss after expired")
+	}
+}
+
+func TestCacheBypass(t *testing.T) {
+	for _, refresh := range []bool{false, true} {
+		options, err := anypb.New(&v1.Cache{Bypass: &v1.Bypass{Header: "X-Cache-Bypass", Value: "1", Refresh: refresh}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := Middleware(&config.Middleware{Options: options})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer m.Close()
+		var calls int
+		version := "v1"
+		rt := m.Process(middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			calls++
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       io.NopCloser(bytes.NewBufferString(version)),
+			}, nil
+		}))
+		get := func(bypass string) string {
+			req := httptest.NewRequest("GET", "http://example.com/api/echo", nil)
+			if bypass != "" {
+				req.Header.Set("X-Cache-Bypass", bypass)
+			}
+			resp, err := rt.RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, _ := io.ReadAll(resp.Body)
+			return string(b)
+		}
+
+		get("")
+		version = "v2"
+		// 请求头的值不匹配时使用缓存
+		if got := get("0"); got != "v1" || calls != 1 {
+			t.Fatalf("refresh %v: want cached v1 but got %s after %d upstream calls", refresh, got, calls)
+		}
+		// 绕过缓存的请求总是发往上游
+		if got := get("1"); got != "v2" || calls != 2 {
+			t.Fatalf("refresh %v: want upstream v2 but got %s after %d upstream calls", refresh, got, calls)
+		}
+		want := "v1"
+		if refresh {
+			want = "v2"
+		}
+		if got := get(""); got != want || calls != 2 {
+			t.Fatalf("refresh %v: want cached %s but got %s after %d upstream calls", refresh, want, got, calls)
+		}
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	endpoint := &config.Endpoint{Path: "/api/echo", Protocol: config.Protocol_HTTP}
+	tests := []struct {
+		name string
+		key  *v1.Key
+		// requests 是依次发出的请求，hits 是每个请求是否命中缓存
+		requests []func(req *http.Request)
+		hits     []bool
+	}{
+		{
+			name: "selected query params",
+			key:  &v1.Key{QueryParams: []string{"page"}},
+			requests: []func(req *http.Request){
+				func(req *http.Request) { req.URL.RawQuery = "page=1&ts=1" },
+				func(req *http.Request) { req.URL.RawQuery = "ts=2&page=1" },
+				func(req *http.Request) { req.URL.RawQuery = "page=2" },
+			},
+			hits: []bool{false, true, false},
+		},
+		{
+			name: "ignore host",
+			key:  &v1.Key{IgnoreHost: true},
+			requests: []func(req *http.Request){
+				func(req *http.Request) { req.Host = "a.example.com" },
+				func(req *http.Request) { req.Host = "b.example.com" },
+			},
+			hits: []bool{false, true},
+		},
+		{
+			name: "headers",
+			key:  &v1.Key{Headers: []string{"x-tenant"}},
+			requests: []func(req *http.Request){
+				func(req *http.Request) { req.Header.Set("X-Tenant", "a") },
+				func(req *http.Request) { req.Header.Set("X-Tenant", "b") },
+				func(req *http.Request) { req.Header.Set("X-Tenant", "a") },
+			},
+			hits: []bool{false, false, true},
+		},
+		{
+			name: "identity header",
+			key:  &v1.Key{IdentityHeader: "Authorization"},
+			requests: []func(req *http.Request){
+				func(req *http.Request) { req.Header.Set("Authorization", "Bearer alice") },
+				func(req *http.Request) { req.Header.Set("Authorization", "Bearer bob") },
+				func(req *http.Request) { req.Header.Set("Authorization", "Bearer alice") },
+				// 没有身份的请求不使用缓存
+				func(req *http.Request) {},
+				func(req *http.Request) {},
+			},
+			hits: []bool{false, false, true, false, false},
+		},
+		{
+			name: "identity attribute",
+			key:  &v1.Key{IdentityAttribute: "user_id"},
+			requests: []func(req *http.Request){
+				func(req *http.Request) { middleware.SetRequestAttribute(req.Context(), "user_id", "alice") },
+				func(req *http.Request) { middleware.SetRequestAttribute(req.Context(), "user_id", "bob") },
+				func(req *http.Request) { middleware.SetRequestAttribute(req.Context(), "user_id", "alice") },
+			},
+			hits: []bool{false, false, true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options, err := anypb.New(&v1.Cache{Key: tt.key})
+			if err != nil {
+				t.Fatal(err)
+			}
+			m, err := Middleware(&config.Middleware{Options: options})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer m.Close()
+			var calls int
+			rt := m.Process(newBackend(&calls, ""))
+			for i, prepare := range tt.requests {
+				req := httptest.NewRequest("GET", "http://example.com/api/echo", nil)
+				req = req.WithContext(middleware.NewRequestContext(req.Context(), middleware.NewRequestOptions(endpoint)))
+				prepare(req)
+				before := calls
+				resp, err := rt.RoundTrip(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp.Body.Close()
+				if hit := calls == before; hit != tt.hits[i] {
+					t.Fatalf("request %d: want hit %v but got %v", i, tt.hits[i], hit)
+				}
+			}
+		})
+	}
+}
+
+func TestCacheStatusAndNoStore(t *testing.T) {
+	options, err := anypb.New(&v1.Cache{MaxEntryBytes: 8, CacheableStatuses: []int32{http.StatusOK, http.StatusNotFound}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Middleware(&config.Middleware{Options: options})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+	var calls int
+	rt := m.Process(middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		status := http.StatusOK
+		switch req.URL.Path {
+		case "/missing":
+			status = http.StatusNotFound
+		case "/error":
+			status = http.StatusInternalServerError
+		}
+		body := "ok"
+		if req.URL.Path == "/large" {
+			body = "larger than eight bytes"
+		}
+		header := http.Header{}
+		if req.URL.Path == "/private" {
+			header.Set("Cache-Control", "private, no-store")
+		}
+		return &http.Response{StatusCode: status, Header: header, Body: io.NopCloser(bytes.NewBufferString(body))}, nil
+	}))
+	tests := []struct {
+		path    string
+		noStore bool
+		want    []string
+	}{
+		{path: "/ok", want: []string{"MISS", "HIT"}},
+		{path: "/missing", want: []string{"MISS", "HIT"}},
+		{path: "/error", want: []string{"MISS", "MISS"}},
+		{path: "/large", want: []string{"MISS", "MISS"}},
+		{path: "/private", want: []string{"MISS", "MISS"}},
+		{path: "/ok", noStore: true, want: []string{"", ""}},
+	}
+	for _, tt := range tests {
+		for i, want := range tt.want {
+			req := httptest.NewRequest("GET", "http://example.com"+tt.path, nil)
+			if tt.noStore {
+				req.Header.Set("Cache-Control", "No-Store")
+			}
+			resp, err := rt.RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			if got := resp.Header.Get("X-Cache"); got != want {
+				t.Fatalf("%s request %d: want X-Cache %q but got %q", tt.path, i, want, got)
+			}
+			// 超过大小限制的响应体仍然完整地返回给客户端
+			if tt.path == "/large" && string(b) != "larger than eight bytes" {
+				t.Fatalf("want the full body but got %q", b)
+			}
+		}
+	}
+	if calls != 10 {
+		t.Fatalf("want 10 upstream calls but got %d", calls)
+	}
+}
+
+func TestStoreEvictLRU(t *testing.T) {
+	s := newStore(10)
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+	set := func(path, body string) {
+		req := httptest.NewRequest("GET", "http://example.com"+path, nil)
+		s.set(primaryKey(req), req, resp, []byte(body), time.Minute)
+	}
+	cached := func(path string) bool {
+		req := httptest.NewRequest("GET", "http://example.com"+path, nil)
+		_, ok := s.get(primaryKey(req), req)
+		return ok
+	}
+	set("/a", "aaaa")
+	set("/b", "bbbb")
+	// 访问 /a 之后 /b 成为最近最少使用的响应
+	if !cached("/a") {
+		t.Fatal("want /a cached")
+	}
+	set("/c", "cccc")
+	if !cached("/a") || cached("/b") || !cached("/c") {
+		t.Fatal("want /b evicted")
+	}
+	// 覆盖已缓存的响应不重复计算大小
+	set("/c", "cc")
+	if s.size != 6 {
+		t.Fatalf("want 6 bytes cached but got %d", s.size)
+	}
+	// 超过总大小限制的响应不缓存
+	set("/d", "ddddddddddd")
+	if cached("/d") || !cached("/a") {
+		t.Fatal("want /d not cached and /a kept")
+	}
+
+	now := time.Now().Add(time.Minute)
+	s.now = func() time.Time { return now }
+	s.evictExpired()
+	if s.size != 0 || s.lru.Len() != 0 || len(s.items) != 0 {
+		t.Fatalf("want all expired responses evicted, but got %d bytes in %d responses", s.size, s.lru.Len())
+	}
+}
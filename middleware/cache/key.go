@@ -0,0 +1,85 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/cache/v1"
+	"github.com/cnsync/gateway/middleware"
+)
+
+// keyFunc 返回请求的主缓存键，返回 false 时请求不使用缓存
+type keyFunc func(req *http.Request) (string, bool)
+
+// newKeyFunc 函数根据缓存键的配置创建计算主缓存键的函数，未配置时使用默认的主缓存键
+func newKeyFunc(c *v1.Key) keyFunc {
+	if c == nil {
+		return func(req *http.Request) (string, bool) {
+			return primaryKey(req), true
+		}
+	}
+	headers := make([]string, 0, len(c.Headers))
+	for _, name := range c.Headers {
+		headers = append(headers, http.CanonicalHeaderKey(name))
+	}
+	sort.Strings(headers)
+	params := append([]string(nil), c.QueryParams...)
+	sort.Strings(params)
+	return func(req *http.Request) (string, bool) {
+		var b strings.Builder
+		b.WriteString(req.Method)
+		b.WriteByte(' ')
+		if !c.IgnoreHost {
+			b.WriteString(req.Host)
+		}
+		b.WriteString(req.URL.EscapedPath())
+		if len(params) == 0 {
+			if req.URL.RawQuery != "" {
+				b.WriteByte('?')
+				b.WriteString(req.URL.RawQuery)
+			}
+		} else {
+			query := req.URL.Query()
+			selected := url.Values{}
+			for _, name := range params {
+				if values, ok := query[name]; ok {
+					selected[name] = values
+				}
+			}
+			if len(selected) > 0 {
+				b.WriteByte('?')
+				b.WriteString(selected.Encode())
+			}
+		}
+		b.WriteByte('\n')
+		b.WriteString(variantKey(req, headers))
+		// 按调用方身份隔离缓存，缓存键中只保存身份的摘要
+		if c.IdentityHeader != "" || c.IdentityAttribute != "" {
+			identity := requestIdentity(req, c)
+			if identity == "" {
+				return "", false
+			}
+			sum := sha256.Sum256([]byte(identity))
+			b.WriteString("identity:")
+			b.WriteString(hex.EncodeToString(sum[:]))
+		}
+		return b.String(), true
+	}
+}
+
+// requestIdentity 函数返回请求的调用方身份，优先使用请求属性
+func requestIdentity(req *http.Request, c *v1.Key) string {
+	if c.IdentityAttribute != "" {
+		if v := middleware.RequestAttributes(req.Context())[c.IdentityAttribute]; v != "" {
+			return v
+		}
+	}
+	if c.IdentityHeader != "" {
+		return req.Header.Get(c.IdentityHeader)
+	}
+	return ""
+}
@@ -0,0 +1,224 @@
+package cache
+
+import (
+	"container/list"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// entry 结构体是一个已缓存的响应
+type entry struct {
+	// statusCode 是响应的状态码
+	statusCode int
+	// header 是响应头
+	header http.Header
+	// body 是响应体
+	body []byte
+	// expiresAt 是缓存的过期时间
+	expiresAt time.Time
+	// key 和 variant 是响应的主缓存键和变体的缓存键
+	key     string
+	variant string
+	// elem 是响应在最近使用列表中的位置
+	elem *list.Element
+}
+
+// variants 结构体保存了同一个请求的所有变体，变体由上游响应的 Vary 头决定
+type variants struct {
+	// vary 是上游响应的 Vary 头中的请求头名称，已规范化并排序
+	vary []string
+	// entries 保存了每个变体的响应，键为 Vary 请求头的值
+	entries map[string]*entry
+}
+
+// store 结构体是一个并发安全的响应缓存，响应体的总大小超过 maxBytes 时淘汰最近最少使用的响应
+type store struct {
+	lock     sync.Mutex
+	items    map[string]*variants
+	lru      *list.List
+	size     int64
+	maxBytes int64
+	now      func() time.Time
+	stop     chan struct{}
+	once     sync.Once
+}
+
+// newStore 函数创建一个新的 store 实例
+func newStore(maxBytes int64) *store {
+	return &store{
+		items:    map[string]*variants{},
+		lru:      list.New(),
+		maxBytes: maxBytes,
+		now:      time.Now,
+		stop:     make(chan struct{}),
+	}
+}
+
+// primaryKey 函数返回请求的主缓存键，由方法、主机、路径和查询参数组成
+func primaryKey(req *http.Request) string {
+	return req.Method + " " + req.Host + req.URL.RequestURI()
+}
+
+// parseVary 函数解析响应的 Vary 头，返回规范化并排序后的请求头名称，
+// 包含 * 时返回 false，表示响应不可缓存
+func parseVary(header http.Header) ([]string, bool) {
+	var vary []string
+	for _, v := range header.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if name == "*" {
+				return nil, false
+			}
+			vary = append(vary, http.CanonicalHeaderKey(name))
+		}
+	}
+	sort.Strings(vary)
+	return vary, true
+}
+
+// variantKey 函数根据 Vary 请求头的值计算变体的缓存键
+func variantKey(req *http.Request, vary []string) string {
+	var b strings.Builder
+	for _, name := range vary {
+		values := req.Header.Values(name)
+		for i := range values {
+			values[i] = strings.TrimSpace(values[i])
+		}
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(strings.Join(values, ","))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// sameVary 函数判断两个 Vary 请求头列表是否相同
+func sameVary(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// get 方法返回与主缓存键和请求匹配且未过期的缓存响应
+func (s *store) get(key string, req *http.Request) (*entry, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	v, ok := s.items[key]
+	if !ok {
+		return nil, false
+	}
+	vk := variantKey(req, v.vary)
+	e, ok := v.entries[vk]
+	if !ok {
+		return nil, false
+	}
+	if !s.now().Before(e.expiresAt) {
+		s.remove(e)
+		return nil, false
+	}
+	s.lru.MoveToFront(e.elem)
+	return e, true
+}
+
+// set 方法以主缓存键缓存请求对应的响应，响应的 Vary 头为 * 时不缓存
+func (s *store) set(key string, req *http.Request, resp *http.Response, body []byte, ttl time.Duration) {
+	vary, ok := parseVary(resp.Header)
+	if !ok {
+		return
+	}
+	if int64(len(body)) > s.maxBytes {
+		return
+	}
+	e := &entry{
+		statusCode: resp.StatusCode,
+		header:     resp.Header.Clone(),
+		body:       body,
+		expiresAt:  s.now().Add(ttl),
+		key:        key,
+		variant:    variantKey(req, vary),
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if v, ok := s.items[key]; ok {
+		// 上游的 Vary 头发生变化时，之前缓存的变体不再可靠，全部丢弃
+		if !sameVary(v.vary, vary) {
+			for _, old := range v.entries {
+				s.remove(old)
+			}
+		} else if old, ok := v.entries[e.variant]; ok {
+			s.remove(old)
+		}
+	}
+	// 删除最后一个变体时主缓存键也被删除，需要重新获取
+	v, ok := s.items[key]
+	if !ok {
+		v = &variants{vary: vary, entries: map[string]*entry{}}
+		s.items[key] = v
+	}
+	v.entries[e.variant] = e
+	e.elem = s.lru.PushFront(e)
+	s.size += int64(len(body))
+	for s.size > s.maxBytes {
+		s.remove(s.lru.Back().Value.(*entry))
+	}
+}
+
+// remove 方法删除一个缓存的响应，调用者需要持有锁
+func (s *store) remove(e *entry) {
+	s.lru.Remove(e.elem)
+	s.size -= int64(len(e.body))
+	v, ok := s.items[e.key]
+	if !ok || v.entries[e.variant] != e {
+		return
+	}
+	delete(v.entries, e.variant)
+	if len(v.entries) == 0 {
+		delete(s.items, e.key)
+	}
+}
+
+// proceviction 方法启动一个后台任务，按间隔清理过期的响应，缓存关闭时退出
+func (s *store) proceviction(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.evictExpired()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// evictExpired 方法清理所有过期的响应
+func (s *store) evictExpired() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := s.now()
+	for elem := s.lru.Back(); elem != nil; {
+		prev := elem.Prev()
+		if e := elem.Value.(*entry); !now.Before(e.expiresAt) {
+			s.remove(e)
+		}
+		elem = prev
+	}
+}
+
+// Close 方法停止清理过期响应的后台任务
+func (s *store) Close() error {
+	s.once.Do(func() { close(s.stop) })
+	return nil
+}
@@ -0,0 +1,103 @@
+package canary
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/canary/v1"
+	"github.com/cnsync/gateway/middleware"
+	"github.com/cnsync/kratos/selector"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const (
+	// CohortCanary 是金丝雀用户群
+	CohortCanary = "canary"
+	// CohortStable 是稳定用户群
+	CohortStable = "stable"
+)
+
+// _buckets 是用户 ID 哈希后划分的桶数，百分比精确到 0.01%
+const _buckets = 10000
+
+// 包初始化时注册 canary 中间件
+func init() {
+	middleware.Register("canary", Middleware)
+}
+
+// Cohort 函数根据用户 ID 的哈希值为用户分配稳定的用户群，同一用户在相同的 salt 和百分比下总是得到相同的结果
+func Cohort(userID, salt string, percentage float64) string {
+	if userID == "" {
+		return CohortStable
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(salt))
+	_, _ = h.Write([]byte{0})
+	_, _ = h.Write([]byte(userID))
+	if float64(h.Sum64()%_buckets) < percentage*_buckets/100 {
+		return CohortCanary
+	}
+	return CohortStable
+}
+
+// isCanaryNode 函数判断节点的元数据是否包含所有金丝雀元数据
+func isCanaryNode(n selector.Node, md map[string]string) bool {
+	nmd := n.Metadata()
+	for k, v := range md {
+		if nmd[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// cohortFilter 函数返回一个只保留用户群对应节点的过滤器，对应的节点不存在时保留所有节点
+func cohortFilter(cohort string, md map[string]string) selector.NodeFilter {
+	canary := cohort == CohortCanary
+	return func(_ context.Context, nodes []selector.Node) []selector.Node {
+		filtered := make([]selector.Node, 0, len(nodes))
+		for _, n := range nodes {
+			if isCanaryNode(n, md) == canary {
+				filtered = append(filtered, n)
+			}
+		}
+		if len(filtered) == 0 {
+			return nodes
+		}
+		return filtered
+	}
+}
+
+// Middleware 函数根据传入的配置对象 c 创建一个金丝雀中间件实例，
+// 按用户 ID 的哈希值将固定比例的用户稳定地路由到金丝雀节点，其余用户路由到稳定节点
+func Middleware(c *config.Middleware) (middleware.Middleware, error) {
+	options := &v1.Canary{}
+	if c.Options != nil {
+		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+	}
+	if options.UserHeader == "" {
+		return nil, fmt.Errorf("canary: user_header is required")
+	}
+	if options.Percentage < 0 || options.Percentage > 100 {
+		return nil, fmt.Errorf("canary: invalid percentage: %v", options.Percentage)
+	}
+	if len(options.CanaryMetadata) == 0 {
+		return nil, fmt.Errorf("canary: canary_metadata is required")
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			cohort := Cohort(req.Header.Get(options.UserHeader), options.Salt, options.Percentage)
+			if options.CohortHeader != "" {
+				req.Header.Set(options.CohortHeader, cohort)
+			}
+			ctx := middleware.WithSelectorFitler(req.Context(), cohortFilter(cohort, options.CanaryMetadata))
+			return next.RoundTrip(req.WithContext(ctx))
+		})
+	}, nil
+}
@@ -0,0 +1,103 @@
+package canary
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/canary/v1"
+	"github.com/cnsync/gateway/middleware"
+	"github.com/cnsync/kratos/registry"
+	"github.com/cnsync/kratos/selector"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestCohortStable(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		user := "user-" + strconv.Itoa(i)
+		want := Cohort(user, "rollout-1", 30)
+		for j := 0; j < 10; j++ {
+			if got := Cohort(user, "rollout-1", 30); got != want {
+				t.Fatalf("%s: want cohort %s but got %s", user, want, got)
+			}
+		}
+	}
+	if got := Cohort("", "rollout-1", 100); got != CohortStable {
+		t.Fatalf("want anonymous users in the stable cohort but got %s", got)
+	}
+}
+
+func TestCohortPercentage(t *testing.T) {
+	for _, percentage := range []float64{0, 5, 20, 50, 100} {
+		const users = 20000
+		canary := 0
+		for i := 0; i < users; i++ {
+			if Cohort("user-"+strconv.Itoa(i), "rollout-1", percentage) == CohortCanary {
+				canary++
+			}
+		}
+		got := float64(canary) * 100 / users
+		if math.Abs(got-percentage) > 1 {
+			t.Fatalf("want %v%% users in the canary cohort but got %v%%", percentage, got)
+		}
+	}
+	// 提高百分比时，已经在金丝雀用户群中的用户保持不变
+	for i := 0; i < 1000; i++ {
+		user := "user-" + strconv.Itoa(i)
+		if Cohort(user, "rollout-1", 10) == CohortCanary && Cohort(user, "rollout-1", 20) != CohortCanary {
+			t.Fatalf("%s: want kept in the canary cohort when the percentage grows", user)
+		}
+	}
+}
+
+func TestCanaryRouting(t *testing.T) {
+	options, err := anypb.New(&v1.Canary{
+		UserHeader:     "X-User-ID",
+		Percentage:     50,
+		CanaryMetadata: map[string]string{"stage": "canary"},
+		Salt:           "rollout-1",
+		CohortHeader:   "X-Cohort",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Middleware(&config.Middleware{Options: options})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := []selector.Node{
+		selector.NewNode("http", "10.0.0.1:8000", &registry.ServiceInstance{Metadata: map[string]string{"stage": "stable"}}),
+		selector.NewNode("http", "10.0.0.2:8000", &registry.ServiceInstance{Metadata: map[string]string{"stage": "canary"}}),
+	}
+	endpoint := &config.Endpoint{Path: "/api", Protocol: config.Protocol_HTTP}
+	for i := 0; i < 50; i++ {
+		user := "user-" + strconv.Itoa(i)
+		var selected []selector.Node
+		var cohort string
+		next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			cohort = req.Header.Get("X-Cohort")
+			filters, _ := middleware.SelectorFiltersFromContext(req.Context())
+			selected = nodes
+			for _, f := range filters {
+				selected = f(req.Context(), selected)
+			}
+			return &http.Response{StatusCode: http.StatusOK}, nil
+		})
+		req := httptest.NewRequest("GET", "/api", nil)
+		req.Header.Set("X-User-ID", user)
+		req = req.WithContext(middleware.NewRequestContext(context.Background(), middleware.NewRequestOptions(endpoint)))
+		if _, err := m(next).RoundTrip(req); err != nil {
+			t.Fatal(err)
+		}
+		if want := Cohort(user, "rollout-1", 50); cohort != want {
+			t.Fatalf("%s: want cohort header %s but got %s", user, want, cohort)
+		}
+		if len(selected) != 1 || selected[0].Metadata()["stage"] != cohort {
+			t.Fatalf("%s: want only the %s node selected but got %v", user, cohort, selected)
+		}
+	}
+}
@@ -0,0 +1,441 @@
+package capture
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	mrand "math/rand"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/capture/v1"
+	"github.com/cnsync/gateway/middleware"
+	"github.com/cnsync/kratos/log"
+	"github.com/go-kratos/feature"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const (
+	// _defaultSampleRate 是默认的抓取比例
+	_defaultSampleRate = 0.001
+	// _defaultMaxBodyBytes 是默认的请求体和响应体的最大抓取字节数
+	_defaultMaxBodyBytes = 64 << 10
+	// _defaultBufferSize 是默认的等待上传的抓取记录队列长度
+	_defaultBufferSize = 64
+	// _redacted 是脱敏后的值
+	_redacted = "[REDACTED]"
+)
+
+// captureFeature 是请求抓取的功能开关，默认关闭，关闭时中间件不抓取任何请求
+var captureFeature = feature.MustRegister("gw:RequestCapture", false)
+
+// _defaultRedactHeaders 是始终脱敏的头部
+var _defaultRedactHeaders = []string{"Authorization", "Cookie", "Set-Cookie", "Proxy-Authorization"}
+
+// _metricDroppedCaptures 统计了因队列已满而丢弃的抓取记录数量
+var _metricDroppedCaptures = prometheus.NewCounter(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "capture_dropped_total",
+	Help:      "The total number of dropped request captures",
+})
+
+// 包初始化时注册 capture 中间件
+func init() {
+	prometheus.MustRegister(_metricDroppedCaptures)
+	middleware.RegisterV2("capture", Middleware)
+}
+
+// Record 结构体定义了一条完整的请求和响应的抓取记录
+type Record struct {
+	// Time 是请求开始的时间
+	Time time.Time `json:"time"`
+	// Endpoint 是匹配到的端点路径模板
+	Endpoint string `json:"endpoint,omitempty"`
+	// Method 是请求方法
+	Method string `json:"method"`
+	// Host 是请求的主机
+	Host string `json:"host"`
+	// Path 是请求的具体路径
+	Path string `json:"path"`
+	// Query 是请求的查询参数
+	Query string `json:"query,omitempty"`
+	// RequestHeaders 是请求头
+	RequestHeaders http.Header `json:"request_headers"`
+	// RequestBody 是抓取的请求体，二进制内容使用 base64 编码
+	RequestBody string `json:"request_body,omitempty"`
+	// RequestBodyEncoding 为 base64 时表示请求体是 base64 编码的
+	RequestBodyEncoding string `json:"request_body_encoding,omitempty"`
+	// RequestBodyTruncated 表示请求体超过了最大抓取字节数
+	RequestBodyTruncated bool `json:"request_body_truncated,omitempty"`
+	// Status 是响应的状态码，请求失败时为 0
+	Status int `json:"status"`
+	// ResponseHeaders 是响应头
+	ResponseHeaders http.Header `json:"response_headers,omitempty"`
+	// ResponseBody 是抓取的响应体，二进制内容使用 base64 编码
+	ResponseBody string `json:"response_body,omitempty"`
+	// ResponseBodyEncoding 为 base64 时表示响应体是 base64 编码的
+	ResponseBodyEncoding string `json:"response_body_encoding,omitempty"`
+	// ResponseBodyTruncated 表示响应体超过了最大抓取字节数
+	ResponseBodyTruncated bool `json:"response_body_truncated,omitempty"`
+	// LatencyMs 是请求开始到响应体读取完成的耗时，单位为毫秒
+	LatencyMs float64 `json:"latency_ms"`
+	// Error 是请求失败的原因
+	Error string `json:"error,omitempty"`
+}
+
+// capture 结构体保存了一次请求抓取的原始数据，脱敏和编码在上传时完成，不占用请求的处理时间
+type capture struct {
+	start    time.Time
+	latency  time.Duration
+	endpoint string
+	req      *http.Request
+	reqBody  *bodyRecorder
+	resp     *http.Response
+	respBody *bodyRecorder
+	err      error
+}
+
+// bodyRecorder 结构体在请求或响应体被读取时记录前 max 个字节，不会额外读取或缓冲未被读取的数据
+type bodyRecorder struct {
+	io.ReadCloser
+	lock      sync.Mutex
+	max       int
+	buf       []byte
+	truncated bool
+	// onDone 在读到末尾或关闭时调用一次
+	onDone func()
+	once   sync.Once
+}
+
+// Read 方法读取数据并记录
+func (r *bodyRecorder) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		r.lock.Lock()
+		room := r.max - len(r.buf)
+		if room > 0 {
+			r.buf = append(r.buf, p[:min(n, room)]...)
+		}
+		if n > room {
+			r.truncated = true
+		}
+		r.lock.Unlock()
+	}
+	if err != nil {
+		r.done()
+	}
+	return n, err
+}
+
+// Close 方法关闭原始的数据流
+func (r *bodyRecorder) Close() error {
+	err := r.ReadCloser.Close()
+	r.done()
+	return err
+}
+
+// done 方法调用一次 onDone
+func (r *bodyRecorder) done() {
+	if r.onDone != nil {
+		r.once.Do(r.onDone)
+	}
+}
+
+// snapshot 方法返回已记录的数据和是否被截断
+func (r *bodyRecorder) snapshot() ([]byte, bool) {
+	if r == nil {
+		return nil, false
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]byte(nil), r.buf...), r.truncated
+}
+
+// Uploader 结构体将抓取记录异步地上传到对象存储，队列已满时丢弃记录，不会阻塞请求
+type Uploader struct {
+	storage  Storage
+	redactor *redactor
+	captures chan *capture
+	done     chan struct{}
+	closed   chan struct{}
+	once     sync.Once
+}
+
+// newUploader 创建一个新的 Uploader 实例
+func newUploader(storage Storage, redactor *redactor, bufferSize int) *Uploader {
+	if bufferSize <= 0 {
+		bufferSize = _defaultBufferSize
+	}
+	u := &Uploader{
+		storage:  storage,
+		redactor: redactor,
+		captures: make(chan *capture, bufferSize),
+		done:     make(chan struct{}),
+		closed:   make(chan struct{}),
+	}
+	go u.run()
+	return u
+}
+
+// run 方法持续地上传队列中的抓取记录
+func (u *Uploader) run() {
+	defer close(u.closed)
+	for {
+		select {
+		case c := <-u.captures:
+			u.upload(c)
+		case <-u.done:
+			// 关闭前上传队列中剩余的记录
+			for {
+				select {
+				case c := <-u.captures:
+					u.upload(c)
+				default:
+					if err := u.storage.Close(); err != nil {
+						log.Errorf("failed to close capture storage: %v", err)
+					}
+					return
+				}
+			}
+		}
+	}
+}
+
+// upload 方法脱敏并上传一条抓取记录，上传失败时只记录日志
+func (u *Uploader) upload(c *capture) {
+	record := u.redactor.record(c)
+	data, err := json.Marshal(record)
+	if err != nil {
+		log.Errorf("failed to marshal request capture: %v", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), _uploadTimeout)
+	defer cancel()
+	if err := u.storage.Put(ctx, captureKey(record.Time), data); err != nil {
+		log.Errorf("failed to upload request capture: %s %s: %v", record.Method, record.Path, err)
+	}
+}
+
+// Emit 方法提交一条抓取记录，队列已满或已关闭时丢弃该记录
+func (u *Uploader) Emit(c *capture) {
+	select {
+	case <-u.done:
+		return
+	default:
+	}
+	select {
+	case u.captures <- c:
+	default:
+		_metricDroppedCaptures.Inc()
+	}
+}
+
+// Close 方法停止接收新的记录，并等待已提交的记录上传完成
+func (u *Uploader) Close() error {
+	u.once.Do(func() { close(u.done) })
+	<-u.closed
+	return nil
+}
+
+// captureKey 函数按时间生成抓取记录的对象名称，例如 2024/01/02/150405.000000000-0a1b2c3d.json
+func captureKey(t time.Time) string {
+	var suffix [4]byte
+	_, _ = rand.Read(suffix[:])
+	return t.UTC().Format("2006/01/02/150405.000000000") + "-" + hex.EncodeToString(suffix[:]) + ".json"
+}
+
+// redactor 结构体对抓取记录中的敏感头部、查询参数和请求体字段脱敏
+type redactor struct {
+	headers []string
+	fields  map[string]struct{}
+}
+
+// newRedactor 创建一个新的 redactor 实例
+func newRedactor(headers, fields []string) *redactor {
+	r := &redactor{
+		headers: append(append([]string(nil), _defaultRedactHeaders...), headers...),
+		fields:  make(map[string]struct{}, len(fields)),
+	}
+	for _, f := range fields {
+		r.fields[f] = struct{}{}
+	}
+	return r
+}
+
+// record 方法根据原始的抓取数据生成脱敏后的抓取记录
+func (r *redactor) record(c *capture) *Record {
+	record := &Record{
+		Time:           c.start,
+		Endpoint:       c.endpoint,
+		Method:         c.req.Method,
+		Host:           c.req.Host,
+		Path:           c.req.URL.Path,
+		Query:          r.query(c.req.URL.RawQuery),
+		RequestHeaders: r.header(c.req.Header),
+		LatencyMs:      float64(c.latency.Microseconds()) / 1000,
+	}
+	body, truncated := c.reqBody.snapshot()
+	record.RequestBody, record.RequestBodyEncoding = r.body(c.req.Header, body, truncated)
+	record.RequestBodyTruncated = truncated
+	if c.err != nil {
+		record.Error = c.err.Error()
+		return record
+	}
+	record.Status = c.resp.StatusCode
+	record.ResponseHeaders = r.header(c.resp.Header)
+	body, truncated = c.respBody.snapshot()
+	record.ResponseBody, record.ResponseBodyEncoding = r.body(c.resp.Header, body, truncated)
+	record.ResponseBodyTruncated = truncated
+	return record
+}
+
+// header 方法返回脱敏后的头部副本
+func (r *redactor) header(h http.Header) http.Header {
+	out := h.Clone()
+	for _, name := range r.headers {
+		if _, ok := out[http.CanonicalHeaderKey(name)]; ok {
+			out.Set(name, _redacted)
+		}
+	}
+	return out
+}
+
+// query 方法返回脱敏后的查询参数
+func (r *redactor) query(rawQuery string) string {
+	if rawQuery == "" || len(r.fields) == 0 {
+		return rawQuery
+	}
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return _redacted
+	}
+	for k := range values {
+		if _, ok := r.fields[k]; ok {
+			values.Set(k, _redacted)
+		}
+	}
+	return values.Encode()
+}
+
+// body 方法返回脱敏后的消息体以及它的编码，JSON 和表单消息体中的字段被脱敏，
+// 配置了脱敏字段时无法解析的 JSON 消息体（例如被截断）整体脱敏
+func (r *redactor) body(h http.Header, body []byte, truncated bool) (string, string) {
+	if len(body) == 0 {
+		return "", ""
+	}
+	mediaType, _, _ := mime.ParseMediaType(h.Get("Content-Type"))
+	if len(r.fields) > 0 {
+		switch {
+		case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+			var v any
+			if truncated || json.Unmarshal(body, &v) != nil {
+				return _redacted, ""
+			}
+			b, err := json.Marshal(r.redactJSON(v))
+			if err != nil {
+				return _redacted, ""
+			}
+			return string(b), ""
+		case mediaType == "application/x-www-form-urlencoded":
+			return r.query(string(body)), ""
+		}
+	}
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return base64.StdEncoding.EncodeToString(body), "base64"
+}
+
+// redactJSON 方法递归地脱敏 JSON 值中名称匹配的字段
+func (r *redactor) redactJSON(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, child := range val {
+			if _, ok := r.fields[k]; ok {
+				val[k] = _redacted
+				continue
+			}
+			val[k] = r.redactJSON(child)
+		}
+	case []any:
+		for i, child := range val {
+			val[i] = r.redactJSON(child)
+		}
+	}
+	return v
+}
+
+// Middleware 函数根据传入的配置对象 c 创建一个请求抓取中间件实例，按采样率将完整的请求和响应异步上传到对象存储
+func Middleware(c *config.Middleware) (middleware.MiddlewareV2, error) {
+	options := &v1.Capture{}
+	if c.Options != nil {
+		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+	}
+	storage, err := Create(options.Storage)
+	if err != nil {
+		return nil, err
+	}
+	uploader := newUploader(storage, newRedactor(options.RedactHeaders, options.RedactFields), int(options.BufferSize))
+	return newMiddleware(options, uploader), nil
+}
+
+// newMiddleware 函数使用给定的 Uploader 创建请求抓取中间件实例
+func newMiddleware(options *v1.Capture, uploader *Uploader) middleware.MiddlewareV2 {
+	sampleRate := options.SampleRate
+	if sampleRate <= 0 || sampleRate > 1 {
+		sampleRate = _defaultSampleRate
+	}
+	maxBodyBytes := int(options.MaxBodyBytes)
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = _defaultMaxBodyBytes
+	}
+	return middleware.NewWithCloser(func(next http.RoundTripper) http.RoundTripper {
+		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if !captureFeature.Enabled() || mrand.Float64() >= sampleRate {
+				return next.RoundTrip(req)
+			}
+			c := &capture{start: time.Now(), req: req}
+			if reqOpts, ok := middleware.FromRequestContext(req.Context()); ok {
+				c.endpoint = reqOpts.Endpoint.Path
+			}
+			if req.Body != nil && req.Body != http.NoBody {
+				c.reqBody = &bodyRecorder{ReadCloser: req.Body, max: maxBodyBytes}
+				req.Body = c.reqBody
+			}
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				c.err = err
+				c.latency = time.Since(c.start)
+				uploader.Emit(c)
+				return nil, err
+			}
+			c.resp = resp
+			emit := func() {
+				c.latency = time.Since(c.start)
+				uploader.Emit(c)
+			}
+			if resp.Body == nil || resp.Body == http.NoBody {
+				emit()
+				return resp, nil
+			}
+			// 响应体读取完成或被关闭时提交抓取记录
+			c.respBody = &bodyRecorder{ReadCloser: resp.Body, max: maxBodyBytes, onDone: emit}
+			resp.Body = c.respBody
+			return resp, nil
+		})
+	}, uploader)
+}
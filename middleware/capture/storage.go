@@ -0,0 +1,231 @@
+package capture
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// _uploadTimeout 是上传一条抓取记录的超时时间
+const _uploadTimeout = 10 * time.Second
+
+// Storage 接口定义了抓取记录的对象存储
+type Storage interface {
+	// Put 方法将数据写入指定的对象
+	Put(ctx context.Context, key string, data []byte) error
+	// Close 方法关闭对象存储
+	Close() error
+}
+
+// Factory 是一个工厂函数，用于根据 DSN 创建对象存储
+type Factory func(dsn *url.URL) (Storage, error)
+
+// globalFactories 保存了所有已注册的对象存储工厂
+var globalFactories = map[string]Factory{}
+
+// Register 注册一个对象存储工厂
+func Register(name string, factory Factory) {
+	globalFactories[name] = factory
+}
+
+func init() {
+	Register("s3", newS3Storage)
+}
+
+// Create 根据给定的 DSN 创建一个对象存储实例
+func Create(storageDSN string) (Storage, error) {
+	if storageDSN == "" {
+		return nil, fmt.Errorf("storageDSN is empty")
+	}
+	dsn, err := url.Parse(storageDSN)
+	if err != nil {
+		return nil, fmt.Errorf("parse storageDSN error: %s", err)
+	}
+	factory, ok := globalFactories[dsn.Scheme]
+	if !ok {
+		return nil, fmt.Errorf("capture storage %s has not been registered", dsn.Scheme)
+	}
+	return factory(dsn)
+}
+
+// s3Storage 结构体通过 S3 兼容的 PutObject 接口上传对象，使用路径风格的地址和 AWS Signature Version 4 签名
+type s3Storage struct {
+	endpoint  *url.URL
+	bucket    string
+	prefix    string
+	region    string
+	accessKey string
+	secretKey string
+	client    *http.Client
+	now       func() time.Time
+}
+
+// newS3Storage 根据 s3://bucket/prefix?endpoint=https://host:port&region=us-east-1 格式的 DSN 创建一个 S3 兼容的对象存储，
+// 未指定 endpoint 时使用 AWS S3 的地址
+func newS3Storage(dsn *url.URL) (Storage, error) {
+	if dsn.Host == "" {
+		return nil, fmt.Errorf("s3 bucket is empty")
+	}
+	query := dsn.Query()
+	region := query.Get("region")
+	if region == "" {
+		region = "us-east-1"
+	}
+	rawEndpoint := query.Get("endpoint")
+	if rawEndpoint == "" {
+		rawEndpoint = "https://s3." + region + ".amazonaws.com"
+	}
+	endpoint, err := url.Parse(rawEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("parse s3 endpoint error: %s", err)
+	}
+	accessKey, secretKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	if accessKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY are required for s3 storage")
+	}
+	return &s3Storage{
+		endpoint:  endpoint,
+		bucket:    dsn.Host,
+		prefix:    strings.Trim(dsn.Path, "/"),
+		region:    region,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		client:    &http.Client{Timeout: _uploadTimeout},
+		now:       time.Now,
+	}, nil
+}
+
+// Put 方法上传一个对象
+func (s *s3Storage) Put(ctx context.Context, key string, data []byte) error {
+	if s.prefix != "" {
+		key = s.prefix + "/" + key
+	}
+	target := *s.endpoint
+	target.Path = strings.TrimSuffix(target.Path, "/") + "/" + s.bucket + "/" + key
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, target.String(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	sum := sha256.Sum256(data)
+	signV4(req, hex.EncodeToString(sum[:]), s.accessKey, s.secretKey, s.region, "s3", s.now())
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected s3 status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close 方法关闭空闲连接
+func (s *s3Storage) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// signV4 函数使用 AWS Signature Version 4 为请求签名，签名覆盖 host 以及所有 x-amz-*、content-type 和 range 请求头
+func signV4(req *http.Request, payloadHash, accessKey, secretKey, region, service string, t time.Time) {
+	t = t.UTC()
+	amzDate := t.Format("20060102T150405Z")
+	date := t.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		name = strings.ToLower(name)
+		if strings.HasPrefix(name, "x-amz-") || name == "content-type" || name == "range" {
+			headers[name] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL.EscapedPath()),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s,SignedHeaders=%s,Signature=%s", accessKey, scope, signedHeaders, signature))
+}
+
+// hmacSHA256 函数计算 HMAC-SHA256
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// canonicalURI 函数按 SigV4 的规则编码路径，除未保留字符和 / 以外的字符都需要百分号编码
+func canonicalURI(escapedPath string) string {
+	if escapedPath == "" {
+		return "/"
+	}
+	path, err := url.PathUnescape(escapedPath)
+	if err != nil {
+		path = escapedPath
+	}
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		segments[i] = uriEncode(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery 函数按 SigV4 的规则编码并排序查询参数
+func canonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for k, values := range query {
+		for _, v := range values {
+			pairs = append(pairs, uriEncode(k)+"="+uriEncode(v))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode 函数编码除未保留字符以外的所有字符
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
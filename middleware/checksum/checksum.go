@@ -0,0 +1,136 @@
+package checksum
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/checksum/v1"
+	"github.com/cnsync/gateway/middleware"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// 包初始化时注册 checksum 中间件
+func init() {
+	middleware.Register("checksum", Middleware)
+}
+
+// _algorithms 是支持的摘要算法，键为小写的算法名称
+var _algorithms = map[string]func() hash.Hash{
+	"md5":     md5.New,
+	"sha-256": sha256.New,
+}
+
+// digest 结构体是请求头中声明的一个请求体摘要
+type digest struct {
+	// algorithm 是小写的算法名称
+	algorithm string
+	// value 是 base64 编码的摘要
+	value string
+}
+
+// parseDigests 函数从请求头中解析所有支持的请求体摘要，支持以下格式：
+//
+//	Content-MD5: <base64>
+//	Digest: MD5=<base64>, SHA-256=<base64>
+//	Content-Digest: sha-256=:<base64>:
+func parseDigests(header http.Header) []digest {
+	var digests []digest
+	if v := header.Get("Content-MD5"); v != "" {
+		digests = append(digests, digest{algorithm: "md5", value: strings.TrimSpace(v)})
+	}
+	for _, name := range []string{"Digest", "Content-Digest"} {
+		for _, v := range header.Values(name) {
+			for _, item := range strings.Split(v, ",") {
+				algorithm, value, ok := strings.Cut(strings.TrimSpace(item), "=")
+				if !ok {
+					continue
+				}
+				algorithm = strings.ToLower(strings.TrimSpace(algorithm))
+				if _, ok := _algorithms[algorithm]; !ok {
+					// 忽略不支持的算法
+					continue
+				}
+				// Content-Digest 的值是以冒号包裹的字节序列
+				value = strings.Trim(strings.TrimSpace(value), ":")
+				digests = append(digests, digest{algorithm: algorithm, value: value})
+			}
+		}
+	}
+	return digests
+}
+
+// verify 函数校验请求体是否与所有声明的摘要一致
+func verify(body []byte, digests []digest) error {
+	for _, d := range digests {
+		want, err := base64.StdEncoding.DecodeString(d.value)
+		if err != nil {
+			return fmt.Errorf("invalid %s digest: %w", d.algorithm, err)
+		}
+		h := _algorithms[d.algorithm]()
+		h.Write(body)
+		if subtle.ConstantTimeCompare(h.Sum(nil), want) != 1 {
+			return fmt.Errorf("%s digest mismatch", d.algorithm)
+		}
+	}
+	return nil
+}
+
+// newResponse 函数创建一个 400 响应
+func newResponse(req *http.Request, reason string) *http.Response {
+	return &http.Response{
+		StatusCode:    http.StatusBadRequest,
+		Header:        http.Header{"Content-Type": []string{"text/plain; charset=utf-8"}},
+		Body:          io.NopCloser(bytes.NewBufferString(reason)),
+		ContentLength: int64(len(reason)),
+		Request:       req,
+	}
+}
+
+// Middleware 函数根据传入的配置对象 c 创建一个请求体校验中间件实例，
+// 请求体与 Content-MD5、Digest 或 Content-Digest 头中声明的摘要不一致时返回 400
+func Middleware(c *config.Middleware) (middleware.Middleware, error) {
+	options := &v1.Checksum{}
+	if c.Options != nil {
+		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			digests := parseDigests(req.Header)
+			if len(digests) == 0 {
+				if options.Required {
+					return newResponse(req, "checksum required"), nil
+				}
+				return next.RoundTrip(req)
+			}
+			var body []byte
+			if req.Body != nil {
+				var err error
+				if body, err = io.ReadAll(req.Body); err != nil {
+					return nil, err
+				}
+				req.Body.Close()
+			}
+			if err := verify(body, digests); err != nil {
+				return newResponse(req, err.Error()), nil
+			}
+			// 重新设置请求体，以便转发给上游
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			req.GetBody = func() (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewReader(body)), nil
+			}
+			return next.RoundTrip(req)
+		})
+	}, nil
+}
@@ -0,0 +1,78 @@
+package checksum
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/checksum/v1"
+	"github.com/cnsync/gateway/middleware"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestChecksum(t *testing.T) {
+	body := `{"hello":"world"}`
+	md5Sum := md5.Sum([]byte(body))
+	sha256Sum := sha256.Sum256([]byte(body))
+	md5Value := base64.StdEncoding.EncodeToString(md5Sum[:])
+	sha256Value := base64.StdEncoding.EncodeToString(sha256Sum[:])
+	wrong := base64.StdEncoding.EncodeToString([]byte("wrong"))
+
+	tests := []struct {
+		name     string
+		header   http.Header
+		required bool
+		want     int
+	}{
+		{"content-md5", http.Header{"Content-Md5": {md5Value}}, false, 200},
+		{"content-md5-mismatch", http.Header{"Content-Md5": {wrong}}, false, 400},
+		{"digest-md5", http.Header{"Digest": {"MD5=" + md5Value}}, false, 200},
+		{"digest-sha256", http.Header{"Digest": {"SHA-256=" + sha256Value}}, false, 200},
+		{"digest-both", http.Header{"Digest": {"MD5=" + md5Value + ", SHA-256=" + sha256Value}}, false, 200},
+		{"digest-one-mismatch", http.Header{"Digest": {"MD5=" + md5Value + ", SHA-256=" + wrong}}, false, 400},
+		{"digest-unsupported", http.Header{"Digest": {"UNIXsum=30637"}}, false, 200},
+		{"content-digest", http.Header{"Content-Digest": {"sha-256=:" + sha256Value + ":"}}, false, 200},
+		{"content-digest-mismatch", http.Header{"Content-Digest": {"sha-256=:" + wrong + ":"}}, false, 400},
+		{"invalid-base64", http.Header{"Content-Md5": {"!!!"}}, false, 400},
+		{"missing", http.Header{}, false, 200},
+		{"missing-required", http.Header{}, true, 400},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options, err := anypb.New(&v1.Checksum{Required: tt.required})
+			if err != nil {
+				t.Fatal(err)
+			}
+			m, err := Middleware(&config.Middleware{Options: options})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var upstream []byte
+			next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				upstream, _ = io.ReadAll(req.Body)
+				return &http.Response{StatusCode: http.StatusOK}, nil
+			})
+			req := httptest.NewRequest("PUT", "/upload", bytes.NewBufferString(body))
+			for k, v := range tt.header {
+				req.Header[k] = v
+			}
+			resp, err := m(next).RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.want {
+				t.Fatalf("want status %d but got %d", tt.want, resp.StatusCode)
+			}
+			// 校验通过后上游收到完整的请求体
+			if tt.want == http.StatusOK && string(upstream) != body {
+				t.Fatalf("want upstream body %q but got %q", body, upstream)
+			}
+		})
+	}
+}
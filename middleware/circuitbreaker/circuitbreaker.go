@@ -125,16 +125,11 @@ func makeOnBreakHandler(buildContext *client.BuildContext, in *v1.CircuitBreaker
 			resp.Body = io.NopCloser(bytes.NewReader(action.ResponseData.Body))
 			return resp, nil
 		}), io.NopCloser(nil), nil
+	case nil:
+		return middleware.NewRejectHandler(in.RejectResponse, http.StatusServiceUnavailable), io.NopCloser(nil), nil
 	default:
 		log.Warnf("Unrecoginzed circuit breaker aciton: %+v", action)
-		return middleware.RoundTripperFunc(func(*http.Request) (*http.Response, error) {
-			// TBD: on break response
-			return &http.Response{
-				StatusCode: http.StatusServiceUnavailable,
-				Header:     http.Header{},
-				Body:       io.NopCloser(&bytes.Buffer{}),
-			}, nil
-		}), io.NopCloser(nil), nil
+		return middleware.NewRejectHandler(in.RejectResponse, http.StatusServiceUnavailable), io.NopCloser(nil), nil
 	}
 }
 
@@ -165,16 +160,30 @@ func New(factory client.Factory) middleware.FactoryV2 {
 		}
 		assertCondtions, err := condition.ParseConditon(options.AssertCondtions...)
 		if err != nil {
+			closer.Close()
+			return nil, err
+		}
+		shared, err := newSharedState(options)
+		if err != nil {
+			closer.Close()
 			return nil, err
 		}
+		if shared != nil {
+			closer = multiCloser{closer, shared}
+		}
 
 		return middleware.NewWithCloser(func(next http.RoundTripper) http.RoundTripper {
 			return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				if shared.isOpen() {
+					deniedRequestIncr(req)
+					return onBreakHandler.RoundTrip(req)
+				}
 				if err := breaker.Allow(); err != nil {
 					// rejected
 					// NOTE: when client reject requests locally,
 					// continue add counter let the drop ratio higher.
 					breaker.MarkFailed()
+					shared.trip()
 					deniedRequestIncr(req)
 					return onBreakHandler.RoundTrip(req)
 				}
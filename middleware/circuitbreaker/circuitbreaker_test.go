@@ -0,0 +1,132 @@
+package circuitbreaker
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/circuitbreaker/v1"
+	"github.com/cnsync/gateway/middleware"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func TestRejectResponse(t *testing.T) {
+	tests := []struct {
+		name       string
+		reject     *config.RejectResponse
+		wantStatus int
+		wantBody   string
+		wantHeader string
+	}{
+		{name: "default", wantStatus: http.StatusServiceUnavailable},
+		{
+			name: "custom",
+			reject: &config.RejectResponse{
+				StatusCode: http.StatusTooManyRequests,
+				Headers:    map[string]string{"Content-Type": "application/json", "Retry-After": "30"},
+				Body:       []byte(`{"error":"circuit open","retry_after":30}`),
+			},
+			wantStatus: http.StatusTooManyRequests,
+			wantBody:   `{"error":"circuit open","retry_after":30}`,
+			wantHeader: "30",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 比例为 0 时熔断器拒绝所有请求
+			cfg, err := anypb.New(&v1.CircuitBreaker{
+				Trigger:        &v1.CircuitBreaker_Ratio{Ratio: 0},
+				RejectResponse: tt.reject,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			m, err := New(nil)(&config.Middleware{Options: cfg})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer m.Close()
+			next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				t.Fatal("want the request rejected")
+				return nil, nil
+			})
+			resp, err := m.Process(next).RoundTrip(httptest.NewRequest("GET", "/", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if resp.StatusCode != tt.wantStatus || string(body) != tt.wantBody || resp.Header.Get("Retry-After") != tt.wantHeader {
+				t.Fatalf("unexpected reject response: %d %v %q", resp.StatusCode, resp.Header, body)
+			}
+		})
+	}
+}
+
+func TestSharedState(t *testing.T) {
+	mr := miniredis.RunT(t)
+	newInstance := func(ratio int64) middleware.MiddlewareV2 {
+		cfg, err := anypb.New(&v1.CircuitBreaker{
+			Trigger: &v1.CircuitBreaker_Ratio{Ratio: ratio},
+			SharedState: &v1.SharedState{
+				Redis:        "redis://" + mr.Addr(),
+				Key:          "breaker",
+				OpenDuration: durationpb.New(300 * time.Millisecond),
+				SyncInterval: durationpb.New(10 * time.Millisecond),
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := New(nil)(&config.Middleware{Options: cfg})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { m.Close() })
+		return m
+	}
+	next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	})
+	status := func(m middleware.MiddlewareV2) int {
+		resp, err := m.Process(next).RoundTrip(httptest.NewRequest("GET", "/", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.StatusCode
+	}
+	waitStatus := func(m middleware.MiddlewareV2, want int) {
+		deadline := time.Now().Add(2 * time.Second)
+		for status(m) != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("want status %d", want)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	// 实例 a 的熔断器拒绝所有请求，实例 b 的熔断器放行所有请求
+	a, b := newInstance(0), newInstance(10000)
+	if got := status(b); got != http.StatusOK {
+		t.Fatalf("want b to allow requests before a opens, got %d", got)
+	}
+	if got := status(a); got != http.StatusServiceUnavailable {
+		t.Fatalf("want a to reject requests, got %d", got)
+	}
+	// a 打开后 b 同步到共享状态也开始拒绝请求
+	waitStatus(b, http.StatusServiceUnavailable)
+	// 共享状态过期后 b 恢复放行
+	waitStatus(b, http.StatusOK)
+
+	// redis 不可用时 b 只按本地熔断器放行
+	mr.Set("breaker", strconv.FormatInt(time.Now().Add(time.Hour).UnixNano(), 10))
+	waitStatus(b, http.StatusServiceUnavailable)
+	mr.Close()
+	waitStatus(b, http.StatusOK)
+}
@@ -0,0 +1,162 @@
+package circuitbreaker
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/circuitbreaker/v1"
+	"github.com/cnsync/kratos/log"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	_defaultOpenDuration = 10 * time.Second
+	_defaultSyncInterval = time.Second
+	_redisTimeout        = 500 * time.Millisecond
+)
+
+// sharedState 通过 redis 在多个网关实例之间共享熔断器的打开状态，
+// redis 不可用时视为未打开，只由本地熔断器决定是否放行
+type sharedState struct {
+	client       *redis.Client
+	key          string
+	openDuration time.Duration
+	syncInterval time.Duration
+	openUntil    atomic.Int64
+	lastPublish  atomic.Int64
+	now          func() time.Time
+	cancel       context.CancelFunc
+	done         chan struct{}
+}
+
+func sharedStateKey(in *v1.CircuitBreaker) (string, error) {
+	if in.SharedState.Key != "" {
+		return in.SharedState.Key, nil
+	}
+	cfg := proto.Clone(in).(*v1.CircuitBreaker)
+	cfg.SharedState = nil
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(cfg)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return "gateway:circuitbreaker:" + hex.EncodeToString(sum[:8]), nil
+}
+
+func newSharedState(in *v1.CircuitBreaker) (*sharedState, error) {
+	if in.SharedState == nil {
+		return nil, nil
+	}
+	opts, err := redis.ParseURL(in.SharedState.Redis)
+	if err != nil {
+		return nil, err
+	}
+	key, err := sharedStateKey(in)
+	if err != nil {
+		return nil, err
+	}
+	s := &sharedState{
+		client:       redis.NewClient(opts),
+		key:          key,
+		openDuration: _defaultOpenDuration,
+		syncInterval: _defaultSyncInterval,
+		now:          time.Now,
+		done:         make(chan struct{}),
+	}
+	if d := in.SharedState.OpenDuration; d != nil && d.AsDuration() > 0 {
+		s.openDuration = d.AsDuration()
+	}
+	if d := in.SharedState.SyncInterval; d != nil && d.AsDuration() > 0 {
+		s.syncInterval = d.AsDuration()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	go s.run(ctx)
+	return s, nil
+}
+
+func (s *sharedState) run(ctx context.Context) {
+	defer close(s.done)
+	ticker := time.NewTicker(s.syncInterval)
+	defer ticker.Stop()
+	for {
+		s.sync(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *sharedState) sync(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, _redisTimeout)
+	defer cancel()
+	value, err := s.client.Get(ctx, s.key).Result()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) && ctx.Err() == nil {
+			log.Warnf("failed to get shared circuit breaker state %s: %v", s.key, err)
+		}
+		s.openUntil.Store(0)
+		return
+	}
+	openUntil, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		log.Warnf("invalid shared circuit breaker state %s: %q", s.key, value)
+		s.openUntil.Store(0)
+		return
+	}
+	s.openUntil.Store(openUntil)
+}
+
+func (s *sharedState) isOpen() bool {
+	if s == nil {
+		return false
+	}
+	return s.now().UnixNano() < s.openUntil.Load()
+}
+
+// trip 发布熔断器的打开状态，每半个打开时长内最多发布一次
+func (s *sharedState) trip() {
+	if s == nil {
+		return
+	}
+	now := s.now().UnixNano()
+	last := s.lastPublish.Load()
+	if now-last < int64(s.openDuration/2) || !s.lastPublish.CompareAndSwap(last, now) {
+		return
+	}
+	openUntil := now + int64(s.openDuration)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), _redisTimeout)
+		defer cancel()
+		if err := s.client.Set(ctx, s.key, strconv.FormatInt(openUntil, 10), s.openDuration).Err(); err != nil {
+			log.Warnf("failed to publish shared circuit breaker state %s: %v", s.key, err)
+		}
+	}()
+}
+
+func (s *sharedState) Close() error {
+	s.cancel()
+	<-s.done
+	return s.client.Close()
+}
+
+type multiCloser []io.Closer
+
+func (m multiCloser) Close() error {
+	var errs []error
+	for _, c := range m {
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,138 @@
+package cookie
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/cookie/v1"
+	"github.com/cnsync/gateway/middleware"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// 包初始化时注册 cookie 中间件
+func init() {
+	middleware.Register("cookie", Middleware)
+}
+
+// Middleware 函数根据传入的配置对象 c 创建一个改写响应 Set-Cookie 属性的中间件实例
+func Middleware(c *config.Middleware) (middleware.Middleware, error) {
+	options := &v1.Cookie{}
+	if c.Options != nil {
+		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+	}
+	switch strings.ToLower(options.SameSite) {
+	case "", "strict", "lax", "none":
+	default:
+		return nil, fmt.Errorf("invalid same_site: %s", options.SameSite)
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				return nil, err
+			}
+			// 逐条改写 Set-Cookie，保留多个 Set-Cookie 的顺序
+			cookies := resp.Header.Values("Set-Cookie")
+			if len(cookies) == 0 {
+				return resp, nil
+			}
+			rewritten := make([]string, 0, len(cookies))
+			for _, cookie := range cookies {
+				rewritten = append(rewritten, rewriteCookie(options, cookie))
+			}
+			resp.Header["Set-Cookie"] = rewritten
+			return resp, nil
+		})
+	}, nil
+}
+
+// rewriteCookie 函数按照配置改写一条 Set-Cookie 的属性，未涉及的属性保持原样
+func rewriteCookie(options *v1.Cookie, cookie string) string {
+	parts := strings.Split(cookie, ";")
+	out := make([]string, 0, len(parts)+3)
+	// 第一部分是 name=value，保持原样
+	out = append(out, strings.TrimSpace(parts[0]))
+	hasSecure, hasHTTPOnly := false, false
+	for _, part := range parts[1:] {
+		attr := strings.TrimSpace(part)
+		if attr == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(attr, "=")
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "domain":
+			domain, ok := rewriteDomain(options.DomainRewrite, value)
+			if !ok {
+				continue
+			}
+			attr = "Domain=" + domain
+		case "path":
+			attr = "Path=" + rewritePath(options.PathRewrite, value)
+		case "samesite":
+			if options.SameSite != "" {
+				continue
+			}
+		case "secure":
+			hasSecure = true
+		case "httponly":
+			hasHTTPOnly = true
+		}
+		out = append(out, attr)
+	}
+	if options.Secure && !hasSecure {
+		out = append(out, "Secure")
+	}
+	if options.HttpOnly && !hasHTTPOnly {
+		out = append(out, "HttpOnly")
+	}
+	if options.SameSite != "" {
+		out = append(out, "SameSite="+canonicalSameSite(options.SameSite))
+	}
+	return strings.Join(out, "; ")
+}
+
+// rewriteDomain 函数改写 Domain 属性，返回 false 表示删除该属性
+func rewriteDomain(rewrite map[string]string, domain string) (string, bool) {
+	to, ok := rewrite[strings.ToLower(strings.TrimPrefix(domain, "."))]
+	if !ok {
+		to, ok = rewrite[strings.ToLower(domain)]
+	}
+	if !ok {
+		to, ok = rewrite["*"]
+	}
+	if !ok {
+		return domain, true
+	}
+	return to, to != ""
+}
+
+// rewritePath 函数使用最长匹配的前缀改写 Path 属性
+func rewritePath(rewrite map[string]string, path string) string {
+	matched := ""
+	for prefix := range rewrite {
+		if strings.HasPrefix(path, prefix) && len(prefix) > len(matched) {
+			matched = prefix
+		}
+	}
+	if matched == "" {
+		return path
+	}
+	return rewrite[matched] + strings.TrimPrefix(path, matched)
+}
+
+// canonicalSameSite 函数返回规范化的 SameSite 属性值
+func canonicalSameSite(in string) string {
+	switch strings.ToLower(in) {
+	case "strict":
+		return "Strict"
+	case "lax":
+		return "Lax"
+	default:
+		return "None"
+	}
+}
@@ -0,0 +1,87 @@
+package cookie
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/cookie/v1"
+	"github.com/cnsync/gateway/middleware"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func buildConfig(t *testing.T, options *v1.Cookie) *config.Middleware {
+	v, err := anypb.New(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &config.Middleware{Name: "cookie", Options: v}
+}
+
+func TestCookie(t *testing.T) {
+	upstream := []string{
+		"session=abc; Path=/api/v1/users; Domain=.internal.svc; SameSite=Lax",
+		"theme=dark; Path=/; Max-Age=3600; Secure",
+		"tracking=1; Domain=cdn.internal.svc; Partitioned",
+	}
+	tests := []struct {
+		name    string
+		options *v1.Cookie
+		want    []string
+	}{
+		{
+			name:    "preserve",
+			options: &v1.Cookie{},
+			want: []string{
+				"session=abc; Path=/api/v1/users; Domain=.internal.svc; SameSite=Lax",
+				"theme=dark; Path=/; Max-Age=3600; Secure",
+				"tracking=1; Domain=cdn.internal.svc; Partitioned",
+			},
+		},
+		{
+			name: "rewrite",
+			options: &v1.Cookie{
+				DomainRewrite: map[string]string{"internal.svc": "example.com", "*": ""},
+				PathRewrite:   map[string]string{"/api": "/gateway", "/api/v1": "/v1"},
+				Secure:        true,
+				HttpOnly:      true,
+				SameSite:      "strict",
+			},
+			want: []string{
+				"session=abc; Path=/v1/users; Domain=example.com; Secure; HttpOnly; SameSite=Strict",
+				"theme=dark; Path=/; Max-Age=3600; Secure; HttpOnly; SameSite=Strict",
+				"tracking=1; Partitioned; Secure; HttpOnly; SameSite=Strict",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Middleware(buildConfig(t, tt.options))
+			if err != nil {
+				t.Fatal(err)
+			}
+			next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				header := http.Header{}
+				for _, c := range upstream {
+					header.Add("Set-Cookie", c)
+				}
+				return &http.Response{StatusCode: http.StatusOK, Header: header}, nil
+			})
+			resp, err := m(next).RoundTrip(httptest.NewRequest("GET", "/", nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := resp.Header.Values("Set-Cookie"); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("want %q but got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestCookieInvalidSameSite(t *testing.T) {
+	if _, err := Middleware(buildConfig(t, &v1.Cookie{SameSite: "always"})); err == nil {
+		t.Fatal("want error for invalid same_site")
+	}
+}
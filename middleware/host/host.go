@@ -0,0 +1,79 @@
+package host
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/host/v1"
+	"github.com/cnsync/gateway/middleware"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// 包初始化时注册 host 中间件
+func init() {
+	middleware.Register("host", Middleware)
+}
+
+// hostname 函数返回去掉端口并转换为小写的主机名
+func hostname(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
+
+// isHostAllowed 函数判断主机名是否在允许列表中，*.example.com 匹配 example.com 的所有子域名
+func isHostAllowed(host string, allowedHosts []string) bool {
+	if host == "" {
+		return false
+	}
+	for _, allowed := range allowedHosts {
+		allowed = strings.ToLower(allowed)
+		if allowed == "*" {
+			return true
+		}
+		if strings.HasPrefix(allowed, "*.") {
+			if strings.HasSuffix(host, allowed[1:]) {
+				return true
+			}
+			continue
+		}
+		if host == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// Middleware 函数根据传入的配置对象 c 创建一个 Host 校验中间件实例，
+// 请求的 Host（HTTP/2 中的 :authority）不在允许列表中时返回 400，或者改写为配置的 fallback_host
+func Middleware(c *config.Middleware) (middleware.Middleware, error) {
+	options := &v1.Host{}
+	if c.Options != nil {
+		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if isHostAllowed(hostname(req.Host), options.AllowedHosts) {
+				return next.RoundTrip(req)
+			}
+			if options.FallbackHost == "" {
+				return &http.Response{
+					StatusCode: http.StatusBadRequest,
+					Header:     http.Header{},
+					Body:       io.NopCloser(&bytes.Buffer{}),
+				}, nil
+			}
+			// 丢弃不可信的 Host，避免其被上游用于生成链接或缓存键
+			req.Host = options.FallbackHost
+			return next.RoundTrip(req)
+		})
+	}, nil
+}
@@ -0,0 +1,67 @@
+package host
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/host/v1"
+	"github.com/cnsync/gateway/middleware"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func buildConfig(options *v1.Host) *config.Middleware {
+	v, err := anypb.New(options)
+	if err != nil {
+		panic(err)
+	}
+	return &config.Middleware{Options: v}
+}
+
+func TestHost(t *testing.T) {
+	allowed := []string{"api.example.com", "*.example.org"}
+	tests := []struct {
+		name       string
+		options    *v1.Host
+		host       string
+		statusCode int
+		upstream   string
+	}{
+		{"allowed", &v1.Host{AllowedHosts: allowed}, "api.example.com", 200, "api.example.com"},
+		{"allowed-with-port", &v1.Host{AllowedHosts: allowed}, "API.example.com:8443", 200, "API.example.com:8443"},
+		{"disallowed", &v1.Host{AllowedHosts: allowed}, "evil.com", 400, ""},
+		{"disallowed-suffix", &v1.Host{AllowedHosts: allowed}, "api.example.com.evil.com", 400, ""},
+		{"wildcard", &v1.Host{AllowedHosts: allowed}, "www.example.org", 200, "www.example.org"},
+		{"wildcard-nested", &v1.Host{AllowedHosts: allowed}, "a.b.example.org", 200, "a.b.example.org"},
+		{"wildcard-apex", &v1.Host{AllowedHosts: allowed}, "example.org", 400, ""},
+		{"wildcard-lookalike", &v1.Host{AllowedHosts: allowed}, "evilexample.org", 400, ""},
+		{"empty", &v1.Host{AllowedHosts: allowed}, "", 400, ""},
+		{"fallback", &v1.Host{AllowedHosts: allowed, FallbackHost: "api.example.com"}, "evil.com", 200, "api.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Middleware(buildConfig(tt.options))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var upstream string
+			next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				upstream = req.Host
+				return &http.Response{StatusCode: http.StatusOK}, nil
+			})
+			req := httptest.NewRequest("GET", "/api/echo", nil)
+			req.Host = tt.host
+			resp, err := m(next).RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.statusCode {
+				t.Fatalf("want status %d but got %d", tt.statusCode, resp.StatusCode)
+			}
+			if upstream != tt.upstream {
+				t.Fatalf("want upstream host %q but got %q", tt.upstream, upstream)
+			}
+		})
+	}
+}
@@ -46,6 +46,7 @@ func Middleware(c *config.Middleware) (middleware.Middleware, error) {
 				"backend_code", reqOpt.UpstreamStatusCode,
 				"backend_latency", reqOpt.UpstreamResponseTime,
 				"last_attempt", reqOpt.LastAttempt,
+				"attributes", middleware.RequestAttributes(ctx),
 			)
 			return reply, err
 		})
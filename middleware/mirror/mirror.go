@@ -0,0 +1,198 @@
+package mirror
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/mirror/v1"
+	"github.com/cnsync/gateway/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const (
+	// _defaultMaxOutstanding 是默认同时进行的镜像请求的最大数量
+	_defaultMaxOutstanding = 100
+	// _defaultTimeout 是默认镜像请求的超时时间
+	_defaultTimeout = time.Second
+)
+
+const (
+	// _resultSuccess 表示镜像请求成功
+	_resultSuccess = "success"
+	// _resultFailure 表示镜像请求失败或影子后端返回 5xx
+	_resultFailure = "failure"
+	// _resultDropped 表示同时进行的镜像请求过多，请求没有被镜像
+	_resultDropped = "dropped"
+)
+
+var _metricMirrorRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "mirror_requests_total",
+	Help:      "The total number of requests mirrored to the shadow backend by result",
+}, []string{"protocol", "method", "path", "service", "basePath", "result"})
+
+// 包初始化时注册 mirror 中间件
+func init() {
+	middleware.RegisterV2("mirror", Middleware)
+	prometheus.MustRegister(_metricMirrorRequestsTotal)
+}
+
+func mirrorRequestIncr(req *http.Request, result string) {
+	labels, ok := middleware.MetricsLabelsFromContext(req.Context())
+	if ok {
+		_metricMirrorRequestsTotal.WithLabelValues(labels.Protocol(), labels.Method(), labels.Path(), labels.Service(), labels.BasePath(), result).Inc()
+	}
+}
+
+// mirror 结构体将采样的请求复制后异步发往影子后端，slots 限制了同时进行的镜像请求数量
+type mirror struct {
+	target     *url.URL
+	percentage float64
+	timeout    time.Duration
+	slots      chan struct{}
+	transport  *http.Transport
+	random     func() float64
+}
+
+// sampled 方法判断请求是否需要镜像
+func (m *mirror) sampled() bool {
+	return m.percentage >= 100 || m.random()*100 < m.percentage
+}
+
+// readBody 函数读取请求体用于镜像，并替换请求体使主请求仍然可以完整地读取
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(body)
+	}
+	b, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	return b, err
+}
+
+// newMirrorRequest 方法复制请求并将其发往影子后端，上下文不随主请求取消
+func (m *mirror) newMirrorRequest(ctx context.Context, req *http.Request, body []byte) *http.Request {
+	out := req.Clone(ctx)
+	out.URL.Scheme = m.target.Scheme
+	out.URL.Host = m.target.Host
+	out.Host = m.target.Host
+	out.RequestURI = ""
+	out.Body = http.NoBody
+	out.GetBody = nil
+	out.ContentLength = int64(len(body))
+	if len(body) > 0 {
+		out.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return out
+}
+
+// send 方法发送镜像请求，丢弃响应并记录结果，完成后释放占用的名额
+func (m *mirror) send(req, out *http.Request) {
+	defer func() { <-m.slots }()
+	defer out.Body.Close()
+	resp, err := m.transport.RoundTrip(out)
+	if err != nil {
+		mirrorRequestIncr(req, _resultFailure)
+		return
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusInternalServerError {
+		mirrorRequestIncr(req, _resultFailure)
+		return
+	}
+	mirrorRequestIncr(req, _resultSuccess)
+}
+
+// process 方法在采样的请求发往上游之前启动镜像请求，同时进行的镜像请求达到上限时不镜像
+func (m *mirror) process(req *http.Request) {
+	if !m.sampled() {
+		return
+	}
+	select {
+	case m.slots <- struct{}{}:
+	default:
+		mirrorRequestIncr(req, _resultDropped)
+		return
+	}
+	body, err := readBody(req)
+	if err != nil {
+		<-m.slots
+		mirrorRequestIncr(req, _resultFailure)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(req.Context()), m.timeout)
+	out := m.newMirrorRequest(ctx, req, body)
+	go func() {
+		defer cancel()
+		m.send(req, out)
+	}()
+}
+
+// Close 方法关闭影子后端的空闲连接
+func (m *mirror) Close() error {
+	m.transport.CloseIdleConnections()
+	return nil
+}
+
+// Middleware 函数根据传入的配置对象 c 创建一个流量镜像中间件实例，
+// 将采样的请求复制后异步发往影子后端，影子后端的响应和错误被丢弃，不影响主请求的延迟和结果
+func Middleware(c *config.Middleware) (middleware.MiddlewareV2, error) {
+	options := &v1.Mirror{}
+	if c.Options != nil {
+		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+	}
+	target, err := url.Parse(options.Target)
+	if err != nil {
+		return nil, fmt.Errorf("mirror: invalid target: %w", err)
+	}
+	if target.Scheme != "http" && target.Scheme != "https" || target.Host == "" {
+		return nil, fmt.Errorf("mirror: invalid target: %q", options.Target)
+	}
+	percentage := options.Percentage
+	if percentage <= 0 || percentage > 100 {
+		percentage = 100
+	}
+	maxOutstanding := int(options.MaxOutstanding)
+	if maxOutstanding <= 0 {
+		maxOutstanding = _defaultMaxOutstanding
+	}
+	timeout := _defaultTimeout
+	if options.Timeout != nil && options.Timeout.AsDuration() > 0 {
+		timeout = options.Timeout.AsDuration()
+	}
+	m := &mirror{
+		target:     target,
+		percentage: percentage,
+		timeout:    timeout,
+		slots:      make(chan struct{}, maxOutstanding),
+		transport:  http.DefaultTransport.(*http.Transport).Clone(),
+		random:     rand.Float64,
+	}
+	return middleware.NewWithCloser(func(next http.RoundTripper) http.RoundTripper {
+		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			m.process(req)
+			return next.RoundTrip(req)
+		})
+	}, m), nil
+}
@@ -0,0 +1,113 @@
+package mirror
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/mirror/v1"
+	"github.com/cnsync/gateway/middleware"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func newMiddleware(t *testing.T, options *v1.Mirror) http.RoundTripper {
+	v, err := anypb.New(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Middleware(&config.Middleware{Name: "mirror", Options: v})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { m.Close() })
+	return m.Process(middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		b, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: io.NopCloser(bytes.NewReader(b))}, nil
+	}))
+}
+
+func TestMirror(t *testing.T) {
+	mirrored := make(chan string, 1)
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		mirrored <- r.Method + " " + r.URL.RequestURI() + " " + r.Header.Get("X-Test") + " " + string(b)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer shadow.Close()
+
+	rt := newMiddleware(t, &v1.Mirror{Target: shadow.URL})
+	req := httptest.NewRequest("POST", "/api/users?id=1", strings.NewReader("hello"))
+	req.Header.Set("X-Test", "1")
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 主请求仍然读取到完整的请求体，影子后端的错误不影响主请求
+	if b, _ := io.ReadAll(resp.Body); resp.StatusCode != http.StatusOK || string(b) != "hello" {
+		t.Fatalf("want the primary response unaffected but got %d %q", resp.StatusCode, b)
+	}
+	select {
+	case got := <-mirrored:
+		if want := "POST /api/users?id=1 1 hello"; got != want {
+			t.Fatalf("want mirrored %q but got %q", want, got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("want the request mirrored")
+	}
+}
+
+func TestMirrorMaxOutstanding(t *testing.T) {
+	release := make(chan struct{})
+	received := make(chan struct{}, 10)
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received <- struct{}{}
+		<-release
+	}))
+	defer shadow.Close()
+	defer close(release)
+
+	rt := newMiddleware(t, &v1.Mirror{Target: shadow.URL, MaxOutstanding: 1})
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		resp, err := rt.RoundTrip(httptest.NewRequest("GET", "/api/users", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	// 影子后端阻塞时主请求不等待镜像请求
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("want the primary requests not blocked but took %s", elapsed)
+	}
+	<-received
+	select {
+	case <-received:
+		t.Fatal("want only one outstanding mirrored request")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestMirrorSample(t *testing.T) {
+	m := &mirror{percentage: 30, random: func() float64 { return 0.25 }}
+	if !m.sampled() {
+		t.Fatal("want sampled below the percentage")
+	}
+	m.random = func() float64 { return 0.35 }
+	if m.sampled() {
+		t.Fatal("want not sampled above the percentage")
+	}
+	for _, target := range []string{"", "127.0.0.1:9000", "ftp://127.0.0.1"} {
+		v, _ := anypb.New(&v1.Mirror{Target: target})
+		if _, err := Middleware(&config.Middleware{Options: v}); err == nil {
+			t.Fatalf("want error for target %q", target)
+		}
+	}
+}
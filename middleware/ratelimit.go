@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-kratos/feature"
+)
+
+// 限流响应头的名称，参考 IETF RateLimit header fields 草案
+const (
+	// RateLimitLimitHeader 是限流窗口内允许的请求数
+	RateLimitLimitHeader = "RateLimit-Limit"
+	// RateLimitRemainingHeader 是限流窗口内剩余的请求数
+	RateLimitRemainingHeader = "RateLimit-Remaining"
+	// RateLimitResetHeader 是距离配额恢复的秒数
+	RateLimitResetHeader = "RateLimit-Reset"
+)
+
+// rateLimitHeadersFeature 控制限流和配额中间件是否返回标准的限流响应头
+var rateLimitHeadersFeature = feature.MustRegister("gw:RateLimitHeaders", true)
+
+// RateLimitStatus 结构体描述了一次限流判断后的配额状态
+type RateLimitStatus struct {
+	// Limit 是限流窗口内允许的请求数
+	Limit int64
+	// Remaining 是限流窗口内剩余的请求数
+	Remaining int64
+	// Reset 是距离配额恢复的时长
+	Reset time.Duration
+}
+
+// SetRateLimitHeaders 将配额状态写入标准的限流响应头，所有限流和配额中间件都应通过它设置响应头，
+// 使客户端无论被哪个限流器限制都能得到一致的响应头。请求经过多个限流器时保留剩余请求数最少的配额状态
+func SetRateLimitHeaders(header http.Header, status RateLimitStatus) {
+	if !rateLimitHeadersFeature.Enabled() {
+		return
+	}
+	if prev := header.Get(RateLimitRemainingHeader); prev != "" {
+		if remaining, err := strconv.ParseInt(prev, 10, 64); err == nil && remaining <= status.Remaining {
+			return
+		}
+	}
+	if status.Remaining < 0 {
+		status.Remaining = 0
+	}
+	// 重置时间向上取整到秒，避免客户端在配额恢复之前重试
+	reset := int64(math.Ceil(status.Reset.Seconds()))
+	if reset < 0 {
+		reset = 0
+	}
+	header.Set(RateLimitLimitHeader, strconv.FormatInt(status.Limit, 10))
+	header.Set(RateLimitRemainingHeader, strconv.FormatInt(status.Remaining, 10))
+	header.Set(RateLimitResetHeader, strconv.FormatInt(reset, 10))
+}
@@ -0,0 +1,102 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// bucket 结构体是一个令牌桶，tokens 是上次使用时剩余的令牌数
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// limiter 结构体按键维护令牌桶，长时间未使用的令牌桶由后台任务定期清理
+type limiter struct {
+	lock    sync.Mutex
+	rate    float64
+	burst   float64
+	idle    time.Duration
+	buckets map[string]*bucket
+	now     func() time.Time
+	stop    chan struct{}
+	once    sync.Once
+}
+
+// newLimiter 函数创建一个限流器，并启动清理空闲令牌桶的后台任务
+func newLimiter(rate float64, burst int64, idle time.Duration) *limiter {
+	// 空闲的时间不足以填满令牌桶时，清理令牌桶会使客户端得到更多的令牌
+	if full := time.Duration(float64(burst) / rate * float64(time.Second)); idle < full {
+		idle = full
+	}
+	l := &limiter{
+		rate:    rate,
+		burst:   float64(burst),
+		idle:    idle,
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+		stop:    make(chan struct{}),
+	}
+	go l.proccleanup()
+	return l
+}
+
+// allow 方法从键对应的令牌桶中取出一个令牌，返回是否允许请求、剩余的令牌数，
+// 以及允许时令牌桶填满的时长或拒绝时下一个令牌可用的时长
+func (l *limiter) allow(key string) (bool, int64, time.Duration) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	now := l.now()
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(l.burst, b.tokens+elapsed.Seconds()*l.rate)
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false, 0, l.duration(1 - b.tokens)
+	}
+	b.tokens--
+	return true, int64(b.tokens), l.duration(l.burst - b.tokens)
+}
+
+// duration 方法返回产生指定数量的令牌需要的时长
+func (l *limiter) duration(tokens float64) time.Duration {
+	return time.Duration(tokens / l.rate * float64(time.Second))
+}
+
+// proccleanup 方法启动一个后台任务，定期清理空闲的令牌桶，限流器关闭时退出
+func (l *limiter) proccleanup() {
+	ticker := time.NewTicker(l.idle)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			l.cleanup()
+		case <-l.stop:
+			return
+		}
+	}
+}
+
+// cleanup 方法清理空闲超过 idle 的令牌桶，这些令牌桶已经填满，清理后再次使用时与新建的令牌桶相同
+func (l *limiter) cleanup() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	now := l.now()
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= l.idle {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// Close 方法停止清理空闲令牌桶的后台任务
+func (l *limiter) Close() error {
+	l.once.Do(func() { close(l.stop) })
+	return nil
+}
@@ -0,0 +1,130 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/ratelimit/v1"
+	"github.com/cnsync/gateway/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// _defaultIdleTimeout 是默认清理空闲令牌桶的时长
+const _defaultIdleTimeout = 5 * time.Minute
+
+var _metricRejectedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "requests_rate_limited_total",
+	Help:      "The total number of requests rejected by the local rate limit",
+}, []string{"protocol", "method", "path", "service", "basePath"})
+
+// 包初始化时注册 ratelimit 中间件
+func init() {
+	middleware.RegisterV2("ratelimit", Middleware)
+	prometheus.MustRegister(_metricRejectedTotal)
+}
+
+func rejectedRequestIncr(req *http.Request) {
+	labels, ok := middleware.MetricsLabelsFromContext(req.Context())
+	if ok {
+		_metricRejectedTotal.WithLabelValues(labels.Protocol(), labels.Method(), labels.Path(), labels.Service(), labels.BasePath()).Inc()
+	}
+}
+
+// clientIP 函数返回 X-Forwarded-For 中受信任的代理之前的地址，地址不足时返回第一个地址
+func clientIP(req *http.Request, trustedProxies int) string {
+	addrs := strings.Split(strings.Join(req.Header.Values("X-Forwarded-For"), ","), ",")
+	i := len(addrs) - 1 - trustedProxies
+	if i < 0 {
+		i = 0
+	}
+	return strings.TrimSpace(addrs[i])
+}
+
+// keyFunc 函数根据配置返回请求对应的令牌桶的键
+func keyFunc(options *v1.RateLimit) (func(*http.Request) string, error) {
+	switch options.Key {
+	case v1.RateLimit_CLIENT_IP:
+		trustedProxies := int(options.TrustedProxies)
+		return func(req *http.Request) string {
+			return clientIP(req, trustedProxies)
+		}, nil
+	case v1.RateLimit_HEADER:
+		if options.Header == "" {
+			return nil, fmt.Errorf("ratelimit: header is required by the HEADER key")
+		}
+		return func(req *http.Request) string {
+			return req.Header.Get(options.Header)
+		}, nil
+	case v1.RateLimit_ENDPOINT:
+		return func(req *http.Request) string {
+			if reqOpts, ok := middleware.FromRequestContext(req.Context()); ok {
+				return reqOpts.Endpoint.Path
+			}
+			return ""
+		}, nil
+	}
+	return nil, fmt.Errorf("ratelimit: unknown key: %s", options.Key)
+}
+
+// Middleware 函数根据传入的配置对象 c 创建一个本地限流中间件实例，
+// 按请求的键使用令牌桶限流，令牌桶为空时返回 429 并在 Retry-After 中告知客户端下一个令牌可用的时间
+func Middleware(c *config.Middleware) (middleware.MiddlewareV2, error) {
+	options := &v1.RateLimit{}
+	if c.Options != nil {
+		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+	}
+	if options.RequestsPerSecond <= 0 {
+		return nil, fmt.Errorf("ratelimit: requests_per_second must be positive")
+	}
+	burst := int64(options.Burst)
+	if burst == 0 {
+		burst = int64(math.Ceil(options.RequestsPerSecond))
+	}
+	idle := _defaultIdleTimeout
+	if options.IdleTimeout != nil && options.IdleTimeout.AsDuration() > 0 {
+		idle = options.IdleTimeout.AsDuration()
+	}
+	key, err := keyFunc(options)
+	if err != nil {
+		return nil, err
+	}
+	l := newLimiter(options.RequestsPerSecond, burst, idle)
+	onReject := middleware.NewRejectHandler(options.RejectResponse, http.StatusTooManyRequests)
+	return middleware.NewWithCloser(func(next http.RoundTripper) http.RoundTripper {
+		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ok, remaining, reset := l.allow(key(req))
+			status := middleware.RateLimitStatus{Limit: burst, Remaining: remaining, Reset: reset}
+			if !ok {
+				rejectedRequestIncr(req)
+				resp, err := onReject.RoundTrip(req)
+				if err != nil {
+					return nil, err
+				}
+				// 向上取整到秒，避免客户端在令牌可用之前重试
+				resp.Header.Set("Retry-After", strconv.FormatInt(int64(math.Ceil(reset.Seconds())), 10))
+				middleware.SetRateLimitHeaders(resp.Header, status)
+				return resp, nil
+			}
+			resp, err := next.RoundTrip(req)
+			if err != nil {
+				return nil, err
+			}
+			if resp.Header == nil {
+				resp.Header = make(http.Header)
+			}
+			middleware.SetRateLimitHeaders(resp.Header, status)
+			return resp, nil
+		})
+	}, l), nil
+}
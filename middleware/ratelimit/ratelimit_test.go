@@ -0,0 +1,124 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/ratelimit/v1"
+	"github.com/cnsync/gateway/middleware"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func newMiddleware(t *testing.T, options *v1.RateLimit) (http.RoundTripper, *int) {
+	v, err := anypb.New(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Middleware(&config.Middleware{Name: "ratelimit", Options: v})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { m.Close() })
+	forwarded := new(int)
+	return m.Process(middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		*forwarded++
+		return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody}, nil
+	})), forwarded
+}
+
+func send(t *testing.T, rt http.RoundTripper, header http.Header) *http.Response {
+	req := httptest.NewRequest("GET", "/api/users", nil)
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestRateLimit(t *testing.T) {
+	rt, forwarded := newMiddleware(t, &v1.RateLimit{RequestsPerSecond: 0.5, Burst: 2})
+	// 令牌桶按客户端 IP 区分，只信任网关追加的地址
+	alice := http.Header{"X-Forwarded-For": {"10.0.0.9, 192.0.2.1"}}
+	bob := http.Header{"X-Forwarded-For": {"10.0.0.9, 192.0.2.2"}}
+	for i := 0; i < 2; i++ {
+		if resp := send(t, rt, alice); resp.StatusCode != http.StatusOK {
+			t.Fatalf("want request %d allowed but got %d", i, resp.StatusCode)
+		}
+	}
+	resp := send(t, rt, alice)
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("want the request rejected but got %d", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Retry-After"); got != "2" {
+		t.Fatalf("want Retry-After 2 but got %q", got)
+	}
+	if got := resp.Header.Get(middleware.RateLimitRemainingHeader); got != "0" {
+		t.Fatalf("want no remaining requests but got %q", got)
+	}
+	if *forwarded != 2 {
+		t.Fatalf("want the rejected request not forwarded but got %d forwarded", *forwarded)
+	}
+	if resp := send(t, rt, bob); resp.StatusCode != http.StatusOK {
+		t.Fatalf("want another client allowed but got %d", resp.StatusCode)
+	}
+
+	// 按请求头区分时，缺少请求头的请求共享一个令牌桶
+	rt, _ = newMiddleware(t, &v1.RateLimit{RequestsPerSecond: 1, Key: v1.RateLimit_HEADER, Header: "X-API-Key"})
+	if resp := send(t, rt, http.Header{"X-Api-Key": {"a"}}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("want key a allowed but got %d", resp.StatusCode)
+	}
+	if resp := send(t, rt, http.Header{"X-Api-Key": {"b"}}); resp.StatusCode != http.StatusOK {
+		t.Fatalf("want key b allowed but got %d", resp.StatusCode)
+	}
+	if resp := send(t, rt, http.Header{"X-Api-Key": {"a"}}); resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("want key a rejected but got %d", resp.StatusCode)
+	}
+
+	// 按请求头区分时必须配置请求头
+	v, _ := anypb.New(&v1.RateLimit{RequestsPerSecond: 1, Key: v1.RateLimit_HEADER})
+	if _, err := Middleware(&config.Middleware{Name: "ratelimit", Options: v}); err == nil {
+		t.Fatal("want an error without the header")
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	l := newLimiter(2, 4, time.Second)
+	defer l.Close()
+	now := time.Unix(0, 0)
+	l.now = func() time.Time { return now }
+	for i := 0; i < 4; i++ {
+		if ok, remaining, _ := l.allow("k"); !ok || remaining != int64(3-i) {
+			t.Fatalf("want request %d allowed with %d remaining but got %v %d", i, 3-i, ok, remaining)
+		}
+	}
+	if ok, _, wait := l.allow("k"); ok || wait != 500*time.Millisecond {
+		t.Fatalf("want the request rejected for 500ms but got %v %v", ok, wait)
+	}
+	// 令牌按速率补充
+	now = now.Add(time.Second)
+	for i := 0; i < 2; i++ {
+		if ok, _, _ := l.allow("k"); !ok {
+			t.Fatalf("want refilled request %d allowed", i)
+		}
+	}
+	if ok, _, _ := l.allow("k"); ok {
+		t.Fatal("want the request rejected after the refilled tokens are used")
+	}
+	// 空闲的令牌桶在填满之后才被清理
+	now = now.Add(time.Second)
+	l.cleanup()
+	if len(l.buckets) != 1 {
+		t.Fatal("want the bucket kept until it's full")
+	}
+	now = now.Add(time.Second)
+	l.cleanup()
+	if len(l.buckets) != 0 {
+		t.Fatal("want the idle bucket removed")
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/go-kratos/feature"
+)
+
+func TestSetRateLimitHeaders(t *testing.T) {
+	tests := []struct {
+		name   string
+		status []RateLimitStatus
+		want   [3]string
+	}{
+		{
+			name:   "single",
+			status: []RateLimitStatus{{Limit: 100, Remaining: 42, Reset: 1500 * time.Millisecond}},
+			want:   [3]string{"100", "42", "2"},
+		},
+		{
+			name:   "exhausted",
+			status: []RateLimitStatus{{Limit: 10, Remaining: -1, Reset: -time.Second}},
+			want:   [3]string{"10", "0", "0"},
+		},
+		{
+			// 经过多个限流器时保留剩余请求数最少的配额状态
+			name: "most restrictive",
+			status: []RateLimitStatus{
+				{Limit: 100, Remaining: 5, Reset: 30 * time.Second},
+				{Limit: 10, Remaining: 8, Reset: time.Second},
+				{Limit: 1000, Remaining: 3, Reset: time.Minute},
+			},
+			want: [3]string{"1000", "3", "60"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for _, s := range tt.status {
+				SetRateLimitHeaders(header, s)
+			}
+			got := [3]string{header.Get("RateLimit-Limit"), header.Get("RateLimit-Remaining"), header.Get("RateLimit-Reset")}
+			if got != tt.want {
+				t.Fatalf("want %v but got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSetRateLimitHeadersDisabled(t *testing.T) {
+	feature.SetEnabled("gw:RateLimitHeaders", false)
+	defer feature.SetEnabled("gw:RateLimitHeaders", true)
+	header := http.Header{}
+	SetRateLimitHeaders(header, RateLimitStatus{Limit: 1, Remaining: 0, Reset: time.Second})
+	if len(header) != 0 {
+		t.Fatalf("want no headers but got %v", header)
+	}
+}
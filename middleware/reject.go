@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	configv1 "github.com/cnsync/gateway/api/gateway/config/v1"
+)
+
+// NewRejectHandler 根据配置创建保护机制拒绝请求时返回响应的 RoundTripper，未配置状态码时使用 defaultStatus。
+func NewRejectHandler(c *configv1.RejectResponse, defaultStatus int) http.RoundTripper {
+	status := int(c.GetStatusCode())
+	if status == 0 {
+		status = defaultStatus
+	}
+	header := make(http.Header, len(c.GetHeaders()))
+	for k, v := range c.GetHeaders() {
+		header.Set(k, v)
+	}
+	body := c.GetBody()
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			Status:        http.StatusText(status),
+			StatusCode:    status,
+			Header:        header.Clone(),
+			Body:          io.NopCloser(bytes.NewReader(body)),
+			ContentLength: int64(len(body)),
+		}, nil
+	})
+}
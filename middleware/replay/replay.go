@@ -0,0 +1,149 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/replay/v1"
+	"github.com/cnsync/gateway/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const (
+	// _defaultHeader 是默认携带 nonce 的请求头
+	_defaultHeader = "X-Nonce"
+	// _defaultWindow 是默认记住已使用 nonce 的时长
+	_defaultWindow = 5 * time.Minute
+	// _defaultStore 是默认的 nonce 存储
+	_defaultStore = "memory"
+	// _defaultKeyPrefix 是默认的 nonce 键前缀
+	_defaultKeyPrefix = "gateway:nonce:"
+)
+
+const (
+	// _reasonMissing 表示请求没有携带 nonce
+	_reasonMissing = "missing"
+	// _reasonReplayed 表示请求的 nonce 在时间窗口内已被使用
+	_reasonReplayed = "replayed"
+)
+
+var _metricRejectedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "requests_replay_rejected_total",
+	Help:      "The total number of requests rejected by the replay protection",
+}, []string{"protocol", "method", "path", "service", "basePath", "reason"})
+
+// NonceStore 接口定义了记录已使用 nonce 的存储
+type NonceStore interface {
+	// Use 方法将 key 记录为已使用并保留 window 时长，key 在 window 内已被使用过时返回 false
+	Use(ctx context.Context, key string, window time.Duration) (bool, error)
+	// Close 方法释放存储使用的资源
+	Close() error
+}
+
+// StoreFactory 是一个工厂函数，用于根据配置创建 nonce 存储
+type StoreFactory func(*v1.Replay) (NonceStore, error)
+
+var (
+	storesLock sync.RWMutex
+	stores     = map[string]StoreFactory{
+		_defaultStore: newMemoryStore,
+		"redis":       newRedisStore,
+	}
+)
+
+// RegisterStore 注册一个 nonce 存储工厂
+func RegisterStore(name string, factory StoreFactory) {
+	storesLock.Lock()
+	defer storesLock.Unlock()
+	stores[name] = factory
+}
+
+// 包初始化时注册 replay 中间件
+func init() {
+	middleware.RegisterV2("replay", Middleware)
+	prometheus.MustRegister(_metricRejectedTotal)
+}
+
+func rejectedRequestIncr(req *http.Request, reason string) {
+	labels, ok := middleware.MetricsLabelsFromContext(req.Context())
+	if ok {
+		_metricRejectedTotal.WithLabelValues(labels.Protocol(), labels.Method(), labels.Path(), labels.Service(), labels.BasePath(), reason).Inc()
+	}
+}
+
+// acceptedKey 是请求值中记录已通过检查的 nonce 的键，使代理重试时不会把自己的重试当作重放
+type acceptedKey struct{}
+
+// Middleware 函数根据传入的配置对象 c 创建一个防重放中间件实例，
+// 请求必须在请求头中携带 nonce，同一个 nonce 在时间窗口内只能使用一次
+func Middleware(c *config.Middleware) (middleware.MiddlewareV2, error) {
+	options := &v1.Replay{}
+	if c.Options != nil {
+		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+	}
+	header := options.Header
+	if header == "" {
+		header = _defaultHeader
+	}
+	window := _defaultWindow
+	if options.Window != nil && options.Window.AsDuration() > 0 {
+		window = options.Window.AsDuration()
+	}
+	prefix := options.KeyPrefix
+	if prefix == "" {
+		prefix = _defaultKeyPrefix
+	}
+	name := options.Store
+	if name == "" {
+		name = _defaultStore
+	}
+	storesLock.RLock()
+	factory, ok := stores[name]
+	storesLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("nonce store %s has not been registered", name)
+	}
+	store, err := factory(options)
+	if err != nil {
+		return nil, err
+	}
+	onReplay := middleware.NewRejectHandler(options.RejectResponse, http.StatusConflict)
+	onMissing := middleware.NewRejectHandler(options.MissingResponse, http.StatusBadRequest)
+	return middleware.NewWithCloser(func(next http.RoundTripper) http.RoundTripper {
+		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			nonce := req.Header.Get(header)
+			if nonce == "" {
+				rejectedRequestIncr(req, _reasonMissing)
+				return onMissing.RoundTrip(req)
+			}
+			reqOpts, hasOpts := middleware.FromRequestContext(req.Context())
+			if hasOpts {
+				if accepted, ok := reqOpts.Values.Get(acceptedKey{}); ok && accepted == nonce {
+					return next.RoundTrip(req)
+				}
+			}
+			ok, err := store.Use(req.Context(), prefix+nonce, window)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				rejectedRequestIncr(req, _reasonReplayed)
+				return onReplay.RoundTrip(req)
+			}
+			if hasOpts {
+				reqOpts.Values.Set(acceptedKey{}, nonce)
+			}
+			return next.RoundTrip(req)
+		})
+	}, store), nil
+}
@@ -0,0 +1,119 @@
+package replay
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/replay/v1"
+	"github.com/cnsync/gateway/middleware"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func newMiddleware(t *testing.T, options *v1.Replay) http.RoundTripper {
+	v, err := anypb.New(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Middleware(&config.Middleware{Name: "replay", Options: v})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { m.Close() })
+	return m.Process(middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}))
+}
+
+func send(t *testing.T, rt http.RoundTripper, nonce string) int {
+	req := httptest.NewRequest("POST", "/api/transfer", nil)
+	if nonce != "" {
+		req.Header.Set("X-Nonce", nonce)
+	}
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Unix(0, 0)
+	globalMemoryStore.now = func() time.Time { return now }
+	defer func() { globalMemoryStore.now = time.Now }()
+	rt := newMiddleware(t, &v1.Replay{
+		Window:    durationpb.New(time.Minute),
+		KeyPrefix: "test:memory:",
+		RejectResponse: &config.RejectResponse{
+			StatusCode: http.StatusUnauthorized,
+		},
+	})
+
+	steps := []struct {
+		after time.Duration
+		nonce string
+		want  int
+	}{
+		{0, "", http.StatusBadRequest},
+		{0, "a", http.StatusOK},
+		{0, "b", http.StatusOK},
+		// 时间窗口内重复使用 nonce 被拒绝
+		{30 * time.Second, "a", http.StatusUnauthorized},
+		// 超过时间窗口后 nonce 可以再次使用
+		{31 * time.Second, "a", http.StatusOK},
+		{0, "a", http.StatusUnauthorized},
+	}
+	for i, step := range steps {
+		now = now.Add(step.after)
+		if got := send(t, rt, step.nonce); got != step.want {
+			t.Fatalf("step %d: nonce %q want status %d but got %d", i, step.nonce, step.want, got)
+		}
+	}
+}
+
+func TestRetry(t *testing.T) {
+	rt := newMiddleware(t, &v1.Replay{KeyPrefix: "test:retry:"})
+	// 代理重试同一个请求时不被当作重放
+	ctx := middleware.NewRequestContext(context.Background(), middleware.NewRequestOptions(&config.Endpoint{}))
+	for i := 0; i < 3; i++ {
+		req := httptest.NewRequest("POST", "/api/transfer", nil).WithContext(ctx)
+		req.Header.Set("X-Nonce", "retried")
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("attempt %d: want status 200 but got %d", i, resp.StatusCode)
+		}
+	}
+	if got := send(t, rt, "retried"); got != http.StatusConflict {
+		t.Fatalf("want status 409 but got %d", got)
+	}
+}
+
+func TestRedisStore(t *testing.T) {
+	mr := miniredis.RunT(t)
+	options := &v1.Replay{
+		Store:  "redis",
+		Redis:  "redis://" + mr.Addr(),
+		Window: durationpb.New(time.Minute),
+	}
+	// 两个网关实例共享已使用的 nonce
+	a, b := newMiddleware(t, options), newMiddleware(t, options)
+	if got := send(t, a, "shared"); got != http.StatusOK {
+		t.Fatalf("want status 200 but got %d", got)
+	}
+	if got := send(t, b, "shared"); got != http.StatusConflict {
+		t.Fatalf("want status 409 but got %d", got)
+	}
+	mr.FastForward(time.Minute)
+	if got := send(t, b, "shared"); got != http.StatusOK {
+		t.Fatalf("want status 200 after the window but got %d", got)
+	}
+}
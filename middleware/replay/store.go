@@ -0,0 +1,79 @@
+package replay
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/replay/v1"
+	"github.com/redis/go-redis/v9"
+)
+
+// _sweepInterval 是内存存储清理过期 nonce 的最小间隔
+const _sweepInterval = time.Second
+
+// globalMemoryStore 是所有端点共享的内存存储，使已使用的 nonce 在配置重新加载后仍然有效
+var globalMemoryStore = &memoryStore{
+	expires: make(map[string]time.Time),
+	now:     time.Now,
+}
+
+// memoryStore 结构体在内存中记录已使用的 nonce，只在单个网关实例内有效
+type memoryStore struct {
+	lock      sync.Mutex
+	expires   map[string]time.Time
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func newMemoryStore(*v1.Replay) (NonceStore, error) {
+	return globalMemoryStore, nil
+}
+
+// Use 方法记录 key 为已使用，并按间隔清理过期的 nonce
+func (s *memoryStore) Use(_ context.Context, key string, window time.Duration) (bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := s.now()
+	if now.Sub(s.lastSweep) >= _sweepInterval {
+		for k, expire := range s.expires {
+			if !now.Before(expire) {
+				delete(s.expires, k)
+			}
+		}
+		s.lastSweep = now
+	}
+	if expire, ok := s.expires[key]; ok && now.Before(expire) {
+		return false, nil
+	}
+	s.expires[key] = now.Add(window)
+	return true, nil
+}
+
+// Close 方法不关闭共享的内存存储
+func (s *memoryStore) Close() error {
+	return nil
+}
+
+// redisStore 结构体在 redis 中记录已使用的 nonce，多个网关实例共享
+type redisStore struct {
+	client *redis.Client
+}
+
+func newRedisStore(options *v1.Replay) (NonceStore, error) {
+	opts, err := redis.ParseURL(options.Redis)
+	if err != nil {
+		return nil, err
+	}
+	return &redisStore{client: redis.NewClient(opts)}, nil
+}
+
+// Use 方法使用 SET NX 原子地记录 key 为已使用
+func (s *redisStore) Use(ctx context.Context, key string, window time.Duration) (bool, error) {
+	return s.client.SetNX(ctx, key, 1, window).Result()
+}
+
+// Close 方法关闭 redis 客户端
+func (s *redisStore) Close() error {
+	return s.client.Close()
+}
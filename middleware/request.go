@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"context"
+	"net/http"
 
 	config "github.com/cnsync/gateway/api/gateway/config/v1"
 	"github.com/cnsync/kratos/selector"
@@ -234,6 +235,54 @@ func WithSelectorFitler(ctx context.Context, fn selector.NodeFilter) context.Con
 	return ctx
 }
 
+// NodeTransform 是选择节点之后、发送请求之前对请求的变换，可以根据所选节点的元数据改写请求。
+type NodeTransform func(req *http.Request, node selector.Node) error
+
+type nodeTransformsKey struct{}
+
+// WithNodeTransform 将节点变换添加到 Context 中，变换只对携带该 Context 的这一次尝试生效。
+func WithNodeTransform(ctx context.Context, fn NodeTransform) context.Context {
+	prev, _ := ctx.Value(nodeTransformsKey{}).([]NodeTransform)
+	// 复制已有的变换列表，避免与其他 Context 共享底层数组
+	transforms := make([]NodeTransform, 0, len(prev)+1)
+	transforms = append(transforms, prev...)
+	return context.WithValue(ctx, nodeTransformsKey{}, append(transforms, fn))
+}
+
+// NodeTransformsFromContext 从 Context 中提取节点变换列表。
+func NodeTransformsFromContext(ctx context.Context) []NodeTransform {
+	transforms, _ := ctx.Value(nodeTransformsKey{}).([]NodeTransform)
+	return transforms
+}
+
+type requestAttributesKey struct{}
+
+// SetRequestAttribute 将命名属性保存到请求值映射中，供其他中间件和日志使用。
+func SetRequestAttribute(ctx context.Context, name, value string) {
+	o, ok := FromRequestContext(ctx)
+	if !ok {
+		return
+	}
+	attrs, _ := o.Values.Get(requestAttributesKey{})
+	m, ok := attrs.(map[string]string)
+	if !ok {
+		m = make(map[string]string)
+		o.Values.Set(requestAttributesKey{}, m)
+	}
+	m[name] = value
+}
+
+// RequestAttributes 从 Context 中提取请求的命名属性。
+func RequestAttributes(ctx context.Context) map[string]string {
+	o, ok := FromRequestContext(ctx)
+	if !ok {
+		return nil
+	}
+	attrs, _ := o.Values.Get(requestAttributesKey{})
+	m, _ := attrs.(map[string]string)
+	return m
+}
+
 // MetricsLabelsFromContext 从 Context 中提取度量标签。
 func MetricsLabelsFromContext(ctx context.Context) (MetricsLabels, bool) {
 	// 尝试从 Context 中获取 RequestOptions
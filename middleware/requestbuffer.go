@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"os"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+)
+
+// RequestBuffer 结构体缓存了请求体，使请求体能够在重试时重放，
+// 超过内存大小限制的请求体缓存在临时文件中
+type RequestBuffer struct {
+	// data 是缓存在内存中的请求体
+	data []byte
+	// file 是缓存请求体的临时文件，为 nil 时请求体缓存在内存中
+	file *os.File
+	// size 是请求体的大小
+	size int64
+}
+
+// BufferRequestBody 函数读取并缓存请求体，超过 max_memory_bytes 的请求体写入临时文件
+func BufferRequestBody(body io.Reader, cfg *config.RequestBuffering) (*RequestBuffer, error) {
+	maxMemory := cfg.GetMaxMemoryBytes()
+	if maxMemory <= 0 {
+		data, err := io.ReadAll(body)
+		return &RequestBuffer{data: data, size: int64(len(data))}, err
+	}
+	data, err := io.ReadAll(io.LimitReader(body, maxMemory+1))
+	if err != nil {
+		return &RequestBuffer{data: data, size: int64(len(data))}, err
+	}
+	if int64(len(data)) <= maxMemory {
+		return &RequestBuffer{data: data, size: int64(len(data))}, nil
+	}
+	file, err := os.CreateTemp(cfg.GetTempDir(), "gateway-request-*")
+	if err != nil {
+		return &RequestBuffer{}, err
+	}
+	b := &RequestBuffer{file: file}
+	b.size, err = io.Copy(file, io.MultiReader(bytes.NewReader(data), body))
+	return b, err
+}
+
+// Len 方法返回请求体的大小
+func (b *RequestBuffer) Len() int64 {
+	return b.size
+}
+
+// NewReader 方法返回一个从头读取请求体的读取器，多个读取器之间互不影响
+func (b *RequestBuffer) NewReader() io.ReadCloser {
+	if b.file != nil {
+		return io.NopCloser(io.NewSectionReader(b.file, 0, b.size))
+	}
+	return io.NopCloser(bytes.NewReader(b.data))
+}
+
+// Close 方法关闭并删除临时文件
+func (b *RequestBuffer) Close() error {
+	if b.file == nil {
+		return nil
+	}
+	b.file.Close()
+	return os.Remove(b.file.Name())
+}
@@ -0,0 +1,72 @@
+package requestid
+
+import (
+	"net/http"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/requestid/v1"
+	"github.com/cnsync/gateway/middleware"
+	"github.com/google/uuid"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// defaultHeader 是默认携带请求 ID 的请求头
+const defaultHeader = "X-Request-Id"
+
+// metadataKey 是请求 ID 在请求选项元数据中的键名
+const metadataKey = "request_id"
+
+// 包初始化时注册 requestid 中间件
+func init() {
+	middleware.Register("requestid", Middleware)
+}
+
+// Middleware 函数根据传入的配置对象 c 创建一个请求 ID 中间件实例
+func Middleware(c *config.Middleware) (middleware.Middleware, error) {
+	options := &v1.RequestID{}
+	if c.Options != nil {
+		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+	}
+	header := options.Header
+	if header == "" {
+		header = defaultHeader
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			// 优先沿用客户端传入的请求 ID，否则由网关生成
+			id := req.Header.Get(header)
+			if id == "" {
+				id = uuid.New().String()
+			}
+			// 记录到请求选项中，便于其他中间件使用
+			if reqOpts, ok := middleware.FromRequestContext(req.Context()); ok {
+				reqOpts.Metadata[metadataKey] = id
+			}
+			// 转发给上游，HTTP/2 发送时请求头的键名转为小写，gRPC 后端以 metadata 的形式读取
+			req.Header.Set(header, id)
+			reply, err := next.RoundTrip(req)
+			if err != nil {
+				return nil, err
+			}
+			// 将请求 ID 回写到响应头中
+			if reply.Header == nil {
+				reply.Header = make(http.Header)
+			}
+			reply.Header.Set(header, id)
+			return reply, nil
+		})
+	}, nil
+}
+
+// FromRequest 函数返回网关为请求分配的请求 ID
+func FromRequest(req *http.Request) (string, bool) {
+	reqOpts, ok := middleware.FromRequestContext(req.Context())
+	if !ok {
+		return "", false
+	}
+	id, ok := reqOpts.Metadata[metadataKey]
+	return id, ok
+}
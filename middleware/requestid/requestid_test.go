@@ -0,0 +1,173 @@
+package requestid
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/requestid/v1"
+	"github.com/cnsync/gateway/middleware"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/hpack"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+	}{
+		{name: "incoming", incoming: "abc"},
+		{name: "generated"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Middleware(&config.Middleware{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var upstream http.Header
+			next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				upstream = req.Header.Clone()
+				return &http.Response{StatusCode: http.StatusOK}, nil
+			})
+			req := httptest.NewRequest("GET", "/users", nil)
+			if tt.incoming != "" {
+				req.Header.Set("X-Request-Id", tt.incoming)
+			}
+			reqOpts := middleware.NewRequestOptions(&config.Endpoint{Protocol: config.Protocol_HTTP})
+			req = req.WithContext(middleware.NewRequestContext(req.Context(), reqOpts))
+			reply, err := m(next).RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			values, ok := upstream["X-Request-Id"]
+			if !ok || len(values) != 1 || values[0] == "" || len(upstream) != 1 {
+				t.Fatalf("want a single upstream header X-Request-Id but got: %v", upstream)
+			}
+			if tt.incoming != "" && values[0] != tt.incoming {
+				t.Fatalf("want request id %q but got %q", tt.incoming, values[0])
+			}
+			if got := reply.Header.Get("X-Request-Id"); got != values[0] {
+				t.Fatalf("want response request id %q but got %q", values[0], got)
+			}
+			if id, _ := FromRequest(req); id != values[0] {
+				t.Fatalf("want request options id %q but got %q", values[0], id)
+			}
+		})
+	}
+}
+
+// TestRequestIDHTTP2Metadata 测试通过 HTTP/2 转发给 gRPC 后端时，请求 ID 以小写键名的 metadata 发送
+func TestRequestIDHTTP2Metadata(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	// 上游直接读取 HTTP/2 帧，记录线路上收到的请求头键名
+	received := make(chan []hpack.HeaderField, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if _, err := io.ReadFull(conn, make([]byte, len(http2.ClientPreface))); err != nil {
+			return
+		}
+		framer := http2.NewFramer(conn, conn)
+		framer.ReadMetaHeaders = hpack.NewDecoder(4096, nil)
+		if err := framer.WriteSettings(); err != nil {
+			return
+		}
+		for {
+			f, err := framer.ReadFrame()
+			if err != nil {
+				return
+			}
+			switch f := f.(type) {
+			case *http2.SettingsFrame:
+				if !f.IsAck() {
+					_ = framer.WriteSettingsAck()
+				}
+			case *http2.MetaHeadersFrame:
+				received <- f.Fields
+				var buf bytes.Buffer
+				_ = hpack.NewEncoder(&buf).WriteField(hpack.HeaderField{Name: ":status", Value: "200"})
+				_ = framer.WriteHeaders(http2.HeadersFrameParam{
+					StreamID:      f.StreamID,
+					BlockFragment: buf.Bytes(),
+					EndStream:     true,
+					EndHeaders:    true,
+				})
+			}
+		}
+	}()
+	transport := &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}
+	defer transport.CloseIdleConnections()
+
+	m, err := Middleware(&config.Middleware{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("POST", "http://"+ln.Addr().String()+"/helloworld.Greeter/SayHello", nil)
+	req.RequestURI = ""
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("X-Request-Id", "abc")
+	reqOpts := middleware.NewRequestOptions(&config.Endpoint{Protocol: config.Protocol_GRPC})
+	req = req.WithContext(middleware.NewRequestContext(req.Context(), reqOpts))
+	reply, err := m(transport).RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reply.Body.Close()
+
+	var keys []string
+	for _, f := range <-received {
+		if strings.EqualFold(f.Name, "X-Request-Id") {
+			keys = append(keys, f.Name+"="+f.Value)
+		}
+	}
+	if len(keys) != 1 || keys[0] != "x-request-id=abc" {
+		t.Fatalf("want a single lowercase x-request-id=abc on the wire but got %v", keys)
+	}
+}
+
+func TestRequestIDCustomHeader(t *testing.T) {
+	options, err := anypb.New(&v1.RequestID{Header: "X-Trace-Id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Middleware(&config.Middleware{Options: options})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var upstream http.Header
+	next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		upstream = req.Header.Clone()
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+	req := httptest.NewRequest("POST", "/helloworld.Greeter/SayHello", nil)
+	reqOpts := middleware.NewRequestOptions(&config.Endpoint{Protocol: config.Protocol_GRPC})
+	req = req.WithContext(middleware.NewRequestContext(req.Context(), reqOpts))
+	if _, err := m(next).RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+	if v := upstream["X-Trace-Id"]; len(v) != 1 {
+		t.Fatalf("want upstream header X-Trace-Id but got: %v", upstream)
+	}
+}
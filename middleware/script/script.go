@@ -0,0 +1,203 @@
+package script
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/script/v1"
+	"github.com/cnsync/gateway/middleware"
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+const (
+	// defaultTimeout 是脚本默认的最长执行时间
+	defaultTimeout = 50 * time.Millisecond
+	// registryMaxSize 是脚本虚拟机寄存器的最大数量，用于限制脚本的内存占用
+	registryMaxSize = 1024 * 80
+	// callStackSize 是脚本调用栈的最大深度
+	callStackSize = 256
+)
+
+// _unsafeGlobals 是沙箱中移除的全局函数，它们可以访问文件系统或加载任意代码
+var _unsafeGlobals = []string{"dofile", "loadfile", "load", "loadstring", "require", "module", "print", "collectgarbage", "_printregs"}
+
+// errResponded 是脚本调用 respond 结束执行时使用的错误
+var errResponded = errors.New("script responded")
+
+// 包初始化时注册 script 中间件
+func init() {
+	middleware.Register("script", Middleware)
+}
+
+// compile 函数编译 Lua 脚本
+func compile(source string) (*lua.FunctionProto, error) {
+	chunk, err := parse.Parse(strings.NewReader(source), "script")
+	if err != nil {
+		return nil, err
+	}
+	return lua.Compile(chunk, "script")
+}
+
+// newState 函数创建一个沙箱化的 Lua 虚拟机，只开放 base、table、string 和 math 标准库，
+// 不开放 io、os、package 等可以访问文件系统、网络或进程的库
+func newState(ctx context.Context) *lua.LState {
+	L := lua.NewState(lua.Options{
+		SkipOpenLibs:    true,
+		CallStackSize:   callStackSize,
+		RegistryMaxSize: registryMaxSize,
+	})
+	for _, lib := range []struct {
+		name string
+		fn   lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.fn))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	for _, name := range _unsafeGlobals {
+		L.SetGlobal(name, lua.LNil)
+	}
+	L.SetContext(ctx)
+	return L
+}
+
+// scriptRequest 结构体将请求暴露给脚本，并记录脚本给出的提前响应
+type scriptRequest struct {
+	req  *http.Request
+	resp *http.Response
+}
+
+// table 方法返回暴露给脚本的 req 表
+func (s *scriptRequest) table(L *lua.LState) *lua.LTable {
+	t := L.NewTable()
+	t.RawSetString("method", lua.LString(s.req.Method))
+	t.RawSetString("path", lua.LString(s.req.URL.Path))
+	t.RawSetString("host", lua.LString(s.req.Host))
+	t.RawSetString("raw_query", lua.LString(s.req.URL.RawQuery))
+	// req.header(name) 返回请求头的值
+	t.RawSetString("header", L.NewFunction(func(L *lua.LState) int {
+		L.Push(lua.LString(s.req.Header.Get(L.CheckString(1))))
+		return 1
+	}))
+	// req.set_header(name, value) 设置请求头
+	t.RawSetString("set_header", L.NewFunction(func(L *lua.LState) int {
+		s.req.Header.Set(L.CheckString(1), L.CheckString(2))
+		return 0
+	}))
+	// req.del_header(name) 删除请求头
+	t.RawSetString("del_header", L.NewFunction(func(L *lua.LState) int {
+		s.req.Header.Del(L.CheckString(1))
+		return 0
+	}))
+	// req.query(name) 返回查询参数的值
+	t.RawSetString("query", L.NewFunction(func(L *lua.LState) int {
+		L.Push(lua.LString(s.req.URL.Query().Get(L.CheckString(1))))
+		return 1
+	}))
+	// req.set_path(path) 改写请求路径
+	t.RawSetString("set_path", L.NewFunction(func(L *lua.LState) int {
+		path := L.CheckString(1)
+		if !strings.HasPrefix(path, "/") {
+			L.ArgError(1, "path must start with /")
+		}
+		s.req.URL.Path = path
+		s.req.URL.RawPath = ""
+		t.RawSetString("path", lua.LString(path))
+		return 0
+	}))
+	return t
+}
+
+// respond 方法是暴露给脚本的 respond(status, body, headers) 函数，直接返回响应并结束脚本的执行
+func (s *scriptRequest) respond(L *lua.LState) int {
+	status := L.CheckInt(1)
+	if status < 100 || status > 999 {
+		L.ArgError(1, "invalid status code")
+	}
+	body := L.OptString(2, "")
+	header := http.Header{}
+	if headers := L.OptTable(3, nil); headers != nil {
+		headers.ForEach(func(k, v lua.LValue) {
+			header.Set(k.String(), v.String())
+		})
+	}
+	s.resp = &http.Response{
+		StatusCode:    status,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewBufferString(body)),
+		ContentLength: int64(len(body)),
+		Request:       s.req,
+	}
+	L.RaiseError("%s", errResponded)
+	return 0
+}
+
+// run 函数在沙箱中执行脚本，脚本调用 respond 时返回提前响应
+func run(ctx context.Context, fn *lua.FunctionProto, req *http.Request) (*http.Response, error) {
+	L := newState(ctx)
+	defer L.Close()
+	s := &scriptRequest{req: req}
+	L.SetGlobal("req", s.table(L))
+	L.SetGlobal("respond", L.NewFunction(s.respond))
+	L.Push(L.NewFunctionFromProto(fn))
+	err := L.PCall(0, 0, nil)
+	// 即使脚本用 pcall 捕获了 respond 结束执行的错误，也以提前响应为准
+	if s.resp != nil {
+		return s.resp, nil
+	}
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("script execution aborted: %w", ctxErr)
+		}
+		return nil, fmt.Errorf("script execution failed: %w", err)
+	}
+	return nil, nil
+}
+
+// Middleware 函数根据传入的配置对象 c 创建一个脚本中间件实例，
+// 每个请求在独立的沙箱中执行配置的 Lua 脚本，脚本可以修改请求头和路径，或者直接返回响应
+func Middleware(c *config.Middleware) (middleware.Middleware, error) {
+	options := &v1.Script{}
+	if c.Options != nil {
+		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+	}
+	fn, err := compile(options.Source)
+	if err != nil {
+		return nil, err
+	}
+	timeout := defaultTimeout
+	if options.Timeout != nil && options.Timeout.AsDuration() > 0 {
+		timeout = options.Timeout.AsDuration()
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			ctx, cancel := context.WithTimeout(req.Context(), timeout)
+			resp, err := run(ctx, fn, req)
+			cancel()
+			if err != nil {
+				return nil, err
+			}
+			if resp != nil {
+				return resp, nil
+			}
+			return next.RoundTrip(req)
+		})
+	}, nil
+}
@@ -0,0 +1,138 @@
+package script
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/script/v1"
+	"github.com/cnsync/gateway/middleware"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+func buildConfig(options *v1.Script) *config.Middleware {
+	v, err := anypb.New(options)
+	if err != nil {
+		panic(err)
+	}
+	return &config.Middleware{Options: v}
+}
+
+func TestScriptRewrite(t *testing.T) {
+	m, err := Middleware(buildConfig(&v1.Script{Source: `
+		req.set_header("X-Client", string.upper(req.header("User-Agent")))
+		req.del_header("X-Debug")
+		if req.query("version") == "2" then
+			req.set_path("/v2" .. req.path)
+		end
+	`}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var upstream *http.Request
+	next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		upstream = req
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+	req := httptest.NewRequest("GET", "/api/echo?version=2", nil)
+	req.Header.Set("User-Agent", "curl")
+	req.Header.Set("X-Debug", "1")
+	if _, err := m(next).RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+	if got := upstream.Header.Get("X-Client"); got != "CURL" {
+		t.Fatalf("want X-Client CURL but got %q", got)
+	}
+	if _, ok := upstream.Header["X-Debug"]; ok {
+		t.Fatalf("want X-Debug removed but got: %v", upstream.Header)
+	}
+	if upstream.URL.Path != "/v2/api/echo" {
+		t.Fatalf("want path /v2/api/echo but got %q", upstream.URL.Path)
+	}
+}
+
+func TestScriptRespond(t *testing.T) {
+	m, err := Middleware(buildConfig(&v1.Script{Source: `
+		if req.header("Authorization") == "" then
+			respond(401, "unauthorized", {["WWW-Authenticate"] = "Bearer"})
+		end
+		-- pcall 不能阻止 respond 结束请求
+		if req.method == "DELETE" then
+			pcall(respond, 405)
+		end
+	`}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls int
+	next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+
+	resp, err := m(next).RoundTrip(httptest.NewRequest("GET", "/api/echo", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusUnauthorized || string(body) != "unauthorized" || resp.Header.Get("WWW-Authenticate") != "Bearer" {
+		t.Fatalf("unexpected response: %d %q %v", resp.StatusCode, body, resp.Header)
+	}
+
+	req := httptest.NewRequest("DELETE", "/api/echo", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	resp, err = m(next).RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("want status 405 but got %d", resp.StatusCode)
+	}
+
+	req = httptest.NewRequest("GET", "/api/echo", nil)
+	req.Header.Set("Authorization", "Bearer token")
+	if resp, err = m(next).RoundTrip(req); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("want upstream response but got: %v %v", resp, err)
+	}
+	if calls != 1 {
+		t.Fatalf("want 1 upstream call but got %d", calls)
+	}
+}
+
+func TestScriptSandbox(t *testing.T) {
+	next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"timeout", `while true do end`, "aborted"},
+		{"os", `os.execute("true")`, "failed"},
+		{"io", `io.open("/etc/passwd")`, "failed"},
+		{"require", `require("socket")`, "failed"},
+		{"loadstring", `loadstring("return 1")()`, "failed"},
+		{"runtime-error", `error("boom")`, "boom"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Middleware(buildConfig(&v1.Script{Source: tt.source, Timeout: durationpb.New(50 * time.Millisecond)}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = m(next).RoundTrip(httptest.NewRequest("GET", "/api/echo", nil))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("want error containing %q but got: %v", tt.want, err)
+			}
+		})
+	}
+	if _, err := Middleware(buildConfig(&v1.Script{Source: `if then`})); err == nil {
+		t.Fatal("want error for invalid script")
+	}
+}
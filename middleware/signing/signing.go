@@ -0,0 +1,223 @@
+package signing
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/signing/v1"
+	"github.com/cnsync/gateway/middleware"
+	"github.com/cnsync/gateway/secrets"
+	"github.com/cnsync/kratos/selector"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// HMAC 签名写入的请求头
+const (
+	// HeaderKeyID 携带签名使用的密钥 ID
+	HeaderKeyID = "X-Gateway-Key-Id"
+	// HeaderTimestamp 携带签名时的 Unix 时间戳
+	HeaderTimestamp = "X-Gateway-Timestamp"
+	// HeaderSignedHeaders 携带参与签名的请求头名称，以分号分隔
+	HeaderSignedHeaders = "X-Gateway-Signed-Headers"
+	// HeaderSignature 携带十六进制编码的签名
+	HeaderSignature = "X-Gateway-Signature"
+)
+
+// _defaultSigner 是默认的签名器名称
+const _defaultSigner = "hmac"
+
+// _defaultMaxBodyBytes 是默认允许签名的最大请求体大小
+const _defaultMaxBodyBytes = 10 << 20
+
+// Signer 接口定义了出站请求的签名器，签名器在选择节点之后、请求转发给上游之前对请求进行签名
+type Signer interface {
+	Sign(req *http.Request) error
+}
+
+// SignerFactory 是一个工厂函数，用于根据配置创建签名器
+type SignerFactory func(*v1.Signing) (Signer, error)
+
+var (
+	signersLock sync.RWMutex
+	signers     = map[string]SignerFactory{
+		_defaultSigner: newHMACSigner,
+	}
+)
+
+// RegisterSigner 注册一个签名器工厂，例如 AWS SigV4 等其他签名方式
+func RegisterSigner(name string, factory SignerFactory) {
+	signersLock.Lock()
+	defer signersLock.Unlock()
+	signers[name] = factory
+}
+
+// 包初始化时注册 signing 中间件
+func init() {
+	middleware.Register("signing", Middleware)
+}
+
+// Middleware 函数根据传入的配置对象 c 创建一个出站请求签名中间件实例
+func Middleware(c *config.Middleware) (middleware.Middleware, error) {
+	options := &v1.Signing{}
+	if c.Options != nil {
+		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+	}
+	name := options.Signer
+	if name == "" {
+		name = _defaultSigner
+	}
+	signersLock.RLock()
+	factory, ok := signers[name]
+	signersLock.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("signer %s has not been registered", name)
+	}
+	signer, err := factory(options)
+	if err != nil {
+		return nil, err
+	}
+	// 选择节点之后才确定发往上游的主机和地址，因此在节点变换中签名，签名的请求与上游收到的请求一致
+	sign := func(req *http.Request, _ selector.Node) error {
+		return signer.Sign(req)
+	}
+	return func(next http.RoundTripper) http.RoundTripper {
+		return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return next.RoundTrip(req.WithContext(middleware.WithNodeTransform(req.Context(), sign)))
+		})
+	}, nil
+}
+
+// secretFromOptions 返回配置的密钥，环境变量优先于配置中的密钥，配置中的密钥可以是密钥引用
+func secretFromOptions(options *v1.Signing) ([]byte, error) {
+	secret := os.Getenv(options.SecretEnv)
+	if options.SecretEnv == "" {
+		var err error
+		if secret, err = secrets.Resolve(context.Background(), options.Secret); err != nil {
+			return nil, err
+		}
+	}
+	if secret == "" {
+		return nil, fmt.Errorf("signing secret is empty")
+	}
+	return []byte(secret), nil
+}
+
+// hmacSigner 结构体使用 HMAC-SHA256 对请求进行签名
+type hmacSigner struct {
+	keyID         string
+	secret        []byte
+	signedHeaders []string
+	maxBodyBytes  int64
+	now           func() time.Time
+}
+
+// newHMACSigner 根据配置创建一个 HMAC 签名器
+func newHMACSigner(options *v1.Signing) (Signer, error) {
+	secret, err := secretFromOptions(options)
+	if err != nil {
+		return nil, err
+	}
+	signedHeaders := make([]string, 0, len(options.SignedHeaders))
+	for _, h := range options.SignedHeaders {
+		signedHeaders = append(signedHeaders, strings.ToLower(h))
+	}
+	sort.Strings(signedHeaders)
+	maxBodyBytes := options.MaxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = _defaultMaxBodyBytes
+	}
+	return &hmacSigner{
+		keyID:         options.KeyId,
+		secret:        secret,
+		signedHeaders: signedHeaders,
+		maxBodyBytes:  maxBodyBytes,
+		now:           time.Now,
+	}, nil
+}
+
+// Sign 方法计算请求的签名并写入请求头
+func (s *hmacSigner) Sign(req *http.Request) error {
+	bodyHash, err := hashBody(req, s.maxBodyBytes)
+	if err != nil {
+		return err
+	}
+	timestamp := strconv.FormatInt(s.now().Unix(), 10)
+	req.Header.Set(HeaderKeyID, s.keyID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignedHeaders, strings.Join(s.signedHeaders, ";"))
+	req.Header.Set(HeaderSignature, Sum(s.secret, StringToSign(req, timestamp, s.signedHeaders, bodyHash)))
+	return nil
+}
+
+// hashBody 一边读取请求体一边计算 sha256 摘要，请求体按端点的请求缓冲配置缓存在内存或临时文件中，
+// 缓存的请求体替换原来的请求体继续转发，并在请求结束后释放，超过 maxBytes 的请求体返回错误
+func hashBody(req *http.Request, maxBytes int64) (string, error) {
+	h := sha256.New()
+	if req.Body != nil && req.Body != http.NoBody {
+		var cfg *config.RequestBuffering
+		if reqOpts, ok := middleware.FromRequestContext(req.Context()); ok {
+			cfg = reqOpts.Endpoint.GetRequestBuffering()
+		}
+		body, err := middleware.BufferRequestBody(io.TeeReader(http.MaxBytesReader(nil, req.Body, maxBytes), h), cfg)
+		req.Body.Close()
+		if err != nil {
+			body.Close()
+			return "", err
+		}
+		// 请求结束后删除缓存请求体的临时文件
+		context.AfterFunc(req.Context(), func() { body.Close() })
+		req.Body = body.NewReader()
+		req.GetBody = func() (io.ReadCloser, error) {
+			return body.NewReader(), nil
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// StringToSign 返回 HMAC 签名的待签名字符串，上游可以使用相同的方式验证签名：
+// 请求方法、路径、排序后的查询参数、时间戳、参与签名的请求头以及请求体摘要，以换行符分隔
+func StringToSign(req *http.Request, timestamp string, signedHeaders []string, bodyHash string) string {
+	var b strings.Builder
+	b.WriteString(req.Method)
+	b.WriteByte('\n')
+	b.WriteString(req.URL.EscapedPath())
+	b.WriteByte('\n')
+	b.WriteString(req.URL.Query().Encode())
+	b.WriteByte('\n')
+	b.WriteString(timestamp)
+	b.WriteByte('\n')
+	for _, h := range signedHeaders {
+		v := req.Header.Get(h)
+		if h == "host" {
+			v = req.Host
+		}
+		b.WriteString(h)
+		b.WriteByte(':')
+		b.WriteString(strings.TrimSpace(v))
+		b.WriteByte('\n')
+	}
+	b.WriteString(bodyHash)
+	return b.String()
+}
+
+// Sum 返回十六进制编码的 HMAC-SHA256 签名
+func Sum(secret []byte, stringToSign string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(stringToSign))
+	return hex.EncodeToString(mac.Sum(nil))
+}
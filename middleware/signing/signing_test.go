@@ -0,0 +1,233 @@
+package signing
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/signing/v1"
+	"github.com/cnsync/gateway/client"
+	"github.com/cnsync/gateway/middleware"
+	"github.com/cnsync/kratos/registry"
+	"github.com/cnsync/kratos/selector"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func buildConfig(t *testing.T, options *v1.Signing) *config.Middleware {
+	v, err := anypb.New(options)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &config.Middleware{Name: "signing", Options: v}
+}
+
+// verify 模拟上游使用相同的密钥验证签名
+func verify(t *testing.T, req *http.Request, secret string) bool {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(body)
+	var signedHeaders []string
+	if v := req.Header.Get(HeaderSignedHeaders); v != "" {
+		signedHeaders = strings.Split(v, ";")
+	}
+	stringToSign := StringToSign(req, req.Header.Get(HeaderTimestamp), signedHeaders, hex.EncodeToString(sum[:]))
+	return Sum([]byte(secret), stringToSign) == req.Header.Get(HeaderSignature)
+}
+
+// selectNode 模拟客户端选择节点之后改写请求的主机和地址，再应用节点变换
+func selectNode(req *http.Request) error {
+	node := selector.NewNode("http", "10.0.0.1:8000", &registry.ServiceInstance{Metadata: map[string]string{"host": "backend.internal"}})
+	req.URL.Host = node.Address()
+	req.Host = node.Metadata()["host"]
+	for _, transform := range middleware.NodeTransformsFromContext(req.Context()) {
+		if err := transform(req, node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestHMACSigning(t *testing.T) {
+	t.Setenv("TEST_SIGNING_SECRET", "env-secret")
+	tests := []struct {
+		name    string
+		options *v1.Signing
+		secret  string
+	}{
+		{"secret", &v1.Signing{KeyId: "gateway", Secret: "secret"}, "secret"},
+		{"secret-env", &v1.Signing{KeyId: "gateway", Secret: "secret", SecretEnv: "TEST_SIGNING_SECRET"}, "env-secret"},
+		{"secret-reference", &v1.Signing{KeyId: "gateway", Secret: "env://TEST_SIGNING_SECRET"}, "env-secret"},
+		{"signed-headers", &v1.Signing{KeyId: "gateway", Secret: "secret", SignedHeaders: []string{"Host", "Content-Type"}}, "secret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Middleware(buildConfig(t, tt.options))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var upstream *http.Request
+			next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				if err := selectNode(req); err != nil {
+					return nil, err
+				}
+				upstream = req
+				return &http.Response{StatusCode: http.StatusOK}, nil
+			})
+			req := httptest.NewRequest("POST", "http://example.com/api/echo?b=2&a=1", bytes.NewBufferString(`{"hello":"world"}`))
+			req.Header.Set("Content-Type", "application/json")
+			if _, err := m(next).RoundTrip(req); err != nil {
+				t.Fatal(err)
+			}
+			if got := upstream.Header.Get(HeaderKeyID); got != "gateway" {
+				t.Fatalf("want key id gateway but got %q", got)
+			}
+			if !verify(t, upstream, tt.secret) {
+				t.Fatalf("invalid signature: %v", upstream.Header)
+			}
+			// 篡改请求后签名失效
+			upstream.URL.Path = "/api/other"
+			upstream.Body = io.NopCloser(bytes.NewBufferString(`{"hello":"world"}`))
+			if verify(t, upstream, tt.secret) {
+				t.Fatal("want signature to be invalid after the request is tampered")
+			}
+		})
+	}
+}
+
+func TestHMACSigningBody(t *testing.T) {
+	m, err := Middleware(buildConfig(t, &v1.Signing{KeyId: "gateway", Secret: "secret", MaxBodyBytes: 16}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var upstream *http.Request
+	next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if err := selectNode(req); err != nil {
+			return nil, err
+		}
+		upstream = req
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+	// 超过内存大小限制的请求体按端点的请求缓冲配置缓存在临时文件中，请求结束后删除
+	dir := t.TempDir()
+	endpoint := &config.Endpoint{RequestBuffering: &config.RequestBuffering{MaxMemoryBytes: 4, TempDir: dir}}
+	ctx, cancel := context.WithCancel(context.Background())
+	req := httptest.NewRequest("POST", "http://example.com/api/echo", strings.NewReader(strings.Repeat("a", 16)))
+	req = req.WithContext(middleware.NewRequestContext(ctx, middleware.NewRequestOptions(endpoint)))
+	if _, err := m(next).RoundTrip(req); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Fatalf("want the body spooled to a temp file but got %d files", len(files))
+	}
+	replay, err := upstream.GetBody()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verify(t, upstream, "secret") {
+		t.Fatalf("invalid signature: %v", upstream.Header)
+	}
+	if b, _ := io.ReadAll(replay); string(b) != strings.Repeat("a", 16) {
+		t.Fatalf("want the body replayable but got %q", b)
+	}
+	cancel()
+	deadline := time.Now().Add(time.Second)
+	for files, _ := os.ReadDir(dir); len(files) != 0; files, _ = os.ReadDir(dir) {
+		if time.Now().After(deadline) {
+			t.Fatalf("want the temp file removed after the request but got %d files", len(files))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// 超过最大大小的请求体不签名，请求失败
+	req = httptest.NewRequest("POST", "http://example.com/api/echo", bytes.NewBufferString(`{"hello":"world"}`))
+	_, err = m(next).RoundTrip(req)
+	var tooLarge *http.MaxBytesError
+	if !errors.As(err, &tooLarge) {
+		t.Fatalf("want MaxBytesError but got %v", err)
+	}
+}
+
+func TestSigningErrors(t *testing.T) {
+	if _, err := Middleware(buildConfig(t, &v1.Signing{KeyId: "gateway"})); err == nil {
+		t.Fatal("want error for empty secret")
+	}
+	if _, err := Middleware(buildConfig(t, &v1.Signing{Signer: "sigv4", Secret: "secret"})); err == nil {
+		t.Fatal("want error for unregistered signer")
+	}
+}
+
+type headerSigner struct{}
+
+func (headerSigner) Sign(req *http.Request) error {
+	req.Header.Set("Authorization", "Custom signed")
+	return nil
+}
+
+func TestRegisterSigner(t *testing.T) {
+	RegisterSigner("custom", func(*v1.Signing) (Signer, error) { return headerSigner{}, nil })
+	m, err := Middleware(buildConfig(t, &v1.Signing{Signer: "custom"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if err := selectNode(req); err != nil {
+			return nil, err
+		}
+		if req.Header.Get("Authorization") != "Custom signed" {
+			t.Fatalf("want custom signature but got %v", req.Header)
+		}
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+	if _, err := m(next).RoundTrip(httptest.NewRequest("GET", "/", nil)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHMACSigningUpstream(t *testing.T) {
+	// 上游使用收到的请求验证签名，签名的主机是节点元数据中的主机而不是客户端请求的主机
+	verified := make(chan bool, 1)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verified <- r.Host == "backend.internal" && verify(t, r, "secret")
+	}))
+	defer upstream.Close()
+
+	endpoint := &config.Endpoint{
+		Path:     "/api/echo",
+		Protocol: config.Protocol_HTTP,
+		Backends: []*config.Backend{{
+			Target:   strings.TrimPrefix(upstream.URL, "http://"),
+			Metadata: map[string]string{"host": "backend.internal"},
+		}},
+	}
+	c, err := client.NewFactory(nil)(client.EmptyBuildContext(), endpoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	m, err := Middleware(buildConfig(t, &v1.Signing{KeyId: "gateway", Secret: "secret", SignedHeaders: []string{"Host"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("POST", "http://example.com/api/echo?b=2&a=1", bytes.NewBufferString(`{"hello":"world"}`))
+	req = req.WithContext(middleware.NewRequestContext(req.Context(), middleware.NewRequestOptions(endpoint)))
+	resp, err := m(c).RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if !<-verified {
+		t.Fatal("want the signature valid for the request the upstream receives")
+	}
+}
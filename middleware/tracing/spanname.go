@@ -0,0 +1,94 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cnsync/gateway/middleware"
+)
+
+// defaultSpanName 是默认的 span 名称模板，使用端点的路径模板避免 span 名称的基数过高
+const defaultSpanName = "{method} {route}"
+
+// spanNamer 是解析后的 span 名称模板，由固定文本和变量交替组成
+type spanNamer []func(req *http.Request, b *strings.Builder)
+
+// newSpanNamer 函数解析 span 名称模板
+func newSpanNamer(template string) (spanNamer, error) {
+	if template == "" {
+		template = defaultSpanName
+	}
+	var namer spanNamer
+	for template != "" {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			namer = append(namer, literal(template))
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("span name template: unclosed variable in %q", template)
+		}
+		if start > 0 {
+			namer = append(namer, literal(template[:start]))
+		}
+		variable, err := newVariable(template[start+1 : start+end])
+		if err != nil {
+			return nil, err
+		}
+		namer = append(namer, variable)
+		template = template[start+end+1:]
+	}
+	return namer, nil
+}
+
+// literal 函数返回一个写入固定文本的片段
+func literal(s string) func(*http.Request, *strings.Builder) {
+	return func(_ *http.Request, b *strings.Builder) {
+		b.WriteString(s)
+	}
+}
+
+// newVariable 函数返回一个写入变量值的片段
+func newVariable(name string) (func(*http.Request, *strings.Builder), error) {
+	switch name {
+	case "method":
+		return func(req *http.Request, b *strings.Builder) { b.WriteString(req.Method) }, nil
+	case "route":
+		return func(req *http.Request, b *strings.Builder) { b.WriteString(route(req)) }, nil
+	case "path":
+		return func(req *http.Request, b *strings.Builder) { b.WriteString(req.URL.Path) }, nil
+	case "host":
+		return func(req *http.Request, b *strings.Builder) { b.WriteString(req.Host) }, nil
+	case "protocol":
+		return func(req *http.Request, b *strings.Builder) {
+			if o, ok := middleware.FromRequestContext(req.Context()); ok {
+				b.WriteString(o.Endpoint.GetProtocol().String())
+			}
+		}, nil
+	}
+	if attr, ok := strings.CutPrefix(name, "attr."); ok && attr != "" {
+		return func(req *http.Request, b *strings.Builder) {
+			b.WriteString(middleware.RequestAttributes(req.Context())[attr])
+		}, nil
+	}
+	return nil, fmt.Errorf("span name template: unknown variable {%s}", name)
+}
+
+// route 函数返回请求匹配的端点路径模板，没有端点时返回请求路径
+func route(req *http.Request) string {
+	if o, ok := middleware.FromRequestContext(req.Context()); ok && o.Endpoint.GetPath() != "" {
+		return o.Endpoint.GetPath()
+	}
+	return req.URL.Path
+}
+
+// name 方法根据请求生成 span 名称
+func (n spanNamer) name(req *http.Request) string {
+	var b strings.Builder
+	for _, part := range n {
+		part(req, &b)
+	}
+	return b.String()
+}
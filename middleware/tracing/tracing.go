@@ -2,7 +2,6 @@ package tracing
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"net/http"
 	"sync"
@@ -71,6 +70,11 @@ func Middleware(c *config.Middleware) (middleware.Middleware, error) {
 			otel.SetTextMapPropagator(propagator)
 		})
 	}
+	// 解析 span 名称模板
+	namer, err := newSpanNamer(options.SpanName)
+	if err != nil {
+		return nil, err
+	}
 	// 获取一个默认的 tracer 实例
 	tracer := otel.Tracer(defaultTracerName)
 	// 返回一个函数，该函数接受一个 http.RoundTripper 并返回一个新的 http.RoundTripper
@@ -80,12 +84,13 @@ func Middleware(c *config.Middleware) (middleware.Middleware, error) {
 			// 从请求中获取上下文，并创建一个新的 span
 			ctx, span := tracer.Start(
 				req.Context(),
-				fmt.Sprintf("%s %s", req.Method, req.URL.Path),
+				namer.name(req),
 				trace.WithSpanKind(trace.SpanKindClient),
 			)
-			// 设置 span 的属性，包括 HTTP 方法、目标 URL 和客户端 IP
+			// 设置 span 的属性，包括 HTTP 方法、路由模板、目标 URL 和客户端 IP
 			span.SetAttributes(
 				semconv.HTTPMethodKey.String(req.Method),
+				semconv.HTTPRouteKey.String(route(req)),
 				semconv.HTTPTargetKey.String(req.URL.Path),
 				semconv.NetPeerIPKey.String(req.RemoteAddr),
 			)
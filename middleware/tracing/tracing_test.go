@@ -10,6 +10,9 @@ import (
 	config "github.com/cnsync/gateway/api/gateway/config/v1"
 	v1 "github.com/cnsync/gateway/api/gateway/middleware/tracing/v1"
 	"github.com/cnsync/gateway/middleware"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"google.golang.org/protobuf/types/known/anypb"
 )
 
@@ -40,3 +43,60 @@ func TestTracer(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestSpanName(t *testing.T) {
+	// 先初始化全局的 tracerProvider，再替换为记录 span 的 tracerProvider
+	if _, err := Middleware(&config.Middleware{}); err != nil {
+		t.Fatal(err)
+	}
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+
+	next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	})
+	tests := []struct {
+		template string
+		endpoint *config.Endpoint
+		path     string
+		want     string
+	}{
+		{endpoint: &config.Endpoint{Path: "/users/{id}"}, path: "/users/1", want: "GET /users/{id}"},
+		{endpoint: &config.Endpoint{Path: "/users/{id}"}, path: "/users/2", want: "GET /users/{id}"},
+		{path: "/users/3", want: "GET /users/3"},
+		{template: "{protocol} {route} {attr.tenant}", endpoint: &config.Endpoint{Path: "/api/*", Protocol: config.Protocol_HTTP}, path: "/api/x", want: "HTTP /api/* acme"},
+		{template: "{host}{path}", endpoint: &config.Endpoint{Path: "/api/*"}, path: "/api/x", want: "example.com/api/x"},
+	}
+	for _, tt := range tests {
+		cfg, err := anypb.New(&v1.Tracing{SpanName: tt.template})
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := Middleware(&config.Middleware{Options: cfg})
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest("GET", tt.path, nil)
+		if tt.endpoint != nil {
+			req = req.WithContext(middleware.NewRequestContext(req.Context(), middleware.NewRequestOptions(tt.endpoint)))
+			middleware.SetRequestAttribute(req.Context(), "tenant", "acme")
+		}
+		if _, err := m(next).RoundTrip(req); err != nil {
+			t.Fatal(err)
+		}
+		spans := recorder.Ended()
+		if got := spans[len(spans)-1].Name(); got != tt.want {
+			t.Fatalf("want span name %q but got %q", tt.want, got)
+		}
+	}
+
+	for _, template := range []string{"{method", "{unknown}"} {
+		cfg, err := anypb.New(&v1.Tracing{SpanName: template})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Middleware(&config.Middleware{Options: cfg}); err == nil {
+			t.Fatalf("want error for span name template %q", template)
+		}
+	}
+}
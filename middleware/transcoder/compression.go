@@ -0,0 +1,57 @@
+package transcoder
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// decompress 根据 grpc-encoding 解压消息，compressed 为消息帧的压缩标志
+func decompress(compressed byte, encoding string, msg []byte) ([]byte, error) {
+	if compressed == 0 {
+		return msg, nil
+	}
+	var (
+		r   io.ReadCloser
+		err error
+	)
+	switch encoding {
+	case "gzip":
+		r, err = gzip.NewReader(bytes.NewReader(msg))
+	case "deflate":
+		r, err = zlib.NewReader(bytes.NewReader(msg))
+	case "", "identity":
+		return nil, errors.New("transcoder: compressed message without grpc-encoding")
+	default:
+		return nil, fmt.Errorf("transcoder: unsupported grpc-encoding: %s", encoding)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	// 限制解压后的大小，避免压缩炸弹
+	data, err := io.ReadAll(io.LimitReader(r, _maxStreamMessageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > _maxStreamMessageSize {
+		return nil, fmt.Errorf("transcoder: decompressed message too large: > %d", _maxStreamMessageSize)
+	}
+	return data, nil
+}
+
+// decodeMessage 解析一元响应的消息帧，并按 grpc-encoding 解压消息
+func decodeMessage(data []byte, encoding string) ([]byte, error) {
+	if len(data) < 5 {
+		return nil, fmt.Errorf("transcoder: malformed grpc message: %d bytes", len(data))
+	}
+	length := binary.BigEndian.Uint32(data[1:5])
+	if uint64(length) > uint64(len(data)-5) {
+		return nil, fmt.Errorf("transcoder: truncated grpc message: want %d bytes but got %d", length, len(data)-5)
+	}
+	return decompress(data[0], encoding, data[5:5+length])
+}
@@ -0,0 +1,226 @@
+package transcoder
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const (
+	// contentTypeGRPCWeb 是 gRPC-Web 二进制格式的 Content-Type 前缀
+	contentTypeGRPCWeb = "application/grpc-web"
+	// contentTypeGRPCWebText 是 gRPC-Web 文本格式的 Content-Type 前缀，消息帧以 base64 编码
+	contentTypeGRPCWebText = "application/grpc-web-text"
+)
+
+// _grpcWebTrailerFlag 是 gRPC-Web 中 trailer 帧的标志位
+const _grpcWebTrailerFlag = 0x80
+
+// _grpcStatusHeaders 是只有 trailer 的 gRPC 响应中放在响应头里的状态
+var _grpcStatusHeaders = []string{"Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin"}
+
+// grpcWeb 结构体描述了一个 gRPC-Web 请求的格式
+type grpcWeb struct {
+	// contentType 是客户端请求的 Content-Type，响应使用相同的 Content-Type
+	contentType string
+	// text 表示消息帧是否以 base64 编码
+	text bool
+	// subtype 是消息的编码格式，例如 +proto，可以为空
+	subtype string
+}
+
+// parseGRPCWeb 根据 Content-Type 判断请求是否是 gRPC-Web 请求
+func parseGRPCWeb(contentType string) (*grpcWeb, bool) {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	web := &grpcWeb{contentType: contentType}
+	switch {
+	case strings.HasPrefix(mediaType, contentTypeGRPCWebText):
+		web.text = true
+		web.subtype = mediaType[len(contentTypeGRPCWebText):]
+	case strings.HasPrefix(mediaType, contentTypeGRPCWeb):
+		web.subtype = mediaType[len(contentTypeGRPCWeb):]
+	default:
+		return nil, false
+	}
+	if web.subtype != "" && !strings.HasPrefix(web.subtype, "+") {
+		return nil, false
+	}
+	return web, true
+}
+
+// decodeGRPCWebText 解码 base64 编码的请求体，客户端可能将多段带有填充的 base64 拼接在一起发送
+func decodeGRPCWebText(data []byte) ([]byte, error) {
+	data = bytes.TrimSpace(data)
+	out := make([]byte, 0, base64.StdEncoding.DecodedLen(len(data)))
+	for len(data) > 0 {
+		end := bytes.IndexByte(data, '=')
+		if end < 0 {
+			end = len(data)
+		}
+		for end < len(data) && data[end] == '=' {
+			end++
+		}
+		chunk := data[:end]
+		decode := base64.StdEncoding.DecodeString
+		if len(chunk)%4 != 0 {
+			decode = base64.RawStdEncoding.DecodeString
+		}
+		b, err := decode(string(chunk))
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b...)
+		data = data[end:]
+	}
+	return out, nil
+}
+
+// roundTrip 方法将 gRPC-Web 请求转换为 gRPC 请求发往上游，并将上游的 trailer 编码为 gRPC-Web 的 trailer 帧，
+// 消息帧原样转发，不需要解析消息
+func (web *grpcWeb) roundTrip(next http.RoundTripper, req *http.Request) (*http.Response, error) {
+	b, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	if web.text {
+		if b, err = decodeGRPCWebText(b); err != nil {
+			return nil, err
+		}
+	}
+	req.Header.Set("Content-Type", "application/grpc"+web.subtype)
+	req.Header.Del("Content-Length")
+	req.ContentLength = int64(len(b))
+	req.Body = io.NopCloser(bytes.NewReader(b))
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	// 上游没有返回 gRPC 响应，例如网关自身的错误响应，原样返回
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/grpc") {
+		return resp, nil
+	}
+	// 返回新的响应对象，上游响应的 trailer 在响应体读完之后才会填充，由 grpcWebReader 写入 trailer 帧
+	out := *resp
+	out.Body = newGRPCWebReader(resp, web.text)
+	out.Trailer = nil
+	out.ContentLength = -1
+	out.Header.Set("Content-Type", web.contentType)
+	out.Header.Del("Content-Length")
+	return &out, nil
+}
+
+// grpcWebReader 结构体原样读取上游的消息帧，在末尾追加由上游 trailer 编码的 trailer 帧，
+// 文本格式时将所有数据以 base64 编码，只在末尾填充
+type grpcWebReader struct {
+	resp *http.Response
+	body io.ReadCloser
+	text bool
+	// trailer 是只有 trailer 的响应中从响应头移出的状态
+	trailer http.Header
+	// pending 是文本格式下还不足 3 个字节、尚未编码的数据
+	pending []byte
+	buf     bytes.Buffer
+	done    bool
+}
+
+// newGRPCWebReader 创建一个新的 grpcWebReader 实例，只有 trailer 的响应的状态从响应头移到 trailer 帧
+func newGRPCWebReader(resp *http.Response, text bool) *grpcWebReader {
+	r := &grpcWebReader{resp: resp, body: resp.Body, text: text, trailer: http.Header{}}
+	if resp.Header.Get("Grpc-Status") != "" {
+		for _, name := range _grpcStatusHeaders {
+			if values := resp.Header.Values(name); len(values) > 0 {
+				r.trailer[name] = values
+				resp.Header.Del(name)
+			}
+		}
+	}
+	return r
+}
+
+// Read 方法读取转换后的数据
+func (r *grpcWebReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.next(len(p)); err != nil {
+			return 0, err
+		}
+	}
+	return r.buf.Read(p)
+}
+
+// next 方法从上游读取下一段数据写入缓冲区，读到末尾时写入 trailer 帧
+func (r *grpcWebReader) next(size int) error {
+	if size < 512 {
+		size = 512
+	}
+	chunk := make([]byte, size)
+	n, err := r.body.Read(chunk)
+	r.write(chunk[:n])
+	if err == io.EOF {
+		r.done = true
+		r.write(r.trailerFrame())
+		if r.text && len(r.pending) > 0 {
+			r.buf.WriteString(base64.StdEncoding.EncodeToString(r.pending))
+			r.pending = nil
+		}
+		return nil
+	}
+	return err
+}
+
+// write 方法将数据写入缓冲区，文本格式时只编码 3 的整数倍个字节，避免在数据中间出现填充
+func (r *grpcWebReader) write(b []byte) {
+	if !r.text {
+		r.buf.Write(b)
+		return
+	}
+	r.pending = append(r.pending, b...)
+	n := len(r.pending) / 3 * 3
+	if n == 0 {
+		return
+	}
+	r.buf.WriteString(base64.StdEncoding.EncodeToString(r.pending[:n]))
+	r.pending = append(r.pending[:0], r.pending[n:]...)
+}
+
+// trailerFrame 方法将上游的 trailer 编码为 gRPC-Web 的 trailer 帧，每个 trailer 一行，名称为小写
+func (r *grpcWebReader) trailerFrame() []byte {
+	trailer := r.trailer.Clone()
+	for name, values := range r.resp.Trailer {
+		trailer[name] = append(trailer[name], values...)
+	}
+	names := make([]string, 0, len(trailer))
+	for name := range trailer {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var b bytes.Buffer
+	for _, name := range names {
+		for _, v := range trailer[name] {
+			b.WriteString(strings.ToLower(name))
+			b.WriteString(": ")
+			b.WriteString(v)
+			b.WriteString("\r\n")
+		}
+	}
+	frame := make([]byte, 5+b.Len())
+	frame[0] = _grpcWebTrailerFlag
+	binary.BigEndian.PutUint32(frame[1:], uint32(b.Len()))
+	copy(frame[5:], b.Bytes())
+	return frame
+}
+
+// Close 方法关闭原始的响应体
+func (r *grpcWebReader) Close() error {
+	return r.body.Close()
+}
@@ -0,0 +1,137 @@
+package transcoder
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// 服务端流式响应转换成的格式
+const (
+	// contentTypeNDJSON 是以换行符分隔的 JSON
+	contentTypeNDJSON = "application/x-ndjson"
+	// contentTypeJSONSeq 是 RFC 7464 定义的 JSON 文本序列，每条消息以 RS 开头、以换行符结尾
+	contentTypeJSONSeq = "application/json-seq"
+)
+
+// _maxStreamMessageSize 是流式响应中单条消息的最大长度
+const _maxStreamMessageSize = 16 << 20
+
+// streamingFormat 根据请求的 Accept 头判断客户端期望的流式响应格式，不期望流式响应时返回空字符串
+func streamingFormat(accept string) string {
+	for _, v := range strings.Split(accept, ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(v))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case contentTypeNDJSON, contentTypeJSONSeq:
+			return mediaType
+		}
+	}
+	return ""
+}
+
+// streamReader 结构体将 gRPC 服务端流式响应的消息帧逐条转换为流式 JSON，每次读取最多转换一条消息，
+// 以便上层在每条消息到达时立即写出
+type streamReader struct {
+	resp   *http.Response
+	body   io.ReadCloser
+	format string
+	// encoding 是上游消息的 grpc-encoding
+	encoding string
+	buf      bytes.Buffer
+	done     bool
+}
+
+// newStreamReader 创建一个新的 streamReader 实例
+func newStreamReader(resp *http.Response, format string) *streamReader {
+	return &streamReader{resp: resp, body: resp.Body, format: format, encoding: resp.Header.Get("grpc-encoding")}
+}
+
+// Read 方法读取转换后的数据
+func (r *streamReader) Read(p []byte) (int, error) {
+	for r.buf.Len() == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+	}
+	return r.buf.Read(p)
+}
+
+// next 方法读取下一个消息帧并写入缓冲区，读到流的末尾时检查 gRPC 状态
+func (r *streamReader) next() error {
+	var header [5]byte
+	if _, err := io.ReadFull(r.body, header[:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			r.done = true
+			return r.finish()
+		}
+		return err
+	}
+	length := binary.BigEndian.Uint32(header[1:])
+	if length > _maxStreamMessageSize {
+		return fmt.Errorf("transcoder: stream message too large: %d", length)
+	}
+	msg := make([]byte, length)
+	if _, err := io.ReadFull(r.body, msg); err != nil {
+		return err
+	}
+	msg, err := decompress(header[0], r.encoding, msg)
+	if err != nil {
+		return err
+	}
+	r.writeMessage(msg)
+	return nil
+}
+
+// finish 方法在流结束时检查 trailer 中的 gRPC 状态，如果不是 OK，则将状态作为最后一条消息写出
+func (r *streamReader) finish() error {
+	grpcStatus := r.resp.Trailer.Get("grpc-status")
+	if grpcStatus == "" || grpcStatus == "0" {
+		return nil
+	}
+	code, err := strconv.ParseInt(grpcStatus, 10, 64)
+	if err != nil {
+		return err
+	}
+	data, err := protojson.Marshal(&spb.Status{
+		Code:    int32(code),
+		Message: r.resp.Trailer.Get("grpc-message"),
+	})
+	if err != nil {
+		return err
+	}
+	msg := make([]byte, 0, len(data)+10)
+	msg = append(msg, `{"error":`...)
+	msg = append(msg, data...)
+	msg = append(msg, '}')
+	r.writeMessage(msg)
+	return nil
+}
+
+// writeMessage 方法按照流式格式将一条消息写入缓冲区
+func (r *streamReader) writeMessage(msg []byte) {
+	if r.format == contentTypeJSONSeq {
+		r.buf.WriteByte(0x1e)
+	}
+	r.buf.Write(msg)
+	r.buf.WriteByte('\n')
+}
+
+// Close 方法关闭原始的响应体
+func (r *streamReader) Close() error {
+	return r.body.Close()
+}
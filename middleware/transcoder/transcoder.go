@@ -10,10 +10,12 @@ import (
 	"strings"
 
 	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/transcoder/v1"
 	"github.com/cnsync/gateway/middleware"
 	spb "google.golang.org/genproto/googleapis/rpc/status"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
 )
 
 // decodeBinHeader 解码 base64 编码的二进制数据
@@ -47,8 +49,15 @@ func init() {
 	middleware.Register("transcoder", Middleware)
 }
 
-// Middleware 函数根据传入的配置对象 c 创建一个中间件实例
+// Middleware 函数根据传入的配置对象 c 创建一个中间件实例，将 JSON 请求和 gRPC-Web 请求转换为 gRPC 请求
 func Middleware(c *config.Middleware) (middleware.Middleware, error) {
+	options := &v1.Transcoder{}
+	if c.Options != nil {
+		if err := anypb.UnmarshalTo(c.Options, options, proto.UnmarshalOptions{Merge: true}); err != nil {
+			return nil, err
+		}
+	}
+	acceptEncoding := strings.Join(options.AcceptEncodings, ",")
 	// 返回一个函数，该函数接受一个 http.RoundTripper 并返回一个新的 http.RoundTripper
 	return func(next http.RoundTripper) http.RoundTripper {
 		// 返回一个 RoundTripperFunc，它是 http.RoundTripper 的一个实现
@@ -59,6 +68,12 @@ func Middleware(c *config.Middleware) (middleware.Middleware, error) {
 			contentType := req.Header.Get("Content-Type")
 			// 从上下文中获取端点信息
 			endpoint, _ := middleware.EndpointFromContext(ctx)
+			// 浏览器发送的 gRPC-Web 请求转换为 gRPC 请求，消息本身不需要转换
+			if endpoint.Protocol == config.Protocol_GRPC {
+				if web, ok := parseGRPCWeb(contentType); ok {
+					return web.roundTrip(next, req)
+				}
+			}
 			// 如果端点协议不是 gRPC 或者 Content-Type 不是以 application/grpc 开头，则直接返回
 			if endpoint.Protocol != config.Protocol_GRPC || strings.HasPrefix(contentType, "application/grpc") {
 				return next.RoundTrip(req)
@@ -78,6 +93,10 @@ func Middleware(c *config.Middleware) (middleware.Middleware, error) {
 			req.Header.Set("Content-Type", "application/grpc+"+strings.TrimLeft(contentType, "application/"))
 			// 删除请求的 Content-Length 头
 			req.Header.Del("Content-Length")
+			// 告知上游网关可以解压的消息编码
+			if acceptEncoding != "" {
+				req.Header.Set("grpc-accept-encoding", acceptEncoding)
+			}
 			// 设置请求的 ContentLength 为新数组的长度
 			req.ContentLength = int64(len(bb))
 			// 将请求体替换为新的字节数组
@@ -87,6 +106,15 @@ func Middleware(c *config.Middleware) (middleware.Middleware, error) {
 			if err != nil {
 				return nil, err
 			}
+			// 如果客户端期望流式响应，并且上游不是只返回了 trailer 的错误响应，则逐条转换服务端流式响应的消息
+			if format := streamingFormat(req.Header.Get("Accept")); format != "" && resp.Header.Get("grpc-status") == "" {
+				resp.Body = newStreamReader(resp, format)
+				resp.Header.Set("Content-Type", format)
+				resp.Header.Del("grpc-encoding")
+				resp.Header.Del("Content-Length")
+				resp.ContentLength = -1
+				return resp, nil
+			}
 			// 读取响应体
 			data, err := io.ReadAll(resp.Body)
 			if err != nil {
@@ -132,10 +160,16 @@ func Middleware(c *config.Middleware) (middleware.Middleware, error) {
 				// 创建一个新的响应，状态码为 200，包含 JSON 数据
 				return newResponse(200, resp.Header, data)
 			}
-			// 从响应数据中移除前 5 个字节
-			resp.Body = io.NopCloser(bytes.NewReader(data[5:]))
-			// 设置响应的 ContentLength 为移除前 5 个字节后的数据长度
-			resp.ContentLength = int64(len(data) - 5)
+			// 移除消息帧的前 5 个字节，消息被压缩时按 grpc-encoding 解压
+			msg, err := decodeMessage(data, resp.Header.Get("grpc-encoding"))
+			if err != nil {
+				return nil, err
+			}
+			resp.Body = io.NopCloser(bytes.NewReader(msg))
+			// 设置响应的 ContentLength 为消息的长度
+			resp.ContentLength = int64(len(msg))
+			// 消息已解压，不再向客户端声明消息编码
+			resp.Header.Del("grpc-encoding")
 			// 删除 Content-Length 头，因为 trailers 可能会影响长度
 			resp.Header.Del("Content-Length")
 			// 返回修改后的响应
@@ -0,0 +1,325 @@
+package transcoder
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	v1 "github.com/cnsync/gateway/api/gateway/middleware/transcoder/v1"
+	"github.com/cnsync/gateway/middleware"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+func grpcFrame(msg string) []byte {
+	b := make([]byte, 5+len(msg))
+	binary.BigEndian.PutUint32(b[1:], uint32(len(msg)))
+	copy(b[5:], msg)
+	return b
+}
+
+// streamingBackend 返回一个服务端流式的后端，每次从 messages 中收到一条消息时写出一个消息帧
+func streamingBackend(messages <-chan string, trailer http.Header) http.RoundTripper {
+	return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		pr, pw := io.Pipe()
+		resp := &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/grpc+json"}},
+			Body:       pr,
+			Trailer:    http.Header{},
+		}
+		go func() {
+			for msg := range messages {
+				if _, err := pw.Write(grpcFrame(msg)); err != nil {
+					return
+				}
+			}
+			for k, v := range trailer {
+				resp.Trailer[k] = v
+			}
+			pw.Close()
+		}()
+		return resp, nil
+	})
+}
+
+func newStreamingRequest(accept string) *http.Request {
+	req := httptest.NewRequest("POST", "/helloworld.Greeter/SayHelloStream", strings.NewReader(`{"name":"kratos"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", accept)
+	reqOpts := middleware.NewRequestOptions(&config.Endpoint{Protocol: config.Protocol_GRPC})
+	return req.WithContext(middleware.NewRequestContext(req.Context(), reqOpts))
+}
+
+func TestServerStreamingNDJSON(t *testing.T) {
+	m, err := Middleware(&config.Middleware{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := make(chan string)
+	resp, err := m(streamingBackend(messages, http.Header{"Grpc-Status": []string{"0"}})).RoundTrip(newStreamingRequest("application/x-ndjson"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Fatalf("want ndjson content type but got %q", ct)
+	}
+	reader := bufio.NewReader(resp.Body)
+	// 每条消息到达后即可读到，不需要等待整个流结束
+	for _, msg := range []string{`{"message":"hello 1"}`, `{"message":"hello 2"}`} {
+		messages <- msg
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line != msg+"\n" {
+			t.Fatalf("want %q but got %q", msg+"\n", line)
+		}
+	}
+	close(messages)
+	if rest, err := io.ReadAll(reader); err != nil || len(rest) != 0 {
+		t.Fatalf("want end of stream but got %q: %v", rest, err)
+	}
+}
+
+func TestServerStreamingJSONSeqError(t *testing.T) {
+	m, err := Middleware(&config.Middleware{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := make(chan string, 1)
+	messages <- `{"message":"hello"}`
+	close(messages)
+	trailer := http.Header{"Grpc-Status": []string{"13"}, "Grpc-Message": []string{"internal"}}
+	resp, err := m(streamingBackend(messages, trailer)).RoundTrip(newStreamingRequest("application/json-seq"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := bytes.Split(bytes.TrimPrefix(data, []byte{0x1e}), []byte{0x1e})
+	if len(records) != 2 {
+		t.Fatalf("want 2 records but got %q", data)
+	}
+	if string(records[0]) != `{"message":"hello"}`+"\n" {
+		t.Fatalf("unexpected record: %q", records[0])
+	}
+	if !bytes.HasPrefix(records[1], []byte(`{"error":`)) || !bytes.Contains(records[1], []byte("internal")) {
+		t.Fatalf("want error record but got %q", records[1])
+	}
+}
+
+func TestUnaryWithoutStreamingAccept(t *testing.T) {
+	m, err := Middleware(&config.Middleware{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if ct := req.Header.Get("Content-Type"); ct != "application/grpc+json" {
+			t.Fatalf("want grpc content type but got %q", ct)
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{"application/grpc+json"}},
+			Body:       io.NopCloser(bytes.NewReader(grpcFrame(`{"message":"hello"}`))),
+			Trailer:    http.Header{"Grpc-Status": []string{"0"}},
+		}, nil
+	})
+	resp, err := m(next).RoundTrip(newStreamingRequest("application/json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	if string(data) != `{"message":"hello"}` {
+		t.Fatalf("unexpected body: %q", data)
+	}
+}
+
+func gzipFrame(t *testing.T, msg string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write([]byte(msg)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	b := grpcFrame(buf.String())
+	b[0] = 1
+	return b
+}
+
+func TestCompressedResponse(t *testing.T) {
+	cfg, err := anypb.New(&v1.Transcoder{AcceptEncodings: []string{"gzip"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := Middleware(&config.Middleware{Options: cfg})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		accept   string
+		body     []byte
+		encoding string
+		want     string
+		wantErr  bool
+	}{
+		{name: "unary", accept: "application/json", body: gzipFrame(t, `{"message":"hello"}`), encoding: "gzip", want: `{"message":"hello"}`},
+		{name: "uncompressed", accept: "application/json", body: grpcFrame(`{"message":"hello"}`), encoding: "gzip", want: `{"message":"hello"}`},
+		{
+			name:     "stream",
+			accept:   "application/x-ndjson",
+			body:     append(gzipFrame(t, `{"message":"hello 1"}`), grpcFrame(`{"message":"hello 2"}`)...),
+			encoding: "gzip",
+			want:     `{"message":"hello 1"}` + "\n" + `{"message":"hello 2"}` + "\n",
+		},
+		{name: "unsupported", accept: "application/json", body: gzipFrame(t, `{}`), encoding: "snappy", wantErr: true},
+		{name: "missing encoding", accept: "application/json", body: gzipFrame(t, `{}`), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				if v := req.Header.Get("grpc-accept-encoding"); v != "gzip" {
+					t.Fatalf("want grpc-accept-encoding gzip but got %q", v)
+				}
+				header := http.Header{"Content-Type": []string{"application/grpc+json"}}
+				if tt.encoding != "" {
+					header.Set("Grpc-Encoding", tt.encoding)
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     header,
+					Body:       io.NopCloser(bytes.NewReader(tt.body)),
+					Trailer:    http.Header{"Grpc-Status": []string{"0"}},
+				}, nil
+			})
+			resp, err := m(next).RoundTrip(newStreamingRequest(tt.accept))
+			var data []byte
+			if err == nil {
+				data, err = io.ReadAll(resp.Body)
+			}
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("want error but got %q", data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Fatalf("want %q but got %q", tt.want, data)
+			}
+			if v := resp.Header.Get("Grpc-Encoding"); v != "" {
+				t.Fatalf("want grpc-encoding removed but got %q", v)
+			}
+		})
+	}
+}
+
+func TestGRPCWebUnary(t *testing.T) {
+	m, err := Middleware(&config.Middleware{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	trailer := func(text string) []byte {
+		b := grpcFrame(text)
+		b[0] = 0x80
+		return b
+	}
+	tests := []struct {
+		name        string
+		contentType string
+		text        bool
+		// trailersOnly 表示上游只返回了 trailer，状态在响应头中
+		trailersOnly bool
+		want         []byte
+	}{
+		{
+			name:        "grpc-web+proto",
+			contentType: "application/grpc-web+proto",
+			want:        append(grpcFrame("world"), trailer("grpc-message: not found\r\ngrpc-status: 5\r\n")...),
+		},
+		{
+			name:        "grpc-web-text",
+			contentType: "application/grpc-web-text",
+			text:        true,
+			want:        append(grpcFrame("world"), trailer("grpc-message: not found\r\ngrpc-status: 5\r\n")...),
+		},
+		{
+			name:         "trailers only",
+			contentType:  "application/grpc-web-text+proto",
+			text:         true,
+			trailersOnly: true,
+			want:         trailer("grpc-message: not found\r\ngrpc-status: 5\r\n"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				if ct, want := req.Header.Get("Content-Type"), "application/grpc"+strings.TrimPrefix(strings.TrimPrefix(tt.contentType, "application/grpc-web"), "-text"); ct != want {
+					t.Fatalf("want content type %q but got %q", want, ct)
+				}
+				if b, _ := io.ReadAll(req.Body); !bytes.Equal(b, grpcFrame("hello")) {
+					t.Fatalf("want the grpc frame but got %q", b)
+				}
+				status := http.Header{"Grpc-Status": []string{"5"}, "Grpc-Message": []string{"not found"}}
+				header := http.Header{"Content-Type": []string{"application/grpc+proto"}}
+				if tt.trailersOnly {
+					for k, v := range status {
+						header[k] = v
+					}
+					return &http.Response{StatusCode: http.StatusOK, Header: header, Body: http.NoBody}, nil
+				}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     header,
+					Body:       io.NopCloser(bytes.NewReader(grpcFrame("world"))),
+					Trailer:    status,
+				}, nil
+			})
+			body := grpcFrame("hello")
+			if tt.text {
+				// 客户端可能分段编码请求体
+				body = []byte(base64.StdEncoding.EncodeToString(body[:4]) + base64.StdEncoding.EncodeToString(body[4:]))
+			}
+			req := httptest.NewRequest("POST", "/helloworld.Greeter/SayHello", bytes.NewReader(body))
+			req.Header.Set("Content-Type", tt.contentType)
+			reqOpts := middleware.NewRequestOptions(&config.Endpoint{Protocol: config.Protocol_GRPC})
+			req = req.WithContext(middleware.NewRequestContext(req.Context(), reqOpts))
+			resp, err := m(next).RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.text {
+				if data, err = base64.StdEncoding.DecodeString(string(data)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !bytes.Equal(data, tt.want) {
+				t.Fatalf("want %q but got %q", tt.want, data)
+			}
+			if ct := resp.Header.Get("Content-Type"); ct != tt.contentType {
+				t.Fatalf("want content type %q but got %q", tt.contentType, ct)
+			}
+			if resp.Header.Get("Grpc-Status") != "" || resp.Trailer != nil {
+				t.Fatalf("want the status only in the trailer frame but got %v %v", resp.Header, resp.Trailer)
+			}
+		})
+	}
+}
@@ -57,12 +57,35 @@ type byHeader struct {
 }
 
 func (c *byHeader) Judge(resp *http.Response) bool {
-	v := resp.Header.Get(c.ByHeader.Name)
-	if v == "" {
+	return c.match(resp) != c.ByHeader.Negate
+}
+
+func (c *byHeader) match(resp *http.Response) bool {
+	values := resp.Header.Values(c.parsed.name)
+	if len(values) == 0 {
 		return false
 	}
-	_, ok := c.parsed.values[v]
-	return ok
+	if c.ByHeader.Present {
+		return true
+	}
+	for _, v := range values {
+		if _, ok := c.parsed.values[v]; ok {
+			return true
+		}
+		if !strings.Contains(v, ",") {
+			continue
+		}
+		for _, part := range strings.Split(v, ",") {
+			if _, ok := c.parsed.values[strings.TrimSpace(part)]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (c *byHeader) negated() bool {
+	return c.ByHeader.Negate
 }
 
 func (c *byHeader) Prepare() error {
@@ -117,14 +140,37 @@ func ParseConditon(in ...*config.Condition) ([]Condition, error) {
 	return conditions, nil
 }
 
+// negation 接口由可以否决其他条件的条件实现
+type negation interface {
+	negated() bool
+}
+
+func isNegated(cond Condition) bool {
+	n, ok := cond.(negation)
+	return ok && n.negated()
+}
+
+// JudgeConditons 函数判断响应是否满足任意一个条件，并且满足所有否定条件，任意否定条件不满足时否决匹配
 func JudgeConditons(conditions []Condition, resp *http.Response, onEmpty bool) bool {
 	if len(conditions) <= 0 {
 		return onEmpty
 	}
+	matched, positive := false, false
 	for _, cond := range conditions {
-		if cond.Judge(resp) {
-			return true
+		if isNegated(cond) {
+			if !cond.Judge(resp) {
+				return false
+			}
+			continue
+		}
+		positive = true
+		if !matched && cond.Judge(resp) {
+			matched = true
 		}
 	}
-	return false
+	// 只有否定条件时，未被否决即为匹配
+	if !positive {
+		return true
+	}
+	return matched
 }
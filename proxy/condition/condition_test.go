@@ -164,3 +164,67 @@ func TestRetryByHeader(t *testing.T) {
 		}
 	}
 }
+
+func TestRetryByHeaderMultiValue(t *testing.T) {
+	testCases := []struct {
+		header *config.ConditionHeader
+		resp   http.Header
+		result bool
+	}{
+		{&config.ConditionHeader{Name: "X-Error", Value: `["timeout", "overload"]`}, http.Header{"X-Error": {"other", "overload"}}, true},
+		{&config.ConditionHeader{Name: "X-Error", Value: `["timeout", "overload"]`}, http.Header{"X-Error": {"other, timeout"}}, true},
+		{&config.ConditionHeader{Name: "X-Error", Value: `["timeout", "overload"]`}, http.Header{"X-Error": {"other"}}, false},
+		{&config.ConditionHeader{Name: "X-Retryable", Present: true}, http.Header{"X-Retryable": {""}}, true},
+		{&config.ConditionHeader{Name: "X-Retryable", Present: true}, http.Header{}, false},
+		{&config.ConditionHeader{Name: "X-No-Retry", Present: true, Negate: true}, http.Header{"X-No-Retry": {"1"}}, false},
+		{&config.ConditionHeader{Name: "X-No-Retry", Present: true, Negate: true}, http.Header{}, true},
+		{&config.ConditionHeader{Name: "Grpc-Status", Value: "14", Negate: true}, http.Header{"Grpc-Status": {"5"}}, true},
+	}
+	for i, tc := range testCases {
+		conditions, err := ParseConditon(&config.Condition{Condition: &config.Condition_ByHeader{ByHeader: tc.header}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := conditions[0].Judge(&http.Response{Header: tc.resp}); got != tc.result {
+			t.Errorf("case %d: want %v but got %v", i, tc.result, got)
+		}
+	}
+}
+
+func TestJudgeNegatedConditions(t *testing.T) {
+	conditions, err := ParseConditon(
+		&config.Condition{Condition: &config.Condition_ByStatusCode{ByStatusCode: "500-599"}},
+		&config.Condition{Condition: &config.Condition_ByHeader{ByHeader: &config.ConditionHeader{Name: "X-No-Retry", Present: true, Negate: true}}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testCases := []struct {
+		resp   *http.Response
+		result bool
+	}{
+		{&http.Response{StatusCode: 503, Header: http.Header{}}, true},
+		// 否定条件否决了状态码的匹配
+		{&http.Response{StatusCode: 503, Header: http.Header{"X-No-Retry": {"1"}}}, false},
+		{&http.Response{StatusCode: 200, Header: http.Header{}}, false},
+	}
+	for i, tc := range testCases {
+		if got := JudgeConditons(conditions, tc.resp, false); got != tc.result {
+			t.Errorf("case %d: want %v but got %v", i, tc.result, got)
+		}
+	}
+
+	// 只有否定条件时，未被否决的响应都满足条件
+	conditions, err = ParseConditon(
+		&config.Condition{Condition: &config.Condition_ByHeader{ByHeader: &config.ConditionHeader{Name: "X-No-Retry", Present: true, Negate: true}}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !JudgeConditons(conditions, &http.Response{StatusCode: 500, Header: http.Header{}}, false) {
+		t.Error("want matched without the negated header")
+	}
+	if JudgeConditons(conditions, &http.Response{StatusCode: 500, Header: http.Header{"X-No-Retry": {"1"}}}, false) {
+		t.Error("want vetoed with the negated header")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"net/http/pprof"
 	"path"
 	"strings"
+	"sync"
 
 	rmux "github.com/cnsync/gateway/router/mux"
 	"github.com/cnsync/kratos/log"
@@ -47,6 +48,8 @@ var globalService = &debugService{
 	},
 	// mux 是一个路由器，用于处理调试请求的路由
 	mux: mux.NewRouter(),
+	// disabled 是被禁用的调试处理程序分组
+	disabled: map[string]bool{},
 }
 
 // Register 函数用于向全局的 debugService 实例注册一个可调试的服务
@@ -55,13 +58,19 @@ func Register(name string, debuggable Debuggable) {
 	globalService.Register(name, debuggable)
 }
 
+// Disable 函数禁用指定分组的调试处理程序，被禁用的分组返回 404。
+// 分组是 /debug/ 之后的第一段路径，例如 pprof、ping 以及通过 Register 注册的名称
+func Disable(groups ...string) {
+	globalService.Disable(groups...)
+}
+
 // MashupWithDebugHandler 函数将调试处理程序与原始处理程序合并
 func MashupWithDebugHandler(origin http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		// 检查请求的 URL 路径是否以 _debugPrefix 开头
 		if strings.HasPrefix(req.URL.Path, _debugPrefix) {
-			// 如果是，则使用受保护的处理程序来处理请求
-			rmux.ProtectedHandler(globalService).ServeHTTP(w, req)
+			// 如果是，则使用受保护的处理程序来处理请求，处理完成后记录调试操作的审计事件
+			rmux.ProtectedHandler("debug", globalService).ServeHTTP(w, req)
 			return
 		}
 		// 如果不是，则使用原始处理程序来处理请求
@@ -80,10 +89,47 @@ type debugService struct {
 	handlers map[string]http.HandlerFunc
 	// mux 是一个路由器，用于处理调试请求的路由
 	mux *mux.Router
+	// lock 保护 disabled
+	lock sync.RWMutex
+	// disabled 是被禁用的调试处理程序分组
+	disabled map[string]bool
+}
+
+// group 函数返回调试请求所属的分组，即 /debug/ 之后的第一段路径
+func group(p string) string {
+	p = strings.TrimPrefix(strings.TrimPrefix(p, _debugPrefix), "/")
+	name, _, _ := strings.Cut(p, "/")
+	return name
+}
+
+// Disable 方法禁用指定分组的调试处理程序
+func (d *debugService) Disable(groups ...string) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	for _, g := range groups {
+		g = strings.TrimSpace(g)
+		if g == "" {
+			continue
+		}
+		d.disabled[g] = true
+		log.Infof("disable debug: %s", path.Join(_debugPrefix, g))
+	}
+}
+
+// isDisabled 方法判断分组是否被禁用
+func (d *debugService) isDisabled(g string) bool {
+	d.lock.RLock()
+	defer d.lock.RUnlock()
+	return d.disabled[g]
 }
 
 // ServeHTTP 方法实现了 http.Handler 接口，用于处理 HTTP 请求
 func (d *debugService) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	// 被禁用的分组与不存在的处理程序一样返回 404
+	if d.isDisabled(group(req.URL.Path)) {
+		http.NotFound(w, req)
+		return
+	}
 	// 遍历 handlers 映射，查找与请求路径匹配的处理函数
 	for path, handler := range d.handlers {
 		// 如果找到匹配的路径，则调用相应的处理函数
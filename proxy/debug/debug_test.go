@@ -0,0 +1,97 @@
+package debug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cnsync/gateway/audit"
+	"github.com/gorilla/mux"
+)
+
+type debuggable struct{}
+
+func (debuggable) DebugHandler() http.Handler {
+	debugMux := http.NewServeMux()
+	debugMux.HandleFunc("/debug/proxy/router/inspect", func(rw http.ResponseWriter, r *http.Request) {
+		_, _ = rw.Write([]byte("inspect"))
+	})
+	return debugMux
+}
+
+func TestDisable(t *testing.T) {
+	d := &debugService{
+		handlers: globalService.handlers,
+		mux:      mux.NewRouter(),
+		disabled: map[string]bool{},
+	}
+	d.Register("proxy", debuggable{})
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/debug/pprof/", http.StatusOK},
+		{"/debug/proxy/router/inspect", http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		d.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.Code != tt.want {
+			t.Fatalf("%s: want status %d but got %d", tt.path, tt.want, w.Code)
+		}
+	}
+
+	d.Disable("pprof")
+	tests = []struct {
+		path string
+		want int
+	}{
+		{"/debug/pprof/", http.StatusNotFound},
+		{"/debug/pprof/trace", http.StatusNotFound},
+		{"/debug/pprof/heap", http.StatusNotFound},
+		{"/debug/ping", http.StatusOK},
+		{"/debug/proxy/router/inspect", http.StatusOK},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		d.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.Code != tt.want {
+			t.Fatalf("%s: want status %d but got %d", tt.path, tt.want, w.Code)
+		}
+	}
+}
+
+type memorySink struct {
+	events []*audit.Event
+}
+
+func (s *memorySink) Write(e *audit.Event) error {
+	s.events = append(s.events, e)
+	return nil
+}
+
+func (s *memorySink) Close() error { return nil }
+
+func TestMashupAudit(t *testing.T) {
+	sink := &memorySink{}
+	l := audit.NewLogger(sink, 0)
+	defer audit.SetLogger(audit.SetLogger(l))
+
+	h := MashupWithDebugHandler(http.NotFoundHandler())
+	for _, path := range []string{"/debug/ping", "/debug/unknown", "/other"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+	l.Close()
+
+	// 调试操作的结果在处理完成之后根据响应状态码记录，非调试路径不记录
+	if len(sink.events) != 2 {
+		t.Fatalf("want 2 audit events but got %d", len(sink.events))
+	}
+	if e := sink.events[0]; e.Action != "debug" || e.Result != audit.ResultSuccess || e.Resource != "GET /debug/ping" {
+		t.Fatalf("unexpected audit event: %+v", e)
+	}
+	if e := sink.events[1]; e.Action != "debug" || e.Result != audit.ResultFailure || e.Reason != "status 404" {
+		t.Fatalf("unexpected audit event: %+v", e)
+	}
+}
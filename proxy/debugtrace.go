@@ -0,0 +1,126 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cnsync/gateway/middleware"
+)
+
+const (
+	// _debugTraceHeader 是开启单个请求调试跟踪的请求头，也是返回调试跟踪的响应头
+	_debugTraceHeader = "X-Debug-Trace"
+	// _debugTraceTrailer 是请求头的取值，表示调试跟踪作为响应的 Trailer 返回，从而包括复制响应体的过程
+	_debugTraceTrailer = "trailer"
+)
+
+// debugTraceKey 是调试跟踪在请求上下文中的键
+type debugTraceKey struct{}
+
+// debugTrace 结构体记录单个请求经过网关的过程，包括中间件的结果、选择的节点和各阶段的耗时
+type debugTrace struct {
+	start   time.Time
+	trailer bool
+	lock    sync.Mutex
+	events  []string
+}
+
+// newDebugTrace 函数为携带调试跟踪请求头的受信任请求创建调试跟踪，否则返回 nil。
+// 需要在设置 X-Forwarded-For 之前调用，请求头不会转发给上游
+func newDebugTrace(req *http.Request) *debugTrace {
+	v := req.Header.Get(_debugTraceHeader)
+	if v == "" || !isTrustedSource(req) {
+		return nil
+	}
+	req.Header.Del(_debugTraceHeader)
+	return &debugTrace{
+		start:   time.Now(),
+		trailer: strings.EqualFold(v, _debugTraceTrailer),
+	}
+}
+
+// withDebugTrace 函数返回携带调试跟踪的上下文
+func withDebugTrace(ctx context.Context, t *debugTrace) context.Context {
+	return context.WithValue(ctx, debugTraceKey{}, t)
+}
+
+// debugTraceFromContext 函数返回上下文中的调试跟踪，没有时返回 nil
+func debugTraceFromContext(ctx context.Context) *debugTrace {
+	t, _ := ctx.Value(debugTraceKey{}).(*debugTrace)
+	return t
+}
+
+// record 方法记录一个事件及其相对请求开始的时间，t 为 nil 时不做任何操作
+func (t *debugTrace) record(format string, args ...any) {
+	if t == nil {
+		return
+	}
+	elapsed := time.Since(t.start)
+	event := fmt.Sprintf(format, args...)
+	// 事件作为响应头的值返回，不能包含换行
+	event = strings.NewReplacer("\r", " ", "\n", " ").Replace(event)
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.events = append(t.events, fmt.Sprintf("+%.3fms %s", float64(elapsed.Microseconds())/1000, event))
+}
+
+// String 方法返回以分号分隔的所有事件
+func (t *debugTrace) String() string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	return strings.Join(t.events, "; ")
+}
+
+// writeHeader 方法在写入响应头之前将调试跟踪设置到响应头，作为 Trailer 返回时不做任何操作。
+// force 为 true 时总是设置到响应头，用于网关直接返回错误的情况
+func (t *debugTrace) writeHeader(w http.ResponseWriter, force bool) {
+	if t == nil || (t.trailer && !force) {
+		return
+	}
+	w.Header().Set(_debugTraceHeader, t.String())
+}
+
+// writeTrailer 方法在复制响应体之后将调试跟踪设置到响应的 Trailer
+func (t *debugTrace) writeTrailer(w http.ResponseWriter) {
+	if t == nil || !t.trailer {
+		return
+	}
+	w.Header().Set(http.TrailerPrefix+_debugTraceHeader, t.String())
+}
+
+// traceMiddleware 函数包装一个中间件，为开启调试跟踪的请求记录中间件的耗时和结果
+func traceMiddleware(name string, next http.RoundTripper) http.RoundTripper {
+	return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		t := debugTraceFromContext(req.Context())
+		if t == nil {
+			return next.RoundTrip(req)
+		}
+		start := time.Now()
+		resp, err := next.RoundTrip(req)
+		elapsed := float64(time.Since(start).Microseconds()) / 1000
+		if err != nil {
+			t.record("middleware %s failed in %.3fms: %v", name, elapsed, err)
+		} else {
+			t.record("middleware %s returned %d in %.3fms", name, resp.StatusCode, elapsed)
+		}
+		return resp, err
+	})
+}
+
+// traceAttempt 函数记录一次发往上游的尝试，包括选择的节点、上游的状态码和耗时
+func traceAttempt(t *debugTrace, opts *middleware.RequestOptions, i int, elapsed time.Duration, resp *http.Response, err error) {
+	node := "none"
+	if opts.CurrentNode != nil {
+		node = opts.CurrentNode.Address()
+	}
+	ms := float64(elapsed.Microseconds()) / 1000
+	if err != nil {
+		t.record("attempt %d to node %s failed in %.3fms: %v", i+1, node, ms, err)
+		return
+	}
+	t.record("attempt %d to node %s returned %d in %.3fms", i+1, node, resp.StatusCode, ms)
+}
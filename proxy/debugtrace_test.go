@@ -0,0 +1,94 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/client"
+	"github.com/cnsync/gateway/middleware"
+	"github.com/cnsync/gateway/middleware/logging"
+	"github.com/cnsync/kratos/selector"
+)
+
+func TestDebugTrace(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol:    config.Protocol_HTTP,
+			Path:        "/debug/trace",
+			Method:      "GET",
+			Backends:    []*config.Backend{{Target: "127.0.0.1:8000"}},
+			Middlewares: []*config.Middleware{{Name: "logging"}},
+		}},
+	}
+	var upstreamHeader string
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			opts, _ := middleware.FromRequestContext(req.Context())
+			opts.CurrentNode = selector.NewNode("http", "127.0.0.1:8000", nil)
+			upstreamHeader = req.Header.Get(_debugTraceHeader)
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody}, nil
+		}), nil
+	}
+	middlewareFactory := func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return logging.Middleware(c)
+	}
+	p, err := New(clientFactory, middlewareFactory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	do := func(remoteAddr, trace string) *http.Response {
+		req := httptest.NewRequest("GET", "/debug/trace", nil)
+		req.RemoteAddr = remoteAddr
+		if trace != "" {
+			req.Header.Set(_debugTraceHeader, trace)
+		}
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("want 200 but got %d", w.Code)
+		}
+		return w.Result()
+	}
+
+	// 受信任的来源开启调试跟踪，请求头不转发给上游
+	resp := do("127.0.0.1:1234", "1")
+	got := resp.Header.Get(_debugTraceHeader)
+	for _, want := range []string{"endpoint GET /debug/trace", "attempt 1 to node 127.0.0.1:8000 returned 200", "middleware logging returned 200"} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("want %q in the debug trace but got %q", want, got)
+		}
+	}
+	if upstreamHeader != "" {
+		t.Fatalf("want the debug trace header not forwarded but got %q", upstreamHeader)
+	}
+
+	// 作为 Trailer 返回时包括复制响应体的过程
+	resp = do("127.0.0.1:1234", "trailer")
+	if got := resp.Header.Get(_debugTraceHeader); got != "" {
+		t.Fatalf("want no debug trace header but got %q", got)
+	}
+	if got := resp.Trailer.Get(_debugTraceHeader); !strings.Contains(got, "response body copied") {
+		t.Fatalf("want the debug trace in the trailer but got %q", got)
+	}
+
+	// 不受信任的来源和未开启调试跟踪的请求没有调试跟踪
+	for _, tt := range []struct{ remoteAddr, trace string }{
+		{"127.0.0.1:1234", ""},
+		{"203.0.113.1:1234", "1"},
+	} {
+		resp := do(tt.remoteAddr, tt.trace)
+		if got := resp.Header.Get(_debugTraceHeader); got != "" {
+			t.Fatalf("%s: want no debug trace but got %q", tt.remoteAddr, got)
+		}
+	}
+	if upstreamHeader != "1" {
+		t.Fatalf("want the header of the untrusted request forwarded but got %q", upstreamHeader)
+	}
+}
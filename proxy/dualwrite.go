@@ -0,0 +1,230 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/client"
+	"github.com/cnsync/gateway/middleware"
+	"github.com/cnsync/kratos/log"
+	"github.com/cnsync/kratos/selector"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+)
+
+// _defaultDualWriteMethods 是默认写入次要后端的请求方法
+var _defaultDualWriteMethods = []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// errSecondaryWriteFailed 是写入次要后端失败的错误
+var errSecondaryWriteFailed = errors.New("secondary write failed")
+
+// _metricDualWriteRequestsTotal 是一个计数器，按结果记录写入次要后端的请求数
+var _metricDualWriteRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "dual_write_requests_total",
+	Help:      "The total number of write requests sent to the secondary backends by result",
+}, []string{"protocol", "method", "path", "service", "basePath", "backend", "result"})
+
+func init() {
+	prometheus.MustRegister(_metricDualWriteRequestsTotal)
+}
+
+// secondaryBackend 结构体是双写的一个次要后端
+type secondaryBackend struct {
+	target string
+	client client.Client
+}
+
+// dualWrite 结构体将写请求同时发往端点的后端和每个次要后端，返回端点后端的响应。
+// 忽略失败时次要后端的写入在后台进行，否则等待所有写入完成，任一次要后端写入失败时请求失败
+type dualWrite struct {
+	endpoint    *config.Endpoint
+	labels      *metricsLabels
+	primary     http.RoundTripper
+	secondaries []*secondaryBackend
+	methods     map[string]bool
+	fail        bool
+	// timeout 是后台写入次要后端的超时时间
+	timeout time.Duration
+}
+
+// newDualWrite 函数根据端点配置为每个次要后端创建客户端，返回的关闭器列表包括所有创建的客户端
+func newDualWrite(factory client.Factory, buildCtx *client.BuildContext, e *config.Endpoint, primary http.RoundTripper) (_ *dualWrite, closers []io.Closer, retError error) {
+	defer func() {
+		if retError != nil {
+			for _, c := range closers {
+				c.Close()
+			}
+		}
+	}()
+	c := e.DualWrite
+	if len(c.Secondaries) == 0 {
+		return nil, closers, fmt.Errorf("no secondary backends for dual write on endpoint: %s %s", e.Method, e.Path)
+	}
+	d := &dualWrite{
+		endpoint: e,
+		labels:   newMetricsLabels(e),
+		primary:  primary,
+		methods:  make(map[string]bool),
+		fail:     c.OnFailure == config.DualWrite_FAIL,
+		timeout:  calcTimeout(e),
+	}
+	if c.Timeout != nil && c.Timeout.AsDuration() > 0 {
+		d.timeout = c.Timeout.AsDuration()
+	}
+	methods := c.Methods
+	if len(methods) == 0 {
+		methods = _defaultDualWriteMethods
+	}
+	for _, m := range methods {
+		d.methods[strings.ToUpper(m)] = true
+	}
+	for _, b := range c.Secondaries {
+		se := proto.Clone(e).(*config.Endpoint)
+		se.Backends = []*config.Backend{b}
+		se.BackendSchedules = nil
+		se.ErrorBudget = nil
+		se.DualWrite = nil
+		sc, err := factory(buildCtx, se)
+		if err != nil {
+			return nil, closers, err
+		}
+		closers = append(closers, sc)
+		d.secondaries = append(d.secondaries, &secondaryBackend{target: b.Target, client: sc})
+	}
+	return d, closers, nil
+}
+
+// readDualWriteBody 函数读取请求体的副本，次要后端的写入可能在请求结束之后进行，因此不能复用请求体
+func readDualWriteBody(req *http.Request) ([]byte, error) {
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, err
+}
+
+// secondaryRequest 方法为次要后端复制请求，复制的请求使用独立的请求选项，不影响发往端点后端的请求
+func (d *dualWrite) secondaryRequest(ctx context.Context, req *http.Request, body []byte) *http.Request {
+	sreq := req.Clone(middleware.NewRequestContext(ctx, middleware.NewRequestOptions(d.endpoint)))
+	sreq.ContentLength = int64(len(body))
+	sreq.Body = io.NopCloser(bytes.NewReader(body))
+	sreq.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	return sreq
+}
+
+// secondaryRequests 方法为每个次要后端复制请求
+func (d *dualWrite) secondaryRequests(ctx context.Context, req *http.Request, body []byte) []*http.Request {
+	reqs := make([]*http.Request, len(d.secondaries))
+	for i := range d.secondaries {
+		reqs[i] = d.secondaryRequest(ctx, req, body)
+	}
+	return reqs
+}
+
+// write 方法将请求写入一个次要后端，记录写入的结果，响应状态码为 5xx 时视为失败
+func (d *dualWrite) write(s *secondaryBackend, req *http.Request) error {
+	ctx := req.Context()
+	opts, _ := middleware.FromRequestContext(ctx)
+	resp, err := s.client.RoundTrip(req)
+	if err == nil {
+		_, err = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		opts.DoneFunc(ctx, selector.DoneInfo{Err: err})
+		if err == nil && resp.StatusCode >= http.StatusInternalServerError {
+			err = fmt.Errorf("status code %d", resp.StatusCode)
+		}
+	}
+	result := "success"
+	if err != nil {
+		result = "failure"
+		log.Warnf("Failed to write request %s %s to the secondary backend %s: %v", req.Method, req.URL.Path, s.target, err)
+		err = fmt.Errorf("%w to %s: %v", errSecondaryWriteFailed, s.target, err)
+	}
+	if !d.endpoint.Metrics.GetDisableAll() {
+		_metricDualWriteRequestsTotal.WithLabelValues(d.labels.Protocol(), req.Method, d.labels.Path(), d.labels.Service(), d.labels.BasePath(), s.target, result).Inc()
+	}
+	return err
+}
+
+// writeSecondaries 方法并发写入所有次要后端，返回第一个失败的写入的错误
+func (d *dualWrite) writeSecondaries(reqs []*http.Request) error {
+	errs := make([]error, len(reqs))
+	var wg sync.WaitGroup
+	for i, s := range d.secondaries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = d.write(s, reqs[i])
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RoundTrip 方法将写请求同时发往端点的后端和所有次要后端，返回端点后端的响应
+func (d *dualWrite) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !d.methods[req.Method] {
+		return d.primary.RoundTrip(req)
+	}
+	body, err := readDualWriteBody(req)
+	if err != nil {
+		return nil, err
+	}
+	// 发往端点后端的请求会被客户端修改，需要在发送之前复制请求
+	if !d.fail {
+		// 后台写入不随请求结束而取消
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(req.Context()), d.timeout)
+		reqs := d.secondaryRequests(ctx, req, body)
+		go func() {
+			defer cancel()
+			d.writeSecondaries(reqs)
+		}()
+		return d.primary.RoundTrip(req)
+	}
+	reqs := d.secondaryRequests(req.Context(), req, body)
+	result := make(chan error, 1)
+	go func() {
+		result <- d.writeSecondaries(reqs)
+	}()
+	resp, err := d.primary.RoundTrip(req)
+	secondaryErr := <-result
+	if err != nil {
+		return nil, err
+	}
+	if secondaryErr != nil {
+		// 端点后端的响应被丢弃，完成该次选择并关闭响应体
+		if opts, ok := middleware.FromRequestContext(req.Context()); ok {
+			opts.DoneFunc(req.Context(), selector.DoneInfo{})
+		}
+		resp.Body.Close()
+		return nil, secondaryErr
+	}
+	return resp, nil
+}
@@ -0,0 +1,114 @@
+package proxy
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/client"
+	"github.com/cnsync/gateway/middleware"
+	"github.com/cnsync/gateway/middleware/logging"
+)
+
+func TestDualWrite(t *testing.T) {
+	for _, policy := range []config.DualWrite_FailurePolicy{config.DualWrite_IGNORE, config.DualWrite_FAIL} {
+		t.Run(policy.String(), func(t *testing.T) {
+			path := "/dualwrite/" + strings.ToLower(policy.String())
+			c := &config.Gateway{
+				Name: "Test",
+				Endpoints: []*config.Endpoint{{
+					Protocol: config.Protocol_HTTP,
+					Path:     path,
+					Backends: []*config.Backend{{Target: "primary"}},
+					DualWrite: &config.DualWrite{
+						Secondaries: []*config.Backend{{Target: "healthy"}, {Target: "broken"}},
+						OnFailure:   policy,
+					},
+				}},
+			}
+			// 次要后端收到的请求体按目标记录
+			writes := make(chan string, 10)
+			clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+				target := e.Backends[0].Target
+				return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+					body, err := io.ReadAll(req.Body)
+					if err != nil {
+						return nil, err
+					}
+					statusCode := http.StatusOK
+					if target != "primary" {
+						writes <- target + ":" + string(body)
+						if target == "broken" {
+							statusCode = http.StatusInternalServerError
+						}
+					}
+					return &http.Response{StatusCode: statusCode, Header: http.Header{"X-Backend": {target}}, Body: http.NoBody}, nil
+				}), nil
+			}
+			middlewareFactory := func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+				return logging.Middleware(c)
+			}
+			p, err := New(clientFactory, middlewareFactory)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := p.Update(client.NewBuildContext(c), c); err != nil {
+				t.Fatal(err)
+			}
+
+			// 读请求只发往端点的后端
+			w := httptest.NewRecorder()
+			p.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+			if w.Code != http.StatusOK || w.Header().Get("X-Backend") != "primary" {
+				t.Fatalf("want the primary response but got %d from %q", w.Code, w.Header().Get("X-Backend"))
+			}
+			select {
+			case got := <-writes:
+				t.Fatalf("want no secondary write for GET but got %s", got)
+			case <-time.After(50 * time.Millisecond):
+			}
+
+			// 写请求同时发往所有次要后端
+			w = httptest.NewRecorder()
+			p.ServeHTTP(w, httptest.NewRequest("POST", path, strings.NewReader("payload")))
+			switch policy {
+			case config.DualWrite_IGNORE:
+				if w.Code != http.StatusOK || w.Header().Get("X-Backend") != "primary" {
+					t.Fatalf("want the primary response but got %d from %q", w.Code, w.Header().Get("X-Backend"))
+				}
+			case config.DualWrite_FAIL:
+				if w.Code != http.StatusBadGateway {
+					t.Fatalf("want the request failed by the secondary but got %d", w.Code)
+				}
+			}
+			got := map[string]bool{}
+			for i := 0; i < 2; i++ {
+				select {
+				case write := <-writes:
+					got[write] = true
+				case <-time.After(time.Second):
+					t.Fatal("want the write sent to every secondary")
+				}
+			}
+			if !got["healthy:payload"] || !got["broken:payload"] {
+				t.Fatalf("want the body written to every secondary but got %v", got)
+			}
+			labels := map[string]string{"path": path, "method": "POST", "backend": "broken", "result": "failure"}
+			deadline := time.Now().Add(time.Second)
+			for metricValue(t, "go_gateway_dual_write_requests_total", labels) != 1 {
+				if time.Now().After(deadline) {
+					t.Fatal("want the failed secondary write counted")
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			labels["backend"], labels["result"] = "healthy", "success"
+			if got := metricValue(t, "go_gateway_dual_write_requests_total", labels); got != 1 {
+				t.Fatalf("want the successful secondary write counted but got %v", got)
+			}
+		})
+	}
+}
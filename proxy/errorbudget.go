@@ -0,0 +1,191 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/client"
+	"github.com/cnsync/kratos/log"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// _defaultErrorBudgetWindow 是默认统计失败请求的滑动窗口
+	_defaultErrorBudgetWindow = time.Minute
+	// _defaultErrorBudgetMinRequests 是默认开始检查错误预算之前窗口内的最少请求数
+	_defaultErrorBudgetMinRequests = 20
+	// _errorBudgetBuckets 是滑动窗口的桶数
+	_errorBudgetBuckets = 10
+)
+
+// errErrorBudgetExhausted 是错误预算耗尽时拒绝请求的错误
+var errErrorBudgetExhausted = errors.New("error budget exhausted")
+
+// _metricErrorBudgetExhausted 是一个仪表，记录端点的错误预算是否耗尽
+var _metricErrorBudgetExhausted = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "error_budget_exhausted",
+	Help:      "Whether the error budget of the endpoint is exhausted and the action is applied",
+}, []string{"protocol", "path", "service", "basePath"})
+
+func init() {
+	prometheus.MustRegister(_metricErrorBudgetExhausted)
+}
+
+// errorBudgetBucket 结构体是滑动窗口中的一个桶
+type errorBudgetBucket struct {
+	// epoch 是桶所属的时间段序号
+	epoch    int64
+	requests int64
+	failures int64
+}
+
+// errorBudget 结构体统计端点在滑动窗口内的失败请求比例，超过预算时对部分流量执行配置的动作，
+// 动作执行期间失败的请求逐渐移出窗口，预算恢复后自动撤销动作
+type errorBudget struct {
+	lock        sync.Mutex
+	budget      float64
+	minRequests int64
+	weight      float64
+	bucketSize  time.Duration
+	buckets     [_errorBudgetBuckets]errorBudgetBucket
+	exhausted   bool
+	now         func() time.Time
+	random      func() float64
+
+	endpoint *config.Endpoint
+	labels   *metricsLabels
+	primary  http.RoundTripper
+	// fallback 是备用后端的客户端，为 nil 时拒绝请求
+	fallback http.RoundTripper
+}
+
+// newErrorBudget 函数根据端点配置创建错误预算，配置了转移动作时 fallback 是备用后端的客户端
+func newErrorBudget(e *config.Endpoint, primary, fallback http.RoundTripper) *errorBudget {
+	c := e.ErrorBudget
+	b := &errorBudget{
+		budget:      c.Budget,
+		minRequests: _defaultErrorBudgetMinRequests,
+		weight:      1,
+		bucketSize:  _defaultErrorBudgetWindow / _errorBudgetBuckets,
+		now:         time.Now,
+		random:      rand.Float64,
+		endpoint:    e,
+		labels:      newMetricsLabels(e),
+		primary:     primary,
+		fallback:    fallback,
+	}
+	if c.MinRequests > 0 {
+		b.minRequests = c.MinRequests
+	}
+	if c.Weight > 0 && c.Weight < 1 {
+		b.weight = c.Weight
+	}
+	if c.Window != nil && c.Window.AsDuration() > 0 {
+		b.bucketSize = c.Window.AsDuration() / _errorBudgetBuckets
+	}
+	b.setExhaustedMetric(false)
+	return b
+}
+
+// newFallbackClient 函数为端点的错误预算创建备用后端的客户端，配置了拒绝动作时返回 nil
+func newFallbackClient(factory client.Factory, buildCtx *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+	c := e.ErrorBudget
+	if c.Budget <= 0 || c.Budget >= 1 {
+		return nil, fmt.Errorf("invalid error budget %v on endpoint: %s %s", c.Budget, e.Method, e.Path)
+	}
+	if c.Action != config.ErrorBudget_FALLBACK {
+		return nil, nil
+	}
+	if len(c.FallbackBackends) == 0 {
+		return nil, fmt.Errorf("no fallback backends on endpoint: %s %s", e.Method, e.Path)
+	}
+	fe := proto.Clone(e).(*config.Endpoint)
+	fe.Backends = c.FallbackBackends
+	fe.ErrorBudget = nil
+	return factory(buildCtx, fe)
+}
+
+// setExhaustedMetric 方法更新错误预算是否耗尽的指标
+func (b *errorBudget) setExhaustedMetric(exhausted bool) {
+	if b.endpoint.Metrics.GetDisableAll() {
+		return
+	}
+	var v float64
+	if exhausted {
+		v = 1
+	}
+	_metricErrorBudgetExhausted.WithLabelValues(b.labels.Protocol(), b.labels.Path(), b.labels.Service(), b.labels.BasePath()).Set(v)
+}
+
+// stat 方法返回窗口内的请求数和失败数，调用方需要持有锁
+func (b *errorBudget) stat(epoch int64) (requests, failures int64) {
+	for _, bucket := range b.buckets {
+		if epoch-bucket.epoch < _errorBudgetBuckets {
+			requests += bucket.requests
+			failures += bucket.failures
+		}
+	}
+	return requests, failures
+}
+
+// divert 方法重新判断错误预算是否耗尽，返回当前请求是否需要执行动作
+func (b *errorBudget) divert() bool {
+	b.lock.Lock()
+	epoch := b.now().UnixNano() / int64(b.bucketSize)
+	requests, failures := b.stat(epoch)
+	exhausted := requests >= b.minRequests && float64(failures) > b.budget*float64(requests)
+	changed := exhausted != b.exhausted
+	b.exhausted = exhausted
+	b.lock.Unlock()
+	if changed {
+		if exhausted {
+			log.Warnf("Error budget exhausted on endpoint: %s %s, %d of %d requests failed, applying %s to %v of the traffic",
+				b.endpoint.Method, b.endpoint.Path, failures, requests, b.endpoint.ErrorBudget.Action, b.weight)
+		} else {
+			log.Infof("Error budget recovered on endpoint: %s %s, reverting %s", b.endpoint.Method, b.endpoint.Path, b.endpoint.ErrorBudget.Action)
+		}
+		b.setExhaustedMetric(exhausted)
+	}
+	return exhausted && (b.weight >= 1 || b.random() < b.weight)
+}
+
+// record 方法记录一次发往主后端的请求的结果
+func (b *errorBudget) record(failed bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	epoch := b.now().UnixNano() / int64(b.bucketSize)
+	bucket := &b.buckets[epoch%_errorBudgetBuckets]
+	if bucket.epoch != epoch {
+		*bucket = errorBudgetBucket{epoch: epoch}
+	}
+	bucket.requests++
+	if failed {
+		bucket.failures++
+	}
+}
+
+// RoundTrip 方法在错误预算耗尽时将请求转移到备用后端或拒绝请求，否则发往主后端并记录结果
+func (b *errorBudget) RoundTrip(req *http.Request) (*http.Response, error) {
+	if b.divert() {
+		if b.fallback == nil {
+			return nil, errErrorBudgetExhausted
+		}
+		return b.fallback.RoundTrip(req)
+	}
+	resp, err := b.primary.RoundTrip(req)
+	// 客户端取消的请求不代表后端失败
+	if errors.Is(err, context.Canceled) {
+		return resp, err
+	}
+	b.record(err != nil || resp.StatusCode >= http.StatusInternalServerError)
+	return resp, err
+}
@@ -0,0 +1,115 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// _defaultGenerationWait 是等待配置代数时默认的最长等待时间
+const _defaultGenerationWait = 30 * time.Second
+
+// GenerationInfo 结构体定义了当前生效的配置代数
+type GenerationInfo struct {
+	// Generation 是成功应用配置的次数，每次更新成功后加一
+	Generation uint64 `json:"generation"`
+	// ConfigHash 是当前生效配置的 sha256 摘要
+	ConfigHash string `json:"config_hash"`
+	// ConfigName 是当前生效配置的名称
+	ConfigName string `json:"config_name"`
+	// ConfigVersion 是当前生效配置的版本
+	ConfigVersion string `json:"config_version"`
+}
+
+// generations 结构体记录当前生效的配置代数，并在每次更新后唤醒所有等待者
+type generations struct {
+	lock sync.Mutex
+	info GenerationInfo
+	// applied 在下一次更新时被关闭
+	applied chan struct{}
+}
+
+// newGenerations 函数创建一个新的 generations 实例
+func newGenerations() *generations {
+	return &generations{applied: make(chan struct{})}
+}
+
+// advance 方法记录一次成功的配置更新，并唤醒所有等待者
+func (g *generations) advance(hash, name, version string) uint64 {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.info = GenerationInfo{
+		Generation:    g.info.Generation + 1,
+		ConfigHash:    hash,
+		ConfigName:    name,
+		ConfigVersion: version,
+	}
+	close(g.applied)
+	g.applied = make(chan struct{})
+	return g.info.Generation
+}
+
+// current 方法返回当前生效的配置代数，以及在下一次更新时被关闭的通道
+func (g *generations) current() (GenerationInfo, <-chan struct{}) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	return g.info, g.applied
+}
+
+// wait 方法等待直到 done 对当前的配置代数返回 true，或者上下文结束
+func (g *generations) wait(ctx context.Context, done func(GenerationInfo) bool) (GenerationInfo, bool) {
+	for {
+		info, applied := g.current()
+		if done(info) {
+			return info, true
+		}
+		select {
+		case <-applied:
+		case <-ctx.Done():
+			return info, false
+		}
+	}
+}
+
+// Generation 方法返回当前生效的配置代数
+func (p *Proxy) Generation() GenerationInfo {
+	info, _ := p.generations.current()
+	return info
+}
+
+// generationHandler 方法返回当前生效的配置代数，部署工具可以通过以下参数等待配置重新加载完成：
+// generation 等待代数不小于该值，hash 等待生效配置的摘要等于该值，timeout 是最长等待时间，超时后返回 504
+func (p *Proxy) generationHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var (
+		want    uint64
+		hash    = query.Get("hash")
+		timeout = _defaultGenerationWait
+		err     error
+	)
+	if v := query.Get("generation"); v != "" {
+		if want, err = strconv.ParseUint(v, 10, 64); err != nil {
+			http.Error(w, "invalid generation: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if v := query.Get("timeout"); v != "" {
+		if timeout, err = time.ParseDuration(v); err != nil {
+			http.Error(w, "invalid timeout: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+	info, ok := p.generations.wait(ctx, func(info GenerationInfo) bool {
+		return info.Generation >= want && (hash == "" || info.ConfigHash == hash)
+	})
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		w.WriteHeader(http.StatusGatewayTimeout)
+	}
+	_ = json.NewEncoder(w).Encode(info)
+}
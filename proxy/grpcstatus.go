@@ -0,0 +1,74 @@
+package proxy
+
+import (
+	"encoding/base64"
+	"net/http"
+	"os"
+
+	"github.com/cnsync/gateway/middleware/requestid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// _grpcErrorDomain 是网关生成的 gRPC 错误详情的错误域
+const _grpcErrorDomain = "gateway"
+
+// 网关生成 gRPC 错误的原因，写入 ErrorInfo.Reason
+const (
+	_reasonClientCanceled          = "CLIENT_CANCELED"
+	_reasonDeadlineExceeded        = "DEADLINE_EXCEEDED"
+	_reasonBackendRateLimited      = "BACKEND_RATE_LIMITED"
+	_reasonUpstreamHeadersTooLarge = "UPSTREAM_HEADERS_TOO_LARGE"
+	_reasonErrorBudgetExhausted    = "ERROR_BUDGET_EXHAUSTED"
+	_reasonUpstreamFailed          = "UPSTREAM_FAILED"
+)
+
+// gatewayName 是写入 gRPC 错误详情的网关实例名称，从环境变量 PROXY_GATEWAY_NAME 中读取，默认为主机名
+var gatewayName = os.Getenv("PROXY_GATEWAY_NAME")
+
+func init() {
+	if gatewayName == "" {
+		gatewayName, _ = os.Hostname()
+	}
+}
+
+// grpcStatusDetails 函数生成 grpc-status-details-bin 响应头的值，
+// 详情中的 ErrorInfo 携带错误原因、网关实例和请求路径，RequestInfo 携带请求 ID，便于 gRPC 客户端定位问题
+func grpcStatusDetails(r *http.Request, code int32, message, reason string) (string, error) {
+	metadata := map[string]string{
+		"gateway": gatewayName,
+		"path":    r.URL.Path,
+	}
+	id, hasID := requestid.FromRequest(r)
+	if hasID {
+		metadata["request_id"] = id
+	}
+	errorInfo, err := anypb.New(&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   _grpcErrorDomain,
+		Metadata: metadata,
+	})
+	if err != nil {
+		return "", err
+	}
+	st := &spb.Status{
+		Code:    code,
+		Message: message,
+		Details: []*anypb.Any{errorInfo},
+	}
+	if hasID {
+		requestInfo, err := anypb.New(&errdetails.RequestInfo{RequestId: id})
+		if err != nil {
+			return "", err
+		}
+		st.Details = append(st.Details, requestInfo)
+	}
+	data, err := proto.Marshal(st)
+	if err != nil {
+		return "", err
+	}
+	// 二进制响应头按 gRPC 规范使用不带填充的 base64 编码
+	return base64.RawStdEncoding.EncodeToString(data), nil
+}
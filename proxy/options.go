@@ -0,0 +1,29 @@
+package proxy
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cnsync/gateway/router"
+	"github.com/cnsync/gateway/router/mux"
+)
+
+// isPreflight 函数判断请求是否是 CORS 预检请求，预检请求由端点的 cors 中间件处理
+func isPreflight(req *http.Request) bool {
+	return req.Header.Get("Origin") != "" && req.Header.Get("Access-Control-Request-Method") != ""
+}
+
+// serveAutoOptions 函数由网关直接响应 OPTIONS 请求，Allow 响应头列出所有匹配请求路径的端点的请求方法，
+// 不是 OPTIONS 请求、是预检请求或者无法列出请求方法时返回 false，由路由器继续处理
+func serveAutoOptions(w http.ResponseWriter, req *http.Request, r router.Router) bool {
+	if req.Method != http.MethodOptions || isPreflight(req) {
+		return false
+	}
+	methods, ok := mux.AllowedMethods(r, req)
+	if !ok {
+		return false
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	w.WriteHeader(http.StatusNoContent)
+	return true
+}
@@ -0,0 +1,67 @@
+package proxy
+
+import (
+	"net/http"
+	"strings"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/kratos/log"
+)
+
+// _defaultMethodOverrideHeader 是默认携带覆盖方法的请求头
+const _defaultMethodOverrideHeader = "X-HTTP-Method-Override"
+
+// methodOverride 结构体保存了解析后的请求方法覆盖配置
+type methodOverride struct {
+	// header 是携带覆盖方法的请求头
+	header string
+	// allowed 是允许覆盖成的请求方法集合
+	allowed map[string]struct{}
+}
+
+// newMethodOverride 根据配置创建一个 methodOverride 实例，未配置允许的方法时返回 nil
+func newMethodOverride(c *config.MethodOverride) *methodOverride {
+	if c == nil || len(c.AllowedMethods) == 0 {
+		return nil
+	}
+	mo := &methodOverride{
+		header:  c.Header,
+		allowed: make(map[string]struct{}, len(c.AllowedMethods)),
+	}
+	if mo.header == "" {
+		mo.header = _defaultMethodOverrideHeader
+	}
+	for _, m := range c.AllowedMethods {
+		mo.allowed[strings.ToUpper(m)] = struct{}{}
+	}
+	return mo
+}
+
+// apply 方法在路由之前根据请求头覆盖请求方法，覆盖的方法不被允许时返回 false 并写入 405 响应
+func (mo *methodOverride) apply(w http.ResponseWriter, req *http.Request) bool {
+	// 只允许 POST 请求覆盖方法，避免 GET 请求产生副作用
+	if req.Method != http.MethodPost {
+		return true
+	}
+	method := req.Header.Get(mo.header)
+	if method == "" {
+		return true
+	}
+	method = strings.ToUpper(strings.TrimSpace(method))
+	if _, ok := mo.allowed[method]; !ok {
+		log.Context(req.Context()).Errorw(
+			"source", "accesslog",
+			"host", req.Host,
+			"method", req.Method,
+			"path", req.URL.Path,
+			"override", method,
+			"code", http.StatusMethodNotAllowed,
+			"error", "method override not allowed",
+		)
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return false
+	}
+	req.Method = method
+	req.Header.Del(mo.header)
+	return true
+}
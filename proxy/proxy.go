@@ -1,12 +1,12 @@
 package proxy
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net"
 	"net/http"
 	"os"
@@ -17,6 +17,7 @@ import (
 	"time"
 
 	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/audit"
 	"github.com/cnsync/gateway/client"
 	"github.com/cnsync/gateway/middleware"
 	"github.com/cnsync/gateway/router"
@@ -24,7 +25,6 @@ import (
 	"github.com/cnsync/kratos/log"
 	"github.com/cnsync/kratos/selector"
 	"github.com/cnsync/kratos/transport/http/status"
-	"github.com/go-kratos/aegis/circuitbreaker/sre"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -65,6 +65,47 @@ var (
 		Name:      "requests_retry_state",
 		Help:      "Total request retries",
 	}, []string{"protocol", "method", "path", "service", "basePath", "success"})
+	// _metricBodyErrors 是一个计数器，用于按类型记录读写请求体和响应体失败的次数
+	_metricBodyErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "requests_body_errors_total",
+		Help:      "Total request body read and response body copy errors",
+	}, []string{"protocol", "method", "path", "service", "basePath", "type"})
+	// _metricRequestsInFlight 是一个仪表，用于记录每个端点正在处理的请求数量
+	_metricRequestsInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "requests_in_flight",
+		Help:      "The number of requests currently being processed",
+	}, []string{"protocol", "method", "path", "service", "basePath"})
+	// _metricClientCanceled 是一个计数器，用于按阶段记录客户端在请求完成前取消请求或断开连接的次数
+	_metricClientCanceled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "requests_client_canceled_total",
+		Help:      "Total requests canceled by the client before completion",
+	}, []string{"protocol", "method", "path", "service", "basePath", "stage"})
+)
+
+// 读写请求体和响应体失败的类型
+const (
+	// _bodyErrorRequestRead 表示读取客户端请求体失败
+	_bodyErrorRequestRead = "request_read"
+	// _bodyErrorResponseCopy 表示读取上游响应体失败
+	_bodyErrorResponseCopy = "response_copy"
+	// _bodyErrorClientDisconnect 表示向客户端写入响应体失败，通常是客户端断开了连接
+	_bodyErrorClientDisconnect = "client_disconnect"
+	// _bodyErrorDeadline 表示复制响应体的过程中到达了请求的截止时间
+	_bodyErrorDeadline = "deadline"
+)
+
+// 客户端取消请求的阶段
+const (
+	// _canceledStageRequest 表示客户端在收到响应头之前取消了请求，此时上游请求会随之取消
+	_canceledStageRequest = "request"
+	// _canceledStageResponse 表示客户端在接收响应体的过程中断开了连接
+	_canceledStageResponse = "response"
 )
 
 // init 函数在程序启动时自动执行，用于注册 Prometheus 指标
@@ -79,6 +120,12 @@ func init() {
 	prometheus.MustRegister(_metricSentBytes)
 	// 注册 _metricReceivedBytes 指标，用于记录接收的总字节数
 	prometheus.MustRegister(_metricReceivedBytes)
+	// 注册 _metricBodyErrors 指标，用于记录读写请求体和响应体失败的次数
+	prometheus.MustRegister(_metricBodyErrors)
+	// 注册 _metricRequestsInFlight 指标，用于记录每个端点正在处理的请求数量
+	prometheus.MustRegister(_metricRequestsInFlight)
+	// 注册 _metricClientCanceled 指标，用于记录客户端取消请求的次数
+	prometheus.MustRegister(_metricClientCanceled)
 }
 
 // setXFFHeader 函数用于设置 HTTP 请求头中的 X-Forwarded-For 字段
@@ -101,17 +148,37 @@ func setXFFHeader(req *http.Request) {
 }
 
 // writeError 函数用于将错误信息写入 HTTP 响应
-func writeError(w http.ResponseWriter, r *http.Request, err error, labels middleware.MetricsLabels) {
+func writeError(w http.ResponseWriter, r *http.Request, err error, labels *metricsLabels) {
 	// 根据错误类型设置状态码
 	var statusCode int
+	// reason 是写入 gRPC 错误详情的错误原因
+	reason := _reasonUpstreamFailed
 	switch {
 	case errors.Is(err, context.Canceled),
 		err.Error() == "client disconnected":
 		// 客户端取消请求或断开连接
 		statusCode = 499
+		reason = _reasonClientCanceled
+		if clientCanceled(r) {
+			clientCanceledIncr(r, labels, _canceledStageRequest)
+		}
 	case errors.Is(err, context.DeadlineExceeded):
 		// 请求超时
 		statusCode = 504
+		reason = _reasonDeadlineExceeded
+	case errors.Is(err, client.ErrBackendRateLimited):
+		// 超过后端的出站速率限制
+		statusCode = 503
+		reason = _reasonBackendRateLimited
+	case errors.Is(err, client.ErrUpstreamHeadersTooLarge):
+		// 转发给上游的请求头超过了端点的限制
+		log.Warnf("Failed to handle request: %s: %v", r.URL.String(), err)
+		statusCode = http.StatusRequestHeaderFieldsTooLarge
+		reason = _reasonUpstreamHeadersTooLarge
+	case errors.Is(err, errErrorBudgetExhausted):
+		// 错误预算耗尽时拒绝的请求
+		statusCode = 503
+		reason = _reasonErrorBudgetExhausted
 	default:
 		// 其他错误
 		log.Errorf("Failed to handle request: %s: %+v", r.URL.String(), err)
@@ -123,11 +190,17 @@ func writeError(w http.ResponseWriter, r *http.Request, err error, labels middle
 	if labels.Protocol() == config.Protocol_GRPC.String() {
 		// see https://github.com/googleapis/googleapis/blob/master/google/rpc/code.proto
 		// 将状态码转换为 gRPC 错误码
-		code := strconv.Itoa(int(status.ToGRPCCode(statusCode)))
+		code := status.ToGRPCCode(statusCode)
 		// 设置响应头
 		w.Header().Set("Content-Type", "application/grpc")
-		w.Header().Set("Grpc-Status", code)
+		w.Header().Set("Grpc-Status", strconv.Itoa(int(code)))
 		w.Header().Set("Grpc-Message", err.Error())
+		// 附带结构化的错误详情，包括网关实例、请求 ID 和错误原因
+		if details, derr := grpcStatusDetails(r, int32(code), err.Error(), reason); derr == nil {
+			w.Header().Set("Grpc-Status-Details-Bin", details)
+		} else {
+			log.Errorf("Failed to encode grpc status details: %v", derr)
+		}
 		// gRPC 状态码为 200
 		statusCode = 200
 	}
@@ -174,6 +247,28 @@ func methodNotAllowedHandler(w http.ResponseWriter, r *http.Request) {
 	message := http.StatusText(code)
 	// 使用 http.Error 函数向客户端发送 405 错误
 	http.Error(w, message, code)
+	// 记录访问日志和指标
+	logMethodNotAllowed(r, code, message)
+}
+
+// newMethodNotAllowedHandler 函数根据端点配置创建请求方法不匹配时返回自定义响应的处理器
+func newMethodNotAllowedHandler(c *config.RejectResponse) http.Handler {
+	reject := middleware.NewRejectHandler(c, http.StatusMethodNotAllowed)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, _ := reject.RoundTrip(r)
+		defer resp.Body.Close()
+		headers := w.Header()
+		for k, v := range resp.Header {
+			headers[k] = v
+		}
+		w.WriteHeader(resp.StatusCode)
+		_, _ = io.Copy(w, resp.Body)
+		logMethodNotAllowed(r, resp.StatusCode, http.StatusText(http.StatusMethodNotAllowed))
+	})
+}
+
+// logMethodNotAllowed 函数记录请求方法不匹配的访问日志和指标
+func logMethodNotAllowed(r *http.Request, code int, message string) {
 	// 使用 log 包记录错误信息
 	log.Context(r.Context()).Errorw(
 		// 记录错误来源为 accesslog
@@ -211,6 +306,9 @@ func (i *interceptors) SetPrepareAttemptTimeoutContext(f func(ctx context.Contex
 	}
 }
 
+// _catchAllPattern 是默认端点的路由模式，匹配所有路径
+const _catchAllPattern = "/*"
+
 // Proxy 是一个网关代理。
 type Proxy struct {
 	// router 是一个原子值，用于存储路由器。
@@ -221,6 +319,24 @@ type Proxy struct {
 	Interceptors interceptors
 	// middlewareFactory 是一个中间件工厂，用于创建中间件。
 	middlewareFactory middleware.FactoryV2
+	// readiness 保存了最近一次成功更新的配置信息，用于就绪探针。
+	readiness atomic.Pointer[readinessState]
+	// methodOverride 保存了请求方法覆盖的配置，为空时不覆盖请求方法。
+	methodOverride atomic.Pointer[methodOverride]
+	// tenantResolver 解析请求所属的租户，为空时不区分租户。
+	tenantResolver atomic.Pointer[tenantResolver]
+	// warming 表示是否正在预热，预热期间就绪探针返回未就绪。
+	warming atomic.Bool
+	// draining 表示网关是否正在排空连接，排空开始后就绪探针返回未就绪。
+	draining atomic.Bool
+	// generations 记录当前生效的配置代数，用于确认配置重新加载完成。
+	generations *generations
+	// maxURLLength 是在路由之前检查的请求 URI 最大长度，为 0 时不限制。
+	maxURLLength atomic.Int64
+	// retryBreakers 保存了当前生效的所有端点的重试熔断器，用于管理接口查询和重置。
+	retryBreakers atomic.Pointer[[]*retryBreaker]
+	// autoOptions 表示是否由网关直接响应 OPTIONS 请求。
+	autoOptions atomic.Bool
 }
 
 // New 函数用于创建一个新的 Proxy 实例。
@@ -236,15 +352,48 @@ func New(clientFactory client.Factory, middlewareFactory middleware.FactoryV2) (
 			// 设置默认的尝试超时上下文函数。
 			prepareAttemptTimeoutContext: defaultAttemptTimeoutContext,
 		},
+		// 初始化配置代数。
+		generations: newGenerations(),
 	}
 	// 初始化路由器。
-	p.router.Store(mux.NewRouter(http.HandlerFunc(notFoundHandler), http.HandlerFunc(methodNotAllowedHandler)))
+	p.router.Store(p.newRouter())
+	// 加载配置之前使用全局的请求 URI 最大长度。
+	p.maxURLLength.Store(maxURLLength)
 	// 返回新创建的 Proxy 实例和 nil 错误。
 	return p, nil
 }
 
-// buildMiddleware 方法用于构建一个中间件链，其中每个中间件都会处理下一个中间件的请求。
-func (p *Proxy) buildMiddleware(ms []*config.Middleware, next http.RoundTripper) (http.RoundTripper, error) {
+// newRouter 方法创建一个新的路由器，并注册网关自身的处理程序。
+func (p *Proxy) newRouter() router.Router {
+	// 创建一个新的路由器，使用 notFoundHandler 和 methodNotAllowedHandler 作为默认处理器
+	r := mux.NewRouter(http.HandlerFunc(notFoundHandler), http.HandlerFunc(methodNotAllowedHandler))
+	// 配置了就绪探针的路径时注册就绪探针，先于端点注册以保证优先匹配
+	if readinessPath != "" {
+		_ = r.Handle(readinessPath, http.MethodGet, "", http.HandlerFunc(p.readinessHandler), io.NopCloser(nil))
+	}
+	return r
+}
+
+// shadowedPath 函数返回端点路由匹配的就绪探针的路径，该路径的 GET 请求不会到达端点，
+// 包括通配、路径参数和前缀匹配的端点，没有重叠时返回空字符串
+func shadowedPath(e *config.Endpoint) string {
+	if readinessPath != "" && mux.PatternMatches(e.Path, e.Method, http.MethodGet, readinessPath) {
+		return readinessPath
+	}
+	return ""
+}
+
+// buildMiddleware 方法用于构建一个中间件链，其中每个中间件都会处理下一个中间件的请求，
+// 同时返回所有中间件实例，以便在端点关闭时释放它们持有的资源。
+func (p *Proxy) buildMiddleware(ms []*config.Middleware, next http.RoundTripper) (_ http.RoundTripper, closers []io.Closer, retError error) {
+	// 构建失败时关闭已经创建的中间件实例。
+	defer func() {
+		if retError != nil {
+			for _, c := range closers {
+				_ = c.Close()
+			}
+		}
+	}()
 	// 遍历中间件列表，从后往前遍历。
 	for i := len(ms) - 1; i >= 0; i-- {
 		// 从中间件工厂中获取中间件实例。
@@ -259,19 +408,48 @@ func (p *Proxy) buildMiddleware(ms []*config.Middleware, next http.RoundTripper)
 				continue
 			}
 			// 如果错误不是因为中间件不存在，返回错误。
-			return nil, err
+			return nil, closers, err
 		}
-		// 将当前中间件添加到中间件链中，处理下一个中间件的请求。
-		next = m.Process(next)
+		closers = append(closers, m)
+		// 将当前中间件添加到中间件链中，处理下一个中间件的请求，开启调试跟踪的请求记录中间件的结果。
+		next = traceMiddleware(ms[i].Name, m.Process(next))
 	}
 	// 返回构建好的中间件链和 nil 错误。
-	return next, nil
+	return next, closers, nil
+}
+
+// endpointCloser 结构体在关闭端点时同时关闭客户端和端点使用的所有中间件实例。
+type endpointCloser struct {
+	client io.Closer
+	// backends 是计划路由、备用后端和双写次要后端的客户端
+	backends    []io.Closer
+	middlewares []io.Closer
+}
+
+// Close 方法关闭客户端、计划路由、备用后端和双写次要后端的客户端以及所有中间件实例。
+func (c *endpointCloser) Close() error {
+	errs := []error{c.client.Close()}
+	for _, b := range c.backends {
+		errs = append(errs, b.Close())
+	}
+	for _, m := range c.middlewares {
+		errs = append(errs, m.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// endpointClient 函数返回端点关闭器中的客户端，用于获取节点等客户端信息。
+func endpointClient(c io.Closer) io.Closer {
+	if ec, ok := c.(*endpointCloser); ok {
+		return ec.client
+	}
+	return c
 }
 
 // splitRetryMetricsHandler 函数用于拆分重试指标处理程序
 func splitRetryMetricsHandler(e *config.Endpoint) (func(*http.Request, int), func(*http.Request, int, error)) {
 	// 根据端点配置创建指标标签
-	labels := middleware.NewMetricsLabels(e)
+	labels := newMetricsLabels(e)
 	// 定义成功重试处理函数
 	success := func(req *http.Request, i int) {
 		// 如果重试次数小于等于 0，则不进行任何操作
@@ -298,7 +476,7 @@ func splitRetryMetricsHandler(e *config.Endpoint) (func(*http.Request, int), fun
 	return success, failed
 }
 
-func (p *Proxy) buildEndpoint(buildCtx *client.BuildContext, e *config.Endpoint, ms []*config.Middleware) (_ http.Handler, _ io.Closer, retError error) {
+func (p *Proxy) buildEndpoint(buildCtx *client.BuildContext, e *config.Endpoint, ms []*config.Middleware, retryBreaker *retryBreaker) (_ http.Handler, _ io.Closer, retError error) {
 	// 使用客户端工厂创建一个新的客户端实例
 	client, err := p.clientFactory(buildCtx, e)
 	// 如果发生错误，返回 nil, nil, err
@@ -307,23 +485,56 @@ func (p *Proxy) buildEndpoint(buildCtx *client.BuildContext, e *config.Endpoint,
 	}
 	// 将客户端转换为 http.RoundTripper 接口类型
 	tripper := http.RoundTripper(client)
-	// 将客户端转换为 io.Closer 接口类型
-	closer := io.Closer(client)
+	// 端点关闭时需要同时关闭客户端和中间件实例
+	closer := &endpointCloser{client: client}
 	// 延迟调用 closeOnError 函数，确保在函数返回时关闭资源
 	defer closeOnError(closer, &retError)
+	// 端点配置了计划路由时，在计划的时间窗口内将请求发往计划路由的后端
+	if len(e.BackendSchedules) > 0 {
+		router, closers, err := newScheduledRouter(p.clientFactory, buildCtx, e, tripper)
+		if err != nil {
+			return nil, nil, err
+		}
+		closer.backends = append(closer.backends, closers...)
+		tripper = router
+	}
+	// 端点配置了错误预算时，在预算耗尽期间将部分请求转移到备用后端或拒绝
+	if e.ErrorBudget != nil {
+		fallback, err := newFallbackClient(p.clientFactory, buildCtx, e)
+		if err != nil {
+			return nil, nil, err
+		}
+		var fallbackTripper http.RoundTripper
+		if fallback != nil {
+			closer.backends = append(closer.backends, fallback)
+			fallbackTripper = fallback
+		}
+		tripper = newErrorBudget(e, tripper, fallbackTripper)
+	}
+	// 端点配置了双写时，将写请求同时发往次要后端
+	if e.DualWrite != nil {
+		dualWrite, closers, err := newDualWrite(p.clientFactory, buildCtx, e, tripper)
+		if err != nil {
+			return nil, nil, err
+		}
+		closer.backends = append(closer.backends, closers...)
+		tripper = dualWrite
+	}
 
 	// 使用中间件工厂构建中间件链
-	tripper, err = p.buildMiddleware(e.Middlewares, tripper)
+	tripper, closers, err := p.buildMiddleware(e.Middlewares, tripper)
 	// 如果发生错误，返回 nil, nil, err
 	if err != nil {
 		return nil, nil, err
 	}
+	closer.middlewares = append(closer.middlewares, closers...)
 	// 使用中间件工厂构建中间件链
-	tripper, err = p.buildMiddleware(ms, tripper)
+	tripper, closers, err = p.buildMiddleware(ms, tripper)
 	// 如果发生错误，返回 nil, nil, err
 	if err != nil {
 		return nil, nil, err
 	}
+	closer.middlewares = append(closer.middlewares, closers...)
 	// 准备重试策略
 	retryStrategy, err := prepareRetryStrategy(e)
 	// 如果发生错误，返回 nil, nil, err
@@ -331,11 +542,9 @@ func (p *Proxy) buildEndpoint(buildCtx *client.BuildContext, e *config.Endpoint,
 		return nil, nil, err
 	}
 	// 创建指标标签
-	labels := middleware.NewMetricsLabels(e)
+	labels := newMetricsLabels(e)
 	// 拆分重试指标处理程序
 	markSuccessStat, markFailedStat := splitRetryMetricsHandler(e)
-	// 创建重试断路器
-	retryBreaker := sre.NewBreaker(sre.WithSuccess(0.8))
 	// 定义标记成功的函数
 	markSuccess := func(req *http.Request, i int) {
 		// 标记成功状态
@@ -354,10 +563,25 @@ func (p *Proxy) buildEndpoint(buildCtx *client.BuildContext, e *config.Endpoint,
 			retryBreaker.MarkFailed()
 		}
 	}
+	// 端点生效的请求 URI 最大长度
+	urlLimit := endpointMaxURLLength(e)
+	// 端点的延迟目标
+	slo := newLatencySLO(e, labels)
 	// 返回一个 http.Handler 接口类型的函数
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// 拒绝超过端点限制的请求 URI
+		if !checkURLLength(w, req, urlLimit) {
+			return
+		}
 		// 记录请求开始时间
 		startTime := time.Now()
+		// 增加正在处理的请求数量，延迟减少以保证发生 panic 或请求取消时同样生效
+		inFlightAdd(req, labels, 1)
+		defer inFlightAdd(req, labels, -1)
+		// 向受信任的来源暴露匹配到的路由模板
+		exposeRouteTemplate(w, req, e)
+		// 受信任的来源可以通过请求头开启单个请求的调试跟踪
+		trace := newDebugTrace(req)
 		// 设置 X-Forwarded-For 头部
 		setXFFHeader(req)
 
@@ -369,27 +593,40 @@ func (p *Proxy) buildEndpoint(buildCtx *client.BuildContext, e *config.Endpoint,
 		ctx, cancel := context.WithTimeout(ctx, retryStrategy.timeout)
 		// 延迟调用 cancel 函数，确保在函数结束时取消上下文
 		defer cancel()
+		if trace != nil {
+			ctx = withDebugTrace(ctx, trace)
+			trace.record("endpoint %s %s", e.Method, e.Path)
+		}
 		// 延迟调用函数，记录请求持续时间
 		defer func() {
 			// 观察请求持续时间指标
-			requestsDurationObserve(req, labels, time.Since(startTime).Seconds())
+			elapsed := time.Since(startTime)
+			requestsDurationObserve(req, labels, elapsed.Seconds())
+			// 记录请求是否满足延迟目标
+			slo.observe(req, labels, elapsed)
 		}()
 
-		// 读取请求体
-		body, err := io.ReadAll(req.Body)
+		// 端点配置了由 GET 合成 HEAD 时，以 GET 方法请求上游并丢弃响应体
+		headAsGet := e.HeadAsGet && req.Method == http.MethodHead
+		// 如果端点配置了丢弃请求体，并且请求方法不应该携带请求体，则在转发前丢弃请求体
+		if e.DropRequestBody && !methodAllowsBody(req.Method) {
+			dropRequestBody(req)
+		}
+		// 读取请求体，端点配置了请求缓冲时较大的请求体缓存到临时文件
+		body, err := middleware.BufferRequestBody(req.Body, e.RequestBuffering)
+		// 延迟删除缓存请求体的临时文件
+		defer body.Close()
 		// 如果发生错误，写入错误信息并返回
 		if err != nil {
-			writeError(w, req, err, labels)
+			bodyErrorsIncr(req, labels, _bodyErrorRequestRead)
+			writeError(w, req.WithContext(ctx), err, labels)
 			return
 		}
 		// 增加接收到的字节数指标
-		receivedBytesAdd(req, labels, int64(len(body)))
+		receivedBytesAdd(req, labels, body.Len())
 		// 设置请求体的读取函数
 		req.GetBody = func() (io.ReadCloser, error) {
-			// 创建一个新的字节读取器
-			reader := bytes.NewReader(body)
-			// 返回一个 io.NopCloser 包装的读取器
-			return io.NopCloser(reader), nil
+			return body.NewReader(), nil
 		}
 
 		// 初始化响应对象
@@ -407,6 +644,14 @@ func (p *Proxy) buildEndpoint(buildCtx *client.BuildContext, e *config.Endpoint,
 					markFailed(req, i, err)
 					break
 				}
+				// 按退避策略等待，等待会超过总超时时间时放弃重试，返回上一次尝试的结果
+				delay, ok := retryStrategy.backoff.wait(ctx, i)
+				if trace != nil && delay > 0 {
+					trace.record("retry %d backoff %s, waited: %v", i, delay, ok)
+				}
+				if !ok {
+					break
+				}
 			}
 
 			// 如果是最后一次尝试
@@ -418,19 +663,39 @@ func (p *Proxy) buildEndpoint(buildCtx *client.BuildContext, e *config.Endpoint,
 				markFailed(req, i, err)
 				break
 			}
+			// 上一次尝试的响应将被丢弃，完成该次选择并关闭响应体，使节点的在途请求得以释放
+			if resp != nil {
+				reqOpts.DoneFunc(ctx, selector.DoneInfo{Err: errors.New("assertion failed")})
+				if resp.Body != nil {
+					resp.Body.Close()
+				}
+				resp = nil
+			}
 			// 准备尝试超时上下文
 			tryCtx, cancel := p.Interceptors.prepareAttemptTimeoutContext(ctx, req, retryStrategy.perTryTimeout)
 			// 延迟调用 cancel 函数，确保在函数结束时取消上下文
 			defer cancel()
-			// 创建一个新的字节读取器
-			reader := bytes.NewReader(body)
-			// 将请求体设置为新的读取器
-			req.Body = io.NopCloser(reader)
-			// 发送请求并获取响应
-			resp, err = tripper.RoundTrip(req.Clone(tryCtx))
+			// 每次尝试都从头读取请求体
+			req.Body = body.NewReader()
+			// 发送请求并获取响应，由 GET 合成的 HEAD 请求以 GET 方法发往上游
+			upstreamReq := req.Clone(tryCtx)
+			if headAsGet {
+				upstreamReq.Method = http.MethodGet
+			}
+			attemptStart := time.Now()
+			resp, err = tripper.RoundTrip(upstreamReq)
+			if trace != nil {
+				traceAttempt(trace, reqOpts, i, time.Since(attemptStart), resp, err)
+			}
 			// 如果发生错误，标记失败并记录日志
 			if err != nil {
 				markFailed(req, i, err)
+				upstreamErrorsIncr(req, labels, err)
+				// 客户端已经取消请求时上游请求随之取消，不再重试
+				if clientCanceled(req) {
+					err = req.Context().Err()
+					break
+				}
 				log.Errorf("Attempt at [%d/%d], failed to handle request: %s: %+v", i+1, retryStrategy.attempts, req.URL.String(), err)
 				continue
 			}
@@ -445,19 +710,50 @@ func (p *Proxy) buildEndpoint(buildCtx *client.BuildContext, e *config.Endpoint,
 			markFailed(req, i, errors.New("assertion failed"))
 			// 继续重试循环
 		}
-		// 如果发生错误，写入错误信息并返回
+		// 限制返回给客户端的响应体大小
+		if err == nil {
+			var oversized bool
+			if oversized, err = limitResponse(e.ResponseLimit, resp); oversized {
+				bodyErrorsIncr(req, labels, _bodyErrorResponseTooLarge)
+			}
+			if err != nil {
+				reqOpts.DoneFunc(ctx, selector.DoneInfo{Err: err})
+				resp.Body.Close()
+			}
+		}
+		// 缓冲较小的响应以设置 Content-Length
+		if err == nil && !headAsGet {
+			if err = bufferResponse(e.ResponseBuffering, resp); err != nil {
+				bodyErrorsIncr(req, labels, _bodyErrorResponseCopy)
+				reqOpts.DoneFunc(ctx, selector.DoneInfo{Err: err})
+				resp.Body.Close()
+			}
+		}
+		// 如果发生错误，写入错误信息并返回，携带请求上下文以便在错误详情中附带请求 ID
 		if err != nil {
-			writeError(w, req, err, labels)
+			trace.writeHeader(w, true)
+			writeError(w, req.WithContext(ctx), err, labels)
 			return
 		}
 
+		// 上游响应没有 Content-Type 时按端点配置检测并设置内容类型
+		if e.SniffContentType && !headAsGet {
+			sniffContentType(resp)
+		}
 		// 将响应头复制到响应写入器
 		headers := w.Header()
 		for k, v := range resp.Header {
 			headers[k] = v
 		}
+		// 开启调试跟踪时返回到目前为止的调试跟踪
+		trace.writeHeader(w, false)
 		// 设置响应状态码
 		w.WriteHeader(resp.StatusCode)
+		// 流式响应立即刷新响应头，使客户端不必等待第一条消息
+		streaming := isStreamingResponse(resp)
+		if streaming {
+			_ = http.NewResponseController(w).Flush()
+		}
 
 		// 定义一个函数，用于复制响应体
 		doCopyBody := func() bool {
@@ -467,10 +763,35 @@ func (p *Proxy) buildEndpoint(buildCtx *client.BuildContext, e *config.Endpoint,
 			}
 			// 延迟关闭响应体
 			defer resp.Body.Close()
-			// 复制响应体到响应写入器
-			sent, err := io.Copy(w, resp.Body)
+			// 由 GET 合成的 HEAD 请求只返回响应头
+			if headAsGet {
+				reqOpts.DoneFunc(ctx, selector.DoneInfo{ReplyMD: getReplyMD(e, resp)})
+				return true
+			}
+			// 复制响应体到响应写入器，记录读取响应体时的错误以区分上游和客户端的失败
+			body := &readErrorRecorder{Reader: &contextReader{ctx: ctx, Reader: resp.Body}}
+			// 到达请求的截止时间时关闭上游响应体，使阻塞中的读取立即返回，而不是等待客户端断开连接
+			stop := context.AfterFunc(ctx, func() { _ = resp.Body.Close() })
+			defer stop()
+			// 流式响应在每次写入后立即刷新，使客户端能够及时收到每条消息
+			dst := io.Writer(w)
+			if streaming {
+				dst = &flushWriter{ResponseWriter: w, controller: http.NewResponseController(w)}
+			}
+			sent, err := io.Copy(dst, body)
 			// 如果发生错误，记录错误信息并增加发送字节数指标
 			if err != nil {
+				if clientCanceled(req) {
+					// 客户端断开连接时请求上下文随之取消，需要在截止时间之前判断
+					bodyErrorsIncr(req, labels, _bodyErrorClientDisconnect)
+					clientCanceledIncr(req, labels, _canceledStageResponse)
+				} else if ctx.Err() != nil {
+					bodyErrorsIncr(req, labels, _bodyErrorDeadline)
+				} else if body.err != nil {
+					bodyErrorsIncr(req, labels, _bodyErrorResponseCopy)
+				} else {
+					bodyErrorsIncr(req, labels, _bodyErrorClientDisconnect)
+				}
 				reqOpts.DoneFunc(ctx, selector.DoneInfo{Err: err})
 				sentBytesAdd(req, labels, sent)
 				log.Errorf("Failed to copy backend response body to client: [%s] %s %s %d %+v\n", e.Protocol, e.Method, e.Path, sent, err)
@@ -487,12 +808,97 @@ func (p *Proxy) buildEndpoint(buildCtx *client.BuildContext, e *config.Endpoint,
 			return true
 		}
 		// 调用复制响应体的函数
-		doCopyBody()
+		if doCopyBody() {
+			trace.record("response body copied")
+		} else {
+			trace.record("response body copy failed")
+		}
+		trace.writeTrailer(w)
 		// 增加请求总数指标
 		requestsTotalIncr(req, labels, resp.StatusCode)
 	}), closer, nil
 }
 
+// readErrorRecorder 结构体记录读取过程中发生的错误。
+type readErrorRecorder struct {
+	io.Reader
+	err error
+}
+
+// Read 方法读取数据并记录除 io.EOF 之外的错误。
+func (r *readErrorRecorder) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
+
+// contextReader 结构体在每次读取之前检查上下文，上下文结束后不再读取。
+type contextReader struct {
+	ctx context.Context
+	io.Reader
+}
+
+// Read 方法在上下文结束时返回上下文的错误，否则读取数据。
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.Reader.Read(p)
+}
+
+// isStreamingResponse 判断响应是否是需要逐条刷新的流式响应。
+func isStreamingResponse(resp *http.Response) bool {
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/x-ndjson", "application/json-seq", "text/event-stream":
+		return true
+	default:
+		// gRPC-Web 的服务端流包括 application/grpc-web、application/grpc-web+proto 和 application/grpc-web-text 等
+		return strings.HasPrefix(mediaType, "application/grpc-web")
+	}
+}
+
+// flushWriter 结构体在每次写入后刷新响应。
+type flushWriter struct {
+	http.ResponseWriter
+	controller *http.ResponseController
+}
+
+// Write 方法写入数据并立即刷新，响应不支持刷新时忽略错误。
+func (w *flushWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	if err != nil {
+		return n, err
+	}
+	_ = w.controller.Flush()
+	return n, nil
+}
+
+// methodAllowsBody 判断请求方法是否允许携带请求体。
+func methodAllowsBody(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		return false
+	default:
+		return true
+	}
+}
+
+// dropRequestBody 丢弃请求体，并清理与请求体相关的头部。
+func dropRequestBody(req *http.Request) {
+	// 关闭原始请求体
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	req.Body = http.NoBody
+	req.ContentLength = 0
+	req.TransferEncoding = nil
+	req.Header.Del("Content-Length")
+	req.Header.Del("Transfer-Encoding")
+}
+
 // getReplyMD 根据协议类型获取响应的元数据。
 func getReplyMD(ep *config.Endpoint, resp *http.Response) selector.ReplyMD {
 	// 如果协议是 gRPC，则返回响应的 Trailer
@@ -503,32 +909,115 @@ func getReplyMD(ep *config.Endpoint, resp *http.Response) selector.ReplyMD {
 	return resp.Header
 }
 
+// metricsLabels 结构体在指标标签的基础上记录了端点禁用的指标
+type metricsLabels struct {
+	middleware.MetricsLabels
+	// disableRequestsTotal 表示是否禁用请求总数指标
+	disableRequestsTotal bool
+	// disableRequestsDuration 表示是否禁用请求持续时间指标
+	disableRequestsDuration bool
+	// disableBytes 表示是否禁用收发字节数指标
+	disableBytes bool
+	// disableRetryState 表示是否禁用重试状态指标
+	disableRetryState bool
+	// disableBodyErrors 表示是否禁用读写请求体和响应体失败的指标
+	disableBodyErrors bool
+	// disableInFlight 表示是否禁用正在处理的请求数量指标
+	disableInFlight bool
+	// disableUpstreamErrors 表示是否禁用请求上游失败的分类指标
+	disableUpstreamErrors bool
+	// disableClientCanceled 表示是否禁用客户端取消请求的指标
+	disableClientCanceled bool
+}
+
+// newMetricsLabels 根据端点配置创建指标标签。
+func newMetricsLabels(e *config.Endpoint) *metricsLabels {
+	labels := &metricsLabels{MetricsLabels: middleware.NewMetricsLabels(e)}
+	m := e.Metrics
+	if m == nil {
+		return labels
+	}
+	labels.disableRequestsTotal = m.DisableAll || m.DisableRequestsTotal
+	labels.disableRequestsDuration = m.DisableAll || m.DisableRequestsDuration
+	labels.disableBytes = m.DisableAll || m.DisableBytes
+	labels.disableRetryState = m.DisableAll || m.DisableRetryState
+	labels.disableBodyErrors = m.DisableAll
+	labels.disableInFlight = m.DisableAll
+	labels.disableUpstreamErrors = m.DisableAll || m.DisableUpstreamErrors
+	labels.disableClientCanceled = m.DisableAll
+	return labels
+}
+
 // receivedBytesAdd 增加接收到的字节数指标。
-func receivedBytesAdd(req *http.Request, labels middleware.MetricsLabels, received int64) {
+func receivedBytesAdd(req *http.Request, labels *metricsLabels, received int64) {
+	if labels.disableBytes {
+		return
+	}
 	// 使用标签值更新接收到的字节数指标
 	_metricReceivedBytes.WithLabelValues(labels.Protocol(), req.Method, labels.Path(), labels.Service(), labels.BasePath()).Add(float64(received))
 }
 
 // sentBytesAdd 增加发送的字节数指标。
-func sentBytesAdd(req *http.Request, labels middleware.MetricsLabels, sent int64) {
+func sentBytesAdd(req *http.Request, labels *metricsLabels, sent int64) {
+	if labels.disableBytes {
+		return
+	}
 	// 使用标签值更新发送的字节数指标
 	_metricSentBytes.WithLabelValues(labels.Protocol(), req.Method, labels.Path(), labels.Service(), labels.BasePath()).Add(float64(sent))
 }
 
 // requestsTotalIncr 增加请求总数指标。
-func requestsTotalIncr(req *http.Request, labels middleware.MetricsLabels, statusCode int) {
+func requestsTotalIncr(req *http.Request, labels *metricsLabels, statusCode int) {
+	if labels.disableRequestsTotal {
+		return
+	}
 	// 使用标签值更新请求总数指标
 	_metricRequestsTotal.WithLabelValues(labels.Protocol(), req.Method, labels.Path(), strconv.Itoa(statusCode), labels.Service(), labels.BasePath()).Inc()
 }
 
 // requestsDurationObserve 观察请求持续时间指标。
-func requestsDurationObserve(req *http.Request, labels middleware.MetricsLabels, seconds float64) {
+func requestsDurationObserve(req *http.Request, labels *metricsLabels, seconds float64) {
+	if labels.disableRequestsDuration {
+		return
+	}
 	// 使用标签值更新请求持续时间指标
 	_metricRequestsDuration.WithLabelValues(labels.Protocol(), req.Method, labels.Path(), labels.Service(), labels.BasePath()).Observe(seconds)
 }
 
+// bodyErrorsIncr 增加读写请求体和响应体失败的指标。
+func bodyErrorsIncr(req *http.Request, labels *metricsLabels, typ string) {
+	if labels.disableBodyErrors {
+		return
+	}
+	_metricBodyErrors.WithLabelValues(labels.Protocol(), req.Method, labels.Path(), labels.Service(), labels.BasePath(), typ).Inc()
+}
+
+// clientCanceled 判断客户端是否已经取消了请求或断开了连接。
+func clientCanceled(req *http.Request) bool {
+	return errors.Is(req.Context().Err(), context.Canceled)
+}
+
+// clientCanceledIncr 按阶段增加客户端取消请求的指标。
+func clientCanceledIncr(req *http.Request, labels *metricsLabels, stage string) {
+	if labels.disableClientCanceled {
+		return
+	}
+	_metricClientCanceled.WithLabelValues(labels.Protocol(), req.Method, labels.Path(), labels.Service(), labels.BasePath(), stage).Inc()
+}
+
+// inFlightAdd 增加或减少正在处理的请求数量指标。
+func inFlightAdd(req *http.Request, labels *metricsLabels, delta float64) {
+	if labels.disableInFlight {
+		return
+	}
+	_metricRequestsInFlight.WithLabelValues(labels.Protocol(), req.Method, labels.Path(), labels.Service(), labels.BasePath()).Add(delta)
+}
+
 // retryStateIncr 增加重试状态指标。
-func retryStateIncr(req *http.Request, labels middleware.MetricsLabels, success bool) {
+func retryStateIncr(req *http.Request, labels *metricsLabels, success bool) {
+	if labels.disableRetryState {
+		return
+	}
 	// 如果重试成功，则增加成功重试的指标
 	if success {
 		_metricRetryState.WithLabelValues(labels.Protocol(), req.Method, labels.Path(), labels.Service(), labels.BasePath(), "true").Inc()
@@ -550,13 +1039,31 @@ func closeOnError(closer io.Closer, err *error) {
 
 // Update 更新服务端点。
 func (p *Proxy) Update(buildContext *client.BuildContext, c *config.Gateway) (retError error) {
-	// 创建一个新的路由器，使用 notFoundHandler 和 methodNotAllowedHandler 作为默认处理器
-	router := mux.NewRouter(http.HandlerFunc(notFoundHandler), http.HandlerFunc(methodNotAllowedHandler))
+	// 记录配置更新的审计事件
+	defer func() { auditConfigUpdate(c, retError) }()
+	// 创建一个新的路由器
+	router := p.newRouter()
+	// 租户的端点排在共享的端点之前
+	endpoints := routeEndpoints(c)
+	// 记录所有端点的客户端，用于就绪探针统计节点数量
+	clients := make([]io.Closer, 0, len(endpoints))
+	// 记录所有端点注册路由的处理程序，用于注册另一种斜杠形式的路由，流量分配中的其他端点为 nil
+	handlers := make([]http.Handler, 0, len(endpoints))
+	// 记录所有端点的重试熔断器，用于管理接口查询和重置
+	breakers := make([]*retryBreaker, 0, len(endpoints)+1)
+	// 路由相同且带有权重的端点按权重分配流量
+	splits := make(map[splitKey]*trafficSplit)
 
 	// 遍历配置中的所有端点
-	for _, e := range c.Endpoints {
+	for _, te := range endpoints {
+		e := te.endpoint
+		// 就绪探针先于端点注册，与之重叠的端点的 GET 请求不会到达端点
+		if path := shadowedPath(e); path != "" {
+			log.Warnf("endpoint %s %s overlaps the readiness probe, GET %s is not routed to it", e.Method, e.Path, path)
+		}
+		breaker := newRetryBreaker(te.tenant, e)
 		// 为每个端点构建处理程序和关闭器
-		handler, closer, err := p.buildEndpoint(buildContext, e, c.Middlewares)
+		handler, closer, err := p.buildEndpoint(buildContext, e, te.middlewares(c), breaker)
 		// 如果发生错误，返回错误
 		if err != nil {
 			return err
@@ -564,17 +1071,89 @@ func (p *Proxy) Update(buildContext *client.BuildContext, c *config.Gateway) (re
 		// 延迟调用 closeOnError 函数，确保在函数返回时关闭资源
 		defer closeOnError(closer, &retError)
 
-		// 将处理程序注册到路由器中
-		if err = router.Handle(e.Path, e.Method, e.Host, handler, closer); err != nil {
-			// 如果注册过程中发生错误，返回错误
+		routeHandler, routeCloser := handler, closer
+		weight, weighted, err := endpointWeight(e)
+		if err != nil {
 			return err
 		}
+		if weighted {
+			key := splitKey{tenant: te.tenant, virtualHost: te.virtualHost, host: e.Host, method: e.Method, path: e.Path}
+			split, registered := splits[key]
+			if !registered {
+				split = newTrafficSplit()
+				splits[key] = split
+			}
+			// 端点的关闭器随流量分配一起关闭，重新加载配置时不会遗漏
+			split.add(handler, weight, closer)
+			routeHandler, routeCloser = split, split
+			// 流量分配只在第一个端点的位置注册路由
+			if registered {
+				routeHandler = nil
+			}
+		}
+		// 将处理程序注册到路由器中
+		if routeHandler != nil {
+			if err = router.Handle(e.Path, e.Method, e.Host, routeHandler, routeCloser, te.handleOptions()...); err != nil {
+				// 如果注册过程中发生错误，返回错误
+				return err
+			}
+		}
+		clients = append(clients, closer)
+		handlers = append(handlers, routeHandler)
+		breakers = append(breakers, breaker)
 		// 记录日志，表示成功构建了端点
+		if te.tenant != "" {
+			log.Infof("build endpoint: [%s] %s %s tenant: %s", e.Protocol, e.Method, e.Path, te.tenant)
+			continue
+		}
+		if te.virtualHost != nil {
+			log.Infof("build endpoint: [%s] %s %s virtual host: %s", e.Protocol, e.Method, e.Path, virtualHostPrefix(te.virtualHost))
+			continue
+		}
 		log.Infof("build endpoint: [%s] %s %s", e.Protocol, e.Method, e.Path)
 	}
+	// 在所有端点注册之后，根据尾部斜杠处理策略注册另一种斜杠形式的路由
+	for i, te := range endpoints {
+		// 流量分配中的其他端点没有单独的路由
+		if handlers[i] == nil {
+			continue
+		}
+		if err := handleTrailingSlash(router, te.endpoint, handlers[i], te.handleOptions()); err != nil {
+			return err
+		}
+	}
+	// 最后注册默认端点，以最低的优先级处理所有未匹配的请求
+	if e := c.DefaultEndpoint; e != nil {
+		breaker := newRetryBreaker("", e)
+		handler, closer, err := p.buildEndpoint(buildContext, e, c.Middlewares, breaker)
+		if err != nil {
+			return err
+		}
+		defer closeOnError(closer, &retError)
+		if err = router.Handle(_catchAllPattern, "", "", handler, closer); err != nil {
+			return err
+		}
+		clients = append(clients, closer)
+		breakers = append(breakers, breaker)
+		log.Infof("build default endpoint: [%s] %s", e.Protocol, _catchAllPattern)
+	}
 
 	// 替换旧的路由器
 	old := p.router.Swap(router)
+	// 更新请求方法覆盖的配置
+	p.methodOverride.Store(newMethodOverride(c.MethodOverride))
+	// 更新解析请求所属租户的配置
+	p.tenantResolver.Store(newTenantResolver(c.TenantRouting))
+	// 更新在路由之前检查的请求 URI 最大长度
+	p.maxURLLength.Store(urlLengthCeiling(c))
+	// 更新重试熔断器，重新加载配置后熔断器的统计从零开始
+	p.retryBreakers.Store(&breakers)
+	// 更新是否由网关直接响应 OPTIONS 请求
+	p.autoOptions.Store(c.AutoOptions)
+	// 更新就绪信息，并增加配置代数
+	state := newReadinessState(c, clients, len(endpoints))
+	state.info.Generation = p.generations.advance(state.info.ConfigHash, c.Name, c.Version)
+	p.readiness.Store(state)
 	// 尝试关闭旧的路由器
 	tryCloseRouter(old)
 
@@ -582,6 +1161,54 @@ func (p *Proxy) Update(buildContext *client.BuildContext, c *config.Gateway) (re
 	return nil
 }
 
+// endpointHandleOptions 根据端点配置返回注册路由时的附加匹配条件。
+func endpointHandleOptions(e *config.Endpoint) []router.HandleOption {
+	var opts []router.HandleOption
+	if len(e.Headers) > 0 {
+		headers := make([]router.HeaderMatcher, 0, len(e.Headers))
+		for _, h := range e.Headers {
+			headers = append(headers, router.HeaderMatcher{Name: h.Name, Value: h.Value, Regex: h.Regex})
+		}
+		opts = append(opts, router.WithHeaders(headers...))
+	}
+	if len(e.Queries) > 0 {
+		queries := make([]router.QueryMatcher, 0, len(e.Queries))
+		for _, q := range e.Queries {
+			queries = append(queries, router.QueryMatcher{Name: q.Name, Value: q.Value, Regex: q.Regex})
+		}
+		opts = append(opts, router.WithQueries(queries...))
+	}
+	if e.HeadAsGet && e.Method == http.MethodGet {
+		opts = append(opts, router.WithMethods(http.MethodHead))
+	}
+	if len(e.AllowedMethods) > 0 {
+		opts = append(opts, router.WithMethods(e.AllowedMethods...))
+	}
+	if e.MethodNotAllowed != nil {
+		opts = append(opts, router.WithMethodNotAllowed(newMethodNotAllowedHandler(e.MethodNotAllowed)))
+	}
+	return opts
+}
+
+// auditConfigUpdate 记录配置更新的审计事件。
+func auditConfigUpdate(c *config.Gateway, err error) {
+	e := &audit.Event{
+		Type:     audit.TypeConfig,
+		Action:   "update",
+		Resource: c.Name,
+		Result:   audit.ResultSuccess,
+		Metadata: map[string]string{
+			"version": c.Version,
+			"hash":    configHash(c),
+		},
+	}
+	if err != nil {
+		e.Result = audit.ResultFailure
+		e.Reason = err.Error()
+	}
+	audit.Emit(e)
+}
+
 // tryCloseRouter 尝试关闭传入的路由器。
 func tryCloseRouter(in interface{}) {
 	// 如果传入的对象为 nil，则直接返回
@@ -623,8 +1250,25 @@ func (p *Proxy) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 			fmt.Fprintf(os.Stderr, "panic recovered: %+v\n%s\n", err, buf[:n])
 		}
 	}()
+	// 在路由之前拒绝过长的请求 URI，端点可以配置更严格的限制
+	if !checkURLLength(w, req, p.maxURLLength.Load()) {
+		return
+	}
+	// 在路由之前覆盖请求方法，使请求路由到覆盖后的方法对应的端点
+	if mo := p.methodOverride.Load(); mo != nil && !mo.apply(w, req) {
+		return
+	}
+	// 在路由之前解析请求所属的租户，使租户的路由优先匹配
+	if tr := p.tenantResolver.Load(); tr != nil {
+		req = tr.apply(req)
+	}
 	// 加载当前的路由器，并将其转换为 router.Router 接口类型
-	p.router.Load().(router.Router).ServeHTTP(w, req)
+	r := p.router.Load().(router.Router)
+	// 由网关直接响应 OPTIONS 请求，列出路径允许的请求方法
+	if p.autoOptions.Load() && serveAutoOptions(w, req, r) {
+		return
+	}
+	r.ServeHTTP(w, req)
 }
 
 // DebugHandler 实现了一个调试处理器。
@@ -646,6 +1290,12 @@ func (p *Proxy) DebugHandler() http.Handler {
 		// 将检查信息编码为 JSON 并写入响应
 		json.NewEncoder(rw).Encode(inspect)
 	})
+	// 注册一个处理函数，用于查询或等待当前生效的配置代数
+	debugMux.HandleFunc("/debug/proxy/config/generation", p.generationHandler)
+	// 注册一个处理函数，用于查询或重置端点的重试熔断器
+	debugMux.HandleFunc("/debug/proxy/retry/breakers", p.retryBreakersHandler)
+	// 注册一个处理函数，用于查询网关的构建信息
+	debugMux.HandleFunc("/debug/proxy/version", versionHandler)
 	// 返回调试处理器
 	return debugMux
 }
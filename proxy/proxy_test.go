@@ -1,18 +1,38 @@
 package proxy
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/audit"
 	"github.com/cnsync/gateway/client"
 	"github.com/cnsync/gateway/middleware"
 	"github.com/cnsync/gateway/middleware/logging"
+	"github.com/cnsync/gateway/trust"
+	"github.com/cnsync/kratos/registry"
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	spb "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 type responseWriter struct {
@@ -47,6 +67,24 @@ func (f RoundTripperCloserFunc) Close() error {
 	return nil
 }
 
+// newTestProxy 使用 logging 中间件创建 Proxy，c 不为空时加载该配置
+func newTestProxy(t *testing.T, clientFactory client.Factory, c *config.Gateway) *Proxy {
+	t.Helper()
+	middlewareFactory := func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return logging.Middleware(c)
+	}
+	p, err := New(clientFactory, middlewareFactory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c != nil {
+		if err := p.Update(client.NewBuildContext(c), c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return p
+}
+
 func TestProxy(t *testing.T) {
 	c := &config.Gateway{
 		Name: "Test",
@@ -89,15 +127,7 @@ func TestProxy(t *testing.T) {
 		})
 		return dummyClient, nil
 	}
-	middlewareFactory := func(c *config.Middleware) (middleware.MiddlewareV2, error) {
-		return logging.Middleware(c)
-	}
-	p, err := New(clientFactory, middlewareFactory)
-	if err != nil {
-		t.Fatal(err)
-	}
-	buildContext := client.NewBuildContext(c)
-	p.Update(buildContext, c)
+	p := newTestProxy(t, clientFactory, c)
 	{
 		b := []byte("notfound")
 		r := httptest.NewRequest("GET", "/notfound", bytes.NewBuffer(b))
@@ -184,15 +214,7 @@ func TestRetryBreaker(t *testing.T) {
 		})
 		return dummyClient, nil
 	}
-	middlewareFactory := func(c *config.Middleware) (middleware.MiddlewareV2, error) {
-		return logging.Middleware(c)
-	}
-	p, err := New(clientFactory, middlewareFactory)
-	if err != nil {
-		t.Fatal(err)
-	}
-	buildContext := client.NewBuildContext(c)
-	p.Update(buildContext, c)
+	p := newTestProxy(t, clientFactory, c)
 
 	t.Run("retry-breaker", func(t *testing.T) {
 		var lastResponse *responseWriter
@@ -230,3 +252,2543 @@ func TestRetryBreaker(t *testing.T) {
 	})
 
 }
+
+func TestRetryBreakerReset(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/retryable",
+			Method:   "GET",
+			Retry: &config.Retry{
+				Attempts: 2,
+				Conditions: []*config.Condition{{
+					Condition: &config.Condition_ByStatusCode{
+						ByStatusCode: "500-599",
+					},
+				}},
+				Breaker: &config.RetryBreaker{
+					Success: 0.5,
+					Request: 10,
+					Window:  durationpb.New(time.Minute),
+				},
+			},
+		}},
+	}
+	var upstream atomic.Int64
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			upstream.Add(1)
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}, Body: http.NoBody}, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+	send := func(n int) {
+		for i := 0; i < n; i++ {
+			p.ServeHTTP(newResponseWriter(), httptest.NewRequest("GET", "/retryable", nil))
+		}
+	}
+	breakers := func(method string) []RetryBreakerStatus {
+		t.Helper()
+		w := httptest.NewRecorder()
+		p.DebugHandler().ServeHTTP(w, httptest.NewRequest(method, "/debug/proxy/retry/breakers?path=/retryable", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("want 200 but got %d %s", w.Code, w.Body.String())
+		}
+		var status []RetryBreakerStatus
+		if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+			t.Fatal(err)
+		}
+		if len(status) != 1 {
+			t.Fatalf("want 1 retry breaker but got %+v", status)
+		}
+		return status
+	}
+
+	// 配置的最少重试次数之后，全部失败的重试几乎都被丢弃
+	send(200)
+	status := breakers("GET")[0]
+	if status.Success != 0.5 || status.Request != 10 || status.Window != "1m0s" {
+		t.Fatalf("want the configured breaker but got %+v", status)
+	}
+	if status.Allowed < 10 || status.Rejected < 150 {
+		t.Fatalf("want retries shed after 10 failures but got %+v", status)
+	}
+	if got := upstream.Load(); got != 200+status.Allowed {
+		t.Fatalf("want %d upstream requests but got %d", 200+status.Allowed, got)
+	}
+
+	// 重置之后统计清零，重试恢复
+	status = breakers("POST")[0]
+	if status.Allowed != 0 || status.Rejected != 0 || status.Failed != 0 {
+		t.Fatalf("want the statistics cleared but got %+v", status)
+	}
+	upstream.Store(0)
+	send(5)
+	if got := upstream.Load(); got != 10 {
+		t.Fatalf("want every request retried after the reset but got %d upstream requests", got)
+	}
+
+	w := httptest.NewRecorder()
+	p.DebugHandler().ServeHTTP(w, httptest.NewRequest("POST", "/debug/proxy/retry/breakers?path=/unknown", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("want 404 for an unknown endpoint but got %d", w.Code)
+	}
+}
+
+// enableReadiness 在测试期间以 /readyz 注册就绪探针
+func enableReadiness(t *testing.T) {
+	old := readinessPath
+	readinessPath = "/readyz"
+	t.Cleanup(func() { readinessPath = old })
+}
+
+func TestReadinessConfigHash(t *testing.T) {
+	enableReadiness(t)
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK}, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, nil)
+	readiness := func() ReadinessInfo {
+		r := httptest.NewRequest("GET", "/readyz", nil)
+		r.RemoteAddr = "127.0.0.1:1234"
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Fatalf("want ok but got: %d", w.Code)
+		}
+		var info ReadinessInfo
+		if err := json.NewDecoder(w.Body).Decode(&info); err != nil {
+			t.Fatal(err)
+		}
+		return info
+	}
+
+	c := &config.Gateway{
+		Name:    "Test",
+		Version: "v1",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/foo/bar",
+			Method:   "GET",
+		}},
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	first := readiness()
+	if first.ConfigHash == "" || first.ConfigHash != configHash(c) {
+		t.Fatalf("want config hash %s but got %s", configHash(c), first.ConfigHash)
+	}
+	if first.ConfigVersion != "v1" || first.Endpoints != 1 {
+		t.Fatalf("unexpected readiness info: %+v", first)
+	}
+
+	// reload with the same config keeps the hash
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	if same := readiness(); same.ConfigHash != first.ConfigHash {
+		t.Fatalf("want config hash %s but got %s", first.ConfigHash, same.ConfigHash)
+	}
+
+	c2 := proto.Clone(c).(*config.Gateway)
+	c2.Version = "v2"
+	c2.Endpoints = append(c2.Endpoints, &config.Endpoint{
+		Protocol: config.Protocol_HTTP,
+		Path:     "/foo/baz",
+		Method:   "GET",
+	})
+	if err := p.Update(client.NewBuildContext(c2), c2); err != nil {
+		t.Fatal(err)
+	}
+	second := readiness()
+	if second.ConfigHash == first.ConfigHash {
+		t.Fatalf("want config hash changed after reload but got %s", second.ConfigHash)
+	}
+	if second.ConfigVersion != "v2" || second.Endpoints != 2 {
+		t.Fatalf("unexpected readiness info: %+v", second)
+	}
+
+	// 不受信任的来源只得到状态码，没有构建和配置信息
+	untrusted := httptest.NewRecorder()
+	p.ServeHTTP(untrusted, httptest.NewRequest("GET", "/readyz", nil))
+	if untrusted.Code != http.StatusOK || untrusted.Body.Len() != 0 {
+		t.Fatalf("want bare 200 for untrusted source but got %d: %s", untrusted.Code, untrusted.Body.String())
+	}
+
+	// 开始排空连接之后就绪探针返回 503，请求仍然正常处理
+	if err := p.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest("GET", "/readyz", nil)
+	r.RemoteAddr = "127.0.0.1:1234"
+	p.ServeHTTP(w, r)
+	var draining ReadinessInfo
+	if err := json.NewDecoder(w.Body).Decode(&draining); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusServiceUnavailable || draining.Ready || !draining.Draining {
+		t.Fatalf("want unready while draining but got %d: %+v", w.Code, draining)
+	}
+}
+
+type fakeWatcher struct {
+	ctx  context.Context
+	next chan []*registry.ServiceInstance
+}
+
+func (w *fakeWatcher) Next() ([]*registry.ServiceInstance, error) {
+	select {
+	case services := <-w.next:
+		return services, nil
+	case <-w.ctx.Done():
+		return nil, w.ctx.Err()
+	}
+}
+
+func (w *fakeWatcher) Stop() error { return nil }
+
+type fakeDiscovery struct {
+	watcher *fakeWatcher
+}
+
+func (d *fakeDiscovery) GetService(ctx context.Context, name string) ([]*registry.ServiceInstance, error) {
+	return nil, nil
+}
+
+func (d *fakeDiscovery) Watch(ctx context.Context, name string) (registry.Watcher, error) {
+	return d.watcher, nil
+}
+
+func TestShadowedPath(t *testing.T) {
+	enableReadiness(t)
+	tests := []struct {
+		path   string
+		method string
+		want   string
+	}{
+		{"/readyz", "GET", "/readyz"},
+		{"/readyz", "POST", ""},
+		{"/*", "", "/readyz"},
+		{"/{name}", "GET", "/readyz"},
+		{"/version", "GET", ""},
+		{"/api/*", "", ""},
+	}
+	for _, tt := range tests {
+		if got := shadowedPath(&config.Endpoint{Path: tt.path, Method: tt.method}); got != tt.want {
+			t.Errorf("%s %s: want %q but got %q", tt.method, tt.path, tt.want, got)
+		}
+	}
+}
+
+func TestReadinessCriticalServices(t *testing.T) {
+	enableReadiness(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	watcher := &fakeWatcher{ctx: ctx, next: make(chan []*registry.ServiceInstance, 1)}
+	instance := &registry.ServiceInstance{ID: "1", Name: "readiness-critical", Endpoints: []string{"http://127.0.0.1:9000"}}
+	watcher.next <- []*registry.ServiceInstance{instance}
+
+	p := newTestProxy(t, client.NewFactory(&fakeDiscovery{watcher: watcher}), nil)
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/users",
+			Method:   "GET",
+			Backends: []*config.Backend{{Target: "discovery:///readiness-critical"}},
+		}},
+		CriticalServices: []string{"readiness-critical"},
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	readyz := func() (int, ReadinessInfo) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/readyz", nil)
+		r.RemoteAddr = "127.0.0.1:1234"
+		p.ServeHTTP(w, r)
+		var info ReadinessInfo
+		if err := json.NewDecoder(w.Body).Decode(&info); err != nil {
+			t.Fatal(err)
+		}
+		return w.Code, info
+	}
+	waitReady := func(want bool) ReadinessInfo {
+		deadline := time.Now().Add(time.Second)
+		for {
+			code, info := readyz()
+			if info.Ready == want && (code == http.StatusOK) == want {
+				return info
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("want ready %v but got %d: %+v", want, code, info)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	waitReady(true)
+
+	// 关键服务的实例全部下线后网关不再就绪
+	watcher.next <- nil
+	info := waitReady(false)
+	if !reflect.DeepEqual(info.UnhealthyServices, []string{"readiness-critical"}) {
+		t.Fatalf("want unhealthy services [readiness-critical] but got %v", info.UnhealthyServices)
+	}
+
+	watcher.next <- []*registry.ServiceInstance{instance}
+	if info := waitReady(true); len(info.UnhealthyServices) != 0 {
+		t.Fatalf("want no unhealthy services but got %v", info.UnhealthyServices)
+	}
+
+	// 未被任何端点监控的关键服务同样视为不健康
+	c2 := proto.Clone(c).(*config.Gateway)
+	c2.CriticalServices = append(c2.CriticalServices, "readiness-unknown")
+	if err := p.Update(client.NewBuildContext(c2), c2); err != nil {
+		t.Fatal(err)
+	}
+	if info := waitReady(false); !reflect.DeepEqual(info.UnhealthyServices, []string{"readiness-unknown"}) {
+		t.Fatalf("want unhealthy services [readiness-unknown] but got %v", info.UnhealthyServices)
+	}
+}
+
+func TestDropRequestBody(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol:        config.Protocol_HTTP,
+			Path:            "/drop",
+			Method:          "GET",
+			DropRequestBody: true,
+		}, {
+			Protocol:        config.Protocol_HTTP,
+			Path:            "/drop",
+			Method:          "POST",
+			DropRequestBody: true,
+		}},
+	}
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: req.Body}, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+
+	for method, want := range map[string]string{
+		"GET":  "",
+		"POST": "body",
+	} {
+		r := httptest.NewRequest(method, "/drop", bytes.NewBufferString("body"))
+		w := newResponseWriter()
+		p.ServeHTTP(w, r)
+		if w.statusCode != http.StatusOK {
+			t.Fatalf("%s: want ok but got: %+v", method, w)
+		}
+		if got := w.body.String(); got != want {
+			t.Fatalf("%s: want body %q but got %q", method, want, got)
+		}
+	}
+}
+
+// hasMetricWithPath 判断默认注册表中指定指标是否存在 path 标签为给定值的样本
+func hasMetricWithPath(t *testing.T, name, path string) bool {
+	mfs, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() != name {
+			continue
+		}
+		for _, m := range mf.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == "path" && l.GetValue() == path {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+func TestDisableMetrics(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/metrics/enabled",
+			Method:   "POST",
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/metrics/disabled",
+			Method:   "POST",
+			Metrics:  &config.Metrics{DisableAll: true},
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/metrics/partial",
+			Method:   "POST",
+			Metrics:  &config.Metrics{DisableBytes: true},
+		}},
+	}
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: req.Body}, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+	for _, path := range []string{"/metrics/enabled", "/metrics/disabled", "/metrics/partial"} {
+		r := httptest.NewRequest("POST", path, bytes.NewBufferString("body"))
+		w := newResponseWriter()
+		p.ServeHTTP(w, r)
+		if w.statusCode != http.StatusOK {
+			t.Fatalf("%s: want ok but got: %+v", path, w)
+		}
+	}
+
+	tests := []struct {
+		metric string
+		path   string
+		want   bool
+	}{
+		{"go_gateway_requests_code_total", "/metrics/enabled", true},
+		{"go_gateway_requests_duration_seconds", "/metrics/enabled", true},
+		{"go_gateway_requests_rx_bytes", "/metrics/enabled", true},
+		{"go_gateway_requests_tx_bytes", "/metrics/enabled", true},
+		{"go_gateway_requests_code_total", "/metrics/disabled", false},
+		{"go_gateway_requests_duration_seconds", "/metrics/disabled", false},
+		{"go_gateway_requests_rx_bytes", "/metrics/disabled", false},
+		{"go_gateway_requests_tx_bytes", "/metrics/disabled", false},
+		{"go_gateway_requests_code_total", "/metrics/partial", true},
+		{"go_gateway_requests_duration_seconds", "/metrics/partial", true},
+		{"go_gateway_requests_rx_bytes", "/metrics/partial", false},
+		{"go_gateway_requests_tx_bytes", "/metrics/partial", false},
+	}
+	for _, tt := range tests {
+		if got := hasMetricWithPath(t, tt.metric, tt.path); got != tt.want {
+			t.Errorf("%s{path=%q}: want recorded=%v but got %v", tt.metric, tt.path, tt.want, got)
+		}
+	}
+}
+
+type memoryAuditSink struct {
+	events []*audit.Event
+}
+
+func (s *memoryAuditSink) Write(e *audit.Event) error {
+	s.events = append(s.events, e)
+	return nil
+}
+
+func (s *memoryAuditSink) Close() error { return nil }
+
+func TestAuditConfigUpdate(t *testing.T) {
+	sink := &memoryAuditSink{}
+	l := audit.NewLogger(sink, 0)
+	old := audit.SetLogger(l)
+	defer audit.SetLogger(old)
+
+	c := &config.Gateway{
+		Name:    "Test",
+		Version: "v1",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/audit",
+			Method:   "GET",
+		}},
+	}
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}, nil
+		}), nil
+	}
+	middlewareFactory := func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return nil, errors.New("broken middleware")
+	}
+	p, err := New(clientFactory, middlewareFactory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	broken := proto.Clone(c).(*config.Gateway)
+	broken.Version = "v2"
+	broken.Middlewares = []*config.Middleware{{Name: "broken"}}
+	if err := p.Update(client.NewBuildContext(broken), broken); err == nil {
+		t.Fatal("want update error")
+	}
+	l.Close()
+
+	if len(sink.events) != 2 {
+		t.Fatalf("want 2 audit events but got %d", len(sink.events))
+	}
+	if e := sink.events[0]; e.Type != audit.TypeConfig || e.Result != audit.ResultSuccess || e.Metadata["version"] != "v1" || e.Metadata["hash"] != configHash(c) {
+		t.Fatalf("unexpected audit event: %+v", e)
+	}
+	if e := sink.events[1]; e.Result != audit.ResultFailure || e.Metadata["version"] != "v2" || e.Reason == "" {
+		t.Fatalf("unexpected audit event: %+v", e)
+	}
+}
+
+func TestMethodOverride(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		MethodOverride: &config.MethodOverride{
+			AllowedMethods: []string{"PUT", "patch", "DELETE"},
+		},
+	}
+	for _, method := range []string{"POST", "PUT", "PATCH", "DELETE", "TRACE"} {
+		c.Endpoints = append(c.Endpoints, &config.Endpoint{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/override",
+			Method:   method,
+		})
+	}
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			header := http.Header{}
+			header.Set("X-Upstream-Method", req.Method)
+			header.Set("X-Upstream-Override", req.Header.Get("X-HTTP-Method-Override"))
+			return &http.Response{StatusCode: http.StatusOK, Header: header}, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+
+	tests := []struct {
+		method     string
+		override   string
+		wantCode   int
+		wantMethod string
+	}{
+		{"POST", "", http.StatusOK, "POST"},
+		{"POST", "PUT", http.StatusOK, "PUT"},
+		{"POST", "patch", http.StatusOK, "PATCH"},
+		{"POST", "DELETE", http.StatusOK, "DELETE"},
+		{"POST", "TRACE", http.StatusMethodNotAllowed, ""},
+		{"POST", "GET", http.StatusMethodNotAllowed, ""},
+		// 只有 POST 请求可以覆盖请求方法
+		{"PUT", "DELETE", http.StatusOK, "PUT"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, "/override", nil)
+		if tt.override != "" {
+			r.Header.Set("X-HTTP-Method-Override", tt.override)
+		}
+		w := newResponseWriter()
+		p.ServeHTTP(w, r)
+		if w.statusCode != tt.wantCode {
+			t.Fatalf("%s %s: want code %d but got %d", tt.method, tt.override, tt.wantCode, w.statusCode)
+		}
+		if tt.wantCode != http.StatusOK {
+			continue
+		}
+		if got := w.header.Get("X-Upstream-Method"); got != tt.wantMethod {
+			t.Fatalf("%s %s: want upstream method %s but got %s", tt.method, tt.override, tt.wantMethod, got)
+		}
+		if tt.method == http.MethodPost && w.header.Get("X-Upstream-Override") != "" {
+			t.Fatalf("%s %s: override header should not be forwarded", tt.method, tt.override)
+		}
+	}
+
+	// 未配置方法覆盖时忽略请求头
+	c.MethodOverride = nil
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest("POST", "/override", nil)
+	r.Header.Set("X-HTTP-Method-Override", "DELETE")
+	w := newResponseWriter()
+	p.ServeHTTP(w, r)
+	if got := w.header.Get("X-Upstream-Method"); got != "POST" {
+		t.Fatalf("want upstream method POST but got %s", got)
+	}
+}
+
+func TestTrailingSlash(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol:      config.Protocol_HTTP,
+			Path:          "/web/page",
+			Method:        "GET",
+			TrailingSlash: config.TrailingSlash_TRAILING_SLASH_REDIRECT,
+		}, {
+			Protocol:      config.Protocol_HTTP,
+			Path:          "/web/form/",
+			Method:        "POST",
+			TrailingSlash: config.TrailingSlash_TRAILING_SLASH_REDIRECT,
+		}, {
+			Protocol:      config.Protocol_HTTP,
+			Path:          "/api/users",
+			Method:        "GET",
+			TrailingSlash: config.TrailingSlash_TRAILING_SLASH_TRANSPARENT,
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/api/exact",
+			Method:   "GET",
+		}, {
+			// 显式配置的端点优先于另一种斜杠形式的路由
+			Protocol:      config.Protocol_HTTP,
+			Path:          "/api/both",
+			Method:        "GET",
+			TrailingSlash: config.TrailingSlash_TRAILING_SLASH_REDIRECT,
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/api/both/",
+			Method:   "GET",
+		}},
+	}
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			header := http.Header{}
+			header.Set("X-Endpoint", e.Path)
+			return &http.Response{StatusCode: http.StatusOK, Header: header}, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+
+	tests := []struct {
+		method       string
+		target       string
+		wantCode     int
+		wantLocation string
+		wantEndpoint string
+	}{
+		{"GET", "/web/page", http.StatusOK, "", "/web/page"},
+		{"GET", "/web/page/?a=1", http.StatusMovedPermanently, "/web/page?a=1", ""},
+		{"POST", "/web/form", http.StatusPermanentRedirect, "/web/form/", ""},
+		{"GET", "/api/users", http.StatusOK, "", "/api/users"},
+		{"GET", "/api/users/", http.StatusOK, "", "/api/users"},
+		{"GET", "/api/exact", http.StatusOK, "", "/api/exact"},
+		{"GET", "/api/exact/", http.StatusNotFound, "", ""},
+		{"GET", "/api/both/", http.StatusOK, "", "/api/both/"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, tt.target, nil)
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, r)
+		if w.Code != tt.wantCode {
+			t.Fatalf("%s %s: want code %d but got %d", tt.method, tt.target, tt.wantCode, w.Code)
+		}
+		if got := w.Header().Get("Location"); got != tt.wantLocation {
+			t.Fatalf("%s %s: want location %q but got %q", tt.method, tt.target, tt.wantLocation, got)
+		}
+		if got := w.Header().Get("X-Endpoint"); got != tt.wantEndpoint {
+			t.Fatalf("%s %s: want endpoint %q but got %q", tt.method, tt.target, tt.wantEndpoint, got)
+		}
+	}
+}
+
+// metricValue 返回默认注册表中指定计数器或仪表在给定标签下的值
+func metricValue(t *testing.T, name string, labels map[string]string) float64 {
+	mfs, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mf := range mfs {
+		if mf.GetName() != name {
+			continue
+		}
+	next:
+		for _, m := range mf.GetMetric() {
+			matched := 0
+			for _, l := range m.GetLabel() {
+				if v, ok := labels[l.GetName()]; ok {
+					if v != l.GetValue() {
+						continue next
+					}
+					matched++
+				}
+			}
+			if matched == len(labels) {
+				if g := m.GetGauge(); g != nil {
+					return g.GetValue()
+				}
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+type errReader struct {
+	data []byte
+	err  error
+}
+
+func (r *errReader) Read(p []byte) (int, error) {
+	if len(r.data) > 0 {
+		n := copy(p, r.data)
+		r.data = r.data[n:]
+		return n, nil
+	}
+	return 0, r.err
+}
+
+func (r *errReader) Close() error { return nil }
+
+type brokenResponseWriter struct {
+	*responseWriter
+}
+
+func (w brokenResponseWriter) Write([]byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func TestBodyErrorsMetrics(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/body/request",
+			Method:   "POST",
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/body/response",
+			Method:   "POST",
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/body/disconnect",
+			Method:   "POST",
+		}},
+	}
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}
+			if e.Path == "/body/response" {
+				resp.Body = &errReader{data: []byte("partial"), err: errors.New("upstream reset")}
+			} else {
+				resp.Body = io.NopCloser(bytes.NewBufferString("ok"))
+			}
+			return resp, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+
+	r := httptest.NewRequest("POST", "/body/request", &errReader{err: errors.New("client reset")})
+	w := newResponseWriter()
+	p.ServeHTTP(w, r)
+	if w.statusCode != http.StatusBadGateway {
+		t.Fatalf("want 502 but got %d", w.statusCode)
+	}
+	r = httptest.NewRequest("POST", "/body/response", nil)
+	p.ServeHTTP(newResponseWriter(), r)
+	r = httptest.NewRequest("POST", "/body/disconnect", nil)
+	p.ServeHTTP(brokenResponseWriter{newResponseWriter()}, r)
+
+	tests := []struct {
+		path string
+		typ  string
+		want float64
+	}{
+		{"/body/request", "request_read", 1},
+		{"/body/request", "response_copy", 0},
+		{"/body/response", "response_copy", 1},
+		{"/body/response", "client_disconnect", 0},
+		{"/body/disconnect", "client_disconnect", 1},
+		{"/body/disconnect", "response_copy", 0},
+	}
+	for _, tt := range tests {
+		got := metricValue(t, "go_gateway_requests_body_errors_total", map[string]string{"path": tt.path, "type": tt.typ})
+		if got != tt.want {
+			t.Errorf("%s %s: want %v but got %v", tt.path, tt.typ, tt.want, got)
+		}
+	}
+}
+
+// slowReader 在返回第一段数据后一直阻塞，直到被关闭
+type slowReader struct {
+	data   []byte
+	closed chan struct{}
+	once   sync.Once
+}
+
+func (r *slowReader) Read(p []byte) (int, error) {
+	if len(r.data) > 0 {
+		n := copy(p, r.data)
+		r.data = r.data[n:]
+		return n, nil
+	}
+	<-r.closed
+	return 0, errors.New("read on closed body")
+}
+
+func (r *slowReader) Close() error {
+	r.once.Do(func() { close(r.closed) })
+	return nil
+}
+
+func TestBodyCopyDeadline(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/body/slow",
+			Method:   "GET",
+			Timeout:  durationpb.New(100 * time.Millisecond),
+		}},
+	}
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       &slowReader{data: []byte("partial"), closed: make(chan struct{})},
+			}, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+
+	done := make(chan struct{})
+	w := httptest.NewRecorder()
+	go func() {
+		defer close(done)
+		p.ServeHTTP(w, httptest.NewRequest("GET", "/body/slow", nil))
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("body copy was not aborted at the deadline")
+	}
+	if got := w.Body.String(); got != "partial" {
+		t.Fatalf("want partial body but got %q", got)
+	}
+	labels := map[string]string{"path": "/body/slow", "type": "deadline"}
+	if got := metricValue(t, "go_gateway_requests_body_errors_total", labels); got != 1 {
+		t.Fatalf("want 1 deadline body error but got %v", got)
+	}
+	if got := metricValue(t, "go_gateway_requests_tx_bytes", map[string]string{"path": "/body/slow"}); got != float64(len("partial")) {
+		t.Fatalf("want %d sent bytes but got %v", len("partial"), got)
+	}
+}
+
+func TestDefaultEndpoint(t *testing.T) {
+	enableReadiness(t)
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/api/users",
+			Method:   "GET",
+			Metadata: map[string]string{"service": "users"},
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/api/orders/*",
+			Method:   "POST",
+			Metadata: map[string]string{"service": "orders"},
+		}},
+		DefaultEndpoint: &config.Endpoint{
+			Protocol: config.Protocol_HTTP,
+			Metadata: map[string]string{"service": "legacy"},
+		},
+	}
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			header := http.Header{}
+			header.Set("X-Service", e.Metadata["service"])
+			return &http.Response{StatusCode: http.StatusOK, Header: header}, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{"GET", "/api/users", "users"},
+		{"POST", "/api/orders/1", "orders"},
+		{"GET", "/api/products", "legacy"},
+		{"DELETE", "/legacy/page", "legacy"},
+		{"GET", "/", "legacy"},
+		// 网关自身的处理程序优先于默认端点
+		{"GET", "/readyz", ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, tt.path, nil)
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, r)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s %s: want ok but got %d", tt.method, tt.path, w.Code)
+		}
+		if got := w.Header().Get("X-Service"); got != tt.want {
+			t.Fatalf("%s %s: want service %q but got %q", tt.method, tt.path, tt.want, got)
+		}
+	}
+	if info := p.Readiness(); info.Endpoints != 2 {
+		t.Fatalf("want 2 endpoints but got %d", info.Endpoints)
+	}
+
+	// 未配置就绪探针的路径时不注册就绪探针，请求由默认端点处理
+	readinessPath = ""
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	probe := httptest.NewRecorder()
+	p.ServeHTTP(probe, httptest.NewRequest("GET", "/readyz", nil))
+	if got := probe.Header().Get("X-Service"); probe.Code != http.StatusOK || got != "legacy" {
+		t.Fatalf("want /readyz routed to the default endpoint but got %d %q", probe.Code, got)
+	}
+
+	// 未配置默认端点时未匹配的请求返回 404
+	c.DefaultEndpoint = nil
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/api/products", nil))
+	if w.Code != http.StatusNotFound {
+		t.Fatalf("want 404 but got %d", w.Code)
+	}
+}
+
+func TestHeaderRouting(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/assets",
+			Method:   "GET",
+			Metadata: map[string]string{"service": "cdn-edge"},
+			Headers:  []*config.HeaderMatch{{Name: "X-Cdn-Pop", Value: "edge"}},
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/assets",
+			Method:   "GET",
+			Metadata: map[string]string{"service": "cdn-region"},
+			Headers:  []*config.HeaderMatch{{Name: "X-Cdn-Pop", Regex: "^region-[0-9]+$"}},
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/assets",
+			Method:   "GET",
+			Metadata: map[string]string{"service": "cdn-any"},
+			Headers:  []*config.HeaderMatch{{Name: "X-Cdn-Pop"}},
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/assets",
+			Method:   "GET",
+			Metadata: map[string]string{"service": "origin"},
+		}},
+	}
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			header := http.Header{}
+			header.Set("X-Service", e.Metadata["service"])
+			return &http.Response{StatusCode: http.StatusOK, Header: header}, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+
+	tests := []struct {
+		pop  string
+		want string
+	}{
+		{"edge", "cdn-edge"},
+		{"region-12", "cdn-region"},
+		{"region-x", "cdn-any"},
+		{"", "origin"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/assets", nil)
+		if tt.pop != "" {
+			r.Header.Set("X-Cdn-Pop", tt.pop)
+		}
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, r)
+		if got := w.Header().Get("X-Service"); got != tt.want {
+			t.Fatalf("X-Cdn-Pop %q: want service %q but got %q", tt.pop, tt.want, got)
+		}
+	}
+
+	// 非法的正则表达式会导致更新失败
+	c.Endpoints[1].Headers[0].Regex = "("
+	if err := p.Update(client.NewBuildContext(c), c); err == nil {
+		t.Fatal("want error for invalid header regex")
+	}
+}
+
+func TestQueryRouting(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/search",
+			Method:   "GET",
+			Metadata: map[string]string{"service": "image"},
+			Queries:  []*config.QueryMatch{{Name: "type", Value: "image"}},
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/search",
+			Method:   "GET",
+			Metadata: map[string]string{"service": "video"},
+			Queries:  []*config.QueryMatch{{Name: "type", Regex: "^(video|movie)$"}},
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/search",
+			Method:   "GET",
+			Metadata: map[string]string{"service": "typed"},
+			Queries:  []*config.QueryMatch{{Name: "type"}},
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/search",
+			Method:   "GET",
+			Metadata: map[string]string{"service": "web"},
+		}},
+	}
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			header := http.Header{}
+			header.Set("X-Service", e.Metadata["service"])
+			return &http.Response{StatusCode: http.StatusOK, Header: header}, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"type=image", "image"},
+		{"q=cat&type=video", "video"},
+		{"type=movie", "video"},
+		{"type=news", "typed"},
+		{"type=", "typed"},
+		{"q=cat", "web"},
+		{"", "web"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/search?"+tt.query, nil)
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, r)
+		if got := w.Header().Get("X-Service"); got != tt.want {
+			t.Fatalf("query %q: want service %q but got %q", tt.query, tt.want, got)
+		}
+	}
+
+	// 非法的正则表达式会导致更新失败
+	c.Endpoints[1].Queries[0].Regex = "("
+	if err := p.Update(client.NewBuildContext(c), c); err == nil {
+		t.Fatal("want error for invalid query regex")
+	}
+}
+
+func TestWarmup(t *testing.T) {
+	enableReadiness(t)
+	oldTimeout := warmupTimeout
+	defer func() { warmupTimeout = oldTimeout }()
+
+	// 预热请求等待 gate 关闭后才返回，conns 记录后端接受的连接数
+	var gate atomic.Value
+	var conns atomic.Int64
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			select {
+			case <-gate.Load().(chan struct{}):
+			case <-r.Context().Done():
+			}
+		}
+	}))
+	backend.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			conns.Add(1)
+		}
+	}
+	backend.Start()
+	defer backend.Close()
+	addr := strings.TrimPrefix(backend.URL, "http://")
+
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/warmup",
+			Method:   "GET",
+			Backends: []*config.Backend{{Target: addr}},
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/warmup2",
+			Method:   "GET",
+			Backends: []*config.Backend{{Target: addr}},
+		}},
+	}
+	p := newTestProxy(t, client.NewFactory(nil), nil)
+	readyCode := func() int {
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+		return w.Code
+	}
+	if code := readyCode(); code != http.StatusServiceUnavailable {
+		t.Fatalf("want 503 before config loaded but got %d", code)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("completed", func(t *testing.T) {
+		warmupTimeout = time.Minute
+		release := make(chan struct{})
+		gate.Store(release)
+		done := p.Warmup(context.Background())
+		if code := readyCode(); code != http.StatusServiceUnavailable {
+			t.Fatalf("want 503 during warmup but got %d", code)
+		}
+		close(release)
+		<-done
+		if code := readyCode(); code != http.StatusOK {
+			t.Fatalf("want 200 after warmup but got %d", code)
+		}
+		// 相同的后端只预热一次，每个预热请求建立一个连接
+		if n := conns.Load(); n != int64(warmupConns) {
+			t.Fatalf("want %d warmed connections but got %d", warmupConns, n)
+		}
+		// 之后的请求复用预热建立的连接
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, httptest.NewRequest("GET", "/warmup", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("want 200 but got %d", w.Code)
+		}
+		if n := conns.Load(); n != int64(warmupConns) {
+			t.Fatalf("want the request to reuse a warmed connection but got %d connections", n)
+		}
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		warmupTimeout = 50 * time.Millisecond
+		gate.Store(make(chan struct{}))
+		done := p.Warmup(context.Background())
+		if code := readyCode(); code != http.StatusServiceUnavailable {
+			t.Fatalf("want 503 during warmup but got %d", code)
+		}
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("warmup should be bounded by the timeout")
+		}
+		if code := readyCode(); code != http.StatusOK {
+			t.Fatalf("want 200 after warmup timed out but got %d", code)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		warmupTimeout = 0
+		<-p.Warmup(context.Background())
+		if code := readyCode(); code != http.StatusOK {
+			t.Fatalf("want 200 but got %d", code)
+		}
+	})
+}
+
+func TestRequestsInFlight(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/inflight/a",
+			Method:   "GET",
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/inflight/b",
+			Method:   "GET",
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/inflight/panic",
+			Method:   "GET",
+		}},
+	}
+	release := make(chan struct{})
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			if e.Path == "/inflight/panic" {
+				panic("upstream panic")
+			}
+			select {
+			case <-release:
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			}
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+	inFlight := func(path string) float64 {
+		return metricValue(t, "go_gateway_requests_in_flight", map[string]string{"path": path})
+	}
+	waitInFlight := func(path string, want float64) {
+		deadline := time.Now().Add(5 * time.Second)
+		for inFlight(path) != want {
+			if time.Now().After(deadline) {
+				t.Fatalf("%s: want %v in flight but got %v", path, want, inFlight(path))
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	wg := sync.WaitGroup{}
+	serve := func(ctx context.Context, path string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r := httptest.NewRequest("GET", path, nil).WithContext(ctx)
+			p.ServeHTTP(httptest.NewRecorder(), r)
+		}()
+	}
+	for i := 0; i < 3; i++ {
+		serve(context.Background(), "/inflight/a")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	serve(context.Background(), "/inflight/b")
+	serve(ctx, "/inflight/b")
+	waitInFlight("/inflight/a", 3)
+	waitInFlight("/inflight/b", 2)
+
+	// 取消的请求不再计入
+	cancel()
+	waitInFlight("/inflight/b", 1)
+	if got := inFlight("/inflight/a"); got != 3 {
+		t.Fatalf("want 3 in flight but got %v", got)
+	}
+
+	// 发生 panic 的请求同样不再计入
+	p.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/inflight/panic", nil))
+	if got := inFlight("/inflight/panic"); got != 0 {
+		t.Fatalf("want 0 in flight after panic but got %v", got)
+	}
+
+	close(release)
+	wg.Wait()
+	waitInFlight("/inflight/a", 0)
+	waitInFlight("/inflight/b", 0)
+}
+
+func TestMultipleSetCookie(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/cookies",
+			Method:   "GET",
+		}},
+	}
+	cookies := []string{
+		"a=1; Path=/",
+		"b=2; Path=/; Expires=Wed, 21 Oct 2015 07:28:00 GMT",
+		"c=3; Path=/; HttpOnly",
+	}
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			header := http.Header{}
+			for _, c := range cookies {
+				header.Add("Set-Cookie", c)
+			}
+			return &http.Response{StatusCode: http.StatusOK, Header: header}, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+	srv := httptest.NewServer(p)
+	defer srv.Close()
+	resp, err := http.Get(srv.URL + "/cookies")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if got := resp.Header.Values("Set-Cookie"); !reflect.DeepEqual(got, cookies) {
+		t.Fatalf("want %q but got %q", cookies, got)
+	}
+}
+
+func TestStreamingResponseFlush(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/stream",
+			Method:   "GET",
+		}},
+	}
+	messages := make(chan string)
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			pr, pw := io.Pipe()
+			go func() {
+				for msg := range messages {
+					pw.Write([]byte(msg + "\n"))
+				}
+				pw.Close()
+			}()
+			header := http.Header{}
+			header.Set("Content-Type", "application/x-ndjson")
+			return &http.Response{StatusCode: http.StatusOK, Header: header, Body: pr}, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+	srv := httptest.NewServer(p)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	reader := bufio.NewReader(resp.Body)
+	for _, msg := range []string{`{"n":1}`, `{"n":2}`} {
+		messages <- msg
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if line != msg+"\n" {
+			t.Fatalf("want %q but got %q", msg+"\n", line)
+		}
+	}
+	close(messages)
+}
+
+func TestGRPCWebStreamingFlush(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/stream",
+			Method:   "POST",
+		}},
+	}
+	frames := make(chan []byte)
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			pr, pw := io.Pipe()
+			go func() {
+				for frame := range frames {
+					pw.Write(frame)
+				}
+				pw.Close()
+			}()
+			header := http.Header{}
+			header.Set("Content-Type", "application/grpc-web+proto")
+			return &http.Response{StatusCode: http.StatusOK, Header: header, ContentLength: -1, Body: pr}, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+	srv := httptest.NewServer(p)
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/stream", "application/grpc-web+proto", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	// 上游结束之前客户端就能读到每一个消息帧
+	for _, msg := range []string{"first", "second"} {
+		frame := append([]byte{0, 0, 0, 0, byte(len(msg))}, msg...)
+		frames <- frame
+		got := make([]byte, len(frame))
+		if _, err := io.ReadFull(resp.Body, got); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, frame) {
+			t.Fatalf("want frame %q but got %q", frame, got)
+		}
+	}
+	close(frames)
+}
+
+func TestRouteTemplateHeader(t *testing.T) {
+	defer func(old string) { routeTemplateHeader = old }(routeTemplateHeader)
+	routeTemplateHeader = "X-Route-Template"
+
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/api/users/{id}",
+			Method:   "GET",
+		}},
+		DefaultEndpoint: &config.Endpoint{
+			Protocol: config.Protocol_HTTP,
+		},
+	}
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+
+	defer func(networks []*net.IPNet) { trust.Networks = networks }(trust.Networks)
+	tests := []struct {
+		networks   string
+		path       string
+		remoteAddr string
+		forwarded  string
+		want       string
+	}{
+		{"127.0.0.0/8", "/api/users/43", "127.0.0.1:1234", "", "/api/users/{id}"},
+		// 默认只信任回环地址，私有网段需要显式配置
+		{"127.0.0.0/8", "/api/users/42", "10.0.0.1:1234", "", ""},
+		{"127.0.0.0/8,10.0.0.0/8", "/api/users/42", "10.0.0.1:1234", "", "/api/users/{id}"},
+		{"127.0.0.0/8,10.0.0.0/8", "/unknown", "10.0.0.1:1234", "", "/*"},
+		// 不受信任的来源
+		{"127.0.0.0/8,10.0.0.0/8", "/api/users/42", "203.0.113.1:1234", "", ""},
+		// 经过受信任的代理转发的受信任来源的请求
+		{"127.0.0.0/8,10.0.0.0/8", "/api/users/42", "127.0.0.1:1234", "10.0.0.2", "/api/users/{id}"},
+		// 经过受信任的代理转发的不受信任来源的请求
+		{"127.0.0.0/8,10.0.0.0/8", "/api/users/42", "10.0.0.1:1234", "203.0.113.1", ""},
+		// 不受信任的对端伪造 X-Forwarded-For
+		{"127.0.0.0/8,10.0.0.0/8", "/api/users/42", "203.0.113.1:1234", "127.0.0.1", ""},
+	}
+	for _, tt := range tests {
+		trust.Networks = trust.MustParseCIDRs(tt.networks)
+		r := httptest.NewRequest("GET", tt.path, nil)
+		r.RemoteAddr = tt.remoteAddr
+		if tt.forwarded != "" {
+			r.Header.Set("X-Forwarded-For", tt.forwarded)
+		}
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, r)
+		if got := w.Header().Get("X-Route-Template"); got != tt.want {
+			t.Fatalf("%s from %s: want template %q but got %q", tt.path, tt.remoteAddr, tt.want, got)
+		}
+	}
+}
+
+func TestVersionHandler(t *testing.T) {
+	defer func(version, sha, buildTime string) {
+		Version, BuildSHA, BuildTime = version, sha, buildTime
+	}(Version, BuildSHA, BuildTime)
+	SetBuildInfo("v1.2.3", "abcdef", "2024-12-01T00:00:00Z")
+	// 为空的值保持不变
+	SetBuildInfo("", "", "")
+
+	p, err := New(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 构建信息只由调试处理程序提供
+	w := httptest.NewRecorder()
+	p.DebugHandler().ServeHTTP(w, httptest.NewRequest("GET", "/debug/proxy/version", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("want 200 but got %d", w.Code)
+	}
+	info := BuildInfo{}
+	if err := json.Unmarshal(w.Body.Bytes(), &info); err != nil {
+		t.Fatal(err)
+	}
+	want := BuildInfo{Version: "v1.2.3", BuildSHA: "abcdef", BuildTime: "2024-12-01T00:00:00Z", GoVersion: runtime.Version()}
+	if info != want {
+		t.Fatalf("want %+v but got %+v", want, info)
+	}
+
+	// 数据面不占用 /version，请求由端点处理
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/*",
+		}},
+	}
+	p, err = New(func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			header := http.Header{}
+			header.Set("X-Backend", "api")
+			return &http.Response{StatusCode: http.StatusOK, Header: header}, nil
+		}), nil
+	}, func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return logging.Middleware(c)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	r := httptest.NewRequest("GET", "/version", nil)
+	r.RemoteAddr = "127.0.0.1:1234"
+	w = httptest.NewRecorder()
+	p.ServeHTTP(w, r)
+	if w.Code != http.StatusOK || w.Header().Get("X-Backend") != "api" {
+		t.Fatalf("want /version routed to the endpoint but got %d", w.Code)
+	}
+}
+
+func TestResponseLimit(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol:      config.Protocol_HTTP,
+			Path:          "/limit/reject",
+			Method:        "GET",
+			ResponseLimit: &config.ResponseLimit{MaxBytes: 5},
+		}, {
+			Protocol:      config.Protocol_HTTP,
+			Path:          "/limit/truncate",
+			Method:        "GET",
+			ResponseLimit: &config.ResponseLimit{MaxBytes: 5, Action: config.ResponseLimit_TRUNCATE},
+		}},
+	}
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			body := req.URL.Query().Get("body")
+			resp := &http.Response{
+				StatusCode:    http.StatusOK,
+				Header:        http.Header{},
+				Body:          io.NopCloser(strings.NewReader(body)),
+				ContentLength: -1,
+			}
+			// 声明长度的响应带有 Content-Length 头
+			if req.URL.Query().Get("length") != "" {
+				resp.ContentLength = int64(len(body))
+				resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+			}
+			return resp, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+
+	tests := []struct {
+		target   string
+		wantCode int
+		wantBody string
+	}{
+		{"/limit/reject?body=hello", 200, "hello"},
+		{"/limit/reject?body=hello&length=1", 200, "hello"},
+		{"/limit/reject?body=hello+world", 502, ""},
+		{"/limit/reject?body=hello+world&length=1", 502, ""},
+		{"/limit/truncate?body=hello", 200, "hello"},
+		{"/limit/truncate?body=hello+world", 200, "hello"},
+		{"/limit/truncate?body=hello+world&length=1", 200, "hello"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, httptest.NewRequest("GET", tt.target, nil))
+		if w.Code != tt.wantCode || w.Body.String() != tt.wantBody {
+			t.Fatalf("%s: want %d %q but got %d %q", tt.target, tt.wantCode, tt.wantBody, w.Code, w.Body.String())
+		}
+		if tt.wantCode == 200 {
+			if cl := w.Header().Get("Content-Length"); cl != "" && cl != strconv.Itoa(len(tt.wantBody)) {
+				t.Fatalf("%s: want Content-Length %d but got %s", tt.target, len(tt.wantBody), cl)
+			}
+		}
+	}
+	labels := map[string]string{"path": "/limit/reject", "type": "response_too_large"}
+	if got := metricValue(t, "go_gateway_requests_body_errors_total", labels); got != 2 {
+		t.Fatalf("want 2 oversized responses but got %v", got)
+	}
+}
+
+func TestConfigGeneration(t *testing.T) {
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, nil)
+	generation := func(query string) (int, GenerationInfo) {
+		w := httptest.NewRecorder()
+		p.DebugHandler().ServeHTTP(w, httptest.NewRequest("GET", "/debug/proxy/config/generation"+query, nil))
+		var info GenerationInfo
+		if err := json.NewDecoder(w.Body).Decode(&info); err != nil {
+			t.Fatal(err)
+		}
+		return w.Code, info
+	}
+	if code, info := generation(""); code != http.StatusOK || info.Generation != 0 {
+		t.Fatalf("want generation 0 before any update but got %d %+v", code, info)
+	}
+
+	for i := 1; i <= 2; i++ {
+		c := &config.Gateway{
+			Name:    "Test",
+			Version: "v" + strconv.Itoa(i),
+			Endpoints: []*config.Endpoint{{
+				Protocol: config.Protocol_HTTP,
+				Path:     "/foo",
+				Method:   "GET",
+			}},
+		}
+		if err := p.Update(client.NewBuildContext(c), c); err != nil {
+			t.Fatal(err)
+		}
+		code, info := generation("")
+		if code != http.StatusOK || info.Generation != uint64(i) || info.ConfigHash != configHash(c) || info.ConfigVersion != c.Version {
+			t.Fatalf("want generation %d of %s but got %d %+v", i, c.Version, code, info)
+		}
+		if ready := p.Readiness(); ready.Generation != uint64(i) {
+			t.Fatalf("want readiness generation %d but got %d", i, ready.Generation)
+		}
+	}
+
+	// 失败的更新不增加代数
+	bad := &config.Gateway{Name: "Test", Endpoints: []*config.Endpoint{{Protocol: config.Protocol_HTTP, Path: "/foo", Method: "GET", Retry: &config.Retry{Conditions: []*config.Condition{{Condition: &config.Condition_ByStatusCode{ByStatusCode: "x"}}}}}}}
+	if err := p.Update(client.NewBuildContext(bad), bad); err == nil {
+		t.Fatal("want the update failed")
+	}
+	if info := p.Generation(); info.Generation != 2 {
+		t.Fatalf("want generation 2 after a failed update but got %d", info.Generation)
+	}
+
+	// 等待尚未生效的代数超时
+	if code, info := generation("?generation=3&timeout=50ms"); code != http.StatusGatewayTimeout || info.Generation != 2 {
+		t.Fatalf("want 504 with generation 2 but got %d %+v", code, info)
+	}
+
+	// 等待下一次更新生效
+	next := &config.Gateway{Name: "Test", Version: "v3", Endpoints: []*config.Endpoint{{Protocol: config.Protocol_HTTP, Path: "/bar", Method: "GET"}}}
+	done := make(chan GenerationInfo)
+	go func() {
+		_, info := generation("?hash=" + configHash(next) + "&timeout=5s")
+		done <- info
+	}()
+	time.Sleep(50 * time.Millisecond)
+	if err := p.Update(client.NewBuildContext(next), next); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case info := <-done:
+		if info.Generation != 3 || info.ConfigHash != configHash(next) {
+			t.Fatalf("want generation 3 of the new config but got %+v", info)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("waiting for the generation was not woken up by the update")
+	}
+}
+
+func TestMaxURLLength(t *testing.T) {
+	old := maxURLLength
+	maxURLLength = 32
+	defer func() { maxURLLength = old }()
+
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/global",
+			Method:   "GET",
+		}, {
+			Protocol:     config.Protocol_HTTP,
+			Path:         "/strict",
+			Method:       "GET",
+			MaxUrlLength: 16,
+		}, {
+			Protocol:     config.Protocol_HTTP,
+			Path:         "/loose",
+			Method:       "GET",
+			MaxUrlLength: 64,
+		}},
+	}
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}}, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+
+	// uri 函数返回指定长度的请求 URI
+	uri := func(path string, length int) string {
+		return path + "?q=" + strings.Repeat("x", length-len(path)-3)
+	}
+	tests := []struct {
+		uri  string
+		want int
+	}{
+		{uri("/global", 31), 200},
+		{uri("/global", 32), 200},
+		{uri("/global", 33), 414},
+		{uri("/strict", 15), 200},
+		{uri("/strict", 16), 200},
+		{uri("/strict", 17), 414},
+		{uri("/loose", 63), 200},
+		{uri("/loose", 64), 200},
+		{uri("/loose", 65), 414},
+		// 超过所有限制的请求在路由之前被拒绝
+		{uri("/unknown", 65), 414},
+		{uri("/unknown", 40), 404},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, httptest.NewRequest("GET", tt.uri, nil))
+		if w.Code != tt.want {
+			t.Fatalf("%s (%d): want %d but got %d", tt.uri, len(tt.uri), tt.want, w.Code)
+		}
+	}
+}
+
+func TestClientCancel(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/cancel/request",
+			Method:   "GET",
+			Retry:    &config.Retry{Attempts: 3},
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/cancel/response",
+			Method:   "GET",
+		}},
+	}
+	var attempts atomic.Int32
+	started := make(chan struct{}, 1)
+	upstreamErr := make(chan error, 1)
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			if e.Path == "/cancel/response" {
+				started <- struct{}{}
+				return &http.Response{
+					StatusCode: http.StatusOK,
+					Header:     http.Header{},
+					Body:       &slowReader{data: []byte("partial"), closed: make(chan struct{})},
+				}, nil
+			}
+			attempts.Add(1)
+			started <- struct{}{}
+			// 上游一直阻塞，直到请求的上下文被取消
+			select {
+			case <-req.Context().Done():
+				upstreamErr <- req.Context().Err()
+				return nil, req.Context().Err()
+			case <-time.After(5 * time.Second):
+				upstreamErr <- nil
+				return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody}, nil
+			}
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+
+	// 客户端在上游返回之前断开连接，上游请求应当随之取消且不再重试
+	srv := httptest.NewServer(p)
+	defer srv.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL+"/cancel/request", nil)
+	if _, err := http.DefaultClient.Do(req); !errors.Is(err, context.Canceled) {
+		t.Fatalf("want client request canceled but got %v", err)
+	}
+	select {
+	case err := <-upstreamErr:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("want upstream context canceled but got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("upstream request was not canceled")
+	}
+	labels := map[string]string{"path": "/cancel/request", "stage": "request"}
+	deadline := time.Now().Add(2 * time.Second)
+	for metricValue(t, "go_gateway_requests_client_canceled_total", labels) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("want 1 client canceled request")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := attempts.Load(); got != 1 {
+		t.Fatalf("want 1 attempt but got %d", got)
+	}
+	if got := metricValue(t, "go_gateway_requests_code_total", map[string]string{"path": "/cancel/request", "code": "499"}); got != 1 {
+		t.Fatalf("want 1 request with status 499 but got %v", got)
+	}
+
+	// 客户端在接收响应体的过程中断开连接
+	ctx, cancel = context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.ServeHTTP(newResponseWriter(), httptest.NewRequest("GET", "/cancel/response", nil).WithContext(ctx))
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("body copy was not aborted after the client disconnected")
+	}
+	labels = map[string]string{"path": "/cancel/response", "stage": "response"}
+	if got := metricValue(t, "go_gateway_requests_client_canceled_total", labels); got != 1 {
+		t.Fatalf("want 1 client canceled response but got %v", got)
+	}
+	labels = map[string]string{"path": "/cancel/response", "type": "client_disconnect"}
+	if got := metricValue(t, "go_gateway_requests_body_errors_total", labels); got != 1 {
+		t.Fatalf("want 1 client disconnect body error but got %v", got)
+	}
+}
+
+func TestRequestBuffering(t *testing.T) {
+	dir := t.TempDir()
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol:         config.Protocol_HTTP,
+			Path:             "/upload",
+			Method:           "POST",
+			Retry:            &config.Retry{Attempts: 3},
+			RequestBuffering: &config.RequestBuffering{MaxMemoryBytes: 1024, TempDir: dir},
+		}},
+	}
+	var (
+		bodies    [][]byte
+		tempFiles []int
+	)
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			bodies = append(bodies, body)
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				return nil, err
+			}
+			tempFiles = append(tempFiles, len(entries))
+			// 前两次尝试失败，验证请求体在重试时能够重放
+			if len(bodies) < 3 {
+				return nil, errors.New("upstream reset")
+			}
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody}, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+
+	tests := []struct {
+		name     string
+		size     int
+		wantFile int
+	}{
+		{"memory", 1024, 0},
+		{"disk", 4 << 20, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bodies, tempFiles = nil, nil
+			want := bytes.Repeat([]byte("0123456789abcdef"), tt.size/16)
+			w := newResponseWriter()
+			p.ServeHTTP(w, httptest.NewRequest("POST", "/upload", bytes.NewReader(want)))
+			if w.statusCode != http.StatusOK {
+				t.Fatalf("want 200 but got %d", w.statusCode)
+			}
+			if len(bodies) != 3 {
+				t.Fatalf("want 3 attempts but got %d", len(bodies))
+			}
+			for i, body := range bodies {
+				if !bytes.Equal(body, want) {
+					t.Fatalf("attempt %d: want %d bytes body but got %d bytes", i+1, len(want), len(body))
+				}
+				if tempFiles[i] != tt.wantFile {
+					t.Fatalf("attempt %d: want %d temp files but got %d", i+1, tt.wantFile, tempFiles[i])
+				}
+			}
+			// 请求结束后删除临时文件
+			if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+				t.Fatalf("want temp files removed but got %d", len(entries))
+			}
+		})
+	}
+}
+
+func TestHeadAsGet(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol:  config.Protocol_HTTP,
+			Path:      "/head/synthesized",
+			Method:    "GET",
+			HeadAsGet: true,
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/head/plain",
+			Method:   "GET",
+		}},
+	}
+	var methods []string
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			methods = append(methods, req.Method)
+			header := http.Header{}
+			header.Set("Content-Type", "text/plain")
+			header.Set("Content-Length", "5")
+			header.Set("ETag", `"v1"`)
+			return &http.Response{
+				StatusCode:    http.StatusOK,
+				Header:        header,
+				Body:          io.NopCloser(bytes.NewBufferString("hello")),
+				ContentLength: 5,
+			}, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+	srv := httptest.NewServer(p)
+	defer srv.Close()
+
+	resp, err := http.Head(srv.URL + "/head/synthesized")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || len(body) != 0 {
+		t.Fatalf("want 200 without body but got %d %q", resp.StatusCode, body)
+	}
+	if resp.ContentLength != 5 || resp.Header.Get("ETag") != `"v1"` || resp.Header.Get("Content-Type") != "text/plain" {
+		t.Fatalf("want the headers of the GET response but got %d %v", resp.ContentLength, resp.Header)
+	}
+	if !reflect.DeepEqual(methods, []string{"GET"}) {
+		t.Fatalf("want upstream GET but got %v", methods)
+	}
+	if got := metricValue(t, "go_gateway_requests_body_errors_total", map[string]string{"path": "/head/synthesized"}); got != 0 {
+		t.Fatalf("want no body errors but got %v", got)
+	}
+
+	// GET 请求不受影响
+	resp, err = http.Get(srv.URL + "/head/synthesized")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "hello" {
+		t.Fatalf("want GET body but got %q", body)
+	}
+
+	// 未开启时 HEAD 请求不会路由到 GET 端点
+	resp, err = http.Head(srv.URL + "/head/plain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("want 405 but got %d", resp.StatusCode)
+	}
+}
+
+func TestAllowedMethods(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/items",
+			Method:   "GET",
+		}, {
+			Protocol:       config.Protocol_HTTP,
+			Path:           "/items",
+			Method:         "POST",
+			AllowedMethods: []string{"PUT"},
+			MethodNotAllowed: &config.RejectResponse{
+				Headers: map[string]string{"Content-Type": "application/json"},
+				Body:    []byte(`{"error":"method not allowed"}`),
+			},
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/plain",
+			Method:   "GET",
+		}},
+	}
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody}, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+
+	tests := []struct {
+		method     string
+		path       string
+		wantStatus int
+		wantAllow  string
+		wantBody   string
+	}{
+		{"GET", "/items", http.StatusOK, "", ""},
+		{"PUT", "/items", http.StatusOK, "", ""},
+		{"DELETE", "/items", http.StatusMethodNotAllowed, "GET, OPTIONS, POST, PUT", `{"error":"method not allowed"}`},
+		{"DELETE", "/plain", http.StatusMethodNotAllowed, "GET, OPTIONS", "Method Not Allowed\n"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+		if w.Code != tt.wantStatus {
+			t.Fatalf("%s %s: want status %d but got %d", tt.method, tt.path, tt.wantStatus, w.Code)
+		}
+		if got := w.Header().Get("Allow"); got != tt.wantAllow {
+			t.Fatalf("%s %s: want Allow %q but got %q", tt.method, tt.path, tt.wantAllow, got)
+		}
+		if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+			t.Fatalf("%s %s: want body %q but got %q", tt.method, tt.path, tt.wantBody, w.Body.String())
+		}
+	}
+}
+
+// headerNotifyWriter 在写入响应头时通知测试
+type headerNotifyWriter struct {
+	*responseWriter
+	wroteHeader chan http.Header
+}
+
+func (w *headerNotifyWriter) WriteHeader(statusCode int) {
+	w.responseWriter.WriteHeader(statusCode)
+	w.wroteHeader <- w.header.Clone()
+}
+
+func TestSniffContentType(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol:         config.Protocol_HTTP,
+			Path:             "/sniff/*",
+			SniffContentType: true,
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/plain/*",
+		}},
+	}
+	var stream *io.PipeReader
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			header := http.Header{}
+			var body io.ReadCloser = io.NopCloser(strings.NewReader("<!DOCTYPE html><html></html>"))
+			switch strings.TrimPrefix(strings.TrimPrefix(req.URL.Path, "/sniff"), "/plain") {
+			case "/typed":
+				header.Set("Content-Type", "application/octet-stream")
+			case "/gzip":
+				header.Set("Content-Encoding", "gzip")
+			case "/json":
+				body = io.NopCloser(strings.NewReader(`{"ok":true}`))
+			case "/stream":
+				body = stream
+			}
+			return &http.Response{StatusCode: http.StatusOK, Header: header, Body: body, ContentLength: -1}, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/sniff/html", "text/html; charset=utf-8"},
+		{"/sniff/json", "text/plain; charset=utf-8"},
+		// 上游设置了 Content-Type 或压缩了响应体时不检测
+		{"/sniff/typed", "application/octet-stream"},
+		{"/sniff/gzip", ""},
+		// 端点未启用时不检测
+		{"/plain/html", ""},
+	}
+	for _, tt := range tests {
+		w := newResponseWriter()
+		p.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if got := w.header.Get("Content-Type"); got != tt.want {
+			t.Fatalf("%s: want Content-Type %q but got %q", tt.path, tt.want, got)
+		}
+		if w.statusCode != http.StatusOK || !strings.HasPrefix(w.body.String(), "<!DOCTYPE html>") && !strings.HasPrefix(w.body.String(), "{") {
+			t.Fatalf("%s: want the whole upstream body but got %d %q", tt.path, w.statusCode, w.body.String())
+		}
+	}
+
+	// 流式响应在收到第一段数据后立即写入响应头，不等待整个响应体
+	var pw *io.PipeWriter
+	stream, pw = io.Pipe()
+	w := &headerNotifyWriter{responseWriter: newResponseWriter(), wroteHeader: make(chan http.Header, 1)}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.ServeHTTP(w, httptest.NewRequest("GET", "/sniff/stream", nil))
+	}()
+	_, _ = pw.Write([]byte("data: first\n\n"))
+	select {
+	case header := <-w.wroteHeader:
+		if got := header.Get("Content-Type"); got != "text/plain; charset=utf-8" {
+			t.Fatalf("want Content-Type sniffed from the first chunk but got %q", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("response header is not written before the stream ends")
+	}
+	_, _ = pw.Write([]byte("data: second\n\n"))
+	pw.Close()
+	<-done
+	if got := w.body.String(); got != "data: first\n\ndata: second\n\n" {
+		t.Fatalf("want the whole stream but got %q", got)
+	}
+}
+
+func TestResponseBuffering(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol:          config.Protocol_HTTP,
+			Path:              "/buffered/*",
+			ResponseBuffering: &config.ResponseBuffering{ThresholdBytes: 4096},
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/plain/*",
+		}},
+	}
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			size, _ := strconv.Atoi(req.URL.Query().Get("size"))
+			header := http.Header{}
+			header.Set("Content-Type", "text/plain")
+			return &http.Response{
+				StatusCode:    http.StatusOK,
+				Header:        header,
+				Body:          io.NopCloser(strings.NewReader(strings.Repeat("a", size))),
+				ContentLength: -1,
+			}, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+	srv := httptest.NewServer(p)
+	defer srv.Close()
+
+	tests := []struct {
+		path          string
+		size          int
+		contentLength int64
+	}{
+		{"/buffered/small", 3000, 3000},
+		{"/buffered/threshold", 4096, 4096},
+		// 超过阈值的响应分块流式返回
+		{"/buffered/large", 10000, -1},
+		// 端点未启用时按原样流式返回
+		{"/plain/small", 3000, -1},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(srv.URL + tt.path + "?size=" + strconv.Itoa(tt.size))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(body) != tt.size {
+			t.Fatalf("%s: want %d bytes but got %d", tt.path, tt.size, len(body))
+		}
+		if resp.ContentLength != tt.contentLength {
+			t.Fatalf("%s: want Content-Length %d but got %d", tt.path, tt.contentLength, resp.ContentLength)
+		}
+		chunked := len(resp.TransferEncoding) > 0 && resp.TransferEncoding[0] == "chunked"
+		if chunked != (tt.contentLength < 0) {
+			t.Fatalf("%s: want chunked %v but got %v", tt.path, tt.contentLength < 0, resp.TransferEncoding)
+		}
+	}
+}
+
+func TestTenantRouting(t *testing.T) {
+	endpoint := func(path, backend string) *config.Endpoint {
+		return &config.Endpoint{
+			Protocol: config.Protocol_HTTP,
+			Path:     path,
+			Method:   "GET",
+			Backends: []*config.Backend{{Target: backend}},
+		}
+	}
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{
+			endpoint("/api/orders", "shared-orders"),
+			endpoint("/api/profile", "shared-profile"),
+		},
+		TenantRouting: &config.TenantRouting{
+			Domain: "example.com",
+			Tenants: map[string]*config.Tenant{
+				"acme":   {Endpoints: []*config.Endpoint{endpoint("/api/orders", "acme-orders")}},
+				"globex": {Endpoints: []*config.Endpoint{endpoint("/api/orders", "globex-orders")}},
+			},
+		},
+	}
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		backend := e.Backends[0].Target
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader(backend)),
+			}, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+
+	tests := []struct {
+		name   string
+		host   string
+		tenant string
+		path   string
+		want   string
+	}{
+		{"header", "gateway.local", "acme", "/api/orders", "acme-orders"},
+		{"subdomain", "globex.example.com:8080", "", "/api/orders", "globex-orders"},
+		{"nested subdomain", "api.acme.example.com", "", "/api/orders", "acme-orders"},
+		// 请求头优先于子域名
+		{"header over subdomain", "globex.example.com", "acme", "/api/orders", "acme-orders"},
+		// 租户没有配置的路径使用共享的端点
+		{"shared fallback", "gateway.local", "acme", "/api/profile", "shared-profile"},
+		{"unknown tenant", "initech.example.com", "", "/api/orders", "shared-orders"},
+		{"no tenant", "gateway.local", "", "/api/orders", "shared-orders"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		req.Host = tt.host
+		if tt.tenant != "" {
+			req.Header.Set("X-Tenant-Id", tt.tenant)
+		}
+		w := newResponseWriter()
+		p.ServeHTTP(w, req)
+		if w.statusCode != http.StatusOK || w.body.String() != tt.want {
+			t.Fatalf("%s: want %s but got %d %s", tt.name, tt.want, w.statusCode, w.body.String())
+		}
+	}
+	// 就绪信息统计租户的端点
+	if info := p.Readiness(); info.Endpoints != 4 {
+		t.Fatalf("want 4 endpoints but got %d", info.Endpoints)
+	}
+}
+
+func TestGRPCStatusDetails(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Middlewares: []*config.Middleware{{
+			Name: "requestid",
+		}},
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_GRPC,
+			Path:     "/helloworld.Greeter/SayHello",
+			Method:   "POST",
+		}},
+	}
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, client.ErrBackendRateLimited
+		}), nil
+	}
+	p, err := New(clientFactory, middleware.Create)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	defer func(name string) { gatewayName = name }(gatewayName)
+	gatewayName = "gateway-test-0"
+	req := httptest.NewRequest("POST", "/helloworld.Greeter/SayHello", nil)
+	req.Header.Set("Content-Type", "application/grpc")
+	req.Header.Set("X-Request-Id", "req-1514")
+	w := newResponseWriter()
+	p.ServeHTTP(w, req)
+	if w.statusCode != http.StatusOK || w.header.Get("Grpc-Status") != "14" {
+		t.Fatalf("want grpc status 14 but got %d %v", w.statusCode, w.header)
+	}
+
+	data, err := base64.RawStdEncoding.DecodeString(w.header.Get("Grpc-Status-Details-Bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := &spb.Status{}
+	if err := proto.Unmarshal(data, st); err != nil {
+		t.Fatal(err)
+	}
+	if st.Code != 14 || st.Message != client.ErrBackendRateLimited.Error() {
+		t.Fatalf("want code 14 with the error message but got %v", st)
+	}
+	if len(st.Details) != 2 {
+		t.Fatalf("want 2 details but got %v", st.Details)
+	}
+	errorInfo := &errdetails.ErrorInfo{}
+	if err := st.Details[0].UnmarshalTo(errorInfo); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"gateway":    "gateway-test-0",
+		"path":       "/helloworld.Greeter/SayHello",
+		"request_id": "req-1514",
+	}
+	if errorInfo.Reason != _reasonBackendRateLimited || errorInfo.Domain != _grpcErrorDomain || !reflect.DeepEqual(errorInfo.Metadata, want) {
+		t.Fatalf("want error info %v but got %v", want, errorInfo)
+	}
+	requestInfo := &errdetails.RequestInfo{}
+	if err := st.Details[1].UnmarshalTo(requestInfo); err != nil {
+		t.Fatal(err)
+	}
+	if requestInfo.RequestId != "req-1514" {
+		t.Fatalf("want request id req-1514 but got %v", requestInfo)
+	}
+}
+
+func TestVirtualHosts(t *testing.T) {
+	c := &config.Gateway{
+		Name:        "Test",
+		Middlewares: []*config.Middleware{{Name: "global"}},
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/*",
+			Backends: []*config.Backend{{Target: "shared"}},
+		}},
+		VirtualHosts: []*config.VirtualHost{{
+			PathPrefix:  "/app1/",
+			Middlewares: []*config.Middleware{{Name: "app1"}},
+			Backends:    []*config.Backend{{Target: "app1"}},
+			Endpoints: []*config.Endpoint{{
+				Protocol: config.Protocol_HTTP,
+				Path:     "/users",
+				Method:   "GET",
+			}, {
+				Protocol: config.Protocol_HTTP,
+				Path:     "/orders",
+				Method:   "GET",
+				Backends: []*config.Backend{{Target: "app1-orders"}},
+			}},
+		}, {
+			PathPrefix:  "/app2",
+			Middlewares: []*config.Middleware{{Name: "app2-auth"}, {Name: "app2-cache"}},
+			Backends:    []*config.Backend{{Target: "app2"}},
+		}},
+	}
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		backend := e.Backends[0].Target
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader(backend)),
+			}, nil
+		}), nil
+	}
+	// 每个中间件将自己的名称追加到响应头中
+	middlewareFactory := func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return middleware.Middleware(func(next http.RoundTripper) http.RoundTripper {
+			return middleware.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				resp, err := next.RoundTrip(req)
+				if err != nil {
+					return nil, err
+				}
+				resp.Header.Add("X-Middleware", c.Name)
+				return resp, nil
+			})
+		}), nil
+	}
+	p, err := New(clientFactory, middlewareFactory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path        string
+		backend     string
+		middlewares []string
+	}{
+		{"/app1/users", "app1", []string{"app1"}},
+		{"/app1/orders", "app1-orders", []string{"app1"}},
+		// 虚拟主机没有配置的路径使用共享的端点
+		{"/app1/unknown", "shared", []string{"global"}},
+		{"/app2/anything/below", "app2", []string{"app2-cache", "app2-auth"}},
+		{"/other", "shared", []string{"global"}},
+	}
+	for _, tt := range tests {
+		w := newResponseWriter()
+		p.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.statusCode != http.StatusOK || w.body.String() != tt.backend {
+			t.Fatalf("%s: want backend %s but got %d %s", tt.path, tt.backend, w.statusCode, w.body.String())
+		}
+		if got := w.header.Values("X-Middleware"); !reflect.DeepEqual(got, tt.middlewares) {
+			t.Fatalf("%s: want middlewares %v but got %v", tt.path, tt.middlewares, got)
+		}
+	}
+	// 就绪信息统计虚拟主机展开后的端点
+	if info := p.Readiness(); info.Endpoints != 4 {
+		t.Fatalf("want 4 endpoints but got %d", info.Endpoints)
+	}
+}
+
+func TestLatencySLO(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol:   config.Protocol_HTTP,
+			Path:       "/slo",
+			Method:     "GET",
+			LatencySlo: &config.LatencySLO{Threshold: durationpb.New(50 * time.Millisecond)},
+		}},
+	}
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			if req.Header.Get("X-Slow") != "" {
+				time.Sleep(80 * time.Millisecond)
+			}
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody}, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+	for i := 0; i < 5; i++ {
+		req := httptest.NewRequest("GET", "/slo", nil)
+		if i%2 == 1 {
+			req.Header.Set("X-Slow", "1")
+		}
+		p.ServeHTTP(newResponseWriter(), req)
+	}
+	labels := map[string]string{"path": "/slo"}
+	if got := metricValue(t, "go_gateway_slo_requests_total", labels); got != 5 {
+		t.Fatalf("want 5 requests but got %v", got)
+	}
+	if got := metricValue(t, "go_gateway_slo_violations_total", labels); got != 2 {
+		t.Fatalf("want 2 violations but got %v", got)
+	}
+	if got := metricValue(t, "go_gateway_slo_objective", labels); got != _defaultSLOObjective {
+		t.Fatalf("want the default objective but got %v", got)
+	}
+}
+
+func TestAutoOptions(t *testing.T) {
+	endpoint := func(method, path string) *config.Endpoint {
+		return &config.Endpoint{Protocol: config.Protocol_HTTP, Method: method, Path: path}
+	}
+	c := &config.Gateway{
+		Name:        "Test",
+		AutoOptions: true,
+		Endpoints: []*config.Endpoint{
+			endpoint("GET", "/items"),
+			endpoint("POST", "/items"),
+			endpoint("PUT", "/items/{id}"),
+			endpoint("DELETE", "/items/{id}"),
+			endpoint("", "/any"),
+		},
+	}
+	var forwarded atomic.Int64
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			forwarded.Add(1)
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody}, nil
+		}), nil
+	}
+	p := newTestProxy(t, clientFactory, c)
+	options := func(path string, preflight bool) *responseWriter {
+		req := httptest.NewRequest("OPTIONS", path, nil)
+		if preflight {
+			req.Header.Set("Origin", "https://example.com")
+			req.Header.Set("Access-Control-Request-Method", "POST")
+		}
+		w := newResponseWriter()
+		p.ServeHTTP(w, req)
+		return w
+	}
+
+	// Allow 响应头汇总了同一路径所有端点的请求方法
+	tests := []struct {
+		path  string
+		allow string
+	}{
+		{"/items", "GET, OPTIONS, POST"},
+		{"/items/42", "DELETE, OPTIONS, PUT"},
+	}
+	for _, tt := range tests {
+		w := options(tt.path, false)
+		if w.statusCode != http.StatusNoContent || w.header.Get("Allow") != tt.allow {
+			t.Fatalf("%s: want 204 with Allow %q but got %d %q", tt.path, tt.allow, w.statusCode, w.header.Get("Allow"))
+		}
+	}
+	if got := forwarded.Load(); got != 0 {
+		t.Fatalf("want no request forwarded but got %d", got)
+	}
+	// 预检请求、允许所有方法的端点和未匹配的路径不由网关直接响应
+	if w := options("/items", true); w.statusCode != http.StatusOK || forwarded.Load() != 1 {
+		t.Fatalf("want the preflight forwarded but got %d", w.statusCode)
+	}
+	if w := options("/any", false); w.statusCode != http.StatusOK || forwarded.Load() != 2 {
+		t.Fatalf("want the request forwarded but got %d", w.statusCode)
+	}
+	if w := options("/unknown", false); w.statusCode != http.StatusNotFound {
+		t.Fatalf("want 404 but got %d", w.statusCode)
+	}
+
+	// 未开启时 OPTIONS 请求转发给后端
+	c.AutoOptions = false
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	if w := options("/items", false); w.statusCode != http.StatusOK || forwarded.Load() != 3 {
+		t.Fatalf("want the request forwarded but got %d", w.statusCode)
+	}
+}
+
+func TestErrorBudget(t *testing.T) {
+	for _, action := range []config.ErrorBudget_Action{config.ErrorBudget_FALLBACK, config.ErrorBudget_SHED} {
+		t.Run(action.String(), func(t *testing.T) {
+			path := "/budget/" + strings.ToLower(action.String())
+			c := &config.Gateway{
+				Name: "Test",
+				Endpoints: []*config.Endpoint{{
+					Protocol: config.Protocol_HTTP,
+					Path:     path,
+					Method:   "GET",
+					Backends: []*config.Backend{{Target: "primary"}},
+					ErrorBudget: &config.ErrorBudget{
+						Budget:           0.5,
+						Window:           durationpb.New(200 * time.Millisecond),
+						MinRequests:      4,
+						Action:           action,
+						FallbackBackends: []*config.Backend{{Target: "fallback"}},
+					},
+				}},
+			}
+			var failing atomic.Bool
+			failing.Store(true)
+			clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+				target := e.Backends[0].Target
+				return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+					statusCode := http.StatusOK
+					if target == "primary" && failing.Load() {
+						statusCode = http.StatusInternalServerError
+					}
+					return &http.Response{StatusCode: statusCode, Header: http.Header{"X-Backend": {target}}, Body: http.NoBody}, nil
+				}), nil
+			}
+			p := newTestProxy(t, clientFactory, c)
+			do := func() *httptest.ResponseRecorder {
+				w := httptest.NewRecorder()
+				p.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+				return w
+			}
+			labels := map[string]string{"path": path}
+
+			// 失败的请求未达到最少请求数之前不执行动作
+			for i := 0; i < 4; i++ {
+				if w := do(); w.Code != http.StatusInternalServerError {
+					t.Fatalf("want the primary response but got %d", w.Code)
+				}
+			}
+			if got := metricValue(t, "go_gateway_error_budget_exhausted", labels); got != 0 {
+				t.Fatalf("want the budget not exhausted but got %v", got)
+			}
+			// 预算耗尽之后执行动作
+			w := do()
+			switch action {
+			case config.ErrorBudget_FALLBACK:
+				if w.Code != http.StatusOK || w.Header().Get("X-Backend") != "fallback" {
+					t.Fatalf("want the fallback response but got %d from %q", w.Code, w.Header().Get("X-Backend"))
+				}
+			case config.ErrorBudget_SHED:
+				if w.Code != http.StatusServiceUnavailable {
+					t.Fatalf("want the request shed but got %d", w.Code)
+				}
+			}
+			if got := metricValue(t, "go_gateway_error_budget_exhausted", labels); got != 1 {
+				t.Fatalf("want the budget exhausted but got %v", got)
+			}
+			// 失败的请求移出窗口之后撤销动作
+			failing.Store(false)
+			time.Sleep(250 * time.Millisecond)
+			if w := do(); w.Code != http.StatusOK || w.Header().Get("X-Backend") != "primary" {
+				t.Fatalf("want the primary response but got %d from %q", w.Code, w.Header().Get("X-Backend"))
+			}
+			if got := metricValue(t, "go_gateway_error_budget_exhausted", labels); got != 0 {
+				t.Fatalf("want the budget recovered but got %v", got)
+			}
+		})
+	}
+}
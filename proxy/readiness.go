@@ -0,0 +1,153 @@
+package proxy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/client"
+	"github.com/cnsync/kratos/log"
+	"google.golang.org/protobuf/proto"
+)
+
+// readinessPath 是就绪探针的路径，从环境变量 PROXY_READINESS_PATH 中读取，例如 /readyz，为空时不注册就绪探针，
+// 就绪探针先于端点注册，与之重叠的端点的 GET 请求会被就绪探针处理，详细的就绪信息只对受信任的来源开放
+var readinessPath = os.Getenv("PROXY_READINESS_PATH")
+
+func init() {
+	if readinessPath != "" && !strings.HasPrefix(readinessPath, "/") {
+		panic(fmt.Errorf("invalid PROXY_READINESS_PATH: %s", readinessPath))
+	}
+}
+
+// deployStage 是当前的部署阶段，从环境变量 DEPLOY_STAGE 中读取，例如 canary、prod
+var deployStage = os.Getenv("DEPLOY_STAGE")
+
+// ReadinessInfo 结构体定义了就绪探针返回的信息
+type ReadinessInfo struct {
+	// Ready 表示网关是否已加载配置并完成预热，且没有开始排空连接
+	Ready bool `json:"ready"`
+	// Warming 表示网关是否正在预热
+	Warming bool `json:"warming"`
+	// Draining 表示网关是否正在排空连接
+	Draining bool `json:"draining"`
+	// Stage 是当前的部署阶段
+	Stage string `json:"stage"`
+	// Version 是网关的版本号
+	Version string `json:"version"`
+	// BuildSHA 是构建时的 git commit
+	BuildSHA string `json:"build_sha"`
+	// ConfigName 是已加载配置的名称
+	ConfigName string `json:"config_name"`
+	// ConfigVersion 是已加载配置的版本
+	ConfigVersion string `json:"config_version"`
+	// ConfigHash 是已加载配置的 sha256 摘要
+	ConfigHash string `json:"config_hash"`
+	// Generation 是已加载配置的代数，每次配置更新成功后加一
+	Generation uint64 `json:"generation"`
+	// Endpoints 是已加载的端点数量
+	Endpoints int `json:"endpoints"`
+	// Nodes 是所有端点当前可用的节点数量之和
+	Nodes int64 `json:"nodes"`
+	// UnhealthyServices 是在注册发现服务中没有实例的关键服务
+	UnhealthyServices []string `json:"unhealthy_services,omitempty"`
+}
+
+// readinessState 结构体保存了最近一次成功更新的配置信息
+type readinessState struct {
+	// info 是不随请求变化的就绪信息
+	info ReadinessInfo
+	// clients 是最近一次更新时创建的所有客户端
+	clients []io.Closer
+	// criticalServices 是没有实例时网关不能就绪的服务
+	criticalServices []string
+}
+
+// configHash 函数计算网关配置的 sha256 摘要
+func configHash(c *config.Gateway) string {
+	// 使用确定性序列化，保证相同的配置得到相同的摘要
+	b, err := proto.MarshalOptions{Deterministic: true}.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// newReadinessState 函数根据网关配置、客户端列表和注册的端点数量创建一个新的 readinessState 实例，
+// 端点数量包括租户和虚拟主机的端点，不包括默认端点
+func newReadinessState(c *config.Gateway, clients []io.Closer, endpoints int) *readinessState {
+	return &readinessState{
+		info: ReadinessInfo{
+			Stage:         deployStage,
+			Version:       Version,
+			BuildSHA:      BuildSHA,
+			ConfigName:    c.Name,
+			ConfigVersion: c.Version,
+			ConfigHash:    configHash(c),
+			Endpoints:     endpoints,
+		},
+		clients:          clients,
+		criticalServices: c.CriticalServices,
+	}
+}
+
+// Readiness 方法返回当前的就绪信息
+func (p *Proxy) Readiness() ReadinessInfo {
+	warming := p.warming.Load()
+	draining := p.draining.Load()
+	state := p.readiness.Load()
+	if state == nil {
+		return ReadinessInfo{Warming: warming, Draining: draining, Stage: deployStage, Version: Version, BuildSHA: BuildSHA}
+	}
+	info := state.info
+	info.Warming = warming
+	info.Draining = draining
+	// 节点数量会随着服务发现动态变化，因此在每次请求时重新计算
+	for _, c := range state.clients {
+		if counter, ok := endpointClient(c).(client.NodeCounter); ok {
+			info.Nodes += counter.NodeCount()
+		}
+	}
+	// 关键服务在注册发现服务中没有实例或未被监控时，网关无法正常转发请求
+	for _, service := range state.criticalServices {
+		if n, _ := client.ServiceInstanceCount(service); n == 0 {
+			info.UnhealthyServices = append(info.UnhealthyServices, service)
+		}
+	}
+	info.Ready = !warming && !draining && len(info.UnhealthyServices) == 0
+	return info
+}
+
+// Drain 方法标记网关开始排空连接，之后就绪探针总是返回 503，使负载均衡器在连接关闭之前停止路由请求到网关，
+// 可以通过 server.OnDrain 注册
+func (p *Proxy) Drain(context.Context) error {
+	if !p.draining.Swap(true) {
+		log.Info("proxy draining, readiness probe reports unready")
+	}
+	return nil
+}
+
+// readinessHandler 方法处理就绪探针请求，未就绪时返回 503，
+// 详细的就绪信息包含构建和配置信息，只以 JSON 格式返回给受信任的来源，其他来源只得到状态码
+func (p *Proxy) readinessHandler(w http.ResponseWriter, r *http.Request) {
+	info := p.Readiness()
+	code := http.StatusOK
+	if !info.Ready {
+		code = http.StatusServiceUnavailable
+	}
+	if !isTrustedSource(r) {
+		w.WriteHeader(code)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(info)
+}
@@ -0,0 +1,33 @@
+package proxy
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+)
+
+// bufferResponse 函数缓冲没有 Content-Length 的响应体至多 threshold_bytes 字节，
+// 响应体在阈值以内时设置 Content-Length，超过阈值时已缓冲的部分和剩余的响应体一起分块流式返回。
+// 流式响应和带有 Trailer 的响应不会被缓冲
+func bufferResponse(b *config.ResponseBuffering, resp *http.Response) error {
+	threshold := b.GetThresholdBytes()
+	if threshold <= 0 || resp.Body == nil || resp.ContentLength >= 0 || len(resp.Trailer) > 0 || isStreamingResponse(resp) {
+		return nil
+	}
+	buf, err := io.ReadAll(io.LimitReader(resp.Body, threshold+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(buf)) > threshold {
+		resp.Body = &readCloser{Reader: io.MultiReader(bytes.NewReader(buf), resp.Body), Closer: resp.Body}
+		return nil
+	}
+	resp.ContentLength = int64(len(buf))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(buf)))
+	resp.Header.Del("Transfer-Encoding")
+	resp.Body = &readCloser{Reader: bytes.NewReader(buf), Closer: resp.Body}
+	return nil
+}
@@ -0,0 +1,56 @@
+package proxy
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+)
+
+// _bodyErrorResponseTooLarge 表示上游响应体超过了端点允许返回给客户端的大小
+const _bodyErrorResponseTooLarge = "response_too_large"
+
+// errResponseTooLarge 表示上游响应体超过了端点允许返回给客户端的大小
+var errResponseTooLarge = errors.New("upstream response body exceeds the endpoint limit")
+
+// readCloser 结构体组合了读取器和关闭器
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// limitResponse 函数按端点配置限制返回给客户端的响应体大小，超过大小且配置为拒绝时返回 errResponseTooLarge，
+// 没有 Content-Length 的响应会被缓冲至多 max_bytes 字节以判断是否超过大小
+func limitResponse(l *config.ResponseLimit, resp *http.Response) (bool, error) {
+	if l.GetMaxBytes() <= 0 || resp.Body == nil {
+		return false, nil
+	}
+	limit := l.MaxBytes
+	if l.Action == config.ResponseLimit_TRUNCATE {
+		oversized := resp.ContentLength > limit
+		if oversized {
+			resp.ContentLength = limit
+			resp.Header.Set("Content-Length", strconv.FormatInt(limit, 10))
+		}
+		resp.Body = &readCloser{Reader: io.LimitReader(resp.Body, limit), Closer: resp.Body}
+		return oversized, nil
+	}
+	if resp.ContentLength > limit {
+		return true, errResponseTooLarge
+	}
+	if resp.ContentLength >= 0 {
+		return false, nil
+	}
+	buf, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return false, err
+	}
+	if int64(len(buf)) > limit {
+		return true, errResponseTooLarge
+	}
+	resp.Body = &readCloser{Reader: io.MultiReader(bytes.NewReader(buf), resp.Body), Closer: resp.Body}
+	return false, nil
+}
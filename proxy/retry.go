@@ -2,19 +2,35 @@ package proxy
 
 import (
 	"context"
+	"fmt"
+	"math"
+	"math/rand"
 	"net/http"
+	"os"
 	"time"
 
 	config "github.com/cnsync/gateway/api/gateway/config/v1"
 	"github.com/cnsync/gateway/proxy/condition"
+	"github.com/cnsync/kratos/log"
 	"github.com/go-kratos/feature"
 )
 
 var (
 	// retryFeature 是一个注册的功能标志，用于表示是否启用重试功能
 	retryFeature = feature.MustRegister("gw:Retry", true)
+	// maxTimeout 是端点超时时间的全局上限，从环境变量 PROXY_MAX_TIMEOUT 中读取，为 0 时不限制
+	maxTimeout time.Duration
 )
 
+func init() {
+	var err error
+	if v := os.Getenv("PROXY_MAX_TIMEOUT"); v != "" {
+		if maxTimeout, err = time.ParseDuration(v); err != nil {
+			panic(err)
+		}
+	}
+}
+
 // retryStrategy 结构体定义了一个重试策略，包括尝试次数、总超时时间、每次尝试的超时时间和重试条件
 type retryStrategy struct {
 	// attempts 是重试尝试的总次数
@@ -25,6 +41,83 @@ type retryStrategy struct {
 	perTryTimeout time.Duration
 	// conditions 是重试条件的列表
 	conditions []condition.Condition
+	// backoff 是重试之间的退避策略，为 nil 时立即重试
+	backoff *retryBackoff
+}
+
+// retryBackoff 结构体定义了重试之间的指数退避，第 n 次重试的等待时间为 base * multiplier^(n-1)，
+// 不超过 max，并按 jitter 随机化
+type retryBackoff struct {
+	base       time.Duration
+	multiplier float64
+	max        time.Duration
+	jitter     config.RetryBackoff_Jitter
+	random     func() float64
+}
+
+// calcBackoff 函数用于解析端点配置中的重试退避策略，没有配置或基础等待时间为 0 时返回 nil
+func calcBackoff(endpoint *config.Endpoint) (*retryBackoff, error) {
+	if endpoint.Retry == nil || endpoint.Retry.Backoff == nil {
+		return nil, nil
+	}
+	c := endpoint.Retry.Backoff
+	if c.Base == nil || c.Base.AsDuration() <= 0 {
+		return nil, nil
+	}
+	b := &retryBackoff{
+		base:       c.Base.AsDuration(),
+		multiplier: 2,
+		jitter:     c.Jitter,
+		random:     rand.Float64,
+	}
+	if c.Multiplier != 0 {
+		if c.Multiplier < 1 {
+			return nil, fmt.Errorf("invalid retry backoff multiplier %v on endpoint: %s %s", c.Multiplier, endpoint.Method, endpoint.Path)
+		}
+		b.multiplier = c.Multiplier
+	}
+	if c.Max != nil {
+		b.max = c.Max.AsDuration()
+	}
+	return b, nil
+}
+
+// delay 方法返回第 retry 次重试之前的等待时间，retry 从 1 开始
+func (b *retryBackoff) delay(retry int) time.Duration {
+	d := float64(b.base) * math.Pow(b.multiplier, float64(retry-1))
+	if b.max > 0 && d > float64(b.max) {
+		d = float64(b.max)
+	}
+	switch b.jitter {
+	case config.RetryBackoff_FULL:
+		d = d * b.random()
+	case config.RetryBackoff_EQUAL:
+		d = d/2 + d/2*b.random()
+	}
+	return time.Duration(d)
+}
+
+// wait 方法在第 retry 次重试之前等待，等待会超过上下文的截止时间或者上下文在等待中结束时返回 false，
+// 此时应放弃重试并返回上一次尝试的结果
+func (b *retryBackoff) wait(ctx context.Context, retry int) (time.Duration, bool) {
+	if b == nil {
+		return 0, true
+	}
+	d := b.delay(retry)
+	if d <= 0 {
+		return 0, true
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= d {
+		return d, false
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return d, true
+	case <-ctx.Done():
+		return d, false
+	}
 }
 
 // calcTimeout 函数用于计算给定端点的超时时间
@@ -87,6 +180,8 @@ func prepareRetryStrategy(e *config.Endpoint) (*retryStrategy, error) {
 		// 计算每次尝试的超时时间
 		perTryTimeout: calcPerTryTimeout(e),
 	}
+	// 将超时时间限制在全局上限之内
+	clampTimeout(e, strategy)
 	// 解析重试条件
 	conditions, err := parseRetryConditon(e)
 	// 如果解析失败，返回错误
@@ -95,10 +190,30 @@ func prepareRetryStrategy(e *config.Endpoint) (*retryStrategy, error) {
 	}
 	// 设置重试条件
 	strategy.conditions = conditions
+	// 解析重试退避策略
+	if strategy.backoff, err = calcBackoff(e); err != nil {
+		return nil, err
+	}
 	// 返回重试策略和 nil 错误，表示成功
 	return strategy, nil
 }
 
+// clampTimeout 函数将重试策略的超时时间限制在全局上限 maxTimeout 之内，超出时打印警告日志
+func clampTimeout(e *config.Endpoint, strategy *retryStrategy) {
+	if maxTimeout <= 0 {
+		return
+	}
+	if strategy.timeout > maxTimeout {
+		log.Warnf("endpoint timeout clamped: [%s] %s %s %s > %s", e.Protocol, e.Method, e.Path, strategy.timeout, maxTimeout)
+		strategy.timeout = maxTimeout
+	}
+	// 每次尝试的超时时间同样不能超过全局上限
+	if strategy.perTryTimeout > maxTimeout {
+		log.Warnf("endpoint per-try timeout clamped: [%s] %s %s %s > %s", e.Protocol, e.Method, e.Path, strategy.perTryTimeout, maxTimeout)
+		strategy.perTryTimeout = maxTimeout
+	}
+}
+
 // parseRetryConditon 函数用于解析端点配置中的重试条件
 func parseRetryConditon(endpoint *config.Endpoint) ([]condition.Condition, error) {
 	// 如果端点没有配置重试策略，则返回一个空的条件列表和 nil 错误
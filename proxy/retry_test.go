@@ -1,10 +1,19 @@
 package proxy
 
 import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/client"
+	"github.com/cnsync/gateway/middleware"
+	"github.com/cnsync/gateway/middleware/logging"
+	"github.com/cnsync/kratos/log"
 	"google.golang.org/protobuf/types/known/durationpb"
 )
 
@@ -71,3 +80,158 @@ func TestCalcTimeout(t *testing.T) {
 		}
 	}
 }
+
+func TestMaxTimeout(t *testing.T) {
+	var buf bytes.Buffer
+	logger := log.GetLogger()
+	log.SetLogger(log.NewStdLogger(&buf))
+	defer log.SetLogger(logger)
+	defer func(old time.Duration) { maxTimeout = old }(maxTimeout)
+	maxTimeout = time.Second * 10
+
+	testCases := []struct {
+		endpoint      *config.Endpoint
+		timeout       time.Duration
+		perTryTimeout time.Duration
+		warned        bool
+	}{
+		{
+			endpoint: &config.Endpoint{
+				Path:    "/short",
+				Timeout: &durationpb.Duration{Seconds: 5},
+			},
+			timeout:       time.Second * 5,
+			perTryTimeout: time.Second * 5,
+		},
+		{
+			endpoint: &config.Endpoint{
+				Path:    "/long",
+				Timeout: &durationpb.Duration{Seconds: 60},
+				Retry:   &config.Retry{PerTryTimeout: &durationpb.Duration{Seconds: 30}},
+			},
+			timeout:       time.Second * 10,
+			perTryTimeout: time.Second * 10,
+			warned:        true,
+		},
+	}
+	for _, testCase := range testCases {
+		buf.Reset()
+		strategy, err := prepareRetryStrategy(testCase.endpoint)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strategy.timeout != testCase.timeout {
+			t.Errorf("%s: timeout = %v, want %v", testCase.endpoint.Path, strategy.timeout, testCase.timeout)
+		}
+		if strategy.perTryTimeout != testCase.perTryTimeout {
+			t.Errorf("%s: perTryTimeout = %v, want %v", testCase.endpoint.Path, strategy.perTryTimeout, testCase.perTryTimeout)
+		}
+		warned := strings.Contains(buf.String(), "timeout clamped") && strings.Contains(buf.String(), testCase.endpoint.Path)
+		if warned != testCase.warned {
+			t.Errorf("%s: warned = %v, want %v: %q", testCase.endpoint.Path, warned, testCase.warned, buf.String())
+		}
+	}
+}
+
+func TestRetryBackoffDelay(t *testing.T) {
+	testCases := []struct {
+		name    string
+		backoff *config.RetryBackoff
+		delays  []time.Duration
+	}{
+		{
+			name:    "default multiplier",
+			backoff: &config.RetryBackoff{Base: durationpb.New(10 * time.Millisecond)},
+			delays:  []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond},
+		},
+		{
+			name:    "capped",
+			backoff: &config.RetryBackoff{Base: durationpb.New(10 * time.Millisecond), Multiplier: 3, Max: durationpb.New(50 * time.Millisecond)},
+			delays:  []time.Duration{10 * time.Millisecond, 30 * time.Millisecond, 50 * time.Millisecond},
+		},
+		{
+			name:    "full jitter",
+			backoff: &config.RetryBackoff{Base: durationpb.New(10 * time.Millisecond), Jitter: config.RetryBackoff_FULL},
+			delays:  []time.Duration{2500 * time.Microsecond, 5 * time.Millisecond, 10 * time.Millisecond},
+		},
+		{
+			name:    "equal jitter",
+			backoff: &config.RetryBackoff{Base: durationpb.New(10 * time.Millisecond), Jitter: config.RetryBackoff_EQUAL},
+			delays:  []time.Duration{6250 * time.Microsecond, 12500 * time.Microsecond, 25 * time.Millisecond},
+		},
+	}
+	for _, testCase := range testCases {
+		b, err := calcBackoff(&config.Endpoint{Retry: &config.Retry{Backoff: testCase.backoff}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.random = func() float64 { return 0.25 }
+		for i, want := range testCase.delays {
+			if got := b.delay(i + 1); got != want {
+				t.Errorf("%s: delay of retry %d = %v, want %v", testCase.name, i+1, got, want)
+			}
+		}
+	}
+
+	if b, err := calcBackoff(&config.Endpoint{Retry: &config.Retry{Backoff: &config.RetryBackoff{}}}); err != nil || b != nil {
+		t.Fatalf("want no backoff without base, got %v, %v", b, err)
+	}
+	if _, err := calcBackoff(&config.Endpoint{Retry: &config.Retry{Backoff: &config.RetryBackoff{
+		Base:       durationpb.New(time.Millisecond),
+		Multiplier: 0.5,
+	}}}); err == nil {
+		t.Fatal("want error for a multiplier less than 1")
+	}
+}
+
+func TestRetryBackoffTimeout(t *testing.T) {
+	timeout := 300 * time.Millisecond
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/backoff",
+			Method:   "GET",
+			Timeout:  durationpb.New(timeout),
+			Retry: &config.Retry{
+				Attempts: 10,
+				Conditions: []*config.Condition{{
+					Condition: &config.Condition_ByStatusCode{ByStatusCode: "500-599"},
+				}},
+				Backoff: &config.RetryBackoff{Base: durationpb.New(50 * time.Millisecond)},
+			},
+		}},
+	}
+	var attempts int
+	clientFactory := func(*client.BuildContext, *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: io.NopCloser(strings.NewReader("unavailable"))}, nil
+		}), nil
+	}
+	middlewareFactory := func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return logging.Middleware(c)
+	}
+	p, err := New(clientFactory, middlewareFactory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	w := newResponseWriter()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/backoff", nil))
+	elapsed := time.Since(start)
+	// 重试之前依次等待 50ms 和 100ms，第三次重试需要等待 200ms，会超过总超时时间，因此放弃并返回上一次的响应
+	if attempts != 3 {
+		t.Fatalf("want 3 attempts but got %d", attempts)
+	}
+	if elapsed < 150*time.Millisecond || elapsed >= timeout {
+		t.Fatalf("want elapsed time between 150ms and %s but got %s", timeout, elapsed)
+	}
+	if w.statusCode != http.StatusServiceUnavailable || w.body.String() != "unavailable" {
+		t.Fatalf("want the last upstream response but got %d %q", w.statusCode, w.body.String())
+	}
+}
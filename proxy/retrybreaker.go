@@ -0,0 +1,190 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/go-kratos/aegis/circuitbreaker"
+	"github.com/go-kratos/aegis/circuitbreaker/sre"
+)
+
+const (
+	// _defaultRetryBreakerSuccess 是默认期望的重试成功率
+	_defaultRetryBreakerSuccess = 0.8
+	// _defaultRetryBreakerRequest 是默认开始丢弃重试之前窗口内的最少重试次数
+	_defaultRetryBreakerRequest = 100
+	// _defaultRetryBreakerWindow 是默认统计重试结果的滑动窗口
+	_defaultRetryBreakerWindow = 3 * time.Second
+)
+
+// retryBreaker 结构体包装了端点的重试熔断器，记录重置之后的统计，并支持通过管理接口重置
+type retryBreaker struct {
+	tenant string
+	method string
+	path   string
+	host   string
+
+	success float64
+	request int64
+	window  time.Duration
+
+	lock    sync.RWMutex
+	breaker circuitbreaker.CircuitBreaker
+	resetAt time.Time
+
+	allowed   atomic.Int64
+	rejected  atomic.Int64
+	succeeded atomic.Int64
+	failed    atomic.Int64
+}
+
+// newRetryBreaker 函数根据端点的重试配置创建一个重试熔断器
+func newRetryBreaker(tenant string, e *config.Endpoint) *retryBreaker {
+	b := &retryBreaker{
+		tenant:  tenant,
+		method:  e.Method,
+		path:    e.Path,
+		host:    e.Host,
+		success: _defaultRetryBreakerSuccess,
+		request: _defaultRetryBreakerRequest,
+		window:  _defaultRetryBreakerWindow,
+	}
+	if c := e.GetRetry().GetBreaker(); c != nil {
+		if c.Success > 0 && c.Success <= 1 {
+			b.success = c.Success
+		}
+		if c.Request > 0 {
+			b.request = c.Request
+		}
+		if c.Window != nil && c.Window.AsDuration() > 0 {
+			b.window = c.Window.AsDuration()
+		}
+	}
+	b.reset()
+	return b
+}
+
+// reset 方法用新的熔断器替换旧的熔断器，清空窗口内的统计
+func (b *retryBreaker) reset() {
+	breaker := sre.NewBreaker(sre.WithSuccess(b.success), sre.WithRequest(b.request), sre.WithWindow(b.window))
+	b.lock.Lock()
+	b.breaker = breaker
+	b.resetAt = time.Now()
+	b.allowed.Store(0)
+	b.rejected.Store(0)
+	b.succeeded.Store(0)
+	b.failed.Store(0)
+	b.lock.Unlock()
+}
+
+// current 方法返回当前的熔断器
+func (b *retryBreaker) current() circuitbreaker.CircuitBreaker {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+	return b.breaker
+}
+
+// Allow 方法判断是否允许重试
+func (b *retryBreaker) Allow() error {
+	if err := b.current().Allow(); err != nil {
+		b.rejected.Add(1)
+		return err
+	}
+	b.allowed.Add(1)
+	return nil
+}
+
+// MarkSuccess 方法记录一次成功的重试
+func (b *retryBreaker) MarkSuccess() {
+	b.succeeded.Add(1)
+	b.current().MarkSuccess()
+}
+
+// MarkFailed 方法记录一次失败的重试
+func (b *retryBreaker) MarkFailed() {
+	b.failed.Add(1)
+	b.current().MarkFailed()
+}
+
+// RetryBreakerStatus 结构体定义了重试熔断器的配置和重置之后的统计
+type RetryBreakerStatus struct {
+	Tenant    string    `json:"tenant,omitempty"`
+	Method    string    `json:"method"`
+	Path      string    `json:"path"`
+	Host      string    `json:"host,omitempty"`
+	Success   float64   `json:"success"`
+	Request   int64     `json:"request"`
+	Window    string    `json:"window"`
+	ResetAt   time.Time `json:"reset_at"`
+	Allowed   int64     `json:"allowed"`
+	Rejected  int64     `json:"rejected"`
+	Succeeded int64     `json:"succeeded"`
+	Failed    int64     `json:"failed"`
+}
+
+// status 方法返回熔断器的状态
+func (b *retryBreaker) status() RetryBreakerStatus {
+	b.lock.RLock()
+	resetAt := b.resetAt
+	b.lock.RUnlock()
+	return RetryBreakerStatus{
+		Tenant:    b.tenant,
+		Method:    b.method,
+		Path:      b.path,
+		Host:      b.host,
+		Success:   b.success,
+		Request:   b.request,
+		Window:    b.window.String(),
+		ResetAt:   resetAt,
+		Allowed:   b.allowed.Load(),
+		Rejected:  b.rejected.Load(),
+		Succeeded: b.succeeded.Load(),
+		Failed:    b.failed.Load(),
+	}
+}
+
+// matches 方法判断熔断器是否匹配管理接口的查询参数，未指定的参数匹配所有值
+func (b *retryBreaker) matches(r *http.Request) bool {
+	query := r.URL.Query()
+	for name, value := range map[string]string{"tenant": b.tenant, "method": b.method, "path": b.path, "host": b.host} {
+		if query.Has(name) && query.Get(name) != value {
+			return false
+		}
+	}
+	return true
+}
+
+// retryBreakersHandler 方法返回匹配的重试熔断器的状态，POST 请求时重置匹配的熔断器，
+// 查询参数 tenant、method、path、host 用于筛选端点
+func (p *Proxy) retryBreakersHandler(w http.ResponseWriter, r *http.Request) {
+	var breakers []*retryBreaker
+	if v := p.retryBreakers.Load(); v != nil {
+		breakers = *v
+	}
+	reset := r.Method == http.MethodPost
+	if !reset && r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	status := make([]RetryBreakerStatus, 0, len(breakers))
+	for _, b := range breakers {
+		if !b.matches(r) {
+			continue
+		}
+		if reset {
+			b.reset()
+		}
+		status = append(status, b.status())
+	}
+	if reset && len(status) == 0 {
+		http.Error(w, "retry breaker not found", http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(status)
+}
@@ -0,0 +1,199 @@
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/client"
+	"github.com/cnsync/kratos/log"
+	"google.golang.org/protobuf/proto"
+)
+
+// _minutesPerDay 是一天的分钟数
+const _minutesPerDay = 24 * 60
+
+// _weekdays 是计划时间窗口中星期的名称
+var _weekdays = map[string]time.Weekday{
+	"SUN": time.Sunday,
+	"MON": time.Monday,
+	"TUE": time.Tuesday,
+	"WED": time.Wednesday,
+	"THU": time.Thursday,
+	"FRI": time.Friday,
+	"SAT": time.Saturday,
+}
+
+// scheduleWindow 结构体是一个按星期和时刻重复的时间窗口
+type scheduleWindow struct {
+	// days 记录了窗口开始的星期
+	days [7]bool
+	// start 和 end 是窗口开始和结束的时刻，以一天中的分钟数表示
+	start, end int
+	location   *time.Location
+	notBefore  time.Time
+	notAfter   time.Time
+}
+
+// parseTimeOfDay 函数解析 HH:MM 格式的时刻，返回一天中的分钟数
+func parseTimeOfDay(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day: %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// newScheduleWindow 函数根据配置创建一个计划时间窗口
+func newScheduleWindow(c *config.ScheduleWindow) (*scheduleWindow, error) {
+	w := &scheduleWindow{location: time.UTC, end: _minutesPerDay}
+	if len(c.Days) == 0 {
+		for i := range w.days {
+			w.days[i] = true
+		}
+	}
+	for _, day := range c.Days {
+		d, ok := _weekdays[strings.ToUpper(day)]
+		if !ok {
+			return nil, fmt.Errorf("invalid day: %q", day)
+		}
+		w.days[d] = true
+	}
+	var err error
+	if c.Start != "" || c.End != "" {
+		if w.start, err = parseTimeOfDay(c.Start); err != nil {
+			return nil, err
+		}
+		if w.end, err = parseTimeOfDay(c.End); err != nil {
+			return nil, err
+		}
+	}
+	if c.Timezone != "" {
+		if w.location, err = time.LoadLocation(c.Timezone); err != nil {
+			return nil, err
+		}
+	}
+	if c.NotBefore != "" {
+		if w.notBefore, err = time.Parse(time.RFC3339, c.NotBefore); err != nil {
+			return nil, err
+		}
+	}
+	if c.NotAfter != "" {
+		if w.notAfter, err = time.Parse(time.RFC3339, c.NotAfter); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// active 方法判断时间窗口在给定的时间是否生效
+func (w *scheduleWindow) active(now time.Time) bool {
+	if !w.notBefore.IsZero() && now.Before(w.notBefore) {
+		return false
+	}
+	if !w.notAfter.IsZero() && !now.Before(w.notAfter) {
+		return false
+	}
+	now = now.In(w.location)
+	minute := now.Hour()*60 + now.Minute()
+	if w.start < w.end {
+		return w.days[now.Weekday()] && minute >= w.start && minute < w.end
+	}
+	// 跨越零点的窗口属于开始的那一天
+	if w.days[now.Weekday()] && minute >= w.start {
+		return true
+	}
+	return w.days[(now.Weekday()+6)%7] && minute < w.end
+}
+
+// backendSchedule 结构体是端点的一个计划路由，任一时间窗口生效时请求发往它的后端
+type backendSchedule struct {
+	name    string
+	windows []*scheduleWindow
+	client  client.Client
+}
+
+// active 方法判断计划路由在给定的时间是否生效
+func (s *backendSchedule) active(now time.Time) bool {
+	for _, w := range s.windows {
+		if w.active(now) {
+			return true
+		}
+	}
+	return false
+}
+
+// scheduledRouter 结构体按请求时间将请求发往第一个生效的计划路由的后端，没有生效的计划路由时发往端点的后端
+type scheduledRouter struct {
+	endpoint  *config.Endpoint
+	schedules []*backendSchedule
+	primary   http.RoundTripper
+	now       func() time.Time
+	// current 是最近一次使用的计划路由的名称，用于记录路由的切换
+	current atomic.Value
+}
+
+// newScheduledRouter 函数根据端点配置为每个计划路由创建客户端，返回的关闭器列表包括所有创建的客户端
+func newScheduledRouter(factory client.Factory, buildCtx *client.BuildContext, e *config.Endpoint, primary http.RoundTripper) (_ *scheduledRouter, closers []io.Closer, retError error) {
+	defer func() {
+		if retError != nil {
+			for _, c := range closers {
+				c.Close()
+			}
+		}
+	}()
+	r := &scheduledRouter{endpoint: e, primary: primary, now: time.Now}
+	r.current.Store("")
+	for i, c := range e.BackendSchedules {
+		s := &backendSchedule{name: c.Name}
+		if s.name == "" {
+			s.name = fmt.Sprintf("schedule-%d", i)
+		}
+		if len(c.Backends) == 0 {
+			return nil, closers, fmt.Errorf("no backends in schedule %s on endpoint: %s %s", s.name, e.Method, e.Path)
+		}
+		for _, wc := range c.Windows {
+			w, err := newScheduleWindow(wc)
+			if err != nil {
+				return nil, closers, fmt.Errorf("schedule %s on endpoint: %s %s: %w", s.name, e.Method, e.Path, err)
+			}
+			s.windows = append(s.windows, w)
+		}
+		se := proto.Clone(e).(*config.Endpoint)
+		se.Backends = c.Backends
+		se.BackendSchedules = nil
+		se.ErrorBudget = nil
+		sc, err := factory(buildCtx, se)
+		if err != nil {
+			return nil, closers, err
+		}
+		closers = append(closers, sc)
+		s.client = sc
+		r.schedules = append(r.schedules, s)
+	}
+	return r, closers, nil
+}
+
+// RoundTrip 方法将请求发往第一个生效的计划路由的后端，没有生效的计划路由时发往端点的后端
+func (r *scheduledRouter) RoundTrip(req *http.Request) (*http.Response, error) {
+	now := r.now()
+	next, name := r.primary, ""
+	for _, s := range r.schedules {
+		if s.active(now) {
+			next, name = s.client, s.name
+			break
+		}
+	}
+	if prev := r.current.Swap(name); prev != name {
+		if name != "" {
+			log.Infof("Routing endpoint: %s %s to the backends of schedule %s", r.endpoint.Method, r.endpoint.Path, name)
+		} else {
+			log.Infof("Routing endpoint: %s %s back to its backends from schedule %s", r.endpoint.Method, r.endpoint.Path, prev)
+		}
+	}
+	return next.RoundTrip(req)
+}
@@ -0,0 +1,88 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/client"
+)
+
+func TestScheduledRouting(t *testing.T) {
+	e := &config.Endpoint{
+		Protocol: config.Protocol_HTTP,
+		Path:     "/scheduled",
+		Method:   "GET",
+		Backends: []*config.Backend{{Target: "default"}},
+		BackendSchedules: []*config.BackendSchedule{{
+			Name: "maintenance",
+			// 周六 22:00 到周日 02:00 的维护窗口，按上海时间
+			Windows:  []*config.ScheduleWindow{{Days: []string{"SAT"}, Start: "22:00", End: "02:00", Timezone: "Asia/Shanghai"}},
+			Backends: []*config.Backend{{Target: "maintenance"}},
+		}, {
+			Name: "cutover",
+			// 切换之后的所有请求发往新的后端
+			Windows:  []*config.ScheduleWindow{{NotBefore: "2024-06-10T00:00:00+08:00"}},
+			Backends: []*config.Backend{{Target: "new"}},
+		}},
+	}
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		target := e.Backends[0].Target
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"X-Backend": {target}}, Body: http.NoBody}, nil
+		}), nil
+	}
+	primary, err := clientFactory(nil, e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, closers, err := newScheduledRouter(clientFactory, nil, e, primary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(closers) != 2 {
+		t.Fatalf("want 2 schedule clients but got %d", len(closers))
+	}
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		now  string
+		want string
+	}{
+		{"2024-06-08 21:59", "default"},
+		{"2024-06-08 22:00", "maintenance"},
+		{"2024-06-08 23:59", "maintenance"},
+		{"2024-06-09 01:59", "maintenance"},
+		{"2024-06-09 02:00", "default"},
+		{"2024-06-09 22:00", "default"},
+		{"2024-06-09 23:59", "default"},
+		{"2024-06-10 00:00", "new"},
+		// 先配置的计划路由优先
+		{"2024-06-15 22:30", "maintenance"},
+		{"2024-06-16 02:00", "new"},
+	}
+	for _, tt := range tests {
+		now, err := time.ParseInLocation("2006-01-02 15:04", tt.now, shanghai)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.now = func() time.Time { return now }
+		resp, err := r.RoundTrip(httptest.NewRequest("GET", "/scheduled", nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resp.Header.Get("X-Backend"); got != tt.want {
+			t.Fatalf("%s: want backend %s but got %s", tt.now, tt.want, got)
+		}
+	}
+
+	// 无效的时间窗口在构建端点时返回错误
+	e.BackendSchedules[0].Windows[0].Days = []string{"SATURDAY"}
+	if _, _, err := newScheduledRouter(clientFactory, nil, e, primary); err == nil {
+		t.Fatal("want an error for the invalid day")
+	}
+}
@@ -0,0 +1,79 @@
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/router"
+)
+
+// trailingSlashPolicy 是全局的尾部斜杠处理策略，从环境变量 TRAILING_SLASH_POLICY 中读取，可选值为 exact、transparent、redirect
+var trailingSlashPolicy = config.TrailingSlash_TRAILING_SLASH_EXACT
+
+func init() {
+	if v := os.Getenv("TRAILING_SLASH_POLICY"); v != "" {
+		policy, ok := config.TrailingSlash_value["TRAILING_SLASH_"+strings.ToUpper(v)]
+		if !ok || policy == int32(config.TrailingSlash_TRAILING_SLASH_UNSPECIFIED) {
+			panic(fmt.Errorf("invalid TRAILING_SLASH_POLICY: %s", v))
+		}
+		trailingSlashPolicy = config.TrailingSlash(policy)
+	}
+}
+
+// endpointTrailingSlash 返回端点生效的尾部斜杠处理策略，端点未配置时使用全局策略
+func endpointTrailingSlash(e *config.Endpoint) config.TrailingSlash {
+	if e.TrailingSlash == config.TrailingSlash_TRAILING_SLASH_UNSPECIFIED {
+		return trailingSlashPolicy
+	}
+	return e.TrailingSlash
+}
+
+// toggleTrailingSlash 返回路径添加或去掉尾部斜杠后的形式
+func toggleTrailingSlash(p string) string {
+	if strings.HasSuffix(p, "/") {
+		return strings.TrimSuffix(p, "/")
+	}
+	return p + "/"
+}
+
+// alternatePattern 返回端点路径另一种斜杠形式的路由模式，根路径和前缀匹配的路径没有另一种形式
+func alternatePattern(pattern string) (string, bool) {
+	if pattern == "" || pattern == "/" || strings.HasSuffix(pattern, "*") {
+		return "", false
+	}
+	return toggleTrailingSlash(pattern), true
+}
+
+// redirectTrailingSlash 将请求重定向到端点路径的斜杠形式，GET 和 HEAD 请求使用 301，其他请求使用 308 以保留请求方法和请求体
+func redirectTrailingSlash(w http.ResponseWriter, req *http.Request) {
+	u := *req.URL
+	u.Path = toggleTrailingSlash(u.Path)
+	u.RawPath = ""
+	code := http.StatusMovedPermanently
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		code = http.StatusPermanentRedirect
+	}
+	http.Redirect(w, req, u.String(), code)
+}
+
+// handleTrailingSlash 根据端点的尾部斜杠处理策略注册另一种斜杠形式的路由，
+// 必须在所有端点注册之后调用，以保证显式配置的端点优先匹配，opts 是注册端点路由时的附加匹配条件
+func handleTrailingSlash(r router.Router, e *config.Endpoint, handler http.Handler, opts []router.HandleOption) error {
+	pattern, ok := alternatePattern(e.Path)
+	if !ok {
+		return nil
+	}
+	switch endpointTrailingSlash(e) {
+	case config.TrailingSlash_TRAILING_SLASH_TRANSPARENT:
+		// 端点的关闭器已经注册过，这里不再重复注册
+		return r.Handle(pattern, e.Method, e.Host, handler, io.NopCloser(nil), opts...)
+	case config.TrailingSlash_TRAILING_SLASH_REDIRECT:
+		return r.Handle(pattern, e.Method, e.Host, http.HandlerFunc(redirectTrailingSlash), io.NopCloser(nil), opts...)
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,73 @@
+package proxy
+
+import (
+	"net/http"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// _defaultSLOObjective 是默认期望满足延迟目标的请求比例
+const _defaultSLOObjective = 0.99
+
+var (
+	// _metricSLORequests 是一个计数器，用于记录配置了延迟目标的请求总数
+	_metricSLORequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "slo_requests_total",
+		Help:      "Total requests of the endpoints with a latency SLO",
+	}, []string{"protocol", "method", "path", "service", "basePath"})
+	// _metricSLOViolations 是一个计数器，用于记录超过延迟目标的请求数量
+	_metricSLOViolations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "slo_violations_total",
+		Help:      "Total requests slower than the latency SLO",
+	}, []string{"protocol", "method", "path", "service", "basePath"})
+	// _metricSLOObjective 是一个仪表，记录端点期望满足延迟目标的请求比例，用于计算错误预算的消耗速度，
+	// 端点可以匹配多个请求方法，因此没有 method 标签
+	_metricSLOObjective = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "go",
+		Subsystem: "gateway",
+		Name:      "slo_objective",
+		Help:      "The ratio of requests expected to meet the latency SLO",
+	}, []string{"protocol", "path", "service", "basePath"})
+)
+
+func init() {
+	prometheus.MustRegister(_metricSLORequests)
+	prometheus.MustRegister(_metricSLOViolations)
+	prometheus.MustRegister(_metricSLOObjective)
+}
+
+// latencySLO 结构体是端点的延迟目标
+type latencySLO struct {
+	threshold time.Duration
+}
+
+// newLatencySLO 函数根据端点配置创建延迟目标并导出目标比例，未配置或指标被禁用时返回 nil
+func newLatencySLO(e *config.Endpoint, labels *metricsLabels) *latencySLO {
+	c := e.LatencySlo
+	if c == nil || c.Threshold == nil || c.Threshold.AsDuration() <= 0 || e.Metrics.GetDisableAll() {
+		return nil
+	}
+	objective := c.Objective
+	if objective <= 0 || objective >= 1 {
+		objective = _defaultSLOObjective
+	}
+	_metricSLOObjective.WithLabelValues(labels.Protocol(), labels.Path(), labels.Service(), labels.BasePath()).Set(objective)
+	return &latencySLO{threshold: c.Threshold.AsDuration()}
+}
+
+// observe 方法记录一次请求的耗时是否满足延迟目标
+func (s *latencySLO) observe(req *http.Request, labels *metricsLabels, elapsed time.Duration) {
+	if s == nil {
+		return
+	}
+	_metricSLORequests.WithLabelValues(labels.Protocol(), req.Method, labels.Path(), labels.Service(), labels.BasePath()).Inc()
+	if elapsed > s.threshold {
+		_metricSLOViolations.WithLabelValues(labels.Protocol(), req.Method, labels.Path(), labels.Service(), labels.BasePath()).Inc()
+	}
+}
@@ -0,0 +1,59 @@
+package proxy
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+)
+
+// _sniffLen 是检测内容类型时最多使用的字节数，与 http.DetectContentType 一致
+const _sniffLen = 512
+
+// errorReader 结构体在读取时返回固定的错误
+type errorReader struct {
+	err error
+}
+
+// Read 方法返回固定的错误
+func (r errorReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+// sniffContentType 函数在上游响应没有 Content-Type 时根据响应体的开头检测内容类型并设置响应头。
+// 它只等待上游的第一次读取而不是读满检测长度，使流式响应不会因为检测而被缓冲，
+// 已读取的数据和读取时的错误会在复制响应体时原样返回
+func sniffContentType(resp *http.Response) {
+	if _, ok := resp.Header["Content-Type"]; ok || resp.Body == nil || resp.ContentLength == 0 {
+		return
+	}
+	// 压缩后的响应体无法检测出原始的内容类型
+	if resp.Header.Get("Content-Encoding") != "" {
+		return
+	}
+	switch {
+	case resp.StatusCode >= 100 && resp.StatusCode <= 199,
+		resp.StatusCode == http.StatusNoContent,
+		resp.StatusCode == http.StatusNotModified:
+		return
+	}
+	buf := make([]byte, _sniffLen)
+	var (
+		n   int
+		err error
+	)
+	// 读取器可能返回 0 字节和 nil 错误，此时继续读取
+	for n == 0 && err == nil {
+		n, err = resp.Body.Read(buf)
+	}
+	if n > 0 {
+		resp.Header.Set("Content-Type", http.DetectContentType(buf[:n]))
+	}
+	rest := resp.Body
+	switch {
+	case err == io.EOF:
+		rest = http.NoBody
+	case err != nil:
+		rest = io.NopCloser(errorReader{err: err})
+	}
+	resp.Body = &readCloser{Reader: io.MultiReader(bytes.NewReader(buf[:n]), rest), Closer: resp.Body}
+}
@@ -0,0 +1,99 @@
+package proxy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+)
+
+// _weightMetadataKey 是端点元数据中的流量权重，路由相同且带有权重的端点按权重分配流量
+const _weightMetadataKey = "weight"
+
+// splitKey 是分配流量的端点的路由，路由相同的端点属于同一个流量分配
+type splitKey struct {
+	tenant      string
+	virtualHost *config.VirtualHost
+	host        string
+	method      string
+	path        string
+}
+
+// endpointWeight 函数返回端点元数据中的流量权重，没有权重时返回 false
+func endpointWeight(e *config.Endpoint) (float64, bool, error) {
+	v, ok := e.Metadata[_weightMetadataKey]
+	if !ok {
+		return 0, false, nil
+	}
+	weight, err := strconv.ParseFloat(v, 64)
+	if err != nil || weight < 0 {
+		return 0, false, fmt.Errorf("invalid weight %q on endpoint: %s %s", v, e.Method, e.Path)
+	}
+	return weight, true, nil
+}
+
+// weightedEndpoint 结构体是流量分配中的一个端点
+type weightedEndpoint struct {
+	handler http.Handler
+	weight  float64
+	closer  io.Closer
+}
+
+// trafficSplit 结构体按权重将请求随机分配给路由相同的多个端点，每个端点独立记录指标，
+// 可以通过元数据中的 service 区分各个端点的指标。端点按配置的顺序排列，权重为 0 的端点不接收请求，
+// 所有端点的权重都为 0 时第一个端点接收所有请求
+type trafficSplit struct {
+	endpoints []weightedEndpoint
+	total     float64
+	random    func() float64
+}
+
+// newTrafficSplit 函数创建一个空的流量分配
+func newTrafficSplit() *trafficSplit {
+	return &trafficSplit{random: rand.Float64}
+}
+
+// add 方法将一个端点加入流量分配
+func (s *trafficSplit) add(handler http.Handler, weight float64, closer io.Closer) {
+	s.endpoints = append(s.endpoints, weightedEndpoint{handler: handler, weight: weight, closer: closer})
+	s.total += weight
+}
+
+// pick 方法按权重选择一个端点
+func (s *trafficSplit) pick() http.Handler {
+	if s.total <= 0 {
+		return s.endpoints[0].handler
+	}
+	r := s.random() * s.total
+	last := 0
+	for i, e := range s.endpoints {
+		if e.weight <= 0 {
+			continue
+		}
+		if r < e.weight {
+			return e.handler
+		}
+		r -= e.weight
+		last = i
+	}
+	// 浮点数误差使随机数超出总权重时选择最后一个权重不为 0 的端点
+	return s.endpoints[last].handler
+}
+
+// ServeHTTP 方法将请求交给按权重选择的端点处理
+func (s *trafficSplit) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	s.pick().ServeHTTP(w, req)
+}
+
+// Close 方法关闭流量分配中所有端点的客户端和中间件实例
+func (s *trafficSplit) Close() error {
+	errs := make([]error, 0, len(s.endpoints))
+	for _, e := range s.endpoints {
+		errs = append(errs, e.closer.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,137 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/client"
+	"github.com/cnsync/gateway/middleware"
+	"github.com/cnsync/gateway/middleware/logging"
+)
+
+type countingCloser struct {
+	RoundTripperCloserFunc
+	closed *atomic.Int32
+}
+
+func (c countingCloser) Close() error {
+	c.closed.Add(1)
+	return nil
+}
+
+func TestTrafficSplitPick(t *testing.T) {
+	handler := func(name string) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("X-Endpoint", name)
+		})
+	}
+	pick := func(s *trafficSplit, r float64) string {
+		s.random = func() float64 { return r }
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		return w.Header().Get("X-Endpoint")
+	}
+	s := newTrafficSplit()
+	s.add(handler("zero"), 0, nil)
+	s.add(handler("stable"), 3, nil)
+	s.add(handler("canary"), 1, nil)
+	tests := []struct {
+		random float64
+		want   string
+	}{
+		{0, "stable"},
+		{0.74, "stable"},
+		{0.75, "canary"},
+		{0.9999, "canary"},
+	}
+	for _, tt := range tests {
+		if got := pick(s, tt.random); got != tt.want {
+			t.Fatalf("%v: want %s but got %s", tt.random, tt.want, got)
+		}
+	}
+	// 所有端点的权重都为 0 时第一个端点接收所有请求
+	s = newTrafficSplit()
+	s.add(handler("first"), 0, nil)
+	s.add(handler("second"), 0, nil)
+	if got := pick(s, 0.9); got != "first" {
+		t.Fatalf("want the first endpoint but got %s", got)
+	}
+}
+
+func TestTrafficSplit(t *testing.T) {
+	stable := &config.Endpoint{
+		Protocol: config.Protocol_HTTP,
+		Path:     "/split",
+		Method:   "GET",
+		Metadata: map[string]string{"weight": "0", "service": "split-stable"},
+		Backends: []*config.Backend{{Target: "stable"}},
+	}
+	canary := &config.Endpoint{
+		Protocol: config.Protocol_HTTP,
+		Path:     "/split",
+		Method:   "GET",
+		Metadata: map[string]string{"weight": "1", "service": "split-canary"},
+		Backends: []*config.Backend{{Target: "canary"}},
+	}
+	closed := map[string]*atomic.Int32{"stable": {}, "canary": {}}
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		target := e.Backends[0].Target
+		return countingCloser{
+			RoundTripperCloserFunc: func(req *http.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Header: http.Header{"X-Backend": {target}}, Body: http.NoBody}, nil
+			},
+			closed: closed[target],
+		}, nil
+	}
+	middlewareFactory := func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return logging.Middleware(c)
+	}
+	p, err := New(clientFactory, middlewareFactory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &config.Gateway{Name: "Test", Endpoints: []*config.Endpoint{stable, canary}}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, httptest.NewRequest("GET", "/split", nil))
+		if got := w.Header().Get("X-Backend"); got != "canary" {
+			t.Fatalf("want the request sent to the canary but got %q", got)
+		}
+	}
+	// 每个端点独立记录指标
+	if got := metricValue(t, "go_gateway_requests_code_total", map[string]string{"path": "/split", "service": "split-canary"}); got != 3 {
+		t.Fatalf("want 3 requests counted for the canary but got %v", got)
+	}
+
+	// 移除金丝雀端点之后旧的端点全部关闭
+	c = &config.Gateway{Name: "Test", Endpoints: []*config.Endpoint{stable}}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	// 旧的路由器在后台关闭
+	deadline := time.Now().Add(5 * time.Second)
+	for closed["canary"].Load() != 1 || closed["stable"].Load() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("want the old endpoints closed but got canary %d stable %d", closed["canary"].Load(), closed["stable"].Load())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	w := httptest.NewRecorder()
+	p.ServeHTTP(w, httptest.NewRequest("GET", "/split", nil))
+	if got := w.Header().Get("X-Backend"); got != "stable" {
+		t.Fatalf("want the request sent to the stable endpoint but got %q", got)
+	}
+
+	// 无效的权重在构建时返回错误
+	stable.Metadata["weight"] = "-1"
+	if err := p.Update(client.NewBuildContext(c), c); err == nil {
+		t.Fatal("want an error for the negative weight")
+	}
+}
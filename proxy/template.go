@@ -0,0 +1,31 @@
+package proxy
+
+import (
+	"net/http"
+	"os"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/trust"
+)
+
+// routeTemplateHeader 是暴露匹配到的路由模板的响应头，从环境变量 PROXY_ROUTE_TEMPLATE_HEADER 中读取，为空时不暴露
+var routeTemplateHeader = os.Getenv("PROXY_ROUTE_TEMPLATE_HEADER")
+
+// isTrustedSource 函数判断请求是否来自受信任的来源，对端以及经过的每一个代理都需要属于 trust.Networks
+func isTrustedSource(req *http.Request) bool {
+	return trust.IsTrusted(req)
+}
+
+// exposeRouteTemplate 函数向受信任的来源暴露匹配到的路由模板，即配置中的 path 而不是具体的请求路径，
+// 需要在设置 X-Forwarded-For 之前调用
+func exposeRouteTemplate(w http.ResponseWriter, req *http.Request, e *config.Endpoint) {
+	if routeTemplateHeader == "" || !isTrustedSource(req) {
+		return
+	}
+	template := e.Path
+	// 默认端点没有配置 path，使用兜底路由的模式
+	if template == "" {
+		template = _catchAllPattern
+	}
+	w.Header().Set(routeTemplateHeader, template)
+}
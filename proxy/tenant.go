@@ -0,0 +1,120 @@
+package proxy
+
+import (
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/router"
+)
+
+// _defaultTenantHeader 是默认携带租户 ID 的请求头
+const _defaultTenantHeader = "X-Tenant-Id"
+
+// tenantResolver 结构体根据请求头或子域名解析请求所属的租户
+type tenantResolver struct {
+	// header 是携带租户 ID 的请求头
+	header string
+	// suffix 是解析子域名时的域名后缀，为空时不解析子域名
+	suffix string
+}
+
+// newTenantResolver 根据配置创建一个租户解析器，未配置租户时返回 nil
+func newTenantResolver(c *config.TenantRouting) *tenantResolver {
+	if len(c.GetTenants()) == 0 {
+		return nil
+	}
+	r := &tenantResolver{header: c.Header}
+	if r.header == "" {
+		r.header = _defaultTenantHeader
+	}
+	if c.Domain != "" {
+		r.suffix = "." + strings.ToLower(strings.Trim(c.Domain, "."))
+	}
+	return r
+}
+
+// resolve 方法返回请求所属的租户，请求头优先于子域名，都无法解析时返回空字符串
+func (r *tenantResolver) resolve(req *http.Request) string {
+	if tenant := req.Header.Get(r.header); tenant != "" {
+		return tenant
+	}
+	if r.suffix == "" {
+		return ""
+	}
+	host := req.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	sub, ok := strings.CutSuffix(strings.ToLower(host), r.suffix)
+	if !ok || sub == "" {
+		return ""
+	}
+	// 多级子域名时使用紧邻域名的一级，例如 api.acme.example.com 的租户为 acme
+	if i := strings.LastIndexByte(sub, '.'); i >= 0 {
+		sub = sub[i+1:]
+	}
+	return sub
+}
+
+// apply 方法将请求所属的租户写入请求上下文，使路由器优先匹配租户的路由
+func (r *tenantResolver) apply(req *http.Request) *http.Request {
+	tenant := r.resolve(req)
+	if tenant == "" {
+		return req
+	}
+	return req.WithContext(router.NewTenantContext(req.Context(), tenant))
+}
+
+// tenantEndpoint 结构体是一个端点及其所属的租户，共享的端点不属于任何租户，
+// 虚拟主机的端点使用虚拟主机的中间件
+type tenantEndpoint struct {
+	tenant      string
+	virtualHost *config.VirtualHost
+	endpoint    *config.Endpoint
+}
+
+// routeEndpoints 函数返回需要注册的所有端点，租户的端点按租户 ID 排序排在最前面，
+// 其次是虚拟主机的端点，最后是共享的端点，使租户和虚拟主机的路由优先于共享的路由匹配
+func routeEndpoints(c *config.Gateway) []tenantEndpoint {
+	tenants := c.TenantRouting.GetTenants()
+	ids := make([]string, 0, len(tenants))
+	for id := range tenants {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	var out []tenantEndpoint
+	for _, id := range ids {
+		for _, e := range tenants[id].GetEndpoints() {
+			out = append(out, tenantEndpoint{tenant: id, endpoint: e})
+		}
+	}
+	for _, vh := range c.VirtualHosts {
+		for _, e := range virtualHostEndpoints(vh) {
+			out = append(out, tenantEndpoint{virtualHost: vh, endpoint: e})
+		}
+	}
+	for _, e := range c.Endpoints {
+		out = append(out, tenantEndpoint{endpoint: e})
+	}
+	return out
+}
+
+// middlewares 方法返回端点使用的全局中间件，虚拟主机的端点只使用虚拟主机的中间件
+func (te tenantEndpoint) middlewares(c *config.Gateway) []*config.Middleware {
+	if te.virtualHost != nil {
+		return te.virtualHost.Middlewares
+	}
+	return c.Middlewares
+}
+
+// handleOptions 方法返回注册端点路由时的附加匹配条件
+func (te tenantEndpoint) handleOptions() []router.HandleOption {
+	opts := endpointHandleOptions(te.endpoint)
+	if te.tenant != "" {
+		opts = append(opts, router.WithTenant(te.tenant))
+	}
+	return opts
+}
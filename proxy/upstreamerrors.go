@@ -0,0 +1,103 @@
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"syscall"
+
+	"github.com/cnsync/gateway/client"
+	"github.com/cnsync/kratos/selector"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// 上游请求失败的分类
+const (
+	// _upstreamErrorTimeout 表示请求超时
+	_upstreamErrorTimeout = "timeout"
+	// _upstreamErrorCanceled 表示请求被取消，通常是客户端断开了连接
+	_upstreamErrorCanceled = "canceled"
+	// _upstreamErrorRefused 表示上游拒绝了连接
+	_upstreamErrorRefused = "connection_refused"
+	// _upstreamErrorReset 表示连接被上游重置或关闭
+	_upstreamErrorReset = "connection_reset"
+	// _upstreamErrorDNS 表示解析上游地址失败
+	_upstreamErrorDNS = "dns"
+	// _upstreamErrorTLS 表示与上游的 TLS 握手或证书校验失败
+	_upstreamErrorTLS = "tls"
+	// _upstreamErrorTLSRenegotiation 表示上游请求了不被允许的 TLS 重新协商
+	_upstreamErrorTLSRenegotiation = "tls_renegotiation"
+	// _upstreamErrorProtocol 表示上游的协议不符合预期，例如 gRPC 上游不支持 HTTP/2
+	_upstreamErrorProtocol = "protocol"
+	// _upstreamErrorNoNode 表示没有可用的上游节点
+	_upstreamErrorNoNode = "no_available_node"
+	// _upstreamErrorRateLimited 表示请求超过了后端的出站速率限制
+	_upstreamErrorRateLimited = "rate_limited"
+	// _upstreamErrorOther 表示其他错误
+	_upstreamErrorOther = "other"
+)
+
+// _metricUpstreamErrors 是一个计数器，用于按分类记录请求上游失败的次数
+var _metricUpstreamErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "upstream_errors_total",
+	Help:      "Total upstream request failures by error class",
+}, []string{"protocol", "method", "path", "service", "basePath", "class"})
+
+func init() {
+	prometheus.MustRegister(_metricUpstreamErrors)
+}
+
+// classifyUpstreamError 函数将请求上游返回的错误分类
+func classifyUpstreamError(err error) string {
+	var (
+		dnsErr     *net.DNSError
+		netErr     net.Error
+		recordErr  tls.RecordHeaderError
+		alertErr   tls.AlertError
+		certErr    *tls.CertificateVerificationError
+		unknownCA  x509.UnknownAuthorityError
+		hostErr    x509.HostnameError
+		invalidErr x509.CertificateInvalidError
+	)
+	switch {
+	case errors.Is(err, context.Canceled):
+		return _upstreamErrorCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return _upstreamErrorTimeout
+	case errors.Is(err, selector.ErrNoAvailable):
+		return _upstreamErrorNoNode
+	case errors.Is(err, client.ErrBackendRateLimited):
+		return _upstreamErrorRateLimited
+	case errors.Is(err, client.ErrHTTP2Unsupported):
+		return _upstreamErrorProtocol
+	case errors.Is(err, client.ErrTLSRenegotiation):
+		return _upstreamErrorTLSRenegotiation
+	case errors.As(err, &dnsErr):
+		return _upstreamErrorDNS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return _upstreamErrorRefused
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return _upstreamErrorReset
+	case errors.As(err, &recordErr), errors.As(err, &alertErr), errors.As(err, &certErr),
+		errors.As(err, &unknownCA), errors.As(err, &hostErr), errors.As(err, &invalidErr):
+		return _upstreamErrorTLS
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return _upstreamErrorTimeout
+	}
+	return _upstreamErrorOther
+}
+
+// upstreamErrorsIncr 按分类增加请求上游失败的指标。
+func upstreamErrorsIncr(req *http.Request, labels *metricsLabels, err error) {
+	if labels.disableUpstreamErrors {
+		return
+	}
+	_metricUpstreamErrors.WithLabelValues(labels.Protocol(), req.Method, labels.Path(), labels.Service(), labels.BasePath(), classifyUpstreamError(err)).Inc()
+}
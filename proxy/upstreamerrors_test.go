@@ -0,0 +1,116 @@
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/gateway/client"
+	"github.com/cnsync/gateway/middleware"
+	"github.com/cnsync/gateway/middleware/logging"
+	"github.com/cnsync/kratos/selector"
+)
+
+// timeoutError 是一个超时的网络错误
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestClassifyUpstreamError(t *testing.T) {
+	// 连接一个已关闭的端口得到真实的连接拒绝错误
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+	_, refused := http.Get("http://" + addr)
+
+	opErr := func(err error) error {
+		return &url.Error{Op: "Get", URL: "http://backend", Err: &net.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", err)}}
+	}
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{refused, _upstreamErrorRefused},
+		{opErr(syscall.ECONNREFUSED), _upstreamErrorRefused},
+		{opErr(syscall.ECONNRESET), _upstreamErrorReset},
+		{fmt.Errorf("read body: %w", io.ErrUnexpectedEOF), _upstreamErrorReset},
+		{context.DeadlineExceeded, _upstreamErrorTimeout},
+		{&url.Error{Op: "Get", URL: "http://backend", Err: timeoutError{}}, _upstreamErrorTimeout},
+		{context.Canceled, _upstreamErrorCanceled},
+		{&url.Error{Op: "Get", URL: "http://backend", Err: &net.DNSError{Err: "no such host", Name: "backend"}}, _upstreamErrorDNS},
+		{&url.Error{Op: "Get", URL: "https://backend", Err: &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}}, _upstreamErrorTLS},
+		{tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}, _upstreamErrorTLS},
+		{selector.ErrNoAvailable, _upstreamErrorNoNode},
+		{fmt.Errorf("127.0.0.1:9000: %w", client.ErrHTTP2Unsupported), _upstreamErrorProtocol},
+		{client.ErrBackendRateLimited, _upstreamErrorRateLimited},
+		{fmt.Errorf("127.0.0.1:443: %w", client.ErrTLSRenegotiation), _upstreamErrorTLSRenegotiation},
+		{errors.New("boom"), _upstreamErrorOther},
+	}
+	for _, tt := range tests {
+		if got := classifyUpstreamError(tt.err); got != tt.want {
+			t.Errorf("%v: want class %s but got %s", tt.err, tt.want, got)
+		}
+	}
+}
+
+func TestUpstreamErrorsMetric(t *testing.T) {
+	c := &config.Gateway{
+		Name: "Test",
+		Endpoints: []*config.Endpoint{{
+			Protocol: config.Protocol_HTTP,
+			Path:     "/upstream/errors",
+			Method:   "GET",
+		}, {
+			Protocol: config.Protocol_HTTP,
+			Path:     "/upstream/errors/disabled",
+			Method:   "GET",
+			Metrics:  &config.Metrics{DisableUpstreamErrors: true},
+		}},
+	}
+	clientFactory := func(_ *client.BuildContext, e *config.Endpoint) (client.Client, error) {
+		return RoundTripperCloserFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, &url.Error{Op: "Get", URL: "http://backend", Err: &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}}
+		}), nil
+	}
+	middlewareFactory := func(c *config.Middleware) (middleware.MiddlewareV2, error) {
+		return logging.Middleware(c)
+	}
+	p, err := New(clientFactory, middlewareFactory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Update(client.NewBuildContext(c), c); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/upstream/errors", "/upstream/errors/disabled"} {
+		w := httptest.NewRecorder()
+		p.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusBadGateway {
+			t.Fatalf("%s: want 502 but got %d", path, w.Code)
+		}
+	}
+	labels := map[string]string{"path": "/upstream/errors", "class": _upstreamErrorRefused}
+	if got := metricValue(t, "go_gateway_upstream_errors_total", labels); got != 1 {
+		t.Fatalf("want 1 connection refused error but got %v", got)
+	}
+	labels = map[string]string{"path": "/upstream/errors/disabled"}
+	if got := metricValue(t, "go_gateway_upstream_errors_total", labels); got != 0 {
+		t.Fatalf("want the disabled metric not recorded but got %v", got)
+	}
+}
@@ -0,0 +1,83 @@
+package proxy
+
+import (
+	"net/http"
+	"os"
+	"strconv"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"github.com/cnsync/kratos/log"
+)
+
+// maxURLLength 是全局的请求 URI 最大长度，从环境变量 PROXY_MAX_URL_LENGTH 中读取，为 0 时不限制
+var maxURLLength int64
+
+func init() {
+	var err error
+	if v := os.Getenv("PROXY_MAX_URL_LENGTH"); v != "" {
+		if maxURLLength, err = strconv.ParseInt(v, 10, 64); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// requestURILength 函数返回请求 URI 的长度，包括查询字符串
+func requestURILength(req *http.Request) int64 {
+	if req.RequestURI != "" {
+		return int64(len(req.RequestURI))
+	}
+	return int64(len(req.URL.RequestURI()))
+}
+
+// endpointMaxURLLength 返回端点生效的请求 URI 最大长度，端点未配置时使用全局限制
+func endpointMaxURLLength(e *config.Endpoint) int64 {
+	if e.MaxUrlLength > 0 {
+		return e.MaxUrlLength
+	}
+	return maxURLLength
+}
+
+// urlLengthCeiling 函数返回在路由之前检查的请求 URI 最大长度，即全局限制与所有端点限制中的最大值，
+// 任意一方不限制时返回 0
+func urlLengthCeiling(c *config.Gateway) int64 {
+	endpoints := make([]*config.Endpoint, 0, len(c.Endpoints)+1)
+	for _, te := range routeEndpoints(c) {
+		endpoints = append(endpoints, te.endpoint)
+	}
+	if c.DefaultEndpoint != nil {
+		endpoints = append(endpoints, c.DefaultEndpoint)
+	}
+	ceiling := maxURLLength
+	for _, e := range endpoints {
+		limit := endpointMaxURLLength(e)
+		if limit <= 0 {
+			return 0
+		}
+		if limit > ceiling {
+			ceiling = limit
+		}
+	}
+	return ceiling
+}
+
+// checkURLLength 函数检查请求 URI 的长度，超过 limit 时返回 414 并返回 false，limit 为 0 时不限制
+func checkURLLength(w http.ResponseWriter, req *http.Request, limit int64) bool {
+	if limit <= 0 || requestURILength(req) <= limit {
+		return true
+	}
+	code := http.StatusRequestURITooLong
+	message := http.StatusText(code)
+	http.Error(w, message, code)
+	// 不记录过长的查询字符串
+	log.Context(req.Context()).Errorw(
+		"source", "accesslog",
+		"host", req.Host,
+		"method", req.Method,
+		"path", req.URL.Path,
+		"uri_length", requestURILength(req),
+		"code", code,
+		"error", message,
+	)
+	_metricRequestsTotal.WithLabelValues("HTTP", req.Method, "/414", strconv.Itoa(code), "", "").Inc()
+	return false
+}
@@ -0,0 +1,57 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+)
+
+var (
+	// Version 是网关的版本号，构建时可通过 -ldflags "-X github.com/cnsync/gateway/proxy.Version=v1.0.0" 注入
+	Version = "dev"
+	// BuildSHA 是构建时的 git commit，构建时可通过 -ldflags "-X github.com/cnsync/gateway/proxy.BuildSHA=xxx" 注入
+	BuildSHA = ""
+	// BuildTime 是构建的时间，构建时可通过 -ldflags "-X github.com/cnsync/gateway/proxy.BuildTime=xxx" 注入
+	BuildTime = ""
+)
+
+// BuildInfo 结构体定义了网关的构建信息
+type BuildInfo struct {
+	// Version 是网关的版本号
+	Version string `json:"version"`
+	// BuildSHA 是构建时的 git commit
+	BuildSHA string `json:"build_sha"`
+	// BuildTime 是构建的时间
+	BuildTime string `json:"build_time"`
+	// GoVersion 是构建使用的 Go 版本
+	GoVersion string `json:"go_version"`
+}
+
+// SetBuildInfo 函数设置网关的构建信息，为空的值保持不变
+func SetBuildInfo(version, buildSHA, buildTime string) {
+	if version != "" {
+		Version = version
+	}
+	if buildSHA != "" {
+		BuildSHA = buildSHA
+	}
+	if buildTime != "" {
+		BuildTime = buildTime
+	}
+}
+
+// GetBuildInfo 函数返回网关的构建信息
+func GetBuildInfo() BuildInfo {
+	return BuildInfo{
+		Version:   Version,
+		BuildSHA:  BuildSHA,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+}
+
+// versionHandler 函数以 JSON 格式返回网关的构建信息，只注册在调试处理程序中，不占用数据面的路径
+func versionHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(GetBuildInfo())
+}
@@ -0,0 +1,43 @@
+package proxy
+
+import (
+	"strings"
+
+	config "github.com/cnsync/gateway/api/gateway/config/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// virtualHostPrefix 函数返回规范化的虚拟主机路径前缀，以斜杠开头且不以斜杠结尾
+func virtualHostPrefix(vh *config.VirtualHost) string {
+	prefix := strings.TrimRight(vh.PathPrefix, "/")
+	if !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+	return prefix
+}
+
+// virtualHostEndpoints 函数返回虚拟主机展开后的端点，端点路径加上虚拟主机的前缀，
+// 没有配置后端的端点使用虚拟主机的后端，虚拟主机没有配置端点时将前缀下的所有请求转发给后端
+func virtualHostEndpoints(vh *config.VirtualHost) []*config.Endpoint {
+	prefix := virtualHostPrefix(vh)
+	if len(vh.Endpoints) == 0 {
+		return []*config.Endpoint{{
+			Path:     prefix + "/*",
+			Protocol: config.Protocol_HTTP,
+			Backends: vh.Backends,
+		}}
+	}
+	out := make([]*config.Endpoint, 0, len(vh.Endpoints))
+	for _, e := range vh.Endpoints {
+		e = proto.Clone(e).(*config.Endpoint)
+		if !strings.HasPrefix(e.Path, "/") {
+			e.Path = "/" + e.Path
+		}
+		e.Path = prefix + e.Path
+		if len(e.Backends) == 0 {
+			e.Backends = vh.Backends
+		}
+		out = append(out, e)
+	}
+	return out
+}
@@ -0,0 +1,99 @@
+package proxy
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cnsync/gateway/client"
+	"github.com/cnsync/kratos/log"
+)
+
+var (
+	// warmupTimeout 是预热的最长时间，从环境变量 PROXY_WARMUP_TIMEOUT 中读取，为 0 时不进行预热
+	warmupTimeout time.Duration
+	// warmupConns 是预热时对每个后端节点建立的连接数，从环境变量 PROXY_WARMUP_CONNS 中读取
+	warmupConns = 3
+)
+
+func init() {
+	var err error
+	if v := os.Getenv("PROXY_WARMUP_TIMEOUT"); v != "" {
+		if warmupTimeout, err = time.ParseDuration(v); err != nil {
+			panic(err)
+		}
+	}
+	if v := os.Getenv("PROXY_WARMUP_CONNS"); v != "" {
+		if warmupConns, err = strconv.Atoi(v); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Warmup 方法通过每个后端节点自身的客户端发送预热请求，建立的连接保留在连接池中供之后的请求复用，
+// 预热完成或超时之前就绪探针返回未就绪，返回的通道在预热结束时关闭。
+func (p *Proxy) Warmup(ctx context.Context) <-chan struct{} {
+	done := make(chan struct{})
+	if warmupTimeout <= 0 || warmupConns <= 0 {
+		close(done)
+		return done
+	}
+	// 同步地标记为预热中，保证返回之后就绪探针立即生效
+	p.warming.Store(true)
+	go func() {
+		defer close(done)
+		defer p.warming.Store(false)
+		ctx, cancel := context.WithTimeout(ctx, warmupTimeout)
+		defer cancel()
+		start := time.Now()
+		targets := p.warmupTargets()
+		p.warmup(ctx, targets)
+		if err := ctx.Err(); err != nil {
+			log.Warnf("warmup %d backends timed out after %s: %v", len(targets), time.Since(start), err)
+			return
+		}
+		log.Infof("warmup %d backends completed in %s", len(targets), time.Since(start))
+	}()
+	return done
+}
+
+// warmupTargets 方法返回当前配置中所有后端节点的预热目标，多个端点共用的节点只预热一次
+func (p *Proxy) warmupTargets() []client.WarmupTarget {
+	state := p.readiness.Load()
+	if state == nil {
+		return nil
+	}
+	seen := make(map[client.WarmupTarget]struct{})
+	var targets []client.WarmupTarget
+	for _, c := range state.clients {
+		warmer, ok := endpointClient(c).(client.NodeWarmer)
+		if !ok {
+			continue
+		}
+		for _, target := range warmer.WarmupTargets() {
+			if _, ok := seen[target]; ok {
+				continue
+			}
+			seen[target] = struct{}{}
+			targets = append(targets, target)
+		}
+	}
+	return targets
+}
+
+// warmup 方法并发地预热每个后端节点，每个节点建立 warmupConns 个连接
+func (p *Proxy) warmup(ctx context.Context, targets []client.WarmupTarget) {
+	wg := sync.WaitGroup{}
+	for _, target := range targets {
+		wg.Add(1)
+		go func(target client.WarmupTarget) {
+			defer wg.Done()
+			if err := target.Warm(ctx, warmupConns); err != nil {
+				log.Warnf("failed to warmup backend %s: %v", target.URL, err)
+			}
+		}(target)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,122 @@
+package mux
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/cnsync/gateway/audit"
+)
+
+// metricsProtection 是 /metrics 的保护方式，从环境变量中读取
+var metricsProtection = newMetricsProtection(
+	os.Getenv("METRICS_ALLOWED_CIDRS"),
+	os.Getenv("METRICS_BEARER_TOKEN"),
+	os.Getenv("METRICS_BASIC_AUTH"),
+)
+
+// metricsProtect 结构体定义了 /metrics 的保护方式，配置了多种方式时需要全部满足，
+// 都未配置时沿用 ProtectedHandler，拒绝经过不受信任的代理转发的请求
+type metricsProtect struct {
+	// allowedNetworks 是允许访问的来源网段，只检查直连的对端地址，因此可以放行经过受信任代理的抓取请求
+	allowedNetworks []*net.IPNet
+	// bearerToken 是访问需要携带的 Bearer 令牌
+	bearerToken string
+	// basicUser 和 basicPassword 是访问需要携带的 Basic 认证信息
+	basicUser, basicPassword string
+}
+
+// newMetricsProtection 函数根据以逗号分隔的网段列表、Bearer 令牌和 user:password 格式的 Basic 认证信息创建保护方式，
+// 配置无效时 panic
+func newMetricsProtection(cidrs, bearerToken, basicAuth string) *metricsProtect {
+	p := &metricsProtect{bearerToken: bearerToken}
+	for _, cidr := range strings.Split(cidrs, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		p.allowedNetworks = append(p.allowedNetworks, network)
+	}
+	if basicAuth != "" {
+		user, password, ok := strings.Cut(basicAuth, ":")
+		if !ok {
+			panic("METRICS_BASIC_AUTH must be in the form of user:password")
+		}
+		p.basicUser, p.basicPassword = user, password
+	}
+	return p
+}
+
+// configured 方法判断是否配置了任意一种保护方式
+func (p *metricsProtect) configured() bool {
+	return len(p.allowedNetworks) > 0 || p.bearerToken != "" || p.basicUser != ""
+}
+
+// secureEqual 函数以固定时间比较两个字符串
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+// check 方法判断请求是否满足所有配置的保护方式，不满足时返回状态码和原因，满足时返回的状态码为 0
+func (p *metricsProtect) check(r *http.Request) (int, string) {
+	if len(p.allowedNetworks) > 0 {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		ip := net.ParseIP(host)
+		if err != nil || ip == nil || !containsIP(p.allowedNetworks, ip) {
+			return http.StatusForbidden, "remote address not allowed"
+		}
+	}
+	if p.bearerToken != "" {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || !secureEqual(token, p.bearerToken) {
+			return http.StatusUnauthorized, "invalid bearer token"
+		}
+	}
+	if p.basicUser != "" {
+		user, password, ok := r.BasicAuth()
+		// 分别比较用户名和密码，避免短路泄露用户名是否正确
+		userOK := secureEqual(user, p.basicUser)
+		passwordOK := secureEqual(password, p.basicPassword)
+		if !ok || !userOK || !passwordOK {
+			return http.StatusUnauthorized, "invalid basic auth"
+		}
+	}
+	return 0, ""
+}
+
+// containsIP 函数判断 IP 是否属于任一网段
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handler 方法返回受保护的处理程序
+func (p *metricsProtect) Handler(h http.Handler) http.Handler {
+	if !p.configured() {
+		return ProtectedHandler("metrics", h)
+	}
+	// 记录管理操作结果的处理程序
+	audited := audit.Handler(audit.TypeAdmin, "metrics", h)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if status, reason := p.check(r); status != 0 {
+			if status == http.StatusUnauthorized && p.basicUser != "" {
+				w.Header().Set("WWW-Authenticate", `Basic realm="metrics"`)
+			}
+			http.Error(w, http.StatusText(status), status)
+			// 记录鉴权拒绝的审计事件
+			audit.EmitRequest(r, audit.TypeAuth, "metrics", audit.ResultDeny, reason)
+			return
+		}
+		audited.ServeHTTP(w, r)
+	})
+}
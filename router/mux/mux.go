@@ -2,15 +2,20 @@ package mux
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"os"
 	"path"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
 
+	"github.com/cnsync/gateway/audit"
 	"github.com/cnsync/gateway/router"
+	"github.com/cnsync/gateway/trust"
 	"github.com/cnsync/kratos/log"
 	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -46,21 +51,30 @@ type muxRouter struct {
 	wg *sync.WaitGroup
 	// allCloser 字段是一个 io.Closer 接口的切片，用于存储所有需要关闭的资源
 	allCloser []io.Closer
+	// methodNotAllowed 字段是请求方法不匹配时使用的默认处理器
+	methodNotAllowed http.Handler
+	// routeMethodNotAllowed 字段记录了注册时指定了请求方法不匹配处理器的路由
+	routeMethodNotAllowed map[*mux.Route]http.Handler
 }
 
-// ProtectedHandler 函数用于保护指定的 HTTP 处理程序
-func ProtectedHandler(h http.Handler) http.Handler {
+// ProtectedHandler 函数用于保护指定的 HTTP 处理程序，拒绝经过代理转发、且转发链路中有不属于 trust.Networks 的地址的请求，
+// 允许的请求在处理完成后根据响应状态码记录 action 管理操作的审计事件
+func ProtectedHandler(action string, h http.Handler) http.Handler {
+	// 记录管理操作结果的处理程序
+	audited := audit.Handler(audit.TypeAdmin, action, h)
 	// 返回一个新的 http.Handler 接口实现
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// 检查请求头中是否包含 "X-Forwarded-For" 字段
-		if r.Header.Get("X-Forwarded-For") != "" {
+		// 检查请求头中是否包含 "X-Forwarded-For" 字段，只放行由受信任的代理转发的受信任来源的请求
+		if r.Header.Get("X-Forwarded-For") != "" && !trust.IsTrusted(r) {
 			// 如果包含，则返回 403 Forbidden 状态码和相应的状态文本
 			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			// 记录鉴权拒绝的审计事件
+			audit.EmitRequest(r, audit.TypeAuth, action, audit.ResultDeny, "forwarded request")
 			// 阻止请求继续处理
 			return
 		}
-		// 调用传入的处理程序 h 的 ServeHTTP 方法，处理请求
-		h.ServeHTTP(w, r)
+		// 调用传入的处理程序处理请求，并记录操作结果
+		audited.ServeHTTP(w, r)
 	})
 }
 
@@ -72,13 +86,16 @@ func NewRouter(notFoundHandler, methodNotAllowedHandler http.Handler) router.Rou
 		Router: mux.NewRouter().StrictSlash(EnableStrictSlash),
 		// 初始化同步等待组
 		wg: &sync.WaitGroup{},
+		// 保存默认的 405 处理程序
+		methodNotAllowed:      methodNotAllowedHandler,
+		routeMethodNotAllowed: make(map[*mux.Route]http.Handler),
 	}
-	// 注册一个处理程序，用于处理 /metrics 路径的请求
-	r.Router.Handle("/metrics", ProtectedHandler(promhttp.Handler()))
+	// 注册一个处理程序，用于处理 /metrics 路径的请求，保护方式由环境变量配置
+	r.Router.Handle("/metrics", metricsProtection.Handler(promhttp.Handler()))
 	// 设置 404 未找到处理程序
 	r.Router.NotFoundHandler = notFoundHandler
-	// 设置 405 方法不允许处理程序
-	r.Router.MethodNotAllowedHandler = methodNotAllowedHandler
+	// 设置 405 方法不允许处理程序，填充 Allow 响应头后交给路由指定的或默认的处理程序
+	r.Router.MethodNotAllowedHandler = http.HandlerFunc(r.serveMethodNotAllowed)
 	// 返回创建的路由器实例
 	return r
 }
@@ -117,7 +134,12 @@ func (r *muxRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 }
 
 // Handle 方法用于注册一个处理器，该处理器将处理指定模式、方法和主机的请求
-func (r *muxRouter) Handle(pattern, method, host string, handler http.Handler, closer io.Closer) error {
+func (r *muxRouter) Handle(pattern, method, host string, handler http.Handler, closer io.Closer, opts ...router.HandleOption) error {
+	// 应用附加的匹配条件
+	options := &router.HandleOptions{}
+	for _, o := range opts {
+		o(options)
+	}
 	// 创建一个新的路由，并设置处理函数
 	next := r.Router.NewRoute().Handler(handler)
 	// 如果指定了主机名，则设置路由的主机限制
@@ -135,8 +157,42 @@ func (r *muxRouter) Handle(pattern, method, host string, handler http.Handler, c
 		next = next.Path(pattern)
 	}
 	// 如果指定了方法，则设置路由的方法限制
+	methods := options.Methods
 	if method != "" && method != "*" {
-		next = next.Methods(method, http.MethodOptions)
+		methods = append([]string{method}, methods...)
+	}
+	if len(methods) > 0 {
+		next = next.Methods(append(methods, http.MethodOptions)...)
+	}
+	// 记录路由指定的请求方法不匹配处理器
+	if options.MethodNotAllowed != nil {
+		r.routeMethodNotAllowed[next] = options.MethodNotAllowed
+	}
+	// 如果指定了请求头匹配条件，则设置路由的请求头限制
+	for _, h := range options.Headers {
+		if h.Regex != "" {
+			next = next.HeadersRegexp(h.Name, h.Regex)
+			continue
+		}
+		// 值为空时 gorilla/mux 只要求请求头存在
+		next = next.Headers(h.Name, h.Value)
+	}
+	// 如果指定了查询参数匹配条件，则设置路由的查询参数限制
+	for _, q := range options.Queries {
+		if q.Regex != "" {
+			re, err := regexp.Compile(q.Regex)
+			if err != nil {
+				return err
+			}
+			next = next.MatcherFunc(queryRegexpMatcher(q.Name, re))
+			continue
+		}
+		// 值为空时 gorilla/mux 只要求查询参数存在
+		next = next.Queries(q.Name, q.Value)
+	}
+	// 如果指定了租户，则只匹配该租户的请求
+	if options.Tenant != "" {
+		next = next.MatcherFunc(tenantMatcher(options.Tenant))
 	}
 	// 检查路由配置是否有错误
 	if err := next.GetError(); err != nil {
@@ -148,6 +204,122 @@ func (r *muxRouter) Handle(pattern, method, host string, handler http.Handler, c
 	return nil
 }
 
+// PatternMatches 函数判断按 Handle 注册的模式和方法是否匹配指定方法和路径的请求，
+// 只比较路径和方法，不考虑主机、请求头等附加条件
+func PatternMatches(pattern, method, reqMethod, path string) bool {
+	route := mux.NewRouter().NewRoute()
+	if strings.HasSuffix(pattern, "*") {
+		route = route.PathPrefix(strings.TrimRight(pattern, "*"))
+	} else {
+		route = route.Path(pattern)
+	}
+	if method != "" && method != "*" {
+		route = route.Methods(method)
+	}
+	if route.GetError() != nil {
+		return false
+	}
+	req, err := http.NewRequest(reqMethod, path, nil)
+	if err != nil {
+		return false
+	}
+	return route.Match(req, &mux.RouteMatch{})
+}
+
+// serveMethodNotAllowed 方法处理路径匹配而请求方法不匹配的请求，Allow 响应头列出所有匹配路径的路由允许的方法，
+// 第一个指定了处理器的匹配路由的处理器负责写入响应
+func (r *muxRouter) serveMethodNotAllowed(w http.ResponseWriter, req *http.Request) {
+	handler := r.methodNotAllowed
+	custom := false
+	allowed := make(map[string]struct{})
+	_ = r.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		var match mux.RouteMatch
+		if route.Match(req, &match) || !errors.Is(match.MatchErr, mux.ErrMethodMismatch) {
+			return nil
+		}
+		methods, _ := route.GetMethods()
+		for _, m := range methods {
+			allowed[m] = struct{}{}
+		}
+		if h, ok := r.routeMethodNotAllowed[route]; ok && !custom {
+			handler, custom = h, true
+		}
+		return nil
+	})
+	if len(allowed) > 0 {
+		methods := make([]string, 0, len(allowed))
+		for m := range allowed {
+			methods = append(methods, m)
+		}
+		sort.Strings(methods)
+		w.Header().Set("Allow", strings.Join(methods, ", "))
+	}
+	if handler == nil {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	handler.ServeHTTP(w, req)
+}
+
+// AllowedMethods 函数返回匹配请求的所有路由允许的请求方法，已排序。没有匹配的路由，
+// 或者有匹配的路由允许所有请求方法时返回 false
+func AllowedMethods(in interface{}, req *http.Request) ([]string, bool) {
+	r, ok := in.(*muxRouter)
+	if !ok {
+		return nil, false
+	}
+	// 与 ServeHTTP 一样按清理后的路径匹配
+	match := *req
+	u := *req.URL
+	u.Path = cleanPath(u.Path)
+	match.URL = &u
+	allowed := make(map[string]struct{})
+	anyMethod := false
+	_ = r.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		if !route.Match(&match, &mux.RouteMatch{}) {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			anyMethod = true
+			return nil
+		}
+		for _, m := range methods {
+			allowed[m] = struct{}{}
+		}
+		return nil
+	})
+	if anyMethod || len(allowed) == 0 {
+		return nil, false
+	}
+	methods := make([]string, 0, len(allowed))
+	for m := range allowed {
+		methods = append(methods, m)
+	}
+	sort.Strings(methods)
+	return methods, true
+}
+
+// queryRegexpMatcher 函数返回一个匹配器，要求查询参数存在且有任一值匹配正则表达式，
+// 不使用 gorilla/mux 的查询模板是为了避免正则中的花括号与模板变量冲突
+func queryRegexpMatcher(name string, re *regexp.Regexp) mux.MatcherFunc {
+	return func(req *http.Request, _ *mux.RouteMatch) bool {
+		for _, v := range req.URL.Query()[name] {
+			if re.MatchString(v) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// tenantMatcher 函数返回一个匹配器，要求请求所属的租户与路由所属的租户一致
+func tenantMatcher(tenant string) mux.MatcherFunc {
+	return func(req *http.Request, _ *mux.RouteMatch) bool {
+		return router.TenantFromContext(req.Context()) == tenant
+	}
+}
+
 // SyncClose 方法用于同步关闭路由器，等待所有请求处理完毕后关闭
 func (r *muxRouter) SyncClose(ctx context.Context) error {
 	// 检查是否超时，如果超时则记录警告信息
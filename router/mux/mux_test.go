@@ -1,7 +1,13 @@
 package mux
 
 import (
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	"github.com/cnsync/gateway/audit"
+	"github.com/cnsync/gateway/trust"
 )
 
 func TestPathClean(t *testing.T) {
@@ -32,3 +38,169 @@ func TestPathClean(t *testing.T) {
 		}
 	}
 }
+
+func TestPatternMatches(t *testing.T) {
+	tests := []struct {
+		pattern string
+		method  string
+		want    bool
+	}{
+		{"/readyz", "", true},
+		{"/readyz", "GET", true},
+		{"/readyz", "POST", false},
+		{"/*", "", true},
+		{"/ready*", "*", true},
+		{"/{name}", "GET", true},
+		{"/{name:[a-z]+}", "", true},
+		{"/{name:[0-9]+}", "", false},
+		{"/api/*", "", false},
+		{"/readyz/{id}", "", false},
+	}
+	for _, tt := range tests {
+		if got := PatternMatches(tt.pattern, tt.method, http.MethodGet, "/readyz"); got != tt.want {
+			t.Errorf("PatternMatches(%s, %s) = %v, want %v", tt.pattern, tt.method, got, tt.want)
+		}
+	}
+}
+
+type memorySink struct {
+	events []*audit.Event
+}
+
+func (s *memorySink) Write(e *audit.Event) error {
+	s.events = append(s.events, e)
+	return nil
+}
+
+func (s *memorySink) Close() error { return nil }
+
+func TestProtectedHandlerAudit(t *testing.T) {
+	sink := &memorySink{}
+	l := audit.NewLogger(sink, 0)
+	old := audit.SetLogger(l)
+	defer audit.SetLogger(old)
+
+	h := ProtectedHandler("debug", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/debug/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	allowed := httptest.NewRequest("GET", "/debug/ok", nil)
+	h.ServeHTTP(httptest.NewRecorder(), allowed)
+	failed := httptest.NewRequest("GET", "/debug/fail", nil)
+	h.ServeHTTP(httptest.NewRecorder(), failed)
+	denied := httptest.NewRequest("GET", "/debug/ok", nil)
+	denied.Header.Set("X-Forwarded-For", "10.0.0.1")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, denied)
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("want 403 but got %d", w.Code)
+	}
+	l.Close()
+
+	if len(sink.events) != 3 {
+		t.Fatalf("want 3 audit events but got %d", len(sink.events))
+	}
+	// 允许的请求在处理完成之后按响应状态码记录结果
+	if e := sink.events[0]; e.Type != audit.TypeAdmin || e.Action != "debug" || e.Result != audit.ResultSuccess || e.Resource != "GET /debug/ok" {
+		t.Fatalf("unexpected audit event: %+v", e)
+	}
+	if e := sink.events[1]; e.Type != audit.TypeAdmin || e.Result != audit.ResultFailure || e.Reason != "status 500" {
+		t.Fatalf("unexpected audit event: %+v", e)
+	}
+	e := sink.events[2]
+	if e.Type != audit.TypeAuth || e.Result != audit.ResultDeny || e.Actor != denied.RemoteAddr || e.Resource != "GET /debug/ok" {
+		t.Fatalf("unexpected audit event: %+v", e)
+	}
+}
+
+func TestProtectedHandlerTrustedProxy(t *testing.T) {
+	defer func(networks []*net.IPNet) { trust.Networks = networks }(trust.Networks)
+	trust.Networks = trust.MustParseCIDRs("127.0.0.0/8,10.0.0.0/8")
+	h := ProtectedHandler("debug", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	tests := []struct {
+		remoteAddr string
+		forwarded  string
+		want       int
+	}{
+		{"203.0.113.1:1234", "", http.StatusOK},
+		// 受信任的代理转发的受信任来源的请求
+		{"127.0.0.1:1234", "10.0.0.2", http.StatusOK},
+		// 受信任的代理转发的不受信任来源的请求
+		{"127.0.0.1:1234", "203.0.113.1", http.StatusForbidden},
+		// 不受信任的对端转发的请求
+		{"203.0.113.1:1234", "10.0.0.2", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/debug/ok", nil)
+		r.RemoteAddr = tt.remoteAddr
+		if tt.forwarded != "" {
+			r.Header.Set("X-Forwarded-For", tt.forwarded)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != tt.want {
+			t.Fatalf("%s via %s: want %d but got %d", tt.forwarded, tt.remoteAddr, tt.want, w.Code)
+		}
+	}
+}
+
+func TestMetricsProtection(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	tests := []struct {
+		name       string
+		protection *metricsProtect
+		remoteAddr string
+		forwarded  string
+		bearer     string
+		user       string
+		password   string
+		want       int
+	}{
+		{"default", newMetricsProtection("", "", ""), "10.0.0.2:1234", "", "", "", "", 200},
+		{"default-proxied", newMetricsProtection("", "", ""), "10.0.0.2:1234", "10.0.0.1", "", "", "", 403},
+		{"allowlist", newMetricsProtection("10.0.0.0/8", "", ""), "10.0.0.2:1234", "", "", "", "", 200},
+		{"allowlist-proxied", newMetricsProtection("10.0.0.0/8", "", ""), "10.0.0.2:1234", "203.0.113.1", "", "", "", 200},
+		{"allowlist-denied", newMetricsProtection("10.0.0.0/8", "", ""), "203.0.113.1:1234", "", "", "", "", 403},
+		{"bearer", newMetricsProtection("", "token", ""), "203.0.113.1:1234", "10.0.0.1", "token", "", "", 200},
+		{"bearer-invalid", newMetricsProtection("", "token", ""), "203.0.113.1:1234", "", "other", "", "", 401},
+		{"bearer-missing", newMetricsProtection("", "token", ""), "203.0.113.1:1234", "", "", "", "", 401},
+		{"basic", newMetricsProtection("", "", "prom:secret"), "203.0.113.1:1234", "10.0.0.1", "", "prom", "secret", 200},
+		{"basic-invalid", newMetricsProtection("", "", "prom:secret"), "203.0.113.1:1234", "", "", "prom", "wrong", 401},
+		{"combined", newMetricsProtection("10.0.0.0/8", "token", ""), "10.0.0.2:1234", "", "token", "", "", 200},
+		{"combined-denied", newMetricsProtection("10.0.0.0/8", "token", ""), "203.0.113.1:1234", "", "token", "", "", 403},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/metrics", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.bearer != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.bearer)
+			}
+			if tt.user != "" {
+				r.SetBasicAuth(tt.user, tt.password)
+			}
+			w := httptest.NewRecorder()
+			tt.protection.Handler(next).ServeHTTP(w, r)
+			if w.Code != tt.want {
+				t.Fatalf("want status %d but got %d", tt.want, w.Code)
+			}
+		})
+	}
+}
+
+func TestMetricsProtectionInvalid(t *testing.T) {
+	for _, args := range [][3]string{{"invalid", "", ""}, {"", "", "no-colon"}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatalf("%v: want panic", args)
+				}
+			}()
+			newMetricsProtection(args[0], args[1], args[2])
+		}()
+	}
+}
@@ -11,7 +11,93 @@ type Router interface {
 	// Handler http.Handler 是一个接口，用于处理 HTTP 请求。
 	http.Handler
 	// Handle 方法用于注册一个处理器，该处理器将处理指定模式、方法和主机的请求。
-	Handle(pattern, method, host string, handler http.Handler, closer io.Closer) error
+	Handle(pattern, method, host string, handler http.Handler, closer io.Closer, opts ...HandleOption) error
 	// SyncClose 方法用于同步关闭路由器，等待所有请求处理完毕后关闭。
 	SyncClose(ctx context.Context) error
 }
+
+// HeaderMatcher 结构体定义了一个请求头匹配条件
+type HeaderMatcher struct {
+	// Name 是请求头的名称
+	Name string
+	// Value 是请求头需要精确匹配的值
+	Value string
+	// Regex 是请求头的值需要匹配的正则表达式，Value 和 Regex 都为空时只要求请求头存在
+	Regex string
+}
+
+// QueryMatcher 结构体定义了一个查询参数匹配条件
+type QueryMatcher struct {
+	// Name 是查询参数的名称
+	Name string
+	// Value 是查询参数需要精确匹配的值
+	Value string
+	// Regex 是查询参数的值需要匹配的正则表达式，Value 和 Regex 都为空时只要求查询参数存在
+	Regex string
+}
+
+// HandleOptions 结构体定义了注册处理器时的附加匹配条件
+type HandleOptions struct {
+	// Headers 是请求需要全部满足的请求头匹配条件
+	Headers []HeaderMatcher
+	// Queries 是请求需要全部满足的查询参数匹配条件
+	Queries []QueryMatcher
+	// Methods 是除注册的方法以外同样匹配的请求方法，注册的方法为空时只匹配这些方法
+	Methods []string
+	// MethodNotAllowed 是路径匹配而请求方法不匹配时使用的处理器，为 nil 时使用路由器默认的处理器
+	MethodNotAllowed http.Handler
+	// Tenant 是路由所属的租户，不为空时只匹配该租户的请求，为空时匹配所有请求
+	Tenant string
+}
+
+// HandleOption 是一个函数类型，用于设置 HandleOptions
+type HandleOption func(*HandleOptions)
+
+// WithHeaders 函数返回一个 HandleOption，用于设置请求头匹配条件
+func WithHeaders(headers ...HeaderMatcher) HandleOption {
+	return func(o *HandleOptions) {
+		o.Headers = append(o.Headers, headers...)
+	}
+}
+
+// WithMethods 函数返回一个 HandleOption，用于设置同样匹配的请求方法
+func WithMethods(methods ...string) HandleOption {
+	return func(o *HandleOptions) {
+		o.Methods = append(o.Methods, methods...)
+	}
+}
+
+// WithMethodNotAllowed 函数返回一个 HandleOption，用于设置请求方法不匹配时使用的处理器
+func WithMethodNotAllowed(handler http.Handler) HandleOption {
+	return func(o *HandleOptions) {
+		o.MethodNotAllowed = handler
+	}
+}
+
+// WithTenant 函数返回一个 HandleOption，用于设置路由所属的租户
+func WithTenant(tenant string) HandleOption {
+	return func(o *HandleOptions) {
+		o.Tenant = tenant
+	}
+}
+
+// WithQueries 函数返回一个 HandleOption，用于设置查询参数匹配条件
+func WithQueries(queries ...QueryMatcher) HandleOption {
+	return func(o *HandleOptions) {
+		o.Queries = append(o.Queries, queries...)
+	}
+}
+
+// tenantKey 是请求上下文中租户的键
+type tenantKey struct{}
+
+// NewTenantContext 函数返回一个携带请求所属租户的上下文，路由器据此匹配租户的路由
+func NewTenantContext(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext 函数返回请求所属的租户，未携带时返回空字符串
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
@@ -0,0 +1,102 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// _vaultTimeout 是请求 Vault 的超时时间
+const _vaultTimeout = 5 * time.Second
+
+func init() {
+	Register("file", ProviderFunc(fileProvider))
+	Register("env", ProviderFunc(envProvider))
+	Register("vault", &vaultProvider{client: &http.Client{Timeout: _vaultTimeout}})
+}
+
+// fileProvider 函数从文件中读取密钥，例如 file:///etc/gateway/tls.key，
+// 指定 field 时文件内容需要是一个 JSON 对象，例如 file:///etc/gateway/secrets.json#token
+func fileProvider(_ context.Context, path, field string) (string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	if field == "" {
+		return string(b), nil
+	}
+	data := map[string]interface{}{}
+	if err := json.Unmarshal(b, &data); err != nil {
+		return "", err
+	}
+	return jsonField(data, field)
+}
+
+// envProvider 函数从环境变量中读取密钥，例如 env://GATEWAY_TOKEN
+func envProvider(_ context.Context, name, _ string) (string, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("env %s is not set", name)
+	}
+	return v, nil
+}
+
+// vaultProvider 结构体通过 HTTP API 从 Vault 中读取密钥，例如 vault://secret/data/gateway#tls_key，
+// Vault 的地址和令牌分别从环境变量 VAULT_ADDR 和 VAULT_TOKEN 中读取
+type vaultProvider struct {
+	client *http.Client
+}
+
+// Get 方法从 Vault 中读取 path 对应的密钥，同时支持 KV v1 和 KV v2 引擎
+func (p *vaultProvider) Get(ctx context.Context, path, field string) (string, error) {
+	if field == "" {
+		return "", fmt.Errorf("vault secret field is required")
+	}
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return "", fmt.Errorf("env VAULT_ADDR is not set")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(addr, "/")+"/v1/"+strings.TrimLeft(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", os.Getenv("VAULT_TOKEN"))
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault responded with status: %d", resp.StatusCode)
+	}
+	body := struct {
+		Data map[string]interface{} `json:"data"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	// KV v2 引擎的密钥嵌套在 data.data 中
+	if nested, ok := body.Data["data"].(map[string]interface{}); ok {
+		if _, ok := body.Data["metadata"]; ok {
+			return jsonField(nested, field)
+		}
+	}
+	return jsonField(body.Data, field)
+}
+
+// jsonField 函数返回 JSON 对象中 field 字段的字符串值
+func jsonField(data map[string]interface{}, field string) (string, error) {
+	v, ok := data[field]
+	if !ok {
+		return "", fmt.Errorf("secret field not found: %s", field)
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("secret field is not a string: %s", field)
+	}
+	return s, nil
+}
@@ -0,0 +1,92 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Provider 接口定义了一个密钥提供者，用于从外部系统中获取密钥
+type Provider interface {
+	// Get 方法返回 path 对应密钥中 field 字段的值，field 为空时返回整个密钥
+	Get(ctx context.Context, path, field string) (string, error)
+}
+
+// ProviderFunc 是一个函数类型，实现了 Provider 接口
+type ProviderFunc func(ctx context.Context, path, field string) (string, error)
+
+// Get 方法调用函数本身
+func (f ProviderFunc) Get(ctx context.Context, path, field string) (string, error) {
+	return f(ctx, path, field)
+}
+
+var (
+	// lock 保护 providers
+	lock sync.RWMutex
+	// providers 保存了所有已注册的密钥提供者，键为引用的 scheme
+	providers = map[string]Provider{}
+)
+
+// Register 函数注册一个密钥提供者，引用 scheme://path#field 将由对应 scheme 的提供者解析
+func Register(scheme string, p Provider) {
+	lock.Lock()
+	defer lock.Unlock()
+	providers[scheme] = p
+}
+
+// Deregister 函数注销一个密钥提供者
+func Deregister(scheme string) {
+	lock.Lock()
+	defer lock.Unlock()
+	delete(providers, scheme)
+}
+
+// provider 函数返回 scheme 对应的密钥提供者
+func provider(scheme string) (Provider, bool) {
+	lock.RLock()
+	defer lock.RUnlock()
+	p, ok := providers[scheme]
+	return p, ok
+}
+
+// Reference 结构体是一个解析后的密钥引用
+type Reference struct {
+	// Scheme 是密钥提供者的名称，例如 vault、file、env
+	Scheme string
+	// Path 是密钥在提供者中的路径
+	Path string
+	// Field 是密钥中的字段，可以为空
+	Field string
+}
+
+// ParseReference 函数将 scheme://path#field 格式的字符串解析为密钥引用，
+// 只有 scheme 已注册时才被视为引用，否则返回 false，调用方应将其作为内联的值使用
+func ParseReference(s string) (*Reference, bool) {
+	scheme, rest, ok := strings.Cut(s, "://")
+	if !ok || scheme == "" {
+		return nil, false
+	}
+	if _, ok := provider(scheme); !ok {
+		return nil, false
+	}
+	path, field, _ := strings.Cut(rest, "#")
+	return &Reference{Scheme: scheme, Path: path, Field: field}, true
+}
+
+// Resolve 函数解析一个可能是密钥引用的值，不是引用时原样返回
+func Resolve(ctx context.Context, s string) (string, error) {
+	ref, ok := ParseReference(s)
+	if !ok {
+		return s, nil
+	}
+	p, ok := provider(ref.Scheme)
+	if !ok {
+		return "", fmt.Errorf("secrets provider not found: %s", ref.Scheme)
+	}
+	v, err := p.Get(ctx, ref.Path, ref.Field)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve secret %s://%s: %w", ref.Scheme, ref.Path, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,110 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	Register("stub", ProviderFunc(func(_ context.Context, path, field string) (string, error) {
+		return path + "/" + field, nil
+	}))
+	defer Deregister("stub")
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"stub://gateway/tls#key", "gateway/tls/key"},
+		{"stub://gateway/tls", "gateway/tls/"},
+		{"inline-secret", "inline-secret"},
+		{"-----BEGIN CERTIFICATE-----", "-----BEGIN CERTIFICATE-----"},
+		{"unknown://gateway/tls#key", "unknown://gateway/tls#key"},
+	}
+	for _, tt := range tests {
+		got, err := Resolve(context.Background(), tt.in)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Fatalf("%s: want %q but got %q", tt.in, tt.want, got)
+		}
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	dir := t.TempDir()
+	raw := filepath.Join(dir, "tls.key")
+	if err := os.WriteFile(raw, []byte("raw-key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	obj := filepath.Join(dir, "secrets.json")
+	if err := os.WriteFile(obj, []byte(`{"token":"json-token"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"file://" + raw, "raw-key"},
+		{"file://" + obj + "#token", "json-token"},
+	}
+	for _, tt := range tests {
+		got, err := Resolve(context.Background(), tt.in)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Fatalf("%s: want %q but got %q", tt.in, tt.want, got)
+		}
+	}
+	if _, err := Resolve(context.Background(), "file://"+obj+"#missing"); err == nil {
+		t.Fatal("want error for missing field")
+	}
+}
+
+func TestVaultProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/gateway":
+			_, _ = w.Write([]byte(`{"data":{"data":{"password":"v2"},"metadata":{"version":1}}}`))
+		case "/v1/kv/gateway":
+			_, _ = w.Write([]byte(`{"data":{"password":"v1"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	t.Setenv("VAULT_ADDR", srv.URL)
+	t.Setenv("VAULT_TOKEN", "root")
+
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"vault://secret/data/gateway#password", "v2"},
+		{"vault://kv/gateway#password", "v1"},
+	}
+	for _, tt := range tests {
+		got, err := Resolve(context.Background(), tt.in)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Fatalf("%s: want %q but got %q", tt.in, tt.want, got)
+		}
+	}
+	for _, in := range []string{"vault://missing#password", "vault://kv/gateway"} {
+		if _, err := Resolve(context.Background(), in); err == nil {
+			t.Fatalf("%s: want error", in)
+		}
+	}
+}
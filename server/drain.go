@@ -0,0 +1,67 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/cnsync/kratos/log"
+)
+
+// globalDrainer 在网关开始排空连接时调用注册的回调
+var globalDrainer = &drainer{}
+
+// drainer 结构体保存了开始排空连接时调用的回调，回调只调用一次
+type drainer struct {
+	lock    sync.Mutex
+	hooks   []func(context.Context) error
+	drained bool
+}
+
+// onDrain 方法注册一个回调
+func (d *drainer) onDrain(fn func(context.Context) error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.hooks = append(d.hooks, fn)
+}
+
+// drain 方法按注册的顺序调用所有回调，然后等待 drainDelay 使负载均衡器摘除网关，等待不超过上下文的截止时间。
+// 只在第一次调用时生效，同时调用的其它调用者会等到排空完成才返回，返回所有回调的错误
+func (d *drainer) drain(ctx context.Context) error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.drained {
+		return nil
+	}
+	d.drained = true
+	var errs []error
+	for _, fn := range d.hooks {
+		if err := fn(ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if drainDelay > 0 {
+		log.Infof("draining, waiting %s before closing listeners", drainDelay)
+		timer := time.NewTimer(drainDelay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			log.Warnf("drain delay interrupted: %v", ctx.Err())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// OnDrain 函数注册一个在网关开始排空连接时调用的回调，例如从服务发现注销网关自身，
+// 使上游的负载均衡器在连接关闭之前停止路由请求到网关
+func OnDrain(fn func(context.Context) error) {
+	globalDrainer.onDrain(fn)
+}
+
+// Drain 函数通知网关开始排空连接，停止或交接监听套接字之前调用，多次调用只生效一次，
+// 停止代理服务器时会自动调用
+func Drain(ctx context.Context) error {
+	return globalDrainer.drain(ctx)
+}
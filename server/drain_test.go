@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cnsync/gateway/discovery"
+	"github.com/cnsync/kratos/registry"
+)
+
+// fakeRegistry 记录注册和注销的实例
+type fakeRegistry struct {
+	registry.Discovery
+	registered   []string
+	deregistered []string
+	err          error
+}
+
+func (r *fakeRegistry) Register(_ context.Context, s *registry.ServiceInstance) error {
+	r.registered = append(r.registered, s.ID)
+	return nil
+}
+
+func (r *fakeRegistry) Deregister(_ context.Context, s *registry.ServiceInstance) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.deregistered = append(r.deregistered, s.ID)
+	return nil
+}
+
+func TestDrainDeregisters(t *testing.T) {
+	reg := &fakeRegistry{err: errors.New("registry unavailable")}
+	self, err := discovery.NewSelfRegistration(reg, &registry.ServiceInstance{ID: "gateway-1", Name: "gateway"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &drainer{}
+	d.onDrain(self.Deregister)
+	ctx := context.Background()
+	if err := self.Register(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(reg.registered) != 1 {
+		t.Fatalf("want the gateway registered but got %v", reg.registered)
+	}
+	// 排空时注销失败返回错误，之后的排空不再重复调用
+	if err := d.drain(ctx); !errors.Is(err, reg.err) {
+		t.Fatalf("want the deregister error but got %v", err)
+	}
+	reg.err = nil
+	if err := d.drain(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(reg.deregistered) != 0 {
+		t.Fatalf("want drain to run once but got %v", reg.deregistered)
+	}
+	// 停止时再次注销，已经注销后不再重复注销
+	if err := self.Deregister(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := self.Deregister(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(reg.deregistered) != 1 || reg.deregistered[0] != "gateway-1" {
+		t.Fatalf("want the gateway deregistered once but got %v", reg.deregistered)
+	}
+
+	// 正常情况下排空时注销
+	reg = &fakeRegistry{}
+	self, _ = discovery.NewSelfRegistration(reg, &registry.ServiceInstance{ID: "gateway-2", Name: "gateway"})
+	d = &drainer{}
+	d.onDrain(self.Deregister)
+	if err := self.Register(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.drain(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(reg.deregistered) != 1 || reg.deregistered[0] != "gateway-2" {
+		t.Fatalf("want the gateway deregistered on drain but got %v", reg.deregistered)
+	}
+
+	// 不支持注册实例的服务发现
+	if _, err := discovery.NewSelfRegistration(struct{ registry.Discovery }{}, nil); err == nil {
+		t.Fatal("want an error for a discovery without registrar")
+	}
+}
+
+func TestDrainDelay(t *testing.T) {
+	defer func(delay time.Duration) { drainDelay = delay }(drainDelay)
+	drainDelay = 100 * time.Millisecond
+
+	var drained bool
+	d := &drainer{}
+	d.onDrain(func(context.Context) error {
+		drained = true
+		return nil
+	})
+	start := time.Now()
+	if err := d.drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); !drained || elapsed < drainDelay {
+		t.Fatalf("want hooks called and %s waited but got %v after %s", drainDelay, drained, elapsed)
+	}
+	// 排空只生效一次，之后的调用不再等待
+	start = time.Now()
+	if err := d.drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed >= drainDelay {
+		t.Fatalf("want no wait on the second drain but got %s", elapsed)
+	}
+
+	// 等待不超过上下文的截止时间
+	d = &drainer{}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start = time.Now()
+	if err := d.drain(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed >= drainDelay {
+		t.Fatalf("want the wait bounded by the context but got %s", elapsed)
+	}
+}
@@ -0,0 +1,234 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cnsync/kratos/log"
+)
+
+const (
+	// _inheritedListenersEnv 是传递继承的监听套接字的环境变量，格式为 addr=fd,addr=fd
+	_inheritedListenersEnv = "PROXY_INHERITED_LISTENERS"
+	// _handoffReadyEnv 是传递通知父进程已就绪的管道的环境变量
+	_handoffReadyEnv = "PROXY_HANDOFF_READY_FD"
+)
+
+var (
+	// handoffTimeout 是等待新进程就绪的最长时间
+	handoffTimeout = 30 * time.Second
+	// handoffDrain 是交接后停止接受连接到返回之间的等待时间，使已经接受的连接读取完第一个请求，
+	// 避免这些连接在优雅停止时因为尚未开始处理请求而被直接关闭
+	handoffDrain = time.Second
+
+	// inheritedLock 保护 inherited
+	inheritedLock sync.Mutex
+	// inherited 是从父进程继承的监听套接字，键为监听时指定的地址
+	inherited = map[string]*os.File{}
+	// handoffReady 是通知父进程已就绪的管道，不是由父进程交接启动时为 nil
+	handoffReady *os.File
+
+	// activeLock 保护 active
+	activeLock sync.Mutex
+	// active 是正在监听的套接字，交接时传递给新进程
+	active = map[*trackedListener]struct{}{}
+)
+
+// 初始化函数，从环境变量中读取交接的超时时间和从父进程继承的监听套接字
+func init() {
+	var err error
+	if v := os.Getenv("PROXY_HANDOFF_TIMEOUT"); v != "" {
+		if handoffTimeout, err = time.ParseDuration(v); err != nil {
+			panic(err)
+		}
+	}
+	if v := os.Getenv("PROXY_HANDOFF_DRAIN"); v != "" {
+		if handoffDrain, err = time.ParseDuration(v); err != nil {
+			panic(err)
+		}
+	}
+	fds, err := parseInheritedListeners(os.Getenv(_inheritedListenersEnv))
+	if err != nil {
+		panic(err)
+	}
+	for addr, fd := range fds {
+		inherited[addr] = os.NewFile(uintptr(fd), "listener:"+addr)
+	}
+	if v := os.Getenv(_handoffReadyEnv); v != "" {
+		fd, err := strconv.Atoi(v)
+		if err != nil {
+			panic(err)
+		}
+		handoffReady = os.NewFile(uintptr(fd), "handoff-ready")
+	}
+	// 避免由本进程启动的其他进程误用这些文件描述符
+	os.Unsetenv(_inheritedListenersEnv)
+	os.Unsetenv(_handoffReadyEnv)
+}
+
+// parseInheritedListeners 函数解析继承的监听套接字的文件描述符，格式为 addr=fd,addr=fd
+func parseInheritedListeners(v string) (map[string]int, error) {
+	fds := map[string]int{}
+	if v == "" {
+		return fds, nil
+	}
+	for _, pair := range strings.Split(v, ",") {
+		addr, fdStr, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid inherited listener: %q", pair)
+		}
+		fd, err := strconv.Atoi(fdStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid inherited listener: %q: %w", pair, err)
+		}
+		fds[addr] = fd
+	}
+	return fds, nil
+}
+
+// formatInheritedListeners 函数按 parseInheritedListeners 的格式编码传递给新进程的监听套接字
+func formatInheritedListeners(fds map[string]int) string {
+	addrs := make([]string, 0, len(fds))
+	for addr := range fds {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	pairs := make([]string, 0, len(addrs))
+	for _, addr := range addrs {
+		pairs = append(pairs, addr+"="+strconv.Itoa(fds[addr]))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// inheritedListener 函数返回从父进程继承的监听指定地址的套接字，没有继承时返回 false
+func inheritedListener(addr string) (net.Listener, bool, error) {
+	inheritedLock.Lock()
+	f, ok := inherited[addr]
+	delete(inherited, addr)
+	inheritedLock.Unlock()
+	if !ok {
+		return nil, false, nil
+	}
+	defer f.Close()
+	ln, err := net.FileListener(f)
+	if err != nil {
+		return nil, true, fmt.Errorf("inherited listener %s: %w", addr, err)
+	}
+	log.Infof("proxy inherited listener on %s", addr)
+	return ln, true, nil
+}
+
+// NotifyHandoffReady 函数在新进程开始处理请求之后通知父进程退出，并关闭没有被使用的继承的监听套接字，
+// 不是由父进程交接启动时不做任何事
+func NotifyHandoffReady() {
+	inheritedLock.Lock()
+	for addr, f := range inherited {
+		log.Warnf("inherited listener %s is not used, closing it", addr)
+		_ = f.Close()
+	}
+	inherited = map[string]*os.File{}
+	inheritedLock.Unlock()
+	if handoffReady == nil {
+		return
+	}
+	if _, err := handoffReady.Write([]byte{1}); err != nil {
+		log.Errorf("failed to notify the parent process: %v", err)
+	}
+	_ = handoffReady.Close()
+	handoffReady = nil
+}
+
+// trackedListener 结构体记录正在监听的套接字，关闭时从交接列表中移除，交接后停止接受连接
+type trackedListener struct {
+	net.Listener
+	// addr 是监听时指定的地址，新进程按它查找继承的套接字
+	addr string
+	// paused 在交接后关闭，之后的连接由新进程接受
+	paused    chan struct{}
+	pauseOnce sync.Once
+	// closed 在套接字关闭时关闭
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+// track 函数将监听的套接字加入交接列表
+func track(ln net.Listener, addr string) net.Listener {
+	t := &trackedListener{Listener: ln, addr: addr, paused: make(chan struct{}), closed: make(chan struct{})}
+	activeLock.Lock()
+	active[t] = struct{}{}
+	activeLock.Unlock()
+	return t
+}
+
+// Accept 方法接受一个连接，交接后阻塞直到套接字关闭，使新的连接留在队列中由新进程接受
+func (l *trackedListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		select {
+		case <-l.paused:
+			// 交接时设置的截止时间使阻塞中的 Accept 返回
+			<-l.closed
+			return nil, net.ErrClosed
+		default:
+		}
+	}
+	return conn, err
+}
+
+// pause 方法停止接受新的连接
+func (l *trackedListener) pause() {
+	l.pauseOnce.Do(func() {
+		close(l.paused)
+		if d, ok := l.Listener.(interface{ SetDeadline(time.Time) error }); ok {
+			_ = d.SetDeadline(time.Now())
+		}
+	})
+}
+
+// Close 方法关闭套接字并将其从交接列表中移除
+func (l *trackedListener) Close() error {
+	l.closeOnce.Do(func() {
+		activeLock.Lock()
+		delete(active, l)
+		activeLock.Unlock()
+		close(l.closed)
+	})
+	return l.Listener.Close()
+}
+
+// pauseActiveListeners 函数使所有正在监听的套接字停止接受新的连接
+func pauseActiveListeners() {
+	activeLock.Lock()
+	defer activeLock.Unlock()
+	for l := range active {
+		l.pause()
+	}
+}
+
+// activeListenerFiles 函数复制所有正在监听的套接字的文件描述符，键为监听时指定的地址
+func activeListenerFiles() (map[string]*os.File, error) {
+	activeLock.Lock()
+	defer activeLock.Unlock()
+	files := make(map[string]*os.File, len(active))
+	for l := range active {
+		filer, ok := l.Listener.(interface{ File() (*os.File, error) })
+		if !ok {
+			continue
+		}
+		f, err := filer.File()
+		if err != nil {
+			for _, f := range files {
+				_ = f.Close()
+			}
+			return nil, fmt.Errorf("listener %s: %w", l.addr, err)
+		}
+		files[l.addr] = f
+	}
+	return files, nil
+}
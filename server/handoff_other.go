@@ -0,0 +1,16 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package server
+
+import (
+	"context"
+	"errors"
+)
+
+// Handoff 函数在不支持传递文件描述符的平台上返回错误
+func Handoff(ctx context.Context) error {
+	return errors.New("listener handoff is not supported on this platform")
+}
+
+// HandoffOnSignal 函数在不支持传递文件描述符的平台上不做任何事
+func HandoffOnSignal(ctx context.Context, stop func()) {}
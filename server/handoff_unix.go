@@ -0,0 +1,138 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/cnsync/kratos/log"
+)
+
+// handoffCommand 返回交接时启动的新进程的命令，默认以相同的参数启动当前的可执行文件
+var handoffCommand = func() (*exec.Cmd, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	return exec.Command(exe, os.Args[1:]...), nil
+}
+
+// Handoff 函数启动一个新的网关进程并将所有正在监听的套接字交给它，新进程就绪后当前进程停止接受连接，
+// 等待已经接受的连接开始处理请求之后返回，之后由调用方优雅地停止当前进程。交接期间监听的套接字一直处于打开状态，新的连接在新进程开始接受之前
+// 留在套接字的队列中，因此不会丢失连接
+func Handoff(ctx context.Context) error {
+	files, err := activeListenerFiles()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+	if len(files) == 0 {
+		return errors.New("no listener to hand off")
+	}
+	ready, readyW, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+	defer ready.Close()
+	cmd, err := handoffCommand()
+	if err != nil {
+		readyW.Close()
+		return err
+	}
+	// ExtraFiles 中的第 i 个文件在新进程中的文件描述符为 3+i
+	fds := make(map[string]int, len(files))
+	for addr, f := range files {
+		fds[addr] = 3 + len(cmd.ExtraFiles)
+		cmd.ExtraFiles = append(cmd.ExtraFiles, f)
+	}
+	readyFD := 3 + len(cmd.ExtraFiles)
+	cmd.ExtraFiles = append(cmd.ExtraFiles, readyW)
+	cmd.Env = handoffEnv(cmd.Env, formatInheritedListeners(fds), readyFD)
+	if cmd.Stdout == nil {
+		cmd.Stdout = os.Stdout
+	}
+	if cmd.Stderr == nil {
+		cmd.Stderr = os.Stderr
+	}
+	err = cmd.Start()
+	readyW.Close()
+	if err != nil {
+		return err
+	}
+	log.Infof("handing off listeners to process %d", cmd.Process.Pid)
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+	notified := make(chan bool, 1)
+	go func() {
+		// 新进程就绪时写入一个字节，退出时管道关闭
+		n, _ := ready.Read(make([]byte, 1))
+		notified <- n == 1
+	}()
+	ctx, cancel := context.WithTimeout(ctx, handoffTimeout)
+	defer cancel()
+	select {
+	case ok := <-notified:
+		if ok {
+			log.Infof("process %d is ready, handoff completed", cmd.Process.Pid)
+			pauseActiveListeners()
+			time.Sleep(handoffDrain)
+			return nil
+		}
+		err = <-exited
+		return fmt.Errorf("new process exited before ready: %v", err)
+	case <-ctx.Done():
+		_ = cmd.Process.Kill()
+		return fmt.Errorf("new process is not ready: %w", ctx.Err())
+	}
+}
+
+// handoffEnv 函数返回新进程的环境变量，base 为空时继承当前进程的环境变量
+func handoffEnv(base []string, listeners string, readyFD int) []string {
+	if base == nil {
+		base = os.Environ()
+	}
+	env := make([]string, 0, len(base)+2)
+	for _, kv := range base {
+		if strings.HasPrefix(kv, _inheritedListenersEnv+"=") || strings.HasPrefix(kv, _handoffReadyEnv+"=") {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return append(env, _inheritedListenersEnv+"="+listeners, _handoffReadyEnv+"="+strconv.Itoa(readyFD))
+}
+
+// HandoffOnSignal 函数在收到 SIGUSR2 时将监听的套接字交给新的网关进程，交接完成后调用 stop 停止当前进程，
+// 交接失败时当前进程继续处理请求
+func HandoffOnSignal(ctx context.Context, stop func()) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR2)
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+			}
+			if err := Handoff(ctx); err != nil {
+				log.Errorf("failed to hand off listeners: %v", err)
+				continue
+			}
+			stop()
+			return
+		}
+	}()
+}
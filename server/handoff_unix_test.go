@@ -0,0 +1,129 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestHandoffHelper 是交接测试中启动的新进程，它使用继承的套接字处理请求
+func TestHandoffHelper(t *testing.T) {
+	addr := os.Getenv("TEST_HANDOFF_ADDR")
+	if addr == "" {
+		t.Skip("only run as the new process of TestHandoff")
+	}
+	ln, err := listen(context.Background(), addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exit := make(chan struct{})
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/exit" {
+			close(exit)
+			return
+		}
+		_, _ = w.Write([]byte("child"))
+	})}
+	go srv.Serve(ln)
+	NotifyHandoffReady()
+	select {
+	case <-exit:
+	case <-time.After(30 * time.Second):
+	}
+	_ = srv.Close()
+}
+
+func TestHandoff(t *testing.T) {
+	const addr = "127.0.0.1:0"
+	ln, err := listen(context.Background(), addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	url := "http://" + ln.Addr().String()
+	parent := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("parent"))
+	})}
+	go parent.Serve(ln)
+
+	handoffDrain = 200 * time.Millisecond
+	handoffCommand = func() (*exec.Cmd, error) {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestHandoffHelper$")
+		cmd.Env = append(os.Environ(), "TEST_HANDOFF_ADDR="+addr)
+		cmd.Stdout = io.Discard
+		return cmd, nil
+	}
+	// 交接过程中持续使用新的连接发送请求
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}, Timeout: 5 * time.Second}
+	var (
+		stop     atomic.Bool
+		wg       sync.WaitGroup
+		lock     sync.Mutex
+		failures []error
+		served   = map[string]int{}
+	)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for !stop.Load() {
+				resp, err := client.Get(url)
+				if err != nil {
+					lock.Lock()
+					failures = append(failures, err)
+					lock.Unlock()
+					continue
+				}
+				body, _ := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				lock.Lock()
+				served[string(body)]++
+				lock.Unlock()
+			}
+		}()
+	}
+	time.Sleep(100 * time.Millisecond)
+	if err := Handoff(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	// 新进程就绪后停止当前进程
+	if err := parent.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	lock.Lock()
+	served = map[string]int{}
+	lock.Unlock()
+	time.Sleep(200 * time.Millisecond)
+	stop.Store(true)
+	wg.Wait()
+	defer client.Get(url + "/exit")
+
+	if len(failures) > 0 {
+		t.Fatalf("want no failed requests across the handoff but got %d: %v", len(failures), failures[0])
+	}
+	if served["parent"] != 0 || served["child"] == 0 {
+		t.Fatalf("want all requests served by the new process after the handoff but got %v", served)
+	}
+}
+
+func TestInheritedListeners(t *testing.T) {
+	fds := map[string]int{":8080": 3, "127.0.0.1:8443": 4}
+	got, err := parseInheritedListeners(formatInheritedListeners(fds))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, fds) {
+		t.Fatalf("want %v but got %v", fds, got)
+	}
+	if _, err := parseInheritedListeners(":8080"); err == nil {
+		t.Fatal("want an error for a malformed value")
+	}
+}
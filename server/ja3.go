@@ -0,0 +1,246 @@
+package server
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	// _maxClientHelloSize 是记录 ClientHello 时最多缓冲的字节数，超过时放弃计算指纹
+	_maxClientHelloSize = 64 * 1024
+	// _extSupportedGroups 是 supported_groups（elliptic_curves）扩展的类型
+	_extSupportedGroups = 10
+	// _extPointFormats 是 ec_point_formats 扩展的类型
+	_extPointFormats = 11
+)
+
+// errNotClientHello 表示连接的第一条握手消息不是 ClientHello
+var errNotClientHello = errors.New("not a tls client hello")
+
+// ja3Key 是 TLS 连接在请求上下文中的键
+type ja3Key struct{}
+
+// ja3Listener 结构体为接受的连接记录 TLS ClientHello 并计算 JA3 指纹，需要位于 TLS 监听器之下
+type ja3Listener struct {
+	net.Listener
+}
+
+// Accept 方法接受一个新的连接，并包装为 ja3Conn
+func (l *ja3Listener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &ja3Conn{Conn: conn}, nil
+}
+
+// ja3Conn 结构体在 TLS 握手期间记录客户端发送的数据，读取到完整的 ClientHello 后计算 JA3 指纹
+type ja3Conn struct {
+	net.Conn
+	// buf 是已记录的数据，只在握手期间由读取协程访问
+	buf []byte
+	// done 表示是否已经停止记录
+	done bool
+	// fingerprint 是计算得到的 JA3 指纹
+	fingerprint atomic.Pointer[string]
+}
+
+// Read 方法读取连接中的数据，并在停止记录前记录读取的数据
+func (c *ja3Conn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if !c.done && n > 0 {
+		c.buf = append(c.buf, p[:n]...)
+		fp, complete, perr := parseJA3(c.buf)
+		switch {
+		case perr != nil || len(c.buf) > _maxClientHelloSize:
+			c.done, c.buf = true, nil
+		case complete:
+			c.fingerprint.Store(&fp)
+			c.done, c.buf = true, nil
+		}
+	}
+	return n, err
+}
+
+// Fingerprint 方法返回连接的 JA3 指纹，握手未完成或无法解析时返回空字符串
+func (c *ja3Conn) Fingerprint() string {
+	if fp := c.fingerprint.Load(); fp != nil {
+		return *fp
+	}
+	return ""
+}
+
+// isGREASE 函数判断值是否是 RFC 8701 定义的 GREASE 值，JA3 计算时忽略这些值
+func isGREASE(v uint16) bool {
+	return v&0x0f0f == 0x0a0a && v>>8 == v&0xff
+}
+
+// joinUint16 函数将忽略 GREASE 后的值以 - 连接
+func joinUint16(values []uint16) string {
+	parts := make([]string, 0, len(values))
+	for _, v := range values {
+		if !isGREASE(v) {
+			parts = append(parts, strconv.Itoa(int(v)))
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// clientHelloMessage 函数从 TLS 记录中拼接出第一条握手消息，数据不完整时返回 false
+func clientHelloMessage(data []byte) ([]byte, bool, error) {
+	var msg []byte
+	for {
+		if len(data) < 5 {
+			return nil, false, nil
+		}
+		// 握手记录的类型为 22
+		if data[0] != 22 {
+			return nil, false, errNotClientHello
+		}
+		length := int(binary.BigEndian.Uint16(data[3:5]))
+		if len(data) < 5+length {
+			return nil, false, nil
+		}
+		msg = append(msg, data[5:5+length]...)
+		data = data[5+length:]
+		if len(msg) < 4 {
+			continue
+		}
+		// ClientHello 的消息类型为 1
+		if msg[0] != 1 {
+			return nil, false, errNotClientHello
+		}
+		size := int(msg[1])<<16 | int(msg[2])<<8 | int(msg[3])
+		if len(msg) >= 4+size {
+			return msg[4 : 4+size], true, nil
+		}
+	}
+}
+
+// helloReader 结构体按 TLS 的编码格式读取 ClientHello 的字段
+type helloReader struct {
+	data []byte
+	err  bool
+}
+
+// next 方法读取 n 个字节
+func (r *helloReader) next(n int) []byte {
+	if r.err || len(r.data) < n {
+		r.err = true
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+// uint8 方法读取一个单字节整数
+func (r *helloReader) uint8() int {
+	if b := r.next(1); b != nil {
+		return int(b[0])
+	}
+	return 0
+}
+
+// uint16 方法读取一个双字节整数
+func (r *helloReader) uint16() int {
+	if b := r.next(2); b != nil {
+		return int(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+// uint16s 方法将字节序列解析为双字节整数列表
+func uint16s(b []byte) []uint16 {
+	values := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		values = append(values, binary.BigEndian.Uint16(b[i:]))
+	}
+	return values
+}
+
+// parseJA3 函数从客户端发送的 TLS 记录中解析 ClientHello 并计算 JA3 指纹，
+// 格式为 md5(版本,密码套件,扩展,椭圆曲线,椭圆曲线点格式)，数据不完整时返回 false
+func parseJA3(data []byte) (string, bool, error) {
+	msg, complete, err := clientHelloMessage(data)
+	if err != nil || !complete {
+		return "", complete, err
+	}
+	r := &helloReader{data: msg}
+	version := r.uint16()
+	// random
+	r.next(32)
+	// session_id
+	r.next(r.uint8())
+	ciphers := uint16s(r.next(r.uint16()))
+	// compression_methods
+	r.next(r.uint8())
+	var extensions, curves []uint16
+	var points []string
+	if len(r.data) > 0 {
+		ext := &helloReader{data: r.next(r.uint16())}
+		for len(ext.data) > 0 && !ext.err {
+			typ := uint16(ext.uint16())
+			body := &helloReader{data: ext.next(ext.uint16())}
+			extensions = append(extensions, typ)
+			switch typ {
+			case _extSupportedGroups:
+				curves = uint16s(body.next(body.uint16()))
+			case _extPointFormats:
+				for _, p := range body.next(body.uint8()) {
+					points = append(points, strconv.Itoa(int(p)))
+				}
+			}
+		}
+		if ext.err {
+			return "", false, errNotClientHello
+		}
+	}
+	if r.err {
+		return "", false, errNotClientHello
+	}
+	s := strings.Join([]string{
+		strconv.Itoa(version),
+		joinUint16(ciphers),
+		joinUint16(extensions),
+		joinUint16(curves),
+		strings.Join(points, "-"),
+	}, ",")
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:]), true, nil
+}
+
+// ja3ConnContext 函数将 TLS 连接底层的 ja3Conn 保存到连接的上下文中
+func ja3ConnContext(ctx context.Context, c net.Conn) context.Context {
+	if tc, ok := c.(interface{ NetConn() net.Conn }); ok {
+		if jc, ok := tc.NetConn().(*ja3Conn); ok {
+			return context.WithValue(ctx, ja3Key{}, jc)
+		}
+	}
+	return ctx
+}
+
+// JA3Handler 函数包装处理程序，将 TLS 连接的 JA3 指纹设置到请求头 header 中转发给上游，
+// 客户端自行携带的同名请求头总是被删除，因此明文连接的请求不会带有该请求头，header 为空时直接返回原处理程序
+func JA3Handler(next http.Handler, header string) http.Handler {
+	if header == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Del(header)
+		if jc, ok := r.Context().Value(ja3Key{}).(*ja3Conn); ok {
+			if fp := jc.Fingerprint(); fp != "" {
+				r.Header.Set(header, fp)
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
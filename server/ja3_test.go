@@ -0,0 +1,131 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// newTestTLSConfig 函数生成一个使用自签名证书的 TLS 配置
+func newTestTLSConfig(t *testing.T) *tls.Config {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &tls.Config{Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}}}
+}
+
+// serveJA3 函数启动一个代理服务器，处理程序返回上游收到的 JA3 指纹请求头
+func serveJA3(t *testing.T, srv *ProxyServer) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.serve(ln) }()
+	t.Cleanup(func() { _ = srv.Close() })
+	return ln.Addr().String()
+}
+
+// fingerprintHandler 返回上游收到的 JA3 指纹请求头
+var fingerprintHandler = JA3Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	_, _ = io.WriteString(w, r.Header.Get("X-JA3-Fingerprint"))
+}), "X-JA3-Fingerprint")
+
+// getFingerprint 函数发送一个伪造了指纹请求头的请求，返回上游收到的指纹
+func getFingerprint(t *testing.T, c *http.Client, url string) string {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("X-JA3-Fingerprint", "spoofed")
+	resp, err := c.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	return string(b)
+}
+
+func TestJA3TLS(t *testing.T) {
+	addr := serveJA3(t, NewTLSProxy(fingerprintHandler, "", newTestTLSConfig(t), true))
+	for _, h2 := range []bool{false, true} {
+		tr := &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, ForceAttemptHTTP2: h2}
+		if !h2 {
+			tr.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		}
+		c := &http.Client{Transport: tr}
+		fp := getFingerprint(t, c, "https://"+addr+"/")
+		if b, err := hex.DecodeString(fp); err != nil || len(b) != 16 {
+			t.Fatalf("h2=%v: want an md5 JA3 fingerprint but got %q", h2, fp)
+		}
+		// 同一个客户端的指纹保持不变
+		if again := getFingerprint(t, c, "https://"+addr+"/"); again != fp {
+			t.Fatalf("h2=%v: want the same fingerprint but got %q and %q", h2, fp, again)
+		}
+		tr.CloseIdleConnections()
+	}
+}
+
+func TestJA3Plaintext(t *testing.T) {
+	addr := serveJA3(t, NewProxy(fingerprintHandler, ""))
+	if fp := getFingerprint(t, http.DefaultClient, "http://"+addr+"/"); fp != "" {
+		t.Fatalf("want no fingerprint for plaintext connections but got %q", fp)
+	}
+}
+
+func TestParseJA3(t *testing.T) {
+	// TLS 1.2 的 ClientHello，密码套件包含一个 GREASE 值，扩展为 supported_groups 和 ec_point_formats
+	hello := []byte{
+		0x03, 0x03, // client_version
+	}
+	hello = append(hello, make([]byte, 32)...) // random
+	hello = append(hello, 0x00)                // session_id
+	hello = append(hello, 0x00, 0x06, 0x0a, 0x0a, 0xc0, 0x2f, 0xc0, 0x30)
+	hello = append(hello, 0x01, 0x00) // compression_methods
+	exts := []byte{
+		0x00, 0x0a, 0x00, 0x06, 0x00, 0x04, 0x00, 0x1d, 0x00, 0x17, // supported_groups: 29, 23
+		0x00, 0x0b, 0x00, 0x02, 0x01, 0x00, // ec_point_formats: 0
+	}
+	hello = append(hello, byte(len(exts)>>8), byte(len(exts)))
+	hello = append(hello, exts...)
+	msg := append([]byte{0x01, 0x00, byte(len(hello) >> 8), byte(len(hello))}, hello...)
+	record := append([]byte{0x16, 0x03, 0x01, byte(len(msg) >> 8), byte(len(msg))}, msg...)
+
+	// 数据不完整时继续等待
+	if _, complete, err := parseJA3(record[:20]); complete || err != nil {
+		t.Fatalf("want incomplete but got complete=%v err=%v", complete, err)
+	}
+	fp, complete, err := parseJA3(record)
+	if err != nil || !complete {
+		t.Fatalf("want complete but got complete=%v err=%v", complete, err)
+	}
+	// md5("771,49199-49200,10-11,29-23,0")
+	if want := "e4b7d22de660762a660917bc575d312a"; fp != want {
+		t.Fatalf("want fingerprint %s but got %s", want, fp)
+	}
+	if _, _, err := parseJA3([]byte("GET / HTTP/1.1\r\n")); err == nil {
+		t.Fatal("want an error for non tls data")
+	}
+}
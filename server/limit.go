@@ -0,0 +1,150 @@
+package server
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// maxConnsPerIP 是每个客户端 IP 的最大并发连接数，从环境变量 PROXY_MAX_CONNS_PER_IP 中读取，为 0 时不限制
+	maxConnsPerIP int
+	// maxConnRatePerIP 是每个客户端 IP 每秒最多新建的连接数，从环境变量 PROXY_MAX_CONN_RATE_PER_IP 中读取，为 0 时不限制
+	maxConnRatePerIP int
+)
+
+// _metricRejectedConns 统计了因超过客户端 IP 的连接限制而被拒绝的连接数量
+var _metricRejectedConns = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "go",
+	Subsystem: "gateway",
+	Name:      "connections_rejected_total",
+	Help:      "The total number of connections rejected by the per client ip limits or terminated for slow request bodies",
+}, []string{"reason"})
+
+func init() {
+	var err error
+	if v := os.Getenv("PROXY_MAX_CONNS_PER_IP"); v != "" {
+		if maxConnsPerIP, err = strconv.Atoi(v); err != nil {
+			panic(err)
+		}
+	}
+	if v := os.Getenv("PROXY_MAX_CONN_RATE_PER_IP"); v != "" {
+		if maxConnRatePerIP, err = strconv.Atoi(v); err != nil {
+			panic(err)
+		}
+	}
+	prometheus.MustRegister(_metricRejectedConns)
+}
+
+// limitListener 结构体限制每个客户端 IP 的并发连接数和新建连接的速率，超过限制的连接在接受后立即关闭
+type limitListener struct {
+	net.Listener
+	maxConns int
+	maxRate  int
+	now      func() time.Time
+
+	lock sync.Mutex
+	// conns 是每个客户端 IP 当前的连接数
+	conns map[string]int
+	// window 是当前统计新建连接速率的时间窗口，精确到秒
+	window int64
+	// rates 是每个客户端 IP 在当前时间窗口内新建的连接数
+	rates map[string]int
+}
+
+// newLimitListener 函数创建一个限制客户端 IP 连接数的监听器，两个限制都为 0 时直接返回原监听器
+func newLimitListener(ln net.Listener, maxConns, maxRate int) net.Listener {
+	if maxConns <= 0 && maxRate <= 0 {
+		return ln
+	}
+	return &limitListener{
+		Listener: ln,
+		maxConns: maxConns,
+		maxRate:  maxRate,
+		now:      time.Now,
+		conns:    map[string]int{},
+		rates:    map[string]int{},
+	}
+}
+
+// Accept 方法接受一个新的连接，超过限制的连接被立即关闭，然后继续等待下一个连接
+func (l *limitListener) Accept() (net.Conn, error) {
+	for {
+		conn, err := l.Listener.Accept()
+		if err != nil {
+			return nil, err
+		}
+		ip := remoteIP(conn)
+		if reason := l.acquire(ip); reason != "" {
+			_metricRejectedConns.WithLabelValues(reason).Inc()
+			_ = conn.Close()
+			continue
+		}
+		return &limitConn{Conn: conn, release: func() { l.release(ip) }}, nil
+	}
+}
+
+// acquire 方法为客户端 IP 占用一个连接名额，超过限制时返回拒绝的原因
+func (l *limitListener) acquire(ip string) string {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.maxRate > 0 {
+		// 进入新的时间窗口时重置所有客户端 IP 的计数
+		if window := l.now().Unix(); window != l.window {
+			l.window = window
+			l.rates = map[string]int{}
+		}
+		if l.rates[ip] >= l.maxRate {
+			return "rate"
+		}
+		l.rates[ip]++
+	}
+	if l.maxConns > 0 {
+		if l.conns[ip] >= l.maxConns {
+			return "concurrency"
+		}
+		l.conns[ip]++
+	}
+	return ""
+}
+
+// release 方法在连接关闭时归还客户端 IP 的连接名额
+func (l *limitListener) release(ip string) {
+	if l.maxConns <= 0 {
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.conns[ip] <= 1 {
+		delete(l.conns, ip)
+		return
+	}
+	l.conns[ip]--
+}
+
+// remoteIP 函数返回连接的客户端 IP
+func remoteIP(conn net.Conn) string {
+	addr := conn.RemoteAddr().String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// limitConn 结构体在连接关闭时归还连接名额
+type limitConn struct {
+	net.Conn
+	once    sync.Once
+	release func()
+}
+
+// Close 方法关闭连接并归还连接名额，多次调用只归还一次
+func (c *limitConn) Close() error {
+	err := c.Conn.Close()
+	c.once.Do(c.release)
+	return err
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// accepted 函数持续接受连接，并将接受的连接发送到返回的通道中
+func accepted(ln net.Listener) <-chan net.Conn {
+	ch := make(chan net.Conn, 16)
+	go func() {
+		defer close(ch)
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			ch <- conn
+		}
+	}()
+	return ch
+}
+
+// rejected 函数判断连接是否被服务端关闭
+func rejected(t *testing.T, conn net.Conn) bool {
+	_ = conn.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+	_, err := conn.Read(make([]byte, 1))
+	if err == io.EOF {
+		return true
+	}
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		return false
+	}
+	// 连接被重置同样表示被拒绝
+	return err != nil
+}
+
+func TestLimitListenerConcurrency(t *testing.T) {
+	raw, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln := newLimitListener(raw, 2, 0)
+	defer ln.Close()
+	conns := accepted(ln)
+
+	var clients []net.Conn
+	for i := 0; i < 5; i++ {
+		c, err := net.Dial("tcp", ln.Addr().String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		clients = append(clients, c)
+	}
+	var kept []net.Conn
+	for i, c := range clients {
+		if i < 2 {
+			kept = append(kept, <-conns)
+			if rejected(t, c) {
+				t.Fatalf("connection %d: want accepted but got rejected", i)
+			}
+			continue
+		}
+		if !rejected(t, c) {
+			t.Fatalf("connection %d: want rejected but got accepted", i)
+		}
+	}
+
+	// 关闭一个连接后归还名额，可以建立新的连接
+	_ = kept[0].Close()
+	c, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	select {
+	case <-conns:
+	case <-time.After(time.Second):
+		t.Fatal("want the new connection accepted after one is closed")
+	}
+	if rejected(t, c) {
+		t.Fatal("want the new connection accepted after one is closed")
+	}
+}
+
+func TestLimitListenerRate(t *testing.T) {
+	l := newLimitListener(nil, 0, 2).(*limitListener)
+	now := time.Unix(1000, 0)
+	l.now = func() time.Time { return now }
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{"10.0.0.1", ""},
+		{"10.0.0.1", ""},
+		{"10.0.0.1", "rate"},
+		// 不同的客户端 IP 互不影响
+		{"10.0.0.2", ""},
+	}
+	for i, tt := range tests {
+		if got := l.acquire(tt.ip); got != tt.want {
+			t.Fatalf("%d: want %q but got %q", i, tt.want, got)
+		}
+	}
+	// 进入新的时间窗口后重新计数
+	now = now.Add(time.Second)
+	if got := l.acquire("10.0.0.1"); got != "" {
+		t.Fatalf("want accepted in the new window but got %q", got)
+	}
+	if ln := newLimitListener(nil, 0, 0); ln != nil {
+		t.Fatal("want the original listener when no limit is set")
+	}
+}
@@ -0,0 +1,200 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/cnsync/kratos/log"
+)
+
+// Listeners 结构体管理一组共享同一个处理程序的代理监听器，支持在运行时并发安全地添加和移除监听器
+type Listeners struct {
+	// handler 是所有监听器共用的处理程序
+	handler http.Handler
+	// addrs 是启动时需要监听的地址
+	addrs []string
+	// lock 保护 servers
+	lock sync.Mutex
+	// servers 保存了所有正在运行的代理服务器，键为实际监听的地址
+	servers map[string]*ProxyServer
+}
+
+// NewListeners 函数创建一个新的 Listeners 实例，addrs 中的地址将在 Start 时监听
+func NewListeners(handler http.Handler, addrs ...string) *Listeners {
+	return &Listeners{
+		handler: handler,
+		addrs:   addrs,
+		servers: map[string]*ProxyServer{},
+	}
+}
+
+// Start 方法监听启动时指定的所有地址，任一地址监听失败时关闭已监听的地址并返回错误
+func (l *Listeners) Start(ctx context.Context) error {
+	for _, addr := range l.addrs {
+		if _, err := l.Add(addr); err != nil {
+			_ = l.Stop(ctx)
+			return err
+		}
+	}
+	return nil
+}
+
+// Stop 方法先排空连接，然后优雅地关闭所有监听器
+func (l *Listeners) Stop(ctx context.Context) error {
+	if err := Drain(ctx); err != nil {
+		log.Errorf("failed to drain: %v", err)
+	}
+	l.lock.Lock()
+	servers := l.servers
+	l.servers = map[string]*ProxyServer{}
+	l.lock.Unlock()
+	var errs []error
+	for addr, srv := range servers {
+		log.Infof("proxy stopping on %s", addr)
+		if err := srv.Shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", addr, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Add 方法监听一个新的地址并开始处理请求，返回实际监听的地址，例如 :0 会被解析为随机分配的端口
+func (l *Listeners) Add(addr string) (string, error) {
+	// 先完成绑定，以便将端口被占用等错误同步返回给调用方
+	ln, err := listen(context.Background(), addr)
+	if err != nil {
+		return "", err
+	}
+	actual := ln.Addr().String()
+	srv := NewProxy(l.handler, actual)
+	l.lock.Lock()
+	if _, ok := l.servers[actual]; ok {
+		l.lock.Unlock()
+		_ = ln.Close()
+		return "", fmt.Errorf("listener already exists: %s", actual)
+	}
+	l.servers[actual] = srv
+	l.lock.Unlock()
+	log.Infof("proxy listening on %s", actual)
+	go func() {
+		if err := srv.Serve(newLimitListener(ln, maxConnsPerIP, maxConnRatePerIP)); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Errorf("proxy listener %s exited: %v", actual, err)
+			l.lock.Lock()
+			if l.servers[actual] == srv {
+				delete(l.servers, actual)
+			}
+			l.lock.Unlock()
+		}
+	}()
+	return actual, nil
+}
+
+// Remove 方法停止监听指定的地址，并等待该监听器上正在处理的请求完成
+func (l *Listeners) Remove(ctx context.Context, addr string) error {
+	srv, err := l.detach(addr)
+	if err != nil {
+		return err
+	}
+	return srv.Shutdown(ctx)
+}
+
+// detach 方法将指定地址的代理服务器从管理列表中移除并返回
+func (l *Listeners) detach(addr string) (*ProxyServer, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	srv, ok := l.servers[addr]
+	if !ok {
+		// 允许使用 :8080 这样的简写地址
+		for actual, s := range l.servers {
+			if sameAddr(actual, addr) {
+				addr, srv, ok = actual, s, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return nil, fmt.Errorf("listener not found: %s", addr)
+	}
+	delete(l.servers, addr)
+	log.Infof("proxy stopping on %s", addr)
+	return srv, nil
+}
+
+// Addrs 方法返回所有正在监听的地址
+func (l *Listeners) Addrs() []string {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	addrs := make([]string, 0, len(l.servers))
+	for addr := range l.servers {
+		addrs = append(addrs, addr)
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+// sameAddr 函数判断两个监听地址是否相同，主机为空时视为监听所有地址
+func sameAddr(actual, addr string) bool {
+	ah, ap, err := net.SplitHostPort(actual)
+	if err != nil {
+		return false
+	}
+	h, p, err := net.SplitHostPort(addr)
+	if err != nil || ap != p {
+		return false
+	}
+	if h == "" {
+		ip := net.ParseIP(ah)
+		return ip != nil && ip.IsUnspecified()
+	}
+	return h == ah
+}
+
+// DebugHandler 方法返回管理监听器的调试处理程序
+func (l *Listeners) DebugHandler() http.Handler {
+	debugMux := http.NewServeMux()
+	debugMux.HandleFunc("/debug/listeners/inspect", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(rw).Encode(l.Addrs())
+	})
+	debugMux.HandleFunc("/debug/listeners/add", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		addr, err := l.Add(r.URL.Query().Get("addr"))
+		if err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			_, _ = rw.Write([]byte(err.Error()))
+			return
+		}
+		rw.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(rw).Encode(map[string]string{"addr": addr})
+	})
+	debugMux.HandleFunc("/debug/listeners/remove", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			rw.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		srv, err := l.detach(r.URL.Query().Get("addr"))
+		if err != nil {
+			rw.WriteHeader(http.StatusBadRequest)
+			_, _ = rw.Write([]byte(err.Error()))
+			return
+		}
+		// 当前请求可能正是经由被移除的监听器到达的，因此在后台等待其上的请求完成，
+		// Shutdown 会立即关闭监听的端口
+		go func() {
+			if err := srv.Shutdown(context.Background()); err != nil {
+				log.Errorf("failed to shutdown proxy listener %s: %v", srv.Addr, err)
+			}
+		}()
+		rw.WriteHeader(http.StatusNoContent)
+	})
+	return debugMux
+}
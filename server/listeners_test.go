@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func get(t *testing.T, addr string) string {
+	resp, err := http.Get("http://" + addr + "/hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestListeners(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	})
+	l := NewListeners(handler, "127.0.0.1:0")
+	ctx := context.Background()
+	if err := l.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer l.Stop(ctx)
+	if len(l.Addrs()) != 1 {
+		t.Fatalf("want 1 listener but got: %v", l.Addrs())
+	}
+	first := l.Addrs()[0]
+
+	// 运行时添加一个新的监听器
+	second, err := l.Add("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, addr := range []string{first, second} {
+		if got := get(t, addr); got != "hello" {
+			t.Fatalf("%s: want hello but got %q", addr, got)
+		}
+	}
+	// 重复绑定已被占用的端口会返回错误
+	if _, err := l.Add(second); err == nil {
+		t.Fatal("want error when binding an address in use")
+	}
+
+	// 移除旧的监听器后端口被释放，新的监听器不受影响
+	if err := l.Remove(ctx, first); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := http.Get("http://" + first + "/hello"); err == nil {
+		t.Fatal("want error after the listener is removed")
+	}
+	if got := get(t, second); got != "hello" {
+		t.Fatalf("want hello but got %q", got)
+	}
+	if err := l.Remove(ctx, first); err == nil {
+		t.Fatal("want error when removing an unknown listener")
+	}
+}
+
+func TestListenersDebugHandler(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello"))
+	})
+	l := NewListeners(handler)
+	defer l.Stop(context.Background())
+	debugHandler := l.DebugHandler()
+
+	w := httptest.NewRecorder()
+	debugHandler.ServeHTTP(w, httptest.NewRequest("GET", "/debug/listeners/add?addr=127.0.0.1:0", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("want status 405 but got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	debugHandler.ServeHTTP(w, httptest.NewRequest("POST", "/debug/listeners/add?addr=127.0.0.1:0", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("want status 200 but got %d: %s", w.Code, w.Body.String())
+	}
+	out := map[string]string{}
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if got := get(t, out["addr"]); got != "hello" {
+		t.Fatalf("want hello but got %q", got)
+	}
+
+	w = httptest.NewRecorder()
+	debugHandler.ServeHTTP(w, httptest.NewRequest("POST", "/debug/listeners/add?addr=invalid", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("want status 400 but got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	debugHandler.ServeHTTP(w, httptest.NewRequest("POST", "/debug/listeners/remove?addr="+out["addr"], nil))
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("want status 204 but got %d: %s", w.Code, w.Body.String())
+	}
+	if len(l.Addrs()) != 0 {
+		t.Fatalf("want no listener but got: %v", l.Addrs())
+	}
+}
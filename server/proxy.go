@@ -2,8 +2,10 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"math"
+	"net"
 	"net/http"
 	"os"
 	"time"
@@ -22,6 +24,8 @@ var (
 	writeTimeout = time.Second * 15
 	// 定义变量 idleTimeout，设置连接空闲超时时间为 120 秒
 	idleTimeout = time.Second * 120
+	// 定义变量 drainDelay，设置开始排空连接之后到关闭监听器之间的等待时间，默认不等待
+	drainDelay time.Duration
 )
 
 // 初始化函数，从环境变量中读取配置
@@ -59,12 +63,22 @@ func init() {
 			panic(err)
 		}
 	}
+	// 尝试从环境变量中读取 PROXY_DRAIN_DELAY 的值
+	if v := os.Getenv("PROXY_DRAIN_DELAY"); v != "" {
+		// 如果读取成功，则尝试将其解析为 time.Duration 类型
+		if drainDelay, err = time.ParseDuration(v); err != nil {
+			// 如果解析失败，则抛出异常
+			panic(err)
+		}
+	}
 }
 
 // ProxyServer 代理服务器
 type ProxyServer struct {
 	// 嵌入 http.Server 类型，以便使用其方法和字段
 	*http.Server
+	// ja3 表示是否计算 TLS 客户端的 JA3 指纹
+	ja3 bool
 }
 
 // NewProxy 函数用于创建一个新的代理服务器实例
@@ -74,8 +88,8 @@ func NewProxy(handler http.Handler, addr string) *ProxyServer {
 		Server: &http.Server{
 			// 设置服务器监听的地址
 			Addr: addr,
-			// 使用 h2c.NewHandler 包装处理程序，支持 HTTP/2 协议
-			Handler: h2c.NewHandler(handler, &http2.Server{
+			// 使用 h2c.NewHandler 包装处理程序，支持 HTTP/2 协议，并终止请求体传输过慢的连接
+			Handler: h2c.NewHandler(minRateHandler(handler, minBodyRate, minBodyRateGrace), &http2.Server{
 				// 设置空闲超时时间
 				IdleTimeout: idleTimeout,
 				// 设置最大并发流数
@@ -97,8 +111,18 @@ func NewProxy(handler http.Handler, addr string) *ProxyServer {
 func (s *ProxyServer) Start(ctx context.Context) error {
 	// 记录日志，显示代理服务器正在监听的地址
 	log.Infof("proxy listening on %s", s.Addr)
-	// 调用 http.Server 的 ListenAndServe 方法，开始监听并处理请求
-	err := s.ListenAndServe()
+	// 监听地址
+	addr := s.Addr
+	if addr == "" {
+		addr = ":http"
+	}
+	// 按配置的套接字选项监听，例如 SO_REUSEPORT 和 TCP keep-alive
+	ln, err := listen(ctx, addr)
+	if err != nil {
+		return err
+	}
+	// 调用 serve 方法，开始处理请求
+	err = s.serve(ln)
 	// 如果发生错误，并且错误类型是 http.ErrServerClosed
 	if errors.Is(err, http.ErrServerClosed) {
 		// 这表示服务器已经被关闭，返回 nil 表示没有错误
@@ -108,10 +132,27 @@ func (s *ProxyServer) Start(ctx context.Context) error {
 	return err
 }
 
+// serve 方法在监听器上处理请求，并限制每个客户端 IP 的连接数
+func (s *ProxyServer) serve(ln net.Listener) error {
+	ln = newLimitListener(ln, maxConnsPerIP, maxConnRatePerIP)
+	// 配置了 TLS 时在监听器上完成握手，JA3 指纹需要在握手之前记录 ClientHello
+	if s.TLSConfig != nil {
+		if s.ja3 {
+			ln = &ja3Listener{Listener: ln}
+		}
+		ln = tls.NewListener(ln, s.TLSConfig)
+	}
+	return s.Serve(ln)
+}
+
 // Stop 方法用于停止代理服务器的运行
 func (s *ProxyServer) Stop(ctx context.Context) error {
 	// 记录日志，显示代理服务器正在停止
 	log.Info("proxy stopping")
-	// 调用 http.Server 的 Shutdown 方法，停止服务器的运行
+	// 先排空连接，就绪探针返回未就绪并等待负载均衡器摘除网关之后再关闭监听器
+	if err := Drain(ctx); err != nil {
+		log.Errorf("failed to drain: %v", err)
+	}
+	// 调用 http.Server 的 Shutdown 方法，停止服务器的运行，处理中的请求会继续完成
 	return s.Shutdown(ctx)
 }
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+var (
+	// minBodyRate 是请求体的最低传输速率（字节/秒），从环境变量 PROXY_MIN_BODY_RATE 中读取，为 0 时不限制
+	minBodyRate int64
+	// minBodyRateGrace 是开始检查请求体传输速率前的宽限时间，从环境变量 PROXY_MIN_BODY_RATE_GRACE 中读取
+	minBodyRateGrace = time.Second * 5
+)
+
+// errSlowBody 表示请求体的传输速率低于最低速率
+var errSlowBody = errors.New("request body is sent slower than the minimum data rate")
+
+func init() {
+	var err error
+	if v := os.Getenv("PROXY_MIN_BODY_RATE"); v != "" {
+		if minBodyRate, err = strconv.ParseInt(v, 10, 64); err != nil {
+			panic(err)
+		}
+	}
+	if v := os.Getenv("PROXY_MIN_BODY_RATE_GRACE"); v != "" {
+		if minBodyRateGrace, err = time.ParseDuration(v); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// minRateHandler 函数包装处理程序，请求体的平均传输速率在宽限时间之后低于 rate 时终止连接，rate 为 0 时直接返回原处理程序
+func minRateHandler(next http.Handler, rate int64, grace time.Duration) http.Handler {
+	if rate <= 0 {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Body != nil && r.Body != http.NoBody {
+			now := time.Now()
+			body := &minRateBody{
+				ReadCloser: r.Body,
+				controller: http.NewResponseController(w),
+				rate:       rate,
+				grace:      grace,
+				start:      now,
+			}
+			// 不延长服务器本身的读取超时时间
+			if readTimeout > 0 {
+				body.limit = now.Add(readTimeout)
+			}
+			body.extend()
+			r.Body = body
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// minRateBody 结构体根据已读取的字节数不断推迟连接的读取截止时间，
+// 发送方必须在 start+grace+read/rate 之前送达下一段数据，否则读取超时并终止连接
+type minRateBody struct {
+	io.ReadCloser
+	controller *http.ResponseController
+	rate       int64
+	grace      time.Duration
+	start      time.Time
+	// limit 是服务器读取超时对应的截止时间，为零值时不限制
+	limit time.Time
+	// read 是已读取的字节数
+	read int64
+	// slow 表示已经因传输过慢而终止
+	slow bool
+}
+
+// extend 方法根据已读取的字节数推迟读取截止时间
+func (b *minRateBody) extend() {
+	deadline := b.start.Add(b.grace + time.Duration(float64(b.read)/float64(b.rate)*float64(time.Second)))
+	if !b.limit.IsZero() && deadline.After(b.limit) {
+		deadline = b.limit
+	}
+	// 不支持设置截止时间的连接（例如测试中的 ResponseWriter）不做限制
+	_ = b.controller.SetReadDeadline(deadline)
+}
+
+// Read 方法读取请求体，读取超时时返回 errSlowBody
+func (b *minRateBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+	switch {
+	case err == nil:
+		b.extend()
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		if !b.slow {
+			b.slow = true
+			_metricRejectedConns.WithLabelValues("slow_body").Inc()
+		}
+		return n, errSlowBody
+	default:
+		// 请求体读取完毕后恢复服务器本身的读取截止时间，避免影响后续等待上游响应的过程
+		_ = b.controller.SetReadDeadline(b.limit)
+	}
+	return n, err
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// serveMinRate 函数启动一个限制请求体最低传输速率的代理服务器，处理程序读取完整的请求体
+func serveMinRate(t *testing.T, rate int64, grace time.Duration) string {
+	oldRate, oldGrace := minBodyRate, minBodyRateGrace
+	minBodyRate, minBodyRateGrace = rate, grace
+	t.Cleanup(func() { minBodyRate, minBodyRateGrace = oldRate, oldGrace })
+
+	srv := NewProxy(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, "%d", len(b))
+	}), "")
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Serve(ln) }()
+	t.Cleanup(func() { _ = srv.Close() })
+	return ln.Addr().String()
+}
+
+func TestMinBodyRateSlowSender(t *testing.T) {
+	addr := serveMinRate(t, 1000, 100*time.Millisecond)
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if _, err := fmt.Fprintf(conn, "POST /upload HTTP/1.1\r\nHost: example.com\r\nContent-Length: 10000\r\n\r\n"); err != nil {
+		t.Fatal(err)
+	}
+	// 每 100ms 发送 10 字节，远低于 1000 字节/秒
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			if _, err := conn.Write([]byte(strings.Repeat("x", 10))); err != nil {
+				return
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+	}()
+	start := time.Now()
+	_ = conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	// 服务端可能先返回错误响应，随后关闭连接
+	_, _ = io.Copy(io.Discard, conn)
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("want the slow connection dropped but it was kept for %s", elapsed)
+	}
+	conn.Close()
+	<-done
+}
+
+func TestMinBodyRateFastSender(t *testing.T) {
+	addr := serveMinRate(t, 1000, 100*time.Millisecond)
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	body := strings.Repeat("x", 10000)
+	if _, err := fmt.Fprintf(conn, "POST /upload HTTP/1.1\r\nHost: example.com\r\nContent-Length: %d\r\n\r\n%s", len(body), body); err != nil {
+		t.Fatal(err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(b) != "10000" {
+		t.Fatalf("want 200 with the full body read but got %d %q", resp.StatusCode, b)
+	}
+	// 请求体读取完毕后，连接可以继续处理下一个请求
+	time.Sleep(300 * time.Millisecond)
+	if _, err := fmt.Fprintf(conn, "GET / HTTP/1.1\r\nHost: example.com\r\n\r\n"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := http.ReadResponse(bufio.NewReader(conn), nil); err != nil {
+		t.Fatalf("want the connection kept alive but got: %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// socketOptions 结构体定义了代理监听器的套接字选项
+type socketOptions struct {
+	// reusePort 表示是否设置 SO_REUSEPORT，允许多个进程绑定同一个端口，便于无停机重启
+	reusePort bool
+	// keepAlive 是已接受连接的 TCP keep-alive 配置，用于检测失效的连接
+	keepAlive net.KeepAliveConfig
+}
+
+// listenerOptions 是从环境变量中读取的代理监听器的套接字选项
+var listenerOptions = socketOptions{
+	keepAlive: net.KeepAliveConfig{Enable: true},
+}
+
+// 初始化函数，从环境变量中读取套接字选项，零值的 keep-alive 参数使用系统默认值
+func init() {
+	var err error
+	if v := os.Getenv("PROXY_REUSE_PORT"); v != "" {
+		if listenerOptions.reusePort, err = strconv.ParseBool(v); err != nil {
+			panic(err)
+		}
+	}
+	if v := os.Getenv("PROXY_TCP_KEEPALIVE"); v != "" {
+		if listenerOptions.keepAlive.Enable, err = strconv.ParseBool(v); err != nil {
+			panic(err)
+		}
+	}
+	if v := os.Getenv("PROXY_TCP_KEEPALIVE_IDLE"); v != "" {
+		if listenerOptions.keepAlive.Idle, err = time.ParseDuration(v); err != nil {
+			panic(err)
+		}
+	}
+	if v := os.Getenv("PROXY_TCP_KEEPALIVE_INTERVAL"); v != "" {
+		if listenerOptions.keepAlive.Interval, err = time.ParseDuration(v); err != nil {
+			panic(err)
+		}
+	}
+	if v := os.Getenv("PROXY_TCP_KEEPALIVE_COUNT"); v != "" {
+		if listenerOptions.keepAlive.Count, err = strconv.Atoi(v); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// listenConfig 方法根据套接字选项创建监听配置
+func (o socketOptions) listenConfig() *net.ListenConfig {
+	lc := &net.ListenConfig{KeepAliveConfig: o.keepAlive}
+	if !o.keepAlive.Enable {
+		// KeepAlive 为负数时才会真正关闭 keep-alive
+		lc.KeepAlive = -1
+	}
+	if o.reusePort {
+		lc.Control = func(network, address string, c syscall.RawConn) error {
+			var serr error
+			if err := c.Control(func(fd uintptr) {
+				serr = setReusePort(fd)
+			}); err != nil {
+				return err
+			}
+			return serr
+		}
+	}
+	return lc
+}
+
+// listen 函数按全局的套接字选项监听 TCP 地址，优先使用从父进程继承的监听同一地址的套接字
+func listen(ctx context.Context, addr string) (net.Listener, error) {
+	ln, ok, err := inheritedListener(addr)
+	if !ok {
+		ln, err = listenerOptions.listenConfig().Listen(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return track(ln, addr), nil
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+func TestListenReusePort(t *testing.T) {
+	ctx := context.Background()
+	first, err := socketOptions{reusePort: true}.listenConfig().Listen(ctx, "tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Close()
+	addr := first.Addr().String()
+
+	if ln, err := (socketOptions{}).listenConfig().Listen(ctx, "tcp", addr); err == nil {
+		ln.Close()
+		t.Fatal("want address in use without SO_REUSEPORT")
+	}
+	second, err := socketOptions{reusePort: true}.listenConfig().Listen(ctx, "tcp", addr)
+	if err != nil {
+		t.Fatalf("want a second listener on %s with SO_REUSEPORT but got: %v", addr, err)
+	}
+	second.Close()
+}
+
+func TestListenKeepAlive(t *testing.T) {
+	tests := []struct {
+		name      string
+		keepAlive net.KeepAliveConfig
+		wantOn    int
+		wantIdle  int
+		wantIntvl int
+		wantCount int
+	}{
+		{"custom", net.KeepAliveConfig{Enable: true, Idle: 30 * time.Second, Interval: 5 * time.Second, Count: 4}, 1, 30, 5, 4},
+		{"disabled", net.KeepAliveConfig{}, 0, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ln, err := socketOptions{keepAlive: tt.keepAlive}.listenConfig().Listen(context.Background(), "tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer ln.Close()
+			client, err := net.Dial("tcp", ln.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer client.Close()
+			conn, err := ln.Accept()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			raw, err := conn.(*net.TCPConn).SyscallConn()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var on, idle, intvl, count int
+			var serr error
+			err = raw.Control(func(fd uintptr) {
+				if on, serr = unix.GetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_KEEPALIVE); serr != nil || on == 0 {
+					return
+				}
+				if idle, serr = unix.GetsockoptInt(int(fd), unix.IPPROTO_TCP, unix.TCP_KEEPIDLE); serr != nil {
+					return
+				}
+				if intvl, serr = unix.GetsockoptInt(int(fd), unix.IPPROTO_TCP, unix.TCP_KEEPINTVL); serr != nil {
+					return
+				}
+				count, serr = unix.GetsockoptInt(int(fd), unix.IPPROTO_TCP, unix.TCP_KEEPCNT)
+			})
+			if err != nil || serr != nil {
+				t.Fatal(err, serr)
+			}
+			if on != tt.wantOn || idle != tt.wantIdle || intvl != tt.wantIntvl || count != tt.wantCount {
+				t.Fatalf("want keep-alive (%d, %d, %d, %d) but got (%d, %d, %d, %d)",
+					tt.wantOn, tt.wantIdle, tt.wantIntvl, tt.wantCount, on, idle, intvl, count)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package server
+
+import "golang.org/x/sys/unix"
+
+// setReusePort 函数在套接字上设置 SO_REUSEPORT
+func setReusePort(fd uintptr) error {
+	return unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+}
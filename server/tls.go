@@ -0,0 +1,53 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+
+	"github.com/cnsync/gateway/secrets"
+)
+
+// NewTLSProxy 函数创建一个使用 TLS 的代理服务器实例，ja3 为 true 时在握手期间计算客户端的 JA3 指纹，
+// 指纹通过 JA3Handler 转发给上游
+func NewTLSProxy(handler http.Handler, addr string, cfg *tls.Config, ja3 bool) *ProxyServer {
+	s := NewProxy(handler, addr)
+	cfg = cfg.Clone()
+	// 同时支持 HTTP/2 和 HTTP/1.1
+	if len(cfg.NextProtos) == 0 {
+		cfg.NextProtos = []string{"h2", "http/1.1"}
+	}
+	s.TLSConfig = cfg
+	s.ja3 = ja3
+	if ja3 {
+		s.ConnContext = ja3ConnContext
+	}
+	return s
+}
+
+// LoadTLSConfig 函数加载证书和私钥并创建 TLS 配置，cert 和 key 可以是文件路径或者 scheme://path#field 格式的密钥引用
+func LoadTLSConfig(cert, key string) (*tls.Config, error) {
+	var (
+		pair tls.Certificate
+		err  error
+	)
+	_, certRef := secrets.ParseReference(cert)
+	_, keyRef := secrets.ParseReference(key)
+	if certRef || keyRef {
+		ctx := context.Background()
+		var certPEM, keyPEM string
+		if certPEM, err = secrets.Resolve(ctx, cert); err != nil {
+			return nil, err
+		}
+		if keyPEM, err = secrets.Resolve(ctx, key); err != nil {
+			return nil, err
+		}
+		pair, err = tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+	} else {
+		pair, err = tls.LoadX509KeyPair(cert, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{Certificates: []tls.Certificate{pair}}, nil
+}
@@ -0,0 +1,90 @@
+package trust
+
+import (
+	"net"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Networks 是受信任的代理和来源网段，从环境变量 GATEWAY_TRUSTED_CIDRS 中读取，以逗号分隔，
+// 默认只信任回环地址，私有网段等更大的范围需要显式配置
+var Networks = MustParseCIDRs("127.0.0.0/8,::1/128")
+
+func init() {
+	if v := os.Getenv("GATEWAY_TRUSTED_CIDRS"); v != "" {
+		Networks = MustParseCIDRs(v)
+	}
+}
+
+// MustParseCIDRs 函数解析以逗号分隔的网段列表，解析失败时 panic
+func MustParseCIDRs(s string) []*net.IPNet {
+	var networks []*net.IPNet
+	for _, cidr := range strings.Split(s, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// contains 函数判断 IP 是否属于受信任的网段
+func contains(ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range Networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTrustedPeer 函数判断请求的对端地址是否属于受信任的网段
+func IsTrustedPeer(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return false
+	}
+	return contains(net.ParseIP(host))
+}
+
+// ClientIP 函数返回请求的原始客户端地址：对端是受信任的代理时，从右向左跳过 X-Forwarded-For 中受信任的代理，
+// 第一个不受信任的地址即为客户端，对端不受信任时客户端设置的 X-Forwarded-For 不可信，返回对端地址
+func ClientIP(req *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return nil
+	}
+	ip := net.ParseIP(host)
+	if !contains(ip) {
+		return ip
+	}
+	values := req.Header.Values("X-Forwarded-For")
+	for i := len(values) - 1; i >= 0; i-- {
+		hops := strings.Split(values[i], ",")
+		for j := len(hops) - 1; j >= 0; j-- {
+			hop := net.ParseIP(strings.TrimSpace(hops[j]))
+			if hop == nil {
+				return nil
+			}
+			ip = hop
+			if !contains(ip) {
+				return ip
+			}
+		}
+	}
+	return ip
+}
+
+// IsTrusted 函数判断请求是否来自受信任的来源，即对端以及 X-Forwarded-For 中的每一跳都属于受信任的网段
+func IsTrusted(req *http.Request) bool {
+	return contains(ClientIP(req))
+}
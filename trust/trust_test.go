@@ -0,0 +1,53 @@
+package trust
+
+import (
+	"net"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	defer func(networks []*net.IPNet) { Networks = networks }(Networks)
+	Networks = MustParseCIDRs("127.0.0.0/8,10.0.0.0/8")
+	tests := []struct {
+		remoteAddr string
+		forwarded  []string
+		want       string
+		trusted    bool
+	}{
+		{"127.0.0.1:1234", nil, "127.0.0.1", true},
+		{"192.168.0.1:1234", nil, "192.168.0.1", false},
+		// 不受信任的对端设置的 X-Forwarded-For 被忽略
+		{"203.0.113.1:1234", []string{"127.0.0.1"}, "203.0.113.1", false},
+		// 从右向左跳过受信任的代理
+		{"127.0.0.1:1234", []string{"203.0.113.2, 10.0.0.2"}, "203.0.113.2", false},
+		{"127.0.0.1:1234", []string{"198.51.100.1, 203.0.113.2", "10.0.0.2"}, "203.0.113.2", false},
+		{"127.0.0.1:1234", []string{"10.0.0.3", "10.0.0.2"}, "10.0.0.3", true},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = tt.remoteAddr
+		for _, v := range tt.forwarded {
+			r.Header.Add("X-Forwarded-For", v)
+		}
+		if got := ClientIP(r); got.String() != tt.want {
+			t.Errorf("%s %v: want client %s but got %s", tt.remoteAddr, tt.forwarded, tt.want, got)
+		}
+		if got := IsTrusted(r); got != tt.trusted {
+			t.Errorf("%s %v: want trusted %v but got %v", tt.remoteAddr, tt.forwarded, tt.trusted, got)
+		}
+	}
+}
+
+func TestDefaultNetworks(t *testing.T) {
+	for addr, want := range map[string]bool{
+		"127.0.0.1:1234": true,
+		"[::1]:1234":     true,
+		"10.0.0.1:1234":  false,
+		"192.168.0.1:80": false,
+	} {
+		if got := IsTrustedPeer(addr); got != want {
+			t.Errorf("%s: want trusted %v but got %v", addr, want, got)
+		}
+	}
+}